@@ -0,0 +1,626 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/engine"
+	"github.com/Azure/aks-engine/pkg/helpers"
+	"github.com/Azure/aks-engine/pkg/helpers/ssh"
+	"github.com/Azure/aks-engine/pkg/i18n"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	addonsName             = "addons"
+	addonsShortDescription = "Manage the addons of an existing AKS Engine-created Kubernetes cluster"
+	addonsLongDescription  = "List, enable, disable or reconfigure the addons of a cluster built with AKS Engine. Addon manifests are rendered from the apimodel and copied to the addon manager directory of every control plane VM, no node is upgraded or restarted."
+)
+
+const (
+	addonsListName             = "list"
+	addonsListShortDescription = "List the addons of the cluster"
+	addonsListLongDescription  = "List the addons of the cluster, comparing the container images in the apimodel with the images the cluster runs."
+
+	addonsEnableName             = "enable"
+	addonsEnableShortDescription = "Enable an addon"
+	addonsEnableLongDescription  = "Enable an addon, deploy its manifest to the control plane VMs and update the apimodel."
+
+	addonsDisableName             = "disable"
+	addonsDisableShortDescription = "Disable an addon"
+	addonsDisableLongDescription  = "Disable an addon, remove its manifest from the control plane VMs and update the apimodel. The addon manager deletes the addon resources in Reconcile mode."
+
+	addonsDriftName             = "drift"
+	addonsDriftShortDescription = "Report the container images pinned in the apimodel"
+	addonsDriftLongDescription  = "Report the addon and component containers whose image in the apimodel differs from the default, compared with the default images of the target Kubernetes version, and optionally reset the pinned images to their defaults."
+
+	addonsSetName             = "set"
+	addonsSetShortDescription = "Reconfigure an enabled addon"
+	addonsSetLongDescription  = "Update the configuration or the container images of an enabled addon, deploy its manifest to the control plane VMs and update the apimodel."
+)
+
+const (
+	addonsDefaultInterval = 10 * time.Second
+	addonsDefaultTimeout  = 10 * time.Minute
+	addonsSSHTimeout      = 10 * time.Second
+)
+
+// addonEdit changes the addons configuration of an apimodel
+type addonEdit func(addons []api.KubernetesAddon) ([]api.KubernetesAddon, error)
+
+type addonsCmd struct {
+	// user input
+	location               string
+	apiModelPath           string
+	sshHostURI             string
+	linuxSSHPrivateKeyPath string
+	config                 map[string]string
+	images                 map[string]string
+	upgradeVersion         string
+	reset                  bool
+	output                 string
+
+	// computed
+	addonName       string
+	apiVersion      string
+	cs              *api.ContainerService
+	loader          *api.Apiloader
+	linuxAuthConfig *ssh.AuthConfig
+	jumpbox         *ssh.JumpBox
+}
+
+func newAddonsCmd() *cobra.Command {
+	command := &cobra.Command{
+		Use:   addonsName,
+		Short: addonsShortDescription,
+		Long:  addonsLongDescription,
+	}
+	command.AddCommand(newAddonsListCmd())
+	command.AddCommand(newAddonsEnableCmd())
+	command.AddCommand(newAddonsDisableCmd())
+	command.AddCommand(newAddonsSetCmd())
+	command.AddCommand(newAddonsDriftCmd())
+	return command
+}
+
+func newAddonsListCmd() *cobra.Command {
+	ac := addonsCmd{}
+	command := &cobra.Command{
+		Use:   addonsListName,
+		Short: addonsListShortDescription,
+		Long:  addonsListLongDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := ac.validateArgs(false); err != nil {
+				return errors.Wrap(err, "validating addons list args")
+			}
+			if err := ac.loadAPIModel(); err != nil {
+				return errors.Wrap(err, "loading API model")
+			}
+			cmd.SilenceUsage = true
+			return ac.list(os.Stdout)
+		},
+	}
+	ac.addFlags(command, false)
+	return command
+}
+
+func newAddonsEnableCmd() *cobra.Command {
+	ac := addonsCmd{}
+	command := &cobra.Command{
+		Use:   addonsEnableName + " ADDON",
+		Short: addonsEnableShortDescription,
+		Long:  addonsEnableLongDescription,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ac.addonName = args[0]
+			return ac.runEdit(cmd, addonsEnableName, setAddonEnabled(ac.addonName, true), true)
+		},
+	}
+	ac.addFlags(command, true)
+	return command
+}
+
+func newAddonsDisableCmd() *cobra.Command {
+	ac := addonsCmd{}
+	command := &cobra.Command{
+		Use:   addonsDisableName + " ADDON",
+		Short: addonsDisableShortDescription,
+		Long:  addonsDisableLongDescription,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ac.addonName = args[0]
+			return ac.runEdit(cmd, addonsDisableName, setAddonEnabled(ac.addonName, false), false)
+		},
+	}
+	ac.addFlags(command, true)
+	return command
+}
+
+func newAddonsSetCmd() *cobra.Command {
+	ac := addonsCmd{}
+	command := &cobra.Command{
+		Use:   addonsSetName + " ADDON",
+		Short: addonsSetShortDescription,
+		Long:  addonsSetLongDescription,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ac.addonName = args[0]
+			if len(ac.config) == 0 && len(ac.images) == 0 {
+				return errors.New("validating addons set args: --config or --image must be specified")
+			}
+			return ac.runEdit(cmd, addonsSetName, setAddonConfig(ac.addonName, ac.config, ac.images), true)
+		},
+	}
+	f := command.Flags()
+	ac.addFlags(command, true)
+	f.StringToStringVar(&ac.config, "config", nil, "addon configuration entries to set (comma-separated key=value pairs)")
+	f.StringToStringVar(&ac.images, "image", nil, "addon container images to set (comma-separated container=image pairs)")
+	return command
+}
+
+func newAddonsDriftCmd() *cobra.Command {
+	ac := addonsCmd{}
+	command := &cobra.Command{
+		Use:   addonsDriftName,
+		Short: addonsDriftShortDescription,
+		Long:  addonsDriftLongDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := ac.validateArgs(false); err != nil {
+				return errors.Wrap(err, "validating addons drift args")
+			}
+			if err := ac.loadAPIModel(); err != nil {
+				return errors.Wrap(err, "loading API model")
+			}
+			cmd.SilenceUsage = true
+			return ac.drift(os.Stdout)
+		},
+	}
+	f := command.Flags()
+	ac.addFlags(command, false)
+	f.StringVarP(&ac.upgradeVersion, "upgrade-version", "k", "", "target Kubernetes version, defaults to the cluster version")
+	f.BoolVar(&ac.reset, "reset", false, "reset the pinned images to their defaults in the apimodel")
+	f.StringVarP(&ac.output, "output", "o", "human", fmt.Sprintf("Output format. Allowed values: %s", strings.Join(outputFormatOptions, ", ")))
+	return command
+}
+
+func (ac *addonsCmd) addFlags(command *cobra.Command, withSSH bool) {
+	f := command.Flags()
+	f.StringVarP(&ac.location, "location", "l", "", "Azure location where the cluster is deployed (required)")
+	f.StringVarP(&ac.apiModelPath, "api-model", "m", "", "path to the generated apimodel.json file (required)")
+	_ = command.MarkFlagRequired("location")
+	_ = command.MarkFlagRequired("api-model")
+	if withSSH {
+		f.StringVar(&ac.sshHostURI, "ssh-host", "", "FQDN, or IP address, of an SSH listener that can reach all control plane VMs (required)")
+		f.StringVar(&ac.linuxSSHPrivateKeyPath, "linux-ssh-private-key", "", "path to a valid private SSH key to access the cluster's control plane VMs (required)")
+		_ = command.MarkFlagRequired("ssh-host")
+		_ = command.MarkFlagRequired("linux-ssh-private-key")
+	}
+}
+
+// runEdit applies edit to the apimodel, deploys the resulting addon manifest to the control plane VMs
+// and saves the updated apimodel
+func (ac *addonsCmd) runEdit(cmd *cobra.Command, name string, edit addonEdit, enabled bool) error {
+	if err := ac.validateArgs(true); err != nil {
+		return errors.Wrapf(err, "validating addons %s args", name)
+	}
+	if err := ac.loadAPIModel(); err != nil {
+		return errors.Wrap(err, "loading API model")
+	}
+	if err := ac.init(); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	return ac.edit(edit, enabled)
+}
+
+func (ac *addonsCmd) validateArgs(withSSH bool) (err error) {
+	locale, err := i18n.LoadTranslations()
+	if err != nil {
+		return errors.Wrap(err, "loading translation files")
+	}
+	ac.loader = &api.Apiloader{
+		Translator: &i18n.Translator{
+			Locale: locale,
+		},
+	}
+	ac.location = helpers.NormalizeAzureRegion(ac.location)
+	if ac.location == "" {
+		return errors.New("--location must be specified")
+	}
+	if ac.apiModelPath == "" {
+		return errors.New("--api-model must be specified")
+	} else if _, err = os.Stat(ac.apiModelPath); os.IsNotExist(err) {
+		return errors.Errorf("specified --api-model does not exist (%s)", ac.apiModelPath)
+	}
+	if !withSSH {
+		return nil
+	}
+	if ac.sshHostURI == "" {
+		return errors.New("--ssh-host must be specified")
+	}
+	if ac.linuxSSHPrivateKeyPath == "" {
+		return errors.New("--linux-ssh-private-key must be specified")
+	} else if _, err = os.Stat(ac.linuxSSHPrivateKeyPath); os.IsNotExist(err) {
+		return errors.Errorf("specified --linux-ssh-private-key does not exist (%s)", ac.linuxSSHPrivateKeyPath)
+	}
+	return nil
+}
+
+func (ac *addonsCmd) loadAPIModel() (err error) {
+	if ac.cs, ac.apiVersion, err = ac.loader.LoadContainerServiceFromFile(ac.apiModelPath, false, false, nil); err != nil {
+		return errors.Wrap(err, "error parsing api-model")
+	}
+	if ac.cs.Properties.IsCustomCloudProfile() {
+		if err = writeCustomCloudProfile(ac.cs); err != nil {
+			return errors.Wrap(err, "error writing custom cloud profile")
+		}
+		if err = ac.cs.Properties.SetCustomCloudSpec(api.AzureCustomCloudSpecParams{IsUpgrade: false, IsScale: true}); err != nil {
+			return errors.Wrap(err, "error parsing the api model")
+		}
+	}
+	if ac.cs.Location == "" {
+		ac.cs.Location = ac.location
+	} else if ac.cs.Location != ac.location {
+		return errors.New("--location flag does not match api-model location")
+	}
+	return
+}
+
+func (ac *addonsCmd) init() error {
+	ac.linuxAuthConfig = &ssh.AuthConfig{
+		User:           ac.cs.Properties.LinuxProfile.AdminUsername,
+		PrivateKeyPath: ac.linuxSSHPrivateKeyPath,
+	}
+	sshPort := vmssSSHPort
+	if ac.cs.Properties.MasterProfile.IsAvailabilitySet() {
+		sshPort = vmasSSHPort
+	}
+	ac.jumpbox = &ssh.JumpBox{URI: ac.sshHostURI, Port: sshPort, OperatingSystem: api.Linux, AuthConfig: ac.linuxAuthConfig}
+	if err := ssh.ValidateConfig(ac.jumpbox); err != nil {
+		return errors.Wrap(err, "validating ssh configuration")
+	}
+	return nil
+}
+
+func (ac *addonsCmd) edit(edit addonEdit, enabled bool) (err error) {
+	k := ac.cs.Properties.OrchestratorProfile.KubernetesConfig
+	if err = ac.validateAddonName(); err != nil {
+		return err
+	}
+	// the edited apimodel is validated before any control plane VM is changed
+	apiModel, err := ac.editAPIModel(func(cs *api.ContainerService) (err error) {
+		addons := &cs.Properties.OrchestratorProfile.KubernetesConfig.Addons
+		*addons, err = edit(*addons)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if k.Addons, err = edit(k.Addons); err != nil {
+		return err
+	}
+	_, err = ac.cs.SetPropertiesDefaults(api.PropertiesDefaultsParams{
+		IsScale:    true,
+		IsUpgrade:  false,
+		PkiKeySize: helpers.DefaultPkiKeySize,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error in SetPropertiesDefaults template %s", ac.apiModelPath)
+	}
+	if err = engine.ResolveCustomAddons(ac.cs); err != nil {
+		return err
+	}
+
+	if k.IsAddonEnabled(ac.addonName) != enabled {
+		if enabled {
+			return errors.Errorf("addon %s is not enabled", ac.addonName)
+		}
+		return errors.Errorf("addon %s cannot be disabled in this cluster configuration", ac.addonName)
+	}
+
+	if enabled {
+		manifestPath, manifest, err := engine.GetKubernetesAddonManifest(ac.cs, ac.addonName)
+		if err != nil {
+			return err
+		}
+		file := ssh.NewRemoteFile(manifestPath, crtPermissions, rootUserGroup, []byte(manifest))
+		for _, master := range ac.getControlPlaneNodes() {
+			log.Infof("Copying %s to %s", manifestPath, master.URI)
+			if err = ac.copyToRemote(master, file); err != nil {
+				return err
+			}
+		}
+	} else {
+		manifestPath, err := engine.GetKubernetesAddonManifestPath(ac.cs, ac.addonName)
+		if err != nil {
+			return err
+		}
+		for _, master := range ac.getControlPlaneNodes() {
+			log.Infof("Deleting %s from %s", manifestPath, master.URI)
+			if err = ac.executeRemote(master, fmt.Sprintf("sudo rm -f %s", manifestPath)); err != nil {
+				return err
+			}
+		}
+		log.Warnf("Resources of addon %s in EnsureExists mode are not deleted by the addon manager", ac.addonName)
+	}
+
+	if err = ac.writeAPIModel(apiModel); err != nil {
+		return errors.Wrap(err, "updating apimodel")
+	}
+	log.Infof("Addon %s updated, the addon manager applies the changes within a minute", ac.addonName)
+	return nil
+}
+
+// getControlPlaneNodes returns the control plane VMs, reachable through the jumpbox
+func (ac *addonsCmd) getControlPlaneNodes() []*ssh.RemoteHost {
+	var nodes []*ssh.RemoteHost
+	for _, master := range ac.cs.Properties.GetMasterVMNameList() {
+		nodes = append(nodes, &ssh.RemoteHost{
+			URI:             master,
+			Port:            22,
+			OperatingSystem: api.Linux,
+			AuthConfig:      ac.linuxAuthConfig,
+			Jumpbox:         ac.jumpbox,
+		})
+	}
+	return nodes
+}
+
+func (ac *addonsCmd) copyToRemote(node *ssh.RemoteHost, file *ssh.RemoteFile) error {
+	ctx, cancel := context.WithTimeout(context.Background(), addonsSSHTimeout)
+	defer cancel()
+	if stdout, err := ssh.CopyToRemote(ctx, node, file); err != nil {
+		log.Debugf("Remote command output: %s", stdout)
+		return errors.Wrapf(err, "copying %s to %s", file.Path, node.URI)
+	}
+	return nil
+}
+
+func (ac *addonsCmd) executeRemote(node *ssh.RemoteHost, script string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), addonsSSHTimeout)
+	defer cancel()
+	if stdout, err := ssh.ExecuteRemote(ctx, node, script); err != nil {
+		log.Debugf("Remote command output: %s", stdout)
+		return errors.Wrapf(err, "executing remote command on %s", node.URI)
+	}
+	return nil
+}
+
+// validateAddonName returns an error if the addon is neither a built-in addon nor a custom addon of the apimodel
+func (ac *addonsCmd) validateAddonName() error {
+	if addon := ac.cs.Properties.OrchestratorProfile.KubernetesConfig.GetAddonByName(ac.addonName); addon.IsCustom() {
+		return nil
+	}
+	if _, err := engine.GetKubernetesAddonManifestPath(ac.cs, ac.addonName); err != nil {
+		return errors.Errorf("unknown addon %s", ac.addonName)
+	}
+	return nil
+}
+
+// updateAPIModel applies update to the apimodel file as the user wrote it
+func (ac *addonsCmd) updateAPIModel(update func(cs *api.ContainerService) error) error {
+	b, err := ac.editAPIModel(update)
+	if err != nil {
+		return err
+	}
+	return ac.writeAPIModel(b)
+}
+
+// editAPIModel applies update to the apimodel file as the user wrote it, so defaults are not persisted,
+// and returns the updated apimodel once it is validated
+func (ac *addonsCmd) editAPIModel(update func(cs *api.ContainerService) error) ([]byte, error) {
+	cs, apiVersion, err := ac.loader.LoadContainerServiceFromFile(ac.apiModelPath, false, true, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err = update(cs); err != nil {
+		return nil, err
+	}
+	b, err := ac.loader.SerializeContainerService(cs, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = ac.loader.LoadContainerService(b, apiVersion, true, true, nil); err != nil {
+		return nil, errors.Wrap(err, "validating the updated apimodel")
+	}
+	return b, nil
+}
+
+// writeAPIModel saves apiModel to the apimodel file
+func (ac *addonsCmd) writeAPIModel(apiModel []byte) error {
+	f := helpers.FileSaver{
+		Translator: ac.loader.Translator,
+	}
+	dir, file := filepath.Split(ac.apiModelPath)
+	return f.SaveFile(dir, file, apiModel)
+}
+
+func (ac *addonsCmd) list(w io.Writer) error {
+	kubeClient, err := getKubeClient(ac.cs, addonsDefaultInterval, addonsDefaultTimeout)
+	if err != nil {
+		return errors.Wrap(err, "creating Kubernetes client")
+	}
+	pods, err := kubeClient.ListAllPods()
+	if err != nil {
+		return errors.Wrap(err, "listing pods")
+	}
+	return printAddons(w, ac.cs.Properties.OrchestratorProfile.KubernetesConfig.Addons, getRunningImages(pods))
+}
+
+func (ac *addonsCmd) drift(w io.Writer) error {
+	version := ac.upgradeVersion
+	if version == "" {
+		version = ac.cs.Properties.OrchestratorProfile.OrchestratorVersion
+	}
+	drift := ac.cs.GetImageDrift(version)
+	if err := printImageDrift(w, drift, ac.output); err != nil {
+		return err
+	}
+	if ac.reset && len(drift) > 0 {
+		if err := ac.updateAPIModel(func(cs *api.ContainerService) error {
+			cs.ResetImageDrift(drift)
+			return nil
+		}); err != nil {
+			return errors.Wrap(err, "updating apimodel")
+		}
+		log.Infof("Pinned images reset in %s, the next upgrade or addons command deploys the defaults", ac.apiModelPath)
+	}
+	return nil
+}
+
+// printImageDrift writes the pinned images of drift
+func printImageDrift(w io.Writer, drift []api.ImageDrift, output string) error {
+	switch output {
+	case "json":
+		data, err := helpers.JSONMarshalIndent(drift, "", "  ", false)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+	case "human":
+		tw := tabwriter.NewWriter(w, 0, 4, 1, ' ', tabwriter.FilterHTML)
+		fmt.Fprintln(tw, "Kind\tName\tContainer\tPinned\tDefault\tKept\tIncompatible")
+		for _, d := range drift {
+			incompatible := d.Incompatible
+			if incompatible == "" {
+				incompatible = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%t\t%s\n", d.Kind, d.Name, d.Container, d.Image, d.Default, d.Kept, incompatible)
+		}
+		return tw.Flush()
+	default:
+		return errors.Errorf(`output format "%s" is not supported`, output)
+	}
+	return nil
+}
+
+// setAddonEnabled returns an addonEdit that enables or disables addon name
+func setAddonEnabled(name string, enabled bool) addonEdit {
+	return func(addons []api.KubernetesAddon) ([]api.KubernetesAddon, error) {
+		for i := range addons {
+			if addons[i].Name == name {
+				addons[i].Enabled = to.BoolPtr(enabled)
+				return addons, nil
+			}
+		}
+		return append(addons, api.KubernetesAddon{
+			Name:    name,
+			Enabled: to.BoolPtr(enabled),
+		}), nil
+	}
+}
+
+// setAddonConfig returns an addonEdit that sets configuration entries and container images of the enabled addon name
+func setAddonConfig(name string, config, images map[string]string) addonEdit {
+	return func(addons []api.KubernetesAddon) ([]api.KubernetesAddon, error) {
+		i := -1
+		for j := range addons {
+			if addons[j].Name == name {
+				i = j
+			}
+		}
+		if i < 0 {
+			addons = append(addons, api.KubernetesAddon{Name: name})
+			i = len(addons) - 1
+		}
+		if addons[i].Enabled != nil && !to.Bool(addons[i].Enabled) {
+			return nil, errors.Errorf("addon %s is not enabled", name)
+		}
+		for key, val := range config {
+			if addons[i].Config == nil {
+				addons[i].Config = make(map[string]string)
+			}
+			addons[i].Config[key] = val
+		}
+		for container, image := range images {
+			if c := addons[i].GetAddonContainersIndexByName(container); c > -1 {
+				addons[i].Containers[c].Image = image
+			} else {
+				addons[i].Containers = append(addons[i].Containers, api.KubernetesContainerSpec{
+					Name:  container,
+					Image: image,
+				})
+			}
+		}
+		// the manifest of a Helm chart addon is rendered again with the new values
+		if addons[i].IsCustom() && addons[i].Source.HelmChart != nil && len(config) > 0 {
+			addons[i].Data = ""
+		}
+		return addons, nil
+	}
+}
+
+// getRunningImages maps image repositories to the images the cluster pods run from them
+func getRunningImages(pods *v1.PodList) map[string][]string {
+	running := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, pod := range pods.Items {
+		for _, containers := range [][]v1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+			for _, c := range containers {
+				if seen[c.Image] {
+					continue
+				}
+				seen[c.Image] = true
+				repository := getImageRepository(c.Image)
+				running[repository] = append(running[repository], c.Image)
+			}
+		}
+	}
+	for repository := range running {
+		sort.Strings(running[repository])
+	}
+	return running
+}
+
+// getImageRepository returns image without its tag or digest
+func getImageRepository(image string) string {
+	if i := strings.Index(image, "@"); i > -1 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+func printAddons(w io.Writer, addons []api.KubernetesAddon, running map[string][]string) error {
+	sorted := make([]api.KubernetesAddon, len(addons))
+	copy(sorted, addons)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.FilterHTML)
+	fmt.Fprintln(tw, "Addon\tEnabled\tContainer\tDesired\tRunning")
+	for _, addon := range sorted {
+		enabled := addon.IsEnabled()
+		if !enabled || len(addon.Containers) == 0 {
+			fmt.Fprintf(tw, "%s\t%t\t\t\t\n", addon.Name, enabled)
+			continue
+		}
+		for _, c := range addon.Containers {
+			images := running[getImageRepository(c.Image)]
+			status := strings.Join(images, ", ")
+			if len(images) == 0 {
+				status = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%t\t%s\t%s\t%s\n", addon.Name, enabled, c.Name, c.Image, status)
+		}
+	}
+	return tw.Flush()
+}
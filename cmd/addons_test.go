@@ -0,0 +1,357 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/api/common"
+	"github.com/Azure/aks-engine/pkg/helpers"
+	"github.com/Azure/aks-engine/pkg/i18n"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestAddonsCmd(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	command := newAddonsCmd()
+	g.Expect(command.Use).Should(Equal(addonsName))
+	g.Expect(command.Short).Should(Equal(addonsShortDescription))
+	g.Expect(command.Long).Should(Equal(addonsLongDescription))
+
+	var uses []string
+	for _, c := range command.Commands() {
+		uses = append(uses, c.Use)
+	}
+	g.Expect(uses).To(Equal([]string{"disable ADDON", "drift", "enable ADDON", "list", "set ADDON"}))
+
+	for _, args := range [][]string{{"list"}, {"enable"}, {"disable", "a", "b"}, {"set", "coredns"}, {"drift"}} {
+		command = newAddonsCmd()
+		command.SetArgs(args)
+		g.Expect(command.Execute()).To(HaveOccurred(), "args %v", args)
+	}
+}
+
+func TestAddonsCmdValidateArgs(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	existingFile := "../examples/kubernetes.json"
+	missingFile := "./random/file"
+
+	cases := []struct {
+		ac          *addonsCmd
+		withSSH     bool
+		expectedErr error
+		name        string
+	}{
+		{
+			ac:          &addonsCmd{apiModelPath: existingFile},
+			expectedErr: errors.New("--location must be specified"),
+			name:        "NeedsLocation",
+		},
+		{
+			ac:          &addonsCmd{apiModelPath: missingFile, location: "southcentralus"},
+			expectedErr: errors.Errorf("specified --api-model does not exist (%s)", missingFile),
+			name:        "BadAPIModel",
+		},
+		{
+			ac:   &addonsCmd{apiModelPath: existingFile, location: "southcentralus"},
+			name: "ListDoesNotNeedSSH",
+		},
+		{
+			ac:          &addonsCmd{apiModelPath: existingFile, location: "southcentralus"},
+			withSSH:     true,
+			expectedErr: errors.New("--ssh-host must be specified"),
+			name:        "NeedsSSHHost",
+		},
+		{
+			ac:          &addonsCmd{apiModelPath: existingFile, location: "southcentralus", sshHostURI: "server.example.com", linuxSSHPrivateKeyPath: missingFile},
+			withSSH:     true,
+			expectedErr: errors.Errorf("specified --linux-ssh-private-key does not exist (%s)", missingFile),
+			name:        "BadLinuxSSHPrivateKey",
+		},
+		{
+			ac:      &addonsCmd{apiModelPath: existingFile, location: "southcentralus", sshHostURI: "server.example.com", linuxSSHPrivateKeyPath: existingFile},
+			withSSH: true,
+			name:    "IsValid",
+		},
+	}
+
+	for _, tc := range cases {
+		c := tc
+		t.Run(c.name, func(t *testing.T) {
+			err := c.ac.validateArgs(c.withSSH)
+			if c.expectedErr != nil {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(Equal(c.expectedErr.Error()))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestSetAddonEnabled(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	addons := []api.KubernetesAddon{
+		{Name: "coredns", Enabled: to.BoolPtr(true)},
+	}
+	addons, err := setAddonEnabled("coredns", false)(addons)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(addons).To(HaveLen(1))
+	g.Expect(addons[0].IsDisabled()).To(BeTrue())
+
+	addons, err = setAddonEnabled("metrics-server", true)(addons)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(addons).To(HaveLen(2))
+	g.Expect(addons[1].Name).To(Equal("metrics-server"))
+	g.Expect(addons[1].IsEnabled()).To(BeTrue())
+}
+
+func TestSetAddonConfig(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	addons := []api.KubernetesAddon{
+		{
+			Name:    "cluster-autoscaler",
+			Enabled: to.BoolPtr(true),
+			Config:  map[string]string{"scan-interval": "1m"},
+			Containers: []api.KubernetesContainerSpec{
+				{Name: "cluster-autoscaler", Image: "mcr.microsoft.com/oss/kubernetes/autoscaler/cluster-autoscaler:v1.18.2"},
+			},
+		},
+		{Name: "dashboard", Enabled: to.BoolPtr(false)},
+	}
+	edit := setAddonConfig("cluster-autoscaler",
+		map[string]string{"scan-interval": "30s", "expander": "least-waste"},
+		map[string]string{"cluster-autoscaler": "mcr.microsoft.com/oss/kubernetes/autoscaler/cluster-autoscaler:v1.18.3", "sidecar": "example.com/sidecar:v1"})
+	addons, err := edit(addons)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(addons[0].Config).To(Equal(map[string]string{"scan-interval": "30s", "expander": "least-waste"}))
+	g.Expect(addons[0].Containers).To(ConsistOf(
+		api.KubernetesContainerSpec{Name: "cluster-autoscaler", Image: "mcr.microsoft.com/oss/kubernetes/autoscaler/cluster-autoscaler:v1.18.3"},
+		api.KubernetesContainerSpec{Name: "sidecar", Image: "example.com/sidecar:v1"},
+	))
+
+	_, err = setAddonConfig("dashboard", map[string]string{"foo": "bar"}, nil)(addons)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("addon dashboard is not enabled"))
+
+	addons, err = setAddonConfig("metrics-server", map[string]string{"foo": "bar"}, nil)(addons)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(addons).To(HaveLen(3))
+	g.Expect(addons[2]).To(Equal(api.KubernetesAddon{Name: "metrics-server", Config: map[string]string{"foo": "bar"}}))
+
+	addons = []api.KubernetesAddon{
+		{
+			Name:    "ingress-nginx",
+			Enabled: to.BoolPtr(true),
+			Data:    "a2luZDogTmFtZXNwYWNl",
+			Source:  &api.AddonSource{HelmChart: &api.AddonHelmChart{Chart: "ingress-nginx"}},
+		},
+		{
+			Name:    "agent",
+			Enabled: to.BoolPtr(true),
+			Data:    "a2luZDogTmFtZXNwYWNl",
+			Source:  &api.AddonSource{Path: "agent.yaml"},
+		},
+	}
+	addons, err = setAddonConfig("ingress-nginx", map[string]string{"controller.replicaCount": "2"}, nil)(addons)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(addons[0].Data).To(BeEmpty())
+	addons, err = setAddonConfig("agent", map[string]string{"replicas": "2"}, nil)(addons)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(addons[1].Data).To(Equal("a2luZDogTmFtZXNwYWNl"))
+}
+
+func TestGetImageRepository(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	g.Expect(getImageRepository("mcr.microsoft.com/oss/kubernetes/coredns:1.6.6")).To(Equal("mcr.microsoft.com/oss/kubernetes/coredns"))
+	g.Expect(getImageRepository("localhost:5000/coredns")).To(Equal("localhost:5000/coredns"))
+	g.Expect(getImageRepository("localhost:5000/coredns:1.6.6")).To(Equal("localhost:5000/coredns"))
+	g.Expect(getImageRepository("mcr.microsoft.com/coredns@sha256:abc")).To(Equal("mcr.microsoft.com/coredns"))
+}
+
+func TestPrintAddons(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	pods := &v1.PodList{
+		Items: []v1.Pod{
+			{
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{{Image: "example.com/init:v1"}},
+					Containers:     []v1.Container{{Image: "mcr.microsoft.com/oss/kubernetes/coredns:1.6.5"}},
+				},
+			},
+			{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Image: "mcr.microsoft.com/oss/kubernetes/coredns:1.6.6"}},
+				},
+			},
+		},
+	}
+	running := getRunningImages(pods)
+	g.Expect(running["mcr.microsoft.com/oss/kubernetes/coredns"]).To(Equal([]string{
+		"mcr.microsoft.com/oss/kubernetes/coredns:1.6.5",
+		"mcr.microsoft.com/oss/kubernetes/coredns:1.6.6",
+	}))
+	g.Expect(running["example.com/init"]).To(Equal([]string{"example.com/init:v1"}))
+
+	addons := []api.KubernetesAddon{
+		{
+			Name:    "metrics-server",
+			Enabled: to.BoolPtr(true),
+			Containers: []api.KubernetesContainerSpec{
+				{Name: "metrics-server", Image: "mcr.microsoft.com/oss/kubernetes/metrics-server:v0.3.7"},
+			},
+		},
+		{
+			Name:    "coredns",
+			Enabled: to.BoolPtr(true),
+			Containers: []api.KubernetesContainerSpec{
+				{Name: "coredns", Image: "mcr.microsoft.com/oss/kubernetes/coredns:1.6.6"},
+			},
+		},
+		{Name: "dashboard", Enabled: to.BoolPtr(false)},
+	}
+	var out bytes.Buffer
+	g.Expect(printAddons(&out, addons, running)).To(Succeed())
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	g.Expect(lines).To(HaveLen(4))
+	g.Expect(strings.Fields(lines[0])).To(Equal([]string{"Addon", "Enabled", "Container", "Desired", "Running"}))
+	g.Expect(strings.Fields(lines[1])).To(Equal([]string{"coredns", "true", "coredns", "mcr.microsoft.com/oss/kubernetes/coredns:1.6.6",
+		"mcr.microsoft.com/oss/kubernetes/coredns:1.6.5,", "mcr.microsoft.com/oss/kubernetes/coredns:1.6.6"}))
+	g.Expect(strings.Fields(lines[2])).To(Equal([]string{"dashboard", "false"}))
+	g.Expect(strings.Fields(lines[3])).To(Equal([]string{"metrics-server", "true", "metrics-server", "mcr.microsoft.com/oss/kubernetes/metrics-server:v0.3.7", "-"}))
+}
+
+func TestAddonsDrift(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	cs := api.CreateMockContainerService("testcluster", "1.22.17", 1, 1, false)
+	cs.Location = "westus2"
+	cs.Properties.OrchestratorProfile.KubernetesConfig.CustomKubeAPIServerImage = "example.com/kube-apiserver:v1.22.1"
+	_, err := cs.SetPropertiesDefaults(api.PropertiesDefaultsParams{PkiKeySize: helpers.DefaultPkiKeySize})
+	g.Expect(err).NotTo(HaveOccurred())
+	loader := &api.Apiloader{Translator: &i18n.Translator{}}
+	b, err := loader.SerializeContainerService(cs, "vlabs")
+	g.Expect(err).NotTo(HaveOccurred())
+	apiModelPath := filepath.Join(t.TempDir(), "apimodel.json")
+	g.Expect(os.WriteFile(apiModelPath, b, 0600)).To(Succeed())
+
+	ac := &addonsCmd{apiModelPath: apiModelPath, location: "westus2", upgradeVersion: "1.23.17", reset: true, output: "json"}
+	g.Expect(ac.validateArgs(false)).To(Succeed())
+	g.Expect(ac.loadAPIModel()).To(Succeed())
+	var out bytes.Buffer
+	g.Expect(ac.drift(&out)).To(Succeed())
+
+	var drift []api.ImageDrift
+	g.Expect(json.Unmarshal(out.Bytes(), &drift)).To(Succeed())
+	g.Expect(drift).To(HaveLen(1))
+	g.Expect(drift[0].Name).To(Equal(common.APIServerComponentName))
+	g.Expect(drift[0].Kept).To(BeTrue())
+	g.Expect(drift[0].Incompatible).NotTo(BeEmpty())
+
+	cs, _, err = loader.LoadContainerServiceFromFile(apiModelPath, false, false, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cs.Properties.OrchestratorProfile.KubernetesConfig.CustomKubeAPIServerImage).To(BeEmpty())
+	g.Expect(cs.GetImageDrift("1.23.17")).To(BeEmpty())
+}
+
+func TestAddonsEditAPIModel(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	cs := api.CreateMockContainerService("testcluster", "1.22.17", 1, 1, false)
+	cs.Location = "westus2"
+	_, err := cs.SetPropertiesDefaults(api.PropertiesDefaultsParams{PkiKeySize: helpers.DefaultPkiKeySize})
+	g.Expect(err).NotTo(HaveOccurred())
+	loader := &api.Apiloader{Translator: &i18n.Translator{}}
+	b, err := loader.SerializeContainerService(cs, "vlabs")
+	g.Expect(err).NotTo(HaveOccurred())
+	apiModelPath := filepath.Join(t.TempDir(), "apimodel.json")
+	g.Expect(os.WriteFile(apiModelPath, b, 0600)).To(Succeed())
+
+	ac := &addonsCmd{apiModelPath: apiModelPath, location: "westus2"}
+	g.Expect(ac.validateArgs(false)).To(Succeed())
+	g.Expect(ac.loadAPIModel()).To(Succeed())
+
+	ac.addonName = "metrics-server"
+	g.Expect(ac.validateAddonName()).To(Succeed())
+	ac.addonName = "metric-server"
+	g.Expect(ac.validateAddonName()).To(MatchError("unknown addon metric-server"))
+	ac.cs.Properties.OrchestratorProfile.KubernetesConfig.Addons = append(ac.cs.Properties.OrchestratorProfile.KubernetesConfig.Addons,
+		api.KubernetesAddon{Name: "metric-server", Source: &api.AddonSource{Path: "metric-server.yaml"}})
+	g.Expect(ac.validateAddonName()).To(Succeed())
+
+	_, err = ac.editAPIModel(func(cs *api.ContainerService) (err error) {
+		k := cs.Properties.OrchestratorProfile.KubernetesConfig
+		k.Addons, err = setAddonEnabled(common.AADAdminGroupAddonName, true)(k.Addons)
+		return err
+	})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(HavePrefix("validating the updated apimodel"))
+
+	b, err = ac.editAPIModel(func(cs *api.ContainerService) (err error) {
+		k := cs.Properties.OrchestratorProfile.KubernetesConfig
+		k.Addons, err = setAddonEnabled(common.MetricsServerAddonName, false)(k.Addons)
+		return err
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ac.writeAPIModel(b)).To(Succeed())
+	cs, _, err = loader.LoadContainerServiceFromFile(apiModelPath, false, true, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cs.Properties.OrchestratorProfile.KubernetesConfig.IsAddonDisabled(common.MetricsServerAddonName)).To(BeTrue())
+}
+
+func TestPrintImageDrift(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	drift := []api.ImageDrift{
+		{
+			Kind:      api.ImageDriftKindAddon,
+			Name:      "coredns",
+			Container: "coredns",
+			Image:     "example.com/coredns:1.9.0",
+			Default:   "mcr.microsoft.com/oss/kubernetes/coredns:1.8.6",
+		},
+		{
+			Kind:         api.ImageDriftKindComponent,
+			Name:         "kube-apiserver",
+			Container:    "kube-apiserver",
+			Image:        "example.com/kube-apiserver:v1.22.1",
+			Default:      "mcr.microsoft.com/oss/kubernetes/kube-apiserver:v1.23.17",
+			Kept:         true,
+			Incompatible: "image version 1.22 does not match Kubernetes version 1.23",
+		},
+	}
+	var out bytes.Buffer
+	g.Expect(printImageDrift(&out, drift, "human")).To(Succeed())
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	g.Expect(lines).To(HaveLen(3))
+	g.Expect(strings.Fields(lines[0])).To(Equal([]string{"Kind", "Name", "Container", "Pinned", "Default", "Kept", "Incompatible"}))
+	g.Expect(strings.Fields(lines[1])).To(Equal([]string{"addon", "coredns", "coredns", "example.com/coredns:1.9.0", "mcr.microsoft.com/oss/kubernetes/coredns:1.8.6", "false", "-"}))
+	g.Expect(lines[2]).To(HaveSuffix("true  image version 1.22 does not match Kubernetes version 1.23"))
+
+	g.Expect(printImageDrift(&out, drift, "yaml")).To(MatchError(`output format "yaml" is not supported`))
+}
@@ -5,32 +5,26 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"math/rand"
 	"os"
 	"path/filepath"
-	"strings"
-	"time"
 
 	"github.com/Azure/aks-engine/pkg/api"
 	"github.com/Azure/aks-engine/pkg/armhelpers"
-	"github.com/Azure/aks-engine/pkg/engine"
-	"github.com/Azure/aks-engine/pkg/engine/transform"
+	"github.com/Azure/aks-engine/pkg/cluster"
 	"github.com/Azure/aks-engine/pkg/helpers"
 	"github.com/Azure/aks-engine/pkg/i18n"
 	"github.com/Azure/aks-engine/pkg/operations"
-	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/leonelquinteros/gotext"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	prefixed "github.com/x-cray/logrus-prefixed-formatter"
-	v1 "k8s.io/api/core/v1"
 )
 
 type addPoolCmd struct {
 	authArgs
+	progressArgs
+	whatIfArgs
+	outputArgs
 
 	// user input
 	apiModelPath      string
@@ -44,11 +38,7 @@ type addPoolCmd struct {
 	nodePool         *api.AgentPoolProfile
 	client           armhelpers.AKSEngineClient
 	locale           *gotext.Locale
-	nameSuffix       string
 	logger           *log.Entry
-	apiserverURL     string
-	kubeconfig       string
-	nodes            []v1.Node
 }
 
 const (
@@ -65,7 +55,11 @@ func newAddPoolCmd() *cobra.Command {
 		Use:   addPoolName,
 		Short: addPoolShortDescription,
 		Long:  addPoolLongDescription,
-		RunE:  apc.run,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return apc.runWithResult(cmd, func() error {
+				return apc.run(cmd, args)
+			})
+		},
 	}
 
 	f := addPoolCmd.Flags()
@@ -75,6 +69,9 @@ func newAddPoolCmd() *cobra.Command {
 	f.StringVarP(&apc.nodePoolPath, "node-pool", "p", "", "path to a JSON file that defines the new node pool spec")
 
 	addAuthFlags(&apc.authArgs, f)
+	addProgressFlags(&apc.progressArgs, f)
+	addWhatIfFlag(&apc.whatIfArgs, f)
+	addOutputFlag(&apc.outputArgs, f, "o")
 
 	return addPoolCmd
 }
@@ -113,9 +110,7 @@ func (apc *addPoolCmd) validate(cmd *cobra.Command) error {
 }
 
 func (apc *addPoolCmd) load() error {
-	logger := log.New()
-	logger.Formatter = new(prefixed.TextFormatter)
-	apc.logger = log.NewEntry(log.New())
+	apc.logger = newLogger()
 	var err error
 
 	ctx, cancel := context.WithTimeout(context.Background(), armhelpers.DefaultARMOperationTimeout)
@@ -144,16 +139,6 @@ func (apc *addPoolCmd) load() error {
 		return errors.Wrap(err, "error parsing the agent pool")
 	}
 
-	// Assign VMSSName property based on the new pool being added to the end of the existing AgentPoolProfiles array
-	if apc.nodePool.IsVirtualMachineScaleSets() {
-		numExistingPools := len(apc.containerService.Properties.AgentPoolProfiles)
-		// we can reuse the value of numExistingPools due to array index beginning at "0"
-		apc.nodePool.VMSSName = apc.containerService.Properties.GetAgentVMPrefix(apc.nodePool, numExistingPools)
-		if apc.nodePool.VMSSName == "" {
-			return errors.Errorf("unable to compute a VMSSName property value from new pool definition")
-		}
-	}
-
 	if apc.containerService.Properties.IsCustomCloudProfile() {
 		if err = writeCustomCloudProfile(apc.containerService); err != nil {
 			return errors.Wrap(err, "error writing custom cloud profile")
@@ -163,26 +148,26 @@ func (apc *addPoolCmd) load() error {
 		}
 	}
 
-	for _, p := range apc.containerService.Properties.AgentPoolProfiles {
-		if strings.EqualFold(p.Name, apc.nodePool.Name) {
-			return errors.Errorf("node pool %s already exists", p.Name)
-		}
-		if !strings.EqualFold(p.AvailabilityProfile, apc.nodePool.AvailabilityProfile) {
-			return errors.New("mixed mode availability profiles are not allowed, all node pools should have the same availabilityProfile")
-		}
+	if err = apc.authArgs.validateAuthArgs(); err != nil {
+		return err
 	}
 
-	if err = apc.authArgs.validateAuthArgs(); err != nil {
+	if err = apc.validateProgressArgs(); err != nil {
 		return err
 	}
 
 	if apc.client, err = apc.authArgs.getClient(); err != nil {
 		return errors.Wrap(err, "failed to get client")
 	}
+	apc.client = apc.withDeploymentProgress(apc.client)
+	apc.client = apc.withResultRecording(apc.client, apc.SubscriptionID.String())
+	apc.result.ResourceGroup = apc.resourceGroupName
 
-	_, err = apc.client.EnsureResourceGroup(ctx, apc.resourceGroupName, apc.location, nil)
-	if err != nil {
-		return err
+	if !apc.whatIf {
+		_, err = apc.client.EnsureResourceGroup(ctx, apc.resourceGroupName, apc.location, nil)
+		if err != nil {
+			return err
+		}
 	}
 
 	if apc.containerService.Location == "" {
@@ -190,15 +175,6 @@ func (apc *addPoolCmd) load() error {
 	} else if apc.containerService.Location != apc.location {
 		return errors.New("--location does not match api model location")
 	}
-
-	//allows to identify VMs in the resource group that belong to this cluster.
-	apc.nameSuffix = apc.containerService.Properties.GetClusterID()
-	log.Debugf("Cluster ID used in all agent pools: %s", apc.nameSuffix)
-
-	apc.kubeconfig, err = engine.GenerateKubeConfig(apc.containerService.Properties, apc.location)
-	if err != nil {
-		return errors.New("Unable to derive kubeconfig from api model")
-	}
 	return nil
 }
 
@@ -210,114 +186,28 @@ func (apc *addPoolCmd) run(cmd *cobra.Command, args []string) error {
 		return errors.Wrap(err, "failed to load existing container service")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), armhelpers.DefaultARMOperationTimeout)
-	defer cancel()
-	orchestratorInfo := apc.containerService.Properties.OrchestratorProfile
-	winPoolIndex := -1
-
-	if apc.nodePool.IsVirtualMachineScaleSets() {
-		for vmssListPage, err := apc.client.ListVirtualMachineScaleSets(ctx, apc.resourceGroupName); vmssListPage.NotDone(); err = vmssListPage.NextWithContext(ctx) {
-			if err != nil {
-				return errors.Wrap(err, "failed to get VMSS list in the resource group")
-			}
-			for _, vmss := range vmssListPage.Values() {
-				if apc.nodePool.VMSSName == to.String(vmss.Name) {
-					return errors.New("A VMSS node pool with the given name already exists in the cluster")
-				}
-			}
-		}
-	}
-	translator := engine.Context{
-		Translator: &i18n.Translator{
-			Locale: apc.locale,
+	translator := &i18n.Translator{Locale: apc.locale}
+	resp, err := cluster.AddPool(context.Background(), &cluster.AddPoolRequest{
+		Options: cluster.Options{
+			Client:     apc.client,
+			Logger:     apc.logger,
+			Translator: translator,
+			Output:     apc.textOutput(),
+			BuildTag:   BuildTag,
+			WhatIf:     apc.whatIf,
 		},
-	}
-	templateGenerator, err := engine.InitializeTemplateGenerator(translator)
-	if err != nil {
-		return errors.Wrap(err, "failed to initialize template generator")
-	}
-
-	apc.containerService.Properties.AgentPoolProfiles = []*api.AgentPoolProfile{apc.nodePool}
-
-	_, err = apc.containerService.SetPropertiesDefaults(api.PropertiesDefaultsParams{
-		IsScale:    true,
-		IsUpgrade:  false,
-		PkiKeySize: helpers.DefaultPkiKeySize,
+		ContainerService: apc.containerService,
+		ResourceGroup:    apc.resourceGroupName,
+		AgentPool:        apc.nodePool,
 	})
 	if err != nil {
-		return errors.Wrapf(err, "error in SetPropertiesDefaults template %s", apc.apiModelPath)
-	}
-	template, parameters, err := templateGenerator.GenerateTemplateV2(apc.containerService, engine.DefaultGeneratorCode, BuildTag)
-	if err != nil {
-		return errors.Wrapf(err, "error generating template %s", apc.apiModelPath)
-	}
-
-	if template, err = transform.PrettyPrintArmTemplate(template); err != nil {
-		return errors.Wrap(err, "error pretty printing template")
-	}
-
-	templateJSON := make(map[string]interface{})
-	parametersJSON := make(map[string]interface{})
-
-	err = json.Unmarshal([]byte(template), &templateJSON)
-	if err != nil {
-		return errors.Wrap(err, "error unmarshaling template")
-	}
-
-	err = json.Unmarshal([]byte(parameters), &parametersJSON)
-	if err != nil {
-		return errors.Wrap(err, "error unmarshaling parameters")
-	}
-
-	// The agent pool is set to index 0 for the scale operation, we need to overwrite the template variables that rely on pool index.
-	if winPoolIndex != -1 {
-		templateJSON["variables"].(map[string]interface{})[apc.nodePool.Name+"Index"] = winPoolIndex
-		templateJSON["variables"].(map[string]interface{})[apc.nodePool.Name+"VMNamePrefix"] = apc.containerService.Properties.GetAgentVMPrefix(apc.nodePool, winPoolIndex)
-	}
-	transformer := transform.Transformer{Translator: translator.Translator}
-
-	if orchestratorInfo.KubernetesConfig.LoadBalancerSku == api.StandardLoadBalancerSku {
-		err = transformer.NormalizeForK8sSLBScalingOrUpgrade(apc.logger, templateJSON)
-		if err != nil {
-			return errors.Wrapf(err, "error transforming the template for scaling with SLB %s", apc.apiModelPath)
-		}
-	}
-
-	if apc.nodePool.IsVirtualMachineScaleSets() {
-		err = transformer.NormalizeForK8sVMASScalingUp(apc.logger, templateJSON)
-		if err != nil {
-			return errors.Wrapf(err, "error transforming the template for scaling template %s", apc.apiModelPath)
-		}
-		addValue(parametersJSON, apc.nodePool.Name+"Count", 0)
-	} else {
-		err = transformer.NormalizeForK8sAddVMASPool(apc.logger, templateJSON)
-		if err != nil {
-			return errors.Wrap(err, "error transforming the template to add a VMAS node pool")
-		}
-	}
-
-	random := rand.New(rand.NewSource(time.Now().UnixNano()))
-	deploymentSuffix := random.Int31()
-
-	_, err = apc.client.DeployTemplate(
-		ctx,
-		apc.resourceGroupName,
-		fmt.Sprintf("%s-%d", apc.resourceGroupName, deploymentSuffix),
-		templateJSON,
-		parametersJSON)
-	if err != nil {
+		operations.PrintCSEFailures(os.Stderr, operations.GetCSEFailures(err, translator))
 		return err
 	}
-	if apc.nodes != nil {
-		nodes, err := operations.GetNodes(apc.client, apc.logger, apc.apiserverURL, apc.kubeconfig, time.Duration(5)*time.Minute, apc.nodePool.Name, apc.nodePool.Count)
-		if err == nil && nodes != nil {
-			apc.nodes = nodes
-			apc.logger.Infof("Nodes in pool '%s' after scaling:\n", apc.nodePool.Name)
-			operations.PrintNodes(apc.nodes)
-		} else {
-			apc.logger.Warningf("Unable to get nodes in pool %s after scaling:\n", apc.nodePool.Name)
-		}
+	if apc.whatIf {
+		return nil
 	}
+	apc.result.addNodesAdded(resp.NodesAdded...)
 
 	return apc.saveAPIModel()
 }
@@ -0,0 +1,148 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	v1 "github.com/Azure/aks-engine/pkg/api/v1"
+	"github.com/Azure/aks-engine/pkg/api/vlabs"
+	"github.com/Azure/aks-engine/pkg/i18n"
+	"github.com/leonelquinteros/gotext"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	convertName             = "convert"
+	convertShortDescription = "Convert an API model to a different API version"
+	convertLongDescription  = "Convert an existing API model, such as the apimodel.json generated by aks-engine, to a different API version"
+)
+
+type convertCmd struct {
+	// user input
+	apiModelPath string
+	toVersion    string
+	outputFile   string
+
+	// derived
+	containerService *api.ContainerService
+	apiVersion       string
+	locale           *gotext.Locale
+}
+
+func newConvertCmd() *cobra.Command {
+	cc := convertCmd{}
+
+	command := &cobra.Command{
+		Use:   convertName,
+		Short: convertShortDescription,
+		Long:  convertLongDescription,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cc.validate(cmd); err != nil {
+				return errors.Wrap(err, "validating convertCmd")
+			}
+			if err := cc.load(); err != nil {
+				return errors.Wrap(err, "loading API model in convertCmd")
+			}
+			return cc.run()
+		},
+	}
+
+	f := command.Flags()
+	f.StringVarP(&cc.apiModelPath, "api-model", "m", "", "path to the API model to convert")
+	f.StringVar(&cc.toVersion, "to-version", v1.APIVersion, fmt.Sprintf("target API version, either %s or %s", v1.APIVersion, vlabs.APIVersion))
+	f.StringVar(&cc.outputFile, "output-file", "", "file to write the converted API model to (stdout if absent)")
+
+	return command
+}
+
+func (cc *convertCmd) validate(cmd *cobra.Command) error {
+	var err error
+
+	cc.locale, err = i18n.LoadTranslations()
+	if err != nil {
+		return errors.Wrap(err, "error loading translation files")
+	}
+
+	if cc.apiModelPath == "" {
+		_ = cmd.Usage()
+		return errors.New("--api-model must be specified")
+	}
+
+	if _, err = os.Stat(cc.apiModelPath); os.IsNotExist(err) {
+		return errors.Errorf("specified api model does not exist (%s)", cc.apiModelPath)
+	}
+
+	if cc.toVersion != v1.APIVersion && cc.toVersion != vlabs.APIVersion {
+		return errors.Errorf("--to-version must be either %s or %s", v1.APIVersion, vlabs.APIVersion)
+	}
+
+	return nil
+}
+
+func (cc *convertCmd) load() error {
+	var err error
+
+	apiloader := &api.Apiloader{
+		Translator: &i18n.Translator{
+			Locale: cc.locale,
+		},
+	}
+	// the API model is loaded as an update so that the orchestrator version is kept as is
+	cc.containerService, cc.apiVersion, err = apiloader.LoadContainerServiceFromFile(cc.apiModelPath, false, true, nil)
+	if err != nil {
+		return errors.Wrap(err, "error parsing the api model")
+	}
+	return nil
+}
+
+func (cc *convertCmd) run() error {
+	if cc.toVersion == v1.APIVersion {
+		cc.warnDeprecatedProperties()
+	}
+
+	apiloader := &api.Apiloader{
+		Translator: &i18n.Translator{
+			Locale: cc.locale,
+		},
+	}
+	b, err := apiloader.SerializeContainerService(cc.containerService, cc.toVersion)
+	if err != nil {
+		return errors.Wrapf(err, "converting API model to %s", cc.toVersion)
+	}
+
+	if cc.outputFile == "" {
+		fmt.Println(string(b))
+		return nil
+	}
+	if err = os.WriteFile(cc.outputFile, b, 0600); err != nil {
+		return errors.Wrapf(err, "writing converted API model to %s", cc.outputFile)
+	}
+	log.Infof("Converted API model from %s to %s in %s", cc.apiVersion, cc.toVersion, cc.outputFile)
+	return nil
+}
+
+// warnDeprecatedProperties logs the deprecated properties that have no equivalent in v1 and are dropped by the conversion
+func (cc *convertCmd) warnDeprecatedProperties() {
+	p := cc.containerService.Properties
+	for _, pool := range p.AgentPoolProfiles {
+		if pool.WindowsNameVersion != "" {
+			log.Warnf("agentPoolProfiles[%s].windowsNameVersion is deprecated and is not part of the %s API, it will be dropped", pool.Name, v1.APIVersion)
+		}
+	}
+	if p.OrchestratorProfile == nil || p.OrchestratorProfile.KubernetesConfig == nil {
+		return
+	}
+	k := p.OrchestratorProfile.KubernetesConfig
+	if k.DockerEngineVersion != "" {
+		log.Warnf("kubernetesConfig.dockerEngineVersion is deprecated and is not part of the %s API, it will be dropped", v1.APIVersion)
+	}
+	if len(k.PodSecurityPolicyConfig) > 0 {
+		log.Warnf("kubernetesConfig.podSecurityPolicyConfig is deprecated in favor of the pod-security-policy addon and is not part of the %s API, it will be dropped", v1.APIVersion)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	v1 "github.com/Azure/aks-engine/pkg/api/v1"
+	"github.com/pkg/errors"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/spf13/cobra"
+)
+
+func TestNewConvertCmd(t *testing.T) {
+	command := newConvertCmd()
+	if command.Use != convertName || command.Short != convertShortDescription || command.Long != convertLongDescription {
+		t.Fatalf("convert command should have use %s equal %s, short %s equal %s and long %s equal to %s", command.Use, convertName, command.Short, convertShortDescription, command.Long, convertLongDescription)
+	}
+
+	expectedFlags := []string{"api-model", "to-version", "output-file"}
+	for _, f := range expectedFlags {
+		if command.Flags().Lookup(f) == nil {
+			t.Fatalf("convert command should have flag %s", f)
+		}
+	}
+
+	command.SetArgs([]string{})
+	if err := command.Execute(); err == nil {
+		t.Fatalf("expected an error when calling convert with no arguments")
+	}
+}
+
+func TestConvertCmdValidate(t *testing.T) {
+	r := &cobra.Command{}
+
+	cases := []struct {
+		cc          *convertCmd
+		expectedErr error
+		name        string
+	}{
+		{
+			cc:          &convertCmd{toVersion: v1.APIVersion},
+			expectedErr: errors.New("--api-model must be specified"),
+			name:        "NoAPIModel",
+		},
+		{
+			cc:          &convertCmd{apiModelPath: "./not/there.json", toVersion: v1.APIVersion},
+			expectedErr: errors.New("specified api model does not exist (./not/there.json)"),
+			name:        "MissingAPIModel",
+		},
+		{
+			cc:          &convertCmd{apiModelPath: "../pkg/engine/testdata/simple/kubernetes.json", toVersion: "2017-07-01"},
+			expectedErr: errors.New("--to-version must be either v1 or vlabs"),
+			name:        "UnknownVersion",
+		},
+		{
+			cc:          &convertCmd{apiModelPath: "../pkg/engine/testdata/simple/kubernetes.json", toVersion: v1.APIVersion},
+			expectedErr: nil,
+			name:        "IsValid",
+		},
+	}
+
+	for _, tc := range cases {
+		c := tc
+		t.Run(c.name, func(t *testing.T) {
+			err := c.cc.validate(r)
+			if err != nil && c.expectedErr != nil {
+				if err.Error() != c.expectedErr.Error() {
+					t.Fatalf("expected validate convert command to return error %s, but instead got %s", c.expectedErr.Error(), err.Error())
+				}
+			} else if c.expectedErr != nil {
+				t.Fatalf("expected validate convert command to return error %s, but instead got no error", c.expectedErr.Error())
+			} else if err != nil {
+				t.Fatalf("expected validate convert command to return no error, but instead got %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestConvertCmdRun(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "apimodel.json")
+	cc := &convertCmd{
+		apiModelPath: "../pkg/engine/testdata/simple/kubernetes.json",
+		toVersion:    v1.APIVersion,
+		outputFile:   outputFile,
+	}
+	if err := cc.validate(&cobra.Command{}); err != nil {
+		t.Fatalf("unexpected error validating convert command: %s", err)
+	}
+	if err := cc.load(); err != nil {
+		t.Fatalf("unexpected error loading the api model: %s", err)
+	}
+	if err := cc.run(); err != nil {
+		t.Fatalf("unexpected error converting the api model: %s", err)
+	}
+
+	b, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("unexpected error reading the converted api model: %s", err)
+	}
+	if !strings.Contains(string(b), `"apiVersion": "v1"`) {
+		t.Errorf("expected the converted api model to have apiVersion v1, got %s", string(b))
+	}
+
+	// the converted api model loads and converts back to vlabs
+	cc = &convertCmd{
+		apiModelPath: outputFile,
+		toVersion:    "vlabs",
+		outputFile:   outputFile,
+	}
+	if err := cc.load(); err != nil {
+		t.Fatalf("unexpected error loading the v1 api model: %s", err)
+	}
+	if cc.apiVersion != v1.APIVersion {
+		t.Errorf("expected loaded apiVersion %s, got %s", v1.APIVersion, cc.apiVersion)
+	}
+	if err := cc.run(); err != nil {
+		t.Fatalf("unexpected error converting the api model back to vlabs: %s", err)
+	}
+}
+
+func TestConvertCmdWarnDeprecatedProperties(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	cc := &convertCmd{
+		containerService: &api.ContainerService{
+			Properties: &api.Properties{
+				AgentPoolProfiles: []*api.AgentPoolProfile{{Name: "agentwin", WindowsNameVersion: "v2"}},
+				OrchestratorProfile: &api.OrchestratorProfile{
+					KubernetesConfig: &api.KubernetesConfig{
+						DockerEngineVersion:     "17.03.*",
+						PodSecurityPolicyConfig: map[string]string{"data": "foo"},
+					},
+				},
+			},
+		},
+	}
+	cc.warnDeprecatedProperties()
+
+	for _, property := range []string{"kubernetesConfig.dockerEngineVersion", "agentPoolProfiles[agentwin].windowsNameVersion", "kubernetesConfig.podSecurityPolicyConfig"} {
+		found := false
+		for _, entry := range hook.AllEntries() {
+			if strings.HasPrefix(entry.Message, property+" is deprecated") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected a warning about the deprecated %s", property)
+		}
+	}
+}
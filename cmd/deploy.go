@@ -7,8 +7,6 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
-	"io"
-	"math/rand"
 	"os"
 	"path"
 	"path/filepath"
@@ -20,14 +18,13 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
-	"encoding/json"
-
 	"github.com/Azure/aks-engine/pkg/api"
 	"github.com/Azure/aks-engine/pkg/armhelpers"
-	"github.com/Azure/aks-engine/pkg/engine"
+	"github.com/Azure/aks-engine/pkg/cluster"
 	"github.com/Azure/aks-engine/pkg/engine/transform"
 	"github.com/Azure/aks-engine/pkg/helpers"
 	"github.com/Azure/aks-engine/pkg/i18n"
+	"github.com/Azure/aks-engine/pkg/operations"
 	"github.com/Azure/azure-sdk-for-go/services/graphrbac/1.6/graphrbac"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
@@ -41,6 +38,9 @@ const (
 
 type deployCmd struct {
 	authProvider
+	progressArgs
+	whatIfArgs
+	outputArgs
 	apimodelPath      string
 	dnsPrefix         string
 	autoSuffix        bool
@@ -58,7 +58,6 @@ type deployCmd struct {
 
 	client        armhelpers.AKSEngineClient
 	resourceGroup string
-	random        *rand.Rand
 	location      string
 }
 
@@ -72,23 +71,26 @@ func newDeployCmd() *cobra.Command {
 		Short: deployShortDescription,
 		Long:  deployLongDescription,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := dc.validateArgs(cmd, args); err != nil {
-				return errors.Wrap(err, "validating deployCmd")
-			}
-			if err := dc.mergeAPIModel(); err != nil {
-				return errors.Wrap(err, "merging API model in deployCmd")
-			}
-			if err := dc.loadAPIModel(); err != nil {
-				return errors.Wrap(err, "loading API model")
-			}
-			if dc.apiVersion == "vlabs" {
-				if err := dc.validateAPIModelAsVLabs(); err != nil {
-					return errors.Wrap(err, "validating API model after populating values")
+			return dc.runWithResult(cmd, func() error {
+				if err := dc.validateArgs(cmd, args); err != nil {
+					return errors.Wrap(err, "validating deployCmd")
 				}
-			} else {
-				log.Warnf("API model validation is only available for \"apiVersion\": \"vlabs\", skipping validation...")
-			}
-			return dc.run()
+				if err := dc.mergeAPIModel(); err != nil {
+					return errors.Wrap(err, "merging API model in deployCmd")
+				}
+				if err := dc.loadAPIModel(); err != nil {
+					return errors.Wrap(err, "loading API model")
+				}
+				if dc.apiVersion == "vlabs" || dc.apiVersion == "v1" {
+					if err := dc.validateAPIModelAsVLabs(); err != nil {
+						logValidationErrors(err, &i18n.Translator{Locale: dc.locale})
+						return errors.Wrap(err, "validating API model after populating values")
+					}
+				} else {
+					log.Warnf("API model validation is only available for \"apiVersion\": \"vlabs\" and \"v1\", skipping validation...")
+				}
+				return dc.run()
+			})
 		},
 	}
 
@@ -105,6 +107,10 @@ func newDeployCmd() *cobra.Command {
 	f.StringArrayVar(&dc.set, "set", []string{}, "set values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
 
 	addAuthFlags(dc.getAuthArgs(), f)
+	addProgressFlags(&dc.progressArgs, f)
+	addWhatIfFlag(&dc.whatIfArgs, f)
+	// -o is the shorthand of --output-directory
+	addOutputFlag(&dc.outputArgs, f, "")
 
 	return deployCmd
 }
@@ -264,16 +270,21 @@ func (dc *deployCmd) loadAPIModel() error {
 		return err
 	}
 
+	if err = dc.validateProgressArgs(); err != nil {
+		return err
+	}
+
 	dc.client, err = dc.authProvider.getClient()
 	if err != nil {
 		return errors.Wrap(err, "failed to get client")
 	}
+	dc.client = dc.withDeploymentProgress(dc.client)
+	dc.client = dc.withResultRecording(dc.client, dc.getAuthArgs().SubscriptionID.String())
 
 	if err = autofillApimodel(dc); err != nil {
 		return err
 	}
-
-	dc.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	dc.result.ResourceGroup = dc.resourceGroup
 
 	return nil
 }
@@ -336,9 +347,13 @@ func autofillApimodel(dc *deployCmd) error {
 
 	ctx, cancel := context.WithTimeout(context.Background(), armhelpers.DefaultARMOperationTimeout)
 	defer cancel()
-	_, err := dc.client.EnsureResourceGroup(ctx, dc.resourceGroup, dc.location, nil)
-	if err != nil {
-		return err
+	var err error
+	// --what-if reports a missing resource group instead of creating it
+	if !dc.whatIf {
+		_, err = dc.client.EnsureResourceGroup(ctx, dc.resourceGroup, dc.location, nil)
+		if err != nil {
+			return err
+		}
 	}
 
 	k8sConfig := dc.containerService.Properties.OrchestratorProfile.KubernetesConfig
@@ -347,7 +362,10 @@ func autofillApimodel(dc *deployCmd) error {
 
 	if !useManagedIdentity {
 		spp := dc.containerService.Properties.ServicePrincipalProfile
-		if spp != nil && spp.ClientID == "" && spp.Secret == "" && spp.KeyvaultSecretRef == nil && (dc.getAuthArgs().ClientID.String() == "" || dc.getAuthArgs().ClientID.String() == "00000000-0000-0000-0000-000000000000") && dc.getAuthArgs().ClientSecret == "" {
+		missingServicePrincipal := spp != nil && spp.ClientID == "" && spp.Secret == "" && spp.KeyvaultSecretRef == nil && (dc.getAuthArgs().ClientID.String() == "" || dc.getAuthArgs().ClientID.String() == "00000000-0000-0000-0000-000000000000") && dc.getAuthArgs().ClientSecret == ""
+		if missingServicePrincipal && dc.whatIf {
+			log.Warnln("apimodel: ServicePrincipalProfile was missing or empty, no application is created with --what-if")
+		} else if missingServicePrincipal {
 			log.Warnln("apimodel: ServicePrincipalProfile was missing or empty, creating application...")
 
 			// TODO: consider caching the creds here so they persist between subsequent runs of 'deploy'
@@ -405,85 +423,26 @@ func (dc *deployCmd) validateAPIModelAsVLabs() error {
 }
 
 func (dc *deployCmd) run() error {
-	ctx := engine.Context{
-		Translator: &i18n.Translator{
-			Locale: dc.locale,
+	translator := &i18n.Translator{Locale: dc.locale}
+	_, err := cluster.Deploy(context.Background(), &cluster.DeployRequest{
+		Options: cluster.Options{
+			Client:     dc.client,
+			Logger:     log.NewEntry(log.StandardLogger()),
+			Translator: translator,
+			Output:     dc.textOutput(),
+			BuildTag:   BuildTag,
+			WhatIf:     dc.whatIf,
 		},
-	}
-
-	templateGenerator, err := engine.InitializeTemplateGenerator(ctx)
-	if err != nil {
-		return errors.Wrap(err, "initializing template generator")
-	}
-
-	certsgenerated, err := dc.containerService.SetPropertiesDefaults(api.PropertiesDefaultsParams{
-		IsScale:    false,
-		IsUpgrade:  false,
-		PkiKeySize: helpers.DefaultPkiKeySize,
+		ContainerService: dc.containerService,
+		APIVersion:       dc.apiVersion,
+		ResourceGroup:    dc.resourceGroup,
+		OutputDirectory:  dc.outputDirectory,
+		ParametersOnly:   dc.parametersOnly,
 	})
 	if err != nil {
-		return errors.Wrapf(err, "in SetPropertiesDefaults template %s", dc.apimodelPath)
-	}
-
-	if dc.containerService.Properties.IsAzureStackCloud() {
-		if err = dc.validateOSBaseImage(); err != nil {
-			return errors.Wrapf(err, "validating OS base images required by %s", dc.apimodelPath)
-		}
-	}
-
-	template, parameters, err := templateGenerator.GenerateTemplateV2(dc.containerService, engine.DefaultGeneratorCode, BuildTag)
-	if err != nil {
-		return errors.Wrapf(err, "generating template %s", dc.apimodelPath)
-	}
-
-	if template, err = transform.PrettyPrintArmTemplate(template); err != nil {
-		return errors.Wrap(err, "pretty-printing template")
-	}
-	var parametersFile string
-	if parametersFile, err = transform.BuildAzureParametersFile(parameters); err != nil {
-		return errors.Wrap(err, "pretty-printing template parameters")
-	}
-
-	writer := &engine.ArtifactWriter{
-		Translator: &i18n.Translator{
-			Locale: dc.locale,
-		},
-	}
-	if err = writer.WriteTLSArtifacts(dc.containerService, dc.apiVersion, template, parametersFile, dc.outputDirectory, certsgenerated, dc.parametersOnly); err != nil {
-		return errors.Wrap(err, "writing artifacts")
-	}
-
-	templateJSON := make(map[string]interface{})
-	parametersJSON := make(map[string]interface{})
-
-	if err = json.Unmarshal([]byte(template), &templateJSON); err != nil {
+		operations.PrintCSEFailures(os.Stderr, operations.GetCSEFailures(err, translator))
 		return err
 	}
-
-	if err = json.Unmarshal([]byte(parameters), &parametersJSON); err != nil {
-		return err
-	}
-
-	cx, cancel := context.WithTimeout(context.Background(), armhelpers.DefaultARMOperationTimeout)
-	defer cancel()
-
-	deploymentSuffix := dc.random.Int31()
-
-	if res, err := dc.client.DeployTemplate(
-		cx,
-		dc.resourceGroup,
-		fmt.Sprintf("%s-%d", dc.resourceGroup, deploymentSuffix),
-		templateJSON,
-		parametersJSON,
-	); err != nil {
-		if res.Response.Response != nil && res.Body != nil {
-			defer res.Body.Close()
-			body, _ := io.ReadAll(res.Body)
-			log.Errorf(string(body))
-		}
-		return err
-	}
-
 	return nil
 }
 
@@ -559,13 +518,3 @@ func (dc *deployCmd) configureContainerMonitoringAddon(ctx context.Context, k8sC
 	}
 	return nil
 }
-
-// validateOSBaseImage checks if the OS image is available on the target cloud (ATM, Azure Stack only)
-func (dc *deployCmd) validateOSBaseImage() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	if err := armhelpers.ValidateRequiredImages(ctx, dc.location, dc.containerService.Properties, dc.client); err != nil {
-		return errors.Wrap(err, "OS base image not available in target cloud")
-	}
-	return nil
-}
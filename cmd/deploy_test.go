@@ -284,6 +284,53 @@ func TestAutofillApimodelAllowsPrespecifiedCreds(t *testing.T) {
 	testAutodeployCredentialHandling(t, false, "clientID", "clientSecret")
 }
 
+func TestAutofillApimodelWhatIfSkipsResourceGroup(t *testing.T) {
+	t.Parallel()
+
+	apiloader := &api.Apiloader{
+		Translator: nil,
+	}
+
+	apimodel := getExampleAPIModel(false, "clientID", "clientSecret")
+	cs, ver, err := apiloader.DeserializeContainerService([]byte(apimodel), false, false, nil)
+	if err != nil {
+		t.Fatalf("unexpected error deserializing the example apimodel: %s", err)
+	}
+
+	outDir, del := makeTmpDir(t)
+	defer del()
+
+	deployCmd := &deployCmd{
+		apimodelPath:    "./this/is/unused.json",
+		dnsPrefix:       "dnsPrefix1",
+		outputDirectory: outDir,
+		forceOverwrite:  true,
+		location:        "westus",
+		whatIfArgs:      whatIfArgs{whatIf: true},
+
+		containerService: cs,
+		apiVersion:       ver,
+
+		client: &armhelpers.MockAKSEngineClient{FailEnsureResourceGroup: true},
+		authProvider: &mockAuthProvider{
+			authArgs: &authArgs{},
+		},
+	}
+
+	err = autofillApimodel(deployCmd)
+	if err != nil {
+		t.Fatalf("expected --what-if not to ensure the resource group, got error: %s", err)
+	}
+
+	// the first call filled masterProfile.dnsPrefix
+	deployCmd.dnsPrefix = ""
+	deployCmd.whatIf = false
+	err = autofillApimodel(deployCmd)
+	if err == nil || err.Error() != "EnsureResourceGroup failed" {
+		t.Fatalf("expected the resource group to be ensured without --what-if, got error: %v", err)
+	}
+}
+
 func TestAutoSufixWithDnsPrefixInApiModel(t *testing.T) {
 	t.Parallel()
 
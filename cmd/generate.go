@@ -5,11 +5,14 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path"
 
 	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/cluster"
 	"github.com/Azure/aks-engine/pkg/engine"
+	"github.com/Azure/aks-engine/pkg/engine/templatediff"
 	"github.com/Azure/aks-engine/pkg/engine/transform"
 	"github.com/Azure/aks-engine/pkg/helpers"
 	"github.com/Azure/aks-engine/pkg/i18n"
@@ -35,6 +38,7 @@ type generateCmd struct {
 	noPrettyPrint     bool
 	parametersOnly    bool
 	set               []string
+	diffAgainst       string
 
 	// derived
 	containerService *api.ContainerService
@@ -66,12 +70,13 @@ func newGenerateCmd() *cobra.Command {
 			if err := gc.loadAPIModel(); err != nil {
 				return errors.Wrap(err, "loading API model in generateCmd")
 			}
-			if gc.apiVersion == "vlabs" {
+			if gc.apiVersion == "vlabs" || gc.apiVersion == "v1" {
 				if err := gc.validateAPIModelAsVLabs(); err != nil {
+					logValidationErrors(err, &i18n.Translator{Locale: gc.locale})
 					return errors.Wrap(err, "validating API model after populating values")
 				}
 			} else {
-				log.Warnf("API model validation is only available for \"apiVersion\": \"vlabs\", skipping validation...")
+				log.Warnf("API model validation is only available for \"apiVersion\": \"vlabs\" and \"v1\", skipping validation...")
 			}
 			return gc.run()
 		},
@@ -87,6 +92,7 @@ func newGenerateCmd() *cobra.Command {
 	f.BoolVar(&gc.parametersOnly, "parameters-only", false, "only output parameters files")
 	f.StringVar(&gc.rawClientID, "client-id", "", "client id")
 	f.StringVar(&gc.ClientSecret, "client-secret", "", "client secret")
+	f.StringVar(&gc.diffAgainst, "diff-against", "", "print the changes from the template and parameters previously generated in this directory, instead of writing the artifacts")
 	return generateCmd
 }
 
@@ -114,6 +120,12 @@ func (gc *generateCmd) validate(cmd *cobra.Command, args []string) error {
 		return errors.Errorf("specified api model does not exist (%s)", gc.apimodelPath)
 	}
 
+	if gc.diffAgainst != "" {
+		if _, err := os.Stat(path.Join(gc.diffAgainst, "azuredeploy.json")); err != nil {
+			return errors.Errorf("--diff-against: no template was generated in %s", gc.diffAgainst)
+		}
+	}
+
 	gc.ClientID, _ = uuid.Parse(gc.rawClientID)
 
 	return nil
@@ -229,7 +241,9 @@ func (gc *generateCmd) validateAPIModelAsVLabs() error {
 }
 
 func (gc *generateCmd) run() error {
-	log.Infoln(fmt.Sprintf("Generating assets into %s...", gc.outputDirectory))
+	if gc.diffAgainst == "" {
+		log.Infoln(fmt.Sprintf("Generating assets into %s...", gc.outputDirectory))
+	}
 
 	ctx := engine.Context{
 		Translator: &i18n.Translator{
@@ -249,16 +263,25 @@ func (gc *generateCmd) run() error {
 	if err != nil {
 		return errors.Wrapf(err, "in SetPropertiesDefaults template %s", gc.apimodelPath)
 	}
+	cluster.LogRequiredEgressFQDNs(log.NewEntry(log.StandardLogger()), gc.containerService)
 
 	//TODO remove these debug statements when we're new template generation implementation is enabled!
 	//bts, _ := json.Marshal(gc.containerService)
 	//log.Info(string(bts))
 
+	if err = engine.ResolveCustomAddons(gc.containerService); err != nil {
+		return errors.Wrapf(err, "resolving custom addons %s", gc.apimodelPath)
+	}
+
 	template, parameters, err := templateGenerator.GenerateTemplateV2(gc.containerService, engine.DefaultGeneratorCode, BuildTag)
 	if err != nil {
 		return errors.Wrapf(err, "generating template %s", gc.apimodelPath)
 	}
 
+	if gc.diffAgainst != "" {
+		return gc.printDiff(os.Stdout, template, parameters)
+	}
+
 	if !gc.noPrettyPrint {
 		if template, err = transform.PrettyPrintArmTemplate(template); err != nil {
 			return errors.Wrap(err, "pretty-printing template")
@@ -279,3 +302,25 @@ func (gc *generateCmd) run() error {
 
 	return nil
 }
+
+// printDiff prints the changes from the template and parameters generated in the --diff-against directory
+func (gc *generateCmd) printDiff(w io.Writer, template, parameters string) error {
+	beforeTemplate, err := os.ReadFile(path.Join(gc.diffAgainst, "azuredeploy.json"))
+	if err != nil {
+		return errors.Wrap(err, "reading the template to diff against")
+	}
+	beforeParameters, err := os.ReadFile(path.Join(gc.diffAgainst, "azuredeploy.parameters.json"))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "reading the template parameters to diff against")
+	}
+	before, err := templatediff.LoadTemplate(beforeTemplate, beforeParameters)
+	if err != nil {
+		return errors.Wrapf(err, "loading the template generated in %s", gc.diffAgainst)
+	}
+	after, err := templatediff.LoadTemplate([]byte(template), []byte(parameters))
+	if err != nil {
+		return errors.Wrap(err, "loading the generated template")
+	}
+	templatediff.Compare(before, after).Print(w)
+	return nil
+}
@@ -4,6 +4,10 @@
 package cmd
 
 import (
+	"bytes"
+	"os"
+	"path"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -21,7 +25,7 @@ func TestNewGenerateCmd(t *testing.T) {
 		t.Fatalf("generate command should have use %s equal %s, short %s equal %s and long %s equal to %s", command.Use, generateName, command.Short, generateShortDescription, command.Long, generateLongDescription)
 	}
 
-	expectedFlags := []string{"api-model", "output-directory", "ca-certificate-path", "ca-private-key-path", "set", "no-pretty-print", "parameters-only", "client-id", "client-secret"}
+	expectedFlags := []string{"api-model", "output-directory", "ca-certificate-path", "ca-private-key-path", "set", "no-pretty-print", "parameters-only", "client-id", "client-secret", "diff-against"}
 	for _, f := range expectedFlags {
 		if command.Flags().Lookup(f) == nil {
 			t.Fatalf("generate command should have flag %s", f)
@@ -62,6 +66,14 @@ func TestGenerateCmdValidate(t *testing.T) {
 		t.Fatalf("expected error validating multiple args")
 	}
 
+	g = &generateCmd{diffAgainst: "../pkg/engine/testdata/simple"}
+
+	// validate cmd diffing against a directory without a template
+	err = g.validate(r, []string{"../pkg/engine/testdata/simple/kubernetes.json"})
+	if err == nil {
+		t.Fatalf("expected error validating --diff-against without a template")
+	}
+
 }
 
 func TestGenerateCmdMergeAPIModel(t *testing.T) {
@@ -884,3 +896,38 @@ func TestExampleAPIModels(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateCmdPrintDiff(t *testing.T) {
+	t.Parallel()
+
+	dir, err := os.MkdirTemp("", "aks-engine-diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	template := `{"parameters": {"kubernetesVersion": {"type": "string"}}, "variables": {"maxVMsPerPool": 100}, "resources": []}`
+	parameters := `{"parameters": {"kubernetesVersion": {"value": "1.23.17"}}}`
+	if err = os.WriteFile(path.Join(dir, "azuredeploy.json"), []byte(template), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(path.Join(dir, "azuredeploy.parameters.json"), []byte(parameters), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	g := &generateCmd{diffAgainst: dir}
+	var out bytes.Buffer
+	if err = g.printDiff(&out, template, parameters); err != nil {
+		t.Fatalf("unexpected error printing the diff: %s", err)
+	}
+	if out.String() != "No changes.\n" {
+		t.Fatalf("expected no changes, got %q", out.String())
+	}
+
+	out.Reset()
+	if err = g.printDiff(&out, template, `{"parameters": {"kubernetesVersion": {"value": "1.24.17"}}}`); err != nil {
+		t.Fatalf("unexpected error printing the diff: %s", err)
+	}
+	if !strings.Contains(out.String(), `~ kubernetesVersion: "1.23.17" => "1.24.17"`) {
+		t.Fatalf("expected the kubernetesVersion parameter to change, got %q", out.String())
+	}
+}
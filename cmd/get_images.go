@@ -0,0 +1,242 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/engine"
+	"github.com/Azure/aks-engine/pkg/helpers"
+	"github.com/Azure/aks-engine/pkg/i18n"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	getImagesName             = "get-images"
+	getImagesShortDescription = "Display the container images and files a cluster downloads"
+	getImagesLongDescription  = "Display the container images and files the nodes of a cluster download while they are provisioned, to mirror them for air-gapped clusters. With --registry and --file-mirror, the container images and files are pointed at a private registry and file server and the updated API model is written to --output-api-model"
+)
+
+var getImagesOutputFormatOptions = append(outputFormatOptions, "list")
+
+type getImagesCmd struct {
+	// user input
+	apiModelPath       string
+	location           string
+	registry           string
+	fileMirror         string
+	outputAPIModelPath string
+	output             string
+
+	// derived
+	cs         *api.ContainerService
+	apiVersion string
+	loader     *api.Apiloader
+}
+
+func newGetImagesCmd() *cobra.Command {
+	gic := getImagesCmd{}
+
+	command := &cobra.Command{
+		Use:   getImagesName,
+		Short: getImagesShortDescription,
+		Long:  getImagesLongDescription,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := gic.validateArgs(); err != nil {
+				return errors.Wrap(err, "validating get-images args")
+			}
+			if err := gic.loadAPIModel(); err != nil {
+				return errors.Wrap(err, "loading API model")
+			}
+			return gic.run(os.Stdout)
+		},
+	}
+
+	f := command.Flags()
+	f.StringVarP(&gic.apiModelPath, "api-model", "m", "", "path to the cluster definition file (required)")
+	f.StringVarP(&gic.location, "location", "l", "", "Azure location of the cluster, if not set in the API model (optional)")
+	f.StringVar(&gic.registry, "registry", "", "private container registry, e.g. myregistry.azurecr.io, that mirrors the cluster images (optional)")
+	f.StringVar(&gic.fileMirror, "file-mirror", "", "https URL of a file server, e.g. https://mirror.example.com/aks, that mirrors the cluster files (optional)")
+	f.StringVar(&gic.outputAPIModelPath, "output-api-model", "", "path to write the API model pointing at --registry and --file-mirror (required with --registry or --file-mirror)")
+	getImagesCmdDescription := fmt.Sprintf("Output format. Allowed values: %s",
+		strings.Join(getImagesOutputFormatOptions, ", "))
+	f.StringVarP(&gic.output, "output", "o", "human", getImagesCmdDescription)
+
+	return command
+}
+
+func (gic *getImagesCmd) validateArgs() error {
+	if gic.apiModelPath == "" {
+		return errors.New("--api-model must be specified")
+	}
+	if _, err := os.Stat(gic.apiModelPath); os.IsNotExist(err) {
+		return errors.Errorf("specified --api-model does not exist (%s)", gic.apiModelPath)
+	}
+	if (gic.registry != "" || gic.fileMirror != "") && gic.outputAPIModelPath == "" {
+		return errors.New("--output-api-model must be specified with --registry or --file-mirror")
+	}
+	if gic.registry == "" && gic.fileMirror == "" && gic.outputAPIModelPath != "" {
+		return errors.New("--registry or --file-mirror must be specified with --output-api-model")
+	}
+	if strings.Contains(gic.registry, "://") {
+		return errors.Errorf("--registry must be a registry host and optional path, not a URL (%s)", gic.registry)
+	}
+	if gic.fileMirror != "" {
+		if u, err := url.Parse(gic.fileMirror); err != nil || u.Scheme != "https" || u.Host == "" || u.RawQuery != "" {
+			return errors.Errorf("--file-mirror must be an https URL without query (%s)", gic.fileMirror)
+		}
+	}
+	for _, o := range getImagesOutputFormatOptions {
+		if gic.output == o {
+			return nil
+		}
+	}
+	return errors.Errorf(`output format "%s" is not supported`, gic.output)
+}
+
+func (gic *getImagesCmd) loadAPIModel() (err error) {
+	locale, err := i18n.LoadTranslations()
+	if err != nil {
+		return errors.Wrap(err, "error loading translation files")
+	}
+	gic.loader = &api.Apiloader{
+		Translator: &i18n.Translator{
+			Locale: locale,
+		},
+	}
+	if gic.cs, gic.apiVersion, err = gic.loader.LoadContainerServiceFromFile(gic.apiModelPath, false, false, nil); err != nil {
+		return errors.Wrap(err, "error parsing api-model")
+	}
+	return gic.setDefaults(gic.cs)
+}
+
+// setDefaults applies to cs the defaults that aks-engine generate applies
+func (gic *getImagesCmd) setDefaults(cs *api.ContainerService) (err error) {
+	if cs.Location == "" {
+		cs.Location = helpers.NormalizeAzureRegion(gic.location)
+	}
+	if cs.Properties.IsCustomCloudProfile() {
+		if err = writeCustomCloudProfile(cs); err != nil {
+			return errors.Wrap(err, "error writing custom cloud profile")
+		}
+		if err = cs.Properties.SetCustomCloudSpec(api.AzureCustomCloudSpecParams{IsUpgrade: false, IsScale: false}); err != nil {
+			return errors.Wrap(err, "error parsing the api model")
+		}
+	}
+	if _, err = cs.SetPropertiesDefaults(api.PropertiesDefaultsParams{
+		IsScale:    false,
+		IsUpgrade:  false,
+		PkiKeySize: helpers.DefaultPkiKeySize,
+	}); err != nil {
+		return errors.Wrapf(err, "error in SetPropertiesDefaults template %s", gic.apiModelPath)
+	}
+	return engine.ResolveCustomAddons(cs)
+}
+
+func (gic *getImagesCmd) run(out io.Writer) error {
+	artifacts := gic.cs.GetClusterArtifacts()
+	if gic.outputAPIModelPath == "" {
+		return printClusterArtifacts(out, artifacts, gic.output)
+	}
+
+	mirrors := map[string]string{}
+	for _, m := range append(gic.cs.SetContainerImageRegistry(gic.registry), gic.cs.SetFileMirror(gic.fileMirror)...) {
+		mirrors[m.Reference] = m.Mirror
+	}
+	var unmirrored []string
+	for i := range artifacts {
+		artifacts[i].Mirror = mirrors[artifacts[i].Reference]
+		if artifacts[i].Mirror == "" {
+			unmirrored = append(unmirrored, artifacts[i].Reference)
+		}
+	}
+	if err := gic.saveAPIModel(); err != nil {
+		return errors.Wrap(err, "writing the API model")
+	}
+	log.Infof("API model pointing at the mirrors written to %s", gic.outputAPIModelPath)
+	if len(unmirrored) > 0 {
+		log.Warnf("the cluster nodes still download these artifacts from their sources, use --registry and --file-mirror to mirror them: %s", strings.Join(unmirrored, ", "))
+	}
+	if k := gic.cs.Properties.OrchestratorProfile.KubernetesConfig; k.CustomKubeBinaryURL != "" || k.CustomWindowsPackageURL != "" {
+		log.Warnf("the node binaries URLs are pinned to Kubernetes %s, run get-images again with the new version before upgrading the cluster", gic.cs.Properties.OrchestratorProfile.OrchestratorVersion)
+	}
+	return printClusterArtifacts(out, artifacts, gic.output)
+}
+
+// saveAPIModel writes the API model as provided by the user, pointed at the mirrors. The image bases and the artifacts set
+// by the user are rewritten, and the artifacts that the defaults do not derive from them are set explicitly.
+func (gic *getImagesCmd) saveAPIModel() error {
+	cs, _, err := gic.loader.LoadContainerServiceFromFile(gic.apiModelPath, false, false, nil)
+	if err != nil {
+		return errors.Wrap(err, "error parsing api-model")
+	}
+	if cs.Properties.OrchestratorProfile.KubernetesConfig == nil {
+		cs.Properties.OrchestratorProfile.KubernetesConfig = &api.KubernetesConfig{}
+	}
+	cs.SetContainerImageRegistry(gic.registry)
+	b, err := gic.loader.SerializeContainerService(cs, gic.apiVersion)
+	if err != nil {
+		return err
+	}
+	defaulted, err := gic.loader.LoadContainerService(b, gic.apiVersion, false, false, nil)
+	if err != nil {
+		return err
+	}
+	if err = gic.setDefaults(defaulted); err != nil {
+		return err
+	}
+	cs.SetMirroredArtifacts(defaulted, gic.cs)
+
+	if b, err = gic.loader.SerializeContainerService(cs, gic.apiVersion); err != nil {
+		return err
+	}
+	f := helpers.FileSaver{
+		Translator: gic.loader.Translator,
+	}
+	dir, file := filepath.Split(gic.outputAPIModelPath)
+	return f.SaveFile(dir, file, b)
+}
+
+func printClusterArtifacts(out io.Writer, artifacts []api.ClusterArtifact, output string) error {
+	switch output {
+	case "json":
+		data, err := helpers.JSONMarshalIndent(artifacts, "", "  ", false)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(data))
+	case "list":
+		// one artifact per line, followed by its mirror if there is one, to script the mirroring
+		seen := map[string]bool{}
+		for _, a := range artifacts {
+			if !seen[a.Reference] {
+				seen[a.Reference] = true
+				fmt.Fprintln(out, strings.TrimSpace(a.Reference+" "+a.Mirror))
+			}
+		}
+	case "human":
+		w := tabwriter.NewWriter(out, 0, 4, 1, ' ', tabwriter.FilterHTML)
+		fmt.Fprintln(w, "Type\tComponent\tReference\tMirror")
+		for _, a := range artifacts {
+			mirror := a.Mirror
+			if mirror == "" {
+				mirror = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", a.Type, a.Component, a.Reference, mirror)
+		}
+		return w.Flush()
+	default:
+		return errors.Errorf(`output format "%s" is not supported`, output)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+func TestGetImagesCmd(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	command := newGetImagesCmd()
+	g.Expect(command.Use).Should(Equal(getImagesName))
+	g.Expect(command.Short).Should(Equal(getImagesShortDescription))
+	g.Expect(command.Long).Should(Equal(getImagesLongDescription))
+	for _, flag := range []string{"api-model", "location", "registry", "file-mirror", "output-api-model", "output"} {
+		g.Expect(command.Flags().Lookup(flag)).NotTo(BeNil(), flag)
+	}
+}
+
+func TestGetImagesCmdValidateArgs(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	existingFile := "../examples/kubernetes.json"
+	missingFile := "./random/file"
+
+	cases := []struct {
+		gic         *getImagesCmd
+		expectedErr error
+		name        string
+	}{
+		{
+			gic:         &getImagesCmd{output: "human"},
+			expectedErr: errors.New("--api-model must be specified"),
+			name:        "NeedsAPIModel",
+		},
+		{
+			gic:         &getImagesCmd{apiModelPath: missingFile, output: "human"},
+			expectedErr: errors.Errorf("specified --api-model does not exist (%s)", missingFile),
+			name:        "BadAPIModel",
+		},
+		{
+			gic:         &getImagesCmd{apiModelPath: existingFile, registry: "myregistry.azurecr.io", output: "human"},
+			expectedErr: errors.New("--output-api-model must be specified with --registry or --file-mirror"),
+			name:        "RegistryNeedsOutputAPIModel",
+		},
+		{
+			gic:         &getImagesCmd{apiModelPath: existingFile, outputAPIModelPath: "apimodel.json", output: "human"},
+			expectedErr: errors.New("--registry or --file-mirror must be specified with --output-api-model"),
+			name:        "OutputAPIModelNeedsRegistry",
+		},
+		{
+			gic:         &getImagesCmd{apiModelPath: existingFile, registry: "https://myregistry.azurecr.io", outputAPIModelPath: "apimodel.json", output: "human"},
+			expectedErr: errors.New("--registry must be a registry host and optional path, not a URL (https://myregistry.azurecr.io)"),
+			name:        "RegistryIsNotURL",
+		},
+		{
+			gic:         &getImagesCmd{apiModelPath: existingFile, fileMirror: "http://mirror.example.com", outputAPIModelPath: "apimodel.json", output: "human"},
+			expectedErr: errors.New("--file-mirror must be an https URL without query (http://mirror.example.com)"),
+			name:        "FileMirrorIsHTTPS",
+		},
+		{
+			gic:         &getImagesCmd{apiModelPath: existingFile, output: "yaml"},
+			expectedErr: errors.New(`output format "yaml" is not supported`),
+			name:        "BadOutput",
+		},
+		{
+			gic:  &getImagesCmd{apiModelPath: existingFile, registry: "myregistry.azurecr.io", outputAPIModelPath: "apimodel.json", output: "list"},
+			name: "IsValid",
+		},
+		{
+			gic:  &getImagesCmd{apiModelPath: existingFile, fileMirror: "https://mirror.example.com/aks", outputAPIModelPath: "apimodel.json", output: "list"},
+			name: "FileMirrorIsValid",
+		},
+	}
+
+	for _, tc := range cases {
+		c := tc
+		t.Run(c.name, func(t *testing.T) {
+			err := c.gic.validateArgs()
+			if c.expectedErr != nil {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(Equal(c.expectedErr.Error()))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestGetImagesCmdRun(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	outputAPIModelPath := filepath.Join(t.TempDir(), "apimodel.json")
+	gic := &getImagesCmd{
+		apiModelPath:       "../examples/kubernetes.json",
+		location:           "westus2",
+		registry:           "myregistry.azurecr.io",
+		fileMirror:         "https://mirror.example.com/aks",
+		outputAPIModelPath: outputAPIModelPath,
+		output:             "json",
+	}
+	g.Expect(gic.loadAPIModel()).To(Succeed())
+	var out bytes.Buffer
+	g.Expect(gic.run(&out)).To(Succeed())
+
+	var artifacts []api.ClusterArtifact
+	g.Expect(json.Unmarshal(out.Bytes(), &artifacts)).To(Succeed())
+	g.Expect(artifacts).NotTo(BeEmpty())
+	for _, a := range artifacts {
+		if a.Type == api.ClusterArtifactTypeImage {
+			g.Expect(a.Mirror).To(HavePrefix("myregistry.azurecr.io/"), a.Reference)
+		} else {
+			g.Expect(a.Mirror).To(HavePrefix("https://mirror.example.com/aks/"), a.Reference)
+		}
+	}
+
+	b, err := os.ReadFile(outputAPIModelPath)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(b)).To(ContainSubstring(`"privateAzureRegistryServer": "myregistry.azurecr.io"`))
+	g.Expect(string(b)).To(ContainSubstring(`"mcrKubernetesImageBase": "myregistry.azurecr.io/"`))
+	g.Expect(string(b)).To(ContainSubstring(`"etcdDownloadURLBase": "myregistry.azurecr.io/oss/etcd-io/"`))
+	g.Expect(string(b)).To(ContainSubstring(`"cniPluginsURL": "https://mirror.example.com/aks/`))
+	// the API model is written without its defaults
+	g.Expect(string(b)).NotTo(ContainSubstring("caCertificate"))
+	g.Expect(string(b)).NotTo(ContainSubstring("kube-apiserver"))
+
+	// generating the written API model pulls every artifact from the mirrors
+	written := &getImagesCmd{apiModelPath: outputAPIModelPath, location: "westus2", output: "json"}
+	g.Expect(written.loadAPIModel()).To(Succeed())
+	for _, a := range written.cs.GetClusterArtifacts() {
+		if a.Type == api.ClusterArtifactTypeImage {
+			g.Expect(a.Reference).To(HavePrefix("myregistry.azurecr.io/"))
+		} else {
+			g.Expect(a.Reference).To(HavePrefix("https://mirror.example.com/aks/"))
+		}
+	}
+}
+
+func TestPrintClusterArtifacts(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	artifacts := []api.ClusterArtifact{
+		{Type: api.ClusterArtifactTypeFile, Component: "cni-plugins", Reference: "https://example.com/cni-plugins.tgz"},
+		{Type: api.ClusterArtifactTypeImage, Component: "pause", Reference: "mcr.microsoft.com/oss/kubernetes/pause:3.4.1", Mirror: "myregistry.azurecr.io/oss/kubernetes/pause:3.4.1"},
+		{Type: api.ClusterArtifactTypeImage, Component: "windows-pause", Reference: "mcr.microsoft.com/oss/kubernetes/pause:3.4.1", Mirror: "myregistry.azurecr.io/oss/kubernetes/pause:3.4.1"},
+	}
+
+	var out bytes.Buffer
+	g.Expect(printClusterArtifacts(&out, artifacts, "list")).To(Succeed())
+	g.Expect(strings.Split(strings.TrimSpace(out.String()), "\n")).To(Equal([]string{
+		"https://example.com/cni-plugins.tgz",
+		"mcr.microsoft.com/oss/kubernetes/pause:3.4.1 myregistry.azurecr.io/oss/kubernetes/pause:3.4.1",
+	}))
+
+	out.Reset()
+	g.Expect(printClusterArtifacts(&out, artifacts, "human")).To(Succeed())
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	g.Expect(lines).To(HaveLen(4))
+	g.Expect(strings.Fields(lines[0])).To(Equal([]string{"Type", "Component", "Reference", "Mirror"}))
+	g.Expect(strings.Fields(lines[1])).To(Equal([]string{"file", "cni-plugins", "https://example.com/cni-plugins.tgz", "-"}))
+
+	g.Expect(printClusterArtifacts(&out, artifacts, "yaml")).To(MatchError(`output format "yaml" is not supported`))
+}
@@ -15,7 +15,9 @@ import (
 	"time"
 
 	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/engine"
 	"github.com/Azure/aks-engine/pkg/helpers"
+	"github.com/Azure/aks-engine/pkg/helpers/runcommand"
 	"github.com/Azure/aks-engine/pkg/helpers/ssh"
 	"github.com/Azure/aks-engine/pkg/i18n"
 	"github.com/Azure/aks-engine/pkg/kubernetes"
@@ -37,14 +39,20 @@ const (
 const (
 	getLogsLinuxVHDScriptPath      = "/opt/azure/containers/collect-logs.sh"
 	getLogsCustomLinuxScriptPath   = "/tmp/collect-logs.sh"
+	getLogsFlatcarScriptPath       = "/tmp/collect-logs-flatcar.sh"
+	getLogsFlatcarArchivePath      = "/tmp/logs.tar.gz"
 	getLogsWindowsVHDScriptPath    = "c:\\k\\debug\\collect-windows-logs.ps1"
 	getLogsCustomWindowsScriptPath = "$env:temp\\collect-windows-logs.ps1"
 	getLogsUploadTimeout           = 300 * time.Second
+	getLogsRunCommandTimeout       = 30 * time.Minute
 )
 
 type getLogsCmd struct {
+	authProvider
+
 	// user input
 	location               string
+	resourceGroupName      string
 	apiModelPath           string
 	sshHostURI             string
 	linuxSSHPrivateKeyPath string
@@ -54,20 +62,25 @@ type getLogsCmd struct {
 	controlPlaneOnly       bool
 	uploadSASURL           string
 	nodeNames              []string
+	windowsRunCommand      bool
 	// computed
 	cs                  *api.ContainerService
 	locale              *gotext.Locale
 	linuxAuthConfig     *ssh.AuthConfig
 	linuxVHDScript      *ssh.RemoteFile
 	linuxCustomScript   *ssh.RemoteFile
+	linuxFlatcarScript  *ssh.RemoteFile
 	windowsAuthConfig   *ssh.AuthConfig
 	windowsVHDScript    *ssh.RemoteFile
 	windowsCustomScript *ssh.RemoteFile
 	jumpbox             *ssh.JumpBox
+	runCommand          *runcommand.Client
 }
 
 func newGetLogsCmd() *cobra.Command {
-	glc := getLogsCmd{}
+	glc := getLogsCmd{
+		authProvider: &authArgs{},
+	}
 	command := &cobra.Command{
 		Use:   getLogsName,
 		Short: getLogsShortDescription,
@@ -90,12 +103,15 @@ func newGetLogsCmd() *cobra.Command {
 	command.Flags().StringVarP(&glc.apiModelPath, "api-model", "m", "", "path to the generated apimodel.json file (required)")
 	command.Flags().StringVar(&glc.sshHostURI, "ssh-host", "", "FQDN, or IP address, of an SSH listener that can reach all nodes in the cluster (required)")
 	command.Flags().StringVar(&glc.linuxSSHPrivateKeyPath, "linux-ssh-private-key", "", "path to a valid private SSH key to access the cluster's Linux nodes (required)")
-	command.Flags().StringVar(&glc.linuxScriptPath, "linux-script", "", "path to the log collection script to execute on the cluster's Linux nodes (required if distro is not aks-ubuntu-18.04)")
+	command.Flags().StringVar(&glc.linuxScriptPath, "linux-script", "", "path to the log collection script to execute on the cluster's Linux nodes (required if distro is neither aks-ubuntu-18.04 nor flatcar)")
 	command.Flags().StringVar(&glc.windowsScriptPath, "windows-script", "", "path to the log collection script to execute on the cluster's Windows nodes (required if distro is not aks-windows)")
 	command.Flags().StringVarP(&glc.outputDirectory, "output-directory", "o", "", "collected logs destination directory, derived from --api-model if missing")
 	command.Flags().BoolVarP(&glc.controlPlaneOnly, "control-plane-only", "", false, "get logs from control plane VMs only")
 	command.Flags().StringVarP(&glc.uploadSASURL, "upload-sas-url", "", "", "Azure Storage Account SAS URL to upload the collected logs")
 	command.Flags().StringSliceVar(&glc.nodeNames, "vm-names", nil, "get logs from the VM name list only (comma-separated names)")
+	command.Flags().BoolVar(&glc.windowsRunCommand, "windows-run-command", false, "collect the logs of the Windows nodes through the Azure Run Command API instead of SSH, requires --resource-group and --upload-sas-url")
+	command.Flags().StringVarP(&glc.resourceGroupName, "resource-group", "g", "", "the resource group where the cluster is deployed (required with --windows-run-command)")
+	addAuthFlags(glc.getAuthArgs(), command.Flags())
 	_ = command.MarkFlagRequired("location")
 	_ = command.MarkFlagRequired("api-model")
 	_ = command.MarkFlagRequired("ssh-host")
@@ -159,6 +175,14 @@ func (glc *getLogsCmd) validateArgs() (err error) {
 	if glc.nodeNames != nil && glc.controlPlaneOnly {
 		return errors.New("--control-plane-only and --vm-names are mutually exclusive")
 	}
+	if glc.windowsRunCommand {
+		if glc.resourceGroupName == "" {
+			return errors.New("--resource-group must be specified with --windows-run-command")
+		}
+		if glc.uploadSASURL == "" {
+			return errors.New("--upload-sas-url must be specified with --windows-run-command, the Windows nodes upload their logs to the storage account")
+		}
+	}
 	return nil
 }
 
@@ -197,6 +221,14 @@ func (glc *getLogsCmd) init() (err error) {
 			Path: getLogsCustomLinuxScriptPath, Permissions: "744", Owner: "root:root", Content: sc}
 	}
 	glc.linuxVHDScript = &ssh.RemoteFile{Path: getLogsLinuxVHDScriptPath}
+	if glc.cs.Properties.HasFlatcar() {
+		sc, err := engine.Asset("k8s/collect-logs-flatcar.sh")
+		if err != nil {
+			return errors.Wrap(err, "loading Flatcar log collection script")
+		}
+		glc.linuxFlatcarScript = &ssh.RemoteFile{
+			Path: getLogsFlatcarScriptPath, Permissions: "744", Owner: "root:root", Content: sc}
+	}
 	glc.linuxAuthConfig = &ssh.AuthConfig{
 		User:           glc.cs.Properties.LinuxProfile.AdminUsername,
 		PrivateKeyPath: glc.linuxSSHPrivateKeyPath,
@@ -211,13 +243,22 @@ func (glc *getLogsCmd) init() (err error) {
 	}
 	glc.windowsVHDScript = &ssh.RemoteFile{Path: getLogsWindowsVHDScriptPath}
 	if glc.cs.Properties.WindowsProfile != nil {
-		if glc.cs.Properties.WindowsProfile.GetSSHEnabled() {
+		if glc.windowsRunCommand {
+			if err = glc.getAuthArgs().validateAuthArgs(); err != nil {
+				return errors.Wrap(err, "failed to get validate auth args")
+			}
+			client, err := glc.authProvider.getClient()
+			if err != nil {
+				return errors.Wrap(err, "failed to get ARM client")
+			}
+			glc.runCommand = runcommand.NewClient(client, glc.resourceGroupName)
+		} else if glc.cs.Properties.WindowsProfile.GetSSHEnabled() {
 			glc.windowsAuthConfig = &ssh.AuthConfig{
 				User:     glc.cs.Properties.WindowsProfile.AdminUsername,
 				Password: glc.cs.Properties.WindowsProfile.AdminPassword,
 			}
 		} else {
-			log.Warn("Skipping Windows nodes as SSH is not enabled")
+			log.Warn("Skipping Windows nodes as SSH is not enabled, use flag '--windows-run-command' to collect their logs through the Azure Run Command API")
 		}
 	}
 	glc.jumpbox = &ssh.JumpBox{
@@ -237,15 +278,23 @@ func (glc *getLogsCmd) run() error {
 		return nil
 	}
 	for node, script := range nodeScripts {
-		err = collectLogs(glc, node, script)
+		if glc.isRunCommandNode(node) {
+			err = collectLogsRunCommand(glc, node, script)
+		} else {
+			err = collectLogs(glc, node, script)
+		}
 		if err != nil {
 			return err
 		}
 	}
 	log.Infof("Logs downloaded to %s", glc.outputDirectory)
 	if glc.uploadSASURL != "" {
-		for node := range nodeScripts {
-			err = uploadLogs(node, glc.outputDirectory, glc.uploadSASURL)
+		for node, script := range nodeScripts {
+			if glc.isRunCommandNode(node) {
+				continue
+			}
+			_, archive := glc.logsArchive(node, script)
+			err = uploadLogs(node, archive, glc.outputDirectory, glc.uploadSASURL)
 			if err != nil {
 				log.Warnf("Error uploading %s logs", node.URI)
 				log.Debugf("Error: %s", err)
@@ -265,7 +314,7 @@ func getClusterNodes(glc *getLogsCmd, kubeClient kubernetes.NodeLister) (nodes [
 					URI: nodeName, Port: 22, OperatingSystem: api.Linux, AuthConfig: glc.linuxAuthConfig, Jumpbox: glc.jumpbox})
 			} else {
 				log.Infof("Treating node %s as a Windows agent node", nodeName)
-				if glc.windowsAuthConfig != nil {
+				if glc.windowsAuthConfig != nil || glc.runCommand != nil {
 					nodes = append(nodes, &ssh.RemoteHost{
 						URI: nodeName, Port: 22, OperatingSystem: api.Windows, AuthConfig: glc.windowsAuthConfig, Jumpbox: glc.jumpbox})
 				} else {
@@ -294,7 +343,7 @@ func getClusterNodes(glc *getLogsCmd, kubeClient kubernetes.NodeLister) (nodes [
 				nodes = append(nodes, &ssh.RemoteHost{
 					URI: node.Name, Port: 22, OperatingSystem: api.Linux, AuthConfig: glc.linuxAuthConfig, Jumpbox: glc.jumpbox})
 			case api.Windows:
-				if glc.windowsAuthConfig != nil {
+				if glc.windowsAuthConfig != nil || glc.runCommand != nil {
 					nodes = append(nodes, &ssh.RemoteHost{
 						URI: node.Name, Port: 22, OperatingSystem: api.Windows, AuthConfig: glc.windowsAuthConfig, Jumpbox: glc.jumpbox})
 				}
@@ -321,6 +370,9 @@ func getClusterNodeScripts(glc *getLogsCmd, nodes []*ssh.RemoteHost) map[*ssh.Re
 					if pool.IsVHDDistro() && glc.cs.Properties.IsAgentPoolMember(node.URI, pool, i) {
 						nodeScript[node] = glc.linuxVHDScript
 					}
+					if pool.IsFlatcar() && glc.linuxFlatcarScript != nil && glc.cs.Properties.IsAgentPoolMember(node.URI, pool, i) {
+						nodeScript[node] = glc.linuxFlatcarScript
+					}
 				}
 			}
 			if glc.linuxCustomScript != nil {
@@ -343,7 +395,7 @@ func getClusterNodeScripts(glc *getLogsCmd, nodes []*ssh.RemoteHost) map[*ssh.Re
 	}
 	for pool, hasScript := range poolHasScript {
 		if !hasScript {
-			log.Warnf("Skipping node pool '%s' as flag '--linux-script' is not set and the pool distro is not aks-ubuntu-18.04", pool)
+			log.Warnf("Skipping node pool '%s' as flag '--linux-script' is not set and the pool distro is neither aks-ubuntu-18.04 nor flatcar", pool)
 		}
 	}
 	if isWindowsSkipped {
@@ -369,8 +421,8 @@ func collectLogs(glc *getLogsCmd, node *ssh.RemoteHost, script *ssh.RemoteFile)
 	if err != nil {
 		return errors.Wrap(err, stdout)
 	}
-	src := fileToDownload(node.OperatingSystem, node.URI)
-	dst := path.Join(glc.outputDirectory, fmt.Sprintf("%s.zip", node.URI))
+	src, archive := glc.logsArchive(node, script)
+	dst := path.Join(glc.outputDirectory, archive)
 	stdout, err = ssh.CopyFromRemote(ctx, node, src, dst)
 	if err != nil {
 		return errors.Wrap(err, stdout)
@@ -378,12 +430,54 @@ func collectLogs(glc *getLogsCmd, node *ssh.RemoteHost, script *ssh.RemoteFile)
 	return err
 }
 
+// collectLogsRunCommand writes the log collection script (if needed) and executes it through the Azure Run Command API.
+// Run commands cannot download files, so the node uploads the collected logs to the storage account itself.
+func collectLogsRunCommand(glc *getLogsCmd, node *ssh.RemoteHost, script *ssh.RemoteFile) error {
+	ctx, cancel := context.WithTimeout(context.Background(), getLogsRunCommandTimeout)
+	defer cancel()
+
+	log.Infof("Processing node: %s", node.URI)
+	sas, err := url.Parse(glc.uploadSASURL)
+	if err != nil {
+		return errors.Wrap(err, "parsing upload SAS URL")
+	}
+	sas.Path = path.Join(sas.Path, fmt.Sprintf("%s.zip", node.URI))
+	zip := fmt.Sprintf("$env:temp\\%s.zip", node.URI)
+	var lines []string
+	if script.Content != nil {
+		lines = append(lines, runcommand.WriteFileScript(script)...)
+	}
+	lines = append(lines,
+		fmt.Sprintf("iex %s | Where-Object { $_.extension -eq '.zip' } | Copy-Item -Destination %s", script.Path, zip),
+		runcommand.UploadFileScript(zip, sas),
+		fmt.Sprintf("Remove-Item %s", zip))
+	if stdout, err := glc.runCommand.ExecuteRemote(ctx, node.URI, lines...); err != nil {
+		return errors.Wrap(err, stdout)
+	}
+	log.Infof("Logs of node %s uploaded to the storage account", node.URI)
+	return nil
+}
+
+// isRunCommandNode returns true if the scripts of node run through the Azure Run Command API
+func (glc *getLogsCmd) isRunCommandNode(node *ssh.RemoteHost) bool {
+	return glc.runCommand != nil && node.OperatingSystem == api.Windows
+}
+
+// logsArchive returns the archive the log collection script writes on the node and the name of its local copy.
+// Flatcar does not ship zip, so its script writes a gzip'd tarball instead.
+func (glc *getLogsCmd) logsArchive(node *ssh.RemoteHost, script *ssh.RemoteFile) (*ssh.RemoteFile, string) {
+	if glc.linuxFlatcarScript != nil && script == glc.linuxFlatcarScript {
+		return &ssh.RemoteFile{Path: getLogsFlatcarArchivePath}, fmt.Sprintf("%s.tar.gz", node.URI)
+	}
+	return fileToDownload(node.OperatingSystem, node.URI), fmt.Sprintf("%s.zip", node.URI)
+}
+
 // uploadLogs uploads collected logs to an azure storage account
-func uploadLogs(node *ssh.RemoteHost, outputDirectory, uploadSASURL string) error {
+func uploadLogs(node *ssh.RemoteHost, archive, outputDirectory, uploadSASURL string) error {
 	log.Infof("Uploading %s logs", node.URI)
 	ctx, cancel := context.WithTimeout(context.Background(), getLogsUploadTimeout)
 	defer cancel()
-	fp := path.Join(outputDirectory, fmt.Sprintf("%s.zip", node.URI))
+	fp := path.Join(outputDirectory, archive)
 	f, err := os.Open(fp)
 	if err != nil {
 		return errors.Wrapf(err, "reading file %s", fp)
@@ -392,7 +486,7 @@ func uploadLogs(node *ssh.RemoteHost, outputDirectory, uploadSASURL string) erro
 	if err != nil {
 		return errors.Wrap(err, "parsing upload SAS URL")
 	}
-	sas.Path = path.Join(sas.Path, fmt.Sprintf("%s.zip", node.URI))
+	sas.Path = path.Join(sas.Path, archive)
 	_, err = uploadToSASURL(ctx, f, sas)
 	if err != nil {
 		return err
@@ -8,7 +8,11 @@ import (
 	"testing"
 
 	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/helpers/runcommand"
 	"github.com/Azure/aks-engine/pkg/helpers/ssh"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
@@ -216,6 +220,30 @@ func TestGetLogsCmdValidateArgs(t *testing.T) {
 			expectedErr: errors.New("--control-plane-only and --vm-names are mutually exclusive"),
 			name:        "ControlPlane+VMNames",
 		},
+		{
+			glc: &getLogsCmd{
+				apiModelPath:           existingFile,
+				linuxSSHPrivateKeyPath: existingFile,
+				sshHostURI:             "server.example.com",
+				location:               "southcentralus",
+				uploadSASURL:           "https://blob-service-uri/container-name?sas-token",
+				windowsRunCommand:      true,
+			},
+			expectedErr: errors.New("--resource-group must be specified with --windows-run-command"),
+			name:        "WindowsRunCommandNeedsResourceGroup",
+		},
+		{
+			glc: &getLogsCmd{
+				apiModelPath:           existingFile,
+				linuxSSHPrivateKeyPath: existingFile,
+				sshHostURI:             "server.example.com",
+				location:               "southcentralus",
+				resourceGroupName:      "rg",
+				windowsRunCommand:      true,
+			},
+			expectedErr: errors.New("--upload-sas-url must be specified with --windows-run-command, the Windows nodes upload their logs to the storage account"),
+			name:        "WindowsRunCommandNeedsUploadSASURL",
+		},
 		{
 			glc: &getLogsCmd{
 				apiModelPath:           existingFile,
@@ -414,6 +442,18 @@ func TestGetLogsGetClusterNodes(t *testing.T) {
 			expected:            []*ssh.RemoteHost{master, linuxAgent, windowsAgent},
 			name:                "expect all nodes",
 		},
+		{
+			glc: &getLogsCmd{
+				controlPlaneOnly: false,
+				cs:               api.CreateMockContainerService("test", "", 1, 1, false),
+				runCommand:       runcommand.NewClient(&armhelpers.MockAKSEngineClient{}, "rg"),
+			},
+			isWindowsSSHEnabled: false,
+			nodeList:            []string{"k8s-master-22998975-0", "k8s-agentpool1-22998975-0", "windows10"},
+			failListNodes:       false,
+			expected:            []*ssh.RemoteHost{master, linuxAgent, windowsAgent},
+			name:                "windows run command",
+		},
 	}
 	for _, tc := range cases {
 		c := tc
@@ -534,6 +574,35 @@ func TestGetLogsGetClusterNodeScripts(t *testing.T) {
 	}
 }
 
+func TestGetLogsFlatcarNodes(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+	cs := api.CreateMockContainerService("test", "", 1, 1, false)
+	cs.Properties.AgentPoolProfiles[0].Distro = api.Flatcar
+	glc := &getLogsCmd{
+		cs:              cs,
+		outputDirectory: "_output",
+	}
+	err := glc.init()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(glc.linuxFlatcarScript).NotTo(BeNil())
+	g.Expect(glc.linuxFlatcarScript.Content).NotTo(BeEmpty())
+
+	master := &ssh.RemoteHost{URI: "k8s-master-22998975-0", OperatingSystem: api.Linux}
+	linuxAgent := &ssh.RemoteHost{URI: "k8s-agentpool1-22998975-0", OperatingSystem: api.Linux}
+	nodeScripts := getClusterNodeScripts(glc, []*ssh.RemoteHost{master, linuxAgent})
+	g.Expect(nodeScripts).To(HaveLen(1))
+	g.Expect(nodeScripts[linuxAgent]).To(Equal(glc.linuxFlatcarScript))
+
+	src, archive := glc.logsArchive(linuxAgent, nodeScripts[linuxAgent])
+	g.Expect(src.Path).To(Equal("/tmp/logs.tar.gz"))
+	g.Expect(archive).To(Equal("k8s-agentpool1-22998975-0.tar.gz"))
+
+	src, archive = glc.logsArchive(master, glc.linuxVHDScript)
+	g.Expect(src.Path).To(Equal("/tmp/logs.zip"))
+	g.Expect(archive).To(Equal("k8s-master-22998975-0.zip"))
+}
+
 type mockNodeLister struct {
 	nodeNameList  []string
 	failListNodes bool
@@ -556,3 +625,68 @@ func (m *mockNodeLister) ListNodes() (*v1.NodeList, error) {
 	}
 	return nodeList, nil
 }
+
+func TestGetLogsInitWindowsRunCommand(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	glc := &getLogsCmd{
+		authProvider: &mockAuthProvider{
+			authArgs:      &authArgs{AuthMethod: "client_secret", rawClientID: "99999999-9999-9999-9999-999999999999", ClientSecret: "secret", rawSubscriptionID: "99999999-9999-9999-9999-999999999999", RawAzureEnvironment: "AzurePublicCloud"},
+			getClientMock: &armhelpers.MockAKSEngineClient{},
+		},
+		resourceGroupName: "rg",
+		windowsRunCommand: true,
+		cs:                api.CreateMockContainerService("test", "", 1, 1, false),
+	}
+	glc.cs.Properties.WindowsProfile = api.GetK8sDefaultProperties(true).WindowsProfile
+	glc.cs.Properties.WindowsProfile.SSHEnabled = to.BoolPtr(false)
+	g.Expect(glc.init()).To(Succeed())
+	g.Expect(glc.windowsAuthConfig).To(BeNil())
+	g.Expect(glc.runCommand).NotTo(BeNil())
+	g.Expect(glc.isRunCommandNode(&ssh.RemoteHost{URI: "windows10", OperatingSystem: api.Windows})).To(BeTrue())
+	g.Expect(glc.isRunCommandNode(&ssh.RemoteHost{URI: "k8s-agentpool1-22998975-0", OperatingSystem: api.Linux})).To(BeFalse())
+}
+
+func TestGetLogsCollectLogsRunCommand(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	var script []string
+	client := &armhelpers.MockAKSEngineClient{
+		FakeListVirtualMachineResult: func() []compute.VirtualMachine {
+			return []compute.VirtualMachine{{
+				Name: to.StringPtr("windows10"),
+				VirtualMachineProperties: &compute.VirtualMachineProperties{
+					OsProfile: &compute.OSProfile{ComputerName: to.StringPtr("windows10")},
+				},
+			}}
+		},
+		FakeRunCommandResult: func(input compute.RunCommandInput) compute.RunCommandResult {
+			script = *input.Script
+			return compute.RunCommandResult{
+				Value: &[]compute.InstanceViewStatus{
+					{Code: to.StringPtr("ComponentStatus/StdOut/succeeded"), Message: to.StringPtr(runcommand.CompletedMarker)},
+				},
+			}
+		},
+	}
+	glc := &getLogsCmd{
+		uploadSASURL: "https://account.blob.core.windows.net/logs?sv=2019-12-12",
+		runCommand:   runcommand.NewClient(client, "rg"),
+	}
+	node := &ssh.RemoteHost{URI: "windows10", OperatingSystem: api.Windows}
+	customScript := &ssh.RemoteFile{Path: getLogsCustomWindowsScriptPath, Content: []byte("Write-Output logs")}
+	g.Expect(collectLogsRunCommand(glc, node, customScript)).To(Succeed())
+	g.Expect(script).To(Equal([]string{
+		"$ErrorActionPreference = 'Stop'",
+		"[IO.File]::WriteAllBytes(\"$env:temp\\collect-windows-logs.ps1\", [Convert]::FromBase64String('V3JpdGUtT3V0cHV0IGxvZ3M='))",
+		"iex $env:temp\\collect-windows-logs.ps1 | Where-Object { $_.extension -eq '.zip' } | Copy-Item -Destination $env:temp\\windows10.zip",
+		"Invoke-WebRequest -UseBasicParsing -Method Put -InFile \"$env:temp\\windows10.zip\" -Headers @{'x-ms-blob-type'='BlockBlob'; 'x-ms-version'='2019-12-12'} -Uri 'https://account.blob.core.windows.net/logs/windows10.zip?sv=2019-12-12' | Out-Null",
+		"Remove-Item $env:temp\\windows10.zip",
+		"Write-Output aks-engine-run-command-completed",
+	}))
+
+	client.FailRunCommand = true
+	g.Expect(collectLogsRunCommand(glc, node, &ssh.RemoteFile{Path: getLogsWindowsVHDScriptPath})).NotTo(Succeed())
+}
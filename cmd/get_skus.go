@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"regexp"
 	"sort"
 	"strings"
 	"text/tabwriter"
@@ -27,6 +28,18 @@ const (
 
 var skusOutputFormatOptions = append(outputFormatOptions, "code")
 
+// nestedVirtualizationSKURegex matches the VM sizes that support nested virtualization, which the
+// resource SKUs API doesn't report: the Intel and AMD D and E series since v3, Fsv2 and the M series
+var nestedVirtualizationSKURegex = regexp.MustCompile(`^Standard_(([DE][0-9]+(-[0-9]+)?[a-oq-z]*_v[3-9])|(F[0-9]+s_v2)|(M[0-9]+(-[0-9]+)?[a-z]*(_v[0-9])?))$`)
+
+// arm64SKURegex matches the Ampere Altra based VM sizes, used when the resource SKUs API doesn't report the CPU architecture
+var arm64SKURegex = regexp.MustCompile(`^Standard_[DE][0-9]+(-[0-9]+)?p[a-z]*_v5$`)
+
+// hyperVGen1OnlySKURegex and hyperVGen2OnlySKURegex match the VM sizes that only support one Hyper-V generation,
+// used when the resource SKUs API doesn't report the supported generations
+var hyperVGen1OnlySKURegex = regexp.MustCompile(`^Standard_((A[0-9]+m?(_v2)?)|(D[0-9]+)|(DS[0-9]+(-[0-9]+)?)|(F[0-9]+s?)|(G[0-9]+)|(H[0-9]+[a-z]*)|(NC[0-9]+r?)|(NV[0-9]+))$`)
+var hyperVGen2OnlySKURegex = regexp.MustCompile(`^Standard_M[0-9]+(-[0-9]+)?[a-z]*_v2$`)
+
 type SkusCmd struct {
 	authProvider
 
@@ -109,18 +122,29 @@ func (vmc *SkusCmd) run(cmd *cobra.Command, args []string) error {
 			}
 			if !found {
 				acceleratedNetworking := false
+				hyperVGenerations := hyperVGenerationsSupported(name)
+				cpuArchitectureType := cpuArchitecture(name)
 				if r.Capabilities != nil {
 					for _, c := range *r.Capabilities {
-						if c.Name != nil && *c.Name == "AcceleratedNetworkingEnabled" {
-							if c.Value != nil && strings.EqualFold(*c.Value, "True") {
-								acceleratedNetworking = true
-							}
+						if c.Name == nil || c.Value == nil {
+							continue
+						}
+						switch *c.Name {
+						case "AcceleratedNetworkingEnabled":
+							acceleratedNetworking = strings.EqualFold(*c.Value, "True")
+						case "HyperVGenerations":
+							hyperVGenerations = *c.Value
+						case "CpuArchitectureType":
+							cpuArchitectureType = *c.Value
 						}
 					}
 				}
 				skus = append(skus, helpers.VMSku{
 					Name:                  name,
 					AcceleratedNetworking: acceleratedNetworking,
+					NestedVirtualization:  nestedVirtualizationSupported(name),
+					HyperVGenerations:     hyperVGenerations,
+					CPUArchitectureType:   cpuArchitectureType,
 				})
 			}
 		}
@@ -155,21 +179,24 @@ package helpers
 type VMSku struct {
 	Name                  string
 	AcceleratedNetworking bool
+	NestedVirtualization  bool
+	HyperVGenerations     string
+	CPUArchitectureType   string
 }
 
 var VMSkus = []VMSku{
 `)
-		formatStr := "\t{\n\t\tName:                  \"%s\",\n\t\tAcceleratedNetworking: %t,\n\t},\n"
+		formatStr := "\t{\n\t\tName:                  \"%s\",\n\t\tAcceleratedNetworking: %t,\n\t\tNestedVirtualization:  %t,\n\t\tHyperVGenerations:     \"%s\",\n\t\tCPUArchitectureType:   \"%s\",\n\t},\n"
 		for _, s := range skus {
-			b.WriteString(fmt.Sprintf(formatStr, s.Name, s.AcceleratedNetworking))
+			b.WriteString(fmt.Sprintf(formatStr, s.Name, s.AcceleratedNetworking, s.NestedVirtualization, s.HyperVGenerations, s.CPUArchitectureType))
 		}
 		b.WriteString("}")
 		fmt.Println(b.String())
 	case "human":
 		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.FilterHTML)
-		fmt.Fprintln(w, "Name\tAccelerated Networking Support")
+		fmt.Fprintln(w, "Name\tAccelerated Networking Support\tNested Virtualization Support\tHyper-V Generations\tCPU Architecture")
 		for _, sku := range skus {
-			fmt.Fprintf(w, "%s\t%t\n", sku.Name, sku.AcceleratedNetworking)
+			fmt.Fprintf(w, "%s\t%t\t%t\t%s\t%s\n", sku.Name, sku.AcceleratedNetworking, sku.NestedVirtualization, sku.HyperVGenerations, sku.CPUArchitectureType)
 		}
 		w.Flush()
 	}
@@ -178,3 +205,25 @@ var VMSkus = []VMSku{
 
 	return err
 }
+
+func nestedVirtualizationSupported(name string) bool {
+	return nestedVirtualizationSKURegex.MatchString(name)
+}
+
+func hyperVGenerationsSupported(name string) string {
+	switch {
+	case arm64SKURegex.MatchString(name), hyperVGen2OnlySKURegex.MatchString(name):
+		return helpers.HyperVGenerationV2
+	case hyperVGen1OnlySKURegex.MatchString(name):
+		return helpers.HyperVGenerationV1
+	default:
+		return helpers.HyperVGenerationV1 + "," + helpers.HyperVGenerationV2
+	}
+}
+
+func cpuArchitecture(name string) string {
+	if arm64SKURegex.MatchString(name) {
+		return helpers.CPUArchitectureTypeArm64
+	}
+	return helpers.CPUArchitectureTypeX64
+}
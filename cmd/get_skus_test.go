@@ -107,3 +107,40 @@ func TestGetSkusCmd_ShouldErrorIfInvalidOption(t *testing.T) {
 	g.Expect(err).To(HaveOccurred())
 	g.Expect(err.Error()).To(Equal("invalid output format: \"yaml\". Allowed values: human, json, code"))
 }
+
+func TestNestedVirtualizationSupported(t *testing.T) {
+	g := NewGomegaWithT(t)
+	for _, name := range []string{"Standard_D4s_v3", "Standard_D16ads_v5", "Standard_E16-4s_v3", "Standard_F4s_v2", "Standard_M128ms", "Standard_M208ms_v2"} {
+		g.Expect(nestedVirtualizationSupported(name)).To(BeTrue(), name)
+	}
+	for _, name := range []string{"Standard_D2_v2", "Standard_DS2_v2", "Standard_B2ms", "Standard_D4ps_v5", "Standard_DC2s_v3", "Standard_F4s", "Standard_NC6"} {
+		g.Expect(nestedVirtualizationSupported(name)).To(BeFalse(), name)
+	}
+}
+
+func TestHyperVGenerationsSupported(t *testing.T) {
+	g := NewGomegaWithT(t)
+	for name, expected := range map[string]string{
+		"Standard_D4s_v3":    "V1,V2",
+		"Standard_B2ms":      "V1,V2",
+		"Standard_A2_v2":     "V1",
+		"Standard_D2":        "V1",
+		"Standard_DS2":       "V1",
+		"Standard_F4s":       "V1",
+		"Standard_NC6":       "V1",
+		"Standard_M208ms_v2": "V2",
+		"Standard_D4ps_v5":   "V2",
+	} {
+		g.Expect(hyperVGenerationsSupported(name)).To(Equal(expected), name)
+	}
+}
+
+func TestCPUArchitecture(t *testing.T) {
+	g := NewGomegaWithT(t)
+	for _, name := range []string{"Standard_D4ps_v5", "Standard_D8plds_v5", "Standard_E16pds_v5"} {
+		g.Expect(cpuArchitecture(name)).To(Equal("Arm64"), name)
+	}
+	for _, name := range []string{"Standard_D4s_v5", "Standard_D4ads_v5", "Standard_DC2s_v3", "Standard_NC6"} {
+		g.Expect(cpuArchitecture(name)).To(Equal("x64"), name)
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/cluster"
+	"github.com/Azure/aks-engine/pkg/helpers"
+	"github.com/Azure/aks-engine/pkg/i18n"
+	"github.com/Azure/aks-engine/pkg/operations"
+	"github.com/leonelquinteros/gotext"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type removePoolCmd struct {
+	authArgs
+	whatIfArgs
+	outputArgs
+
+	// user input
+	apiModelPath      string
+	resourceGroupName string
+	location          string
+	nodePoolName      string
+	apiserver         string
+	force             bool
+
+	// derived
+	containerService *api.ContainerService
+	apiVersion       string
+	client           armhelpers.AKSEngineClient
+	locale           *gotext.Locale
+	logger           *log.Entry
+	apiserverURL     string
+}
+
+const (
+	removePoolName             = "removepool"
+	removePoolShortDescription = "Remove a node pool from an existing AKS Engine-created Kubernetes cluster"
+	removePoolLongDescription  = "Remove a node pool from an existing AKS Engine-created Kubernetes cluster by cordoning and draining its nodes, deleting its VMs or VMSS, then removing it from the api model"
+)
+
+// newRemovePoolCmd run a command to remove an agent pool from a Kubernetes cluster
+func newRemovePoolCmd() *cobra.Command {
+	rpc := removePoolCmd{}
+
+	removePoolCmd := &cobra.Command{
+		Use:   removePoolName,
+		Short: removePoolShortDescription,
+		Long:  removePoolLongDescription,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return rpc.runWithResult(cmd, func() error {
+				return rpc.run(cmd, args)
+			})
+		},
+	}
+
+	f := removePoolCmd.Flags()
+	f.StringVarP(&rpc.location, "location", "l", "", "location the cluster is deployed in")
+	f.StringVarP(&rpc.resourceGroupName, "resource-group", "g", "", "the resource group where the cluster is deployed")
+	f.StringVarP(&rpc.apiModelPath, "api-model", "m", "", "path to the generated apimodel.json file")
+	f.StringVarP(&rpc.nodePoolName, "node-pool", "p", "", "name of the node pool to remove")
+	f.StringVar(&rpc.apiserver, "apiserver", "", "apiserver endpoint (required to cordon and drain nodes)")
+	f.BoolVar(&rpc.force, "force", false, "remove the last node pool of the cluster, or a node pool cluster-autoscaler scales")
+	f.BoolVar(&rpc.whatIf, "what-if", false, "print the nodes that would be cordoned, drained and deleted, then exit without removing the node pool")
+
+	addAuthFlags(&rpc.authArgs, f)
+	addOutputFlag(&rpc.outputArgs, f, "o")
+
+	return removePoolCmd
+}
+
+func (rpc *removePoolCmd) validate(cmd *cobra.Command) error {
+	log.Debugln("validating removepool command line arguments...")
+	var err error
+
+	rpc.locale, err = i18n.LoadTranslations()
+	if err != nil {
+		return errors.Wrap(err, "error loading translation files")
+	}
+
+	if rpc.resourceGroupName == "" {
+		_ = cmd.Usage()
+		return errors.New("--resource-group must be specified")
+	}
+
+	if rpc.location == "" {
+		_ = cmd.Usage()
+		return errors.New("--location must be specified")
+	}
+
+	rpc.location = helpers.NormalizeAzureRegion(rpc.location)
+
+	if rpc.apiModelPath == "" {
+		_ = cmd.Usage()
+		return errors.New("--api-model must be specified")
+	}
+
+	if rpc.nodePoolName == "" {
+		_ = cmd.Usage()
+		return errors.New("--node-pool must be specified")
+	}
+
+	if rpc.apiserver == "" {
+		_ = cmd.Usage()
+		return errors.New("--apiserver must be specified")
+	}
+	if strings.HasPrefix(rpc.apiserver, "https://") {
+		rpc.apiserverURL = rpc.apiserver
+	} else if strings.HasPrefix(rpc.apiserver, "http://") {
+		return errors.New("apiserver URL cannot be insecure http://")
+	} else {
+		rpc.apiserverURL = fmt.Sprintf("https://%s", rpc.apiserver)
+	}
+	return nil
+}
+
+func (rpc *removePoolCmd) load() error {
+	rpc.logger = newLogger()
+	var err error
+
+	ctx, cancel := context.WithTimeout(context.Background(), armhelpers.DefaultARMOperationTimeout)
+	defer cancel()
+
+	if _, err = os.Stat(rpc.apiModelPath); os.IsNotExist(err) {
+		return errors.Errorf("specified api model does not exist (%s)", rpc.apiModelPath)
+	}
+
+	apiloader := &api.Apiloader{
+		Translator: &i18n.Translator{
+			Locale: rpc.locale,
+		},
+	}
+	rpc.containerService, rpc.apiVersion, err = apiloader.LoadContainerServiceFromFile(rpc.apiModelPath, true, true, nil)
+	if err != nil {
+		return errors.Wrap(err, "error parsing the api model")
+	}
+
+	if rpc.containerService.Properties.IsCustomCloudProfile() {
+		if err = writeCustomCloudProfile(rpc.containerService); err != nil {
+			return errors.Wrap(err, "error writing custom cloud profile")
+		}
+		if err = rpc.containerService.Properties.SetCustomCloudSpec(api.AzureCustomCloudSpecParams{IsUpgrade: false, IsScale: true}); err != nil {
+			return errors.Wrap(err, "error parsing the api model")
+		}
+	}
+
+	if err = rpc.authArgs.validateAuthArgs(); err != nil {
+		return err
+	}
+
+	if rpc.client, err = rpc.authArgs.getClient(); err != nil {
+		return errors.Wrap(err, "failed to get client")
+	}
+	rpc.client = rpc.withResultRecording(rpc.client, rpc.SubscriptionID.String())
+	rpc.result.ResourceGroup = rpc.resourceGroupName
+
+	if err = rpc.checkResourceGroup(ctx); err != nil {
+		return err
+	}
+
+	if rpc.containerService.Location == "" {
+		rpc.containerService.Location = rpc.location
+	} else if rpc.containerService.Location != rpc.location {
+		return errors.New("--location does not match api model location")
+	}
+	return nil
+}
+
+// checkResourceGroup returns an error if the resource group of the cluster doesn't exist, removing a pool never creates it
+func (rpc *removePoolCmd) checkResourceGroup(ctx context.Context) error {
+	existence, err := rpc.client.CheckResourceGroupExistence(ctx, rpc.resourceGroupName)
+	if err != nil {
+		return errors.Wrapf(err, "checking the existence of resource group %s", rpc.resourceGroupName)
+	}
+	if existence.Response != nil && existence.StatusCode == http.StatusNotFound {
+		return errors.Errorf("resource group %s does not exist", rpc.resourceGroupName)
+	}
+	return nil
+}
+
+func (rpc *removePoolCmd) run(cmd *cobra.Command, args []string) error {
+	if err := rpc.validate(cmd); err != nil {
+		return errors.Wrap(err, "failed to validate removepool command")
+	}
+	if err := rpc.load(); err != nil {
+		return errors.Wrap(err, "failed to load existing container service")
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	resp, err := cluster.RemovePool(ctx, &cluster.RemovePoolRequest{
+		Options: cluster.Options{
+			Client:     rpc.client,
+			Logger:     rpc.logger,
+			Translator: &i18n.Translator{Locale: rpc.locale},
+			Output:     rpc.textOutput(),
+			BuildTag:   BuildTag,
+			WhatIf:     rpc.whatIf,
+		},
+		ContainerService: rpc.containerService,
+		SubscriptionID:   rpc.SubscriptionID.String(),
+		ResourceGroup:    rpc.resourceGroupName,
+		AgentPoolName:    rpc.nodePoolName,
+		APIServerURL:     rpc.apiserverURL,
+		Force:            rpc.force,
+	})
+	if resp != nil {
+		rpc.result.addNodesRemoved(resp.NodesRemoved...)
+	}
+	if operations.IsInterrupted(err) {
+		log.Warnf("The removal of node pool %s was %s. Run the same removepool command again to resume it", rpc.nodePoolName, err)
+	}
+	if err != nil || rpc.whatIf {
+		return err
+	}
+	if err = rpc.saveAPIModel(); err != nil {
+		return err
+	}
+	rpc.warnClusterAutoscaler(resp.ClusterAutoscalerMode)
+	return nil
+}
+
+// warnClusterAutoscaler warns that cluster-autoscaler keeps scaling the removed pool until its addon is applied again
+func (rpc *removePoolCmd) warnClusterAutoscaler(mode string) {
+	switch mode {
+	case "":
+		return
+	case api.AddonModeReconcile:
+		log.Warnf("Node pool %s was removed from the pools of the cluster-autoscaler addon in the api model, but cluster-autoscaler keeps trying to scale it "+
+			"until the addon is applied again: run aks-engine upgrade with the updated api model", rpc.nodePoolName)
+	default:
+		log.Warnf("Node pool %s was removed from the pools of the cluster-autoscaler addon in the api model, but cluster-autoscaler keeps trying to scale it. "+
+			"The addon is in %s mode and aks-engine upgrade does not apply it again: "+
+			"remove the --nodes argument of the pool from the cluster-autoscaler deployment in the kube-system namespace", rpc.nodePoolName, mode)
+	}
+}
+
+func (rpc *removePoolCmd) saveAPIModel() error {
+	var err error
+	apiloader := &api.Apiloader{
+		Translator: &i18n.Translator{
+			Locale: rpc.locale,
+		},
+	}
+	var apiVersion string
+	rpc.containerService, apiVersion, err = apiloader.LoadContainerServiceFromFile(rpc.apiModelPath, false, true, nil)
+	if err != nil {
+		return err
+	}
+
+	if !cluster.RemoveAgentPoolProfile(rpc.containerService.Properties, rpc.nodePoolName) {
+		return errors.Errorf("node pool %s was not found in the api model", rpc.nodePoolName)
+	}
+
+	b, err := apiloader.SerializeContainerService(rpc.containerService, apiVersion)
+
+	if err != nil {
+		return err
+	}
+
+	f := helpers.FileSaver{
+		Translator: &i18n.Translator{
+			Locale: rpc.locale,
+		},
+	}
+	dir, file := filepath.Split(rpc.apiModelPath)
+	return f.SaveFile(dir, file, b)
+}
@@ -0,0 +1,195 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/spf13/cobra"
+)
+
+func TestNewRemovePoolCmd(t *testing.T) {
+	command := newRemovePoolCmd()
+	if command.Use != removePoolName || command.Short != removePoolShortDescription || command.Long != removePoolLongDescription {
+		t.Fatalf("removepool command should have use %s equal %s, short %s equal %s and long %s equal to %s", command.Use, removePoolName, command.Short, removePoolShortDescription, command.Long, removePoolLongDescription)
+	}
+
+	expectedFlags := []string{"location", "resource-group", "api-model", "node-pool", "apiserver", "force", "what-if", "output"}
+	for _, f := range expectedFlags {
+		if command.Flags().Lookup(f) == nil {
+			t.Fatalf("removepool command should have flag %s", f)
+		}
+	}
+
+	command.SetArgs([]string{})
+	if err := command.Execute(); err == nil {
+		t.Fatalf("expected an error when calling removepool with no arguments")
+	}
+}
+
+func TestRemovePoolCmdValidate(t *testing.T) {
+	r := &cobra.Command{}
+
+	cases := []struct {
+		rpc                  *removePoolCmd
+		expectedErr          error
+		expectedAPIServerURL string
+		name                 string
+	}{
+		{
+			rpc: &removePoolCmd{
+				apiModelPath:      "./not/used",
+				nodePoolName:      "agentpool1",
+				location:          "centralus",
+				resourceGroupName: "",
+				apiserver:         "mycluster.centralus.cloudapp.azure.com",
+			},
+			expectedErr: errors.New("--resource-group must be specified"),
+			name:        "NoResourceGroup",
+		},
+		{
+			rpc: &removePoolCmd{
+				apiModelPath:      "./not/used",
+				nodePoolName:      "agentpool1",
+				location:          "",
+				resourceGroupName: "testRG",
+				apiserver:         "mycluster.centralus.cloudapp.azure.com",
+			},
+			expectedErr: errors.New("--location must be specified"),
+			name:        "NoLocation",
+		},
+		{
+			rpc: &removePoolCmd{
+				apiModelPath:      "",
+				nodePoolName:      "agentpool1",
+				location:          "centralus",
+				resourceGroupName: "testRG",
+				apiserver:         "mycluster.centralus.cloudapp.azure.com",
+			},
+			expectedErr: errors.New("--api-model must be specified"),
+			name:        "NoAPIModel",
+		},
+		{
+			rpc: &removePoolCmd{
+				apiModelPath:      "./not/used",
+				location:          "centralus",
+				resourceGroupName: "testRG",
+				apiserver:         "mycluster.centralus.cloudapp.azure.com",
+			},
+			expectedErr: errors.New("--node-pool must be specified"),
+			name:        "NoNodePool",
+		},
+		{
+			rpc: &removePoolCmd{
+				apiModelPath:      "./not/used",
+				nodePoolName:      "agentpool1",
+				location:          "centralus",
+				resourceGroupName: "testRG",
+			},
+			expectedErr: errors.New("--apiserver must be specified"),
+			name:        "NoAPIServer",
+		},
+		{
+			rpc: &removePoolCmd{
+				apiModelPath:      "./not/used",
+				nodePoolName:      "agentpool1",
+				location:          "centralus",
+				resourceGroupName: "testRG",
+				apiserver:         "http://mycluster.centralus.cloudapp.azure.com",
+			},
+			expectedErr: errors.New("apiserver URL cannot be insecure http://"),
+			name:        "InsecureAPIServer",
+		},
+		{
+			rpc: &removePoolCmd{
+				apiModelPath:      "./not/used",
+				nodePoolName:      "agentpool1",
+				location:          "centralus",
+				resourceGroupName: "testRG",
+				apiserver:         "mycluster.centralus.cloudapp.azure.com",
+			},
+			expectedErr:          nil,
+			expectedAPIServerURL: "https://mycluster.centralus.cloudapp.azure.com",
+			name:                 "IsValid",
+		},
+	}
+
+	for _, tc := range cases {
+		c := tc
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			err := c.rpc.validate(r)
+			if err != nil && c.expectedErr != nil {
+				if err.Error() != c.expectedErr.Error() {
+					t.Fatalf("expected validate removepool command to return error %s, but instead got %s", c.expectedErr.Error(), err.Error())
+				}
+			} else {
+				if c.expectedErr != nil {
+					t.Fatalf("expected validate removepool command to return error %s, but instead got no error", c.expectedErr.Error())
+				} else if err != nil {
+					t.Fatalf("expected validate removepool command to return no error, but instead got %s", err.Error())
+				}
+				if c.rpc.apiserverURL != c.expectedAPIServerURL {
+					t.Fatalf("expected validate removepool command to set the apiserver URL %s, but instead got %s", c.expectedAPIServerURL, c.rpc.apiserverURL)
+				}
+			}
+		})
+	}
+}
+
+func TestRemovePoolCmdWarnClusterAutoscaler(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	rpc := &removePoolCmd{nodePoolName: "agentpool1"}
+	cases := []struct {
+		mode     string
+		expected string
+	}{
+		{mode: "", expected: ""},
+		{mode: api.AddonModeReconcile, expected: "run aks-engine upgrade"},
+		{mode: api.AddonModeEnsureExists, expected: "remove the --nodes argument of the pool"},
+	}
+	for _, c := range cases {
+		hook.Reset()
+		rpc.warnClusterAutoscaler(c.mode)
+		entry := hook.LastEntry()
+		if c.expected == "" {
+			if entry != nil {
+				t.Errorf("expected no warning without cluster-autoscaler, got %q", entry.Message)
+			}
+			continue
+		}
+		if entry == nil || entry.Level != log.WarnLevel || !strings.Contains(entry.Message, c.expected) {
+			t.Errorf("expected a warning containing %q for addon mode %s, got %v", c.expected, c.mode, entry)
+		}
+	}
+}
+
+func TestRemovePoolCmdCheckResourceGroup(t *testing.T) {
+	t.Parallel()
+
+	client := &armhelpers.MockAKSEngineClient{FailEnsureResourceGroup: true}
+	rpc := &removePoolCmd{resourceGroupName: "testRG", client: client}
+	if err := rpc.checkResourceGroup(context.Background()); err != nil {
+		t.Fatalf("expected an existing resource group to be accepted without ensuring it, got error %s", err)
+	}
+
+	client.ResourceGroupNotFound = true
+	if err := rpc.checkResourceGroup(context.Background()); err == nil || err.Error() != "resource group testRG does not exist" {
+		t.Errorf("expected an error for a missing resource group, got %v", err)
+	}
+
+	client.FailCheckResourceGroupExistence = true
+	if err := rpc.checkResourceGroup(context.Background()); err == nil {
+		t.Errorf("expected an error when the existence of the resource group cannot be checked")
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/i18n"
+	"github.com/Azure/aks-engine/pkg/operations"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Codes of the errors of a command result that are not Azure error codes
+const (
+	resultErrorCodeDeploymentFailed = "DeploymentFailed"
+	resultErrorCodeTimeout          = "Timeout"
+	resultErrorCodeCanceled         = "Canceled"
+	resultErrorCodeInterrupted      = "Interrupted"
+	resultErrorCodeUnknown          = "Error"
+)
+
+// commandResult is the result of a command that changes a cluster, printed with --output json or yaml
+type commandResult struct {
+	Command       string `json:"command"`
+	Succeeded     bool   `json:"succeeded"`
+	ResourceGroup string `json:"resourceGroup,omitempty"`
+	// Deployments are the ARM deployments of the command, in the order they started
+	Deployments []deploymentResult `json:"deployments,omitempty"`
+	// ResourcesDeployed are the IDs of the resources that the ARM deployments created or updated
+	ResourcesDeployed []string `json:"resourcesDeployed,omitempty"`
+	// ResourcesDeleted are the IDs of the resources that the command deleted
+	ResourcesDeleted []string `json:"resourcesDeleted,omitempty"`
+	NodesAdded       []string `json:"nodesAdded,omitempty"`
+	NodesRemoved     []string `json:"nodesRemoved,omitempty"`
+	// NodesUpdated are the nodes that were upgraded, or whose certificates were rotated
+	NodesUpdated    []string     `json:"nodesUpdated,omitempty"`
+	StartTime       time.Time    `json:"startTime"`
+	DurationSeconds float64      `json:"durationSeconds"`
+	Error           *resultError `json:"error,omitempty"`
+
+	mu sync.Mutex
+}
+
+type deploymentResult struct {
+	Name              string  `json:"name"`
+	ProvisioningState string  `json:"provisioningState"`
+	DurationSeconds   float64 `json:"durationSeconds"`
+}
+
+type resultError struct {
+	// Code is the Azure error code of the error if there is one, or one of the resultErrorCode constants
+	Code              string                   `json:"code"`
+	Message           string                   `json:"message"`
+	ExtensionFailures []extensionFailureResult `json:"extensionFailures,omitempty"`
+}
+
+type extensionFailureResult struct {
+	VMName        string `json:"vmName"`
+	ExtensionName string `json:"extensionName,omitempty"`
+	ExitCode      int    `json:"exitCode"`
+	ErrorName     string `json:"errorName,omitempty"`
+	Cause         string `json:"cause,omitempty"`
+	Remediation   string `json:"remediation,omitempty"`
+}
+
+func (r *commandResult) addDeployment(d deploymentResult, resourceIDs ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Deployments = append(r.Deployments, d)
+	r.ResourcesDeployed = append(r.ResourcesDeployed, resourceIDs...)
+}
+
+func (r *commandResult) addDeletedResource(resourceID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ResourcesDeleted = append(r.ResourcesDeleted, resourceID)
+}
+
+func (r *commandResult) addNodesAdded(nodes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.NodesAdded = append(r.NodesAdded, nodes...)
+}
+
+func (r *commandResult) addNodesRemoved(nodes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.NodesRemoved = append(r.NodesRemoved, nodes...)
+}
+
+func (r *commandResult) addNodesUpdated(nodes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.NodesUpdated = append(r.NodesUpdated, nodes...)
+}
+
+// newResultError returns the code and message of err, and the decoded VM extension failures of a failed deployment
+func newResultError(err error, translator *i18n.Translator) *resultError {
+	re := &resultError{Code: resultErrorCodeUnknown, Message: err.Error()}
+	var deploymentErr *armhelpers.DeploymentError
+	switch {
+	case errors.As(err, &deploymentErr):
+		re.Code = resultErrorCodeDeploymentFailed
+		if code := azureErrorCode(deploymentErr.TopError); code != "" {
+			re.Code = code
+		}
+		for _, failure := range operations.GetCSEFailures(err, translator) {
+			re.ExtensionFailures = append(re.ExtensionFailures, extensionFailureResult{
+				VMName:        failure.VMName,
+				ExtensionName: failure.ExtensionName,
+				ExitCode:      failure.ExitCode,
+				ErrorName:     failure.ErrorName,
+				Cause:         failure.Cause,
+				Remediation:   failure.Remediation,
+			})
+		}
+	case operations.IsInterrupted(err):
+		re.Code = resultErrorCodeInterrupted
+	case errors.Is(err, context.DeadlineExceeded):
+		re.Code = resultErrorCodeTimeout
+	case errors.Is(err, context.Canceled):
+		re.Code = resultErrorCodeCanceled
+	default:
+		if code := azureErrorCode(err); code != "" {
+			re.Code = code
+		}
+	}
+	return re
+}
+
+// azureErrorCode returns the code of the Azure service error wrapped by err, or an empty string
+func azureErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var requestErr *azure.RequestError
+	if errors.As(err, &requestErr) && requestErr.ServiceError != nil {
+		return requestErr.ServiceError.Code
+	}
+	var serviceErr *azure.ServiceError
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Code
+	}
+	return ""
+}
+
+// resultRecordingClient is an AKSEngineClient that records the deployments and the deletions of resources of a command in its result
+type resultRecordingClient struct {
+	armhelpers.AKSEngineClient
+	result         *commandResult
+	subscriptionID string
+}
+
+// Unwrap returns the wrapped client
+func (c *resultRecordingClient) Unwrap() armhelpers.AKSEngineClient {
+	return c.AKSEngineClient
+}
+
+// DeployTemplate deploys the template like the wrapped client, and records the deployment and the resources it deployed
+func (c *resultRecordingClient) DeployTemplate(ctx context.Context, resourceGroupName, deploymentName string, template map[string]interface{}, parameters map[string]interface{}) (resources.DeploymentExtended, error) {
+	start := time.Now()
+	de, err := c.AKSEngineClient.DeployTemplate(ctx, resourceGroupName, deploymentName, template, parameters)
+	d := deploymentResult{
+		Name:              deploymentName,
+		ProvisioningState: string(api.Succeeded),
+		DurationSeconds:   time.Since(start).Seconds(),
+	}
+	var resourceIDs []string
+	if err != nil {
+		d.ProvisioningState = string(api.Failed)
+	} else {
+		resourceIDs = c.deployedResources(ctx, resourceGroupName, deploymentName)
+	}
+	c.result.addDeployment(d, resourceIDs...)
+	return de, err
+}
+
+// deployedResources returns the IDs of the resources a deployment created or updated
+func (c *resultRecordingClient) deployedResources(ctx context.Context, resourceGroupName, deploymentName string) []string {
+	resourceIDs, err := armhelpers.ListDeployedResources(ctx, c.AKSEngineClient, resourceGroupName, deploymentName)
+	if err != nil {
+		log.Warnf("unable to list the resources of deployment %s: %v", deploymentName, err)
+	}
+	return resourceIDs
+}
+
+// DeleteVirtualMachine deletes the VM like the wrapped client, and records its deletion
+func (c *resultRecordingClient) DeleteVirtualMachine(ctx context.Context, resourceGroup, name string) error {
+	err := c.AKSEngineClient.DeleteVirtualMachine(ctx, resourceGroup, name)
+	if err == nil {
+		c.result.addDeletedResource(c.resourceID(resourceGroup, "Microsoft.Compute/virtualMachines", name))
+	}
+	return err
+}
+
+// DeleteVirtualMachineScaleSetVM deletes the VMSS VM like the wrapped client, and records its deletion
+func (c *resultRecordingClient) DeleteVirtualMachineScaleSetVM(ctx context.Context, resourceGroup, virtualMachineScaleSet, instanceID string) error {
+	err := c.AKSEngineClient.DeleteVirtualMachineScaleSetVM(ctx, resourceGroup, virtualMachineScaleSet, instanceID)
+	if err == nil {
+		c.result.addDeletedResource(c.resourceID(resourceGroup, "Microsoft.Compute/virtualMachineScaleSets", fmt.Sprintf("%s/virtualMachines/%s", virtualMachineScaleSet, instanceID)))
+	}
+	return err
+}
+
+// DeleteVirtualMachineScaleSet deletes the VMSS like the wrapped client, and records its deletion
+func (c *resultRecordingClient) DeleteVirtualMachineScaleSet(ctx context.Context, resourceGroup, vmssName string) error {
+	err := c.AKSEngineClient.DeleteVirtualMachineScaleSet(ctx, resourceGroup, vmssName)
+	if err == nil {
+		c.result.addDeletedResource(c.resourceID(resourceGroup, "Microsoft.Compute/virtualMachineScaleSets", vmssName))
+	}
+	return err
+}
+
+// DeleteAvailabilitySet deletes the availability set like the wrapped client, and records its deletion
+func (c *resultRecordingClient) DeleteAvailabilitySet(ctx context.Context, resourceGroup, availabilitySet string) error {
+	err := c.AKSEngineClient.DeleteAvailabilitySet(ctx, resourceGroup, availabilitySet)
+	if err == nil {
+		c.result.addDeletedResource(c.resourceID(resourceGroup, "Microsoft.Compute/availabilitySets", availabilitySet))
+	}
+	return err
+}
+
+// DeleteNetworkInterface deletes the NIC like the wrapped client, and records its deletion
+func (c *resultRecordingClient) DeleteNetworkInterface(ctx context.Context, resourceGroup, nicName string) error {
+	err := c.AKSEngineClient.DeleteNetworkInterface(ctx, resourceGroup, nicName)
+	if err == nil {
+		c.result.addDeletedResource(c.resourceID(resourceGroup, "Microsoft.Network/networkInterfaces", nicName))
+	}
+	return err
+}
+
+// DeleteManagedDisk deletes the disk like the wrapped client, and records its deletion
+func (c *resultRecordingClient) DeleteManagedDisk(ctx context.Context, resourceGroupName string, diskName string) error {
+	err := c.AKSEngineClient.DeleteManagedDisk(ctx, resourceGroupName, diskName)
+	if err == nil {
+		c.result.addDeletedResource(c.resourceID(resourceGroupName, "Microsoft.Compute/disks", diskName))
+	}
+	return err
+}
+
+func (c *resultRecordingClient) resourceID(resourceGroup, resourceType, name string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/%s/%s", c.subscriptionID, resourceGroup, resourceType, name)
+}
@@ -0,0 +1,271 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/i18n"
+	"github.com/Azure/aks-engine/pkg/operations"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// deploymentOperationsClient is a mock client whose deployments have the given operations
+type deploymentOperationsClient struct {
+	*armhelpers.MockAKSEngineClient
+	operations []resources.DeploymentOperation
+}
+
+func (c *deploymentOperationsClient) ListDeploymentOperations(ctx context.Context, resourceGroupName string, deploymentName string, top *int32) (armhelpers.DeploymentOperationsListResultPage, error) {
+	return &armhelpers.MockDeploymentOperationsListResultPage{
+		Fn: func(resources.DeploymentOperationsListResult) (resources.DeploymentOperationsListResult, error) {
+			return resources.DeploymentOperationsListResult{}, nil
+		},
+		Dolr: resources.DeploymentOperationsListResult{Value: &c.operations},
+	}, nil
+}
+
+// vmImageFetcherClient is a mock client of a cloud, like Azure Stack Hub, whose VM images are validated before they are used
+type vmImageFetcherClient struct {
+	*armhelpers.MockAKSEngineClient
+}
+
+func (c *vmImageFetcherClient) ListVirtualMachineImages(ctx context.Context, location, publisherName, offer, skus string) (compute.ListVirtualMachineImageResource, error) {
+	return compute.ListVirtualMachineImageResource{Value: &[]compute.VirtualMachineImageResource{{Name: to.StringPtr("1.0.0")}}}, nil
+}
+
+func (c *vmImageFetcherClient) GetVirtualMachineImage(ctx context.Context, location, publisherName, offer, skus, version string) (compute.VirtualMachineImage, error) {
+	return compute.VirtualMachineImage{}, nil
+}
+
+func deploymentOperation(state, resourceID string) resources.DeploymentOperation {
+	return resources.DeploymentOperation{
+		Properties: &resources.DeploymentOperationProperties{
+			ProvisioningState: to.StringPtr(state),
+			TargetResource:    &resources.TargetResource{ID: to.StringPtr(resourceID)},
+		},
+	}
+}
+
+func TestRunWithResult(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	cases := []struct {
+		name   string
+		output string
+		run    func(*commandResult) error
+		expect func(*GomegaWithT, string, error)
+	}{
+		{
+			name:   "human",
+			output: outputHuman,
+			run: func(r *commandResult) error {
+				r.addNodesRemoved("k8s-agentpool-12345678-0")
+				return nil
+			},
+			expect: func(g *GomegaWithT, out string, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(out).To(BeEmpty())
+			},
+		},
+		{
+			name:   "json",
+			output: outputJSON,
+			run: func(r *commandResult) error {
+				r.ResourceGroup = "rg1"
+				r.addNodesRemoved("k8s-agentpool-12345678-0")
+				return nil
+			},
+			expect: func(g *GomegaWithT, out string, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				var result map[string]interface{}
+				g.Expect(json.Unmarshal([]byte(out), &result)).To(Succeed())
+				g.Expect(result).To(HaveKeyWithValue("command", "scale"))
+				g.Expect(result).To(HaveKeyWithValue("succeeded", true))
+				g.Expect(result).To(HaveKeyWithValue("resourceGroup", "rg1"))
+				g.Expect(result).To(HaveKeyWithValue("nodesRemoved", []interface{}{"k8s-agentpool-12345678-0"}))
+				g.Expect(result).To(HaveKey("startTime"))
+				g.Expect(result).To(HaveKey("durationSeconds"))
+				g.Expect(result).NotTo(HaveKey("error"))
+				g.Expect(result).NotTo(HaveKey("nodesAdded"))
+			},
+		},
+		{
+			name:   "yaml with an error",
+			output: outputYAML,
+			run: func(r *commandResult) error {
+				return errors.Wrap(&armhelpers.DeploymentError{
+					DeploymentName: "deployment1",
+					TopError: &azure.RequestError{
+						ServiceError: &azure.ServiceError{Code: "InvalidTemplateDeployment"},
+					},
+				}, "deploying the scale up template")
+			},
+			expect: func(g *GomegaWithT, out string, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(out).To(ContainSubstring("command: scale\n"))
+				g.Expect(out).To(ContainSubstring("succeeded: false\n"))
+				g.Expect(out).To(ContainSubstring("  code: InvalidTemplateDeployment\n"))
+				g.Expect(out).To(ContainSubstring("  message: 'deploying the scale up template: DeploymentName[deployment1]"))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		var out bytes.Buffer
+		cmd := &cobra.Command{Use: "scale"}
+		cmd.SetOutput(&out)
+		args := outputArgs{output: c.output}
+		err := args.runWithResult(cmd, func() error {
+			return c.run(&args.result)
+		})
+		t.Run(c.name, func(t *testing.T) {
+			c.expect(NewGomegaWithT(t), out.String(), err)
+		})
+	}
+
+	ran := false
+	args := outputArgs{output: "xml"}
+	err := args.runWithResult(&cobra.Command{Use: "scale"}, func() error {
+		ran = true
+		return nil
+	})
+	g.Expect(err).To(MatchError(`--output: ERROR: format unsupported. format="xml"`))
+	g.Expect(ran).To(BeFalse())
+}
+
+func TestNewResultError(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		err  error
+		code string
+	}{
+		{
+			name: "deployment error",
+			err:  &armhelpers.DeploymentError{DeploymentName: "deployment1"},
+			code: resultErrorCodeDeploymentFailed,
+		},
+		{
+			name: "deployment error with an Azure code",
+			err: errors.Wrap(&armhelpers.DeploymentError{
+				TopError: &azure.ServiceError{Code: "QuotaExceeded"},
+			}, "deploying"),
+			code: "QuotaExceeded",
+		},
+		{
+			name: "Azure request error",
+			err: errors.Wrap(&azure.RequestError{
+				ServiceError: &azure.ServiceError{Code: "ResourceGroupNotFound"},
+			}, "getting the resource group"),
+			code: "ResourceGroupNotFound",
+		},
+		{
+			name: "timeout",
+			err:  errors.Wrap(context.DeadlineExceeded, "waiting for the nodes"),
+			code: resultErrorCodeTimeout,
+		},
+		{
+			name: "canceled",
+			err:  context.Canceled,
+			code: resultErrorCodeCanceled,
+		},
+		{
+			name: "interrupted",
+			err:  errors.Wrap(&operations.InterruptedError{Step: "upgrading the agent nodes"}, "upgrading cluster"),
+			code: resultErrorCodeInterrupted,
+		},
+		{
+			name: "other error",
+			err:  errors.New("some error"),
+			code: resultErrorCodeUnknown,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewGomegaWithT(t)
+			re := newResultError(c.err, &i18n.Translator{})
+			g.Expect(re.Code).To(Equal(c.code))
+			g.Expect(re.Message).To(Equal(c.err.Error()))
+			g.Expect(re.ExtensionFailures).To(BeEmpty())
+		})
+	}
+}
+
+func TestResultRecordingClient(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	vmID := "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/k8s-agentpool-12345678-2"
+	nicID := "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Network/networkInterfaces/k8s-agentpool-12345678-nic-2"
+	mock := &armhelpers.MockAKSEngineClient{}
+	args := outputArgs{output: outputJSON}
+	client := args.withResultRecording(&deploymentOperationsClient{
+		MockAKSEngineClient: mock,
+		operations: []resources.DeploymentOperation{
+			deploymentOperation(string(api.Succeeded), nicID),
+			deploymentOperation(string(api.Succeeded), vmID),
+			deploymentOperation(string(api.Succeeded), vmID),
+			deploymentOperation(string(api.Failed), "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/extensions/cse-agent-2"),
+		},
+	}, "sub1")
+
+	_, err := client.DeployTemplate(context.Background(), "rg1", "deployment1", nil, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	mock.FailDeployTemplate = true
+	_, err = client.DeployTemplate(context.Background(), "rg1", "deployment2", nil, nil)
+	g.Expect(err).To(HaveOccurred())
+
+	g.Expect(client.DeleteVirtualMachine(context.Background(), "rg1", "k8s-agentpool-12345678-0")).To(Succeed())
+	g.Expect(client.DeleteVirtualMachineScaleSetVM(context.Background(), "rg1", "k8s-agentpool-12345678-vmss", "3")).To(Succeed())
+	mock.FailDeleteVirtualMachine = true
+	g.Expect(client.DeleteVirtualMachine(context.Background(), "rg1", "k8s-agentpool-12345678-1")).NotTo(Succeed())
+
+	result := &args.result
+	g.Expect(result.Deployments).To(HaveLen(2))
+	g.Expect(result.Deployments[0].Name).To(Equal("deployment1"))
+	g.Expect(result.Deployments[0].ProvisioningState).To(Equal(string(api.Succeeded)))
+	g.Expect(result.Deployments[1].Name).To(Equal("deployment2"))
+	g.Expect(result.Deployments[1].ProvisioningState).To(Equal(string(api.Failed)))
+	g.Expect(result.ResourcesDeployed).To(Equal([]string{nicID, vmID}))
+	g.Expect(result.ResourcesDeleted).To(Equal([]string{
+		"/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/k8s-agentpool-12345678-0",
+		"/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachineScaleSets/k8s-agentpool-12345678-vmss/virtualMachines/3",
+	}))
+
+	human := outputArgs{output: outputHuman}
+	g.Expect(human.withResultRecording(mock, "sub1")).To(BeIdenticalTo(mock))
+}
+
+func TestResultRecordingClientValidateRequiredImages(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	args := outputArgs{output: outputJSON}
+	client := args.withResultRecording(armhelpers.WithDeploymentProgress(&vmImageFetcherClient{
+		MockAKSEngineClient: &armhelpers.MockAKSEngineClient{},
+	}, armhelpers.NewTextDeploymentProgressReporter(io.Discard), time.Second), "sub1")
+	properties := &api.Properties{
+		MasterProfile: &api.MasterProfile{Distro: api.AKSUbuntu2004},
+	}
+	g.Expect(armhelpers.ValidateRequiredImages(context.Background(), "local", properties, client)).To(Succeed())
+}
@@ -4,15 +4,19 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/api/common"
 	"github.com/Azure/aks-engine/pkg/api/vlabs"
 	"github.com/Azure/aks-engine/pkg/armhelpers"
 	"github.com/Azure/aks-engine/pkg/armhelpers/azurestack"
@@ -28,6 +32,7 @@ import (
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
 	ini "gopkg.in/ini.v1"
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -36,8 +41,14 @@ const (
 	rootLongDescription  = "AKS Engine deploys and manages Kubernetes clusters in Azure"
 )
 
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
 var (
 	debug            bool
+	logFormat        string
 	dumpDefaultModel bool
 )
 
@@ -47,10 +58,18 @@ func NewRootCmd() *cobra.Command {
 		Use:   rootName,
 		Short: rootShortDescription,
 		Long:  rootLongDescription,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			if debug {
 				log.SetLevel(log.DebugLevel)
 			}
+			switch logFormat {
+			case logFormatText:
+			case logFormatJSON:
+				log.SetFormatter(&log.JSONFormatter{})
+			default:
+				return errors.Errorf("--log-format: ERROR: format unsupported. format=%q", logFormat)
+			}
+			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if dumpDefaultModel {
@@ -62,6 +81,7 @@ func NewRootCmd() *cobra.Command {
 
 	p := rootCmd.PersistentFlags()
 	p.BoolVar(&debug, "debug", false, "enable verbose debug logs")
+	p.StringVar(&logFormat, "log-format", logFormatText, "format of the logs (text, or json for one JSON object per line)")
 
 	f := rootCmd.Flags()
 	f.BoolVar(&dumpDefaultModel, "show-default-model", false, "Dump the default API model to stdout")
@@ -71,14 +91,18 @@ func NewRootCmd() *cobra.Command {
 	rootCmd.AddCommand(newDeployCmd())
 	rootCmd.AddCommand(newGetLogsCmd())
 	rootCmd.AddCommand(newGetVersionsCmd())
+	rootCmd.AddCommand(newGetImagesCmd())
 	rootCmd.AddCommand(newOrchestratorsCmd())
 	rootCmd.AddCommand(newUpgradeCmd())
 	rootCmd.AddCommand(newScaleCmd())
 	rootCmd.AddCommand(newUpdateCmd())
 	rootCmd.AddCommand(newRotateCertsCmd())
 	rootCmd.AddCommand(newAddPoolCmd())
+	rootCmd.AddCommand(newRemovePoolCmd())
 	rootCmd.AddCommand(newGetLocationsCmd())
 	rootCmd.AddCommand(newGetSkusCmd())
+	rootCmd.AddCommand(newConvertCmd())
+	rootCmd.AddCommand(newAddonsCmd())
 	rootCmd.AddCommand(getCompletionCmd(rootCmd))
 
 	return rootCmd
@@ -134,6 +158,172 @@ func addAuthFlags(authArgs *authArgs, f *flag.FlagSet) {
 	f.StringVar(&authArgs.language, "language", "en-us", "language to return error messages in")
 }
 
+const (
+	progressText = "text"
+	progressJSON = "json"
+	progressNone = "none"
+)
+
+type progressArgs struct {
+	progress         string
+	progressInterval time.Duration
+}
+
+func addProgressFlags(progressArgs *progressArgs, f *flag.FlagSet) {
+	f.StringVar(&progressArgs.progress, "progress", progressText, "how to report the progress of ARM deployments to stderr (`text`, `json` for one JSON event per line, `none`)")
+	f.DurationVar(&progressArgs.progressInterval, "progress-interval", armhelpers.DefaultDeploymentProgressInterval, "interval between two polls of the operations of a running ARM deployment")
+}
+
+func (progressArgs *progressArgs) validateProgressArgs() error {
+	switch progressArgs.progress {
+	case "", progressText, progressJSON, progressNone:
+		return nil
+	default:
+		return errors.Errorf("--progress: ERROR: format unsupported. format=%q", progressArgs.progress)
+	}
+}
+
+// withDeploymentProgress wraps client to report the progress of its ARM deployments in the format of --progress
+func (progressArgs *progressArgs) withDeploymentProgress(client armhelpers.AKSEngineClient) armhelpers.AKSEngineClient {
+	interval := progressArgs.progressInterval
+	if interval <= 0 {
+		interval = armhelpers.DefaultDeploymentProgressInterval
+	}
+	switch progressArgs.progress {
+	case progressText:
+		return armhelpers.WithDeploymentProgress(client, armhelpers.NewTextDeploymentProgressReporter(os.Stderr), interval)
+	case progressJSON:
+		return armhelpers.WithDeploymentProgress(client, armhelpers.NewJSONDeploymentProgressReporter(os.Stderr), interval)
+	default:
+		return client
+	}
+}
+
+// newLogger returns a logger for the operations of a command, which writes to stderr at the level of --debug and in the format of --log-format
+func newLogger() *log.Entry {
+	logger := log.New()
+	logger.SetLevel(log.GetLevel())
+	if logFormat == logFormatJSON {
+		logger.SetFormatter(&log.JSONFormatter{})
+	}
+	return log.NewEntry(logger)
+}
+
+// ExitCodeInterrupted is the exit code of a command that stopped at a safe point after SIGINT or SIGTERM,
+// the same command can be run again to resume it
+const ExitCodeInterrupted = 130
+
+// interruptContext returns a context canceled by the first SIGINT or SIGTERM, so that a long-running command stops
+// once the node it is working on is done. The signals are handled by default afterwards, a second one exits immediately
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			log.Warnf("Received %s, stopping once the current step is done. Send it again to exit immediately, which can leave a node deleted", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+const (
+	outputHuman = "human"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+type outputArgs struct {
+	output string
+	result commandResult
+}
+
+func addOutputFlag(outputArgs *outputArgs, f *flag.FlagSet, shorthand string) {
+	f.StringVarP(&outputArgs.output, "output", shorthand, outputHuman, "format of the result of the command (human, json or yaml), which is printed to stdout while the logs and reports are written to stderr")
+}
+
+// structuredOutput returns true if the result of the command is printed as JSON or YAML
+func (outputArgs *outputArgs) structuredOutput() bool {
+	return outputArgs.output == outputJSON || outputArgs.output == outputYAML
+}
+
+// textOutput returns where to print the human readable reports of the command, stderr when stdout is reserved for its result
+func (outputArgs *outputArgs) textOutput() io.Writer {
+	if outputArgs.structuredOutput() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// withResultRecording wraps client to record the deployments and deletions of resources of the command in its result
+func (outputArgs *outputArgs) withResultRecording(client armhelpers.AKSEngineClient, subscriptionID string) armhelpers.AKSEngineClient {
+	if !outputArgs.structuredOutput() {
+		return client
+	}
+	return &resultRecordingClient{
+		AKSEngineClient: client,
+		result:          &outputArgs.result,
+		subscriptionID:  subscriptionID,
+	}
+}
+
+// runWithResult runs a command, then prints its result in the format of --output
+func (outputArgs *outputArgs) runWithResult(cmd *cobra.Command, run func() error) error {
+	switch outputArgs.output {
+	case "", outputHuman:
+		return run()
+	case outputJSON, outputYAML:
+	default:
+		return errors.Errorf("--output: ERROR: format unsupported. format=%q", outputArgs.output)
+	}
+
+	// stdout is reserved for the result
+	log.SetOutput(os.Stderr)
+	result := &outputArgs.result
+	result.Command = cmd.Name()
+	result.StartTime = time.Now()
+	err := run()
+	result.DurationSeconds = time.Since(result.StartTime).Seconds()
+	result.Succeeded = err == nil
+	if err != nil {
+		result.Error = newResultError(err, &i18n.Translator{})
+	}
+	if printErr := printResult(cmd.OutOrStdout(), result, outputArgs.output); printErr != nil {
+		log.Errorf("printing the result of %s: %s", cmd.Name(), printErr)
+	}
+	return err
+}
+
+func printResult(w io.Writer, result *commandResult, format string) error {
+	var b []byte
+	var err error
+	if format == outputYAML {
+		b, err = yaml.Marshal(result)
+	} else {
+		b, err = helpers.JSONMarshalIndent(result, "", "  ", false)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, strings.TrimSuffix(string(b), "\n"))
+	return err
+}
+
+type whatIfArgs struct {
+	whatIf bool
+}
+
+func addWhatIfFlag(whatIfArgs *whatIfArgs, f *flag.FlagSet) {
+	f.BoolVar(&whatIfArgs.whatIf, "what-if", false, "print the changes the ARM deployment would make to the resource group, then exit without deploying")
+}
+
 // getAuthArgs allows the authArgs to be stubbed behind the authProvider interface, and be its own provider when not in tests.
 func (authArgs *authArgs) getAuthArgs() *authArgs {
 	return authArgs
@@ -378,3 +568,23 @@ func writeArtifacts(outputDirectory string, cs *api.ContainerService, apiVersion
 	w := &engine.ArtifactWriter{Translator: translator}
 	return w.WriteTLSArtifacts(cs, apiVersion, tpl, params, outputDirectory, true, false)
 }
+
+// logValidationErrors logs every api model problem carried by err, one per line, so that all of them
+// can be fixed in one pass instead of one per run
+func logValidationErrors(err error, translator *i18n.Translator) {
+	verrs, ok := errors.Cause(err).(common.ValidationErrors)
+	if !ok {
+		return
+	}
+	for _, e := range verrs {
+		msg := e.Message()
+		if translator != nil && translator.Locale != nil {
+			msg = e.Translate(translator)
+		}
+		if e.Severity == common.ValidationSeverityError {
+			log.Errorf("%s: %s", e.Field, msg)
+		} else {
+			log.Warnf("%s (%s): %s", e.Field, e.Severity, msg)
+		}
+	}
+}
@@ -13,10 +13,13 @@ import (
 	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/spf13/cobra"
 	"gopkg.in/ini.v1"
 
 	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/api/common"
 	"github.com/Azure/aks-engine/pkg/armhelpers"
 	"github.com/Azure/aks-engine/pkg/armhelpers/azurestack/testserver"
 	"github.com/Azure/aks-engine/pkg/helpers"
@@ -46,7 +49,7 @@ func TestNewRootCmd(t *testing.T) {
 		t.Fatalf("root command should have use %s equal %s, short %s equal %s and long %s equal to %s", command.Use, rootName, command.Short, rootShortDescription, command.Long, rootLongDescription)
 	}
 	// The commands need to be listed in alphabetical order
-	expectedCommands := []*cobra.Command{newAddPoolCmd(), getCompletionCmd(command), newDeployCmd(), newGenerateCmd(), newGetLocationsCmd(), newGetLogsCmd(), newGetSkusCmd(), newGetVersionsCmd(), newOrchestratorsCmd(), newRotateCertsCmd(), newScaleCmd(), newUpdateCmd(), newUpgradeCmd(), newVersionCmd()}
+	expectedCommands := []*cobra.Command{newAddonsCmd(), newAddPoolCmd(), getCompletionCmd(command), newConvertCmd(), newDeployCmd(), newGenerateCmd(), newGetImagesCmd(), newGetLocationsCmd(), newGetLogsCmd(), newGetSkusCmd(), newGetVersionsCmd(), newOrchestratorsCmd(), newRemovePoolCmd(), newRotateCertsCmd(), newScaleCmd(), newUpdateCmd(), newUpgradeCmd(), newVersionCmd()}
 	rc := command.Commands()
 
 	for i, c := range expectedCommands {
@@ -86,6 +89,18 @@ func TestDebugArg(t *testing.T) {
 	// TODO: examine command output
 }
 
+func TestLogFormatArg(t *testing.T) {
+	// not parallel: the flag sets the log format of the package
+	defer func() { logFormat = logFormatText }()
+
+	command := NewRootCmd()
+	command.SetArgs([]string{"--log-format", "xml", "--show-default-model"})
+	err := command.Execute()
+	if err == nil || err.Error() != `--log-format: ERROR: format unsupported. format="xml"` {
+		t.Fatalf("expected an unsupported log format error, got %v", err)
+	}
+}
+
 func TestCompletionCommand(t *testing.T) {
 	t.Parallel()
 
@@ -408,6 +423,28 @@ func TestValidateAuthArgs(t *testing.T) {
 	}
 }
 
+func TestProgressArgs(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	for _, progress := range []string{"", progressText, progressJSON, progressNone} {
+		args := progressArgs{progress: progress}
+		g.Expect(args.validateProgressArgs()).To(Succeed())
+	}
+	args := progressArgs{progress: "yaml"}
+	g.Expect(args.validateProgressArgs()).To(MatchError(`--progress: ERROR: format unsupported. format="yaml"`))
+
+	client := &armhelpers.MockAKSEngineClient{}
+	for _, progress := range []string{"", progressNone} {
+		args := progressArgs{progress: progress}
+		g.Expect(args.withDeploymentProgress(client)).To(BeIdenticalTo(client))
+	}
+	for _, progress := range []string{progressText, progressJSON} {
+		args := progressArgs{progress: progress}
+		g.Expect(args.withDeploymentProgress(client)).NotTo(BeIdenticalTo(client))
+	}
+}
+
 func isValidIdentitySystem(s string) bool {
 	return s == "azure_ad" || s == "adfs"
 }
@@ -599,6 +636,41 @@ func TestWriteArtifacts(t *testing.T) {
 	g.Expect(err).NotTo(HaveOccurred())
 }
 
+func TestLogValidationErrors(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	verrs := common.ValidationErrors{}
+	verrs.Add("properties.masterProfile", errors.New("missing dnsPrefix"))
+	verrs.Add("properties.agentPoolProfiles", common.NewValidationError("properties.agentPoolProfiles[1].name", "profile name '%s' already exists", "pool1"))
+	verrs.AddWarning("properties.orchestratorProfile", common.ValidationSeverityDeprecation, "%s is deprecated", "dockerEngineVersion")
+	logValidationErrors(errors.Wrap(verrs, "validating API model"), &i18n.Translator{})
+
+	entries := hook.AllEntries()
+	if len(entries) != 3 {
+		t.Fatalf("expected one log entry per validation error, got %d", len(entries))
+	}
+	expected := []struct {
+		level   log.Level
+		message string
+	}{
+		{log.ErrorLevel, "properties.masterProfile: missing dnsPrefix"},
+		{log.ErrorLevel, "properties.agentPoolProfiles[1].name: profile name 'pool1' already exists"},
+		{log.WarnLevel, "properties.orchestratorProfile (deprecation): dockerEngineVersion is deprecated"},
+	}
+	for i, e := range expected {
+		if entries[i].Level != e.level || entries[i].Message != e.message {
+			t.Errorf("expected %s entry %q, got %s entry %q", e.level, e.message, entries[i].Level, entries[i].Message)
+		}
+	}
+
+	hook.Reset()
+	logValidationErrors(errors.New("not a validation error"), nil)
+	if len(hook.AllEntries()) != 0 {
+		t.Errorf("expected no log entry for other errors, got %d", len(hook.AllEntries()))
+	}
+}
+
 func makeTmpDir(t *testing.T) (string, func()) {
 	tmpDir, err := os.MkdirTemp(os.TempDir(), "_tmp_dir")
 	if err != nil {
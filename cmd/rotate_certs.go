@@ -5,10 +5,12 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -17,9 +19,11 @@ import (
 	"github.com/Azure/aks-engine/pkg/api/common"
 	"github.com/Azure/aks-engine/pkg/engine"
 	"github.com/Azure/aks-engine/pkg/helpers"
+	"github.com/Azure/aks-engine/pkg/helpers/runcommand"
 	"github.com/Azure/aks-engine/pkg/helpers/ssh"
 	"github.com/Azure/aks-engine/pkg/i18n"
 	"github.com/Azure/aks-engine/pkg/kubernetes"
+	"github.com/Azure/aks-engine/pkg/operations"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -61,6 +65,7 @@ type fileMap = map[string]*ssh.RemoteFile
 
 type rotateCertsCmd struct {
 	authProvider
+	outputArgs
 
 	// user input
 	resourceGroupName      string
@@ -71,6 +76,7 @@ type rotateCertsCmd struct {
 	linuxSSHPrivateKeyPath string
 	outputDirectory        string
 	force                  bool
+	windowsRunCommand      bool
 
 	// computed
 	backupDirectory   string
@@ -86,6 +92,7 @@ type rotateCertsCmd struct {
 	windowsAuthConfig *ssh.AuthConfig
 	jumpbox           *ssh.JumpBox
 	sshPort           int
+	runCommand        *runcommand.Client
 }
 
 func newRotateCertsCmd() *cobra.Command {
@@ -98,17 +105,21 @@ func newRotateCertsCmd() *cobra.Command {
 		Short: rotateCertsShortDescription,
 		Long:  rotateCertsLongDescription,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := rcc.validateArgs(); err != nil {
-				return errors.Wrap(err, "validating rotate-certs args")
-			}
-			if err := rcc.loadAPIModel(); err != nil {
-				return errors.Wrap(err, "loading API model")
-			}
-			if err := rcc.init(); err != nil {
-				return err
-			}
-			cmd.SilenceUsage = true
-			return rcc.run()
+			return rcc.runWithResult(cmd, func() error {
+				if err := rcc.validateArgs(); err != nil {
+					return errors.Wrap(err, "validating rotate-certs args")
+				}
+				if err := rcc.loadAPIModel(); err != nil {
+					return errors.Wrap(err, "loading API model")
+				}
+				if err := rcc.init(); err != nil {
+					return err
+				}
+				cmd.SilenceUsage = true
+				ctx, stop := interruptContext()
+				defer stop()
+				return rcc.run(ctx)
+			})
 		},
 	}
 	f := command.Flags()
@@ -126,8 +137,10 @@ func newRotateCertsCmd() *cobra.Command {
 
 	f.StringVarP(&rcc.newCertsPath, "certificate-profile", "", "", "path to a JSON file containing the new set of certificates")
 	f.BoolVarP(&rcc.force, "force", "", false, "force execution even if API Server is not responsive")
+	f.BoolVar(&rcc.windowsRunCommand, "windows-run-command", false, "rotate the certificates of the Windows nodes through the Azure Run Command API instead of SSH")
 
 	addAuthFlags(rcc.getAuthArgs(), f)
+	addOutputFlag(&rcc.outputArgs, f, "o")
 
 	return command
 }
@@ -201,8 +214,8 @@ func (rcc *rotateCertsCmd) loadAPIModel() (err error) {
 	} else if rcc.cs.Location != rcc.location {
 		return errors.New("--location flag does not match api-model location")
 	}
-	if rcc.cs.Properties.WindowsProfile != nil && !rcc.cs.Properties.WindowsProfile.GetSSHEnabled() {
-		return errors.New("SSH not enabled on Windows nodes. SSH is required in order to rotate agent nodes certificates")
+	if rcc.cs.Properties.WindowsProfile != nil && !rcc.cs.Properties.WindowsProfile.GetSSHEnabled() && !rcc.windowsRunCommand {
+		return errors.New("SSH not enabled on Windows nodes. SSH, or flag --windows-run-command, is required in order to rotate agent nodes certificates")
 	}
 	if err = rcc.getAuthArgs().validateAuthArgs(); err != nil {
 		return errors.Wrap(err, "failed to get validate auth args")
@@ -212,6 +225,9 @@ func (rcc *rotateCertsCmd) loadAPIModel() (err error) {
 		return errors.Wrap(err, "failed to get ARM client")
 	}
 	rcc.armClient = ops.NewARMClientWrapper(armClient, rotateCertsDefaultInterval, rotateCertsDefaultTimeout)
+	if rcc.windowsRunCommand {
+		rcc.runCommand = runcommand.NewClient(armClient, rcc.resourceGroupName)
+	}
 	return
 }
 
@@ -239,13 +255,21 @@ func (rcc *rotateCertsCmd) init() (err error) {
 	return
 }
 
-func (rcc *rotateCertsCmd) run() (err error) {
+// run rotates the certificates of the control plane nodes, then of the agent nodes.
+// Canceling ctx interrupts the rotation before the agent nodes or between two of them
+func (rcc *rotateCertsCmd) run(ctx context.Context) (err error) {
+	rcc.result.ResourceGroup = rcc.resourceGroupName
 	if err = rcc.backupCerts(); err != nil {
 		return errors.Wrap(err, "backing up current state")
 	}
 	if err = rcc.updateCertificateProfile(); err != nil {
 		return errors.Wrap(err, "updating certificate profile")
 	}
+	defer func() {
+		if operations.IsInterrupted(err) {
+			rcc.handleInterruption(err)
+		}
+	}()
 	rcc.kubeClient, err = rcc.getKubeClient()
 	if err != nil {
 		return errors.Wrap(err, "creating Kubernetes client")
@@ -272,10 +296,16 @@ func (rcc *rotateCertsCmd) run() (err error) {
 		}
 	}
 
+	if err = operations.CheckInterrupted(ctx, "rotating the control plane certificates"); err != nil {
+		return err
+	}
 	if err = rcc.rotateMasterCerts(); err != nil {
 		return errors.Wrap(err, "rotating certificates")
 	}
-	if err = rcc.rotateAgentCerts(); err != nil {
+	if err = operations.CheckInterrupted(ctx, "rotating the agent certificates"); err != nil {
+		return err
+	}
+	if err = rcc.rotateAgentCerts(ctx); err != nil {
 		return errors.Wrap(err, "rotating certificates")
 	}
 
@@ -287,6 +317,22 @@ func (rcc *rotateCertsCmd) run() (err error) {
 	return nil
 }
 
+// handleInterruption saves the new certificates to the output directory, so that running the command again
+// with --certificate-profile resumes the rotation with the certificates already distributed to some nodes
+func (rcc *rotateCertsCmd) handleInterruption(err error) {
+	b, marshalErr := json.MarshalIndent(rcc.cs.Properties.CertificateProfile, "", "  ")
+	if marshalErr != nil {
+		log.Errorf("serializing the new certificate profile: %s", marshalErr)
+		return
+	}
+	profilePath := path.Join(rcc.outputDirectory, "certificateProfile.json")
+	if writeErr := os.WriteFile(profilePath, b, 0600); writeErr != nil {
+		log.Errorf("saving the new certificate profile: %s", writeErr)
+		return
+	}
+	log.Warnf("The certificate rotation was %s. Run the same rotate-certs command with --certificate-profile %s to resume it with the same certificates", err, profilePath)
+}
+
 func (rcc *rotateCertsCmd) backupCerts() error {
 	log.Infof("Backing up artifacts to directory %s", rcc.backupDirectory)
 	if err := writeArtifacts(rcc.backupDirectory, rcc.cs, rcc.apiVersion, rcc.loader.Translator); err != nil {
@@ -390,6 +436,8 @@ func (rcc *rotateCertsCmd) distributeCerts() (err error) {
 			err = upload(masterCerts, node)
 		} else if isLinuxAgent(node) {
 			err = upload(linuxCerts, node)
+		} else if isWindowsAgent(node) && rcc.runCommand != nil {
+			err = rcc.uploadRunCommand(windowsCerts, node)
 		} else if isWindowsAgent(node) {
 			err = upload(windowsCerts, node)
 		}
@@ -422,13 +470,14 @@ func (rcc *rotateCertsCmd) rotateMasterCerts() (err error) {
 	if err = rcc.waitForNodesReady(keys(rcc.nodes)); err != nil {
 		return err
 	}
+	rcc.result.addNodesUpdated(sortedKeys(rcc.nodes)...)
 	if err = rcc.waitForControlPlaneReadiness(); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (rcc *rotateCertsCmd) rotateAgentCerts() (err error) {
+func (rcc *rotateCertsCmd) rotateAgentCerts(ctx context.Context) (err error) {
 	rcc.nodes, err = rcc.getAgentNodes()
 	if err != nil {
 		return errors.Wrap(err, "listing cluster nodes")
@@ -440,7 +489,7 @@ func (rcc *rotateCertsCmd) rotateAgentCerts() (err error) {
 	if err = rcc.backupRemote(); err != nil {
 		return err
 	}
-	if err = rcc.rotateAgents(); err != nil {
+	if err = rcc.rotateAgents(ctx); err != nil {
 		return err
 	}
 	log.Infoln("Deleting temporary artifacts from agent nodes")
@@ -450,6 +499,7 @@ func (rcc *rotateCertsCmd) rotateAgentCerts() (err error) {
 	if err = rcc.waitForNodesReady(keys(rcc.nodes)); err != nil {
 		return err
 	}
+	rcc.result.addNodesUpdated(sortedKeys(rcc.nodes)...)
 	log.Info("Recreating service account tokens")
 	if err = ops.RotateServiceAccountTokens(rcc.kubeClient); err != nil {
 		return err
@@ -467,7 +517,7 @@ func (rcc *rotateCertsCmd) backupRemote() error {
 		if err := execStepsSequence(isLinux, node, execRemoteFunc(remoteBashScript(step))); err != nil {
 			return errors.Wrapf(err, "executing %s function on remote host %s", step, node.URI)
 		}
-		if err := execStepsSequence(isWindowsAgent, node, execRemoteFunc(remotePowershellScript("Backup"))); err != nil {
+		if err := execStepsSequence(isWindowsAgent, node, rcc.execWindowsFunc("Backup")); err != nil {
 			return errors.Wrapf(err, "executing %s function on remote host %s", step, node.URI)
 		}
 	}
@@ -504,15 +554,18 @@ func (rcc *rotateCertsCmd) rotateMasters() error {
 	return nil
 }
 
-func (rcc *rotateCertsCmd) rotateAgents() error {
+func (rcc *rotateCertsCmd) rotateAgents(ctx context.Context) error {
 	log.Info("Rotating agents certificates")
 	step := "agent_certs"
 	for _, node := range rcc.nodes {
+		if err := operations.CheckInterrupted(ctx, fmt.Sprintf("rotating the certificates of remote host %s", node.URI)); err != nil {
+			return err
+		}
 		log.Debugf("Node: %s. Step: %s", node.URI, step)
 		if err := execStepsSequence(isLinuxAgent, node, execRemoteFunc(remoteBashScript(step)), deletePodFunc(rcc.kubeClient, kubeProxyLabels)); err != nil {
 			return errors.Wrapf(err, "executing %s function on remote host %s", step, node.URI)
 		}
-		if err := execStepsSequence(isWindowsAgent, node, execRemoteFunc(remotePowershellScript("Start-CertRotation"))); err != nil {
+		if err := execStepsSequence(isWindowsAgent, node, rcc.execWindowsFunc("Start-CertRotation")); err != nil {
 			return errors.Wrapf(err, "executing Start-CertRotation function on remote host %s", node.URI)
 		}
 	}
@@ -526,7 +579,7 @@ func (rcc *rotateCertsCmd) cleanupRemote() error {
 		if err := execStepsSequence(isLinux, node, execRemoteFunc(remoteBashScript(step))); err != nil {
 			return errors.Wrapf(err, "executing %s function on remote host %s", step, node.URI)
 		}
-		if err := execStepsSequence(isWindowsAgent, node, execRemoteFunc(remotePowershellScript("Clean"))); err != nil {
+		if err := execStepsSequence(isWindowsAgent, node, rcc.execWindowsFunc("Clean")); err != nil {
 			return errors.Wrapf(err, "executing %s function on remote host %s", step, node.URI)
 		}
 	}
@@ -566,6 +619,43 @@ func execRemoteFunc(script string) func(node *ssh.RemoteHost) error {
 	}
 }
 
+// execWindowsFunc returns a step that runs a function of rotate-certs.ps1 on a Windows node,
+// through the Azure Run Command API if --windows-run-command is set
+func (rcc *rotateCertsCmd) execWindowsFunc(function string) func(node *ssh.RemoteHost) error {
+	if rcc.runCommand == nil {
+		return execRemoteFunc(remotePowershellScript(function))
+	}
+	return func(node *ssh.RemoteHost) error {
+		ctx, cancel := context.WithTimeout(context.Background(), rotateCertsDefaultTimeout)
+		defer cancel()
+		out, err := rcc.runCommand.ExecuteRemote(ctx, node.URI, runCommandPowershellScript(function)...)
+		if err != nil {
+			log.Debugf("Remote command output: %s", out)
+		}
+		return err
+	}
+}
+
+// uploadRunCommand writes files on a Windows node through the Azure Run Command API
+func (rcc *rotateCertsCmd) uploadRunCommand(files fileMap, node *ssh.RemoteHost) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	remoteFiles := make([]*ssh.RemoteFile, 0, len(files))
+	for _, name := range names {
+		remoteFiles = append(remoteFiles, files[name])
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), rotateCertsDefaultTimeout)
+	defer cancel()
+	if out, err := rcc.runCommand.CopyToRemote(ctx, node.URI, remoteFiles...); err != nil {
+		log.Debugf("Remote command output: %s", out)
+		return errors.Wrap(err, "uploading certificate")
+	}
+	return nil
+}
+
 func deletePodFunc(client *kubernetes.CompositeClientSet, labels string) func(node *ssh.RemoteHost) error {
 	return func(node *ssh.RemoteHost) error {
 		err := client.DeletePods(metav1.NamespaceSystem, metav1.ListOptions{
@@ -753,7 +843,7 @@ func loadWindowsScript() (*ssh.RemoteFile, error) {
 }
 
 func remoteBashScript(step string) string {
-	return fmt.Sprintf("bash -euxo pipefail -c \"if [ -f /etc/kubernetes/rotate-certs/rotate-certs.sh ]; then sudo /etc/kubernetes/rotate-certs/rotate-certs.sh %s |& sudo tee -a /var/log/azure/rotate-certs.log; fi\"", step)
+	return fmt.Sprintf("bash -euxo pipefail -c \"if [ -f /etc/kubernetes/rotate-certs/rotate-certs.sh ]; then sudo mkdir -p /var/log/azure; sudo /etc/kubernetes/rotate-certs/rotate-certs.sh %s |& sudo tee -a /var/log/azure/rotate-certs.log; fi\"", step)
 }
 
 func remotePowershellScript(step string) string {
@@ -761,6 +851,15 @@ func remotePowershellScript(step string) string {
 	return fmt.Sprintf("powershell -noprofile -command \"cd c:\\k\\; Import-Module %s; iex %s | Out-File -Append -Encoding utf8 rotate-certs.log\"", filePath, step)
 }
 
+func runCommandPowershellScript(function string) []string {
+	filePath := "$env:temp\\rotate-certs.ps1"
+	return []string{
+		"cd c:\\k\\",
+		fmt.Sprintf("Import-Module %s", filePath),
+		fmt.Sprintf("%s | Out-File -Append -Encoding utf8 rotate-certs.log", function),
+	}
+}
+
 type nodeCondition func(*ssh.RemoteHost) bool
 
 func isMaster(node *ssh.RemoteHost) bool {
@@ -777,3 +876,9 @@ func keys(nodes nodeMap) []string {
 	}
 	return n
 }
+
+func sortedKeys(nodes nodeMap) []string {
+	n := keys(nodes)
+	sort.Strings(n)
+	return n
+}
@@ -6,7 +6,12 @@ package cmd
 import (
 	"testing"
 
+	"github.com/Azure/aks-engine/pkg/api"
 	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/helpers/runcommand"
+	"github.com/Azure/aks-engine/pkg/helpers/ssh"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/google/uuid"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
@@ -175,3 +180,58 @@ func TestRotateCertsCmdValidateArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestRotateCertsWindowsRunCommand(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	var scripts [][]string
+	client := &armhelpers.MockAKSEngineClient{
+		FakeListVirtualMachineScaleSetsResult: func() []compute.VirtualMachineScaleSet {
+			return []compute.VirtualMachineScaleSet{{Name: to.StringPtr("akswin")}}
+		},
+		FakeListVirtualMachineScaleSetVMsResult: func() []compute.VirtualMachineScaleSetVM {
+			return []compute.VirtualMachineScaleSetVM{{
+				InstanceID: to.StringPtr("0"),
+				VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
+					OsProfile: &compute.OSProfile{ComputerName: to.StringPtr("akswin000000")},
+				},
+			}}
+		},
+		FakeRunCommandResult: func(input compute.RunCommandInput) compute.RunCommandResult {
+			scripts = append(scripts, *input.Script)
+			return compute.RunCommandResult{
+				Value: &[]compute.InstanceViewStatus{
+					{Code: to.StringPtr("ComponentStatus/StdOut/succeeded"), Message: to.StringPtr(runcommand.CompletedMarker)},
+				},
+			}
+		},
+	}
+	rcc := &rotateCertsCmd{runCommand: runcommand.NewClient(client, "rg")}
+	node := &ssh.RemoteHost{URI: "akswin000000", OperatingSystem: api.Windows}
+
+	files := fileMap{
+		"script": ssh.NewRemoteFile("$env:temp\\rotate-certs.ps1", "", "", []byte("function Backup {}")),
+		"ca.crt": ssh.NewRemoteFile("$env:temp\\ca.crt", "", "", []byte("ca")),
+	}
+	g.Expect(rcc.uploadRunCommand(files, node)).To(Succeed())
+	g.Expect(rcc.execWindowsFunc("Backup")(node)).To(Succeed())
+	g.Expect(scripts).To(Equal([][]string{
+		{
+			"$ErrorActionPreference = 'Stop'",
+			"[IO.File]::WriteAllBytes(\"$env:temp\\ca.crt\", [Convert]::FromBase64String('Y2E='))",
+			"[IO.File]::WriteAllBytes(\"$env:temp\\rotate-certs.ps1\", [Convert]::FromBase64String('ZnVuY3Rpb24gQmFja3VwIHt9'))",
+			"Write-Output aks-engine-run-command-completed",
+		},
+		{
+			"$ErrorActionPreference = 'Stop'",
+			"cd c:\\k\\",
+			"Import-Module $env:temp\\rotate-certs.ps1",
+			"Backup | Out-File -Append -Encoding utf8 rotate-certs.log",
+			"Write-Output aks-engine-run-command-completed",
+		},
+	}))
+
+	client.FailRunCommand = true
+	g.Expect(rcc.execWindowsFunc("Clean")(node)).NotTo(Succeed())
+}
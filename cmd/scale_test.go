@@ -6,7 +6,6 @@ package cmd
 import (
 	"testing"
 
-	"github.com/Azure/aks-engine/pkg/api"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -102,6 +101,46 @@ func TestScaleCmdValidate(t *testing.T) {
 			expectedErr: errors.New("ambiguous, please specify only one of --api-model and --deployment-dir"),
 			name:        "Ambiguous",
 		},
+		{
+			sc: &scaleCmd{
+				apiModelPath:         "./not/used",
+				location:             "centralus",
+				resourceGroupName:    "testRG",
+				agentPoolToScale:     "agentpool1",
+				newDesiredAgentCount: 1,
+				masterFQDN:           "test",
+				nodesToRemove:        []string{"k8s-agentpool1-12345678-0"},
+				scaleDownStrategy:    "OldestFirst",
+			},
+			expectedErr: errors.New("ambiguous, please specify only one of --nodes-to-remove and --scale-down-strategy"),
+			name:        "AmbiguousScaleDown",
+		},
+		{
+			sc: &scaleCmd{
+				apiModelPath:         "./not/used",
+				location:             "centralus",
+				resourceGroupName:    "testRG",
+				agentPoolToScale:     "agentpool1",
+				newDesiredAgentCount: 1,
+				masterFQDN:           "test",
+				scaleDownStrategy:    "NewestFirst",
+			},
+			expectedErr: errors.New("--scale-down-strategy must be one of NotReadyFirst, OldestFirst, LeastPodsFirst"),
+			name:        "InvalidScaleDownStrategy",
+		},
+		{
+			sc: &scaleCmd{
+				apiModelPath:         "./not/used",
+				location:             "centralus",
+				resourceGroupName:    "testRG",
+				agentPoolToScale:     "agentpool1",
+				newDesiredAgentCount: 1,
+				masterFQDN:           "test",
+				scaleDownStrategy:    "LeastPodsFirst",
+			},
+			expectedErr: nil,
+			name:        "IsValidScaleDownStrategy",
+		},
 		{
 			sc: &scaleCmd{
 				apiModelPath:         "./not/used",
@@ -136,106 +175,3 @@ func TestScaleCmdValidate(t *testing.T) {
 		})
 	}
 }
-
-func TestVmInVMASAgentPool(t *testing.T) {
-	tags := map[string]*string{}
-
-	cases := []struct {
-		sc       *scaleCmd
-		expected bool
-		name     string
-		vmName   string
-	}{
-		{
-			sc: &scaleCmd{
-				nameSuffix:       "39573225",
-				agentPoolIndex:   0,
-				agentPoolToScale: "linuxpool",
-				agentPool: &api.AgentPoolProfile{
-					Name:                "linuxpool",
-					OSType:              "Linux",
-					AvailabilityProfile: "AvailabilitySet",
-				},
-				containerService: &api.ContainerService{
-					Properties: &api.Properties{
-						ClusterID: "39573225",
-					},
-				},
-			},
-			expected: false,
-			name:     "linux VM is not in linux pool to scale",
-			vmName:   "k8s-linuxpool2-39573225-0",
-		},
-		{
-			sc: &scaleCmd{
-				nameSuffix:       "39573225",
-				agentPoolIndex:   1,
-				agentPoolToScale: "linuxpool2",
-				agentPool: &api.AgentPoolProfile{
-					Name:                "linuxpool2",
-					OSType:              "Linux",
-					AvailabilityProfile: "AvailabilitySet",
-				},
-				containerService: &api.ContainerService{
-					Properties: &api.Properties{
-						ClusterID: "39573225",
-					},
-				},
-			},
-			expected: true,
-			name:     "linux VM is in linux pool to scale",
-			vmName:   "k8s-linuxpool2-39573225-1",
-		},
-		{
-			sc: &scaleCmd{
-				nameSuffix:       "39573225",
-				agentPoolIndex:   2,
-				agentPoolToScale: "windowspool",
-				agentPool: &api.AgentPoolProfile{
-					Name:                "windowspool",
-					OSType:              "Windows",
-					AvailabilityProfile: "AvailabilitySet",
-				},
-				containerService: &api.ContainerService{
-					Properties: &api.Properties{
-						ClusterID: "39573225",
-					},
-				},
-			},
-			expected: false,
-			name:     "windows VM is not in windows pool to scale",
-			vmName:   "3957k8s030",
-		},
-		{
-			sc: &scaleCmd{
-				nameSuffix:       "39573225",
-				agentPoolIndex:   3,
-				agentPoolToScale: "windowspool2",
-				agentPool: &api.AgentPoolProfile{
-					Name:                "windowspool2",
-					OSType:              "Windows",
-					AvailabilityProfile: "AvailabilitySet",
-				},
-				containerService: &api.ContainerService{
-					Properties: &api.Properties{
-						ClusterID: "39573225",
-					},
-				},
-			},
-			expected: true,
-			name:     "windows VM is in windows pool to scale",
-			vmName:   "3957k8s031",
-		},
-	}
-
-	for _, tc := range cases {
-		c := tc
-		t.Run(c.name, func(t *testing.T) {
-			t.Parallel()
-			ret := c.sc.vmInVMASAgentPool(c.vmName, tags)
-			if ret != c.expected {
-				t.Errorf("expected %t to be %t", ret, c.expected)
-			}
-		})
-	}
-}
@@ -10,7 +10,7 @@ import (
 
 	"github.com/Azure/aks-engine/pkg/api"
 	"github.com/Azure/aks-engine/pkg/armhelpers"
-	"github.com/Azure/aks-engine/pkg/engine"
+	"github.com/Azure/aks-engine/pkg/cluster"
 	"github.com/Azure/aks-engine/pkg/helpers"
 	"github.com/Azure/aks-engine/pkg/i18n"
 	"github.com/Azure/go-autorest/autorest/to"
@@ -18,11 +18,11 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	prefixed "github.com/x-cray/logrus-prefixed-formatter"
 )
 
 type updateCmd struct {
 	authArgs
+	progressArgs
 
 	// user input
 	apiModelPath      string
@@ -36,10 +36,8 @@ type updateCmd struct {
 	agentPool        *api.AgentPoolProfile
 	client           armhelpers.AKSEngineClient
 	locale           *gotext.Locale
-	nameSuffix       string
 	agentPoolIndex   int
 	logger           *log.Entry
-	kubeconfig       string
 }
 
 const (
@@ -65,6 +63,7 @@ func newUpdateCmd() *cobra.Command {
 	f.StringVarP(&uc.apiModelPath, "api-model", "m", "", "path to the generated apimodel.json file")
 	f.StringVar(&uc.agentPoolToUpdate, "node-pool", "", "node pool to scale")
 	addAuthFlags(&uc.authArgs, f)
+	addProgressFlags(&uc.progressArgs, f)
 
 	return updateCmd
 }
@@ -104,9 +103,7 @@ func (uc *updateCmd) validate(cmd *cobra.Command) error {
 }
 
 func (uc *updateCmd) load() error {
-	logger := log.New()
-	logger.Formatter = new(prefixed.TextFormatter)
-	uc.logger = log.NewEntry(log.New())
+	uc.logger = newLogger()
 	var err error
 
 	ctx, cancel := context.WithTimeout(context.Background(), armhelpers.DefaultARMOperationTimeout)
@@ -140,9 +137,14 @@ func (uc *updateCmd) load() error {
 		return err
 	}
 
+	if err = uc.validateProgressArgs(); err != nil {
+		return err
+	}
+
 	if uc.client, err = uc.authArgs.getClient(); err != nil {
 		return errors.Wrap(err, "failed to get client")
 	}
+	uc.client = uc.withDeploymentProgress(uc.client)
 
 	_, err = uc.client.EnsureResourceGroup(ctx, uc.resourceGroupName, uc.location, nil)
 	if err != nil {
@@ -174,15 +176,6 @@ func (uc *updateCmd) load() error {
 	if uc.agentPool.IsVirtualMachineScaleSets() && uc.agentPool.VMSSName == "" {
 		uc.agentPool.VMSSName = uc.containerService.Properties.GetAgentVMPrefix(uc.agentPool, uc.agentPoolIndex)
 	}
-
-	//allows to identify VMs in the resource group that belong to this cluster.
-	uc.nameSuffix = uc.containerService.Properties.GetClusterID()
-	log.Debugf("Cluster ID used in all agent pools: %s", uc.nameSuffix)
-
-	uc.kubeconfig, err = engine.GenerateKubeConfig(uc.containerService.Properties, uc.location)
-	if err != nil {
-		return errors.New("Unable to derive kubeconfig from api model")
-	}
 	return nil
 }
 
@@ -197,59 +190,43 @@ func (uc *updateCmd) run(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), armhelpers.DefaultARMOperationTimeout)
 	defer cancel()
 
-	sc := scaleCmd{
-		location:          uc.location,
-		apiModelPath:      uc.apiModelPath,
-		resourceGroupName: uc.resourceGroupName,
-		agentPoolToScale:  uc.agentPoolToUpdate,
-		validateCmd:       false,
-		updateVMSSModel:   true,
-		loadAPIModel:      false,
-		persistAPIModel:   false,
-	}
-	sc.RawAzureEnvironment = uc.RawAzureEnvironment
-	sc.rawSubscriptionID = uc.rawSubscriptionID
-	sc.SubscriptionID = uc.SubscriptionID
-	sc.AuthMethod = uc.AuthMethod
-	sc.rawClientID = uc.rawClientID
-	sc.ClientID = uc.ClientID
-	sc.ClientSecret = uc.ClientSecret
-	sc.CertificatePath = uc.CertificatePath
-	sc.PrivateKeyPath = uc.PrivateKeyPath
-	sc.IdentitySystem = uc.IdentitySystem
-	sc.language = uc.language
-	sc.logger = uc.logger
-	sc.containerService = uc.containerService
-	sc.apiVersion = uc.apiVersion
-	sc.client = uc.client
-	sc.nameSuffix = uc.nameSuffix
-	sc.kubeconfig = uc.kubeconfig
-	sc.agentPool = uc.agentPool
-	sc.agentPoolIndex = uc.agentPoolIndex
-
-	for vmssListPage, err := sc.client.ListVirtualMachineScaleSets(ctx, sc.resourceGroupName); vmssListPage.NotDone(); err = vmssListPage.NextWithContext(ctx) {
+	var count int
+	for vmssListPage, err := uc.client.ListVirtualMachineScaleSets(ctx, uc.resourceGroupName); vmssListPage.NotDone(); err = vmssListPage.NextWithContext(ctx) {
 		if err != nil {
 			return errors.Wrap(err, "failed to get VMSS list in the resource group")
 		}
 		for _, vmss := range vmssListPage.Values() {
 			vmssName := to.String(vmss.Name)
-			if sc.agentPool.VMSSName == vmssName {
-				log.Infof("found VMSS %s in resource group %s that correlates with node pool %s", vmssName, sc.resourceGroupName, sc.agentPoolToScale)
+			if uc.agentPool.VMSSName == vmssName {
+				log.Infof("found VMSS %s in resource group %s that correlates with node pool %s", vmssName, uc.resourceGroupName, uc.agentPoolToUpdate)
 			} else {
 				continue
 			}
 
 			if vmss.Sku != nil {
-				sc.newDesiredAgentCount = int(*vmss.Sku.Capacity)
-				uc.agentPool.Count = sc.newDesiredAgentCount
+				count = int(*vmss.Sku.Capacity)
+				uc.agentPool.Count = count
 				break
 			} else {
-				return errors.Wrap(err, fmt.Sprintf("failed to find VMSS matching node pool %s in resource group %s", sc.agentPoolToScale, sc.resourceGroupName))
+				return errors.Wrap(err, fmt.Sprintf("failed to find VMSS matching node pool %s in resource group %s", uc.agentPoolToUpdate, uc.resourceGroupName))
 			}
 		}
 	}
 
-	err := sc.run(cmd, args)
+	_, err := cluster.Scale(context.Background(), &cluster.ScaleRequest{
+		Options: cluster.Options{
+			Client:     uc.client,
+			Logger:     uc.logger,
+			Translator: &i18n.Translator{Locale: uc.locale},
+			BuildTag:   BuildTag,
+		},
+		ContainerService: uc.containerService,
+		SubscriptionID:   uc.SubscriptionID.String(),
+		ResourceGroup:    uc.resourceGroupName,
+		AgentPoolName:    uc.agentPoolToUpdate,
+		Count:            count,
+		UpdateVMSSModel:  true,
+	})
 	if err != nil {
 		return errors.Wrap(err, "aks-engine update failed")
 	}
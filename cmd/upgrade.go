@@ -5,24 +5,16 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"path/filepath"
-
-	"regexp"
-	"strings"
 	"time"
 
 	"github.com/Azure/aks-engine/pkg/api"
-	"github.com/Azure/aks-engine/pkg/api/common"
 	"github.com/Azure/aks-engine/pkg/armhelpers"
-	"github.com/Azure/aks-engine/pkg/armhelpers/utils"
-	"github.com/Azure/aks-engine/pkg/engine"
+	"github.com/Azure/aks-engine/pkg/cluster"
 	"github.com/Azure/aks-engine/pkg/helpers"
 	"github.com/Azure/aks-engine/pkg/i18n"
-	"github.com/Azure/aks-engine/pkg/operations/kubernetesupgrade"
-	"github.com/Azure/go-autorest/autorest/to"
-	"github.com/blang/semver"
+	"github.com/Azure/aks-engine/pkg/operations"
 	"github.com/leonelquinteros/gotext"
 	"github.com/pkg/errors"
 
@@ -31,40 +23,39 @@ import (
 )
 
 const (
-	upgradeName                     = "upgrade"
-	upgradeShortDescription         = "Upgrade an existing AKS Engine-created Kubernetes cluster"
-	upgradeLongDescription          = "Upgrade an existing AKS Engine-created Kubernetes cluster, one node at a time"
-	smalldiskWindowsImageIdentifier = "smalldisk"
-	ctrdWindowsImageIdentifier      = "ctrd"
+	upgradeName             = "upgrade"
+	upgradeShortDescription = "Upgrade an existing AKS Engine-created Kubernetes cluster"
+	upgradeLongDescription  = "Upgrade an existing AKS Engine-created Kubernetes cluster, one node at a time"
 )
 
 type upgradeCmd struct {
 	authProvider
+	progressArgs
+	whatIfArgs
+	outputArgs
 
 	// user input
-	resourceGroupName                        string
-	apiModelPath                             string
-	deploymentDirectory                      string
-	currentVersion                           string
-	upgradeVersion                           string
-	location                                 string
-	kubeconfigPath                           string
-	timeoutInMinutes                         int
-	cordonDrainTimeoutInMinutes              int
-	force                                    bool
-	controlPlaneOnly                         bool
-	disableClusterInitComponentDuringUpgrade bool
-	upgradeWindowsVHD                        bool
+	resourceGroupName           string
+	apiModelPath                string
+	deploymentDirectory         string
+	upgradeVersion              string
+	location                    string
+	kubeconfigPath              string
+	timeoutInMinutes            int
+	cordonDrainTimeoutInMinutes int
+	force                       bool
+	controlPlaneOnly            bool
+	upgradeWindowsVHD           bool
+	resetImagePins              bool
 
 	// derived
-	containerService    *api.ContainerService
-	apiVersion          string
-	client              armhelpers.AKSEngineClient
-	locale              *gotext.Locale
-	nameSuffix          string
-	agentPoolsToUpgrade map[string]bool
-	timeout             *time.Duration
-	cordonDrainTimeout  *time.Duration
+	containerService   *api.ContainerService
+	apiVersion         string
+	client             armhelpers.AKSEngineClient
+	locale             *gotext.Locale
+	kubeConfig         string
+	timeout            *time.Duration
+	cordonDrainTimeout *time.Duration
 }
 
 func newUpgradeCmd() *cobra.Command {
@@ -76,7 +67,11 @@ func newUpgradeCmd() *cobra.Command {
 		Use:   upgradeName,
 		Short: upgradeShortDescription,
 		Long:  upgradeLongDescription,
-		RunE:  uc.run,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return uc.runWithResult(cmd, func() error {
+				return uc.run(cmd, args)
+			})
+		},
 	}
 
 	f := upgradeCmd.Flags()
@@ -91,7 +86,11 @@ func newUpgradeCmd() *cobra.Command {
 	f.BoolVarP(&uc.force, "force", "f", false, "force upgrading the cluster to desired version. Allows same version upgrades and downgrades.")
 	f.BoolVarP(&uc.controlPlaneOnly, "control-plane-only", "", false, "upgrade control plane VMs only, do not upgrade node pools")
 	f.BoolVarP(&uc.upgradeWindowsVHD, "upgrade-windows-vhd", "", true, "upgrade image reference of the Windows nodes")
+	f.BoolVar(&uc.resetImagePins, "reset-image-pins", false, "reset the addon and component images pinned in the api model to their defaults")
 	addAuthFlags(uc.getAuthArgs(), f)
+	addProgressFlags(&uc.progressArgs, f)
+	addWhatIfFlag(&uc.whatIfArgs, f)
+	addOutputFlag(&uc.outputArgs, f, "o")
 
 	_ = f.MarkDeprecated("deployment-dir", "deployment-dir is no longer required for scale or upgrade. Please use --api-model.")
 
@@ -169,84 +168,10 @@ func (uc *upgradeCmd) loadCluster() error {
 	// Load the container service.
 	uc.containerService, uc.apiVersion, err = apiloader.LoadContainerServiceFromFile(uc.apiModelPath, true, true, nil)
 	if err != nil {
+		logValidationErrors(err, apiloader.Translator)
 		return errors.Wrap(err, "error parsing the api model")
 	}
 
-	// Ensure there aren't known-breaking API model configurations
-	if uc.containerService.Properties.MasterProfile.AvailabilityProfile == api.VirtualMachineScaleSets {
-		return errors.Errorf("clusters with a VMSS control plane are not upgradable using `aks-engine upgrade`")
-	}
-	if uc.containerService.Properties.OrchestratorProfile != nil &&
-		uc.containerService.Properties.OrchestratorProfile.KubernetesConfig != nil &&
-		to.Bool(uc.containerService.Properties.OrchestratorProfile.KubernetesConfig.EnableEncryptionWithExternalKms) &&
-		to.Bool(uc.containerService.Properties.OrchestratorProfile.KubernetesConfig.UseManagedIdentity) &&
-		uc.containerService.Properties.OrchestratorProfile.KubernetesConfig.UserAssignedID == "" {
-		return errors.Errorf("clusters with enableEncryptionWithExternalKms=true and system-assigned identity are not upgradable using `aks-engine upgrade`")
-	}
-
-	// Set 60 minutes cordonDrainTimeout for Azure Stack Cloud to give it enough time to move around resources during Node Drain,
-	// especially disk detach/attach operations. We still honor the user's input.
-	if uc.cordonDrainTimeout == nil && uc.containerService.Properties.IsAzureStackCloud() {
-		cordonDrainTimeout := time.Duration(60) * time.Minute
-		uc.cordonDrainTimeout = &cordonDrainTimeout
-	}
-
-	// Use the Windows VHD associated with the aks-engine version if upgradeWindowsVHD is set to "true"
-	if uc.upgradeWindowsVHD && uc.containerService.Properties.WindowsProfile != nil {
-		windowsProfile := uc.containerService.Properties.WindowsProfile
-		if api.ImagePublisherAndOfferMatch(windowsProfile, api.AKSWindowsServer2019ContainerDOSImageConfig) && strings.Contains(windowsProfile.WindowsSku, ctrdWindowsImageIdentifier) {
-			windowsProfile.ImageVersion = api.AKSWindowsServer2019ContainerDOSImageConfig.ImageVersion
-			windowsProfile.WindowsSku = api.AKSWindowsServer2019ContainerDOSImageConfig.ImageSku
-		} else if api.ImagePublisherAndOfferMatch(windowsProfile, api.AKSWindowsServer2019OSImageConfig) && strings.Contains(windowsProfile.WindowsSku, smalldiskWindowsImageIdentifier) {
-			windowsProfile.ImageVersion = api.AKSWindowsServer2019OSImageConfig.ImageVersion
-			windowsProfile.WindowsSku = api.AKSWindowsServer2019OSImageConfig.ImageSku
-		} else if api.ImagePublisherAndOfferMatch(windowsProfile, api.WindowsServer2019OSImageConfig) {
-			windowsProfile.ImageVersion = api.WindowsServer2019OSImageConfig.ImageVersion
-			windowsProfile.WindowsSku = api.WindowsServer2019OSImageConfig.ImageSku
-		}
-	}
-
-	// Update the masterProfile and agentPoolProfiles distro for AzureStackCloud to use aks-ubuntu-18.04 instead of aks-ubuntu-16.04
-	if uc.containerService.Properties.IsAzureStackCloud() {
-		if uc.containerService.Properties.MasterProfile.Distro == api.AKSUbuntu1604 {
-			log.Infoln("Distro 'aks-ubuntu-16.04' is not longer supported on Azure Stack Hub, overwriting master profile distro to 'aks-ubuntu-20.04'")
-			uc.containerService.Properties.MasterProfile.Distro = api.AKSUbuntu2004
-		} else if uc.containerService.Properties.MasterProfile.Distro == api.AKSUbuntu1804 {
-			log.Infoln("Distro 'aks-ubuntu-18.04' is not longer supported on Azure Stack Hub, overwriting master profile distro to 'aks-ubuntu-20.04'")
-			uc.containerService.Properties.MasterProfile.Distro = api.AKSUbuntu2004
-		}
-
-		for _, app := range uc.containerService.Properties.AgentPoolProfiles {
-			if app.Distro == api.AKSUbuntu1604 {
-				log.Infoln(fmt.Sprintf("Distro 'aks-ubuntu-16.04' is not longer supported on Azure Stack Hub, overwriting agent pool profile %s distro to 'aks-ubuntu-20.04'", app.Name))
-				app.Distro = api.AKSUbuntu2004
-			} else if app.Distro == api.AKSUbuntu1804 {
-				log.Infoln(fmt.Sprintf("Distro 'aks-ubuntu-18.04' is not longer supported on Azure Stack Hub, overwriting agent pool profile %s distro to 'aks-ubuntu-20.04'", app.Name))
-				app.Distro = api.AKSUbuntu2004
-			}
-		}
-	}
-
-	// Enforce UseCloudControllerManager for Kubernetes 1.21+ on Azure Stack cloud
-	if uc.containerService.Properties.IsAzureStackCloud() && common.IsKubernetesVersionGe(uc.upgradeVersion, "1.21.0") {
-		log.Infoln("The in-tree cloud provider is not longer supported on Azure Stack Hub for v1.21+ clusters, overwriting UseCloudControllerManager to 'true'")
-		uc.containerService.Properties.OrchestratorProfile.KubernetesConfig.UseCloudControllerManager = to.BoolPtr(true)
-	}
-
-	// Only containerd runtime is allowed for Kubernetes 1.24+ on Azure Stack cloud
-	if uc.containerService.Properties.IsAzureStackCloud() && strings.EqualFold(uc.containerService.Properties.OrchestratorProfile.KubernetesConfig.ContainerRuntime, "docker") && common.IsKubernetesVersionGe(uc.upgradeVersion, "1.24.0") {
-		log.Infoln("The docker runtime is no longer supported for v1.24+ clusters, overwriting ContainerRuntime to 'containerd'")
-		uc.containerService.Properties.OrchestratorProfile.KubernetesConfig.ContainerRuntime = "containerd"
-	}
-
-	// The cluster-init component is a cluster create-only feature, temporarily disable if enabled
-	if i := api.GetComponentsIndexByName(uc.containerService.Properties.OrchestratorProfile.KubernetesConfig.Components, common.ClusterInitComponentName); i > -1 {
-		if uc.containerService.Properties.OrchestratorProfile.KubernetesConfig.Components[i].IsEnabled() {
-			uc.disableClusterInitComponentDuringUpgrade = true
-			uc.containerService.Properties.OrchestratorProfile.KubernetesConfig.Components[i].Enabled = to.BoolPtr(false)
-		}
-	}
-
 	if uc.containerService.Properties.IsCustomCloudProfile() {
 		if err = writeCustomCloudProfile(uc.containerService); err != nil {
 			return errors.Wrap(err, "error writing custom cloud profile")
@@ -263,13 +188,22 @@ func (uc *upgradeCmd) loadCluster() error {
 		return err
 	}
 
+	if err = uc.validateProgressArgs(); err != nil {
+		return err
+	}
+
 	if uc.client, err = uc.getAuthArgs().getClient(); err != nil {
 		return errors.Wrap(err, "failed to get client")
 	}
+	uc.client = uc.withDeploymentProgress(uc.client)
+	uc.client = uc.withResultRecording(uc.client, uc.getAuthArgs().SubscriptionID.String())
+	uc.result.ResourceGroup = uc.resourceGroupName
 
-	_, err = uc.client.EnsureResourceGroup(ctx, uc.resourceGroupName, uc.location, nil)
-	if err != nil {
-		return errors.Wrap(err, "error ensuring resource group")
+	if !uc.whatIf {
+		_, err = uc.client.EnsureResourceGroup(ctx, uc.resourceGroupName, uc.location, nil)
+		if err != nil {
+			return errors.Wrap(err, "error ensuring resource group")
+		}
 	}
 
 	err = uc.initialize()
@@ -279,26 +213,6 @@ func (uc *upgradeCmd) loadCluster() error {
 	return nil
 }
 
-func (uc *upgradeCmd) validateTargetVersion() error {
-	// Get available upgrades for container service.
-	orchestratorInfo, err := api.GetOrchestratorVersionProfile(uc.containerService.Properties.OrchestratorProfile, uc.containerService.Properties.HasWindows(), uc.containerService.Properties.IsAzureStackCloud())
-	if err != nil {
-		return errors.Wrap(err, "error getting list of available upgrades")
-	}
-
-	found := false
-	for _, up := range orchestratorInfo.Upgrades {
-		if up.OrchestratorVersion == uc.upgradeVersion {
-			found = true
-			break
-		}
-	}
-	if !found {
-		return errors.Errorf("upgrading from Kubernetes version %s to version %s is not supported. To see a list of available upgrades, use 'aks-engine get-versions --version %s'", uc.containerService.Properties.OrchestratorProfile.OrchestratorVersion, uc.upgradeVersion, uc.containerService.Properties.OrchestratorProfile.OrchestratorVersion)
-	}
-	return nil
-}
-
 func (uc *upgradeCmd) initialize() error {
 	if uc.containerService.Location == "" {
 		uc.containerService.Location = uc.location
@@ -306,30 +220,16 @@ func (uc *upgradeCmd) initialize() error {
 		return errors.New("--location does not match api model location")
 	}
 
-	// Validate semver compatibility
-	_, err := semver.Make(uc.upgradeVersion)
-	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("Invalid --upgrade-version value '%s', not a semver string", uc.upgradeVersion))
-	}
-
-	if !uc.force {
-		err := uc.validateTargetVersion()
+	if uc.kubeconfigPath != "" {
+		path, err := filepath.Abs(uc.kubeconfigPath)
 		if err != nil {
-			return errors.Wrap(err, "Invalid upgrade target version. Consider using --force if you really want to proceed")
+			return errors.Wrap(err, "reading --kubeconfig")
 		}
-	}
-	uc.currentVersion = uc.containerService.Properties.OrchestratorProfile.OrchestratorVersion
-	uc.containerService.Properties.OrchestratorProfile.OrchestratorVersion = uc.upgradeVersion
-
-	//allows to identify VMs in the resource group that belong to this cluster.
-	uc.nameSuffix = uc.containerService.Properties.GetClusterID()
-
-	log.Infoln(fmt.Sprintf("Upgrading cluster with name suffix: %s", uc.nameSuffix))
-
-	uc.agentPoolsToUpgrade = make(map[string]bool)
-	uc.agentPoolsToUpgrade[kubernetesupgrade.MasterPoolName] = true
-	for _, agentPool := range uc.containerService.Properties.AgentPoolProfiles {
-		uc.agentPoolsToUpgrade[agentPool.Name] = true
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrap(err, "reading --kubeconfig")
+		}
+		uc.kubeConfig = string(content)
 	}
 	return nil
 }
@@ -345,65 +245,45 @@ func (uc *upgradeCmd) run(cmd *cobra.Command, args []string) error {
 		return errors.Wrap(err, "loading existing cluster")
 	}
 
-	if uc.containerService.Properties.IsAzureStackCloud() {
-		if err = uc.validateOSBaseImage(); err != nil {
-			return errors.Wrapf(err, "validating OS base images required by %s", uc.apiModelPath)
-		}
-	}
-
-	upgradeCluster := kubernetesupgrade.UpgradeCluster{
-		Translator: &i18n.Translator{
-			Locale: uc.locale,
+	translator := &i18n.Translator{Locale: uc.locale}
+	ctx, stop := interruptContext()
+	defer stop()
+	resp, err := cluster.Upgrade(ctx, &cluster.UpgradeRequest{
+		Options: cluster.Options{
+			Client:     uc.client,
+			Logger:     newLogger(),
+			Translator: translator,
+			Output:     uc.textOutput(),
+			BuildTag:   BuildTag,
+			WhatIf:     uc.whatIf,
 		},
-		Logger:             log.NewEntry(log.New()),
-		Client:             uc.client,
+		ContainerService:   uc.containerService,
+		SubscriptionID:     uc.getAuthArgs().SubscriptionID.String(),
+		ResourceGroup:      uc.resourceGroupName,
+		UpgradeVersion:     uc.upgradeVersion,
+		KubeConfig:         uc.kubeConfig,
+		Force:              uc.force,
+		ControlPlaneOnly:   uc.controlPlaneOnly,
+		UpgradeWindowsVHD:  uc.upgradeWindowsVHD,
+		ResetImagePins:     uc.resetImagePins,
 		StepTimeout:        uc.timeout,
 		CordonDrainTimeout: uc.cordonDrainTimeout,
+	})
+	if operations.IsInterrupted(err) {
+		log.Warnf("The upgrade was %s, the nodes upgraded so far are complete and the api model is unchanged. "+
+			"Run the same upgrade command again to resume it, the nodes already upgraded are skipped", err)
+		return err
 	}
-
-	upgradeCluster.ClusterTopology = kubernetesupgrade.ClusterTopology{}
-	upgradeCluster.SubscriptionID = uc.getAuthArgs().SubscriptionID.String()
-	upgradeCluster.ResourceGroup = uc.resourceGroupName
-	upgradeCluster.DataModel = uc.containerService
-	upgradeCluster.NameSuffix = uc.nameSuffix
-	upgradeCluster.AgentPoolsToUpgrade = uc.agentPoolsToUpgrade
-	upgradeCluster.Force = uc.force
-	upgradeCluster.ControlPlaneOnly = uc.controlPlaneOnly
-
-	var kubeConfig string
-	if uc.kubeconfigPath != "" {
-		var path string
-		var content []byte
-		path, err = filepath.Abs(uc.kubeconfigPath)
-		if err != nil {
-			return errors.Wrap(err, "reading --kubeconfig")
-		}
-		content, err = os.ReadFile(path)
-		if err != nil {
-			return errors.Wrap(err, "reading --kubeconfig")
-		}
-		kubeConfig = string(content)
-	} else {
-		kubeConfig, err = engine.GenerateKubeConfig(uc.containerService.Properties, uc.location)
-		if err != nil {
-			return errors.Wrap(err, "generating kubeconfig")
-		}
-	}
-
-	upgradeCluster.IsVMSSToBeUpgraded = isVMSSNameInAgentPoolsArray
-	upgradeCluster.CurrentVersion = uc.currentVersion
-
-	if err = upgradeCluster.UpgradeCluster(uc.client, kubeConfig, BuildTag); err != nil {
+	if err != nil {
+		operations.PrintCSEFailures(os.Stderr, operations.GetCSEFailures(err, translator))
 		return errors.Wrap(err, "upgrading cluster")
 	}
+	if uc.whatIf {
+		return nil
+	}
+	uc.result.addNodesUpdated(resp.NodesUpgraded...)
 
 	// Save the new apimodel to reflect the cluster's state.
-	// Restore the original cluster-init component enabled value, if it was disabled during upgrade
-	if uc.disableClusterInitComponentDuringUpgrade {
-		if i := api.GetComponentsIndexByName(uc.containerService.Properties.OrchestratorProfile.KubernetesConfig.Components, common.ClusterInitComponentName); i > -1 {
-			uc.containerService.Properties.OrchestratorProfile.KubernetesConfig.Components[i].Enabled = to.BoolPtr(true)
-		}
-	}
 	apiloader := &api.Apiloader{
 		Translator: &i18n.Translator{
 			Locale: uc.locale,
@@ -422,33 +302,3 @@ func (uc *upgradeCmd) run(cmd *cobra.Command, args []string) error {
 	dir, file := filepath.Split(uc.apiModelPath)
 	return f.SaveFile(dir, file, b)
 }
-
-// isVMSSNameInAgentPoolsArray is a helper func to filter out any VMSS in the cluster resource group
-// that are not participating in the aks-engine-created Kubernetes cluster
-func isVMSSNameInAgentPoolsArray(vmss string, cs *api.ContainerService) bool {
-	for _, pool := range cs.Properties.AgentPoolProfiles {
-		if pool.AvailabilityProfile == api.VirtualMachineScaleSets {
-			if pool.OSType == api.Windows {
-				re := regexp.MustCompile(`^[0-9]{4}k8s[0]+`)
-				if re.FindString(vmss) != "" {
-					return true
-				}
-			} else {
-				if poolName, _, _ := utils.VmssNameParts(vmss); poolName == pool.Name {
-					return true
-				}
-			}
-		}
-	}
-	return false
-}
-
-// validateOSBaseImage checks if the OS image is available on the target cloud (ATM, Azure Stack only)
-func (uc *upgradeCmd) validateOSBaseImage() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	if err := armhelpers.ValidateRequiredImages(ctx, uc.location, uc.containerService.Properties, uc.client); err != nil {
-		return errors.Wrap(err, "OS base image not available in target cloud")
-	}
-	return nil
-}
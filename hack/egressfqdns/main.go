@@ -0,0 +1,46 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+// egressfqdns writes the table of the FQDNs that cluster nodes must reach into the markdown file given as
+// argument, between the egress-fqdns begin and end markers, so that the documentation follows the code.
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/Azure/aks-engine/pkg/api"
+)
+
+const (
+	beginMarker = "<!-- egress-fqdns:begin -->\n"
+	endMarker   = "<!-- egress-fqdns:end -->\n"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: egressfqdns <markdown file>")
+		os.Exit(2)
+	}
+	if err := run(os.Args[1]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	doc := string(b)
+	begin := strings.Index(doc, beginMarker)
+	end := strings.Index(doc, endMarker)
+	if begin < 0 || end < begin {
+		return fmt.Errorf("%s has no %q and %q markers", path, strings.TrimSpace(beginMarker), strings.TrimSpace(endMarker))
+	}
+	doc = doc[:begin+len(beginMarker)] + api.GetRequiredEgressFQDNsMarkdownTable() + doc[end:]
+	return ioutil.WriteFile(path, []byte(doc), 0644)
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/Azure/aks-engine/cmd"
+	"github.com/Azure/aks-engine/pkg/operations"
 	colorable "github.com/mattn/go-colorable"
 	log "github.com/sirupsen/logrus"
 )
@@ -26,6 +27,9 @@ or Cluster API Provider Azure (CAPZ) for self-managed Kubernetes:
 	log.Warningf("\u001b[33m%s\u001b[0m", msg)
 	log.SetOutput(colorable.NewColorableStdout())
 	if err := cmd.NewRootCmd().Execute(); err != nil {
+		if operations.IsInterrupted(err) {
+			os.Exit(cmd.ExitCodeInterrupted)
+		}
 		os.Exit(1)
 	}
 }
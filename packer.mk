@@ -4,6 +4,9 @@ build-packer:
 build-packer-20-04:
 	@packer build -var-file=vhd/packer/settings.json vhd/packer/vhd-image-builder-20.04.json
 
+build-packer-22-04:
+	@packer build -var-file=vhd/packer/settings.json vhd/packer/vhd-image-builder-22.04.json
+
 build-packer-ubuntu-gen2:
 	@packer build -var-file=vhd/packer/settings.json vhd/packer/vhd-image-builder-ubuntu-gen2.json
 
@@ -22,6 +25,9 @@ run-packer: az-login
 run-packer-20-04: az-login
 	@packer version && set -o pipefail && ($(MAKE) init-packer | tee packer-output-20-04) && ($(MAKE) build-packer-20-04 | tee -a packer-output-20-04)
 
+run-packer-22-04: az-login
+	@packer version && set -o pipefail && ($(MAKE) init-packer | tee packer-output-22-04) && ($(MAKE) build-packer-22-04 | tee -a packer-output-22-04)
+
 run-packer-ubuntu-gen2: az-login
 	@packer version && set -o pipefail && ($(MAKE) init-packer | tee packer-output) && ($(MAKE) build-packer-ubuntu-gen2 | tee -a packer-output)
 
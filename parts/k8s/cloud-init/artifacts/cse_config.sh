@@ -194,6 +194,7 @@ configureK8s() {
     "vnetName": "${VIRTUAL_NETWORK}",
     "vnetResourceGroup": "${VIRTUAL_NETWORK_RESOURCE_GROUP}",
     "routeTableName": "${ROUTE_TABLE}",
+    "routeTableResourceGroup": "${ROUTE_TABLE_RESOURCE_GROUP:-}",
     "primaryAvailabilitySetName": "${PRIMARY_AVAILABILITY_SET}",
     "primaryScaleSetName": "${PRIMARY_SCALE_SET}",
     "cloudProviderBackoffMode": "${CLOUDPROVIDER_BACKOFF_MODE}",
@@ -315,7 +316,7 @@ installContainerd() {
     os_lower=$(echo ${OS} | tr '[:upper:]' '[:lower:]')
     if [[ ${OS} == "${UBUNTU_OS_NAME}" ]]; then
       url_path="${os_lower}/${UBUNTU_RELEASE}"
-      [[ ${UBUNTU_RELEASE} == "20.04" ]] || url_path+="/multiarch"
+      [[ ${UBUNTU_RELEASE} == "22.04" || ${UBUNTU_RELEASE} == "20.04" ]] || url_path+="/multiarch"
       url_path+="/prod"
     elif [[ ${OS} == "${DEBIAN_OS_NAME}" ]]; then
       url_path="${os_lower}/${UBUNTU_RELEASE}/prod"
@@ -10,8 +10,9 @@ fi
 if [[ ${OS} == "${UBUNTU_OS_NAME}" ]]; then
   UBUNTU_RELEASE=$(lsb_release -r -s)
 fi
+CPU_ARCH=$(uname -m | sed -e 's/x86_64/amd64/' -e 's/aarch64/arm64/')
 DOCKER=/usr/bin/docker
-if [[ $UBUNTU_RELEASE == "20.04" || $UBUNTU_RELEASE == "18.04" ]]; then
+if [[ $UBUNTU_RELEASE == "22.04" || $UBUNTU_RELEASE == "20.04" || $UBUNTU_RELEASE == "18.04" ]]; then
   export GPU_DV=515.65.01
 else
   export GPU_DV=418.40.04
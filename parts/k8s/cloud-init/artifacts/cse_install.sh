@@ -48,8 +48,10 @@ installDeps() {
     retrycmd_no_stats 120 5 25 curl ${MS_APT_REPO}/keys/microsoft.asc | gpg --dearmor >/tmp/microsoft.gpg || exit 26
     retrycmd 10 5 10 cp /tmp/microsoft.gpg /etc/apt/trusted.gpg.d/ || exit 26
     aptmarkWALinuxAgent hold
-    packages+=" cgroup-lite ceph-common glusterfs-client"
-    if [[ $UBUNTU_RELEASE == "20.04" || $UBUNTU_RELEASE == "18.04" ]]; then
+    packages+=" ceph-common glusterfs-client"
+    {{/* cgroup-lite mounts the cgroup v1 hierarchies, 22.04 boots with the unified cgroup v2 hierarchy */}}
+    [[ $UBUNTU_RELEASE == "22.04" ]] || packages+=" cgroup-lite"
+    if [[ $UBUNTU_RELEASE == "22.04" || $UBUNTU_RELEASE == "20.04" || $UBUNTU_RELEASE == "18.04" ]]; then
       disableTimeSyncd
       packages+=" ntp ntpstat chrony net-tools"
     fi
@@ -113,8 +115,8 @@ installRunc() {
   v=$(runc --version | head -n 1 | cut -d" " -f3)
   if [[ $v != "1.1.2" ]]; then
     url=${MS_APT_REPO}/ubuntu/${UBUNTU_RELEASE}
-    [[ ${UBUNTU_RELEASE} == "20.04" ]] || url=${url}/multiarch
-    url=${url}/prod/pool/main/m/moby-runc/moby-runc_1.1.2%2Bazure-ubuntu${UBUNTU_RELEASE}u1_amd64.deb
+    [[ ${UBUNTU_RELEASE} == "22.04" || ${UBUNTU_RELEASE} == "20.04" ]] || url=${url}/multiarch
+    url=${url}/prod/pool/main/m/moby-runc/moby-runc_1.1.2%2Bazure-ubuntu${UBUNTU_RELEASE}u1_${CPU_ARCH}.deb
     if [[ -n "${url:-}" ]]; then
       DEB="${url##*/}"
       retrycmd_no_stats 120 5 25 curl -fsSL ${url} >/tmp/${DEB} || exit 184
@@ -204,6 +206,8 @@ installBpftrace() {
   chmod -R +x "$tools_fp/tools"
 }
 installImg() {
+  {{/* img is only published for amd64 */}}
+  [[ ${CPU_ARCH} == "amd64" ]] || return 0
   img_filepath=/usr/local/bin/img
   retrycmd_get_executable 120 5 $img_filepath "https://upstreamartifacts.azureedge.net/img/img-linux-amd64-v0.5.6" ls || exit 33
 }
@@ -233,7 +237,7 @@ extractHyperkube() {
   fi
 }
 extractKubeBinaries() {
-  KUBE_BINARY_URL=${KUBE_BINARY_URL:-"https://kubernetesartifacts.azureedge.net/kubernetes/v${KUBERNETES_VERSION}/binaries/kubernetes-node-linux-amd64.tar.gz"}
+  KUBE_BINARY_URL=${KUBE_BINARY_URL:-"https://kubernetesartifacts.azureedge.net/kubernetes/v${KUBERNETES_VERSION}/binaries/kubernetes-node-linux-${CPU_ARCH}.tar.gz"}
   local dest="/opt/kubernetes/downloads" tmpDir=${KUBE_BINARY_URL##*/}
   mkdir -p "${dest}"
   retrycmd_get_tarball 120 5 "$dest/${tmpDir}" ${KUBE_BINARY_URL} || exit 31
@@ -43,7 +43,7 @@ wait_for_file 3600 1 {{GetCustomCloudConfigCSEScriptFilepath}} || exit {{GetCSEE
 source {{GetCustomCloudConfigCSEScriptFilepath }}
 {{end}}
 
-if [[ ${UBUNTU_RELEASE} == "20.04" || ${UBUNTU_RELEASE} == "18.04" ]]; then
+if [[ ${UBUNTU_RELEASE} == "22.04" || ${UBUNTU_RELEASE} == "20.04" || ${UBUNTU_RELEASE} == "18.04" ]]; then
   disable1804SystemdResolved
 fi
 
@@ -81,7 +81,7 @@ fi
 {{- if not IsVHDDistroForAllNodes}}
 if [[ $OS == $UBUNTU_OS_NAME || $OS == $DEBIAN_OS_NAME ]] && [ "$FULL_INSTALL_REQUIRED" = "true" ]; then
   time_metric "InstallDeps" installDeps
-  if [[ ${UBUNTU_RELEASE} == "20.04" || ${UBUNTU_RELEASE} == "18.04" ]]; then
+  if [[ ${UBUNTU_RELEASE} == "22.04" || ${UBUNTU_RELEASE} == "20.04" || ${UBUNTU_RELEASE} == "18.04" ]]; then
     overrideNetworkConfig
   fi
   {{- if not IsDockerContainerRuntime}}
@@ -90,7 +90,7 @@ if [[ $OS == $UBUNTU_OS_NAME || $OS == $DEBIAN_OS_NAME ]] && [ "$FULL_INSTALL_RE
 fi
 {{end}}
 
-if [[ ${UBUNTU_RELEASE} == "20.04" || ${UBUNTU_RELEASE} == "18.04" ]]; then
+if [[ ${UBUNTU_RELEASE} == "22.04" || ${UBUNTU_RELEASE} == "20.04" || ${UBUNTU_RELEASE} == "18.04" ]]; then
   if apt list --installed | grep 'chrony'; then
     time_metric "ConfigureChrony" configureChrony
     time_metric "EnsureChrony" ensureChrony
@@ -294,7 +294,9 @@ fi
 
 {{- if not HasBlockOutboundInternet}}
     {{- if RunUnattendedUpgradesOnBootstrap}}
-apt_get_update && unattended_upgrade
+if [[ $OS == $UBUNTU_OS_NAME ]]; then
+  apt_get_update && unattended_upgrade
+fi
     {{- end}}
 {{- end}}
 
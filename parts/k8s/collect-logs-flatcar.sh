@@ -0,0 +1,78 @@
+#!/bin/bash
+
+# Log collection script for Flatcar nodes, which do not ship the aks-engine VHD script nor zip
+
+set -o pipefail
+
+collectCloudProviderJson() {
+    local DIR=${OUTDIR}/etc/kubernetes
+    mkdir -p ${DIR}
+    if [ -f /etc/kubernetes/azure.json ]; then
+        grep -v aadClient /etc/kubernetes/azure.json > ${DIR}/azure.json
+    fi
+    if [ -f /etc/kubernetes/network_interfaces.json ]; then
+        cp /etc/kubernetes/network_interfaces.json ${DIR}
+    fi
+    if [ -f /etc/kubernetes/interfaces.json ]; then
+        cp /etc/kubernetes/interfaces.json ${DIR}
+    fi
+}
+
+collectDirLogs() {
+    local DIR=${OUTDIR}${1}
+    if [ -d ${1} ]; then
+        mkdir -p ${DIR}
+        cp ${1}/*.log ${DIR}
+    fi
+}
+
+collectDir() {
+    local DIR=${OUTDIR}${1}
+    if [ -d ${1} ]; then
+        mkdir -p ${DIR}
+        cp ${1}/* ${DIR}
+    fi
+}
+
+collectDaemonLogs() {
+    local DIR=${OUTDIR}/daemons
+    mkdir -p ${DIR}
+    if systemctl list-units --no-pager | grep -q ${1}; then
+        timeout 15 systemctl status ${1} &> ${DIR}/${1}.status
+        timeout 15 journalctl --utc -o short-iso --no-pager -r -u ${1} &> /tmp/${1}.log
+        tac /tmp/${1}.log > ${DIR}/${1}.log
+    fi
+}
+
+collectIgnitionLogs() {
+    local DIR=${OUTDIR}/ignition
+    mkdir -p ${DIR}
+    timeout 15 journalctl --utc -o short-iso --no-pager -t ignition &> ${DIR}/ignition.log
+}
+
+collectContainerLogs() {
+    local DIR=${OUTDIR}/containers
+    mkdir -p ${DIR}
+    find /var/log/containers -name "${1}*" -exec cp {} ${DIR} \;
+}
+
+compressLogsDirectory() {
+    sync
+    TGZ="/tmp/logs.tar.gz"
+    rm -f ${TGZ}
+    tar -C ${OUTDIR}/.. -czf ${TGZ} ${HOSTNAME}
+}
+
+OUTDIR="$(mktemp -d)/${HOSTNAME}"
+
+collectCloudProviderJson
+collectDirLogs /var/log
+collectDirLogs /var/log/azure
+collectDir /etc/kubernetes/manifests
+collectContainerLogs kube-proxy
+collectDaemonLogs kubelet.service
+collectDaemonLogs docker.service
+collectDaemonLogs containerd.service
+collectIgnitionLogs
+
+compressLogsDirectory
@@ -51,7 +51,10 @@ param(
     $TargetEnvironment,
 
     [string]
-    $UserAssignedClientID
+    $UserAssignedClientID,
+
+    [string]
+    $GmsaDomainJoinPassword # base64
 )
 
 # These globals will not change between nodes in the same cluster, so they are not
@@ -72,7 +75,7 @@ $global:ContainerdUrl = "{{WrapAsParameter "windowsContainerdURL"}}"
 $global:ContainerdSdnPluginUrl = "{{WrapAsParameter "windowsSdnPluginURL"}}"
 
 ## Docker Version
-$global:DockerVersion = "{{WrapAsParameter "windowsDockerVersion"}}"
+$global:DockerVersion = "{{if .WindowsDockerVersion}}{{.WindowsDockerVersion}}{{else}}{{WrapAsParameter "windowsDockerVersion"}}{{end}}"
 
 ## ContainerD Usage
 $global:ContainerRuntime = "{{WrapAsParameter "containerRuntime"}}"
@@ -94,6 +97,7 @@ $global:MasterSubnet = "{{GetWindowsMasterSubnetARMParam}}"
 $global:SecurityGroupName = "{{WrapAsVariable "nsgName"}}"
 $global:VNetName = "{{WrapAsVariable "virtualNetworkName"}}"
 $global:RouteTableName = "{{WrapAsVariable "routeTableName"}}"
+$global:RouteTableResourceGroup = "{{WrapAsVariable "routeTableResourceGroup"}}"
 $global:PrimaryAvailabilitySetName = "{{WrapAsVariable "primaryAvailabilitySetName"}}"
 $global:PrimaryScaleSetName = "{{WrapAsVariable "primaryScaleSetName"}}"
 
@@ -155,6 +159,14 @@ $global:AlwaysPullWindowsPauseImage = [System.Convert]::ToBoolean("{{WrapAsVaria
 # Secure Windows TLS protocols
 $global:WindowsSecureTLSEnabled = [System.Convert]::ToBoolean("{{WrapAsVariable "windowsSecureTLSEnabled" }}");
 
+# gMSA settings
+$global:GmsaMode = "{{WrapAsVariable "windowsGmsaMode" }}";
+$global:GmsaDomainName = "{{WrapAsVariable "windowsGmsaDomainName" }}";
+$global:GmsaDomainJoinUser = "{{WrapAsVariable "windowsGmsaDomainJoinUser" }}";
+$global:GmsaOrganizationalUnit = "{{WrapAsVariable "windowsGmsaOrganizationalUnit" }}";
+$global:GmsaCCGPluginURL = "{{WrapAsVariable "windowsGmsaCCGPluginURL" }}";
+$global:GmsaCCGPluginCLSID = "{{WrapAsVariable "windowsGmsaCCGPluginCLSID" }}";
+
 # Base64 representation of ZIP archive
 $zippedFiles = "{{ GetKubernetesWindowsAgentFunctions }}"
 
@@ -172,6 +184,7 @@ Expand-Archive scripts.zip -DestinationPath "C:\\AzureData\\"
 . c:\AzureData\k8s\windowsinstallopensshfunc.ps1
 . c:\AzureData\k8s\windowscontainerdfunc.ps1
 . c:\AzureData\k8s\windowshostsconfigagentfunc.ps1
+. c:\AzureData\k8s\windowsgmsafunc.ps1
 
 $useContainerD = ($global:ContainerRuntime -eq "containerd")
 $global:KubeClusterConfigPath = "c:\k\kubeclusterconfig.json"
@@ -313,6 +326,7 @@ try
             -SecurityGroupName $global:SecurityGroupName `
             -VNetName $global:VNetName `
             -RouteTableName $global:RouteTableName `
+            -RouteTableResourceGroup $global:RouteTableResourceGroup `
             -PrimaryAvailabilitySetName $global:PrimaryAvailabilitySetName `
             -PrimaryScaleSetName $global:PrimaryScaleSetName `
             -UseManagedIdentityExtension $global:UseManagedIdentityExtension `
@@ -434,6 +448,18 @@ try
             Enable-SecureTls
         }
 
+        if ($global:GmsaMode -eq "DomainJoin") {
+            Write-Log "Join node to domain $global:GmsaDomainName for gMSA"
+            Join-GmsaDomain -DomainName $global:GmsaDomainName `
+                -DomainJoinUser $global:GmsaDomainJoinUser `
+                -DomainJoinPassword $GmsaDomainJoinPassword `
+                -OrganizationalUnit $global:GmsaOrganizationalUnit
+        } elseif ($global:GmsaMode -eq "CCGPlugin") {
+            Write-Log "Install gMSA CCG plugin"
+            Install-GmsaCCGPlugin -CCGPluginURL $global:GmsaCCGPluginURL `
+                -CCGPluginCLSID $global:GmsaCCGPluginCLSID
+        }
+
         Write-Log "Adjust pagefile size"
         Adjust-PageFileSize
 
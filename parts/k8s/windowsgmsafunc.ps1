@@ -0,0 +1,117 @@
+function Join-GmsaDomain {
+    Param(
+        [Parameter(Mandatory = $true)][string]
+        $DomainName,
+        [Parameter(Mandatory = $true)][string]
+        $DomainJoinUser,
+        [Parameter(Mandatory = $true)][string]
+        $DomainJoinPassword, # base64
+        [Parameter(Mandatory = $false)][string]
+        $OrganizationalUnit
+    )
+
+    if ((Get-WmiObject -Class Win32_ComputerSystem).Domain -eq $DomainName) {
+        Write-Log "Node is already joined to domain $DomainName"
+        return
+    }
+
+    $password = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String($DomainJoinPassword))
+    $securePassword = ConvertTo-SecureString -String $password -AsPlainText -Force
+    $credential = New-Object System.Management.Automation.PSCredential($DomainJoinUser, $securePassword)
+
+    $joinParams = @{
+        DomainName = $DomainName
+        Credential = $credential
+        Force = $true
+        ErrorAction = "Stop"
+    }
+    if (-not [string]::IsNullOrEmpty($OrganizationalUnit)) {
+        $joinParams["OUPath"] = $OrganizationalUnit
+    }
+
+    # The node is restarted at the end of the provisioning, which completes the domain join
+    Write-Log "Joining node to domain $DomainName"
+    Retry-Command -Command "Add-Computer" -Args $joinParams -Retries 5 -RetryDelaySeconds 10
+}
+
+function Install-GmsaCCGPlugin {
+    Param(
+        [Parameter(Mandatory = $true)][string]
+        $CCGPluginURL,
+        [Parameter(Mandatory = $true)][string]
+        $CCGPluginCLSID
+    )
+
+    $tempdir = New-TemporaryDirectory
+    $pluginPackage = "$tempdir\ccgakvplugin.zip"
+
+    DownloadFileOverHttp -Url $CCGPluginURL -DestinationPath $pluginPackage
+    Expand-Archive -Path $pluginPackage -DestinationPath $tempdir -Force
+
+    $pluginDll = Get-ChildItem -Path $tempdir -Filter "CCGAKVPlugin.dll" -Recurse | Select-Object -First 1
+    if ($null -eq $pluginDll) {
+        throw "CCGAKVPlugin.dll was not found in $CCGPluginURL"
+    }
+    Copy-Item -Path $pluginDll.FullName -Destination "$env:SystemRoot\System32\CCGAKVPlugin.dll" -Force
+
+    del $tempdir -Recurse
+
+    # The plugin is a COM server that Container Credential Guard (CCG) activates
+    # to retrieve the gMSA credentials from Azure Key Vault
+    Write-Log "Registering the gMSA CCG plugin"
+    & "$env:SystemRoot\System32\regsvr32.exe" /s "$env:SystemRoot\System32\CCGAKVPlugin.dll"
+    if ($LASTEXITCODE -ne 0) {
+        throw "Failed to register CCGAKVPlugin.dll, exit code $LASTEXITCODE"
+    }
+
+    $ccgKey = "HKLM:\SYSTEM\CurrentControlSet\Control\CCG\COMClasses\$CCGPluginCLSID"
+    if (-not (Test-Path $ccgKey)) {
+        Grant-CCGRegistryKeyOwnership
+        New-Item -Path $ccgKey -Force | Out-Null
+    }
+}
+
+function Grant-CCGRegistryKeyOwnership {
+    # The CCG COMClasses key is owned by TrustedInstaller, take ownership and grant
+    # Administrators full control so that the plugin can be registered
+    $keyPath = "SYSTEM\CurrentControlSet\Control\CCG\COMClasses"
+    $definition = @"
+using System;
+using System.Runtime.InteropServices;
+public class CCGTokenPrivilege {
+    [DllImport("advapi32.dll", ExactSpelling = true, SetLastError = true)]
+    internal static extern bool AdjustTokenPrivileges(IntPtr htok, bool disall, ref TokPriv1Luid newst, int len, IntPtr prev, IntPtr relen);
+    [DllImport("advapi32.dll", ExactSpelling = true, SetLastError = true)]
+    internal static extern bool OpenProcessToken(IntPtr h, int acc, ref IntPtr phtok);
+    [DllImport("advapi32.dll", SetLastError = true)]
+    internal static extern bool LookupPrivilegeValue(string host, string name, ref long pluid);
+    [StructLayout(LayoutKind.Sequential, Pack = 1)]
+    internal struct TokPriv1Luid { public int Count; public long Luid; public int Attr; }
+    public static bool Enable(long processHandle, string privilege) {
+        TokPriv1Luid tp;
+        IntPtr htok = IntPtr.Zero;
+        OpenProcessToken(new IntPtr(processHandle), 0x28, ref htok);
+        tp.Count = 1;
+        tp.Luid = 0;
+        tp.Attr = 2;
+        LookupPrivilegeValue(null, privilege, ref tp.Luid);
+        return AdjustTokenPrivileges(htok, false, ref tp, 0, IntPtr.Zero, IntPtr.Zero);
+    }
+}
+"@
+    Add-Type -TypeDefinition $definition
+    $processHandle = (Get-Process -Id $pid).Handle
+    [CCGTokenPrivilege]::Enable($processHandle, "SeTakeOwnershipPrivilege") | Out-Null
+
+    $administrators = New-Object System.Security.Principal.SecurityIdentifier("S-1-5-32-544")
+    $key = [Microsoft.Win32.Registry]::LocalMachine.OpenSubKey($keyPath, [Microsoft.Win32.RegistryKeyPermissionCheck]::ReadWriteSubTree, [System.Security.AccessControl.RegistryRights]::TakeOwnership)
+    $acl = $key.GetAccessControl([System.Security.AccessControl.AccessControlSections]::None)
+    $acl.SetOwner($administrators)
+    $key.SetAccessControl($acl)
+
+    $key = [Microsoft.Win32.Registry]::LocalMachine.OpenSubKey($keyPath, [Microsoft.Win32.RegistryKeyPermissionCheck]::ReadWriteSubTree, [System.Security.AccessControl.RegistryRights]::ChangePermissions)
+    $acl = $key.GetAccessControl()
+    $rule = New-Object System.Security.AccessControl.RegistryAccessRule($administrators, "FullControl", "ContainerInherit", "None", "Allow")
+    $acl.SetAccessRule($rule)
+    $key.SetAccessControl($acl)
+}
@@ -24,6 +24,8 @@ Write-AzureConfig {
         $VNetName,
         [Parameter(Mandatory = $true)][string]
         $RouteTableName,
+        [Parameter(Mandatory = $false)][string]
+        $RouteTableResourceGroup,
         [Parameter(Mandatory = $false)][string] # Need one of these configured
         $PrimaryAvailabilitySetName,
         [Parameter(Mandatory = $false)][string] # Need one of these configured
@@ -66,6 +68,7 @@ Write-AzureConfig {
     "securityGroupName": "$SecurityGroupName",
     "vnetName": "$VNetName",
     "routeTableName": "$RouteTableName",
+    "routeTableResourceGroup": "$RouteTableResourceGroup",
     "primaryAvailabilitySetName": "$PrimaryAvailabilitySetName",
     "primaryScaleSetName": "$PrimaryScaleSetName",
     "useManagedIdentityExtension": $UseManagedIdentityExtension,
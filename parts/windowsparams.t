@@ -52,6 +52,14 @@
         "description": "Password for the Windows Swarm Agent Virtual Machines."
       }
     },
+{{if HasWindowsGMSADomainJoin}}
+    "windowsGmsaDomainJoinPassword": {
+      "type": "securestring",
+      "metadata": {
+        "description": "Password of the user that joins the Windows agent virtual machines to the Active Directory domain."
+      }
+    },
+{{end}}
     "agentWindowsImageName": {
       "defaultValue": "",
       "type": "string",
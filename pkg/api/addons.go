@@ -848,6 +848,26 @@ func (cs *ContainerService) setAddonsConfig(isUpgrade bool) {
 		},
 	}
 
+	defaultGMSAWebhookAddonsConfig := KubernetesAddon{
+		Name:    common.GMSAWebhookAddonName,
+		Enabled: to.BoolPtr(cs.Properties.WindowsProfile.IsGMSAEnabled()),
+		Containers: []KubernetesContainerSpec{
+			{
+				Name:           common.GMSAWebhookAddonName,
+				Image:          specConfig.MCRKubernetesImageBase + gmsaWebhookImageReference,
+				CPURequests:    "10m",
+				MemoryRequests: "50Mi",
+				CPULimits:      "100m",
+				MemoryLimits:   "100Mi",
+			},
+		},
+	}
+
+	defaultHypervRuntimeClassAddonsConfig := KubernetesAddon{
+		Name:    common.HypervRuntimeClassAddonName,
+		Enabled: to.BoolPtr(len(cs.Properties.WindowsProfile.GetWindowsHypervRuntimeHandlerNames()) > 0 && o.KubernetesConfig.NeedsContainerd()),
+	}
+
 	defaultAzureArcOnboardingAddonsConfig := KubernetesAddon{
 		Name:    common.AzureArcOnboardingAddonName,
 		Enabled: to.BoolPtr(DefaultAzureArcOnboardingAddonEnabled),
@@ -897,6 +917,8 @@ func (cs *ContainerService) setAddonsConfig(isUpgrade bool) {
 		defaultScheduledMaintenanceAddonsConfig,
 		defaultSecretsStoreCSIDriverAddonsConfig,
 		defaultAzureArcOnboardingAddonsConfig,
+		defaultGMSAWebhookAddonsConfig,
+		defaultHypervRuntimeClassAddonsConfig,
 	}
 	// Add default addons specification, if no user-provided spec exists
 	if o.KubernetesConfig.Addons == nil {
@@ -995,6 +1017,13 @@ func (cs *ContainerService) setAddonsConfig(isUpgrade bool) {
 		}
 	}
 
+	// The gMSA credential specs of Windows pods require the webhook, also when gMSA is configured on an existing cluster
+	if cs.Properties.WindowsProfile.IsGMSAEnabled() {
+		if i := getAddonsIndexByName(o.KubernetesConfig.Addons, common.GMSAWebhookAddonName); i > -1 {
+			o.KubernetesConfig.Addons[i].Enabled = to.BoolPtr(true)
+		}
+	}
+
 	// Honor customKubeProxyImage field
 	if o.KubernetesConfig.CustomKubeProxyImage != "" {
 		if i := getAddonsIndexByName(o.KubernetesConfig.Addons, common.KubeProxyAddonName); i > -1 {
@@ -90,6 +90,38 @@ func TestPodSecurityPolicyConfigUpgrade(t *testing.T) {
 	}
 }
 
+func TestGMSAWebhookAddonEnabledWithGMSA(t *testing.T) {
+	mockCS := getMockBaseContainerService("1.18.1")
+	o := mockCS.Properties.OrchestratorProfile
+	o.OrchestratorType = Kubernetes
+	o.KubernetesConfig.Addons = []KubernetesAddon{
+		{
+			Name:    common.GMSAWebhookAddonName,
+			Enabled: to.BoolPtr(false),
+		},
+	}
+	mockCS.Properties.WindowsProfile = &WindowsProfile{
+		GMSA: &WindowsGMSAProfile{
+			Mode:       WindowsGMSAModeCCGPlugin,
+			DomainName: "contoso.com",
+		},
+	}
+
+	mockCS.setAddonsConfig(false)
+
+	i := getAddonsIndexByName(o.KubernetesConfig.Addons, common.GMSAWebhookAddonName)
+	if i < 0 {
+		t.Fatalf("expected a positive index for the addon %s, instead got %d from getAddonsIndexByName", common.GMSAWebhookAddonName, i)
+	}
+	if !o.KubernetesConfig.Addons[i].IsEnabled() {
+		t.Errorf("expected addon %s to be enabled when gMSA is configured", common.GMSAWebhookAddonName)
+	}
+	expectedImage := mockCS.GetCloudSpecConfig().KubernetesSpecConfig.MCRKubernetesImageBase + gmsaWebhookImageReference
+	if image := o.KubernetesConfig.Addons[i].Containers[0].Image; image != expectedImage {
+		t.Errorf("expected addon %s image %s, instead got %s", common.GMSAWebhookAddonName, expectedImage, image)
+	}
+}
+
 func TestDisabledAddons(t *testing.T) {
 	defaultAddon := KubernetesAddon{
 		Name:    "mockAddon",
@@ -8,6 +8,7 @@ import (
 	"os"
 	"reflect"
 
+	v1 "github.com/Azure/aks-engine/pkg/api/v1"
 	"github.com/Azure/aks-engine/pkg/api/vlabs"
 	"github.com/Azure/aks-engine/pkg/helpers"
 	"github.com/Azure/aks-engine/pkg/i18n"
@@ -79,11 +80,6 @@ func (a *Apiloader) LoadContainerService(
 	version string,
 	validate, isUpdate bool,
 	existingContainerService *ContainerService) (*ContainerService, error) {
-	var curOrchVersion string
-	hasExistingCS := existingContainerService != nil
-	if hasExistingCS {
-		curOrchVersion = existingContainerService.Properties.OrchestratorProfile.OrchestratorVersion
-	}
 	switch version {
 	case vlabs.APIVersion:
 		containerService := &vlabs.ContainerService{}
@@ -96,36 +92,58 @@ func (a *Apiloader) LoadContainerService(
 		if e := checkJSONKeys(contents, reflect.TypeOf(*containerService), reflect.TypeOf(TypeMeta{})); e != nil {
 			return nil, e
 		}
-		if hasExistingCS {
-			vecs := ConvertContainerServiceToVLabs(existingContainerService)
-			if e := containerService.Merge(vecs); e != nil {
-				return nil, e
-			}
-		}
-		if validate {
-			if e := containerService.Validate(isUpdate); e != nil {
-				return nil, e
-			}
-		}
+		return loadVLabsContainerService(containerService, validate, isUpdate, existingContainerService)
 
-		var unversioned *ContainerService
-		var err error
-		if unversioned, err = ConvertVLabsContainerService(containerService, isUpdate); err != nil {
-			return nil, err
+	case v1.APIVersion:
+		containerService := &v1.ContainerService{}
+		if e := json.Unmarshal(contents, &containerService); e != nil {
+			return nil, e
+		}
+		if containerService.Properties.OrchestratorProfile == nil {
+			containerService.Properties.OrchestratorProfile = &v1.OrchestratorProfile{}
 		}
-		if curOrchVersion != "" &&
-			(containerService.Properties.OrchestratorProfile == nil ||
-				(containerService.Properties.OrchestratorProfile.OrchestratorVersion == "" &&
-					containerService.Properties.OrchestratorProfile.OrchestratorRelease == "")) {
-			unversioned.Properties.OrchestratorProfile.OrchestratorVersion = curOrchVersion
+		if e := checkJSONKeys(contents, reflect.TypeOf(*containerService), reflect.TypeOf(TypeMeta{})); e != nil {
+			return nil, e
 		}
-		return unversioned, nil
+		// v1 is merged, validated and converted through its vlabs representation
+		return loadVLabsContainerService(v1.ConvertToVLabs(containerService), validate, isUpdate, existingContainerService)
 
 	default:
 		return nil, a.Translator.Errorf("unrecognized APIVersion '%s'", version)
 	}
 }
 
+// loadVLabsContainerService merges a vlabs ContainerService with the existing one, validates it and returns the unversioned representation
+func loadVLabsContainerService(containerService *vlabs.ContainerService, validate, isUpdate bool, existingContainerService *ContainerService) (*ContainerService, error) {
+	var curOrchVersion string
+	hasExistingCS := existingContainerService != nil
+	if hasExistingCS {
+		curOrchVersion = existingContainerService.Properties.OrchestratorProfile.OrchestratorVersion
+		vecs := ConvertContainerServiceToVLabs(existingContainerService)
+		if e := containerService.Merge(vecs); e != nil {
+			return nil, e
+		}
+	}
+	if validate {
+		if e := containerService.Validate(isUpdate); e != nil {
+			return nil, e
+		}
+	}
+
+	var unversioned *ContainerService
+	var err error
+	if unversioned, err = ConvertVLabsContainerService(containerService, isUpdate); err != nil {
+		return nil, err
+	}
+	if curOrchVersion != "" &&
+		(containerService.Properties.OrchestratorProfile == nil ||
+			(containerService.Properties.OrchestratorProfile.OrchestratorVersion == "" &&
+				containerService.Properties.OrchestratorProfile.OrchestratorRelease == "")) {
+		unversioned.Properties.OrchestratorProfile.OrchestratorVersion = curOrchVersion
+	}
+	return unversioned, nil
+}
+
 // SerializeContainerService takes an unversioned container service and returns the bytes
 func (a *Apiloader) SerializeContainerService(containerService *ContainerService, version string) ([]byte, error) {
 	switch version {
@@ -139,6 +157,15 @@ func (a *Apiloader) SerializeContainerService(containerService *ContainerService
 			return nil, err
 		}
 		return b, nil
+	case v1.APIVersion:
+		armContainerService := &V1ARMContainerService{}
+		armContainerService.ContainerService = ConvertContainerServiceToV1(containerService)
+		armContainerService.APIVersion = version
+		b, err := helpers.JSONMarshalIndent(armContainerService, "", "  ", false)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
 	default:
 		return nil, a.Translator.Errorf("invalid version %s for conversion back from unversioned object", version)
 	}
@@ -4,12 +4,14 @@
 package api
 
 import (
+	v1 "github.com/Azure/aks-engine/pkg/api/v1"
 	"github.com/Azure/aks-engine/pkg/api/vlabs"
 	"github.com/Azure/aks-engine/pkg/i18n"
 	"github.com/leonelquinteros/gotext"
 
 	"os"
 	"path"
+	"strings"
 	"testing"
 )
 
@@ -567,6 +569,48 @@ func TestSerializeContainerService(t *testing.T) {
 	}
 }
 
+func TestSerializeContainerServiceV1RoundTrip(t *testing.T) {
+	cs := getDefaultContainerService()
+	cs.Properties.OrchestratorProfile.KubernetesConfig.GCHighThreshold = 85
+	cs.Properties.OrchestratorProfile.KubernetesConfig.DockerEngineVersion = "17.03.*"
+	apiloader := &Apiloader{
+		Translator: &i18n.Translator{},
+	}
+
+	b, err := apiloader.SerializeContainerService(cs, v1.APIVersion)
+	if err != nil {
+		t.Fatalf("unexpected error while trying to Serialize Container Service: %s", err.Error())
+	}
+	for _, s := range []string{`"apiVersion": "v1"`, `"gcHighThreshold": 85`} {
+		if !strings.Contains(string(b), s) {
+			t.Errorf("expected v1 api model to contain %s, got %s", s, string(b))
+		}
+	}
+	if strings.Contains(string(b), "dockerEngineVersion") {
+		t.Errorf("expected v1 api model not to contain dockerEngineVersion, got %s", string(b))
+	}
+
+	loaded, version, err := apiloader.DeserializeContainerService(b, false, true, nil)
+	if err != nil {
+		t.Fatalf("unexpected error deserializing the v1 api model: %s", err)
+	}
+	if version != v1.APIVersion {
+		t.Errorf("expected apiVersion %s, instead got: %s", v1.APIVersion, version)
+	}
+	if loaded.Properties.OrchestratorProfile.KubernetesConfig.GCHighThreshold != 85 {
+		t.Errorf("expected gcHighThreshold to survive the round trip, got %d", loaded.Properties.OrchestratorProfile.KubernetesConfig.GCHighThreshold)
+	}
+	if loaded.Properties.MasterProfile.DNSPrefix != cs.Properties.MasterProfile.DNSPrefix {
+		t.Errorf("expected dnsPrefix %s, got %s", cs.Properties.MasterProfile.DNSPrefix, loaded.Properties.MasterProfile.DNSPrefix)
+	}
+
+	// deprecated vlabs properties are rejected by the v1 api version
+	_, _, err = apiloader.DeserializeContainerService([]byte(strings.Replace(string(b), `"gcHighThreshold": 85`, `"gcHighThreshold": 85, "dockerEngineVersion": "17.03.*"`, 1)), false, true, nil)
+	if err == nil {
+		t.Errorf("expected error loading a v1 api model with a deprecated vlabs property")
+	}
+}
+
 func TestLoadCertificateProfileFromFile(t *testing.T) {
 	locale := gotext.NewLocale(path.Join("..", "..", "translations"), "en_US")
 	if err := i18n.Initialize(locale); err != nil {
@@ -125,6 +125,38 @@ var (
 		ImageVersion:   "latest",
 	}
 
+	//Ubuntu2004Arm64OSImageConfig is the Arm64 flavor of the Ubunutu 20.04-LTS Linux distribution.
+	Ubuntu2004Arm64OSImageConfig = AzureOSImageConfig{
+		ImageOffer:     "0001-com-ubuntu-server-focal",
+		ImageSku:       "20_04-lts-arm64",
+		ImagePublisher: "Canonical",
+		ImageVersion:   "latest",
+	}
+
+	//Ubuntu2204OSImageConfig is the Ubunutu 22.04-LTS Linux distribution.
+	Ubuntu2204OSImageConfig = AzureOSImageConfig{
+		ImageOffer:     "0001-com-ubuntu-server-jammy",
+		ImageSku:       "22_04-lts",
+		ImagePublisher: "Canonical",
+		ImageVersion:   "latest",
+	}
+
+	//Ubuntu2204Gen2OSImageConfig is Gen2 flavor the Ubunutu 22.04-LTS Linux distribution.
+	Ubuntu2204Gen2OSImageConfig = AzureOSImageConfig{
+		ImageOffer:     "0001-com-ubuntu-server-jammy",
+		ImageSku:       "22_04-lts-gen2",
+		ImagePublisher: "Canonical",
+		ImageVersion:   "latest",
+	}
+
+	//Ubuntu2204Arm64OSImageConfig is the Arm64 flavor of the Ubunutu 22.04-LTS Linux distribution.
+	Ubuntu2204Arm64OSImageConfig = AzureOSImageConfig{
+		ImageOffer:     "0001-com-ubuntu-server-jammy",
+		ImageSku:       "22_04-lts-arm64",
+		ImagePublisher: "Canonical",
+		ImageVersion:   "latest",
+	}
+
 	//FlatcarImageConfig is the Flatcar Linux distribution.
 	FlatcarImageConfig = AzureOSImageConfig{
 		ImageOffer:     "flatcar-container-linux-free",
@@ -133,6 +165,31 @@ var (
 		ImageVersion:   "latest",
 	}
 
+	//FlatcarGen2ImageConfig is Gen2 flavor the Flatcar Linux distribution.
+	FlatcarGen2ImageConfig = AzureOSImageConfig{
+		ImageOffer:     "flatcar-container-linux-free",
+		ImageSku:       "stable-gen2",
+		ImagePublisher: "kinvolk",
+		ImageVersion:   "latest",
+	}
+
+	// Gen2OSImageConfig holds the Gen2 flavor of the distros based on a generation 1 image,
+	// used by the agent pools that set hyperVGeneration V2
+	Gen2OSImageConfig = map[Distro]AzureOSImageConfig{
+		Ubuntu1804: Ubuntu1804Gen2OSImageConfig,
+		Ubuntu2004: Ubuntu2004Gen2OSImageConfig,
+		Ubuntu2204: Ubuntu2204Gen2OSImageConfig,
+		Flatcar:    FlatcarGen2ImageConfig,
+	}
+
+	// Arm64OSImageConfig holds the Arm64 flavor of the distros supported by arm64 agent pools
+	Arm64OSImageConfig = map[Distro]AzureOSImageConfig{
+		Ubuntu2004:     Ubuntu2004Arm64OSImageConfig,
+		Ubuntu2004Gen2: Ubuntu2004Arm64OSImageConfig,
+		Ubuntu2204:     Ubuntu2204Arm64OSImageConfig,
+		Ubuntu2204Gen2: Ubuntu2204Arm64OSImageConfig,
+	}
+
 	// AKSUbuntu1604OSImageConfig is the AKS image based on Ubuntu 16.04-LTS.
 	// Ubuntu 16.04-LTS has reached EOL as of April 2021, the below image reference should never be updated
 	// Eventually this VHD reference will be deprecated altogether
@@ -208,6 +265,8 @@ var (
 			Ubuntu1804Gen2:    Ubuntu1804Gen2OSImageConfig,
 			Ubuntu2004:        Ubuntu2004OSImageConfig,
 			Ubuntu2004Gen2:    Ubuntu2004Gen2OSImageConfig,
+			Ubuntu2204:        Ubuntu2204OSImageConfig,
+			Ubuntu2204Gen2:    Ubuntu2204Gen2OSImageConfig,
 			Flatcar:           FlatcarImageConfig,
 			AKSUbuntu1604:     AKSUbuntu1604OSImageConfig,
 			AKS1604Deprecated: AKSUbuntu1604OSImageConfig, // for back-compat
@@ -231,6 +290,8 @@ var (
 			Ubuntu1804Gen2:    Ubuntu1804Gen2OSImageConfig,
 			Ubuntu2004:        Ubuntu2004OSImageConfig,
 			Ubuntu2004Gen2:    Ubuntu2004Gen2OSImageConfig,
+			Ubuntu2204:        Ubuntu2204OSImageConfig,
+			Ubuntu2204Gen2:    Ubuntu2204Gen2OSImageConfig,
 			Flatcar:           FlatcarImageConfig,
 			AKSUbuntu1604:     Ubuntu1604OSImageConfig,
 			AKS1604Deprecated: Ubuntu1604OSImageConfig, // for back-compat
@@ -252,6 +313,8 @@ var (
 			Ubuntu1804Gen2:    Ubuntu1804Gen2OSImageConfig,
 			Ubuntu2004:        Ubuntu2004OSImageConfig,
 			Ubuntu2004Gen2:    Ubuntu2004Gen2OSImageConfig,
+			Ubuntu2204:        Ubuntu2204OSImageConfig,
+			Ubuntu2204Gen2:    Ubuntu2204Gen2OSImageConfig,
 			Flatcar:           FlatcarImageConfig,
 			AKSUbuntu1604:     AKSUbuntu1604OSImageConfig,
 			AKS1604Deprecated: AKSUbuntu1604OSImageConfig, // for back-compat
@@ -295,6 +358,8 @@ var (
 			Ubuntu1804Gen2:    Ubuntu1804Gen2OSImageConfig,
 			Ubuntu2004:        Ubuntu2004OSImageConfig,
 			Ubuntu2004Gen2:    Ubuntu2004Gen2OSImageConfig,
+			Ubuntu2204:        Ubuntu2204OSImageConfig,
+			Ubuntu2204Gen2:    Ubuntu2204Gen2OSImageConfig,
 			Flatcar:           FlatcarImageConfig,
 			AKSUbuntu1604:     AKSUbuntu1604OSImageConfig,
 			AKS1604Deprecated: AKSUbuntu1604OSImageConfig, // for back-compat
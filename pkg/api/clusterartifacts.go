@@ -0,0 +1,427 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-engine/pkg/api/common"
+)
+
+const (
+	// ClusterArtifactTypeImage is a container image pulled by the cluster nodes
+	ClusterArtifactTypeImage = "image"
+	// ClusterArtifactTypeFile is a file downloaded by the cluster nodes
+	ClusterArtifactTypeFile = "file"
+)
+
+// defaultKubeBinaryURLFormat is the Linux node binaries URL used by cse_install.sh when customKubeBinaryURL is not set
+const defaultKubeBinaryURLFormat = "https://kubernetesartifacts.azureedge.net/kubernetes/v%s/binaries/kubernetes-node-linux-amd64.tar.gz"
+
+// kubeletPauseImageKey is the kubelet flag that sets the pod sandbox image
+const kubeletPauseImageKey = "--pod-infra-container-image"
+
+// ClusterArtifact is a container image or a file that the cluster nodes download while they are provisioned
+type ClusterArtifact struct {
+	Type      string `json:"type"`
+	Component string `json:"component"`
+	Reference string `json:"reference"`
+	Mirror    string `json:"mirror,omitempty"`
+}
+
+// GetClusterArtifacts returns the container images and the files that the cluster nodes download,
+// sorted by type, component and reference. The container service must have its defaults set.
+func (cs *ContainerService) GetClusterArtifacts() []ClusterArtifact {
+	if cs.Properties == nil || cs.Properties.OrchestratorProfile == nil || cs.Properties.OrchestratorProfile.KubernetesConfig == nil {
+		return nil
+	}
+	cloudSpecConfig := cs.GetCloudSpecConfig()
+	o := cs.Properties.OrchestratorProfile
+	k := o.KubernetesConfig
+	var artifacts []ClusterArtifact
+	add := func(artifactType, component, reference string) {
+		if reference != "" {
+			artifacts = append(artifacts, ClusterArtifact{Type: artifactType, Component: component, Reference: reference})
+		}
+	}
+
+	for _, component := range k.Components {
+		if component.IsEnabled() {
+			for _, container := range component.Containers {
+				add(ClusterArtifactTypeImage, getContainerArtifactComponent(component.Name, container.Name), container.Image)
+			}
+		}
+	}
+	for _, addon := range k.Addons {
+		if addon.IsEnabled() {
+			for _, container := range addon.Containers {
+				add(ClusterArtifactTypeImage, getContainerArtifactComponent(addon.Name, container.Name), container.Image)
+			}
+		}
+	}
+	add(ClusterArtifactTypeImage, "pause", k.KubeletConfig[kubeletPauseImageKey])
+	add(ClusterArtifactTypeImage, "etcd", k.GetEtcdDownloadURLBase(cloudSpecConfig)+"etcd:v"+k.EtcdVersion)
+	add(ClusterArtifactTypeImage, "hyperkube", k.CustomHyperkubeImage)
+	add(ClusterArtifactTypeImage, "cloud-controller-manager", k.CustomCcmImage)
+
+	kubeBinaryURL := cs.getKubeBinaryURL()
+	add(ClusterArtifactTypeFile, "kubernetes-node-linux", kubeBinaryURL)
+	add(ClusterArtifactTypeFile, "cni-plugins", k.GetCNIPluginsURL(cloudSpecConfig))
+	if o.IsAzureCNI() {
+		add(ClusterArtifactTypeFile, "azure-cni-linux", k.GetAzureCNIURLLinux(cloudSpecConfig))
+	}
+	if cs.Properties.HasArm64() {
+		add(ClusterArtifactTypeFile, "kubernetes-node-linux-arm64", GetArchBinaryURL(kubeBinaryURL, ArchitectureArm64))
+		add(ClusterArtifactTypeFile, "cni-plugins-arm64", GetArchBinaryURL(k.GetCNIPluginsURL(cloudSpecConfig), ArchitectureArm64))
+		if o.IsAzureCNI() {
+			add(ClusterArtifactTypeFile, "azure-cni-linux-arm64", GetArchBinaryURL(k.GetAzureCNIURLLinux(cloudSpecConfig), ArchitectureArm64))
+		}
+	}
+	add(ClusterArtifactTypeFile, "moby", k.LinuxMobyURL)
+	add(ClusterArtifactTypeFile, "runc", k.LinuxRuncURL)
+	add(ClusterArtifactTypeFile, "containerd", k.LinuxContainerdURL)
+
+	if cs.Properties.HasWindows() {
+		w := cs.Properties.WindowsProfile
+		add(ClusterArtifactTypeImage, "windows-pause", w.WindowsPauseImageURL)
+
+		add(ClusterArtifactTypeFile, "kubernetes-node-windows", cs.getWindowsKubeBinariesURL())
+		add(ClusterArtifactTypeFile, "kubernetes-node-binaries-windows", k.WindowsNodeBinariesURL)
+		add(ClusterArtifactTypeFile, "windows-provisioning-scripts", w.ProvisioningScriptsPackageURL)
+		if o.IsAzureCNI() {
+			add(ClusterArtifactTypeFile, "azure-cni-windows", k.GetAzureCNIURLWindows(cloudSpecConfig))
+		}
+		if w.IsCSIProxyEnabled() {
+			add(ClusterArtifactTypeFile, "csi-proxy", w.CSIProxyURL)
+		}
+		if k.NeedsContainerd() {
+			add(ClusterArtifactTypeFile, "containerd-windows", k.WindowsContainerdURL)
+			add(ClusterArtifactTypeFile, "sdn-plugin-windows", k.WindowsSdnPluginURL)
+		}
+	}
+
+	sort.SliceStable(artifacts, func(i, j int) bool {
+		if artifacts[i].Type != artifacts[j].Type {
+			return artifacts[i].Type < artifacts[j].Type
+		}
+		if artifacts[i].Component != artifacts[j].Component {
+			return artifacts[i].Component < artifacts[j].Component
+		}
+		return artifacts[i].Reference < artifacts[j].Reference
+	})
+	return artifacts
+}
+
+// SetContainerImageRegistry points the container images of the cluster at registry,
+// keeping the repository path and tag of each image, and returns the images it rewrote.
+// An Azure container registry is also set as the private Azure registry server, so that the nodes
+// authenticate against it with the cluster service principal. The container service must have its defaults set.
+func (cs *ContainerService) SetContainerImageRegistry(registry string) []ClusterArtifact {
+	registry = strings.TrimSuffix(registry, "/")
+	if registry == "" || cs.Properties == nil || cs.Properties.OrchestratorProfile == nil || cs.Properties.OrchestratorProfile.KubernetesConfig == nil {
+		return nil
+	}
+	k := cs.Properties.OrchestratorProfile.KubernetesConfig
+	var mirrored []ClusterArtifact
+	seen := map[string]bool{}
+	mirror := func(component string, image *string) {
+		if *image == "" {
+			return
+		}
+		source := *image
+		*image = GetMirroredImage(source, registry)
+		if !seen[source] {
+			seen[source] = true
+			mirrored = append(mirrored, ClusterArtifact{Type: ClusterArtifactTypeImage, Component: component, Reference: source, Mirror: *image})
+		}
+	}
+
+	for i := range k.Components {
+		for j := range k.Components[i].Containers {
+			mirror(getContainerArtifactComponent(k.Components[i].Name, k.Components[i].Containers[j].Name), &k.Components[i].Containers[j].Image)
+		}
+	}
+	for i := range k.Addons {
+		for j := range k.Addons[i].Containers {
+			mirror(getContainerArtifactComponent(k.Addons[i].Name, k.Addons[i].Containers[j].Name), &k.Addons[i].Containers[j].Image)
+		}
+	}
+	mirror("hyperkube", &k.CustomHyperkubeImage)
+	mirror("kube-apiserver", &k.CustomKubeAPIServerImage)
+	mirror("kube-controller-manager", &k.CustomKubeControllerManagerImage)
+	mirror("kube-proxy", &k.CustomKubeProxyImage)
+	mirror("kube-scheduler", &k.CustomKubeSchedulerImage)
+	mirror("cloud-controller-manager", &k.CustomCcmImage)
+	if k.EtcdVersion != "" {
+		etcdDownloadURLBase := k.GetEtcdDownloadURLBase(cs.GetCloudSpecConfig())
+		etcdImage := etcdDownloadURLBase + "etcd:v" + k.EtcdVersion
+		mirror("etcd", &etcdImage)
+		k.EtcdDownloadURLBase = GetMirroredImage(etcdDownloadURLBase, registry)
+	}
+
+	// the Windows kubelets run a pause image built locally from windowsPauseImageURL
+	kubeletConfigs := []map[string]string{k.KubeletConfig}
+	if cs.Properties.MasterProfile != nil && cs.Properties.MasterProfile.KubernetesConfig != nil {
+		kubeletConfigs = append(kubeletConfigs, cs.Properties.MasterProfile.KubernetesConfig.KubeletConfig)
+	}
+	for _, profile := range cs.Properties.AgentPoolProfiles {
+		if !profile.IsWindows() && profile.KubernetesConfig != nil {
+			kubeletConfigs = append(kubeletConfigs, profile.KubernetesConfig.KubeletConfig)
+		}
+	}
+	for _, kubeletConfig := range kubeletConfigs {
+		if image, ok := kubeletConfig[kubeletPauseImageKey]; ok {
+			mirror("pause", &image)
+			kubeletConfig[kubeletPauseImageKey] = image
+		}
+	}
+	if cs.Properties.WindowsProfile != nil {
+		mirror("windows-pause", &cs.Properties.WindowsProfile.WindowsPauseImageURL)
+	}
+
+	// images defaulted from these bases after the registry is set, e.g. on upgrade, come from the mirror as well
+	k.KubernetesImageBase = registry + "/"
+	k.MCRKubernetesImageBase = registry + "/"
+	if k.PrivateAzureRegistryServer == "" && strings.Contains(getHostName(registry), ".azurecr.") {
+		k.PrivateAzureRegistryServer = getHostName(registry)
+	}
+
+	sort.SliceStable(mirrored, func(i, j int) bool {
+		return mirrored[i].Reference < mirrored[j].Reference
+	})
+	return mirrored
+}
+
+// SetFileMirror points the files that the cluster nodes download at mirror, an https URL,
+// keeping the path of each file, and returns the files it rewrote. The container service must have its defaults set.
+func (cs *ContainerService) SetFileMirror(mirror string) []ClusterArtifact {
+	mirror = strings.TrimSuffix(mirror, "/")
+	if mirror == "" || cs.Properties == nil || cs.Properties.OrchestratorProfile == nil || cs.Properties.OrchestratorProfile.KubernetesConfig == nil {
+		return nil
+	}
+	cloudSpecConfig := cs.GetCloudSpecConfig()
+	o := cs.Properties.OrchestratorProfile
+	k := o.KubernetesConfig
+	var mirrored []ClusterArtifact
+	set := func(component string, url *string, source string, archBinaries bool) {
+		if source == "" {
+			return
+		}
+		*url = GetMirroredFileURL(source, mirror)
+		mirrored = append(mirrored, ClusterArtifact{Type: ClusterArtifactTypeFile, Component: component, Reference: source, Mirror: *url})
+		// the arm64 pools download the binaries next to the amd64 ones
+		if cs.Properties.HasArm64() && archBinaries {
+			mirrored = append(mirrored, ClusterArtifact{Type: ClusterArtifactTypeFile, Component: component + "-arm64",
+				Reference: GetArchBinaryURL(source, ArchitectureArm64), Mirror: GetArchBinaryURL(*url, ArchitectureArm64)})
+		}
+	}
+
+	// older versions run the hyperkube image and cannot override the node binaries URL
+	if common.IsKubernetesVersionGe(o.OrchestratorVersion, "1.17.0") {
+		set("kubernetes-node-linux", &k.CustomKubeBinaryURL, cs.getKubeBinaryURL(), true)
+	}
+	set("cni-plugins", &k.CNIPluginsURL, k.GetCNIPluginsURL(cloudSpecConfig), true)
+	if o.IsAzureCNI() {
+		set("azure-cni-linux", &k.AzureCNIURLLinux, k.GetAzureCNIURLLinux(cloudSpecConfig), true)
+	}
+	set("moby", &k.LinuxMobyURL, k.LinuxMobyURL, false)
+	set("runc", &k.LinuxRuncURL, k.LinuxRuncURL, false)
+	set("containerd", &k.LinuxContainerdURL, k.LinuxContainerdURL, false)
+
+	if cs.Properties.HasWindows() {
+		w := cs.Properties.WindowsProfile
+		set("kubernetes-node-windows", &k.CustomWindowsPackageURL, cs.getWindowsKubeBinariesURL(), false)
+		set("kubernetes-node-binaries-windows", &k.WindowsNodeBinariesURL, k.WindowsNodeBinariesURL, false)
+		set("windows-provisioning-scripts", &w.ProvisioningScriptsPackageURL, w.ProvisioningScriptsPackageURL, false)
+		if o.IsAzureCNI() {
+			set("azure-cni-windows", &k.AzureCNIURLWindows, k.GetAzureCNIURLWindows(cloudSpecConfig), false)
+		}
+		if w.IsCSIProxyEnabled() {
+			set("csi-proxy", &w.CSIProxyURL, w.CSIProxyURL, false)
+		}
+		if k.NeedsContainerd() {
+			set("containerd-windows", &k.WindowsContainerdURL, k.WindowsContainerdURL, false)
+			set("sdn-plugin-windows", &k.WindowsSdnPluginURL, k.WindowsSdnPluginURL, false)
+		}
+	}
+
+	sort.SliceStable(mirrored, func(i, j int) bool {
+		return mirrored[i].Reference < mirrored[j].Reference
+	})
+	return mirrored
+}
+
+// SetMirroredArtifacts sets in cs, an API model as provided by the user, the container images and files of mirrored
+// that the defaults of cs do not already point at their mirror, e.g. the images the defaults do not derive from the
+// image bases. defaulted is a copy of cs with its defaults set and mirrored is the same API model with its defaults set,
+// pointed at the mirrors by SetContainerImageRegistry and SetFileMirror.
+func (cs *ContainerService) SetMirroredArtifacts(defaulted, mirrored *ContainerService) {
+	if cs.Properties == nil || cs.Properties.OrchestratorProfile == nil ||
+		defaulted.Properties == nil || defaulted.Properties.OrchestratorProfile == nil || defaulted.Properties.OrchestratorProfile.KubernetesConfig == nil ||
+		mirrored.Properties == nil || mirrored.Properties.OrchestratorProfile == nil || mirrored.Properties.OrchestratorProfile.KubernetesConfig == nil {
+		return
+	}
+	if cs.Properties.OrchestratorProfile.KubernetesConfig == nil {
+		cs.Properties.OrchestratorProfile.KubernetesConfig = &KubernetesConfig{}
+	}
+	k := cs.Properties.OrchestratorProfile.KubernetesConfig
+	dk := defaulted.Properties.OrchestratorProfile.KubernetesConfig
+	mk := mirrored.Properties.OrchestratorProfile.KubernetesConfig
+	pin := func(value *string, defaultedValue, mirroredValue string) {
+		if mirroredValue != defaultedValue {
+			*value = mirroredValue
+		}
+	}
+
+	for _, component := range mk.Components {
+		if !component.IsEnabled() {
+			continue
+		}
+		defaultedComponent := dk.GetComponentByName(component.Name)
+		for _, container := range component.Containers {
+			if container.Image != getContainerImage(defaultedComponent.Containers, container.Name) {
+				i := 0
+				for i < len(k.Components) && k.Components[i].Name != component.Name {
+					i++
+				}
+				if i == len(k.Components) {
+					k.Components = append(k.Components, KubernetesComponent{Name: component.Name})
+				}
+				k.Components[i].Containers = setContainerImage(k.Components[i].Containers, container.Name, container.Image)
+			}
+		}
+	}
+	for _, addon := range mk.Addons {
+		if !addon.IsEnabled() {
+			continue
+		}
+		defaultedAddon := dk.GetAddonByName(addon.Name)
+		for _, container := range addon.Containers {
+			if container.Image != getContainerImage(defaultedAddon.Containers, container.Name) {
+				i := 0
+				for i < len(k.Addons) && k.Addons[i].Name != addon.Name {
+					i++
+				}
+				if i == len(k.Addons) {
+					k.Addons = append(k.Addons, KubernetesAddon{Name: addon.Name})
+				}
+				k.Addons[i].Containers = setContainerImage(k.Addons[i].Containers, container.Name, container.Image)
+			}
+		}
+	}
+
+	if image := mk.KubeletConfig[kubeletPauseImageKey]; image != "" && image != dk.KubeletConfig[kubeletPauseImageKey] {
+		if k.KubeletConfig == nil {
+			k.KubeletConfig = map[string]string{}
+		}
+		k.KubeletConfig[kubeletPauseImageKey] = image
+	}
+	for _, field := range [][3]*string{
+		{&k.CustomHyperkubeImage, &dk.CustomHyperkubeImage, &mk.CustomHyperkubeImage},
+		{&k.CustomKubeAPIServerImage, &dk.CustomKubeAPIServerImage, &mk.CustomKubeAPIServerImage},
+		{&k.CustomKubeControllerManagerImage, &dk.CustomKubeControllerManagerImage, &mk.CustomKubeControllerManagerImage},
+		{&k.CustomKubeProxyImage, &dk.CustomKubeProxyImage, &mk.CustomKubeProxyImage},
+		{&k.CustomKubeSchedulerImage, &dk.CustomKubeSchedulerImage, &mk.CustomKubeSchedulerImage},
+		{&k.CustomCcmImage, &dk.CustomCcmImage, &mk.CustomCcmImage},
+		{&k.EtcdDownloadURLBase, &dk.EtcdDownloadURLBase, &mk.EtcdDownloadURLBase},
+		{&k.CustomKubeBinaryURL, &dk.CustomKubeBinaryURL, &mk.CustomKubeBinaryURL},
+		{&k.CNIPluginsURL, &dk.CNIPluginsURL, &mk.CNIPluginsURL},
+		{&k.AzureCNIURLLinux, &dk.AzureCNIURLLinux, &mk.AzureCNIURLLinux},
+		{&k.AzureCNIURLWindows, &dk.AzureCNIURLWindows, &mk.AzureCNIURLWindows},
+		{&k.LinuxMobyURL, &dk.LinuxMobyURL, &mk.LinuxMobyURL},
+		{&k.LinuxRuncURL, &dk.LinuxRuncURL, &mk.LinuxRuncURL},
+		{&k.LinuxContainerdURL, &dk.LinuxContainerdURL, &mk.LinuxContainerdURL},
+		{&k.CustomWindowsPackageURL, &dk.CustomWindowsPackageURL, &mk.CustomWindowsPackageURL},
+		{&k.WindowsNodeBinariesURL, &dk.WindowsNodeBinariesURL, &mk.WindowsNodeBinariesURL},
+		{&k.WindowsContainerdURL, &dk.WindowsContainerdURL, &mk.WindowsContainerdURL},
+		{&k.WindowsSdnPluginURL, &dk.WindowsSdnPluginURL, &mk.WindowsSdnPluginURL},
+	} {
+		pin(field[0], *field[1], *field[2])
+	}
+
+	if w, dw, mw := cs.Properties.WindowsProfile, defaulted.Properties.WindowsProfile, mirrored.Properties.WindowsProfile; w != nil && dw != nil && mw != nil {
+		pin(&w.WindowsPauseImageURL, dw.WindowsPauseImageURL, mw.WindowsPauseImageURL)
+		pin(&w.ProvisioningScriptsPackageURL, dw.ProvisioningScriptsPackageURL, mw.ProvisioningScriptsPackageURL)
+		pin(&w.CSIProxyURL, dw.CSIProxyURL, mw.CSIProxyURL)
+	}
+}
+
+// GetMirroredFileURL returns the URL of the file u under mirror, keeping the path of u
+func GetMirroredFileURL(u, mirror string) string {
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+	}
+	path := ""
+	if i := strings.Index(u, "/"); i >= 0 {
+		path = u[i:]
+	}
+	// a SAS token of the source does not grant access to the mirror
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	return strings.TrimSuffix(mirror, "/") + path
+}
+
+// GetMirroredImage returns the reference of image in registry, replacing the registry host of image if it has one
+func GetMirroredImage(image, registry string) string {
+	if i := strings.Index(image, "/"); i > 0 {
+		host := image[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			image = image[i+1:]
+		}
+	}
+	return strings.TrimSuffix(registry, "/") + "/" + image
+}
+
+func getContainerArtifactComponent(name, containerName string) string {
+	if containerName == "" || containerName == name {
+		return name
+	}
+	return name + "/" + containerName
+}
+
+func getContainerImage(containers []KubernetesContainerSpec, name string) string {
+	for _, container := range containers {
+		if container.Name == name {
+			return container.Image
+		}
+	}
+	return ""
+}
+
+func setContainerImage(containers []KubernetesContainerSpec, name, image string) []KubernetesContainerSpec {
+	for i := range containers {
+		if containers[i].Name == name {
+			containers[i].Image = image
+			return containers
+		}
+	}
+	return append(containers, KubernetesContainerSpec{Name: name, Image: image})
+}
+
+// getKubeBinaryURL returns the URL of the Linux node binaries
+func (cs *ContainerService) getKubeBinaryURL() string {
+	o := cs.Properties.OrchestratorProfile
+	if o.KubernetesConfig.CustomKubeBinaryURL != "" {
+		return o.KubernetesConfig.CustomKubeBinaryURL
+	}
+	return fmt.Sprintf(defaultKubeBinaryURLFormat, o.OrchestratorVersion)
+}
+
+// getWindowsKubeBinariesURL returns the URL of the Windows node binaries package
+func (cs *ContainerService) getWindowsKubeBinariesURL() string {
+	o := cs.Properties.OrchestratorProfile
+	k := o.KubernetesConfig
+	if k.CustomWindowsPackageURL != "" {
+		return k.CustomWindowsPackageURL
+	}
+	k8sComponents := GetK8sComponentsByVersionMap(k)[o.OrchestratorVersion]
+	kubeBinariesSASURLBase := cs.GetCloudSpecConfig().KubernetesSpecConfig.KubeBinariesSASURLBase
+	if cs.Properties.IsAzureStackCloud() && !common.IsKubernetesVersionGe(o.OrchestratorVersion, "1.21.0") {
+		return kubeBinariesSASURLBase + k8sComponents[common.WindowsArtifactAzureStackComponentName]
+	}
+	return kubeBinariesSASURLBase + k8sComponents[common.WindowsArtifactComponentName]
+}
@@ -0,0 +1,309 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/api/common"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func getMockArtifactsContainerService(t *testing.T, windows bool) *ContainerService {
+	cs := CreateMockContainerService("testcluster", "1.23.17", 1, 1, false)
+	cs.Location = "westus2"
+	cs.Properties.OrchestratorProfile.KubernetesConfig.NetworkPlugin = NetworkPluginAzure
+	cs.Properties.OrchestratorProfile.KubernetesConfig.KubernetesImageBaseType = common.KubernetesImageBaseTypeMCR
+	if windows {
+		cs.Properties.AgentPoolProfiles[0].OSType = Windows
+		cs.Properties.WindowsProfile = &WindowsProfile{AdminUsername: "azureuser", AdminPassword: "password"}
+	}
+	if _, err := cs.SetPropertiesDefaults(PropertiesDefaultsParams{IsScale: false, IsUpgrade: false, PkiKeySize: 2048}); err != nil {
+		t.Fatalf("unexpected error setting defaults: %s", err)
+	}
+	return cs
+}
+
+func findClusterArtifact(artifacts []ClusterArtifact, component string) *ClusterArtifact {
+	for i := range artifacts {
+		if artifacts[i].Component == component {
+			return &artifacts[i]
+		}
+	}
+	return nil
+}
+
+func TestGetClusterArtifacts(t *testing.T) {
+	cs := getMockArtifactsContainerService(t, false)
+	artifacts := cs.GetClusterArtifacts()
+
+	for i := 1; i < len(artifacts); i++ {
+		if artifacts[i-1].Type > artifacts[i].Type {
+			t.Fatalf("expected files to be listed before images, got %v", artifacts)
+		}
+	}
+	for _, c := range []struct {
+		component, artifactType, reference string
+	}{
+		{"kube-apiserver", ClusterArtifactTypeImage, "mcr.microsoft.com/oss/kubernetes/kube-apiserver:v1.23.17"},
+		{"pause", ClusterArtifactTypeImage, cs.Properties.OrchestratorProfile.KubernetesConfig.KubeletConfig["--pod-infra-container-image"]},
+		{"etcd", ClusterArtifactTypeImage, "mcr.microsoft.com/oss/etcd-io/etcd:v" + cs.Properties.OrchestratorProfile.KubernetesConfig.EtcdVersion},
+		{"kubernetes-node-linux", ClusterArtifactTypeFile, "https://kubernetesartifacts.azureedge.net/kubernetes/v1.23.17/binaries/kubernetes-node-linux-amd64.tar.gz"},
+		{"azure-cni-linux", ClusterArtifactTypeFile, cs.GetCloudSpecConfig().KubernetesSpecConfig.VnetCNILinuxPluginsDownloadURL},
+	} {
+		a := findClusterArtifact(artifacts, c.component)
+		if a == nil {
+			t.Errorf("expected a %s artifact in %v", c.component, artifacts)
+			continue
+		}
+		if a.Type != c.artifactType || a.Reference != c.reference {
+			t.Errorf("expected %s artifact %s, got %s %s", c.artifactType, c.reference, a.Type, a.Reference)
+		}
+	}
+	if a := findClusterArtifact(artifacts, "windows-pause"); a != nil {
+		t.Errorf("expected no Windows artifacts, got %v", a)
+	}
+
+	cs.Properties.OrchestratorProfile.KubernetesConfig.CustomKubeBinaryURL = "https://example.com/kubernetes-node.tar.gz"
+	for i := range cs.Properties.OrchestratorProfile.KubernetesConfig.Addons {
+		addon := &cs.Properties.OrchestratorProfile.KubernetesConfig.Addons[i]
+		if addon.Name == common.MetricsServerAddonName {
+			addon.Enabled = to.BoolPtr(false)
+		}
+	}
+	artifacts = cs.GetClusterArtifacts()
+	if a := findClusterArtifact(artifacts, "kubernetes-node-linux"); a == nil || a.Reference != "https://example.com/kubernetes-node.tar.gz" {
+		t.Errorf("expected the custom kube binary URL, got %v", a)
+	}
+	if a := findClusterArtifact(artifacts, common.MetricsServerAddonName); a != nil {
+		t.Errorf("expected no artifact for a disabled addon, got %v", a)
+	}
+}
+
+func TestGetClusterArtifactsWindows(t *testing.T) {
+	cs := getMockArtifactsContainerService(t, true)
+	artifacts := cs.GetClusterArtifacts()
+	for _, component := range []string{"windows-pause", "kubernetes-node-windows", "windows-provisioning-scripts", "azure-cni-windows"} {
+		if findClusterArtifact(artifacts, component) == nil {
+			t.Errorf("expected a %s artifact in %v", component, artifacts)
+		}
+	}
+}
+
+func TestGetClusterArtifactsArm64(t *testing.T) {
+	cs := getMockArtifactsContainerService(t, false)
+	if a := findClusterArtifact(cs.GetClusterArtifacts(), "kubernetes-node-linux-arm64"); a != nil {
+		t.Errorf("expected no arm64 artifacts, got %v", a)
+	}
+
+	cs.Properties.AgentPoolProfiles[0].Architecture = ArchitectureArm64
+	artifacts := cs.GetClusterArtifacts()
+	for component, reference := range map[string]string{
+		"kubernetes-node-linux-arm64": "https://kubernetesartifacts.azureedge.net/kubernetes/v1.23.17/binaries/kubernetes-node-linux-arm64.tar.gz",
+		"cni-plugins-arm64":           "https://kubernetesartifacts.azureedge.net/cni-plugins/" + CNIPluginVer + "/binaries/cni-plugins-linux-arm64-" + CNIPluginVer + ".tgz",
+		"azure-cni-linux-arm64":       "https://kubernetesartifacts.azureedge.net/azure-cni/" + AzureCniPluginVerLinux + "/binaries/azure-vnet-cni-linux-arm64-" + AzureCniPluginVerLinux + ".tgz",
+	} {
+		a := findClusterArtifact(artifacts, component)
+		if a == nil || a.Reference != reference {
+			t.Errorf("expected %s artifact %s, got %v", component, reference, a)
+		}
+	}
+}
+
+func TestSetContainerImageRegistry(t *testing.T) {
+	cs := getMockArtifactsContainerService(t, true)
+	k := cs.Properties.OrchestratorProfile.KubernetesConfig
+	k.CustomKubeProxyImage = "example.com/kube-proxy:v1.23.17-custom"
+
+	mirrored := cs.SetContainerImageRegistry("myregistry.azurecr.io/")
+	if len(mirrored) == 0 {
+		t.Fatalf("expected mirrored images")
+	}
+	for _, m := range mirrored {
+		if !strings.HasPrefix(m.Mirror, "myregistry.azurecr.io/") {
+			t.Errorf("expected %s to be mirrored to myregistry.azurecr.io, got %s", m.Reference, m.Mirror)
+		}
+	}
+	if k.KubeletConfig["--pod-infra-container-image"] != GetMirroredImage(mirroredSource(mirrored, "pause"), "myregistry.azurecr.io") {
+		t.Errorf("unexpected pause image %s", k.KubeletConfig["--pod-infra-container-image"])
+	}
+	if pool := cs.Properties.AgentPoolProfiles[0]; pool.KubernetesConfig.KubeletConfig["--pod-infra-container-image"] != "kubletwin/pause" {
+		t.Errorf("expected the Windows kubelet pause image to be left alone, got %s", pool.KubernetesConfig.KubeletConfig["--pod-infra-container-image"])
+	}
+	if k.CustomKubeProxyImage != "myregistry.azurecr.io/kube-proxy:v1.23.17-custom" {
+		t.Errorf("unexpected custom kube-proxy image %s", k.CustomKubeProxyImage)
+	}
+	if !strings.HasPrefix(cs.Properties.WindowsProfile.WindowsPauseImageURL, "myregistry.azurecr.io/") {
+		t.Errorf("unexpected Windows pause image %s", cs.Properties.WindowsProfile.WindowsPauseImageURL)
+	}
+	if k.MCRKubernetesImageBase != "myregistry.azurecr.io/" || k.KubernetesImageBase != "myregistry.azurecr.io/" {
+		t.Errorf("unexpected image bases %s %s", k.MCRKubernetesImageBase, k.KubernetesImageBase)
+	}
+	if k.EtcdDownloadURLBase != "myregistry.azurecr.io/oss/etcd-io/" {
+		t.Errorf("unexpected etcd download URL base %s", k.EtcdDownloadURLBase)
+	}
+	if k.PrivateAzureRegistryServer != "myregistry.azurecr.io" {
+		t.Errorf("expected myregistry.azurecr.io to be the private Azure registry server, got %s", k.PrivateAzureRegistryServer)
+	}
+	for _, a := range cs.GetClusterArtifacts() {
+		if a.Type == ClusterArtifactTypeImage && !strings.HasPrefix(a.Reference, "myregistry.azurecr.io/") {
+			t.Errorf("expected image %s to be in myregistry.azurecr.io", a.Reference)
+		}
+	}
+
+	cs = getMockArtifactsContainerService(t, false)
+	cs.SetContainerImageRegistry("mirror.example.com:5000")
+	if server := cs.Properties.OrchestratorProfile.KubernetesConfig.PrivateAzureRegistryServer; server != "" {
+		t.Errorf("expected no private Azure registry server for a registry outside Azure, got %s", server)
+	}
+}
+
+func mirroredSource(mirrored []ClusterArtifact, component string) string {
+	for _, m := range mirrored {
+		if m.Component == component {
+			return m.Reference
+		}
+	}
+	return ""
+}
+
+func TestGetMirroredImage(t *testing.T) {
+	cases := []struct {
+		image, registry, expected string
+	}{
+		{"mcr.microsoft.com/oss/kubernetes/pause:3.4.1", "myregistry.azurecr.io", "myregistry.azurecr.io/oss/kubernetes/pause:3.4.1"},
+		{"localhost:5000/pause:3.4.1", "mirror.example.com/aks/", "mirror.example.com/aks/pause:3.4.1"},
+		{"localhost/pause:3.4.1", "mirror.example.com", "mirror.example.com/pause:3.4.1"},
+		{"library/busybox:1.33", "mirror.example.com", "mirror.example.com/library/busybox:1.33"},
+		{"busybox", "mirror.example.com", "mirror.example.com/busybox"},
+	}
+	for _, c := range cases {
+		if actual := GetMirroredImage(c.image, c.registry); actual != c.expected {
+			t.Errorf("GetMirroredImage(%s, %s): expected %s, got %s", c.image, c.registry, c.expected, actual)
+		}
+	}
+}
+
+func TestSetFileMirror(t *testing.T) {
+	cs := getMockArtifactsContainerService(t, true)
+	cs.Properties.AgentPoolProfiles = append(cs.Properties.AgentPoolProfiles, &AgentPoolProfile{Name: "arm", Count: 1, Architecture: ArchitectureArm64})
+	k := cs.Properties.OrchestratorProfile.KubernetesConfig
+
+	mirrored := cs.SetFileMirror("https://mirror.example.com/aks/")
+	if len(mirrored) == 0 {
+		t.Fatalf("expected mirrored files")
+	}
+	mirrors := map[string]string{}
+	for _, m := range mirrored {
+		mirrors[m.Reference] = m.Mirror
+	}
+	for _, a := range cs.GetClusterArtifacts() {
+		if a.Type == ClusterArtifactTypeFile && !strings.HasPrefix(a.Reference, "https://mirror.example.com/aks/") {
+			t.Errorf("expected file %s to be in https://mirror.example.com/aks", a.Reference)
+		}
+		if a.Type == ClusterArtifactTypeFile && !hasMirror(mirrors, a.Reference) {
+			t.Errorf("expected file %s to be listed as a mirror", a.Reference)
+		}
+	}
+	if k.CustomKubeBinaryURL != "https://mirror.example.com/aks/kubernetes/v1.23.17/binaries/kubernetes-node-linux-amd64.tar.gz" {
+		t.Errorf("unexpected kube binary URL %s", k.CustomKubeBinaryURL)
+	}
+	if k.CNIPluginsURL == "" || k.AzureCNIURLLinux == "" || k.AzureCNIURLWindows == "" || k.CustomWindowsPackageURL == "" {
+		t.Errorf("expected the CNI and Windows package URLs to be set, got %s %s %s %s", k.CNIPluginsURL, k.AzureCNIURLLinux, k.AzureCNIURLWindows, k.CustomWindowsPackageURL)
+	}
+
+	cs = CreateMockContainerService("testcluster", "1.16.15", 1, 1, false)
+	if _, err := cs.SetPropertiesDefaults(PropertiesDefaultsParams{IsScale: false, IsUpgrade: false, PkiKeySize: 2048}); err != nil {
+		t.Fatalf("unexpected error setting defaults: %s", err)
+	}
+	cs.SetFileMirror("https://mirror.example.com")
+	if url := cs.Properties.OrchestratorProfile.KubernetesConfig.CustomKubeBinaryURL; url != "" {
+		t.Errorf("expected no kube binary URL for a hyperkube cluster, got %s", url)
+	}
+}
+
+func hasMirror(mirrors map[string]string, mirror string) bool {
+	for _, m := range mirrors {
+		if m == mirror {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSetMirroredArtifacts(t *testing.T) {
+	newUserContainerService := func() *ContainerService {
+		cs := CreateMockContainerService("testcluster", "1.23.17", 1, 1, false)
+		cs.Location = "westus2"
+		cs.Properties.AgentPoolProfiles[0].OSType = Windows
+		cs.Properties.WindowsProfile = &WindowsProfile{AdminUsername: "azureuser", AdminPassword: "password"}
+		cs.Properties.OrchestratorProfile.KubernetesConfig = &KubernetesConfig{
+			KubernetesImageBaseType: common.KubernetesImageBaseTypeMCR,
+			Addons: []KubernetesAddon{
+				{Name: common.CoreDNSAddonName, Containers: []KubernetesContainerSpec{{Name: common.CoreDNSAddonName, CPURequests: "200m"}}},
+			},
+		}
+		return cs
+	}
+	setDefaults := func(cs *ContainerService) {
+		if _, err := cs.SetPropertiesDefaults(PropertiesDefaultsParams{IsScale: false, IsUpgrade: false, PkiKeySize: 2048}); err != nil {
+			t.Fatalf("unexpected error setting defaults: %s", err)
+		}
+	}
+
+	mirrored := newUserContainerService()
+	setDefaults(mirrored)
+	mirrored.SetContainerImageRegistry("myregistry.azurecr.io")
+	mirrored.SetFileMirror("https://mirror.example.com")
+
+	cs := newUserContainerService()
+	cs.SetContainerImageRegistry("myregistry.azurecr.io")
+	defaulted := newUserContainerService()
+	defaulted.SetContainerImageRegistry("myregistry.azurecr.io")
+	setDefaults(defaulted)
+	cs.SetMirroredArtifacts(defaulted, mirrored)
+
+	k := cs.Properties.OrchestratorProfile.KubernetesConfig
+	if k.KubeletConfig != nil {
+		t.Errorf("expected the pause image to be derived from the image base, got %v", k.KubeletConfig)
+	}
+	coreDNS := k.GetAddonByName(common.CoreDNSAddonName)
+	if coreDNS.Containers[0].Image != "" || coreDNS.Containers[0].CPURequests != "200m" {
+		t.Errorf("expected the coredns image to be derived from the image base, got %v", coreDNS.Containers[0])
+	}
+	if image := getContainerImage(coreDNS.Containers, common.CoreDNSAutoscalerName); !strings.HasPrefix(image, "myregistry.azurecr.io/") {
+		t.Errorf("expected the hardcoded coredns autoscaler image to be pinned to the registry, got %s", image)
+	}
+	if !strings.HasPrefix(cs.Properties.WindowsProfile.WindowsPauseImageURL, "myregistry.azurecr.io/") {
+		t.Errorf("expected the Windows pause image to be pinned to the registry, got %s", cs.Properties.WindowsProfile.WindowsPauseImageURL)
+	}
+	if !strings.HasPrefix(k.CNIPluginsURL, "https://mirror.example.com/") || !strings.HasPrefix(cs.Properties.WindowsProfile.ProvisioningScriptsPackageURL, "https://mirror.example.com/") {
+		t.Errorf("expected the files to be pinned to the mirror, got %s %s", k.CNIPluginsURL, cs.Properties.WindowsProfile.ProvisioningScriptsPackageURL)
+	}
+	if len(cs.Properties.OrchestratorProfile.KubernetesConfig.Components) != 0 {
+		t.Errorf("expected no components, got %v", k.Components)
+	}
+
+	setDefaults(cs)
+	for _, a := range cs.GetClusterArtifacts() {
+		if !strings.HasPrefix(a.Reference, "myregistry.azurecr.io/") && !strings.HasPrefix(a.Reference, "https://mirror.example.com/") {
+			t.Errorf("expected %s %s to be mirrored", a.Type, a.Reference)
+		}
+	}
+}
+
+func TestGetMirroredFileURL(t *testing.T) {
+	cases := []struct {
+		url, mirror, expected string
+	}{
+		{"https://kubernetesartifacts.azureedge.net/cni-plugins/v0.9.1/binaries/cni-plugins-linux-amd64-v0.9.1.tgz", "https://mirror.example.com/", "https://mirror.example.com/cni-plugins/v0.9.1/binaries/cni-plugins-linux-amd64-v0.9.1.tgz"},
+		{"https://acs-mirror.azureedge.net/kubernetes/v1.23.17/windowszip/v1.23.17-1int.zip?sv=2019&sig=abc", "https://mirror.example.com/aks", "https://mirror.example.com/aks/kubernetes/v1.23.17/windowszip/v1.23.17-1int.zip"},
+	}
+	for _, c := range cases {
+		if actual := GetMirroredFileURL(c.url, c.mirror); actual != c.expected {
+			t.Errorf("GetMirroredFileURL(%s, %s): expected %s, got %s", c.url, c.mirror, c.expected, actual)
+		}
+	}
+}
@@ -35,6 +35,28 @@ const (
 	DefaultEnableCSIProxyWindows = false
 	// MaxLoadBalancerOutboundIPs is the maximum number of outbound IPs in a Standard LoadBalancer frontend configuration
 	MaxLoadBalancerOutboundIPs = 16
+	// MinNetworkSecurityRulePriority is the lowest priority value accepted by Azure for a security rule
+	MinNetworkSecurityRulePriority = 100
+	// MaxNetworkSecurityRulePriority is the highest priority value accepted by Azure for a security rule
+	MaxNetworkSecurityRulePriority = 4096
+	// MinNATGatewayIdleTimeoutInMinutes is the lowest TCP idle timeout accepted by Azure for a NAT gateway
+	MinNATGatewayIdleTimeoutInMinutes = 4
+	// MaxNATGatewayIdleTimeoutInMinutes is the highest TCP idle timeout accepted by Azure for a NAT gateway
+	MaxNATGatewayIdleTimeoutInMinutes = 120
+	// NATGatewayPublicIPPrefixLength is the length of the public IP prefixes created for a NAT gateway, each one holds 2 addresses
+	NATGatewayPublicIPPrefixLength = 31
+	// MaxNATGatewayPublicIPPrefixCount is the number of /31 public IP prefixes that fill the 16 addresses a NAT gateway supports
+	MaxNATGatewayPublicIPPrefixCount = 8
+)
+
+// Names of the security rules aks-engine adds to the cluster network security group,
+// a custom network security rule with one of these names replaces the default rule
+const (
+	NetworkSecurityRuleAllowKubeTLS  = "allow_kube_tls"
+	NetworkSecurityRuleAllowSSH      = "allow_ssh"
+	NetworkSecurityRuleAllowRDP      = "allow_rdp"
+	NetworkSecurityRuleAllowVNET     = "allow_vnet"
+	NetworkSecurityRuleBlockOutbound = "block_outbound"
 )
 
 // Availability profiles
@@ -231,6 +253,10 @@ const (
 	CSISecretsStoreProviderAzureContainerName = "provider-azure-installer"
 	// ArcAddonName is the name of the arc addon
 	AzureArcOnboardingAddonName = "azure-arc-onboarding"
+	// GMSAWebhookAddonName is the name of the gMSA admission webhook addon, that validates and populates the gMSA credential specs of Windows pods
+	GMSAWebhookAddonName = "gmsa-webhook"
+	// HypervRuntimeClassAddonName is the name of the addon that creates the RuntimeClass objects of the Hyper-V runtimes of Windows nodes
+	HypervRuntimeClassAddonName = "hyperv-runtimeclass"
 )
 
 // Component name consts
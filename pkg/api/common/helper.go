@@ -333,6 +333,53 @@ func GetOrderedEscapedKeyValsString(config map[string]string) string {
 	return strings.TrimSuffix(buf.String(), ", ")
 }
 
+// builtinAddonNames are the names of the addons whose manifests are part of aks-engine
+var builtinAddonNames = []string{
+	MetricsServerAddonName,
+	TillerAddonName,
+	AADPodIdentityAddonName,
+	AzureDiskCSIDriverAddonName,
+	AzureFileCSIDriverAddonName,
+	ClusterAutoscalerAddonName,
+	BlobfuseFlexVolumeAddonName,
+	SMBFlexVolumeAddonName,
+	KeyVaultFlexVolumeAddonName,
+	DashboardAddonName,
+	NVIDIADevicePluginAddonName,
+	ContainerMonitoringAddonName,
+	IPMASQAgentAddonName,
+	CalicoAddonName,
+	AzureNetworkPolicyAddonName,
+	AzurePolicyAddonName,
+	CloudNodeManagerAddonName,
+	NodeProblemDetectorAddonName,
+	KubeDNSAddonName,
+	CoreDNSAddonName,
+	KubeProxyAddonName,
+	PodSecurityPolicyAddonName,
+	AADAdminGroupAddonName,
+	CiliumAddonName,
+	AntreaAddonName,
+	AuditPolicyAddonName,
+	AzureCloudProviderAddonName,
+	FlannelAddonName,
+	ScheduledMaintenanceAddonName,
+	SecretsStoreCSIDriverAddonName,
+	AzureArcOnboardingAddonName,
+	GMSAWebhookAddonName,
+	HypervRuntimeClassAddonName,
+}
+
+// IsBuiltinAddonName returns true if name is the name of an addon whose manifest is part of aks-engine
+func IsBuiltinAddonName(name string) bool {
+	for _, builtin := range builtinAddonNames {
+		if name == builtin {
+			return true
+		}
+	}
+	return false
+}
+
 // GetOrderedNewlinedKeyValsStringForCloudInit returns an ordered string of key = val, separated by newlines
 func GetOrderedNewlinedKeyValsStringForCloudInit(config map[string]string) string {
 	keys := []string{}
@@ -420,6 +467,21 @@ func ContainerdSandboxImageOverrider(image string) func(*ContainerdConfig) error
 	}
 }
 
+// ContainerdSystemdCgroupOverride transforms a containerd config to let systemd manage the cgroups of the runc containers,
+// required on hosts that boot with the unified cgroup v2 hierarchy.
+func ContainerdSystemdCgroupOverride(config *ContainerdConfig) error {
+	// the runtimes map is shared with DefaultContainerdConfig, so build a new one
+	runtimes := make(map[string]ContainerdRuntime, len(config.Plugins.IoContainerdGrpcV1Cri.Containerd.Runtimes))
+	for name, runtime := range config.Plugins.IoContainerdGrpcV1Cri.Containerd.Runtimes {
+		if runtime.RuntimeType == "io.containerd.runc.v2" {
+			runtime.Options = &ContainerdRuntimeOptions{SystemdCgroup: true}
+		}
+		runtimes[name] = runtime
+	}
+	config.Plugins.IoContainerdGrpcV1Cri.Containerd.Runtimes = runtimes
+	return nil
+}
+
 // DockerNvidiaOverride transforms a docker config to supply nvidia runtime configuration.
 func DockerNvidiaOverride(config *DockerConfig) error {
 	if config.DockerDaemonRuntimes == nil {
@@ -580,6 +642,25 @@ version = 2
           runtime_type = "io.containerd.runc.v2"
 `
 
+var containerdSystemdCgroupConfigString = `oom_score = 0
+version = 2
+
+[plugins]
+  [plugins."io.containerd.grpc.v1.cri"]
+    [plugins."io.containerd.grpc.v1.cri".cni]
+    [plugins."io.containerd.grpc.v1.cri".containerd]
+      default_runtime_name = "runc"
+      [plugins."io.containerd.grpc.v1.cri".containerd.runtimes]
+        [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runc]
+          runtime_type = "io.containerd.runc.v2"
+          [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runc.options]
+            SystemdCgroup = true
+        [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.untrusted]
+          runtime_type = "io.containerd.runc.v2"
+          [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.untrusted.options]
+            SystemdCgroup = true
+`
+
 var defaultDockerConfigString = `{
     "live-restore": true,
     "log-driver": "json-file",
@@ -480,6 +480,14 @@ func TestGetContainerdConfig(t *testing.T) {
 				ContainerdKubenetOverride,
 			},
 		},
+		{
+			name: "container systemd cgroup config",
+			want: containerdSystemdCgroupConfigString,
+			fail: false,
+			overrides: []func(*ContainerdConfig) error{
+				ContainerdSystemdCgroupOverride,
+			},
+		},
 		{
 			name: "container sandbox image config",
 			want: containerdImageConfigString,
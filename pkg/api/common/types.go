@@ -15,7 +15,12 @@ type ContainerdCNIPlugin struct {
 }
 
 type ContainerdRuntime struct {
-	RuntimeType string `toml:"runtime_type,omitempty"`
+	RuntimeType string                    `toml:"runtime_type,omitempty"`
+	Options     *ContainerdRuntimeOptions `toml:"options,omitempty"`
+}
+
+type ContainerdRuntimeOptions struct {
+	SystemdCgroup bool `toml:"SystemdCgroup,omitempty"`
 }
 
 type ContainerdPlugin struct {
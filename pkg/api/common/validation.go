@@ -0,0 +1,146 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ValidationSeverity describes how serious a ValidationError is
+type ValidationSeverity string
+
+const (
+	// ValidationSeverityError marks a problem that prevents the api model from being used
+	ValidationSeverityError ValidationSeverity = "error"
+	// ValidationSeverityWarning marks a problem that is reported but does not block the operation
+	ValidationSeverityWarning ValidationSeverity = "warning"
+	// ValidationSeverityDeprecation marks the use of a deprecated api model property
+	ValidationSeverityDeprecation ValidationSeverity = "deprecation"
+)
+
+// Translator is the subset of i18n.Translator used to localize validation messages
+type Translator interface {
+	T(msgid string, vars ...interface{}) string
+}
+
+// ValidationError is a single api model validation problem
+type ValidationError struct {
+	// Field is the JSON path of the api model property that failed validation, e.g. "properties.masterProfile"
+	Field string `json:"field"`
+	// Severity is one of error, warning or deprecation
+	Severity ValidationSeverity `json:"severity"`
+	// MessageKey is the i18n message id, formatted with Args
+	MessageKey string        `json:"messageKey"`
+	Args       []interface{} `json:"args,omitempty"`
+}
+
+// NewValidationError returns a ValidationError of severity error
+func NewValidationError(field, messageKey string, args ...interface{}) *ValidationError {
+	return &ValidationError{
+		Field:      field,
+		Severity:   ValidationSeverityError,
+		MessageKey: messageKey,
+		Args:       args,
+	}
+}
+
+// Message returns the untranslated validation message
+func (e *ValidationError) Message() string {
+	if len(e.Args) == 0 {
+		return e.MessageKey
+	}
+	return fmt.Sprintf(e.MessageKey, e.Args...)
+}
+
+// Translate returns the validation message localized with t
+func (e *ValidationError) Translate(t Translator) string {
+	if t == nil {
+		return e.Message()
+	}
+	return t.T(e.MessageKey, e.Args...)
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return e.Message()
+}
+
+// ValidationErrors is the list of all problems found while validating an api model
+type ValidationErrors []*ValidationError
+
+// Add appends err to the list under field. Errors that are already ValidationErrors keep
+// their own field paths and severities, any other non-nil error is recorded with severity error.
+func (v *ValidationErrors) Add(field string, err error) {
+	if err == nil {
+		return
+	}
+	switch e := errors.Cause(err).(type) {
+	case ValidationErrors:
+		*v = append(*v, e...)
+	case *ValidationError:
+		*v = append(*v, e)
+	default:
+		*v = append(*v, &ValidationError{
+			Field:      field,
+			Severity:   ValidationSeverityError,
+			MessageKey: err.Error(),
+		})
+	}
+}
+
+// AddWarning appends a warning or deprecation notice for field
+func (v *ValidationErrors) AddWarning(field string, severity ValidationSeverity, messageKey string, args ...interface{}) {
+	*v = append(*v, &ValidationError{
+		Field:      field,
+		Severity:   severity,
+		MessageKey: messageKey,
+		Args:       args,
+	})
+}
+
+// Errors returns only the entries with severity error
+func (v ValidationErrors) Errors() ValidationErrors {
+	var ret ValidationErrors
+	for _, e := range v {
+		if e.Severity == ValidationSeverityError {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+// Warnings returns the entries with severity warning or deprecation
+func (v ValidationErrors) Warnings() ValidationErrors {
+	var ret ValidationErrors
+	for _, e := range v {
+		if e.Severity != ValidationSeverityError {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+// ErrorOrNil returns nil if the list has no entries of severity error, the list itself otherwise
+func (v ValidationErrors) ErrorOrNil() error {
+	if len(v.Errors()) == 0 {
+		return nil
+	}
+	return v
+}
+
+// Error implements the error interface, a single error keeps its original message
+func (v ValidationErrors) Error() string {
+	errs := v.Errors()
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	msgs := make([]string, 0, len(errs))
+	for _, e := range errs {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", e.Field, e.Message()))
+	}
+	return fmt.Sprintf("%d validation errors: %s", len(errs), strings.Join(msgs, "; "))
+}
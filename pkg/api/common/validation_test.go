@@ -0,0 +1,54 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type fakeTranslator struct{}
+
+func (fakeTranslator) T(msgid string, vars ...interface{}) string {
+	return "translated: " + msgid
+}
+
+func TestValidationErrors(t *testing.T) {
+	errs := ValidationErrors{}
+	errs.Add("properties.masterProfile", nil)
+	if errs.ErrorOrNil() != nil {
+		t.Fatalf("expected no error for an empty list, got %v", errs)
+	}
+
+	errs.AddWarning("properties.orchestratorProfile", ValidationSeverityDeprecation, "%s is deprecated", "dockerEngineVersion")
+	if errs.ErrorOrNil() != nil {
+		t.Fatalf("expected warnings not to be returned as an error, got %v", errs)
+	}
+
+	errs.Add("properties.masterProfile", errors.New("missing dnsPrefix"))
+	err := errs.ErrorOrNil()
+	if err == nil || err.Error() != "missing dnsPrefix" {
+		t.Fatalf("expected a single error to keep its message, got %v", err)
+	}
+
+	nested := ValidationErrors{NewValidationError("properties.agentPoolProfiles[0].name", "invalid pool name %q", "Pool1")}
+	errs.Add("properties.agentPoolProfiles", errors.Wrap(nested, "validating agent pools"))
+	expected := `2 validation errors: properties.masterProfile: missing dnsPrefix; properties.agentPoolProfiles[0].name: invalid pool name "Pool1"`
+	if errs.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, errs.Error())
+	}
+	if len(errs.Errors()) != 2 || len(errs.Warnings()) != 1 {
+		t.Errorf("expected 2 errors and 1 warning, got %d and %d", len(errs.Errors()), len(errs.Warnings()))
+	}
+	if errs.Warnings()[0].Message() != "dockerEngineVersion is deprecated" {
+		t.Errorf("unexpected warning message %q", errs.Warnings()[0].Message())
+	}
+	if got := errs[1].Translate(fakeTranslator{}); got != "translated: missing dnsPrefix" {
+		t.Errorf("unexpected translation %q", got)
+	}
+	if got := errs[1].Translate(nil); got != "missing dnsPrefix" {
+		t.Errorf("unexpected untranslated message %q", got)
+	}
+}
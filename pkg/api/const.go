@@ -22,6 +22,8 @@ const (
 	Ubuntu1804Gen2    Distro = "ubuntu-18.04-gen2"
 	Ubuntu2004        Distro = "ubuntu-20.04"
 	Ubuntu2004Gen2    Distro = "ubuntu-20.04-gen2"
+	Ubuntu2204        Distro = "ubuntu-22.04"
+	Ubuntu2204Gen2    Distro = "ubuntu-22.04-gen2"
 	Flatcar           Distro = "flatcar"
 	AKS1604Deprecated Distro = "aks"               // deprecated AKS 16.04 distro. Equivalent to aks-ubuntu-16.04.
 	AKS1804Deprecated Distro = "aks-1804"          // deprecated AKS 18.04 distro. Equivalent to aks-ubuntu-18.04.
@@ -32,6 +34,18 @@ const (
 	ACC1604           Distro = "acc-16.04"
 )
 
+// the Hyper-V generations of agent pool VMs
+const (
+	HyperVGenerationV1 = "V1"
+	HyperVGenerationV2 = "V2"
+)
+
+// the CPU architectures of agent pools
+const (
+	ArchitectureAmd64 = "amd64"
+	ArchitectureArm64 = "arm64"
+)
+
 const (
 	// KubernetesWindowsDockerVersion is the default version for docker on Windows nodes in kubernetes
 	KubernetesWindowsDockerVersion = "20.10.9"
@@ -39,6 +53,10 @@ const (
 	KubernetesDefaultWindowsSku = "Datacenter-Core-1809-with-Containers-smalldisk"
 	// KubernetesDefaultWindowsRuntimeHandler is the default containerd handler for windows pods
 	KubernetesDefaultWindowsRuntimeHandler = "process"
+	// KubernetesWindowsHypervRuntimeHandlerPrefix is the prefix of the containerd handlers of the Hyper-V runtimes, followed by their Windows build number
+	KubernetesWindowsHypervRuntimeHandlerPrefix = "runhcs-wcow-hypervisor-"
+	// KubernetesWindowsRuntimeHandlerLabelPrefix is the prefix of the node labels advertising the containerd handlers of the Windows nodes
+	KubernetesWindowsRuntimeHandlerLabelPrefix = "runtimehandler.kubernetes.azure.com/"
 )
 
 // validation values
@@ -79,6 +97,10 @@ const (
 	DefaultVnetResourceGroupSegmentIndex = 4
 	// DefaultVnetNameResourceSegmentIndex specifies the default virtual network name segment index.
 	DefaultVnetNameResourceSegmentIndex = 8
+	// DefaultRouteTableResourceGroupSegmentIndex specifies the route table resource group segment index.
+	DefaultRouteTableResourceGroupSegmentIndex = 4
+	// DefaultRouteTableNameResourceSegmentIndex specifies the route table name segment index.
+	DefaultRouteTableNameResourceSegmentIndex = 8
 	// VirtualMachineScaleSets means that the vms are in a virtual machine scaleset
 	VirtualMachineScaleSets = "VirtualMachineScaleSets"
 	// ScaleSetPriorityRegular is the default ScaleSet Priority
@@ -157,6 +179,14 @@ const (
 	BasicLoadBalancerSku = "Basic"
 	// StandardLoadBalancerSku is the string const for Azure Standard Load Balancer
 	StandardLoadBalancerSku = "Standard"
+	// OutboundTypeLoadBalancer means that egress traffic goes through the outbound rules of the Standard Load Balancer
+	OutboundTypeLoadBalancer = "loadBalancer"
+	// OutboundTypeUserDefinedRouting means that egress traffic is routed by an existing route table, e.g. to a firewall
+	OutboundTypeUserDefinedRouting = "userDefinedRouting"
+	// OutboundTypeManagedNATGateway means that egress traffic goes through a NAT gateway created by aks-engine
+	OutboundTypeManagedNATGateway = "managedNATGateway"
+	// OutboundTypeUserAssignedNATGateway means that egress traffic goes through an existing NAT gateway
+	OutboundTypeUserAssignedNATGateway = "userAssignedNATGateway"
 	// DefaultExcludeMasterFromStandardLB determines the aks-engine provided default for excluding master nodes from standard load balancer.
 	DefaultExcludeMasterFromStandardLB = true
 	// DefaultSecureKubeletEnabled determines the aks-engine provided default for securing kubelet communications
@@ -216,6 +246,10 @@ const (
 	// DefaultOutboundRuleIdleTimeoutInMinutes determines the aks-engine provided default for IdleTimeoutInMinutes of the OutboundRule of the agent loadbalancer
 	// This value is set greater than the default Linux idle timeout (15.4 min): https://pracucci.com/linux-tcp-rto-min-max-and-tcp-retries2.html
 	DefaultOutboundRuleIdleTimeoutInMinutes = 30
+	// DefaultNATGatewayIdleTimeoutInMinutes determines the aks-engine provided default for the TCP idle timeout of a managed NAT gateway
+	DefaultNATGatewayIdleTimeoutInMinutes = 4
+	// DefaultNATGatewayPublicIPPrefixCount determines the aks-engine provided default for the number of public IP prefixes of a managed NAT gateway
+	DefaultNATGatewayPublicIPPrefixCount = 1
 	// AddonModeEnsureExists
 	AddonModeEnsureExists = "EnsureExists"
 	// AddonModeReconcile
@@ -243,6 +277,7 @@ const (
 	APIVersionKeyVault            = "2019-09-01"
 	APIVersionManagedIdentity     = "2018-11-30"
 	APIVersionNetwork             = "2018-08-01"
+	APIVersionNATGateway          = "2019-11-01"
 	APIVersionStorage             = "2018-07-01"
 )
 
@@ -483,6 +518,15 @@ const (
 	DefaultWindowsProvisioningScriptsPackageVersion = "v0.0.16"
 )
 
+const (
+	// WindowsGMSAModeDomainJoin joins the Windows nodes to the Active Directory domain of the gMSA
+	WindowsGMSAModeDomainJoin = "DomainJoin"
+	// WindowsGMSAModeCCGPlugin installs a Container Credential Guard plugin on the Windows nodes, that retrieves the gMSA credentials without domain join
+	WindowsGMSAModeCCGPlugin = "CCGPlugin"
+	// DefaultWindowsGMSAMode is the default windowsProfile.gmsa.mode value
+	DefaultWindowsGMSAMode = WindowsGMSAModeDomainJoin
+)
+
 const (
 	//DefaultExtensionsRootURL  Root URL for extensions
 	DefaultExtensionsRootURL = "https://raw.githubusercontent.com/Azure/aks-engine/master/"
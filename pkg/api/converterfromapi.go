@@ -6,6 +6,7 @@ package api
 import (
 	"fmt"
 
+	v1 "github.com/Azure/aks-engine/pkg/api/v1"
 	"github.com/Azure/aks-engine/pkg/api/vlabs"
 	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/to"
@@ -40,6 +41,11 @@ func ConvertContainerServiceToVLabs(api *ContainerService) *vlabs.ContainerServi
 	return vlabsCS
 }
 
+// ConvertContainerServiceToV1 converts an unversioned ContainerService to a v1 ContainerService
+func ConvertContainerServiceToV1(api *ContainerService) *v1.ContainerService {
+	return v1.ConvertFromVLabs(ConvertContainerServiceToVLabs(api))
+}
+
 // ConvertOrchestratorVersionProfileToVLabs converts an unversioned OrchestratorVersionProfile to a vlabs OrchestratorVersionProfile
 func ConvertOrchestratorVersionProfileToVLabs(api *OrchestratorVersionProfile) *vlabs.OrchestratorVersionProfile {
 	vlabsProfile := &vlabs.OrchestratorVersionProfile{}
@@ -125,6 +131,8 @@ func convertPropertiesToVLabs(api *Properties, vlabsProps *vlabs.Properties) {
 		vlabsProps.TelemetryProfile = &vlabs.TelemetryProfile{}
 		convertTelemetryProfileToVLabs(api.TelemetryProfile, vlabsProps.TelemetryProfile)
 	}
+
+	vlabsProps.NetworkSecurityRules = convertNetworkSecurityRulesToVLabs(api.NetworkSecurityRules)
 }
 
 func convertExtensionProfileToVLabs(api *ExtensionProfile, obj *vlabs.ExtensionProfile) {
@@ -225,6 +233,24 @@ func convertWindowsProfileToVLabs(api *WindowsProfile, vlabsProfile *vlabs.Windo
 		}
 	}
 	vlabsProfile.WindowsSecureTLSEnabled = api.WindowsSecureTLSEnabled
+	if api.GMSA != nil {
+		vlabsProfile.GMSA = &vlabs.WindowsGMSAProfile{
+			Mode:               api.GMSA.Mode,
+			DomainName:         api.GMSA.DomainName,
+			DomainJoinUser:     api.GMSA.DomainJoinUser,
+			DomainJoinPassword: api.GMSA.DomainJoinPassword,
+			OrganizationalUnit: api.GMSA.OrganizationalUnit,
+			CCGPluginURL:       api.GMSA.CCGPluginURL,
+			CCGPluginCLSID:     api.GMSA.CCGPluginCLSID,
+		}
+		if api.GMSA.DomainJoinPasswordKeyvaultSecretRef != nil {
+			vlabsProfile.GMSA.DomainJoinPasswordKeyvaultSecretRef = &vlabs.KeyvaultSecretRef{
+				VaultID:       api.GMSA.DomainJoinPasswordKeyvaultSecretRef.VaultID,
+				SecretName:    api.GMSA.DomainJoinPasswordKeyvaultSecretRef.SecretName,
+				SecretVersion: api.GMSA.DomainJoinPasswordKeyvaultSecretRef.SecretVersion,
+			}
+		}
+	}
 }
 
 func convertOrchestratorProfileToVLabs(api *OrchestratorProfile, o *vlabs.OrchestratorProfile) {
@@ -305,6 +331,8 @@ func convertKubernetesConfigToVLabs(apiCfg *KubernetesConfig, vlabsCfg *vlabs.Ku
 	vlabsCfg.AzureCNIVersion = apiCfg.AzureCNIVersion
 	vlabsCfg.AzureCNIURLLinux = apiCfg.AzureCNIURLLinux
 	vlabsCfg.AzureCNIURLWindows = apiCfg.AzureCNIURLWindows
+	vlabsCfg.EtcdDownloadURLBase = apiCfg.EtcdDownloadURLBase
+	vlabsCfg.CNIPluginsURL = apiCfg.CNIPluginsURL
 	vlabsCfg.KeyVaultSku = apiCfg.KeyVaultSku
 	vlabsCfg.MaximumLoadBalancerRuleCount = apiCfg.MaximumLoadBalancerRuleCount
 	vlabsCfg.ProxyMode = vlabs.KubeProxyMode(apiCfg.ProxyMode)
@@ -315,6 +343,15 @@ func convertKubernetesConfigToVLabs(apiCfg *KubernetesConfig, vlabsCfg *vlabs.Ku
 	vlabsCfg.MicrosoftAptRepositoryURL = apiCfg.MicrosoftAptRepositoryURL
 	vlabsCfg.EnableMultipleStandardLoadBalancers = apiCfg.EnableMultipleStandardLoadBalancers
 	vlabsCfg.Tags = apiCfg.Tags
+	vlabsCfg.OutboundType = apiCfg.OutboundType
+	vlabsCfg.RouteTableID = apiCfg.RouteTableID
+	if apiCfg.NATGatewayProfile != nil {
+		vlabsCfg.NATGatewayProfile = &vlabs.NATGatewayProfile{
+			ID:                   apiCfg.NATGatewayProfile.ID,
+			IdleTimeoutInMinutes: apiCfg.NATGatewayProfile.IdleTimeoutInMinutes,
+			PublicIPPrefixCount:  apiCfg.NATGatewayProfile.PublicIPPrefixCount,
+		}
+	}
 	convertComponentsToVlabs(apiCfg, vlabsCfg)
 	convertAddonsToVlabs(apiCfg, vlabsCfg)
 	convertKubeletConfigToVlabs(apiCfg, vlabsCfg)
@@ -445,6 +482,7 @@ func convertAddonsToVlabs(a *KubernetesConfig, v *vlabs.KubernetesConfig) {
 			Mode:    a.Addons[i].Mode,
 			Config:  map[string]string{},
 			Data:    a.Addons[i].Data,
+			Source:  convertAddonSourceToVlabs(a.Addons[i].Source),
 		})
 		for j := range a.Addons[i].Containers {
 			v.Addons[i].Containers = append(v.Addons[i].Containers, vlabs.KubernetesContainerSpec{
@@ -475,6 +513,28 @@ func convertAddonsToVlabs(a *KubernetesConfig, v *vlabs.KubernetesConfig) {
 	}
 }
 
+func convertAddonSourceToVlabs(a *AddonSource) *vlabs.AddonSource {
+	if a == nil {
+		return nil
+	}
+	v := &vlabs.AddonSource{
+		Path:     a.Path,
+		URL:      a.URL,
+		SHA256:   a.SHA256,
+		Template: a.Template,
+	}
+	if a.HelmChart != nil {
+		v.HelmChart = &vlabs.AddonHelmChart{
+			Chart:      a.HelmChart.Chart,
+			Version:    a.HelmChart.Version,
+			Repository: a.HelmChart.Repository,
+			Namespace:  a.HelmChart.Namespace,
+			ValuesFile: a.HelmChart.ValuesFile,
+		}
+	}
+	return v
+}
+
 func convertMasterProfileToVLabs(api *MasterProfile, vlabsProfile *vlabs.MasterProfile) {
 	vlabsProfile.Count = api.Count
 	vlabsProfile.DNSPrefix = api.DNSPrefix
@@ -581,6 +641,7 @@ func convertAgentPoolProfileToVLabs(api *AgentPoolProfile, p *vlabs.AgentPoolPro
 	p.DiskEncryptionSetID = api.DiskEncryptionSetID
 	p.EncryptionAtHost = api.EncryptionAtHost
 	p.ProximityPlacementGroupID = api.ProximityPlacementGroupID
+	p.NetworkSecurityRules = convertNetworkSecurityRulesToVLabs(api.NetworkSecurityRules)
 
 	for k, v := range api.CustomNodeLabels {
 		p.CustomNodeLabels[k] = v
@@ -618,6 +679,13 @@ func convertAgentPoolProfileToVLabs(api *AgentPoolProfile, p *vlabs.AgentPoolPro
 	}
 	p.OSDiskCachingType = api.OSDiskCachingType
 	p.DataDiskCachingType = api.DataDiskCachingType
+	p.WindowsPublisher = api.WindowsPublisher
+	p.WindowsOffer = api.WindowsOffer
+	p.WindowsSku = api.WindowsSku
+	p.WindowsImageVersion = api.WindowsImageVersion
+	p.WindowsDockerVersion = api.WindowsDockerVersion
+	p.HyperVGeneration = api.HyperVGeneration
+	p.Architecture = api.Architecture
 	p.VMSSName = api.VMSSName
 }
 
@@ -649,6 +717,8 @@ func convertCertificateProfileToVLabs(api *CertificateProfile, vlabs *vlabs.Cert
 	vlabs.EtcdClientPrivateKey = api.EtcdClientPrivateKey
 	vlabs.EtcdPeerCertificates = api.EtcdPeerCertificates
 	vlabs.EtcdPeerPrivateKeys = api.EtcdPeerPrivateKeys
+	vlabs.GMSAWebhookCertificate = api.GMSAWebhookCertificate
+	vlabs.GMSAWebhookPrivateKey = api.GMSAWebhookPrivateKey
 }
 
 func convertAADProfileToVLabs(api *AADProfile, vlabs *vlabs.AADProfile) {
@@ -716,6 +786,28 @@ func convertTelemetryProfileToVLabs(api *TelemetryProfile, vlabstp *vlabs.Teleme
 	vlabstp.ApplicationInsightsKey = api.ApplicationInsightsKey
 }
 
+func convertNetworkSecurityRulesToVLabs(rules []NetworkSecurityRule) []vlabs.NetworkSecurityRule {
+	if rules == nil {
+		return nil
+	}
+	vlabsRules := make([]vlabs.NetworkSecurityRule, 0, len(rules))
+	for _, r := range rules {
+		vlabsRules = append(vlabsRules, vlabs.NetworkSecurityRule{
+			Name:                       r.Name,
+			Description:                r.Description,
+			Priority:                   r.Priority,
+			Direction:                  r.Direction,
+			Access:                     r.Access,
+			Protocol:                   r.Protocol,
+			SourceAddressPrefixes:      append([]string(nil), r.SourceAddressPrefixes...),
+			SourcePortRange:            r.SourcePortRange,
+			DestinationAddressPrefixes: append([]string(nil), r.DestinationAddressPrefixes...),
+			DestinationPortRanges:      append([]string(nil), r.DestinationPortRanges...),
+		})
+	}
+	return vlabsRules
+}
+
 func convertAzureEnvironmentSpecConfigToVLabs(api *AzureEnvironmentSpecConfig, vlabses *vlabs.AzureEnvironmentSpecConfig) {
 	vlabses.CloudName = api.CloudName
 	vlabses.EndpointConfig = vlabs.AzureEndpointConfig{
@@ -12,6 +12,7 @@ import (
 	"github.com/Azure/aks-engine/pkg/api/vlabs"
 	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 const ValidSSHPublicKey = "ssh-rsa AAAAB3NzaC1yc2EAAAABJQAAAQEApD8+lRvLtUcyfO8N2Cwq0zY9DG1Un9d+tcmU3HgnAzBr6UR/dDT5M07NV7DN1lmu/0dt6Ay/ItjF9xK//nwVJL3ezEX32yhLKkCKFMB1LcANNzlhT++SB5tlRBx65CTL8z9FORe4UCWVJNafxu3as/BshQSrSaYt3hjSeYuzTpwd4+4xQutzbTXEUBDUr01zEfjjzfUu0HDrg1IFae62hnLm3ajG6b432IIdUhFUmgjZDljUt5bI3OEz5IWPsNOOlVTuo6fqU8lJHClAtAlZEZkyv0VotidC7ZSCfV153rRsEk9IWscwL2PQIQnCw7YyEYEffDeLjBwkH6MIdJ6OgQ== rsa-key-20170510"
@@ -681,6 +682,42 @@ func TestPPGToVLabs(t *testing.T) {
 	}
 }
 
+func TestNetworkSecurityRulesToVLabs(t *testing.T) {
+	rules := []NetworkSecurityRule{
+		{
+			Name:                  "allow_nodeports",
+			Priority:              200,
+			Direction:             "Inbound",
+			Access:                "Allow",
+			Protocol:              "Tcp",
+			SourceAddressPrefixes: []string{"10.1.0.0/16"},
+			DestinationPortRanges: []string{"30000-32767"},
+		},
+	}
+	cs := getDefaultContainerService()
+	cs.Properties.NetworkSecurityRules = rules
+	cs.Properties.AgentPoolProfiles[0].NetworkSecurityRules = rules
+	vlabsCS := ConvertContainerServiceToVLabs(cs)
+
+	expected := []vlabs.NetworkSecurityRule{
+		{
+			Name:                  "allow_nodeports",
+			Priority:              200,
+			Direction:             "Inbound",
+			Access:                "Allow",
+			Protocol:              "Tcp",
+			SourceAddressPrefixes: []string{"10.1.0.0/16"},
+			DestinationPortRanges: []string{"30000-32767"},
+		},
+	}
+	if diff := cmp.Diff(vlabsCS.Properties.NetworkSecurityRules, expected, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("unexpected diff in cluster network security rules: %s", diff)
+	}
+	if diff := cmp.Diff(vlabsCS.Properties.AgentPoolProfiles[0].NetworkSecurityRules, expected, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("unexpected diff in agent pool network security rules: %s", diff)
+	}
+}
+
 func TestPlatformFaultDomainCountToVLabs(t *testing.T) {
 	cs := getDefaultContainerService()
 	cs.Properties.MasterProfile.PlatformFaultDomainCount = to.IntPtr(3)
@@ -1006,3 +1043,53 @@ func TestConvertLinuxProfileToVlabs(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertAddonSourceToVlabs(t *testing.T) {
+	a := &KubernetesConfig{
+		Addons: []KubernetesAddon{
+			{
+				Name:    "ingress-nginx",
+				Enabled: to.BoolPtr(true),
+				Source: &AddonSource{
+					Path:     "addons/ingress-nginx",
+					Template: true,
+				},
+			},
+			{
+				Name:    "agent",
+				Enabled: to.BoolPtr(true),
+				Source: &AddonSource{
+					HelmChart: &AddonHelmChart{
+						Chart:      "./charts/agent",
+						ValuesFile: "values.yaml",
+					},
+				},
+			},
+			{
+				Name:    "coredns",
+				Enabled: to.BoolPtr(true),
+			},
+		},
+	}
+	v := &vlabs.KubernetesConfig{}
+	convertAddonsToVlabs(a, v)
+
+	expected := []*vlabs.AddonSource{
+		{
+			Path:     "addons/ingress-nginx",
+			Template: true,
+		},
+		{
+			HelmChart: &vlabs.AddonHelmChart{
+				Chart:      "./charts/agent",
+				ValuesFile: "values.yaml",
+			},
+		},
+		nil,
+	}
+	for i, addon := range v.Addons {
+		if diff := cmp.Diff(expected[i], addon.Source); diff != "" {
+			t.Errorf("unexpected diff testing the source of addon %s: %s", addon.Name, diff)
+		}
+	}
+}
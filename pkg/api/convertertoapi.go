@@ -5,6 +5,7 @@ package api
 
 import (
 	"github.com/Azure/aks-engine/pkg/api/common"
+	v1 "github.com/Azure/aks-engine/pkg/api/v1"
 	"github.com/Azure/aks-engine/pkg/api/vlabs"
 	"github.com/Azure/aks-engine/pkg/helpers"
 	"github.com/Azure/go-autorest/autorest/azure"
@@ -41,6 +42,11 @@ func ConvertVLabsContainerService(vlabs *vlabs.ContainerService, isUpdate bool)
 	return c, nil
 }
 
+// ConvertV1ContainerService converts a v1 ContainerService to an unversioned ContainerService
+func ConvertV1ContainerService(cs *v1.ContainerService, isUpdate bool) (*ContainerService, error) {
+	return ConvertVLabsContainerService(v1.ConvertToVLabs(cs), isUpdate)
+}
+
 // convertVLabsResourcePurchasePlan converts a vlabs ResourcePurchasePlan to an unversioned ResourcePurchasePlan
 func convertVLabsResourcePurchasePlan(vlabs *vlabs.ResourcePurchasePlan, api *ResourcePurchasePlan) {
 	api.Name = vlabs.Name
@@ -110,6 +116,8 @@ func convertVLabsProperties(vlabs *vlabs.Properties, api *Properties, isUpdate b
 		convertVLabsTelemetryProfile(vlabs.TelemetryProfile, api.TelemetryProfile)
 	}
 
+	api.NetworkSecurityRules = convertVLabsNetworkSecurityRules(vlabs.NetworkSecurityRules)
+
 	return nil
 }
 
@@ -220,6 +228,24 @@ func convertVLabsWindowsProfile(vlabs *vlabs.WindowsProfile, api *WindowsProfile
 		}
 	}
 	api.WindowsSecureTLSEnabled = vlabs.WindowsSecureTLSEnabled
+	if vlabs.GMSA != nil {
+		api.GMSA = &WindowsGMSAProfile{
+			Mode:               vlabs.GMSA.Mode,
+			DomainName:         vlabs.GMSA.DomainName,
+			DomainJoinUser:     vlabs.GMSA.DomainJoinUser,
+			DomainJoinPassword: vlabs.GMSA.DomainJoinPassword,
+			OrganizationalUnit: vlabs.GMSA.OrganizationalUnit,
+			CCGPluginURL:       vlabs.GMSA.CCGPluginURL,
+			CCGPluginCLSID:     vlabs.GMSA.CCGPluginCLSID,
+		}
+		if vlabs.GMSA.DomainJoinPasswordKeyvaultSecretRef != nil {
+			api.GMSA.DomainJoinPasswordKeyvaultSecretRef = &KeyvaultSecretRef{
+				VaultID:       vlabs.GMSA.DomainJoinPasswordKeyvaultSecretRef.VaultID,
+				SecretName:    vlabs.GMSA.DomainJoinPasswordKeyvaultSecretRef.SecretName,
+				SecretVersion: vlabs.GMSA.DomainJoinPasswordKeyvaultSecretRef.SecretVersion,
+			}
+		}
+	}
 }
 
 func convertVLabsOrchestratorProfile(vp *vlabs.Properties, api *OrchestratorProfile, isUpdate bool) error {
@@ -312,6 +338,8 @@ func convertVLabsKubernetesConfig(vlabs *vlabs.KubernetesConfig, api *Kubernetes
 	api.AzureCNIVersion = vlabs.AzureCNIVersion
 	api.AzureCNIURLLinux = vlabs.AzureCNIURLLinux
 	api.AzureCNIURLWindows = vlabs.AzureCNIURLWindows
+	api.EtcdDownloadURLBase = vlabs.EtcdDownloadURLBase
+	api.CNIPluginsURL = vlabs.CNIPluginsURL
 	api.KeyVaultSku = vlabs.KeyVaultSku
 	api.MaximumLoadBalancerRuleCount = vlabs.MaximumLoadBalancerRuleCount
 	api.ProxyMode = KubeProxyMode(vlabs.ProxyMode)
@@ -322,6 +350,15 @@ func convertVLabsKubernetesConfig(vlabs *vlabs.KubernetesConfig, api *Kubernetes
 	api.MicrosoftAptRepositoryURL = vlabs.MicrosoftAptRepositoryURL
 	api.EnableMultipleStandardLoadBalancers = vlabs.EnableMultipleStandardLoadBalancers
 	api.Tags = vlabs.Tags
+	api.OutboundType = vlabs.OutboundType
+	api.RouteTableID = vlabs.RouteTableID
+	if vlabs.NATGatewayProfile != nil {
+		api.NATGatewayProfile = &NATGatewayProfile{
+			ID:                   vlabs.NATGatewayProfile.ID,
+			IdleTimeoutInMinutes: vlabs.NATGatewayProfile.IdleTimeoutInMinutes,
+			PublicIPPrefixCount:  vlabs.NATGatewayProfile.PublicIPPrefixCount,
+		}
+	}
 	convertComponentsToAPI(vlabs, api)
 	convertAddonsToAPI(vlabs, api)
 	convertKubeletConfigToAPI(vlabs, api)
@@ -398,6 +435,7 @@ func convertAddonsToAPI(v *vlabs.KubernetesConfig, a *KubernetesConfig) {
 			Mode:    v.Addons[i].Mode,
 			Config:  map[string]string{},
 			Data:    v.Addons[i].Data,
+			Source:  convertAddonSourceToAPI(v.Addons[i].Source),
 		})
 		for j := range v.Addons[i].Containers {
 			a.Addons[i].Containers = append(a.Addons[i].Containers, KubernetesContainerSpec{
@@ -428,6 +466,28 @@ func convertAddonsToAPI(v *vlabs.KubernetesConfig, a *KubernetesConfig) {
 	}
 }
 
+func convertAddonSourceToAPI(v *vlabs.AddonSource) *AddonSource {
+	if v == nil {
+		return nil
+	}
+	a := &AddonSource{
+		Path:     v.Path,
+		URL:      v.URL,
+		SHA256:   v.SHA256,
+		Template: v.Template,
+	}
+	if v.HelmChart != nil {
+		a.HelmChart = &AddonHelmChart{
+			Chart:      v.HelmChart.Chart,
+			Version:    v.HelmChart.Version,
+			Repository: v.HelmChart.Repository,
+			Namespace:  v.HelmChart.Namespace,
+			ValuesFile: v.HelmChart.ValuesFile,
+		}
+	}
+	return a
+}
+
 func convertCustomFilesToAPI(v *vlabs.MasterProfile, a *MasterProfile) {
 	if v.CustomFiles != nil {
 		a.CustomFiles = &[]CustomFile{}
@@ -614,6 +674,7 @@ func convertVLabsAgentPoolProfile(vlabs *vlabs.AgentPoolProfile, api *AgentPoolP
 	api.UltraSSDEnabled = vlabs.UltraSSDEnabled
 	api.EncryptionAtHost = vlabs.EncryptionAtHost
 	api.ProximityPlacementGroupID = vlabs.ProximityPlacementGroupID
+	api.NetworkSecurityRules = convertVLabsNetworkSecurityRules(vlabs.NetworkSecurityRules)
 
 	api.CustomNodeLabels = map[string]string{}
 	for k, v := range vlabs.CustomNodeLabels {
@@ -652,6 +713,13 @@ func convertVLabsAgentPoolProfile(vlabs *vlabs.AgentPoolProfile, api *AgentPoolP
 	}
 	api.OSDiskCachingType = vlabs.OSDiskCachingType
 	api.DataDiskCachingType = vlabs.DataDiskCachingType
+	api.WindowsPublisher = vlabs.WindowsPublisher
+	api.WindowsOffer = vlabs.WindowsOffer
+	api.WindowsSku = vlabs.WindowsSku
+	api.WindowsImageVersion = vlabs.WindowsImageVersion
+	api.WindowsDockerVersion = vlabs.WindowsDockerVersion
+	api.HyperVGeneration = vlabs.HyperVGeneration
+	api.Architecture = vlabs.Architecture
 	api.VMSSName = vlabs.VMSSName
 }
 
@@ -694,6 +762,8 @@ func convertVLabsCertificateProfile(vlabs *vlabs.CertificateProfile, api *Certif
 	api.EtcdClientPrivateKey = vlabs.EtcdClientPrivateKey
 	api.EtcdPeerCertificates = vlabs.EtcdPeerCertificates
 	api.EtcdPeerPrivateKeys = vlabs.EtcdPeerPrivateKeys
+	api.GMSAWebhookCertificate = vlabs.GMSAWebhookCertificate
+	api.GMSAWebhookPrivateKey = vlabs.GMSAWebhookPrivateKey
 }
 
 func convertVLabsAADProfile(vlabs *vlabs.AADProfile, api *AADProfile) {
@@ -753,6 +823,28 @@ func convertVLabsTelemetryProfile(vlabs *vlabs.TelemetryProfile, api *TelemetryP
 	api.ApplicationInsightsKey = vlabs.ApplicationInsightsKey
 }
 
+func convertVLabsNetworkSecurityRules(vlabsRules []vlabs.NetworkSecurityRule) []NetworkSecurityRule {
+	if vlabsRules == nil {
+		return nil
+	}
+	rules := make([]NetworkSecurityRule, 0, len(vlabsRules))
+	for _, r := range vlabsRules {
+		rules = append(rules, NetworkSecurityRule{
+			Name:                       r.Name,
+			Description:                r.Description,
+			Priority:                   r.Priority,
+			Direction:                  r.Direction,
+			Access:                     r.Access,
+			Protocol:                   r.Protocol,
+			SourceAddressPrefixes:      append([]string(nil), r.SourceAddressPrefixes...),
+			SourcePortRange:            r.SourcePortRange,
+			DestinationAddressPrefixes: append([]string(nil), r.DestinationAddressPrefixes...),
+			DestinationPortRanges:      append([]string(nil), r.DestinationPortRanges...),
+		})
+	}
+	return rules
+}
+
 func convertAzureEnvironmentSpecConfig(vlabses *vlabs.AzureEnvironmentSpecConfig, api *AzureEnvironmentSpecConfig) {
 	api.CloudName = vlabses.CloudName
 	api.EndpointConfig = AzureEndpointConfig{
@@ -1248,3 +1248,65 @@ func TestConvertVLabsLinuxProfile(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertAddonSourceToAPI(t *testing.T) {
+	v := &vlabs.KubernetesConfig{
+		Addons: []vlabs.KubernetesAddon{
+			{
+				Name:    "ingress-nginx",
+				Enabled: to.BoolPtr(true),
+				Source: &vlabs.AddonSource{
+					HelmChart: &vlabs.AddonHelmChart{
+						Chart:      "ingress-nginx",
+						Version:    "4.0.1",
+						Repository: "https://kubernetes.github.io/ingress-nginx",
+						Namespace:  "ingress-nginx",
+						ValuesFile: "values.yaml",
+					},
+				},
+			},
+			{
+				Name:    "agent",
+				Enabled: to.BoolPtr(true),
+				Data:    "a2luZDogTmFtZXNwYWNl",
+				Source: &vlabs.AddonSource{
+					URL:      "https://example.com/agent.yaml",
+					SHA256:   "7d865e959b2466918c9863afca942d0fb89d7c9ac0c99bafc3749504ded97730",
+					Template: true,
+				},
+			},
+			{
+				Name:    "coredns",
+				Enabled: to.BoolPtr(true),
+			},
+		},
+	}
+	a := &KubernetesConfig{}
+	convertAddonsToAPI(v, a)
+
+	expected := []*AddonSource{
+		{
+			HelmChart: &AddonHelmChart{
+				Chart:      "ingress-nginx",
+				Version:    "4.0.1",
+				Repository: "https://kubernetes.github.io/ingress-nginx",
+				Namespace:  "ingress-nginx",
+				ValuesFile: "values.yaml",
+			},
+		},
+		{
+			URL:      "https://example.com/agent.yaml",
+			SHA256:   "7d865e959b2466918c9863afca942d0fb89d7c9ac0c99bafc3749504ded97730",
+			Template: true,
+		},
+		nil,
+	}
+	for i, addon := range a.Addons {
+		if diff := cmp.Diff(expected[i], addon.Source); diff != "" {
+			t.Errorf("unexpected diff testing the source of addon %s: %s", addon.Name, diff)
+		}
+	}
+	if a.Addons[1].Data != "a2luZDogTmFtZXNwYWNl" {
+		t.Errorf("expected the resolved data of a custom addon to be kept, got %s", a.Addons[1].Data)
+	}
+}
@@ -296,9 +296,13 @@ func (cs *ContainerService) setKubeletConfig(isUpgrade bool) {
 			}
 		}
 		// Override the --resolv-conf kubelet config value for Ubuntu 18.04 after the distro value is set.
-		if profile.IsUbuntu1804() || profile.IsUbuntu2004() {
+		if profile.IsUbuntu1804() || profile.IsUbuntu2004() || profile.IsUbuntu2204() {
 			profile.KubernetesConfig.KubeletConfig["--resolv-conf"] = "/run/systemd/resolve/resolv.conf"
 		}
+		// Ubuntu 22.04 boots with the unified cgroup v2 hierarchy, which is managed by systemd
+		if profile.IsUbuntu2204() {
+			profile.KubernetesConfig.KubeletConfig["--cgroup-driver"] = "systemd"
+		}
 
 		removeKubeletFlags(profile.KubernetesConfig.KubeletConfig, o.OrchestratorVersion)
 		if cs.Properties.OrchestratorProfile.KubernetesConfig.IsAddonEnabled(common.AADPodIdentityAddonName) && !profile.IsWindows() {
@@ -25,7 +25,7 @@ import (
 )
 
 // DistroValues is a list of currently supported distros
-var DistroValues = []Distro{"", Ubuntu, Ubuntu2004, Ubuntu2004Gen2, Ubuntu1804, Flatcar, AKSUbuntu1604, AKSUbuntu1804, Ubuntu1804Gen2, AKSUbuntu2004, ACC1604}
+var DistroValues = []Distro{"", Ubuntu, Ubuntu2204, Ubuntu2204Gen2, Ubuntu2004, Ubuntu2004Gen2, Ubuntu1804, Flatcar, AKSUbuntu1604, AKSUbuntu1804, Ubuntu1804Gen2, AKSUbuntu2004, ACC1604}
 
 // PropertiesDefaultsParams is the parameters when we set the properties defaults for ContainerService.
 type PropertiesDefaultsParams struct {
@@ -69,6 +69,7 @@ func (cs *ContainerService) SetPropertiesDefaults(params PropertiesDefaultsParam
 	if cs.Properties.WindowsProfile != nil {
 		cs.setWindowsProfileDefaults(params.IsUpgrade, params.IsScale)
 		cs.setCSIProxyDefaults()
+		cs.setWindowsGMSADefaults()
 	}
 
 	properties.setTelemetryProfileDefaults()
@@ -489,6 +490,17 @@ func (cs *ContainerService) setOrchestratorDefaults(isUpgrade, isScale bool) {
 			a.OrchestratorProfile.KubernetesConfig.OutboundRuleIdleTimeoutInMinutes == 0 {
 			a.OrchestratorProfile.KubernetesConfig.OutboundRuleIdleTimeoutInMinutes = DefaultOutboundRuleIdleTimeoutInMinutes
 		}
+		if a.OrchestratorProfile.KubernetesConfig.IsManagedNATGateway() {
+			if a.OrchestratorProfile.KubernetesConfig.NATGatewayProfile == nil {
+				a.OrchestratorProfile.KubernetesConfig.NATGatewayProfile = &NATGatewayProfile{}
+			}
+			if a.OrchestratorProfile.KubernetesConfig.NATGatewayProfile.IdleTimeoutInMinutes == 0 {
+				a.OrchestratorProfile.KubernetesConfig.NATGatewayProfile.IdleTimeoutInMinutes = DefaultNATGatewayIdleTimeoutInMinutes
+			}
+			if a.OrchestratorProfile.KubernetesConfig.NATGatewayProfile.PublicIPPrefixCount == 0 {
+				a.OrchestratorProfile.KubernetesConfig.NATGatewayProfile.PublicIPPrefixCount = DefaultNATGatewayPublicIPPrefixCount
+			}
+		}
 
 		if o.KubernetesConfig.LoadBalancerSku == StandardLoadBalancerSku {
 			if o.KubernetesConfig.CloudProviderDisableOutboundSNAT == nil {
@@ -579,7 +591,10 @@ func (cs *ContainerService) setOrchestratorDefaults(isUpgrade, isScale bool) {
 			if profile.OSType != Windows {
 				if profile.ImageRef == nil {
 					if profile.Distro == "" {
-						if profile.OSDiskSizeGB != 0 && profile.OSDiskSizeGB < VHDDiskSizeAKS {
+						// The AKS Engine VHDs are only built for generation 1 amd64 VMs
+						if profile.IsArm64() || profile.HyperVGeneration == HyperVGenerationV2 {
+							profile.Distro = Ubuntu2004
+						} else if profile.OSDiskSizeGB != 0 && profile.OSDiskSizeGB < VHDDiskSizeAKS {
 							profile.Distro = Ubuntu1804
 							if cs.Properties.IsAzureStackCloud() {
 								profile.Distro = Ubuntu2004
@@ -920,9 +935,30 @@ func (cs *ContainerService) setWindowsProfileDefaults(isUpgrade, isScale bool) {
 			}
 		}
 	}
+	if !isScale {
+		cs.setWindowsAgentPoolImageDefaults()
+	}
 	// Scale: Keep the same version to match other nodes because we have no way to rollback
 }
 
+// setWindowsAgentPoolImageDefaults sets the image version of Windows agent pools that override the image of the WindowsProfile
+func (cs *ContainerService) setWindowsAgentPoolImageDefaults() {
+	for _, profile := range cs.Properties.AgentPoolProfiles {
+		if !profile.HasWindowsImageOverride() || profile.WindowsImageVersion != "" {
+			continue
+		}
+		// The image version of the WindowsProfile is specific to its publisher/offer/sku, so it doesn't apply to the pool
+		imageConfig := profile.GetWindowsImageConfig(cs.Properties.WindowsProfile)
+		profile.WindowsImageVersion = "latest"
+		for _, aksEngineImageConfig := range []AzureOSImageConfig{AKSWindowsServer2019ContainerDOSImageConfig, AKSWindowsServer2019OSImageConfig} {
+			if imageConfig.ImagePublisher == aksEngineImageConfig.ImagePublisher && imageConfig.ImageOffer == aksEngineImageConfig.ImageOffer && imageConfig.ImageSku == aksEngineImageConfig.ImageSku {
+				profile.WindowsImageVersion = aksEngineImageConfig.ImageVersion
+				break
+			}
+		}
+	}
+}
+
 // setStorageDefaults for agents
 func (p *Properties) setStorageDefaults() {
 	if p.MasterProfile != nil && len(p.MasterProfile.StorageProfile) == 0 {
@@ -960,7 +996,7 @@ func (cs *ContainerService) SetDefaultCerts(params DefaultCertParams) (bool, []n
 	provided := certsAlreadyPresent(p.CertificateProfile, p.MasterProfile.Count)
 
 	if areAllTrue(provided) {
-		return false, nil, nil
+		return false, nil, cs.setGMSAWebhookCertDefaults(params.PkiKeySize)
 	}
 
 	var azureProdFQDNs []string
@@ -1022,6 +1058,9 @@ func (cs *ContainerService) SetDefaultCerts(params DefaultCertParams) (bool, []n
 
 		p.CertificateProfile.CaCertificate = caPair.CertificatePem
 		p.CertificateProfile.CaPrivateKey = caPair.PrivateKeyPem
+		// the gmsa-webhook serving pair was signed by the previous CA
+		p.CertificateProfile.GMSAWebhookCertificate = ""
+		p.CertificateProfile.GMSAWebhookPrivateKey = ""
 	}
 
 	serviceCIDR := p.OrchestratorProfile.KubernetesConfig.ServiceCIDR
@@ -1079,7 +1118,34 @@ func (cs *ContainerService) SetDefaultCerts(params DefaultCertParams) (bool, []n
 		}
 	}
 
-	return true, ips, nil
+	return true, ips, cs.setGMSAWebhookCertDefaults(params.PkiKeySize)
+}
+
+// setGMSAWebhookCertDefaults signs a serving cert/key pair for the gmsa-webhook addon with the cluster CA,
+// unless the addon is disabled or the pair was already generated
+func (cs *ContainerService) setGMSAWebhookCertDefaults(pkiKeySize int) error {
+	p := cs.Properties
+	if p.OrchestratorProfile == nil || p.OrchestratorProfile.KubernetesConfig == nil ||
+		!p.OrchestratorProfile.KubernetesConfig.IsAddonEnabled(common.GMSAWebhookAddonName) {
+		return nil
+	}
+	c := p.CertificateProfile
+	if len(c.GMSAWebhookCertificate) > 0 && len(c.GMSAWebhookPrivateKey) > 0 {
+		return nil
+	}
+	caPair := &helpers.PkiKeyCertPair{CertificatePem: c.CaCertificate, PrivateKeyPem: c.CaPrivateKey}
+	dnsNames := []string{
+		common.GMSAWebhookAddonName,
+		common.GMSAWebhookAddonName + ".kube-system",
+		common.GMSAWebhookAddonName + ".kube-system.svc",
+	}
+	servingPair, err := helpers.CreateServerPkiKeyCertPair(caPair, common.GMSAWebhookAddonName, dnsNames, pkiKeySize)
+	if err != nil {
+		return errors.Wrap(err, "signing the gmsa-webhook serving certificate")
+	}
+	c.GMSAWebhookCertificate = servingPair.CertificatePem
+	c.GMSAWebhookPrivateKey = servingPair.PrivateKeyPem
+	return nil
 }
 
 func areAllTrue(m map[string]bool) bool {
@@ -1235,6 +1301,16 @@ func (cs *ContainerService) setCSIProxyDefaults() {
 	}
 }
 
+func (cs *ContainerService) setWindowsGMSADefaults() {
+	w := cs.Properties.WindowsProfile
+	if !w.IsGMSAEnabled() {
+		return
+	}
+	if w.GMSA.Mode == "" {
+		w.GMSA.Mode = DefaultWindowsGMSAMode
+	}
+}
+
 func getPodIPAddressCountForAzureCNI(kubeletMaxPods int, k *KubernetesConfig) int {
 	ret := 1 // We need at least IP address for eth0
 	var numHostNetworkPods int
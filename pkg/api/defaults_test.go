@@ -1973,6 +1973,32 @@ func TestMasterProfileDefaults(t *testing.T) {
 			properties.OrchestratorProfile.KubernetesConfig.OutboundRuleIdleTimeoutInMinutes, DefaultOutboundRuleIdleTimeoutInMinutes)
 	}
 
+	// this validates default configurations for a managed NAT gateway.
+	mockCS = getMockBaseContainerService(common.RationalizeReleaseAndVersion(common.Kubernetes, common.KubernetesDefaultRelease, "", false, false, false))
+	properties = mockCS.Properties
+	properties.OrchestratorProfile.KubernetesConfig.LoadBalancerSku = StandardLoadBalancerSku
+	properties.OrchestratorProfile.KubernetesConfig.OutboundType = OutboundTypeManagedNATGateway
+	_, err = mockCS.SetPropertiesDefaults(PropertiesDefaultsParams{
+		IsScale:    false,
+		IsUpgrade:  false,
+		PkiKeySize: helpers.DefaultPkiKeySize,
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	natGatewayProfile := properties.OrchestratorProfile.KubernetesConfig.NATGatewayProfile
+	if natGatewayProfile == nil {
+		t.Fatalf("OrchestratorProfile.KubernetesConfig.NATGatewayProfile was not defaulted")
+	}
+	if natGatewayProfile.IdleTimeoutInMinutes != DefaultNATGatewayIdleTimeoutInMinutes {
+		t.Fatalf("OrchestratorProfile.KubernetesConfig.NATGatewayProfile.IdleTimeoutInMinutes did not have the expected configuration, got %d, expected %d",
+			natGatewayProfile.IdleTimeoutInMinutes, DefaultNATGatewayIdleTimeoutInMinutes)
+	}
+	if natGatewayProfile.PublicIPPrefixCount != DefaultNATGatewayPublicIPPrefixCount {
+		t.Fatalf("OrchestratorProfile.KubernetesConfig.NATGatewayProfile.PublicIPPrefixCount did not have the expected configuration, got %d, expected %d",
+			natGatewayProfile.PublicIPPrefixCount, DefaultNATGatewayPublicIPPrefixCount)
+	}
+
 	// this validates cluster subnet default configuration for single stack IPv6 only cluster
 	mockCS = getMockBaseContainerService(common.RationalizeReleaseAndVersion(common.Kubernetes, common.KubernetesDefaultRelease, "", false, false, false))
 	properties = mockCS.Properties
@@ -2220,6 +2246,39 @@ func TestDistroDefaults(t *testing.T) {
 	}
 }
 
+func TestDistroDefaultsGen2AndArm64Pools(t *testing.T) {
+	mockAPI := getMockAPIProperties("1.0.0")
+	mockAPI.OrchestratorProfile = &OrchestratorProfile{
+		OrchestratorType: Kubernetes,
+		KubernetesConfig: &KubernetesConfig{},
+	}
+	mockAPI.AgentPoolProfiles = []*AgentPoolProfile{
+		{Name: "gen1", Count: 1, OSType: Linux},
+		{Name: "gen2", Count: 1, OSType: Linux, HyperVGeneration: HyperVGenerationV2},
+		{Name: "arm64", Count: 1, OSType: Linux, Architecture: ArchitectureArm64},
+		{Name: "jammy", Count: 1, OSType: Linux, Architecture: ArchitectureArm64, Distro: Ubuntu2204},
+	}
+	cs := &ContainerService{
+		Location:   "westus2",
+		Properties: &mockAPI,
+	}
+	if _, err := cs.SetPropertiesDefaults(PropertiesDefaultsParams{PkiKeySize: helpers.DefaultPkiKeySize}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, expected := range []Distro{AKSUbuntu1804, Ubuntu2004, Ubuntu2004, Ubuntu2204} {
+		if cs.Properties.AgentPoolProfiles[i].Distro != expected {
+			t.Errorf("expected pool %s to default to distro %s, got %s", cs.Properties.AgentPoolProfiles[i].Name, expected, cs.Properties.AgentPoolProfiles[i].Distro)
+		}
+	}
+	if v, ok := cs.Properties.AgentPoolProfiles[3].KubernetesConfig.KubeletConfig["--cgroup-driver"]; !ok || v != "systemd" {
+		t.Errorf("expected Ubuntu 22.04 pools to use the systemd cgroup driver, got %q", v)
+	}
+	if _, ok := cs.Properties.AgentPoolProfiles[2].KubernetesConfig.KubeletConfig["--cgroup-driver"]; ok {
+		t.Errorf("expected Ubuntu 20.04 pools to keep the default cgroup driver")
+	}
+}
+
 // TestDistroDefaultsOnAzureStack covers tests for setMasterProfileDefaults and setAgentProfileDefaults on azure stack
 func TestDistroDefaultsOnAzureStack(t *testing.T) {
 
@@ -3934,6 +3993,64 @@ func TestSetCertDefaultsVMSS(t *testing.T) {
 	}
 }
 
+func TestSetGMSAWebhookCertDefaults(t *testing.T) {
+	cs := &ContainerService{
+		Properties: &Properties{
+			MasterProfile: &MasterProfile{
+				Count:     1,
+				DNSPrefix: "myprefix1",
+				VMSize:    "Standard_DS2_v2",
+			},
+			OrchestratorProfile: &OrchestratorProfile{
+				OrchestratorType:    Kubernetes,
+				OrchestratorVersion: "1.21.2",
+				KubernetesConfig: &KubernetesConfig{
+					Addons: []KubernetesAddon{
+						{
+							Name:    common.GMSAWebhookAddonName,
+							Enabled: to.BoolPtr(false),
+						},
+					},
+				},
+			},
+		},
+	}
+	cs.setOrchestratorDefaults(false, false)
+	cs.Properties.setMasterProfileDefaults()
+	if _, _, err := cs.SetDefaultCerts(DefaultCertParams{PkiKeySize: helpers.DefaultPkiKeySize}); err != nil {
+		t.Fatalf("unexpected error thrown while executing SetDefaultCerts %s", err.Error())
+	}
+	if cs.Properties.CertificateProfile.GMSAWebhookCertificate != "" || cs.Properties.CertificateProfile.GMSAWebhookPrivateKey != "" {
+		t.Error("expected no gmsa-webhook serving certificate when the addon is disabled")
+	}
+
+	// The pair is generated once the addon is enabled on an existing cluster, and then kept
+	cs.Properties.OrchestratorProfile.KubernetesConfig.Addons[0].Enabled = to.BoolPtr(true)
+	if generated, _, err := cs.SetDefaultCerts(DefaultCertParams{PkiKeySize: helpers.DefaultPkiKeySize}); generated || err != nil {
+		t.Fatalf("expected SetDefaultCerts to only sign the gmsa-webhook serving certificate, got %t, %v", generated, err)
+	}
+	certificate := cs.Properties.CertificateProfile.GMSAWebhookCertificate
+	privateKey := cs.Properties.CertificateProfile.GMSAWebhookPrivateKey
+	if certificate == "" || privateKey == "" {
+		t.Fatal("expected SetDefaultCerts to sign the gmsa-webhook serving certificate")
+	}
+	if _, _, err := cs.SetDefaultCerts(DefaultCertParams{PkiKeySize: helpers.DefaultPkiKeySize}); err != nil {
+		t.Fatalf("unexpected error thrown while executing SetDefaultCerts %s", err.Error())
+	}
+	if cs.Properties.CertificateProfile.GMSAWebhookCertificate != certificate || cs.Properties.CertificateProfile.GMSAWebhookPrivateKey != privateKey {
+		t.Error("expected SetDefaultCerts to keep the gmsa-webhook serving certificate")
+	}
+
+	// A new CA signs a new pair
+	cs.Properties.CertificateProfile.CaCertificate = ""
+	if _, _, err := cs.SetDefaultCerts(DefaultCertParams{PkiKeySize: helpers.DefaultPkiKeySize}); err != nil {
+		t.Fatalf("unexpected error thrown while executing SetDefaultCerts %s", err.Error())
+	}
+	if cs.Properties.CertificateProfile.GMSAWebhookCertificate == "" || cs.Properties.CertificateProfile.GMSAWebhookCertificate == certificate {
+		t.Error("expected SetDefaultCerts to sign a new gmsa-webhook serving certificate with a new CA")
+	}
+}
+
 func TestProxyModeDefaults(t *testing.T) {
 	// Test that default is what we expect
 	mockCS := getMockBaseContainerService("1.10.12")
@@ -6168,6 +6285,104 @@ func TestSetCSIProxyDefaults(t *testing.T) {
 	}
 }
 
+func TestSetWindowsGMSADefaults(t *testing.T) {
+	cases := []struct {
+		name                 string
+		gmsa                 *WindowsGMSAProfile
+		expectedMode         string
+		expectedCCGPluginURL string
+	}{
+		{
+			name:                 "mode defaults to domain join",
+			gmsa:                 &WindowsGMSAProfile{DomainName: "contoso.com"},
+			expectedMode:         WindowsGMSAModeDomainJoin,
+			expectedCCGPluginURL: "",
+		},
+		{
+			name:                 "CCG plugin URL is not defaulted",
+			gmsa:                 &WindowsGMSAProfile{Mode: WindowsGMSAModeCCGPlugin, DomainName: "contoso.com"},
+			expectedMode:         WindowsGMSAModeCCGPlugin,
+			expectedCCGPluginURL: "",
+		},
+		{
+			name:                 "CCG plugin URL is honored",
+			gmsa:                 &WindowsGMSAProfile{Mode: WindowsGMSAModeCCGPlugin, DomainName: "contoso.com", CCGPluginURL: "https://some/plugin.zip"},
+			expectedMode:         WindowsGMSAModeCCGPlugin,
+			expectedCCGPluginURL: "https://some/plugin.zip",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			cs := getMockBaseContainerService("1.18.0")
+			cs.Properties.WindowsProfile = &WindowsProfile{GMSA: c.gmsa}
+			cs.setWindowsGMSADefaults()
+			if cs.Properties.WindowsProfile.GMSA.Mode != c.expectedMode {
+				t.Errorf("expected gmsa mode to be %s, but got %s", c.expectedMode, cs.Properties.WindowsProfile.GMSA.Mode)
+			}
+			if cs.Properties.WindowsProfile.GMSA.CCGPluginURL != c.expectedCCGPluginURL {
+				t.Errorf("expected ccgPluginURL to be %s, but got %s", c.expectedCCGPluginURL, cs.Properties.WindowsProfile.GMSA.CCGPluginURL)
+			}
+		})
+	}
+}
+
+func TestSetWindowsAgentPoolImageDefaults(t *testing.T) {
+	cases := []struct {
+		name            string
+		pool            *AgentPoolProfile
+		expectedVersion string
+	}{
+		{
+			name:            "pool without override",
+			pool:            &AgentPoolProfile{Name: "win", OSType: Windows},
+			expectedVersion: "",
+		},
+		{
+			name:            "pool overriding the sku defaults to latest",
+			pool:            &AgentPoolProfile{Name: "win", OSType: Windows, WindowsSku: "2022-datacenter-core-smalldisk"},
+			expectedVersion: "latest",
+		},
+		{
+			name: "pool overriding the image with an aks-engine VHD defaults to its version",
+			pool: &AgentPoolProfile{
+				Name:             "win",
+				OSType:           Windows,
+				WindowsPublisher: AKSWindowsServer2019ContainerDOSImageConfig.ImagePublisher,
+				WindowsOffer:     AKSWindowsServer2019ContainerDOSImageConfig.ImageOffer,
+				WindowsSku:       AKSWindowsServer2019ContainerDOSImageConfig.ImageSku,
+			},
+			expectedVersion: AKSWindowsServer2019ContainerDOSImageConfig.ImageVersion,
+		},
+		{
+			name:            "pool image version is honored",
+			pool:            &AgentPoolProfile{Name: "win", OSType: Windows, WindowsSku: "2022-datacenter-core-smalldisk", WindowsImageVersion: "20348.1.2"},
+			expectedVersion: "20348.1.2",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			cs := getMockBaseContainerService("1.18.0")
+			cs.Properties.WindowsProfile = &WindowsProfile{
+				WindowsPublisher: AKSWindowsServer2019OSImageConfig.ImagePublisher,
+				WindowsOffer:     AKSWindowsServer2019OSImageConfig.ImageOffer,
+				WindowsSku:       AKSWindowsServer2019OSImageConfig.ImageSku,
+				ImageVersion:     AKSWindowsServer2019OSImageConfig.ImageVersion,
+			}
+			cs.Properties.AgentPoolProfiles = []*AgentPoolProfile{c.pool}
+			cs.setWindowsAgentPoolImageDefaults()
+			if c.pool.WindowsImageVersion != c.expectedVersion {
+				t.Errorf("expected windowsImageVersion to be %q, but got %q", c.expectedVersion, c.pool.WindowsImageVersion)
+			}
+		})
+	}
+}
+
 func ExampleContainerService_setOrchestratorDefaults() {
 	log.SetOutput(os.Stdout)
 	log.SetFormatter(&log.TextFormatter{
@@ -0,0 +1,50 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package api
+
+//go:generate go run ../../hack/egressfqdns ../../docs/topics/features.md
+
+import (
+	"fmt"
+	"strings"
+)
+
+// egressFQDNsDocsLocations are the locations whose clouds are listed in the required egress FQDNs documentation
+var egressFQDNsDocsLocations = []string{"westus", "chinaeast", "germanycentral", "usgovvirginia"}
+
+// GetRequiredEgressFQDNsMarkdownTable returns the markdown table of the FQDNs that the nodes of a Linux cluster
+// must reach in each cloud, as documented in docs/topics/features.md.
+func GetRequiredEgressFQDNsMarkdownTable() string {
+	rows := [][]string{{"Cloud", "FQDNs"}}
+	for _, location := range egressFQDNsDocsLocations {
+		cs := &ContainerService{Location: location, Properties: &Properties{}}
+		rows = append(rows, []string{cs.GetCloudSpecConfig().CloudName, strings.Join(cs.GetRequiredEgressFQDNs(), ", ")})
+	}
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+
+	var table strings.Builder
+	writeRow := func(cells []string) {
+		for i, cell := range cells {
+			fmt.Fprintf(&table, "| %-*s ", widths[i], cell)
+		}
+		table.WriteString("|\n")
+	}
+	writeRow(rows[0])
+	separators := make([]string, len(widths))
+	for i, width := range widths {
+		separators[i] = strings.Repeat("-", width)
+	}
+	writeRow(separators)
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return table.String()
+}
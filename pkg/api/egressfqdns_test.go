@@ -0,0 +1,34 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package api
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestGetRequiredEgressFQDNsMarkdownTable(t *testing.T) {
+	table := GetRequiredEgressFQDNsMarkdownTable()
+	lines := strings.Split(strings.TrimSuffix(table, "\n"), "\n")
+	if len(lines) != len(egressFQDNsDocsLocations)+2 {
+		t.Fatalf("expected a header, a separator and %d rows, got:\n%s", len(egressFQDNsDocsLocations), table)
+	}
+	for _, line := range lines {
+		if len(line) != len(lines[0]) {
+			t.Errorf("expected every line of the table to be padded to %d characters, got %q", len(lines[0]), line)
+		}
+	}
+	if !strings.HasPrefix(lines[3], "| AzureChinaCloud ") || !strings.Contains(lines[3], "mcr.azk8s.cn") {
+		t.Errorf("expected the AzureChinaCloud row to list mcr.azk8s.cn, got %q", lines[3])
+	}
+
+	doc, err := ioutil.ReadFile("../../docs/topics/features.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(doc), "<!-- egress-fqdns:begin -->\n"+table+"<!-- egress-fqdns:end -->\n") {
+		t.Errorf("the required egress FQDNs table in docs/topics/features.md is out of date, run go generate ./pkg/api")
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+const (
+	// ImageDriftKindAddon is the kind of the image drift of an addon container
+	ImageDriftKindAddon = "addon"
+	// ImageDriftKindComponent is the kind of the image drift of a component container
+	ImageDriftKindComponent = "component"
+)
+
+// ImageDrift is an addon or component container whose configured image differs from the default image
+// for the current Kubernetes version, i.e. an image pinned in the API model
+type ImageDrift struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Container string `json:"container"`
+	// Image is the configured image
+	Image string `json:"image"`
+	// Default is the default image for the target Kubernetes version
+	Default string `json:"default"`
+	// Kept is true if the image is set by a custom image property of the Kubernetes config, such as customKubeAPIServerImage.
+	// Upgrade deploys the default image but keeps the property, so that later operations deploy the pinned image again.
+	Kept bool `json:"kept"`
+	// Incompatible explains why the configured image does not work with the target Kubernetes version
+	Incompatible string `json:"incompatible,omitempty"`
+}
+
+// GetImageDrift returns the addon and component containers whose configured image differs from its default,
+// compared with the default images for targetVersion. Addons and components whose manifest is provided
+// in the API model, and containers without a default image, are not reported.
+func (cs *ContainerService) GetImageDrift(targetVersion string) []ImageDrift {
+	if cs.Properties == nil || cs.Properties.OrchestratorProfile == nil || cs.Properties.OrchestratorProfile.KubernetesConfig == nil {
+		return nil
+	}
+	o := cs.Properties.OrchestratorProfile
+	currentDefaults := cs.getDefaultContainerImages(o.OrchestratorVersion, false)
+	targetDefaults := cs.getDefaultContainerImages(targetVersion, false)
+	customImages := cs.getDefaultContainerImages(targetVersion, true)
+
+	var drift []ImageDrift
+	check := func(kind, name, container, image string) {
+		key := getContainerImageKey(kind, name, container)
+		current, ok := currentDefaults[key]
+		if !ok || image == "" || image == current {
+			return
+		}
+		d := ImageDrift{
+			Kind:      kind,
+			Name:      name,
+			Container: container,
+			Image:     image,
+			Default:   targetDefaults[key],
+			Kept:      customImages[key] == image && customImages[key] != targetDefaults[key],
+		}
+		d.Incompatible = getImageIncompatibility(image, d.Default, targetVersion)
+		drift = append(drift, d)
+	}
+	for _, component := range o.KubernetesConfig.Components {
+		if component.IsEnabled() && component.Data == "" {
+			for _, container := range component.Containers {
+				check(ImageDriftKindComponent, component.Name, container.Name, container.Image)
+			}
+		}
+	}
+	for _, addon := range o.KubernetesConfig.Addons {
+		if addon.IsEnabled() && addon.Data == "" {
+			for _, container := range addon.Containers {
+				check(ImageDriftKindAddon, addon.Name, container.Name, container.Image)
+			}
+		}
+	}
+
+	sort.SliceStable(drift, func(i, j int) bool {
+		if drift[i].Kind != drift[j].Kind {
+			return drift[i].Kind < drift[j].Kind
+		}
+		if drift[i].Name != drift[j].Name {
+			return drift[i].Name < drift[j].Name
+		}
+		return drift[i].Container < drift[j].Container
+	})
+	return drift
+}
+
+// ResetImageDrift clears the pinned images of drift, and the custom component images of the Kubernetes config
+// that pin them, so that the next operation on the cluster sets them to their defaults.
+// The images of the containers that are not in drift are left alone.
+func (cs *ContainerService) ResetImageDrift(drift []ImageDrift) {
+	if cs.Properties == nil || cs.Properties.OrchestratorProfile == nil || cs.Properties.OrchestratorProfile.KubernetesConfig == nil {
+		return
+	}
+	k := cs.Properties.OrchestratorProfile.KubernetesConfig
+	pinned := map[string]bool{}
+	pinnedImages := map[string]bool{}
+	for _, d := range drift {
+		pinned[getContainerImageKey(d.Kind, d.Name, d.Container)] = true
+		pinnedImages[d.Image] = true
+	}
+	for i := range k.Components {
+		for j := range k.Components[i].Containers {
+			if pinned[getContainerImageKey(ImageDriftKindComponent, k.Components[i].Name, k.Components[i].Containers[j].Name)] {
+				k.Components[i].Containers[j].Image = ""
+			}
+		}
+	}
+	for i := range k.Addons {
+		for j := range k.Addons[i].Containers {
+			if pinned[getContainerImageKey(ImageDriftKindAddon, k.Addons[i].Name, k.Addons[i].Containers[j].Name)] {
+				k.Addons[i].Containers[j].Image = ""
+			}
+		}
+	}
+	// the custom images are deployed as is, a custom image pins a drifted container if it is its image
+	for _, image := range []*string{&k.CustomHyperkubeImage, &k.CustomKubeAPIServerImage, &k.CustomKubeControllerManagerImage,
+		&k.CustomKubeSchedulerImage, &k.CustomKubeProxyImage, &k.CustomCcmImage} {
+		if pinnedImages[*image] {
+			*image = ""
+		}
+	}
+}
+
+// getDefaultContainerImages returns the default addon and component images for version, keyed by getContainerImageKey.
+// The custom component images of the Kubernetes config are honored if keepCustomImages is true.
+func (cs *ContainerService) getDefaultContainerImages(version string, keepCustomImages bool) map[string]string {
+	// default a copy of the orchestrator profile without addons and components, to leave cs untouched
+	k := *cs.Properties.OrchestratorProfile.KubernetesConfig
+	k.Addons = nil
+	k.Components = nil
+	if !keepCustomImages {
+		k.CustomHyperkubeImage = ""
+		k.CustomKubeAPIServerImage = ""
+		k.CustomKubeControllerManagerImage = ""
+		k.CustomKubeSchedulerImage = ""
+		k.CustomKubeProxyImage = ""
+		k.CustomCcmImage = ""
+	}
+	o := *cs.Properties.OrchestratorProfile
+	o.OrchestratorVersion = version
+	o.KubernetesConfig = &k
+	p := *cs.Properties
+	p.OrchestratorProfile = &o
+	defaults := &ContainerService{Location: cs.Location, Properties: &p}
+	defaults.setComponentsConfig(false)
+	defaults.setAddonsConfig(false)
+
+	images := map[string]string{}
+	for _, component := range k.Components {
+		for _, container := range component.Containers {
+			images[getContainerImageKey(ImageDriftKindComponent, component.Name, container.Name)] = container.Image
+		}
+	}
+	for _, addon := range k.Addons {
+		for _, container := range addon.Containers {
+			images[getContainerImageKey(ImageDriftKindAddon, addon.Name, container.Name)] = container.Image
+		}
+	}
+	return images
+}
+
+func getContainerImageKey(kind, name, container string) string {
+	return kind + "/" + name + "/" + container
+}
+
+// getImageIncompatibility returns why image does not work with Kubernetes version, or an empty string.
+// An image whose default is released with each Kubernetes minor version, such as kube-apiserver,
+// must have the same minor version as Kubernetes.
+func getImageIncompatibility(image, defaultImage, version string) string {
+	k8sVersion, err := semver.Make(version)
+	if err != nil {
+		return ""
+	}
+	defaultVersion, ok := getImageTagVersion(defaultImage)
+	if !ok || defaultVersion.Major != k8sVersion.Major || defaultVersion.Minor != k8sVersion.Minor {
+		return ""
+	}
+	imageVersion, ok := getImageTagVersion(image)
+	if !ok {
+		return ""
+	}
+	if imageVersion.Major != k8sVersion.Major || imageVersion.Minor != k8sVersion.Minor {
+		return fmt.Sprintf("image version %d.%d does not match Kubernetes version %d.%d",
+			imageVersion.Major, imageVersion.Minor, k8sVersion.Major, k8sVersion.Minor)
+	}
+	return ""
+}
+
+// getImageTagVersion parses the tag of an image reference as a semantic version, e.g. v1.23.17-azs
+func getImageTagVersion(image string) (semver.Version, bool) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return semver.Version{}, false
+	}
+	v, err := semver.ParseTolerant(image[i+1:])
+	if err != nil {
+		return semver.Version{}, false
+	}
+	return v, true
+}
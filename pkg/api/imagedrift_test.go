@@ -0,0 +1,107 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/api/common"
+)
+
+func getMockImageDriftContainerService(t *testing.T) *ContainerService {
+	cs := CreateMockContainerService("testcluster", "1.22.17", 1, 1, false)
+	cs.Location = "westus2"
+	k := cs.Properties.OrchestratorProfile.KubernetesConfig
+	k.CustomKubeAPIServerImage = "example.com/kube-apiserver:v1.22.1"
+	k.CustomKubeProxyImage = "example.com/kube-proxy:v1.23.1"
+	if _, err := cs.SetPropertiesDefaults(PropertiesDefaultsParams{IsScale: false, IsUpgrade: false, PkiKeySize: 2048}); err != nil {
+		t.Fatalf("unexpected error setting defaults: %s", err)
+	}
+	i := getAddonsIndexByName(k.Addons, common.CoreDNSAddonName)
+	k.Addons[i].Containers[k.Addons[i].GetAddonContainersIndexByName(common.CoreDNSAddonName)].Image = "example.com/coredns:1.9.0"
+	return cs
+}
+
+func TestGetImageDrift(t *testing.T) {
+	cs := getMockImageDriftContainerService(t)
+
+	drift := cs.GetImageDrift("1.23.17")
+	if len(drift) != 3 {
+		t.Fatalf("expected 3 pinned images, got %+v", drift)
+	}
+	expected := []struct {
+		kind, name, image string
+		kept              bool
+		incompatible      bool
+	}{
+		{ImageDriftKindAddon, common.CoreDNSAddonName, "example.com/coredns:1.9.0", false, false},
+		{ImageDriftKindAddon, common.KubeProxyAddonName, "example.com/kube-proxy:v1.23.1", true, false},
+		{ImageDriftKindComponent, common.APIServerComponentName, "example.com/kube-apiserver:v1.22.1", true, true},
+	}
+	for i, e := range expected {
+		d := drift[i]
+		if d.Kind != e.kind || d.Name != e.name || d.Image != e.image || d.Kept != e.kept || (d.Incompatible != "") != e.incompatible {
+			t.Errorf("expected %+v, got %+v", e, d)
+		}
+		if d.Default == "" || d.Default == d.Image {
+			t.Errorf("expected a default image for %s, got %s", d.Name, d.Default)
+		}
+	}
+	// cs is still at 1.22.17, the reported default is the one of the target version
+	if drift[2].Default == getComponentDefaultContainerImage(common.APIServerComponentName, cs) {
+		t.Errorf("expected the kube-apiserver default for 1.23.17, got %s", drift[2].Default)
+	}
+
+	if drift := cs.GetImageDrift("1.22.17"); len(drift) != 3 {
+		t.Errorf("expected the same pinned images without upgrade, got %+v", drift)
+	}
+
+	k := cs.Properties.OrchestratorProfile.KubernetesConfig
+	k.CustomKubeSchedulerImage = "example.com/kube-scheduler:v1.22.17"
+	cs.ResetImageDrift(drift[2:])
+	if k.CustomKubeAPIServerImage != "" {
+		t.Errorf("expected the drifted custom kube-apiserver image to be reset")
+	}
+	if k.CustomKubeProxyImage == "" || k.CustomKubeSchedulerImage == "" {
+		t.Errorf("expected the custom images that are not reset to be kept, got %q %q", k.CustomKubeProxyImage, k.CustomKubeSchedulerImage)
+	}
+	i := getAddonsIndexByName(k.Addons, common.CoreDNSAddonName)
+	if image := k.Addons[i].Containers[k.Addons[i].GetAddonContainersIndexByName(common.CoreDNSAddonName)].Image; image != "example.com/coredns:1.9.0" {
+		t.Errorf("expected the coredns image that is not reset to be kept, got %s", image)
+	}
+
+	cs.ResetImageDrift(drift)
+	if k.CustomKubeProxyImage != "" {
+		t.Errorf("expected the custom kube-proxy image to be reset")
+	}
+	if k.CustomKubeSchedulerImage == "" {
+		t.Errorf("expected the custom kube-scheduler image that does not drift to be kept")
+	}
+	k.CustomKubeSchedulerImage = ""
+	if _, err := cs.SetPropertiesDefaults(PropertiesDefaultsParams{IsScale: true, IsUpgrade: false, PkiKeySize: 2048}); err != nil {
+		t.Fatalf("unexpected error setting defaults: %s", err)
+	}
+	if drift := cs.GetImageDrift("1.22.17"); len(drift) != 0 {
+		t.Errorf("expected no pinned images after reset, got %+v", drift)
+	}
+}
+
+func TestGetImageIncompatibility(t *testing.T) {
+	cases := []struct {
+		image, defaultImage, version string
+		incompatible                 bool
+	}{
+		{"example.com/kube-apiserver:v1.22.1", "mcr.microsoft.com/oss/kubernetes/kube-apiserver:v1.23.17", "1.23.17", true},
+		{"example.com/kube-apiserver:v1.23.1-custom", "mcr.microsoft.com/oss/kubernetes/kube-apiserver:v1.23.17", "1.23.17", false},
+		{"example.com/kube-apiserver@sha256:abc", "mcr.microsoft.com/oss/kubernetes/kube-apiserver:v1.23.17", "1.23.17", false},
+		{"localhost:5000/kube-apiserver", "mcr.microsoft.com/oss/kubernetes/kube-apiserver:v1.23.17", "1.23.17", false},
+		// coredns is not released with Kubernetes
+		{"example.com/coredns:1.9.0", "mcr.microsoft.com/oss/kubernetes/coredns:1.8.6", "1.23.17", false},
+	}
+	for _, c := range cases {
+		if actual := getImageIncompatibility(c.image, c.defaultImage, c.version); (actual != "") != c.incompatible {
+			t.Errorf("getImageIncompatibility(%s, %s, %s): expected incompatible %t, got %q", c.image, c.defaultImage, c.version, c.incompatible, actual)
+		}
+	}
+}
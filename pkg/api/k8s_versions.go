@@ -61,6 +61,7 @@ const (
 	clusterProportionalAutoscalerImageReference       string = "mcr.microsoft.com/oss/kubernetes/autoscaler/cluster-proportional-autoscaler:1.8.5"
 	azureArcOnboardingImageReference                  string = "arck8sonboarding.azurecr.io/arck8sonboarding:v0.1.0"
 	azureKMSProviderImageReference                    string = "k8s/kms/keyvault:v0.0.10"
+	gmsaWebhookImageReference                         string = "oss/kubernetes-sigs/windows-gmsa-webhook:v0.4.0"
 )
 
 var kubernetesImageBaseDefaultImages = map[string]map[string]string{
@@ -15,6 +15,7 @@ import (
 	"strings"
 
 	"github.com/Azure/aks-engine/pkg/api/common"
+	v1 "github.com/Azure/aks-engine/pkg/api/v1"
 	"github.com/Azure/aks-engine/pkg/api/vlabs"
 	"github.com/Azure/aks-engine/pkg/helpers"
 	"github.com/Azure/go-autorest/autorest/azure"
@@ -67,6 +68,21 @@ type Properties struct {
 	FeatureFlags            *FeatureFlags            `json:"featureFlags,omitempty"`
 	CustomCloudProfile      *CustomCloudProfile      `json:"customCloudProfile,omitempty"`
 	TelemetryProfile        *TelemetryProfile        `json:"telemetryProfile,omitempty"`
+	NetworkSecurityRules    []NetworkSecurityRule    `json:"networkSecurityRules,omitempty"`
+}
+
+// NetworkSecurityRule is a custom security rule added to the cluster or agent pool network security group
+type NetworkSecurityRule struct {
+	Name                       string   `json:"name"`
+	Description                string   `json:"description,omitempty"`
+	Priority                   int32    `json:"priority"`
+	Direction                  string   `json:"direction"`
+	Access                     string   `json:"access"`
+	Protocol                   string   `json:"protocol"`
+	SourceAddressPrefixes      []string `json:"sourceAddressPrefixes,omitempty"`
+	SourcePortRange            string   `json:"sourcePortRange,omitempty"`
+	DestinationAddressPrefixes []string `json:"destinationAddressPrefixes,omitempty"`
+	DestinationPortRanges      []string `json:"destinationPortRanges,omitempty"`
 }
 
 // FeatureFlags defines feature-flag restricted functionality
@@ -125,6 +141,10 @@ type CertificateProfile struct {
 	EtcdPeerCertificates []string `json:"etcdPeerCertificates,omitempty" conform:"redact"`
 	// EtcdPeerPrivateKeys is list of etcd peer private keys, and signed by the CA
 	EtcdPeerPrivateKeys []string `json:"etcdPeerPrivateKeys,omitempty" conform:"redact"`
+	// GMSAWebhookCertificate is the serving certificate of the gmsa-webhook addon, and signed by the CA
+	GMSAWebhookCertificate string `json:"gmsaWebhookCertificate,omitempty" conform:"redact"`
+	// GMSAWebhookPrivateKey is the serving private key of the gmsa-webhook addon, and signed by the CA
+	GMSAWebhookPrivateKey string `json:"gmsaWebhookPrivateKey,omitempty" conform:"redact"`
 }
 
 // LinuxProfile represents the linux parameters passed to the cluster
@@ -170,27 +190,40 @@ const (
 
 // WindowsProfile represents the windows parameters passed to the cluster
 type WindowsProfile struct {
-	AdminUsername                 string            `json:"adminUsername"`
-	AdminPassword                 string            `json:"adminPassword" conform:"redact"`
-	CSIProxyURL                   string            `json:"csiProxyURL,omitempty"`
-	EnableCSIProxy                *bool             `json:"enableCSIProxy,omitempty"`
-	ImageRef                      *ImageReference   `json:"imageReference,omitempty"`
-	ImageVersion                  string            `json:"imageVersion"`
-	ProvisioningScriptsPackageURL string            `json:"provisioningScriptsPackageURL,omitempty"`
-	WindowsImageSourceURL         string            `json:"windowsImageSourceURL"`
-	WindowsPublisher              string            `json:"windowsPublisher"`
-	WindowsOffer                  string            `json:"windowsOffer"`
-	WindowsSku                    string            `json:"windowsSku"`
-	WindowsDockerVersion          string            `json:"windowsDockerVersion"`
-	Secrets                       []KeyVaultSecrets `json:"secrets,omitempty"`
-	SSHEnabled                    *bool             `json:"sshEnabled,omitempty"`
-	EnableAutomaticUpdates        *bool             `json:"enableAutomaticUpdates,omitempty"`
-	IsCredentialAutoGenerated     *bool             `json:"isCredentialAutoGenerated,omitempty"`
-	EnableAHUB                    *bool             `json:"enableAHUB,omitempty"`
-	WindowsPauseImageURL          string            `json:"windowsPauseImageURL"`
-	AlwaysPullWindowsPauseImage   *bool             `json:"alwaysPullWindowsPauseImage,omitempty"`
-	WindowsRuntimes               *WindowsRuntimes  `json:"windowsRuntimes,omitempty"`
-	WindowsSecureTLSEnabled       *bool             `json:"windowsSecureTLSEnabled,omitempty"`
+	AdminUsername                 string              `json:"adminUsername"`
+	AdminPassword                 string              `json:"adminPassword" conform:"redact"`
+	CSIProxyURL                   string              `json:"csiProxyURL,omitempty"`
+	EnableCSIProxy                *bool               `json:"enableCSIProxy,omitempty"`
+	ImageRef                      *ImageReference     `json:"imageReference,omitempty"`
+	ImageVersion                  string              `json:"imageVersion"`
+	ProvisioningScriptsPackageURL string              `json:"provisioningScriptsPackageURL,omitempty"`
+	WindowsImageSourceURL         string              `json:"windowsImageSourceURL"`
+	WindowsPublisher              string              `json:"windowsPublisher"`
+	WindowsOffer                  string              `json:"windowsOffer"`
+	WindowsSku                    string              `json:"windowsSku"`
+	WindowsDockerVersion          string              `json:"windowsDockerVersion"`
+	Secrets                       []KeyVaultSecrets   `json:"secrets,omitempty"`
+	SSHEnabled                    *bool               `json:"sshEnabled,omitempty"`
+	EnableAutomaticUpdates        *bool               `json:"enableAutomaticUpdates,omitempty"`
+	IsCredentialAutoGenerated     *bool               `json:"isCredentialAutoGenerated,omitempty"`
+	EnableAHUB                    *bool               `json:"enableAHUB,omitempty"`
+	WindowsPauseImageURL          string              `json:"windowsPauseImageURL"`
+	AlwaysPullWindowsPauseImage   *bool               `json:"alwaysPullWindowsPauseImage,omitempty"`
+	WindowsRuntimes               *WindowsRuntimes    `json:"windowsRuntimes,omitempty"`
+	WindowsSecureTLSEnabled       *bool               `json:"windowsSecureTLSEnabled,omitempty"`
+	GMSA                          *WindowsGMSAProfile `json:"gmsa,omitempty"`
+}
+
+// WindowsGMSAProfile configures group managed service accounts (gMSA) for the Windows containers of the cluster
+type WindowsGMSAProfile struct {
+	Mode                                string             `json:"mode,omitempty"`
+	DomainName                          string             `json:"domainName,omitempty"`
+	DomainJoinUser                      string             `json:"domainJoinUser,omitempty"`
+	DomainJoinPassword                  string             `json:"domainJoinPassword,omitempty" conform:"redact"`
+	DomainJoinPasswordKeyvaultSecretRef *KeyvaultSecretRef `json:"domainJoinPasswordKeyvaultSecretRef,omitempty"`
+	OrganizationalUnit                  string             `json:"organizationalUnit,omitempty"`
+	CCGPluginURL                        string             `json:"ccgPluginURL,omitempty"`
+	CCGPluginCLSID                      string             `json:"ccgPluginCLSID,omitempty"`
 }
 
 // WindowsRuntimes configures containerd runtimes that are available on the windows nodes
@@ -270,6 +303,25 @@ type KubernetesAddon struct {
 	Config     map[string]string         `json:"config,omitempty"`
 	Pools      []AddonNodePoolsConfig    `json:"pools,omitempty"`
 	Data       string                    `json:"data,omitempty"`
+	Source     *AddonSource              `json:"source,omitempty"`
+}
+
+// AddonSource defines where the manifest of a user-defined custom addon comes from
+type AddonSource struct {
+	Path      string          `json:"path,omitempty"`
+	URL       string          `json:"url,omitempty"`
+	SHA256    string          `json:"sha256,omitempty"`
+	HelmChart *AddonHelmChart `json:"helmChart,omitempty"`
+	Template  bool            `json:"template,omitempty"`
+}
+
+// AddonHelmChart defines a Helm chart that is rendered offline into a custom addon manifest
+type AddonHelmChart struct {
+	Chart      string `json:"chart,omitempty"`
+	Version    string `json:"version,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	ValuesFile string `json:"valuesFile,omitempty"`
 }
 
 // IsEnabled returns true if the addon is enabled
@@ -280,6 +332,11 @@ func (a *KubernetesAddon) IsEnabled() bool {
 	return *a.Enabled
 }
 
+// IsCustom returns true if the addon is a user-defined addon sourced from a manifest or a Helm chart
+func (a *KubernetesAddon) IsCustom() bool {
+	return a.Source != nil
+}
+
 // IsDisabled returns true if the addon is explicitly disabled
 func (a *KubernetesAddon) IsDisabled() bool {
 	if a.Enabled == nil {
@@ -343,6 +400,13 @@ func (c KubernetesComponent) GetContainersIndexByName(containerName string) int
 	return -1
 }
 
+// NATGatewayProfile defines the NAT gateway used for cluster egress
+type NATGatewayProfile struct {
+	ID                   string `json:"id,omitempty"`
+	IdleTimeoutInMinutes int32  `json:"idleTimeoutInMinutes,omitempty"`
+	PublicIPPrefixCount  int    `json:"publicIPPrefixCount,omitempty"`
+}
+
 // PrivateCluster defines the configuration for a private cluster
 type PrivateCluster struct {
 	Enabled                *bool                  `json:"enabled,omitempty"`
@@ -471,6 +535,8 @@ type KubernetesConfig struct {
 	AzureCNIVersion                     string                `json:"azureCNIVersion,omitempty"`
 	AzureCNIURLLinux                    string                `json:"azureCNIURLLinux,omitempty"`
 	AzureCNIURLWindows                  string                `json:"azureCNIURLWindows,omitempty"`
+	EtcdDownloadURLBase                 string                `json:"etcdDownloadURLBase,omitempty"`
+	CNIPluginsURL                       string                `json:"cniPluginsURL,omitempty"`
 	KeyVaultSku                         string                `json:"keyVaultSku,omitempty"`
 	MaximumLoadBalancerRuleCount        int                   `json:"maximumLoadBalancerRuleCount,omitempty"`
 	ProxyMode                           KubeProxyMode         `json:"kubeProxyMode,omitempty"`
@@ -479,6 +545,9 @@ type KubernetesConfig struct {
 	MicrosoftAptRepositoryURL           string                `json:"microsoftAptRepositoryURL,omitempty"`
 	EnableMultipleStandardLoadBalancers *bool                 `json:"enableMultipleStandardLoadBalancers,omitempty"`
 	Tags                                string                `json:"tags,omitempty"`
+	OutboundType                        string                `json:"outboundType,omitempty"`
+	RouteTableID                        string                `json:"routeTableID,omitempty"`
+	NATGatewayProfile                   *NATGatewayProfile    `json:"natGatewayProfile,omitempty"`
 }
 
 // CustomFile has source as the full absolute source path to a file and dest
@@ -612,8 +681,17 @@ type AgentPoolProfile struct {
 	ProximityPlacementGroupID           string               `json:"proximityPlacementGroupID,omitempty"`
 	OSDiskCachingType                   string               `json:"osDiskCachingType,omitempty"`
 	DataDiskCachingType                 string               `json:"dataDiskCachingType,omitempty"`
+	WindowsPublisher                    string               `json:"windowsPublisher,omitempty"`
+	WindowsOffer                        string               `json:"windowsOffer,omitempty"`
+	WindowsSku                          string               `json:"windowsSku,omitempty"`
+	WindowsImageVersion                 string               `json:"windowsImageVersion,omitempty"`
+	WindowsDockerVersion                string               `json:"windowsDockerVersion,omitempty"`
+	HyperVGeneration                    string               `json:"hyperVGeneration,omitempty"`
+	Architecture                        string               `json:"architecture,omitempty"`
 	// VMSSName is a read-only field; its value will be computed during template generation
 	VMSSName string `json:"vmssName,omitempty"`
+	// NetworkSecurityRules are added to a network security group dedicated to this pool
+	NetworkSecurityRules []NetworkSecurityRule `json:"networkSecurityRules,omitempty"`
 }
 
 // AgentPoolProfileRole represents an agent role
@@ -699,6 +777,12 @@ type VlabsARMContainerService struct {
 	*vlabs.ContainerService
 }
 
+// V1ARMContainerService is the type we read and write from file for the v1 api version
+type V1ARMContainerService struct {
+	TypeMeta
+	*v1.ContainerService
+}
+
 // AzureStackMetadataEndpoints is the type for Azure Stack metadata endpoints
 type AzureStackMetadataEndpoints struct {
 	GalleryEndpoint string                            `json:"galleryEndpoint,omitempty"`
@@ -734,6 +818,16 @@ type TelemetryProfile struct {
 	ApplicationInsightsKey string `json:"applicationInsightsKey,omitempty"`
 }
 
+// HasArm64 returns true if the cluster contains arm64 agent pools
+func (p *Properties) HasArm64() bool {
+	for _, agentPoolProfile := range p.AgentPoolProfiles {
+		if agentPoolProfile.IsArm64() {
+			return true
+		}
+	}
+	return false
+}
+
 // HasFlatcar returns true if the cluster contains flatcar nodes
 func (p *Properties) HasFlatcar() bool {
 	for _, agentPoolProfile := range p.AgentPoolProfiles {
@@ -754,6 +848,17 @@ func (p *Properties) HasWindows() bool {
 	return false
 }
 
+// HasAgentPoolNetworkSecurityGroups returns true if an agent pool has a network security group of its own,
+// the cluster network security group is then attached to network interfaces rather than to the shared subnets
+func (p *Properties) HasAgentPoolNetworkSecurityGroups() bool {
+	for _, agentPoolProfile := range p.AgentPoolProfiles {
+		if agentPoolProfile.HasNetworkSecurityGroup() {
+			return true
+		}
+	}
+	return false
+}
+
 // HasManagedDisks returns true if the cluster contains Managed Disks
 func (p *Properties) HasManagedDisks() bool {
 	if p.MasterProfile != nil && p.MasterProfile.StorageProfile == ManagedDisks {
@@ -916,14 +1021,51 @@ func (p *Properties) GetMasterVMPrefix() string {
 
 // GetRouteTableName returns the route table name of the cluster.
 func (p *Properties) GetRouteTableName() string {
+	if p.IsUserDefinedRouting() {
+		return p.OrchestratorProfile.KubernetesConfig.getRouteTableIDSegment(DefaultRouteTableNameResourceSegmentIndex)
+	}
 	return p.GetMasterVMPrefix() + "routetable"
 }
 
+// GetRouteTableResourceGroupName returns the resource group of a user-provided route table,
+// or an empty string when aks-engine creates the route table in the cluster resource group.
+func (p *Properties) GetRouteTableResourceGroupName() string {
+	if p.IsUserDefinedRouting() {
+		return p.OrchestratorProfile.KubernetesConfig.getRouteTableIDSegment(DefaultRouteTableResourceGroupSegmentIndex)
+	}
+	return ""
+}
+
+// IsUserDefinedRouting returns true if cluster egress is routed through a user-provided route table
+func (p *Properties) IsUserDefinedRouting() bool {
+	return p != nil && p.OrchestratorProfile != nil && p.OrchestratorProfile.KubernetesConfig.IsUserDefinedRouting()
+}
+
+// IsNATGateway returns true if cluster egress goes through a NAT gateway
+func (p *Properties) IsNATGateway() bool {
+	return p != nil && p.OrchestratorProfile != nil && p.OrchestratorProfile.KubernetesConfig.IsNATGateway()
+}
+
+// IsManagedNATGateway returns true if aks-engine creates the NAT gateway used for cluster egress
+func (p *Properties) IsManagedNATGateway() bool {
+	return p != nil && p.OrchestratorProfile != nil && p.OrchestratorProfile.KubernetesConfig.IsManagedNATGateway()
+}
+
+// HasLoadBalancerOutboundRules returns true if cluster egress goes through the outbound rules of the Standard load balancer
+func (p *Properties) HasLoadBalancerOutboundRules() bool {
+	return !p.IsUserDefinedRouting() && !p.IsNATGateway()
+}
+
 // GetNSGName returns the name of the network security group of the cluster.
 func (p *Properties) GetNSGName() string {
 	return p.GetMasterVMPrefix() + "nsg"
 }
 
+// GetAgentPoolNSGName returns the name of the network security group of an agent pool with networkSecurityRules.
+func (p *Properties) GetAgentPoolNSGName(profile *AgentPoolProfile) string {
+	return "k8s-" + profile.Name + "-" + p.GetClusterID() + "-nsg"
+}
+
 // GetPrimaryAvailabilitySetName returns the name of the primary availability set of the cluster
 func (p *Properties) GetPrimaryAvailabilitySetName() string {
 	if len(p.AgentPoolProfiles) > 0 {
@@ -1368,9 +1510,19 @@ func (m *MasterProfile) IsUbuntu2004() bool {
 	}
 }
 
+// IsUbuntu2204 returns true if the master profile distro is based on Ubuntu 22.04
+func (m *MasterProfile) IsUbuntu2204() bool {
+	switch m.Distro {
+	case Ubuntu2204, Ubuntu2204Gen2:
+		return true
+	default:
+		return false
+	}
+}
+
 // IsUbuntu returns true if the master profile distro is any ubuntu distro
 func (m *MasterProfile) IsUbuntu() bool {
-	return m.IsUbuntu1604() || m.IsUbuntu1804() || m.IsUbuntu2004()
+	return m.IsUbuntu1604() || m.IsUbuntu1804() || m.IsUbuntu2004() || m.IsUbuntu2204()
 }
 
 // IsUbuntuNonVHD returns true if the distro uses a base Ubuntu image
@@ -1408,11 +1560,83 @@ func (a *AgentPoolProfile) HasImageGallery() bool {
 	return imageRef != nil && imageRef.IsGalleryImage()
 }
 
+// HasWindowsImageOverride returns true if the Windows agent pool overrides the marketplace image of the WindowsProfile
+func (a *AgentPoolProfile) HasWindowsImageOverride() bool {
+	return a.IsWindows() && (a.WindowsPublisher != "" || a.WindowsOffer != "" || a.WindowsSku != "" || a.WindowsImageVersion != "")
+}
+
+// GetWindowsImageConfig returns the marketplace image of the Windows agent pool, falling back to the image of the WindowsProfile
+func (a *AgentPoolProfile) GetWindowsImageConfig(w *WindowsProfile) AzureOSImageConfig {
+	var imageConfig AzureOSImageConfig
+	if w != nil {
+		imageConfig = AzureOSImageConfig{
+			ImagePublisher: w.WindowsPublisher,
+			ImageOffer:     w.WindowsOffer,
+			ImageSku:       w.GetWindowsSku(),
+			ImageVersion:   w.ImageVersion,
+		}
+	}
+	if a.WindowsPublisher != "" {
+		imageConfig.ImagePublisher = a.WindowsPublisher
+	}
+	if a.WindowsOffer != "" {
+		imageConfig.ImageOffer = a.WindowsOffer
+	}
+	if a.WindowsSku != "" {
+		imageConfig.ImageSku = a.WindowsSku
+	}
+	if a.WindowsImageVersion != "" {
+		imageConfig.ImageVersion = a.WindowsImageVersion
+	}
+	return imageConfig
+}
+
+// GetOSImageConfig returns the marketplace image of the Linux agent pool
+func (a *AgentPoolProfile) GetOSImageConfig(cloudSpecConfig AzureEnvironmentSpecConfig) AzureOSImageConfig {
+	if imageConfig, ok := a.GetOSImageFlavor(); ok {
+		return imageConfig
+	}
+	return cloudSpecConfig.OSImageConfig[a.Distro]
+}
+
+// GetOSImageFlavor returns the Gen2 or Arm64 flavor of the distro image, which is selected
+// from the Hyper-V generation and the CPU architecture of the pool, and false if the pool uses the distro image
+func (a *AgentPoolProfile) GetOSImageFlavor() (AzureOSImageConfig, bool) {
+	if a.IsArm64() {
+		if imageConfig, ok := Arm64OSImageConfig[a.Distro]; ok {
+			return imageConfig, true
+		}
+	}
+	if a.HyperVGeneration == HyperVGenerationV2 {
+		if imageConfig, ok := Gen2OSImageConfig[a.Distro]; ok {
+			return imageConfig, true
+		}
+	}
+	return AzureOSImageConfig{}, false
+}
+
+// GetWindowsDockerVersion gets the docker version of the Windows agent pool, falling back to the docker version of the WindowsProfile
+func (a *AgentPoolProfile) GetWindowsDockerVersion(w *WindowsProfile) string {
+	if a.WindowsDockerVersion != "" {
+		return a.WindowsDockerVersion
+	}
+	if w != nil {
+		return w.GetWindowsDockerVersion()
+	}
+	return KubernetesWindowsDockerVersion
+}
+
 // IsCustomVNET returns true if the customer brought their own VNET
 func (a *AgentPoolProfile) IsCustomVNET() bool {
 	return len(a.VnetSubnetID) > 0
 }
 
+// HasNetworkSecurityGroup returns true if the agent pool has custom network security rules,
+// which are applied through a network security group dedicated to the pool
+func (a *AgentPoolProfile) HasNetworkSecurityGroup() bool {
+	return len(a.NetworkSecurityRules) > 0
+}
+
 // IsWindows returns true if the agent pool is windows
 func (a *AgentPoolProfile) IsWindows() bool {
 	return a.OSType == Windows
@@ -1522,9 +1746,57 @@ func (a *AgentPoolProfile) IsUbuntu2004() bool {
 	return false
 }
 
+// IsUbuntu2204 returns true if the agent pool profile distro is based on Ubuntu 22.04
+func (a *AgentPoolProfile) IsUbuntu2204() bool {
+	if a.OSType != Windows {
+		switch a.Distro {
+		case Ubuntu2204, Ubuntu2204Gen2:
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
 // IsUbuntu returns true if the master profile distro is any ubuntu distro
 func (a *AgentPoolProfile) IsUbuntu() bool {
-	return a.IsUbuntu1604() || a.IsUbuntu1804() || a.IsUbuntu2004()
+	return a.IsUbuntu1604() || a.IsUbuntu1804() || a.IsUbuntu2004() || a.IsUbuntu2204()
+}
+
+// IsArm64 returns true if the agent pool runs on Arm64 VM sizes
+func (a *AgentPoolProfile) IsArm64() bool {
+	return a.Architecture == ArchitectureArm64
+}
+
+// GetArchitecture returns the CPU architecture of the agent pool, which defaults to amd64
+func (a *AgentPoolProfile) GetArchitecture() string {
+	if a.Architecture != "" {
+		return a.Architecture
+	}
+	return ArchitectureAmd64
+}
+
+// IsGen2Distro returns true if the distro is the Gen2 flavor of a distro
+func (a *AgentPoolProfile) IsGen2Distro() bool {
+	switch a.Distro {
+	case Ubuntu1804Gen2, Ubuntu2004Gen2, Ubuntu2204Gen2:
+		return true
+	default:
+		return false
+	}
+}
+
+// GetHyperVGeneration returns the Hyper-V generation of the agent pool VMs, which defaults to
+// V2 for the Gen2 distros and the Arm64 pools and to V1 otherwise
+func (a *AgentPoolProfile) GetHyperVGeneration() string {
+	if a.HyperVGeneration != "" {
+		return a.HyperVGeneration
+	}
+	if a.IsGen2Distro() || a.IsArm64() {
+		return HyperVGenerationV2
+	}
+	return HyperVGenerationV1
 }
 
 // IsUbuntuNonVHD returns true if the distro uses a base Ubuntu image
@@ -1654,6 +1926,29 @@ func (w *WindowsProfile) GetWindowsHypervRuntimeHandlers() string {
 	return ""
 }
 
+// GetWindowsHypervRuntimeHandlerNames returns the containerd handler names of the Hyper-V runtimes
+func (w *WindowsProfile) GetWindowsHypervRuntimeHandlerNames() []string {
+	var names []string
+	if w != nil && w.WindowsRuntimes != nil {
+		for _, h := range w.WindowsRuntimes.HypervRuntimes {
+			names = append(names, KubernetesWindowsHypervRuntimeHandlerPrefix+h.BuildNumber)
+		}
+	}
+	return names
+}
+
+// GetWindowsRuntimeHandlerLabels returns the node labels advertising the Hyper-V runtimes of Windows nodes running containerd
+func (p *Properties) GetWindowsRuntimeHandlerLabels() string {
+	if p.OrchestratorProfile == nil || p.OrchestratorProfile.KubernetesConfig == nil || !p.OrchestratorProfile.KubernetesConfig.NeedsContainerd() {
+		return ""
+	}
+	var buf bytes.Buffer
+	for _, name := range p.WindowsProfile.GetWindowsHypervRuntimeHandlerNames() {
+		buf.WriteString(fmt.Sprintf(",%s%s=true", KubernetesWindowsRuntimeHandlerLabelPrefix, name))
+	}
+	return buf.String()
+}
+
 // GetWindowsSku gets the marketplace sku specified (such as Datacenter-Core-1809-with-Containers-smalldisk) or returns default value
 func (w *WindowsProfile) GetWindowsSku() string {
 	if w.WindowsSku != "" {
@@ -1699,6 +1994,16 @@ func (w *WindowsProfile) HasEnableAHUB() bool {
 	return w.EnableAHUB != nil
 }
 
+// IsGMSAEnabled returns true if group managed service accounts are configured for the Windows containers
+func (w *WindowsProfile) IsGMSAEnabled() bool {
+	return w != nil && w.GMSA != nil
+}
+
+// IsGMSADomainJoinEnabled returns true if the Windows nodes join the Active Directory domain of the gMSA
+func (w *WindowsProfile) IsGMSADomainJoinEnabled() bool {
+	return w.IsGMSAEnabled() && w.GMSA.Mode == WindowsGMSAModeDomainJoin
+}
+
 // HasSecrets returns true if the customer specified secrets to install
 func (l *LinuxProfile) HasSecrets() bool {
 	return len(l.Secrets) > 0
@@ -1852,6 +2157,31 @@ func (k *KubernetesConfig) IsRBACEnabled() bool {
 	return false
 }
 
+// IsUserDefinedRouting checks if the cluster uses the userDefinedRouting outbound type
+func (k *KubernetesConfig) IsUserDefinedRouting() bool {
+	return k != nil && k.OutboundType == OutboundTypeUserDefinedRouting
+}
+
+// getRouteTableIDSegment returns the segment of routeTableID at index, or an empty string if
+// routeTableID is not a route table resource ID
+func (k *KubernetesConfig) getRouteTableIDSegment(index int) string {
+	segments := strings.Split(k.RouteTableID, "/")
+	if len(segments) != DefaultRouteTableNameResourceSegmentIndex+1 {
+		return ""
+	}
+	return segments[index]
+}
+
+// IsNATGateway checks if cluster egress goes through a NAT gateway, managed or user-assigned
+func (k *KubernetesConfig) IsNATGateway() bool {
+	return k != nil && (k.OutboundType == OutboundTypeManagedNATGateway || k.OutboundType == OutboundTypeUserAssignedNATGateway)
+}
+
+// IsManagedNATGateway checks if aks-engine creates the NAT gateway used for cluster egress
+func (k *KubernetesConfig) IsManagedNATGateway() bool {
+	return k != nil && k.OutboundType == OutboundTypeManagedNATGateway
+}
+
 // UserAssignedIDEnabled checks if the user assigned ID is enabled or not.
 func (k *KubernetesConfig) UserAssignedIDEnabled() bool {
 	return to.Bool(k.UseManagedIdentity) && k.UserAssignedID != ""
@@ -2183,6 +2513,22 @@ func (k *KubernetesConfig) GetAzureCNIURLWindows(cloudSpecConfig AzureEnvironmen
 	return cloudSpecConfig.KubernetesSpecConfig.VnetCNIWindowsPluginsDownloadURL
 }
 
+// GetEtcdDownloadURLBase returns the base of the etcd image that the control plane nodes pull the etcd binaries from
+func (k *KubernetesConfig) GetEtcdDownloadURLBase(cloudSpecConfig AzureEnvironmentSpecConfig) string {
+	if k.EtcdDownloadURLBase != "" {
+		return k.EtcdDownloadURLBase
+	}
+	return cloudSpecConfig.KubernetesSpecConfig.EtcdDownloadURLBase
+}
+
+// GetCNIPluginsURL returns the full URL to source the reference CNI plugins from
+func (k *KubernetesConfig) GetCNIPluginsURL(cloudSpecConfig AzureEnvironmentSpecConfig) string {
+	if k.CNIPluginsURL != "" {
+		return k.CNIPluginsURL
+	}
+	return cloudSpecConfig.KubernetesSpecConfig.CNIPluginsDownloadURL
+}
+
 // IsFeatureEnabled returns true if a feature flag is on for the provided feature
 func (f *FeatureFlags) IsFeatureEnabled(feature string) bool {
 	if f != nil {
@@ -2214,6 +2560,75 @@ func (cs *ContainerService) GetCloudSpecConfig() AzureEnvironmentSpecConfig {
 	return AzureCloudSpecEnvMap[targetEnv]
 }
 
+// GetRequiredEgressFQDNs returns the sorted host names that cluster nodes must reach to provision and run,
+// these need to be allowed by the firewall or appliance when egress is restricted by userDefinedRouting.
+func (cs *ContainerService) GetRequiredEgressFQDNs() []string {
+	cloudSpecConfig := cs.GetCloudSpecConfig()
+	k8sSpecConfig := cloudSpecConfig.KubernetesSpecConfig
+	urls := []string{
+		k8sSpecConfig.KubernetesImageBase,
+		k8sSpecConfig.MCRKubernetesImageBase,
+		k8sSpecConfig.AzureCNIImageBase,
+		k8sSpecConfig.CalicoImageBase,
+		k8sSpecConfig.EtcdDownloadURLBase,
+		k8sSpecConfig.KubeBinariesSASURLBase,
+		k8sSpecConfig.CNIPluginsDownloadURL,
+		k8sSpecConfig.VnetCNILinuxPluginsDownloadURL,
+		k8sSpecConfig.ContainerdDownloadURLBase,
+		DefaultMicrosoftAptRepositoryURL,
+		// the apt archive configured on the Ubuntu marketplace images
+		"https://azure.archive.ubuntu.com",
+	}
+	if cs.Properties != nil {
+		if cs.Properties.HasWindows() {
+			urls = append(urls,
+				k8sSpecConfig.VnetCNIWindowsPluginsDownloadURL,
+				k8sSpecConfig.CSIProxyDownloadURL,
+				k8sSpecConfig.WindowsProvisioningScriptsPackageURL,
+				k8sSpecConfig.WindowsPauseImageURL)
+		}
+		if cs.Properties.OrchestratorProfile != nil && cs.Properties.OrchestratorProfile.KubernetesConfig != nil {
+			kubernetesConfig := cs.Properties.OrchestratorProfile.KubernetesConfig
+			urls = append(urls, kubernetesConfig.MicrosoftAptRepositoryURL, kubernetesConfig.CustomKubeBinaryURL,
+				kubernetesConfig.EtcdDownloadURLBase, kubernetesConfig.CNIPluginsURL)
+		}
+	}
+
+	var env *azure.Environment
+	if cs.Properties != nil && cs.Properties.IsCustomCloudProfile() && cs.Properties.CustomCloudProfile.Environment != nil {
+		env = cs.Properties.CustomCloudProfile.Environment
+	} else if e, err := azure.EnvironmentFromName(cloudSpecConfig.CloudName); err == nil {
+		env = &e
+	}
+	if env != nil {
+		urls = append(urls, env.ResourceManagerEndpoint, env.ActiveDirectoryEndpoint)
+	}
+
+	seen := map[string]bool{}
+	fqdns := []string{}
+	for _, u := range urls {
+		host := getHostName(u)
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		fqdns = append(fqdns, host)
+	}
+	sort.Strings(fqdns)
+	return fqdns
+}
+
+// getHostName returns the host part of a URL or of an image reference such as mcr.microsoft.com/oss/
+func getHostName(u string) string {
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+	}
+	if i := strings.IndexAny(u, "/:"); i >= 0 {
+		u = u[:i]
+	}
+	return strings.ToLower(u)
+}
+
 // IsAKSBillingEnabled checks if the AKS Billing Extension should be enabled for a cloud environment.
 func (cs *ContainerService) IsAKSBillingEnabled() bool {
 	cloudSpecConfig := cs.GetCloudSpecConfig()
@@ -2246,7 +2661,7 @@ func (cs *ContainerService) GetProvisionScriptParametersCommon(input ProvisionSc
 	kubernetesConfig := cs.Properties.OrchestratorProfile.KubernetesConfig
 	parameters := map[string]string{
 		"ADMINUSER":                               cs.Properties.LinuxProfile.AdminUsername,
-		"ETCD_DOWNLOAD_URL":                       cloudSpecConfig.KubernetesSpecConfig.EtcdDownloadURLBase,
+		"ETCD_DOWNLOAD_URL":                       kubernetesConfig.GetEtcdDownloadURLBase(cloudSpecConfig),
 		"ETCD_VERSION":                            kubernetesConfig.EtcdVersion,
 		"CONTAINERD_VERSION":                      kubernetesConfig.ContainerdVersion,
 		"MOBY_VERSION":                            kubernetesConfig.MobyVersion,
@@ -2271,7 +2686,7 @@ func (cs *ContainerService) GetProvisionScriptParametersCommon(input ProvisionSc
 		"NETWORK_PLUGIN":                          kubernetesConfig.NetworkPlugin,
 		"NETWORK_POLICY":                          kubernetesConfig.NetworkPolicy,
 		"VNET_CNI_PLUGINS_URL":                    kubernetesConfig.GetAzureCNIURLLinux(cloudSpecConfig),
-		"CNI_PLUGINS_URL":                         cloudSpecConfig.KubernetesSpecConfig.CNIPluginsDownloadURL,
+		"CNI_PLUGINS_URL":                         kubernetesConfig.GetCNIPluginsURL(cloudSpecConfig),
 		"CLOUDPROVIDER_BACKOFF":                   strconv.FormatBool(to.Bool(kubernetesConfig.CloudProviderBackoff)),
 		"CLOUDPROVIDER_BACKOFF_MODE":              kubernetesConfig.CloudProviderBackoffMode,
 		"CLOUDPROVIDER_BACKOFF_RETRIES":           strconv.Itoa(kubernetesConfig.CloudProviderBackoffRetries),
@@ -2303,6 +2718,9 @@ func (cs *ContainerService) GetProvisionScriptParametersCommon(input ProvisionSc
 		"TAGS":                                    kubernetesConfig.Tags,
 		"ENABLE_MULTIPLE_STANDARD_LOAD_BALANCERS": strconv.FormatBool(to.Bool(kubernetesConfig.EnableMultipleStandardLoadBalancers)),
 	}
+	if cs.Properties.IsUserDefinedRouting() {
+		parameters["ROUTE_TABLE_RESOURCE_GROUP"] = cs.Properties.GetRouteTableResourceGroupName()
+	}
 
 	keys := make([]string, 0)
 	for k := range parameters {
@@ -2321,6 +2739,36 @@ func (cs *ContainerService) GetProvisionScriptParametersCommon(input ProvisionSc
 	return provisionScriptParametersCommon.String()
 }
 
+// GetProvisionScriptParametersArch returns the environment variables that point the Linux bootstrap scripts
+// of an agent pool at the binaries of its CPU architecture, they override the values of GetProvisionScriptParametersCommon.
+// The parameters are empty for amd64 pools, or start with a space
+func (cs *ContainerService) GetProvisionScriptParametersArch(profile *AgentPoolProfile) string {
+	if !profile.IsArm64() {
+		return ""
+	}
+	cloudSpecConfig := cs.GetCloudSpecConfig()
+	kubernetesConfig := cs.Properties.OrchestratorProfile.KubernetesConfig
+	kubeBinaryURL := kubernetesConfig.CustomKubeBinaryURL
+	if kubeBinaryURL == "" {
+		kubeBinaryURL = fmt.Sprintf(defaultKubeBinaryURLFormat, cs.Properties.OrchestratorProfile.OrchestratorVersion)
+	}
+	parameters := []string{
+		"CNI_PLUGINS_URL=" + GetArchBinaryURL(kubernetesConfig.GetCNIPluginsURL(cloudSpecConfig), profile.Architecture),
+		"KUBE_BINARY_URL=" + GetArchBinaryURL(kubeBinaryURL, profile.Architecture),
+		"VNET_CNI_PLUGINS_URL=" + GetArchBinaryURL(kubernetesConfig.GetAzureCNIURLLinux(cloudSpecConfig), profile.Architecture),
+	}
+	return " " + strings.Join(parameters, " ")
+}
+
+// GetArchBinaryURL returns the URL of the Linux binaries of the given CPU architecture,
+// derived from the URL of their amd64 build
+func GetArchBinaryURL(url, arch string) string {
+	if arch == "" || arch == ArchitectureAmd64 {
+		return url
+	}
+	return strings.Replace(url, "linux-"+ArchitectureAmd64, "linux-"+arch, -1)
+}
+
 // FormatAzureProdFQDNByLocation constructs an Azure prod fqdn
 func FormatAzureProdFQDNByLocation(fqdnPrefix string, location string) string {
 	targetEnv := helpers.GetCloudTargetEnv(location)
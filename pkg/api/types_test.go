@@ -6,6 +6,7 @@ package api
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
@@ -2894,6 +2895,142 @@ func TestWindowsProfile(t *testing.T) {
 	}
 }
 
+func TestAgentPoolProfileWindowsImage(t *testing.T) {
+	w := &WindowsProfile{
+		WindowsPublisher:     AKSWindowsServer2019OSImageConfig.ImagePublisher,
+		WindowsOffer:         AKSWindowsServer2019OSImageConfig.ImageOffer,
+		WindowsSku:           AKSWindowsServer2019OSImageConfig.ImageSku,
+		ImageVersion:         AKSWindowsServer2019OSImageConfig.ImageVersion,
+		WindowsDockerVersion: "19.03.14",
+	}
+
+	pool := &AgentPoolProfile{Name: "win", OSType: Windows}
+	if pool.HasWindowsImageOverride() {
+		t.Errorf("expected HasWindowsImageOverride() to return false for a pool without image properties")
+	}
+	if imageConfig := pool.GetWindowsImageConfig(w); imageConfig != AKSWindowsServer2019OSImageConfig {
+		t.Errorf("expected GetWindowsImageConfig() to return the image of the WindowsProfile, got %+v", imageConfig)
+	}
+	if dv := pool.GetWindowsDockerVersion(w); dv != "19.03.14" {
+		t.Errorf("expected GetWindowsDockerVersion() to return the docker version of the WindowsProfile, got %s", dv)
+	}
+
+	pool = &AgentPoolProfile{Name: "win2022", OSType: Windows, WindowsSku: "2022-datacenter-core-smalldisk", WindowsImageVersion: "latest", WindowsDockerVersion: "20.10.9"}
+	if !pool.HasWindowsImageOverride() {
+		t.Errorf("expected HasWindowsImageOverride() to return true for a pool overriding the sku")
+	}
+	expected := AzureOSImageConfig{
+		ImagePublisher: AKSWindowsServer2019OSImageConfig.ImagePublisher,
+		ImageOffer:     AKSWindowsServer2019OSImageConfig.ImageOffer,
+		ImageSku:       "2022-datacenter-core-smalldisk",
+		ImageVersion:   "latest",
+	}
+	if imageConfig := pool.GetWindowsImageConfig(w); imageConfig != expected {
+		t.Errorf("expected GetWindowsImageConfig() to return %+v, got %+v", expected, imageConfig)
+	}
+	if dv := pool.GetWindowsDockerVersion(w); dv != "20.10.9" {
+		t.Errorf("expected GetWindowsDockerVersion() to return the docker version of the pool, got %s", dv)
+	}
+
+	pool = &AgentPoolProfile{Name: "linux", OSType: Linux, WindowsSku: "2022-datacenter-core-smalldisk"}
+	if pool.HasWindowsImageOverride() {
+		t.Errorf("expected HasWindowsImageOverride() to return false for a Linux pool")
+	}
+	if dv := pool.GetWindowsDockerVersion(nil); dv != KubernetesWindowsDockerVersion {
+		t.Errorf("expected GetWindowsDockerVersion() to return the default docker version, got %s", dv)
+	}
+}
+
+func TestAgentPoolProfileOSImageFlavor(t *testing.T) {
+	cloudSpecConfig := AzureCloudSpecEnvMap[AzurePublicCloud]
+	cases := []struct {
+		name               string
+		pool               AgentPoolProfile
+		expectedImage      AzureOSImageConfig
+		expectedGeneration string
+	}{
+		{
+			name:               "ubuntu 20.04 gen1 amd64",
+			pool:               AgentPoolProfile{Distro: Ubuntu2004},
+			expectedImage:      Ubuntu2004OSImageConfig,
+			expectedGeneration: HyperVGenerationV1,
+		},
+		{
+			name:               "ubuntu 20.04 gen2 distro",
+			pool:               AgentPoolProfile{Distro: Ubuntu2004Gen2},
+			expectedImage:      Ubuntu2004Gen2OSImageConfig,
+			expectedGeneration: HyperVGenerationV2,
+		},
+		{
+			name:               "ubuntu 20.04 with explicit gen2",
+			pool:               AgentPoolProfile{Distro: Ubuntu2004, HyperVGeneration: HyperVGenerationV2},
+			expectedImage:      Ubuntu2004Gen2OSImageConfig,
+			expectedGeneration: HyperVGenerationV2,
+		},
+		{
+			name:               "ubuntu 22.04 gen1",
+			pool:               AgentPoolProfile{Distro: Ubuntu2204},
+			expectedImage:      Ubuntu2204OSImageConfig,
+			expectedGeneration: HyperVGenerationV1,
+		},
+		{
+			name:               "ubuntu 22.04 arm64",
+			pool:               AgentPoolProfile{Distro: Ubuntu2204, Architecture: ArchitectureArm64},
+			expectedImage:      Ubuntu2204Arm64OSImageConfig,
+			expectedGeneration: HyperVGenerationV2,
+		},
+		{
+			name:               "flatcar gen2",
+			pool:               AgentPoolProfile{Distro: Flatcar, HyperVGeneration: HyperVGenerationV2},
+			expectedImage:      FlatcarGen2ImageConfig,
+			expectedGeneration: HyperVGenerationV2,
+		},
+		{
+			name:               "aks vhd keeps the cloud image",
+			pool:               AgentPoolProfile{Distro: AKSUbuntu1804},
+			expectedImage:      cloudSpecConfig.OSImageConfig[AKSUbuntu1804],
+			expectedGeneration: HyperVGenerationV1,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			if imageConfig := c.pool.GetOSImageConfig(cloudSpecConfig); imageConfig != c.expectedImage {
+				t.Errorf("expected GetOSImageConfig() to return %+v, got %+v", c.expectedImage, imageConfig)
+			}
+			if generation := c.pool.GetHyperVGeneration(); generation != c.expectedGeneration {
+				t.Errorf("expected GetHyperVGeneration() to return %s, got %s", c.expectedGeneration, generation)
+			}
+		})
+	}
+}
+
+func TestGetProvisionScriptParametersArch(t *testing.T) {
+	k8sVersion := common.RationalizeReleaseAndVersion(Kubernetes, "", "", false, false, false)
+	cs := CreateMockContainerService("testcluster", k8sVersion, 1, 3, true)
+	cs.Properties.OrchestratorProfile.KubernetesConfig.NetworkPlugin = NetworkPluginAzure
+
+	pool := &AgentPoolProfile{Name: "amd", Distro: Ubuntu2004}
+	if parameters := cs.GetProvisionScriptParametersArch(pool); parameters != "" {
+		t.Errorf("expected no parameters for an amd64 pool, got %s", parameters)
+	}
+
+	pool = &AgentPoolProfile{Name: "arm", Distro: Ubuntu2004, Architecture: ArchitectureArm64}
+	expected := " CNI_PLUGINS_URL=https://kubernetesartifacts.azureedge.net/cni-plugins/" + CNIPluginVer + "/binaries/cni-plugins-linux-arm64-" + CNIPluginVer + ".tgz" +
+		" KUBE_BINARY_URL=https://kubernetesartifacts.azureedge.net/kubernetes/v" + k8sVersion + "/binaries/kubernetes-node-linux-arm64.tar.gz" +
+		" VNET_CNI_PLUGINS_URL=https://kubernetesartifacts.azureedge.net/azure-cni/" + AzureCniPluginVerLinux + "/binaries/azure-vnet-cni-linux-arm64-" + AzureCniPluginVerLinux + ".tgz"
+	if parameters := cs.GetProvisionScriptParametersArch(pool); parameters != expected {
+		t.Errorf("expected GetProvisionScriptParametersArch() to return %s, got %s", expected, parameters)
+	}
+
+	cs.Properties.OrchestratorProfile.KubernetesConfig.CustomKubeBinaryURL = "https://example.com/kubernetes-node-linux-amd64.tar.gz"
+	if parameters := cs.GetProvisionScriptParametersArch(pool); !strings.Contains(parameters, " KUBE_BINARY_URL=https://example.com/kubernetes-node-linux-arm64.tar.gz ") {
+		t.Errorf("expected the custom kube binary URL to point at the arm64 build, got %s", parameters)
+	}
+}
+
 func TestWindowsProfileCustomOS(t *testing.T) {
 	cases := []struct {
 		name            string
@@ -4450,6 +4587,153 @@ func TestGetRouteTableName(t *testing.T) {
 	if actualNSGName != expectedNSGName {
 		t.Errorf("expected route table name %s, but got %s", actualNSGName, expectedNSGName)
 	}
+
+	actualPoolNSGName := p.GetAgentPoolNSGName(p.AgentPoolProfiles[0])
+	expectedPoolNSGName := fmt.Sprintf("k8s-%s-28513887-nsg", p.AgentPoolProfiles[0].Name)
+	if actualPoolNSGName != expectedPoolNSGName {
+		t.Errorf("expected agent pool network security group name %s, but got %s", expectedPoolNSGName, actualPoolNSGName)
+	}
+}
+
+func TestGetRouteTableNameUserDefinedRouting(t *testing.T) {
+	p := &Properties{
+		OrchestratorProfile: &OrchestratorProfile{
+			OrchestratorType: Kubernetes,
+			KubernetesConfig: &KubernetesConfig{
+				OutboundType: OutboundTypeUserDefinedRouting,
+				RouteTableID: "/subscriptions/SUB_ID/resourceGroups/NETWORK_RG/providers/Microsoft.Network/routeTables/firewall-rt",
+			},
+		},
+		MasterProfile: &MasterProfile{
+			Count:     1,
+			DNSPrefix: "foo",
+			VMSize:    "Standard_DS2_v2",
+		},
+	}
+
+	if !p.IsUserDefinedRouting() {
+		t.Errorf("expected IsUserDefinedRouting to be true")
+	}
+	if actual := p.GetRouteTableName(); actual != "firewall-rt" {
+		t.Errorf("expected route table name firewall-rt, but got %s", actual)
+	}
+	if actual := p.GetRouteTableResourceGroupName(); actual != "NETWORK_RG" {
+		t.Errorf("expected route table resource group NETWORK_RG, but got %s", actual)
+	}
+
+	p.OrchestratorProfile.KubernetesConfig.RouteTableID = "firewall-rt"
+	if actual := p.GetRouteTableName(); actual != "" {
+		t.Errorf("expected an empty route table name for a malformed routeTableID, but got %s", actual)
+	}
+	if actual := p.GetRouteTableResourceGroupName(); actual != "" {
+		t.Errorf("expected an empty route table resource group for a malformed routeTableID, but got %s", actual)
+	}
+
+	p.OrchestratorProfile.KubernetesConfig.OutboundType = OutboundTypeLoadBalancer
+	if p.IsUserDefinedRouting() {
+		t.Errorf("expected IsUserDefinedRouting to be false")
+	}
+	if actual := p.GetRouteTableResourceGroupName(); actual != "" {
+		t.Errorf("expected an empty route table resource group, but got %s", actual)
+	}
+}
+
+func TestIsNATGateway(t *testing.T) {
+	cases := []struct {
+		outboundType       string
+		expectedNAT        bool
+		expectedManaged    bool
+		expectedLBOutbound bool
+	}{
+		{outboundType: "", expectedLBOutbound: true},
+		{outboundType: OutboundTypeLoadBalancer, expectedLBOutbound: true},
+		{outboundType: OutboundTypeUserDefinedRouting},
+		{outboundType: OutboundTypeManagedNATGateway, expectedNAT: true, expectedManaged: true},
+		{outboundType: OutboundTypeUserAssignedNATGateway, expectedNAT: true},
+	}
+
+	for _, c := range cases {
+		p := &Properties{
+			OrchestratorProfile: &OrchestratorProfile{
+				OrchestratorType: Kubernetes,
+				KubernetesConfig: &KubernetesConfig{
+					OutboundType: c.outboundType,
+				},
+			},
+		}
+		if actual := p.IsNATGateway(); actual != c.expectedNAT {
+			t.Errorf("outboundType %q: expected IsNATGateway to be %t, but got %t", c.outboundType, c.expectedNAT, actual)
+		}
+		if actual := p.IsManagedNATGateway(); actual != c.expectedManaged {
+			t.Errorf("outboundType %q: expected IsManagedNATGateway to be %t, but got %t", c.outboundType, c.expectedManaged, actual)
+		}
+		if actual := p.HasLoadBalancerOutboundRules(); actual != c.expectedLBOutbound {
+			t.Errorf("outboundType %q: expected HasLoadBalancerOutboundRules to be %t, but got %t", c.outboundType, c.expectedLBOutbound, actual)
+		}
+	}
+
+	var k *KubernetesConfig
+	if k.IsNATGateway() || k.IsManagedNATGateway() {
+		t.Errorf("expected a nil KubernetesConfig not to use a NAT gateway")
+	}
+}
+
+func TestGetRequiredEgressFQDNs(t *testing.T) {
+	cases := []struct {
+		location            string
+		resourceManagerFQDN string
+	}{
+		{location: "westus2", resourceManagerFQDN: "management.azure.com"},
+		{location: "chinaeast2", resourceManagerFQDN: "management.chinacloudapi.cn"},
+		{location: "germanynortheast", resourceManagerFQDN: "management.microsoftazure.de"},
+		{location: "usgovvirginia", resourceManagerFQDN: "management.usgovcloudapi.net"},
+	}
+	for _, c := range cases {
+		cs := CreateMockContainerService("testcluster", "1.18.8", 1, 1, false)
+		cs.Location = c.location
+		cs.Properties.CustomCloudProfile = nil
+		cs.Properties.AgentPoolProfiles[0].OSType = Windows
+
+		fqdns := cs.GetRequiredEgressFQDNs()
+		if !sort.StringsAreSorted(fqdns) {
+			t.Errorf("%s: expected sorted FQDNs, got %v", c.location, fqdns)
+		}
+		k8s := cs.GetCloudSpecConfig().KubernetesSpecConfig
+		for _, host := range []string{
+			getHostName(k8s.MCRKubernetesImageBase),
+			getHostName(k8s.KubeBinariesSASURLBase),
+			getHostName(k8s.CNIPluginsDownloadURL),
+			getHostName(k8s.VnetCNIWindowsPluginsDownloadURL),
+			getHostName(k8s.WindowsProvisioningScriptsPackageURL),
+			getHostName(DefaultMicrosoftAptRepositoryURL),
+			c.resourceManagerFQDN,
+		} {
+			found := false
+			for _, fqdn := range fqdns {
+				if fqdn == host {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("%s: expected %s in the required egress FQDNs %v", c.location, host, fqdns)
+			}
+		}
+	}
+}
+
+func TestGetHostName(t *testing.T) {
+	cases := map[string]string{
+		"mcr.microsoft.com/": "mcr.microsoft.com",
+		"https://kubernetesartifacts.azureedge.net/kubernetes/": "kubernetesartifacts.azureedge.net",
+		"https://management.azure.com:443/":                     "management.azure.com",
+		"mcr.microsoft.com/oss/kubernetes/pause:3.9":            "mcr.microsoft.com",
+		"": "",
+	}
+	for in, expected := range cases {
+		if actual := getHostName(in); actual != expected {
+			t.Errorf("getHostName(%q): expected %q, got %q", in, expected, actual)
+		}
+	}
 }
 
 func TestGetSubnetName(t *testing.T) {
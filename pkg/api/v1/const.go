@@ -0,0 +1,9 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package v1
+
+const (
+	// APIVersion is the version of this API
+	APIVersion = "v1"
+)
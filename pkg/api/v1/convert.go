@@ -0,0 +1,80 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package v1
+
+import (
+	"reflect"
+
+	"github.com/Azure/aks-engine/pkg/api/vlabs"
+)
+
+// Conversions go through vlabs, which is the only version the internal api types convert to directly.
+// Fields are copied by name, so a property added to both versions is converted without changes here,
+// and only the structs that differ between the versions are converted explicitly.
+
+// ConvertToVLabs converts a v1 ContainerService to a vlabs ContainerService
+func ConvertToVLabs(cs *ContainerService) *vlabs.ContainerService {
+	if cs == nil {
+		return nil
+	}
+	v := &vlabs.ContainerService{}
+	copyFields(v, cs)
+	if cs.Properties != nil {
+		v.Properties = &vlabs.Properties{}
+		copyFields(v.Properties, cs.Properties)
+		if o := cs.Properties.OrchestratorProfile; o != nil {
+			v.Properties.OrchestratorProfile = &vlabs.OrchestratorProfile{}
+			copyFields(v.Properties.OrchestratorProfile, o)
+			if o.KubernetesConfig != nil {
+				v.Properties.OrchestratorProfile.KubernetesConfig = &vlabs.KubernetesConfig{}
+				copyFields(v.Properties.OrchestratorProfile.KubernetesConfig, o.KubernetesConfig)
+			}
+		}
+		if cs.Properties.WindowsProfile != nil {
+			v.Properties.WindowsProfile = &vlabs.WindowsProfile{}
+			copyFields(v.Properties.WindowsProfile, cs.Properties.WindowsProfile)
+		}
+	}
+	return v
+}
+
+// ConvertFromVLabs converts a vlabs ContainerService to a v1 ContainerService.
+// Deprecated vlabs properties have no v1 equivalent and are dropped.
+func ConvertFromVLabs(v *vlabs.ContainerService) *ContainerService {
+	if v == nil {
+		return nil
+	}
+	cs := &ContainerService{}
+	copyFields(cs, v)
+	if v.Properties != nil {
+		cs.Properties = &Properties{}
+		copyFields(cs.Properties, v.Properties)
+		if o := v.Properties.OrchestratorProfile; o != nil {
+			cs.Properties.OrchestratorProfile = &OrchestratorProfile{}
+			copyFields(cs.Properties.OrchestratorProfile, o)
+			if o.KubernetesConfig != nil {
+				cs.Properties.OrchestratorProfile.KubernetesConfig = &KubernetesConfig{}
+				copyFields(cs.Properties.OrchestratorProfile.KubernetesConfig, o.KubernetesConfig)
+			}
+		}
+		if v.Properties.WindowsProfile != nil {
+			cs.Properties.WindowsProfile = &WindowsProfile{}
+			copyFields(cs.Properties.WindowsProfile, v.Properties.WindowsProfile)
+		}
+	}
+	return cs
+}
+
+// copyFields sets every field of the struct dst points to from the field of src with the same name and type.
+// Fields missing from src, and fields whose type differs between the versions, are left to the caller.
+func copyFields(dst, src interface{}) {
+	d := reflect.ValueOf(dst).Elem()
+	s := reflect.ValueOf(src).Elem()
+	for i := 0; i < d.NumField(); i++ {
+		f := s.FieldByName(d.Type().Field(i).Name)
+		if f.IsValid() && f.Type() == d.Field(i).Type() {
+			d.Field(i).Set(f)
+		}
+	}
+}
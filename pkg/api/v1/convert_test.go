@@ -0,0 +1,149 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package v1
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/api/vlabs"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func TestConvertRoundTrip(t *testing.T) {
+	cs := &ContainerService{
+		Location: "westus2",
+		Properties: &Properties{
+			OrchestratorProfile: &OrchestratorProfile{
+				OrchestratorType:    vlabs.Kubernetes,
+				OrchestratorVersion: "1.24.9",
+				KubernetesConfig: &KubernetesConfig{
+					NetworkPlugin:   "azure",
+					GCHighThreshold: 85,
+					KubeletConfig:   map[string]string{"--max-pods": "30"},
+					Addons: []KubernetesAddon{
+						{Name: "coredns", Enabled: to.BoolPtr(true)},
+					},
+				},
+			},
+			MasterProfile: &MasterProfile{Count: 3, DNSPrefix: "foo", VMSize: "Standard_D2_v3"},
+			AgentPoolProfiles: []*AgentPoolProfile{
+				{Name: "agentpool1", Count: 2, VMSize: "Standard_D2_v3"},
+			},
+			WindowsProfile: &WindowsProfile{
+				AdminUsername:    "azureuser",
+				WindowsPublisher: "MicrosoftWindowsServer",
+				WindowsSku:       "2019-Datacenter-Core-smalldisk",
+			},
+		},
+	}
+
+	v := ConvertToVLabs(cs)
+	if v.Properties.OrchestratorProfile.KubernetesConfig.GCHighThreshold != 85 {
+		t.Errorf("expected GCHighThreshold to be converted to vlabs")
+	}
+	if v.Properties.WindowsProfile.WindowsPublisher != "MicrosoftWindowsServer" {
+		t.Errorf("expected WindowsPublisher to be converted to vlabs")
+	}
+
+	if roundTripped := ConvertFromVLabs(v); !reflect.DeepEqual(cs, roundTripped) {
+		t.Errorf("expected %+v after round trip through vlabs, got %+v", cs, roundTripped)
+	}
+
+	if ConvertToVLabs(nil) != nil || ConvertFromVLabs(nil) != nil {
+		t.Errorf("expected nil ContainerService to convert to nil")
+	}
+}
+
+// fill sets every settable field reachable from v to a non-zero value, depth bounds the pointers followed
+func fill(v reflect.Value, depth int) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1)
+	case reflect.Ptr:
+		if depth > 10 {
+			return
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem(), depth+1)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(v.Index(0), depth)
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		key, elem := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+		fill(key, depth)
+		fill(elem, depth)
+		v.SetMapIndex(key, elem)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				fill(v.Field(i), depth)
+			}
+		}
+	}
+}
+
+func TestConvertRoundTripsEveryProperty(t *testing.T) {
+	v := &vlabs.ContainerService{}
+	fill(reflect.ValueOf(v).Elem(), 0)
+	// the only vlabs properties v1 drops are the deprecated ones
+	v.Properties.OrchestratorProfile.KubernetesConfig.DockerEngineVersion = ""
+	v.Properties.OrchestratorProfile.KubernetesConfig.PodSecurityPolicyConfig = nil
+	if roundTripped := ConvertToVLabs(ConvertFromVLabs(v)); !reflect.DeepEqual(v, roundTripped) {
+		t.Errorf("expected every vlabs property but the deprecated ones to round trip through v1, a property is missing from the v1 types or from the conversion")
+	}
+
+	cs := &ContainerService{}
+	fill(reflect.ValueOf(cs).Elem(), 0)
+	if roundTripped := ConvertFromVLabs(ConvertToVLabs(cs)); !reflect.DeepEqual(cs, roundTripped) {
+		t.Errorf("expected every v1 property to round trip through vlabs, a property is missing from the vlabs types or from the conversion")
+	}
+}
+
+func TestConvertFromVLabsDropsDeprecatedProperties(t *testing.T) {
+	v := &vlabs.ContainerService{
+		Properties: &vlabs.Properties{
+			OrchestratorProfile: &vlabs.OrchestratorProfile{
+				OrchestratorType: vlabs.Kubernetes,
+				KubernetesConfig: &vlabs.KubernetesConfig{
+					DockerEngineVersion:     "17.03.*",
+					PodSecurityPolicyConfig: map[string]string{"data": "foo"},
+					GCLowThreshold:          80,
+				},
+			},
+		},
+	}
+
+	b, err := json.Marshal(ConvertFromVLabs(v))
+	if err != nil {
+		t.Fatalf("unexpected error marshaling v1 ContainerService: %s", err)
+	}
+	expected := `{"properties":{"orchestratorProfile":{"orchestratorType":"Kubernetes","kubernetesConfig":{"gcLowThreshold":80}}}}`
+	if string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, string(b))
+	}
+}
+
+func TestOrchestratorProfileUnmarshalJSON(t *testing.T) {
+	o := &OrchestratorProfile{}
+	if err := json.Unmarshal([]byte(`{"orchestratorType": "kubernetes"}`), o); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if o.OrchestratorType != vlabs.Kubernetes {
+		t.Errorf("expected orchestratorType %s, got %s", vlabs.Kubernetes, o.OrchestratorType)
+	}
+	if err := json.Unmarshal([]byte(`{"orchestratorType": "DCOS"}`), o); err == nil {
+		t.Errorf("expected error for an unknown orchestratorType")
+	}
+}
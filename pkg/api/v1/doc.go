@@ -0,0 +1,7 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+// Package v1 stores the stable API model. It is the vlabs API model without
+// its deprecated properties, with the same JSON property names. The structs
+// that have no deprecated properties are shared with vlabs.
+package v1
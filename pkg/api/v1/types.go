@@ -0,0 +1,215 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package v1
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Azure/aks-engine/pkg/api/vlabs"
+	"github.com/pkg/errors"
+)
+
+// Types that did not change from vlabs are shared with it
+type (
+	ResourcePurchasePlan    = vlabs.ResourcePurchasePlan
+	FeatureFlags            = vlabs.FeatureFlags
+	ServicePrincipalProfile = vlabs.ServicePrincipalProfile
+	KeyvaultSecretRef       = vlabs.KeyvaultSecretRef
+	CertificateProfile      = vlabs.CertificateProfile
+	LinuxProfile            = vlabs.LinuxProfile
+	WindowsRuntimes         = vlabs.WindowsRuntimes
+	WindowsGMSAProfile      = vlabs.WindowsGMSAProfile
+	ImageReference          = vlabs.ImageReference
+	KeyVaultSecrets         = vlabs.KeyVaultSecrets
+	ProvisioningState       = vlabs.ProvisioningState
+	KubernetesAddon         = vlabs.KubernetesAddon
+	KubernetesComponent     = vlabs.KubernetesComponent
+	PrivateCluster          = vlabs.PrivateCluster
+	KubeProxyMode           = vlabs.KubeProxyMode
+	MasterProfile           = vlabs.MasterProfile
+	ExtensionProfile        = vlabs.ExtensionProfile
+	AgentPoolProfile        = vlabs.AgentPoolProfile
+	AADProfile              = vlabs.AADProfile
+	CustomCloudProfile      = vlabs.CustomCloudProfile
+	TelemetryProfile        = vlabs.TelemetryProfile
+	NetworkSecurityRule     = vlabs.NetworkSecurityRule
+	NATGatewayProfile       = vlabs.NATGatewayProfile
+)
+
+// ContainerService complies with the ARM model of
+// resource definition in a JSON template.
+type ContainerService struct {
+	ID       string                `json:"id,omitempty"`
+	Location string                `json:"location,omitempty"`
+	Name     string                `json:"name,omitempty"`
+	Plan     *ResourcePurchasePlan `json:"plan,omitempty"`
+	Tags     map[string]string     `json:"tags,omitempty"`
+	Type     string                `json:"type,omitempty"`
+
+	Properties *Properties `json:"properties"  validate:"required"`
+}
+
+// Properties represents the AKS cluster definition
+type Properties struct {
+	ProvisioningState       ProvisioningState        `json:"provisioningState,omitempty"`
+	OrchestratorProfile     *OrchestratorProfile     `json:"orchestratorProfile,omitempty"`
+	MasterProfile           *MasterProfile           `json:"masterProfile,omitempty" validate:"required"`
+	AgentPoolProfiles       []*AgentPoolProfile      `json:"agentPoolProfiles,omitempty" validate:"dive,required"`
+	LinuxProfile            *LinuxProfile            `json:"linuxProfile,omitempty" validate:"required"`
+	ExtensionProfiles       []*ExtensionProfile      `json:"extensionProfiles,omitempty"`
+	WindowsProfile          *WindowsProfile          `json:"windowsProfile,omitempty"`
+	ServicePrincipalProfile *ServicePrincipalProfile `json:"servicePrincipalProfile,omitempty"`
+	CertificateProfile      *CertificateProfile      `json:"certificateProfile,omitempty"`
+	AADProfile              *AADProfile              `json:"aadProfile,omitempty"`
+	FeatureFlags            *FeatureFlags            `json:"featureFlags,omitempty"`
+	CustomCloudProfile      *CustomCloudProfile      `json:"customCloudProfile,omitempty"`
+	TelemetryProfile        *TelemetryProfile        `json:"telemetryProfile,omitempty"`
+	NetworkSecurityRules    []NetworkSecurityRule    `json:"networkSecurityRules,omitempty"`
+}
+
+// WindowsProfile represents the windows parameters passed to the cluster
+type WindowsProfile struct {
+	AdminUsername                 string              `json:"adminUsername,omitempty"`
+	AdminPassword                 string              `json:"adminPassword,omitempty"`
+	CSIProxyURL                   string              `json:"csiProxyURL,omitempty"`
+	EnableCSIProxy                *bool               `json:"enableCSIProxy,omitempty"`
+	ImageRef                      *ImageReference     `json:"imageReference,omitempty"`
+	ImageVersion                  string              `json:"imageVersion,omitempty"`
+	ProvisioningScriptsPackageURL string              `json:"provisioningScriptsPackageURL,omitempty"`
+	WindowsImageSourceURL         string              `json:"windowsImageSourceURL,omitempty"`
+	WindowsPublisher              string              `json:"windowsPublisher,omitempty"`
+	WindowsOffer                  string              `json:"windowsOffer,omitempty"`
+	WindowsSku                    string              `json:"windowsSku,omitempty"`
+	WindowsDockerVersion          string              `json:"windowsDockerVersion,omitempty"`
+	Secrets                       []KeyVaultSecrets   `json:"secrets,omitempty"`
+	SSHEnabled                    *bool               `json:"sshEnabled,omitempty"`
+	EnableAutomaticUpdates        *bool               `json:"enableAutomaticUpdates,omitempty"`
+	IsCredentialAutoGenerated     *bool               `json:"isCredentialAutoGenerated,omitempty"`
+	EnableAHUB                    *bool               `json:"enableAHUB,omitempty"`
+	WindowsPauseImageURL          string              `json:"windowsPauseImageURL,omitempty"`
+	AlwaysPullWindowsPauseImage   *bool               `json:"alwaysPullWindowsPauseImage,omitempty"`
+	WindowsRuntimes               *WindowsRuntimes    `json:"windowsRuntimes,omitempty"`
+	WindowsSecureTLSEnabled       *bool               `json:"windowsSecureTLSEnabled,omitempty"`
+	GMSA                          *WindowsGMSAProfile `json:"gmsa,omitempty"`
+}
+
+// OrchestratorProfile contains Orchestrator properties
+type OrchestratorProfile struct {
+	// OrchestratorType is a legacy property, this should always be set to "Kubernetes"
+	OrchestratorType    string            `json:"orchestratorType"`
+	OrchestratorRelease string            `json:"orchestratorRelease,omitempty"`
+	OrchestratorVersion string            `json:"orchestratorVersion,omitempty"`
+	KubernetesConfig    *KubernetesConfig `json:"kubernetesConfig,omitempty"`
+}
+
+// UnmarshalJSON unmarshal json using the default behavior
+// And do fields manipulation, such as populating default value
+func (o *OrchestratorProfile) UnmarshalJSON(b []byte) error {
+	// Need to have a alias type to avoid circular unmarshal
+	type aliasOrchestratorProfile OrchestratorProfile
+	op := aliasOrchestratorProfile{}
+	if e := json.Unmarshal(b, &op); e != nil {
+		return e
+	}
+	*o = OrchestratorProfile(op)
+	switch {
+	case strings.EqualFold(o.OrchestratorType, vlabs.Kubernetes), o.OrchestratorType == "":
+		o.OrchestratorType = vlabs.Kubernetes
+	default:
+		return errors.Errorf("OrchestratorType has unknown orchestrator: %s", o.OrchestratorType)
+	}
+	return nil
+}
+
+// KubernetesConfig contains the Kubernetes config structure, containing
+// Kubernetes specific configuration
+type KubernetesConfig struct {
+	KubernetesImageBase                 string                `json:"kubernetesImageBase,omitempty"`
+	KubernetesImageBaseType             string                `json:"kubernetesImageBaseType,omitempty"`
+	MCRKubernetesImageBase              string                `json:"mcrKubernetesImageBase,omitempty"`
+	ClusterSubnet                       string                `json:"clusterSubnet,omitempty"`
+	DNSServiceIP                        string                `json:"dnsServiceIP,omitempty"`
+	ServiceCidr                         string                `json:"serviceCidr,omitempty"`
+	NetworkPolicy                       string                `json:"networkPolicy,omitempty"`
+	NetworkPlugin                       string                `json:"networkPlugin,omitempty"`
+	NetworkMode                         string                `json:"networkMode,omitempty"`
+	ContainerRuntime                    string                `json:"containerRuntime,omitempty"`
+	MaxPods                             int                   `json:"maxPods,omitempty"`
+	DockerBridgeSubnet                  string                `json:"dockerBridgeSubnet,omitempty"`
+	UseManagedIdentity                  *bool                 `json:"useManagedIdentity,omitempty"`
+	UserAssignedID                      string                `json:"userAssignedID,omitempty"`
+	UserAssignedClientID                string                `json:"userAssignedClientID,omitempty"` // cannot be provided in config, used only for transferring this to azure.json
+	CustomHyperkubeImage                string                `json:"customHyperkubeImage,omitempty"`
+	CustomKubeAPIServerImage            string                `json:"customKubeAPIServerImage,omitempty"`
+	CustomKubeControllerManagerImage    string                `json:"customKubeControllerManagerImage,omitempty"`
+	CustomKubeProxyImage                string                `json:"customKubeProxyImage,omitempty"`
+	CustomKubeSchedulerImage            string                `json:"customKubeSchedulerImage,omitempty"`
+	CustomKubeBinaryURL                 string                `json:"customKubeBinaryURL,omitempty"`
+	MobyVersion                         string                `json:"mobyVersion,omitempty"`
+	LinuxMobyURL                        string                `json:"linuxMobyURL,omitempty"`
+	LinuxRuncURL                        string                `json:"linuxRuncURL,omitempty"`
+	ContainerdVersion                   string                `json:"containerdVersion,omitempty"`
+	LinuxContainerdURL                  string                `json:"linuxContainerdURL,omitempty"`
+	CustomCcmImage                      string                `json:"customCcmImage,omitempty"`
+	UseCloudControllerManager           *bool                 `json:"useCloudControllerManager,omitempty"`
+	CustomWindowsPackageURL             string                `json:"customWindowsPackageURL,omitempty"`
+	WindowsNodeBinariesURL              string                `json:"windowsNodeBinariesURL,omitempty"`
+	WindowsContainerdURL                string                `json:"windowsContainerdURL,omitempty"`
+	WindowsSdnPluginURL                 string                `json:"windowsSdnPluginURL,omitempty"`
+	UseInstanceMetadata                 *bool                 `json:"useInstanceMetadata,omitempty"`
+	EnableRbac                          *bool                 `json:"enableRbac,omitempty"`
+	EnableSecureKubelet                 *bool                 `json:"enableSecureKubelet,omitempty"`
+	EnableAggregatedAPIs                bool                  `json:"enableAggregatedAPIs,omitempty"`
+	PrivateCluster                      *PrivateCluster       `json:"privateCluster,omitempty"`
+	GCHighThreshold                     int                   `json:"gcHighThreshold,omitempty"`
+	GCLowThreshold                      int                   `json:"gcLowThreshold,omitempty"`
+	EtcdVersion                         string                `json:"etcdVersion,omitempty"`
+	EtcdDiskSizeGB                      string                `json:"etcdDiskSizeGB,omitempty"`
+	EtcdStorageLimitGB                  int                   `json:"etcdStorageLimitGB,omitempty"`
+	EtcdEncryptionKey                   string                `json:"etcdEncryptionKey,omitempty"`
+	EnableDataEncryptionAtRest          *bool                 `json:"enableDataEncryptionAtRest,omitempty"`
+	EnableEncryptionWithExternalKms     *bool                 `json:"enableEncryptionWithExternalKms,omitempty"`
+	EnablePodSecurityPolicy             *bool                 `json:"enablePodSecurityPolicy,omitempty"`
+	Addons                              []KubernetesAddon     `json:"addons,omitempty"`
+	Components                          []KubernetesComponent `json:"components,omitempty"`
+	ContainerRuntimeConfig              map[string]string     `json:"containerRuntimeConfig,omitempty"`
+	KubeletConfig                       map[string]string     `json:"kubeletConfig,omitempty"`
+	ControllerManagerConfig             map[string]string     `json:"controllerManagerConfig,omitempty"`
+	CloudControllerManagerConfig        map[string]string     `json:"cloudControllerManagerConfig,omitempty"`
+	APIServerConfig                     map[string]string     `json:"apiServerConfig,omitempty"`
+	SchedulerConfig                     map[string]string     `json:"schedulerConfig,omitempty"`
+	KubeReservedCgroup                  string                `json:"kubeReservedCgroup,omitempty"`
+	CloudProviderBackoffMode            string                `json:"cloudProviderBackoffMode,omitempty"`
+	CloudProviderBackoff                *bool                 `json:"cloudProviderBackoff,omitempty"`
+	CloudProviderBackoffRetries         int                   `json:"cloudProviderBackoffRetries,omitempty"`
+	CloudProviderBackoffJitter          float64               `json:"cloudProviderBackoffJitter,omitempty"`
+	CloudProviderBackoffDuration        int                   `json:"cloudProviderBackoffDuration,omitempty"`
+	CloudProviderBackoffExponent        float64               `json:"cloudProviderBackoffExponent,omitempty"`
+	CloudProviderRateLimit              *bool                 `json:"cloudProviderRateLimit,omitempty"`
+	CloudProviderRateLimitQPS           float64               `json:"cloudProviderRateLimitQPS,omitempty"`
+	CloudProviderRateLimitQPSWrite      float64               `json:"cloudProviderRateLimitQPSWrite,omitempty"`
+	CloudProviderRateLimitBucket        int                   `json:"cloudProviderRateLimitBucket,omitempty"`
+	CloudProviderRateLimitBucketWrite   int                   `json:"cloudProviderRateLimitBucketWrite,omitempty"`
+	CloudProviderDisableOutboundSNAT    *bool                 `json:"cloudProviderDisableOutboundSNAT,omitempty"`
+	LoadBalancerSku                     string                `json:"loadBalancerSku,omitempty"`
+	ExcludeMasterFromStandardLB         *bool                 `json:"excludeMasterFromStandardLB,omitempty"`
+	LoadBalancerOutboundIPs             *int                  `json:"loadBalancerOutboundIPs,omitempty"`
+	AzureCNIVersion                     string                `json:"azureCNIVersion,omitempty"`
+	AzureCNIURLLinux                    string                `json:"azureCNIURLLinux,omitempty"`
+	AzureCNIURLWindows                  string                `json:"azureCNIURLWindows,omitempty"`
+	EtcdDownloadURLBase                 string                `json:"etcdDownloadURLBase,omitempty"`
+	CNIPluginsURL                       string                `json:"cniPluginsURL,omitempty"`
+	KeyVaultSku                         string                `json:"keyVaultSku,omitempty"`
+	MaximumLoadBalancerRuleCount        int                   `json:"maximumLoadBalancerRuleCount,omitempty"`
+	ProxyMode                           KubeProxyMode         `json:"kubeProxyMode,omitempty"`
+	PrivateAzureRegistryServer          string                `json:"privateAzureRegistryServer,omitempty"`
+	OutboundRuleIdleTimeoutInMinutes    int32                 `json:"outboundRuleIdleTimeoutInMinutes,omitempty"`
+	MicrosoftAptRepositoryURL           string                `json:"microsoftAptRepositoryURL,omitempty"`
+	EnableMultipleStandardLoadBalancers *bool                 `json:"enableMultipleStandardLoadBalancers,omitempty"`
+	Tags                                string                `json:"tags,omitempty"`
+	OutboundType                        string                `json:"outboundType,omitempty"`
+	RouteTableID                        string                `json:"routeTableID,omitempty"`
+	NATGatewayProfile                   *NATGatewayProfile    `json:"natGatewayProfile,omitempty"`
+}
@@ -0,0 +1,10 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package v1
+
+// Validate implements validation for ContainerService. v1 accepts the same
+// values as vlabs, so validation is delegated to the vlabs rules.
+func (cs *ContainerService) Validate(isUpdate bool) error {
+	return ConvertToVLabs(cs).Validate(isUpdate)
+}
@@ -27,6 +27,8 @@ const (
 	Ubuntu1804Gen2    Distro = "ubuntu-18.04-gen2"
 	Ubuntu2004        Distro = "ubuntu-20.04"
 	Ubuntu2004Gen2    Distro = "ubuntu-20.04-gen2"
+	Ubuntu2204        Distro = "ubuntu-22.04"
+	Ubuntu2204Gen2    Distro = "ubuntu-22.04-gen2"
 	Flatcar           Distro = "flatcar"
 	AKS1604Deprecated Distro = "aks"               // deprecated AKS 16.04 distro. Equivalent to aks-ubuntu-16.04.
 	AKS1804Deprecated Distro = "aks-1804"          // deprecated AKS 18.04 distro. Equivalent to aks-ubuntu-18.04.
@@ -37,6 +39,18 @@ const (
 	ACC1604           Distro = "acc-16.04"
 )
 
+// the Hyper-V generations of agent pool VMs
+const (
+	HyperVGenerationV1 = "V1"
+	HyperVGenerationV2 = "V2"
+)
+
+// the CPU architectures of agent pools
+const (
+	ArchitectureAmd64 = "amd64"
+	ArchitectureArm64 = "arm64"
+)
+
 // validation values
 const (
 	// MinAgentCount are the minimum number of agents per agent pool
@@ -101,7 +115,13 @@ var (
 	ContainerRuntimeValues = [...]string{"", Docker, Containerd}
 
 	// DistroValues holds the valid values for OS distros
-	DistroValues = []Distro{"", Ubuntu, Ubuntu2004, Ubuntu2004Gen2, Ubuntu1804, Ubuntu1804Gen2, Flatcar, AKSUbuntu1604, AKSUbuntu1804, AKSUbuntu2004, ACC1604}
+	DistroValues = []Distro{"", Ubuntu, Ubuntu2204, Ubuntu2204Gen2, Ubuntu2004, Ubuntu2004Gen2, Ubuntu1804, Ubuntu1804Gen2, Flatcar, AKSUbuntu1604, AKSUbuntu1804, AKSUbuntu2004, ACC1604}
+
+	// HyperVGenerationValues holds the valid values for the Hyper-V generation of agent pools
+	HyperVGenerationValues = [...]string{"", HyperVGenerationV1, HyperVGenerationV2}
+
+	// ArchitectureValues holds the valid values for the CPU architecture of agent pools
+	ArchitectureValues = [...]string{"", ArchitectureAmd64, ArchitectureArm64}
 
 	// DependenciesLocationValues holds the valid values for dependencies location
 	DependenciesLocationValues = []DependenciesLocation{"", AzureCustomCloudDependenciesLocationPublic, AzureCustomCloudDependenciesLocationChina, AzureCustomCloudDependenciesLocationGerman, AzureCustomCloudDependenciesLocationUSGovernment}
@@ -187,6 +207,18 @@ const BasicLoadBalancerSku = "Basic"
 // StandardLoadBalancerSku is the string const for Azure Standard Load Balancer
 const StandardLoadBalancerSku = "Standard"
 
+// outbound types
+const (
+	// OutboundTypeLoadBalancer means that egress traffic goes through the outbound rules of the Standard Load Balancer
+	OutboundTypeLoadBalancer = "loadBalancer"
+	// OutboundTypeUserDefinedRouting means that egress traffic is routed by an existing route table, e.g. to a firewall
+	OutboundTypeUserDefinedRouting = "userDefinedRouting"
+	// OutboundTypeManagedNATGateway means that egress traffic goes through a NAT gateway created by aks-engine
+	OutboundTypeManagedNATGateway = "managedNATGateway"
+	// OutboundTypeUserAssignedNATGateway means that egress traffic goes through an existing NAT gateway
+	OutboundTypeUserAssignedNATGateway = "userAssignedNATGateway"
+)
+
 // addons consts
 const (
 	// AddonModeEnsureExists
@@ -194,3 +226,11 @@ const (
 	// AddonModeReconcile
 	AddonModeReconcile = "Reconcile"
 )
+
+// windowsProfile.gmsa modes
+const (
+	// WindowsGMSAModeDomainJoin joins the Windows nodes to the Active Directory domain of the gMSA
+	WindowsGMSAModeDomainJoin = "DomainJoin"
+	// WindowsGMSAModeCCGPlugin installs a Container Credential Guard plugin on the Windows nodes, that retrieves the gMSA credentials without domain join
+	WindowsGMSAModeCCGPlugin = "CCGPlugin"
+)
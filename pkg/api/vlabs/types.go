@@ -50,6 +50,21 @@ type Properties struct {
 	FeatureFlags            *FeatureFlags            `json:"featureFlags,omitempty"`
 	CustomCloudProfile      *CustomCloudProfile      `json:"customCloudProfile,omitempty"`
 	TelemetryProfile        *TelemetryProfile        `json:"telemetryProfile,omitempty"`
+	NetworkSecurityRules    []NetworkSecurityRule    `json:"networkSecurityRules,omitempty"`
+}
+
+// NetworkSecurityRule is a custom security rule added to the cluster or agent pool network security group
+type NetworkSecurityRule struct {
+	Name                       string   `json:"name"`
+	Description                string   `json:"description,omitempty"`
+	Priority                   int32    `json:"priority"`
+	Direction                  string   `json:"direction"`
+	Access                     string   `json:"access"`
+	Protocol                   string   `json:"protocol"`
+	SourceAddressPrefixes      []string `json:"sourceAddressPrefixes,omitempty"`
+	SourcePortRange            string   `json:"sourcePortRange,omitempty"`
+	DestinationAddressPrefixes []string `json:"destinationAddressPrefixes,omitempty"`
+	DestinationPortRanges      []string `json:"destinationPortRanges,omitempty"`
 }
 
 // FeatureFlags defines feature-flag restricted functionality
@@ -130,6 +145,10 @@ type CertificateProfile struct {
 	EtcdPeerCertificates []string `json:"etcdPeerCertificates,omitempty"`
 	// EtcdPeerPrivateKeys is list of etcd peer private keys, and signed by the CA
 	EtcdPeerPrivateKeys []string `json:"etcdPeerPrivateKeys,omitempty"`
+	// GMSAWebhookCertificate is the serving certificate of the gmsa-webhook addon, and signed by the CA
+	GMSAWebhookCertificate string `json:"gmsaWebhookCertificate,omitempty"`
+	// GMSAWebhookPrivateKey is the serving private key of the gmsa-webhook addon, and signed by the CA
+	GMSAWebhookPrivateKey string `json:"gmsaWebhookPrivateKey,omitempty"`
 }
 
 // LinuxProfile represents the linux parameters passed to the cluster
@@ -174,27 +193,43 @@ const (
 
 // WindowsProfile represents the windows parameters passed to the cluster
 type WindowsProfile struct {
-	AdminUsername                 string            `json:"adminUsername,omitempty"`
-	AdminPassword                 string            `json:"adminPassword,omitempty"`
-	CSIProxyURL                   string            `json:"csiProxyURL,omitempty"`
-	EnableCSIProxy                *bool             `json:"enableCSIProxy,omitempty"`
-	ImageRef                      *ImageReference   `json:"imageReference,omitempty"`
-	ImageVersion                  string            `json:"imageVersion,omitempty"`
-	ProvisioningScriptsPackageURL string            `json:"provisioningScriptsPackageURL,omitempty"`
-	WindowsImageSourceURL         string            `json:"WindowsImageSourceUrl"`
-	WindowsPublisher              string            `json:"WindowsPublisher"`
-	WindowsOffer                  string            `json:"WindowsOffer"`
-	WindowsSku                    string            `json:"WindowsSku"`
-	WindowsDockerVersion          string            `json:"windowsDockerVersion"`
-	Secrets                       []KeyVaultSecrets `json:"secrets,omitempty"`
-	SSHEnabled                    *bool             `json:"sshEnabled,omitempty"`
-	EnableAutomaticUpdates        *bool             `json:"enableAutomaticUpdates,omitempty"`
-	IsCredentialAutoGenerated     *bool             `json:"isCredentialAutoGenerated,omitempty"`
-	EnableAHUB                    *bool             `json:"enableAHUB,omitempty"`
-	WindowsPauseImageURL          string            `json:"windowsPauseImageURL"`
-	AlwaysPullWindowsPauseImage   *bool             `json:"alwaysPullWindowsPauseImage,omitempty"`
-	WindowsRuntimes               *WindowsRuntimes  `json:"windowsRuntimes,omitempty"`
-	WindowsSecureTLSEnabled       *bool             `json:"windowsSecureTLSEnabled,omitempty"`
+	AdminUsername                 string              `json:"adminUsername,omitempty"`
+	AdminPassword                 string              `json:"adminPassword,omitempty"`
+	CSIProxyURL                   string              `json:"csiProxyURL,omitempty"`
+	EnableCSIProxy                *bool               `json:"enableCSIProxy,omitempty"`
+	ImageRef                      *ImageReference     `json:"imageReference,omitempty"`
+	ImageVersion                  string              `json:"imageVersion,omitempty"`
+	ProvisioningScriptsPackageURL string              `json:"provisioningScriptsPackageURL,omitempty"`
+	WindowsImageSourceURL         string              `json:"WindowsImageSourceUrl"`
+	WindowsPublisher              string              `json:"WindowsPublisher"`
+	WindowsOffer                  string              `json:"WindowsOffer"`
+	WindowsSku                    string              `json:"WindowsSku"`
+	WindowsDockerVersion          string              `json:"windowsDockerVersion"`
+	Secrets                       []KeyVaultSecrets   `json:"secrets,omitempty"`
+	SSHEnabled                    *bool               `json:"sshEnabled,omitempty"`
+	EnableAutomaticUpdates        *bool               `json:"enableAutomaticUpdates,omitempty"`
+	IsCredentialAutoGenerated     *bool               `json:"isCredentialAutoGenerated,omitempty"`
+	EnableAHUB                    *bool               `json:"enableAHUB,omitempty"`
+	WindowsPauseImageURL          string              `json:"windowsPauseImageURL"`
+	AlwaysPullWindowsPauseImage   *bool               `json:"alwaysPullWindowsPauseImage,omitempty"`
+	WindowsRuntimes               *WindowsRuntimes    `json:"windowsRuntimes,omitempty"`
+	WindowsSecureTLSEnabled       *bool               `json:"windowsSecureTLSEnabled,omitempty"`
+	GMSA                          *WindowsGMSAProfile `json:"gmsa,omitempty"`
+}
+
+// WindowsGMSAProfile configures group managed service accounts (gMSA) for the Windows containers of the cluster.
+// The Windows nodes are either joined to the Active Directory domain, or retrieve the gMSA credentials
+// through a Container Credential Guard (CCG) plugin.
+// The 'DomainJoinPassword' and 'DomainJoinPasswordKeyvaultSecretRef' parameters are mutually exclusive.
+type WindowsGMSAProfile struct {
+	Mode                                string             `json:"mode,omitempty"`
+	DomainName                          string             `json:"domainName,omitempty"`
+	DomainJoinUser                      string             `json:"domainJoinUser,omitempty"`
+	DomainJoinPassword                  string             `json:"domainJoinPassword,omitempty"`
+	DomainJoinPasswordKeyvaultSecretRef *KeyvaultSecretRef `json:"domainJoinPasswordKeyvaultSecretRef,omitempty"`
+	OrganizationalUnit                  string             `json:"organizationalUnit,omitempty"`
+	CCGPluginURL                        string             `json:"ccgPluginURL,omitempty"`
+	CCGPluginCLSID                      string             `json:"ccgPluginCLSID,omitempty"`
 }
 
 // WindowsRuntimes configures containerd runtimes that are available on the windows nodes
@@ -282,6 +317,25 @@ type KubernetesAddon struct {
 	Config     map[string]string         `json:"config,omitempty"`
 	Pools      []AddonNodePoolsConfig    `json:"pools,omitempty"`
 	Data       string                    `json:"data,omitempty"`
+	Source     *AddonSource              `json:"source,omitempty"`
+}
+
+// AddonSource defines where the manifest of a user-defined custom addon comes from
+type AddonSource struct {
+	Path      string          `json:"path,omitempty"`
+	URL       string          `json:"url,omitempty"`
+	SHA256    string          `json:"sha256,omitempty"`
+	HelmChart *AddonHelmChart `json:"helmChart,omitempty"`
+	Template  bool            `json:"template,omitempty"`
+}
+
+// AddonHelmChart defines a Helm chart that is rendered offline into a custom addon manifest
+type AddonHelmChart struct {
+	Chart      string `json:"chart,omitempty"`
+	Version    string `json:"version,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	ValuesFile string `json:"valuesFile,omitempty"`
 }
 
 // IsEnabled returns true if the addon is enabled
@@ -301,6 +355,13 @@ type KubernetesComponent struct {
 	Data       string                    `json:"data,omitempty"`
 }
 
+// NATGatewayProfile defines the NAT gateway used for cluster egress
+type NATGatewayProfile struct {
+	ID                   string `json:"id,omitempty"`
+	IdleTimeoutInMinutes int32  `json:"idleTimeoutInMinutes,omitempty"`
+	PublicIPPrefixCount  int    `json:"publicIPPrefixCount,omitempty"`
+}
+
 // PrivateCluster defines the configuration for a private cluster
 type PrivateCluster struct {
 	Enabled                *bool                  `json:"enabled,omitempty"`
@@ -405,6 +466,8 @@ type KubernetesConfig struct {
 	AzureCNIVersion                     string                `json:"azureCNIVersion,omitempty"`
 	AzureCNIURLLinux                    string                `json:"azureCNIURLLinux,omitempty"`
 	AzureCNIURLWindows                  string                `json:"azureCNIURLWindows,omitempty"`
+	EtcdDownloadURLBase                 string                `json:"etcdDownloadURLBase,omitempty"`
+	CNIPluginsURL                       string                `json:"cniPluginsURL,omitempty"`
 	KeyVaultSku                         string                `json:"keyVaultSku,omitempty"`
 	MaximumLoadBalancerRuleCount        int                   `json:"maximumLoadBalancerRuleCount,omitempty"`
 	ProxyMode                           KubeProxyMode         `json:"kubeProxyMode,omitempty"`
@@ -413,6 +476,9 @@ type KubernetesConfig struct {
 	MicrosoftAptRepositoryURL           string                `json:"microsoftAptRepositoryURL,omitempty"`
 	EnableMultipleStandardLoadBalancers *bool                 `json:"enableMultipleStandardLoadBalancers,omitempty"`
 	Tags                                string                `json:"tags,omitempty"`
+	OutboundType                        string                `json:"outboundType,omitempty"`
+	RouteTableID                        string                `json:"routeTableID,omitempty"`
+	NATGatewayProfile                   *NATGatewayProfile    `json:"natGatewayProfile,omitempty"`
 }
 
 // CustomFile has source as the full absolute source path to a file and dest
@@ -545,8 +611,17 @@ type AgentPoolProfile struct {
 	ProximityPlacementGroupID         string            `json:"proximityPlacementGroupID,omitempty"`
 	OSDiskCachingType                 string            `json:"osDiskCachingType,omitempty"`
 	DataDiskCachingType               string            `json:"dataDiskCachingType,omitempty"`
+	WindowsPublisher                  string            `json:"windowsPublisher,omitempty"`
+	WindowsOffer                      string            `json:"windowsOffer,omitempty"`
+	WindowsSku                        string            `json:"windowsSku,omitempty"`
+	WindowsImageVersion               string            `json:"windowsImageVersion,omitempty"`
+	WindowsDockerVersion              string            `json:"windowsDockerVersion,omitempty"`
+	HyperVGeneration                  string            `json:"hyperVGeneration,omitempty"`
+	Architecture                      string            `json:"architecture,omitempty"`
 	// VMSSName is a read-only field; its value will be computed during template generation
 	VMSSName string `json:"vmssName,omitempty"`
+	// NetworkSecurityRules are added to a network security group dedicated to this pool
+	NetworkSecurityRules []NetworkSecurityRule `json:"networkSecurityRules,omitempty"`
 }
 
 // AgentPoolProfileRole represents an agent role
@@ -757,9 +832,19 @@ func (m *MasterProfile) IsUbuntu2004() bool {
 	}
 }
 
+// IsUbuntu2204 returns true if the master profile distro is based on Ubuntu 22.04
+func (m *MasterProfile) IsUbuntu2204() bool {
+	switch m.Distro {
+	case Ubuntu2204, Ubuntu2204Gen2:
+		return true
+	default:
+		return false
+	}
+}
+
 // IsUbuntu returns true if the master profile distro is any ubuntu distro
 func (m *MasterProfile) IsUbuntu() bool {
-	return m.IsUbuntu1604() || m.IsUbuntu1804() || m.IsUbuntu2004()
+	return m.IsUbuntu1604() || m.IsUbuntu1804() || m.IsUbuntu2004() || m.IsUbuntu2204()
 }
 
 // IsVirtualMachineScaleSets returns true if the master availability profile is VMSS
@@ -947,9 +1032,57 @@ func (a *AgentPoolProfile) IsUbuntu2004() bool {
 	return false
 }
 
+// IsUbuntu2204 returns true if the agent pool profile distro is based on Ubuntu 22.04
+func (a *AgentPoolProfile) IsUbuntu2204() bool {
+	if a.OSType != Windows {
+		switch a.Distro {
+		case Ubuntu2204, Ubuntu2204Gen2:
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
 // IsUbuntu returns true if the master profile distro is any ubuntu distro
 func (a *AgentPoolProfile) IsUbuntu() bool {
-	return a.IsUbuntu1604() || a.IsUbuntu1804() || a.IsUbuntu2004()
+	return a.IsUbuntu1604() || a.IsUbuntu1804() || a.IsUbuntu2004() || a.IsUbuntu2204()
+}
+
+// IsArm64 returns true if the agent pool runs on Arm64 VM sizes
+func (a *AgentPoolProfile) IsArm64() bool {
+	return a.Architecture == ArchitectureArm64
+}
+
+// GetArchitecture returns the CPU architecture of the agent pool, which defaults to amd64
+func (a *AgentPoolProfile) GetArchitecture() string {
+	if a.Architecture != "" {
+		return a.Architecture
+	}
+	return ArchitectureAmd64
+}
+
+// IsGen2Distro returns true if the distro is the Gen2 flavor of a distro
+func (a *AgentPoolProfile) IsGen2Distro() bool {
+	switch a.Distro {
+	case Ubuntu1804Gen2, Ubuntu2004Gen2, Ubuntu2204Gen2:
+		return true
+	default:
+		return false
+	}
+}
+
+// GetHyperVGeneration returns the Hyper-V generation of the agent pool VMs, which defaults to
+// V2 for the Gen2 distros and the Arm64 pools and to V1 otherwise
+func (a *AgentPoolProfile) GetHyperVGeneration() string {
+	if a.HyperVGeneration != "" {
+		return a.HyperVGeneration
+	}
+	if a.IsGen2Distro() || a.IsArm64() {
+		return HyperVGenerationV2
+	}
+	return HyperVGenerationV1
 }
 
 // HasSearchDomain returns true if the customer specified secrets to install
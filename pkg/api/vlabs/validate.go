@@ -32,6 +32,12 @@ var (
 	labelKeyRegex                  *regexp.Regexp
 	diskEncryptionSetIDRegex       *regexp.Regexp
 	proximityPlacementGroupIDRegex *regexp.Regexp
+	securityRuleNameRegex          *regexp.Regexp
+	routeTableIDRegex              *regexp.Regexp
+	natGatewayIDRegex              *regexp.Regexp
+	customAddonNameRegex           *regexp.Regexp
+	sha256Regex                    *regexp.Regexp
+	clsidRegex                     *regexp.Regexp
 	// Any version has to be available in a container image from mcr.microsoft.com/oss/etcd-io/etcd:v[Version]
 	etcdValidVersions = [...]string{"2.2.5", "2.3.0", "2.3.1", "2.3.2", "2.3.3", "2.3.4", "2.3.5", "2.3.6", "2.3.7", "2.3.8",
 		"3.0.0", "3.0.1", "3.0.2", "3.0.3", "3.0.4", "3.0.5", "3.0.6", "3.0.7", "3.0.8", "3.0.9", "3.0.10", "3.0.11", "3.0.12", "3.0.13", "3.0.14", "3.0.15", "3.0.16", "3.0.17",
@@ -124,57 +130,41 @@ func init() {
 	labelKeyRegex = regexp.MustCompile(labelKeyFormat)
 	diskEncryptionSetIDRegex = regexp.MustCompile(`^/subscriptions/\S+/resourceGroups/\S+/providers/Microsoft.Compute/diskEncryptionSets/[^/\s]+$`)
 	proximityPlacementGroupIDRegex = regexp.MustCompile(`^/subscriptions/\S+/resourceGroups/\S+/providers/Microsoft.Compute/proximityPlacementGroups/[^/\s]+$`)
+	securityRuleNameRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9_.-]{0,78}[a-zA-Z0-9_])?$`)
+	routeTableIDRegex = regexp.MustCompile(`(?i)^/subscriptions/[^/\s]+/resourceGroups/[^/\s]+/providers/Microsoft.Network/routeTables/[^/\s]+$`)
+	natGatewayIDRegex = regexp.MustCompile(`^/subscriptions/\S+/resourceGroups/\S+/providers/Microsoft.Network/natGateways/[^/\s]+$`)
+	customAddonNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+	sha256Regex = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+	clsidRegex = regexp.MustCompile(`^\{[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{12}\}$`)
 }
 
-// Validate implements APIObject
+// Validate implements APIObject. Every check is run so that all problems with the api model
+// are returned together as common.ValidationErrors instead of stopping at the first one.
 func (a *Properties) validate(isUpdate bool) error {
 	if e := validate.Struct(a); e != nil {
 		return handleValidationErrors(e.(validator.ValidationErrors))
 	}
+	errs := common.ValidationErrors{}
 	if e := a.ValidateOrchestratorProfile(isUpdate); e != nil {
-		return e
-	}
-	if e := a.validateMasterProfile(isUpdate); e != nil {
-		return e
-	}
-	if e := a.validateAgentPoolProfiles(isUpdate); e != nil {
-		return e
-	}
-	if e := a.validateZones(); e != nil {
-		return e
-	}
-	if e := a.validateLinuxProfile(); e != nil {
-		return e
-	}
-	if e := a.validateAddons(isUpdate); e != nil {
-		return e
-	}
-	if e := a.validateExtensions(); e != nil {
-		return e
-	}
-	if e := a.validateVNET(); e != nil {
-		return e
-	}
-	if e := a.validateServicePrincipalProfile(); e != nil {
-		return e
-	}
-
-	if e := a.validateAADProfile(); e != nil {
-		return e
-	}
-
-	if e := a.validateCustomKubeComponent(); e != nil {
-		return e
-	}
-
-	if e := a.validateAzureStackSupport(); e != nil {
-		return e
-	}
-
-	if e := a.validateWindowsProfile(isUpdate); e != nil {
-		return e
-	}
-	return nil
+		// the remaining checks rely on a valid orchestrator profile
+		errs.Add("properties.orchestratorProfile", e)
+		return errs.ErrorOrNil()
+	}
+	errs.Add("properties.masterProfile", a.validateMasterProfile(isUpdate))
+	errs.Add("properties.agentPoolProfiles", a.validateAgentPoolProfiles(isUpdate))
+	errs.Add("properties.agentPoolProfiles.availabilityZones", a.validateZones())
+	errs.Add("properties.linuxProfile", a.validateLinuxProfile())
+	errs.Add("properties.orchestratorProfile.kubernetesConfig.addons", a.validateAddons(isUpdate))
+	errs.Add("properties.extensionProfiles", a.validateExtensions())
+	errs.Add("properties.masterProfile.vnetSubnetID", a.validateVNET())
+	errs.Add("properties.servicePrincipalProfile", a.validateServicePrincipalProfile())
+	errs.Add("properties.aadProfile", a.validateAADProfile())
+	errs.Add("properties.orchestratorProfile.kubernetesConfig.customKubeBinaryURL", a.validateCustomKubeComponent())
+	errs.Add("properties", a.validateAzureStackSupport())
+	errs.Add("properties.windowsProfile", a.validateWindowsProfile(isUpdate))
+	errs.Add("properties.networkSecurityRules", a.validateNetworkSecurityRules())
+	errs.Add("properties.orchestratorProfile.kubernetesConfig.outboundType", a.validateOutboundType())
+	return errs.ErrorOrNil()
 }
 
 func handleValidationErrors(e validator.ValidationErrors) error {
@@ -420,6 +410,10 @@ func (a *Properties) validateMasterProfile(isUpdate bool) error {
 		}
 	}
 
+	if m.Distro == Flatcar || m.IsUbuntu2204() {
+		return errors.Errorf("The %s distro is only supported for agent pools", m.Distro)
+	}
+
 	var validOSDiskCachingType bool
 	for _, valid := range cachingTypesValidValues {
 		if valid == m.OSDiskCachingType {
@@ -433,150 +427,174 @@ func (a *Properties) validateMasterProfile(isUpdate bool) error {
 	return common.ValidateDNSPrefix(m.DNSPrefix)
 }
 
+// validateAgentPoolProfiles validates each pool independently, so that the problems of every pool are reported together
 func (a *Properties) validateAgentPoolProfiles(isUpdate bool) error {
-
+	errs := common.ValidationErrors{}
 	profileNames := make(map[string]bool)
-	for i, agentPoolProfile := range a.AgentPoolProfiles {
-		if e := validatePoolName(agentPoolProfile.Name); e != nil {
-			return e
-		}
+	for i := range a.AgentPoolProfiles {
+		errs.Add(fmt.Sprintf("properties.agentPoolProfiles[%d]", i), a.validateAgentPoolProfile(i, profileNames, isUpdate))
+	}
+	return errs.ErrorOrNil()
+}
 
-		// validate os type is linux if dual stack feature is enabled
-		if a.FeatureFlags.IsIPv6DualStackEnabled() || a.FeatureFlags.IsIPv6OnlyEnabled() {
-			if agentPoolProfile.OSType == Windows {
-				if a.FeatureFlags.IsIPv6DualStackEnabled() && !common.IsKubernetesVersionGe(a.OrchestratorProfile.OrchestratorVersion, "1.19.0") {
-					return errors.Errorf("Dual stack IPv6 feature is supported on Windows only from Kubernetes version 1.19, but OrchestratorProfile.OrchestratorVersion is '%s'", a.OrchestratorProfile.OrchestratorVersion)
-				}
-				if a.FeatureFlags.IsIPv6OnlyEnabled() {
-					return errors.Errorf("Single stack IPv6 feature is supported only with Linux, but agent pool '%s' is of os type %s", agentPoolProfile.Name, agentPoolProfile.OSType)
-				}
+func (a *Properties) validateAgentPoolProfile(i int, profileNames map[string]bool, isUpdate bool) error {
+	agentPoolProfile := a.AgentPoolProfiles[i]
+	if e := validatePoolName(agentPoolProfile.Name); e != nil {
+		return e
+	}
+
+	// validate os type is linux if dual stack feature is enabled
+	if a.FeatureFlags.IsIPv6DualStackEnabled() || a.FeatureFlags.IsIPv6OnlyEnabled() {
+		if agentPoolProfile.OSType == Windows {
+			if a.FeatureFlags.IsIPv6DualStackEnabled() && !common.IsKubernetesVersionGe(a.OrchestratorProfile.OrchestratorVersion, "1.19.0") {
+				return errors.Errorf("Dual stack IPv6 feature is supported on Windows only from Kubernetes version 1.19, but OrchestratorProfile.OrchestratorVersion is '%s'", a.OrchestratorProfile.OrchestratorVersion)
 			}
-			if agentPoolProfile.Distro == Flatcar {
-				return errors.Errorf("Dual stack and single stack IPv6 feature is currently supported only with Ubuntu, but agent pool '%s' is of distro type %s", agentPoolProfile.Name, agentPoolProfile.Distro)
+			if a.FeatureFlags.IsIPv6OnlyEnabled() {
+				return errors.Errorf("Single stack IPv6 feature is supported only with Linux, but agent pool '%s' is of os type %s", agentPoolProfile.Name, agentPoolProfile.OSType)
 			}
 		}
-
-		// validate that each AgentPoolProfile Name is unique
-		if _, ok := profileNames[agentPoolProfile.Name]; ok {
-			return errors.Errorf("profile name '%s' already exists, profile names must be unique across pools", agentPoolProfile.Name)
+		if agentPoolProfile.Distro == Flatcar {
+			return errors.Errorf("Dual stack and single stack IPv6 feature is currently supported only with Ubuntu, but agent pool '%s' is of distro type %s", agentPoolProfile.Name, agentPoolProfile.Distro)
 		}
-		profileNames[agentPoolProfile.Name] = true
+	}
 
-		if e := validatePoolOSType(agentPoolProfile.OSType); e != nil {
-			return e
-		}
+	// validate that each AgentPoolProfile Name is unique
+	if _, ok := profileNames[agentPoolProfile.Name]; ok {
+		return common.NewValidationError(fmt.Sprintf("properties.agentPoolProfiles[%d].name", i),
+			"profile name '%s' already exists, profile names must be unique across pools", agentPoolProfile.Name)
+	}
+	profileNames[agentPoolProfile.Name] = true
 
-		if to.Bool(agentPoolProfile.AcceleratedNetworkingEnabled) || to.Bool(agentPoolProfile.AcceleratedNetworkingEnabledWindows) {
-			if a.IsAzureStackCloud() {
-				return errors.Errorf("AcceleratedNetworkingEnabled or AcceleratedNetworkingEnabledWindows shouldn't be set to true as feature is not yet supported on Azure Stack")
-			} else if e := validatePoolAcceleratedNetworking(agentPoolProfile.VMSize); e != nil {
-				return e
-			}
-		}
+	if e := validatePoolOSType(agentPoolProfile.OSType); e != nil {
+		return e
+	}
 
-		if to.Bool(agentPoolProfile.VMSSOverProvisioningEnabled) {
-			if agentPoolProfile.AvailabilityProfile == AvailabilitySet {
-				return errors.Errorf("You have specified VMSS Overprovisioning in agent pool %s, but you did not specify VMSS", agentPoolProfile.Name)
-			}
+	if to.Bool(agentPoolProfile.AcceleratedNetworkingEnabled) || to.Bool(agentPoolProfile.AcceleratedNetworkingEnabledWindows) {
+		if a.IsAzureStackCloud() {
+			return errors.Errorf("AcceleratedNetworkingEnabled or AcceleratedNetworkingEnabledWindows shouldn't be set to true as feature is not yet supported on Azure Stack")
+		} else if e := validatePoolAcceleratedNetworking(agentPoolProfile.VMSize); e != nil {
+			return e
 		}
+	}
 
-		if to.Bool(agentPoolProfile.AuditDEnabled) {
-			if agentPoolProfile.Distro != "" && !agentPoolProfile.IsUbuntu() {
-				return errors.Errorf("You have enabled auditd in agent pool %s, but you did not specify an Ubuntu-based distro", agentPoolProfile.Name)
-			}
+	if to.Bool(agentPoolProfile.VMSSOverProvisioningEnabled) {
+		if agentPoolProfile.AvailabilityProfile == AvailabilitySet {
+			return errors.Errorf("You have specified VMSS Overprovisioning in agent pool %s, but you did not specify VMSS", agentPoolProfile.Name)
 		}
+	}
 
-		if to.Bool(agentPoolProfile.EnableVMSSNodePublicIP) {
-			if agentPoolProfile.AvailabilityProfile == AvailabilitySet {
-				return errors.Errorf("You have enabled VMSS node public IP in agent pool %s, but you did not specify VMSS", agentPoolProfile.Name)
-			}
-			if !strings.EqualFold(a.OrchestratorProfile.KubernetesConfig.LoadBalancerSku, BasicLoadBalancerSku) {
-				return errors.Errorf("You have enabled VMSS node public IP in agent pool %s, but you did not specify Basic Load Balancer SKU", agentPoolProfile.Name)
-			}
+	if to.Bool(agentPoolProfile.AuditDEnabled) {
+		if agentPoolProfile.Distro != "" && !agentPoolProfile.IsUbuntu() {
+			return errors.Errorf("You have enabled auditd in agent pool %s, but you did not specify an Ubuntu-based distro", agentPoolProfile.Name)
 		}
+	}
 
-		if e := agentPoolProfile.validateOrchestratorSpecificProperties(); e != nil {
-			return e
+	if to.Bool(agentPoolProfile.EnableVMSSNodePublicIP) {
+		if agentPoolProfile.AvailabilityProfile == AvailabilitySet {
+			return errors.Errorf("You have enabled VMSS node public IP in agent pool %s, but you did not specify VMSS", agentPoolProfile.Name)
 		}
-
-		if agentPoolProfile.ImageRef != nil {
-			if e := agentPoolProfile.ImageRef.validateImageNameAndGroup(); e != nil {
-				return e
-			}
+		if !strings.EqualFold(a.OrchestratorProfile.KubernetesConfig.LoadBalancerSku, BasicLoadBalancerSku) {
+			return errors.Errorf("You have enabled VMSS node public IP in agent pool %s, but you did not specify Basic Load Balancer SKU", agentPoolProfile.Name)
 		}
+	}
 
-		if e := agentPoolProfile.validateAvailabilityProfile(); e != nil {
-			return e
-		}
+	if e := agentPoolProfile.validateOrchestratorSpecificProperties(); e != nil {
+		return e
+	}
 
-		if e := agentPoolProfile.validateRoles(); e != nil {
+	if agentPoolProfile.ImageRef != nil {
+		if e := agentPoolProfile.ImageRef.validateImageNameAndGroup(); e != nil {
 			return e
 		}
+	}
 
-		if e := agentPoolProfile.validateCustomNodeLabels(); e != nil {
-			return e
-		}
+	if e := agentPoolProfile.validateAvailabilityProfile(); e != nil {
+		return e
+	}
 
-		if agentPoolProfile.AvailabilityProfile != AvailabilitySet {
-			e := validateVMSS(a.OrchestratorProfile, isUpdate, agentPoolProfile.StorageProfile, a.HasWindows(), a.IsAzureStackCloud())
-			if e != nil {
-				return e
-			}
-		}
+	if e := agentPoolProfile.validateRoles(); e != nil {
+		return e
+	}
 
-		if a.AgentPoolProfiles[i].AvailabilityProfile != a.AgentPoolProfiles[0].AvailabilityProfile {
-			return errors.New("mixed mode availability profiles are not allowed. Please set either VirtualMachineScaleSets or AvailabilitySet in availabilityProfile for all agent pools")
-		}
+	if e := agentPoolProfile.validateCustomNodeLabels(); e != nil {
+		return e
+	}
 
-		if a.AgentPoolProfiles[i].SinglePlacementGroup != nil && a.AgentPoolProfiles[i].AvailabilityProfile == AvailabilitySet {
-			return errors.New("singlePlacementGroup is only supported with VirtualMachineScaleSets")
-		}
+	if e := agentPoolProfile.validateWindowsImage(); e != nil {
+		return e
+	}
 
-		distroValues := DistroValues
-		if isUpdate {
-			distroValues = append(distroValues, AKSDockerEngine, AKS1604Deprecated, AKS1804Deprecated)
-		}
-		if !validateDistro(agentPoolProfile.Distro, distroValues) {
-			switch agentPoolProfile.Distro {
-			case AKSDockerEngine, AKS1604Deprecated:
-				return errors.Errorf("The %s distro is deprecated, please use %s instead", agentPoolProfile.Distro, AKSUbuntu1604)
-			case AKS1804Deprecated:
-				return errors.Errorf("The %s distro is deprecated, please use %s instead", agentPoolProfile.Distro, AKSUbuntu1804)
-			default:
-				return errors.Errorf("The %s distro is not supported", agentPoolProfile.Distro)
-			}
-		}
+	if e := agentPoolProfile.validateFlatcar(); e != nil {
+		return e
+	}
 
-		if e := agentPoolProfile.validateLoadBalancerBackendAddressPoolIDs(); e != nil {
-			return e
-		}
+	if e := agentPoolProfile.validateImageFlavor(); e != nil {
+		return e
+	}
 
-		if agentPoolProfile.IsEphemeral() {
-			log.Warnf("Ephemeral disks are enabled for Agent Pool %s. This feature in AKS-Engine is experimental, and data could be lost in some cases.", agentPoolProfile.Name)
-		}
+	if e := a.validateUbuntu2204(agentPoolProfile); e != nil {
+		return e
+	}
 
-		if e := validateProximityPlacementGroupID(agentPoolProfile.ProximityPlacementGroupID); e != nil {
+	if agentPoolProfile.AvailabilityProfile != AvailabilitySet {
+		e := validateVMSS(a.OrchestratorProfile, isUpdate, agentPoolProfile.StorageProfile, a.HasWindows(), a.IsAzureStackCloud())
+		if e != nil {
 			return e
 		}
-		var validOSDiskCachingType, validDataDiskCachingType bool
-		for _, valid := range cachingTypesValidValues {
-			if valid == agentPoolProfile.OSDiskCachingType {
-				validOSDiskCachingType = true
-			}
-			if valid == agentPoolProfile.DataDiskCachingType {
-				validDataDiskCachingType = true
-			}
+	}
+
+	if a.AgentPoolProfiles[i].AvailabilityProfile != a.AgentPoolProfiles[0].AvailabilityProfile {
+		return errors.New("mixed mode availability profiles are not allowed. Please set either VirtualMachineScaleSets or AvailabilitySet in availabilityProfile for all agent pools")
+	}
+
+	if a.AgentPoolProfiles[i].SinglePlacementGroup != nil && a.AgentPoolProfiles[i].AvailabilityProfile == AvailabilitySet {
+		return errors.New("singlePlacementGroup is only supported with VirtualMachineScaleSets")
+	}
+
+	distroValues := DistroValues
+	if isUpdate {
+		distroValues = append(distroValues, AKSDockerEngine, AKS1604Deprecated, AKS1804Deprecated)
+	}
+	if !validateDistro(agentPoolProfile.Distro, distroValues) {
+		switch agentPoolProfile.Distro {
+		case AKSDockerEngine, AKS1604Deprecated:
+			return errors.Errorf("The %s distro is deprecated, please use %s instead", agentPoolProfile.Distro, AKSUbuntu1604)
+		case AKS1804Deprecated:
+			return errors.Errorf("The %s distro is deprecated, please use %s instead", agentPoolProfile.Distro, AKSUbuntu1804)
+		default:
+			return errors.Errorf("The %s distro is not supported", agentPoolProfile.Distro)
 		}
-		if !validOSDiskCachingType {
-			return errors.Errorf("Invalid osDiskCachingType value \"%s\" for agentPoolProfile \"%s\", please use one of the following versions: %s", agentPoolProfile.OSDiskCachingType, agentPoolProfile.Name, cachingTypesValidValues)
+	}
+
+	if e := agentPoolProfile.validateLoadBalancerBackendAddressPoolIDs(); e != nil {
+		return e
+	}
+
+	if agentPoolProfile.IsEphemeral() {
+		log.Warnf("Ephemeral disks are enabled for Agent Pool %s. This feature in AKS-Engine is experimental, and data could be lost in some cases.", agentPoolProfile.Name)
+	}
+
+	if e := validateProximityPlacementGroupID(agentPoolProfile.ProximityPlacementGroupID); e != nil {
+		return e
+	}
+	var validOSDiskCachingType, validDataDiskCachingType bool
+	for _, valid := range cachingTypesValidValues {
+		if valid == agentPoolProfile.OSDiskCachingType {
+			validOSDiskCachingType = true
 		}
-		if !validDataDiskCachingType {
-			return errors.Errorf("Invalid dataDiskCachingType value \"%s\" for agentPoolProfile \"%s\", please use one of the following versions: %s", agentPoolProfile.DataDiskCachingType, agentPoolProfile.Name, cachingTypesValidValues)
+		if valid == agentPoolProfile.DataDiskCachingType {
+			validDataDiskCachingType = true
 		}
-		if agentPoolProfile.IsEphemeral() {
-			if agentPoolProfile.OSDiskCachingType != "" && agentPoolProfile.OSDiskCachingType != string(compute.CachingTypesReadOnly) {
-				return errors.Errorf("Invalid osDiskCachingType value \"%s\" for agentPoolProfile \"%s\" using Ephemeral Disk, you must use: %s", agentPoolProfile.OSDiskCachingType, agentPoolProfile.Name, string(compute.CachingTypesReadOnly))
-			}
+	}
+	if !validOSDiskCachingType {
+		return errors.Errorf("Invalid osDiskCachingType value \"%s\" for agentPoolProfile \"%s\", please use one of the following versions: %s", agentPoolProfile.OSDiskCachingType, agentPoolProfile.Name, cachingTypesValidValues)
+	}
+	if !validDataDiskCachingType {
+		return errors.Errorf("Invalid dataDiskCachingType value \"%s\" for agentPoolProfile \"%s\", please use one of the following versions: %s", agentPoolProfile.DataDiskCachingType, agentPoolProfile.Name, cachingTypesValidValues)
+	}
+	if agentPoolProfile.IsEphemeral() {
+		if agentPoolProfile.OSDiskCachingType != "" && agentPoolProfile.OSDiskCachingType != string(compute.CachingTypesReadOnly) {
+			return errors.Errorf("Invalid osDiskCachingType value \"%s\" for agentPoolProfile \"%s\" using Ephemeral Disk, you must use: %s", agentPoolProfile.OSDiskCachingType, agentPoolProfile.Name, string(compute.CachingTypesReadOnly))
 		}
 	}
 
@@ -658,12 +676,61 @@ func (a *Properties) validateLinuxProfile() error {
 			return errors.New("KeyData in LinuxProfile.SSH.PublicKeys cannot be empty string")
 		}
 	}
+	if a.LinuxProfile.HasSearchDomain() && a.HasFlatcar() {
+		return errors.New("linuxProfile.customSearchDomain is not supported with Flatcar node pools")
+	}
 	if a.LinuxProfile.EnableUnattendedUpgrades == nil {
 		log.Warnf("linuxProfile.enableUnattendedUpgrades configuration was not declared, your cluster nodes will be configured to run unattended-upgrade by default")
 	}
 	return validateKeyVaultSecrets(a.LinuxProfile.Secrets, false)
 }
 
+func validateAddonSource(addon KubernetesAddon) error {
+	if !customAddonNameRegex.MatchString(addon.Name) {
+		return errors.Errorf("custom addon name '%s' is invalid, it must consist of lower case alphanumeric characters or '-', start and end with an alphanumeric character, and be at most 63 characters", addon.Name)
+	}
+	if common.IsBuiltinAddonName(addon.Name) {
+		return errors.Errorf("custom addon %s has the name of a built-in addon", addon.Name)
+	}
+	var sources int
+	for _, isSet := range []bool{addon.Source.Path != "", addon.Source.URL != "", addon.Source.HelmChart != nil} {
+		if isSet {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return errors.Errorf("custom addon %s must specify exactly one of source.path, source.url or source.helmChart", addon.Name)
+	}
+	if addon.Source.URL != "" {
+		u, err := url.Parse(addon.Source.URL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return errors.Errorf("custom addon %s source.url '%s' must be a valid https URL", addon.Name, addon.Source.URL)
+		}
+		if !sha256Regex.MatchString(addon.Source.SHA256) {
+			return errors.Errorf("custom addon %s source.url requires source.sha256 to be the hex-encoded SHA-256 checksum of the manifest", addon.Name)
+		}
+	} else if addon.Source.SHA256 != "" {
+		return errors.Errorf("custom addon %s source.sha256 may only be used with source.url", addon.Name)
+	}
+	if addon.Source.HelmChart != nil {
+		if addon.Source.HelmChart.Chart == "" {
+			return errors.Errorf("custom addon %s source.helmChart.chart must be specified", addon.Name)
+		}
+		if len(addon.Containers) > 0 {
+			return errors.Errorf("custom addon %s containers are not supported with source.helmChart, use config to set chart values instead", addon.Name)
+		}
+		if addon.Source.Template {
+			return errors.Errorf("custom addon %s source.template is not supported with source.helmChart, Helm renders the chart templates", addon.Name)
+		}
+	}
+	if addon.Data != "" {
+		if _, err := base64.StdEncoding.DecodeString(addon.Data); err != nil {
+			return errors.Errorf("Addon %s's data should be base64 encoded", addon.Name)
+		}
+	}
+	return nil
+}
+
 func (a *Properties) validateAddons(isUpdate bool) error {
 	if a.OrchestratorProfile.KubernetesConfig != nil && a.OrchestratorProfile.KubernetesConfig.Addons != nil {
 		var isAvailabilitySets bool
@@ -677,7 +744,11 @@ func (a *Properties) validateAddons(isUpdate bool) error {
 			}
 		}
 		for _, addon := range a.OrchestratorProfile.KubernetesConfig.Addons {
-			if addon.Data != "" {
+			if addon.Source != nil {
+				if err := validateAddonSource(addon); err != nil {
+					return err
+				}
+			} else if addon.Data != "" {
 				if len(addon.Config) > 0 || len(addon.Containers) > 0 {
 					return errors.New("Config and containers should be empty when addon.Data is specified")
 				}
@@ -1000,6 +1071,112 @@ func (a *AgentPoolProfile) validateRoles() error {
 	return nil
 }
 
+func (a *AgentPoolProfile) validateWindowsImage() error {
+	hasMarketplaceImage := a.WindowsPublisher != "" || a.WindowsOffer != "" || a.WindowsSku != "" || a.WindowsImageVersion != ""
+	if !a.IsWindows() {
+		if hasMarketplaceImage || a.WindowsDockerVersion != "" {
+			return errors.Errorf("agent pool %s specifies Windows image properties, which are only supported with osType %s", a.Name, Windows)
+		}
+		return nil
+	}
+	if hasMarketplaceImage && a.ImageRef != nil {
+		return errors.Errorf("Windows agent pool %s can specify either imageReference or windowsPublisher, windowsOffer, windowsSku and windowsImageVersion, but not both", a.Name)
+	}
+	return nil
+}
+
+// validateFlatcar rejects pool features that are provisioned with apt packages or Ubuntu-only drivers
+func (a *AgentPoolProfile) validateFlatcar() error {
+	if !a.IsFlatcar() {
+		return nil
+	}
+	if common.IsNvidiaEnabledSKU(a.VMSize) {
+		return errors.Errorf("Flatcar agent pool %s uses N-series VM size %s, the NVIDIA drivers are only supported with Ubuntu-based distros", a.Name, a.VMSize)
+	}
+	if common.IsSgxEnabledSKU(a.VMSize) {
+		return errors.Errorf("Flatcar agent pool %s uses DC-series VM size %s, the SGX drivers are only supported with Ubuntu-based distros", a.Name, a.VMSize)
+	}
+	return nil
+}
+
+// validateImageFlavor checks the Hyper-V generation and the CPU architecture of the pool against its distro and VM size
+func (a *AgentPoolProfile) validateImageFlavor() error {
+	var validHyperVGeneration, validArchitecture bool
+	for _, valid := range HyperVGenerationValues {
+		if valid == a.HyperVGeneration {
+			validHyperVGeneration = true
+		}
+	}
+	for _, valid := range ArchitectureValues {
+		if valid == a.Architecture {
+			validArchitecture = true
+		}
+	}
+	if !validHyperVGeneration {
+		return errors.Errorf("Invalid hyperVGeneration value \"%s\" for agentPoolProfile \"%s\", please use one of the following values: %s", a.HyperVGeneration, a.Name, HyperVGenerationValues[1:])
+	}
+	if !validArchitecture {
+		return errors.Errorf("Invalid architecture value \"%s\" for agentPoolProfile \"%s\", please use one of the following values: %s", a.Architecture, a.Name, ArchitectureValues[1:])
+	}
+	if a.IsWindows() {
+		if a.HyperVGeneration != "" || a.Architecture != "" {
+			return errors.Errorf("agent pool %s specifies hyperVGeneration or architecture, which are only supported with osType %s", a.Name, Linux)
+		}
+		return nil
+	}
+	if a.IsGen2Distro() && a.HyperVGeneration == HyperVGenerationV1 {
+		return errors.Errorf("agent pool %s uses the Gen2 distro %s, which cannot be combined with hyperVGeneration %s", a.Name, a.Distro, HyperVGenerationV1)
+	}
+	if a.ImageRef == nil {
+		if a.IsArm64() {
+			switch a.Distro {
+			case "", Ubuntu2004, Ubuntu2004Gen2, Ubuntu2204, Ubuntu2204Gen2:
+			default:
+				return errors.Errorf("agent pool %s uses architecture %s, which is only supported with the %s and %s distros", a.Name, ArchitectureArm64, Ubuntu2004, Ubuntu2204)
+			}
+		}
+		if a.HyperVGeneration == HyperVGenerationV2 {
+			switch a.Distro {
+			case "", Ubuntu1804, Ubuntu1804Gen2, Ubuntu2004, Ubuntu2004Gen2, Ubuntu2204, Ubuntu2204Gen2, Flatcar:
+			default:
+				return errors.Errorf("agent pool %s uses hyperVGeneration %s, which is not supported with the %s distro", a.Name, HyperVGenerationV2, a.Distro)
+			}
+		}
+	}
+	if a.IsArm64() && a.GetHyperVGeneration() != HyperVGenerationV2 {
+		return errors.Errorf("agent pool %s uses architecture %s, which requires hyperVGeneration %s", a.Name, ArchitectureArm64, HyperVGenerationV2)
+	}
+	// only the VM sizes of the generated SKU list are checked, so that new VM sizes can be used before the list is updated
+	if sku, ok := helpers.GetVMSku(a.VMSize); ok {
+		// the Hyper-V generation of a custom image is unknown unless it is set explicitly
+		if (a.ImageRef == nil || a.HyperVGeneration != "") && !sku.SupportsHyperVGeneration(a.GetHyperVGeneration()) {
+			return errors.Errorf("VM size %s of agent pool %s does not support hyperVGeneration %s", a.VMSize, a.Name, a.GetHyperVGeneration())
+		}
+		if sku.IsArm64() != a.IsArm64() {
+			return errors.Errorf("VM size %s of agent pool %s has an %s CPU, which does not match the %s architecture of the pool", a.VMSize, a.Name, sku.CPUArchitectureType, a.GetArchitecture())
+		}
+	}
+	return nil
+}
+
+// validateUbuntu2204 rejects pool features that Ubuntu 22.04 pools don't support yet.
+// Ubuntu 22.04 boots with the unified cgroup v2 hierarchy, which requires the systemd cgroup driver
+func (a *Properties) validateUbuntu2204(agentPoolProfile *AgentPoolProfile) error {
+	if !agentPoolProfile.IsUbuntu2204() {
+		return nil
+	}
+	if !common.IsKubernetesVersionGe(a.OrchestratorProfile.OrchestratorVersion, "1.22.0") {
+		return errors.Errorf("agent pool %s uses the %s distro, which requires Kubernetes 1.22.0 or greater", agentPoolProfile.Name, agentPoolProfile.Distro)
+	}
+	if a.OrchestratorProfile.KubernetesConfig == nil || a.OrchestratorProfile.KubernetesConfig.ContainerRuntime != Containerd {
+		return errors.Errorf("agent pool %s uses the %s distro, which is only supported with the %s container runtime", agentPoolProfile.Name, agentPoolProfile.Distro, Containerd)
+	}
+	if common.IsNvidiaEnabledSKU(agentPoolProfile.VMSize) || common.IsSgxEnabledSKU(agentPoolProfile.VMSize) {
+		return errors.Errorf("agent pool %s uses the %s distro, which does not support the GPU and SGX drivers of VM size %s yet", agentPoolProfile.Name, agentPoolProfile.Distro, agentPoolProfile.VMSize)
+	}
+	return nil
+}
+
 func (a *AgentPoolProfile) validateCustomNodeLabels() error {
 	if len(a.CustomNodeLabels) > 0 {
 		for k, v := range a.CustomNodeLabels {
@@ -1098,7 +1275,10 @@ func (a *Properties) validateWindowsProfile(isUpdate bool) error {
 	if e := validateCsiProxyWindowsProperties(w, version); e != nil {
 		return e
 	}
-	if e := validateWindowsRuntimes(w.WindowsRuntimes); e != nil {
+	if e := a.validateWindowsRuntimes(w.WindowsRuntimes); e != nil {
+		return e
+	}
+	if e := validateWindowsGMSAProfile(w.GMSA, version); e != nil {
 		return e
 	}
 
@@ -1112,7 +1292,52 @@ func validateCsiProxyWindowsProperties(w *WindowsProfile, k8sVersion string) err
 	return nil
 }
 
-func validateWindowsRuntimes(r *WindowsRuntimes) error {
+func validateWindowsGMSAProfile(g *WindowsGMSAProfile, k8sVersion string) error {
+	if g == nil {
+		return nil
+	}
+	if !common.IsKubernetesVersionGe(k8sVersion, "1.18.0") {
+		return errors.New("gMSA for Windows is only available in Kubernetes versions 1.18.0 or greater")
+	}
+	if e := validate.Var(g.DomainName, "required"); e != nil {
+		return errors.New("WindowsProfile.GMSA.DomainName is required")
+	}
+	switch g.Mode {
+	case "", WindowsGMSAModeDomainJoin:
+		if e := validate.Var(g.DomainJoinUser, "required"); e != nil {
+			return errors.Errorf("WindowsProfile.GMSA.DomainJoinUser is required with mode %s", WindowsGMSAModeDomainJoin)
+		}
+		if (g.DomainJoinPassword == "") == (g.DomainJoinPasswordKeyvaultSecretRef == nil) {
+			return errors.Errorf("either WindowsProfile.GMSA.DomainJoinPassword or WindowsProfile.GMSA.DomainJoinPasswordKeyvaultSecretRef must be specified with mode %s", WindowsGMSAModeDomainJoin)
+		}
+		if ref := g.DomainJoinPasswordKeyvaultSecretRef; ref != nil {
+			if e := validate.Var(ref.SecretName, "required"); e != nil {
+				return errors.New("the Keyvault Secret must be specified for the gMSA domain join password")
+			}
+			if !keyvaultIDRegex.MatchString(ref.VaultID) {
+				return errors.New("gMSA domain join password keyvault secret reference is of incorrect format")
+			}
+		}
+		if g.CCGPluginURL != "" || g.CCGPluginCLSID != "" {
+			return errors.Errorf("WindowsProfile.GMSA.CCGPluginURL and WindowsProfile.GMSA.CCGPluginCLSID can only be specified with mode %s", WindowsGMSAModeCCGPlugin)
+		}
+	case WindowsGMSAModeCCGPlugin:
+		if g.DomainJoinUser != "" || g.DomainJoinPassword != "" || g.DomainJoinPasswordKeyvaultSecretRef != nil || g.OrganizationalUnit != "" {
+			return errors.Errorf("the domain join properties of WindowsProfile.GMSA can only be specified with mode %s", WindowsGMSAModeDomainJoin)
+		}
+		if e := validate.Var(g.CCGPluginURL, "required,url"); e != nil {
+			return errors.Errorf("WindowsProfile.GMSA.CCGPluginURL must be the URL of the CCG plugin package with mode %s", WindowsGMSAModeCCGPlugin)
+		}
+		if !clsidRegex.MatchString(g.CCGPluginCLSID) {
+			return errors.Errorf("WindowsProfile.GMSA.CCGPluginCLSID must be the CLSID of the CCG plugin COM class, e.g. {00000000-0000-0000-0000-000000000000}, with mode %s", WindowsGMSAModeCCGPlugin)
+		}
+	default:
+		return errors.Errorf("WindowsProfile.GMSA.Mode %q is not supported, supported modes are %s and %s", g.Mode, WindowsGMSAModeDomainJoin, WindowsGMSAModeCCGPlugin)
+	}
+	return nil
+}
+
+func (a *Properties) validateWindowsRuntimes(r *WindowsRuntimes) error {
 	if r == nil {
 		// can be blank defaults will be applied
 		return nil
@@ -1136,6 +1361,17 @@ func validateWindowsRuntimes(r *WindowsRuntimes) error {
 		}
 	}
 
+	if r.Default == "hyperv" || len(r.HypervRuntimes) > 0 {
+		if a.OrchestratorProfile.KubernetesConfig == nil || a.OrchestratorProfile.KubernetesConfig.ContainerRuntime != Containerd {
+			return errors.Errorf("Hyper-V isolated Windows containers are only supported with the %s container runtime", Containerd)
+		}
+		for _, agentPoolProfile := range a.AgentPoolProfiles {
+			if agentPoolProfile.OSType == Windows && !helpers.NestedVirtualizationSupported(agentPoolProfile.VMSize) {
+				return errors.Errorf("Hyper-V isolated Windows containers require nested virtualization, which VM size %s of Windows agent pool %s does not support", agentPoolProfile.VMSize, agentPoolProfile.Name)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -1208,6 +1444,399 @@ func validateProximityPlacementGroupID(ppgID string) error {
 	return nil
 }
 
+// defaultNetworkSecurityRules returns the security rules aks-engine adds to the cluster network security group
+func (a *Properties) defaultNetworkSecurityRules() []NetworkSecurityRule {
+	kubeTLSSource := "*"
+	if a.OrchestratorProfile != nil && a.OrchestratorProfile.KubernetesConfig != nil &&
+		a.OrchestratorProfile.KubernetesConfig.PrivateCluster != nil && to.Bool(a.OrchestratorProfile.KubernetesConfig.PrivateCluster.Enabled) {
+		kubeTLSSource = "VirtualNetwork"
+	}
+	rules := []NetworkSecurityRule{
+		{Name: common.NetworkSecurityRuleAllowKubeTLS, Priority: 100, Direction: "Inbound", Access: "Allow", Protocol: "Tcp",
+			SourceAddressPrefixes: []string{kubeTLSSource}, DestinationPortRanges: []string{"443"}},
+		{Name: common.NetworkSecurityRuleAllowSSH, Priority: 101, Direction: "Inbound", Access: "Allow", Protocol: "Tcp",
+			DestinationPortRanges: []string{"22"}},
+	}
+	if a.HasWindows() {
+		rules = append(rules, rdpNetworkSecurityRule)
+	}
+	return append(rules, a.blockOutboundNetworkSecurityRules()...)
+}
+
+// defaultAgentPoolNetworkSecurityRules returns the security rules aks-engine adds to the network security group
+// of an agent pool, the rules that only apply to masters are left out
+func (a *Properties) defaultAgentPoolNetworkSecurityRules(pool *AgentPoolProfile) []NetworkSecurityRule {
+	var rules []NetworkSecurityRule
+	if pool.OSType == Windows {
+		rules = append(rules, rdpNetworkSecurityRule)
+	}
+	return append(rules, a.blockOutboundNetworkSecurityRules()...)
+}
+
+var rdpNetworkSecurityRule = NetworkSecurityRule{Name: common.NetworkSecurityRuleAllowRDP, Priority: 102, Direction: "Inbound", Access: "Allow", Protocol: "Tcp",
+	DestinationPortRanges: []string{"3389"}}
+
+func (a *Properties) blockOutboundNetworkSecurityRules() []NetworkSecurityRule {
+	if a.FeatureFlags == nil || !a.FeatureFlags.BlockOutboundInternet {
+		return nil
+	}
+	return []NetworkSecurityRule{
+		// the destination is the master subnet, which is only known once defaults are set,
+		// so this rule is never considered to match all of the traffic of another rule
+		{Name: common.NetworkSecurityRuleAllowVNET, Priority: 110, Direction: "Outbound", Access: "Allow", Protocol: "*",
+			SourceAddressPrefixes: []string{"VirtualNetwork"}, DestinationAddressPrefixes: []string{"masterSubnet"}},
+		{Name: common.NetworkSecurityRuleBlockOutbound, Priority: 120, Direction: "Outbound", Access: "Deny", Protocol: "*"},
+	}
+}
+
+func (a *Properties) validateOutboundType() error {
+	if a.OrchestratorProfile == nil || a.OrchestratorProfile.KubernetesConfig == nil {
+		return nil
+	}
+	k := a.OrchestratorProfile.KubernetesConfig
+	switch k.OutboundType {
+	case "", OutboundTypeLoadBalancer:
+	case OutboundTypeUserDefinedRouting:
+		if k.RouteTableID == "" {
+			return errors.Errorf("outboundType %s requires a routeTableID", OutboundTypeUserDefinedRouting)
+		}
+		if !routeTableIDRegex.MatchString(k.RouteTableID) {
+			return errors.Errorf("routeTableID '%s' is not a valid route table resource ID", k.RouteTableID)
+		}
+	case OutboundTypeManagedNATGateway, OutboundTypeUserAssignedNATGateway:
+		if a.IsAzureStackCloud() {
+			return errors.Errorf("outboundType %s is not supported on Azure Stack", k.OutboundType)
+		}
+		if err := k.validateNATGatewayProfile(a.MasterProfile != nil && a.MasterProfile.IsCustomVNET()); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("Invalid outboundType '%s', only %s, %s, %s and %s are supported", k.OutboundType,
+			OutboundTypeLoadBalancer, OutboundTypeUserDefinedRouting, OutboundTypeManagedNATGateway, OutboundTypeUserAssignedNATGateway)
+	}
+	if k.RouteTableID != "" && k.OutboundType != OutboundTypeUserDefinedRouting {
+		return errors.Errorf("routeTableID is only supported with outboundType %s", OutboundTypeUserDefinedRouting)
+	}
+	if k.NATGatewayProfile != nil && k.OutboundType != OutboundTypeManagedNATGateway && k.OutboundType != OutboundTypeUserAssignedNATGateway {
+		return errors.Errorf("natGatewayProfile is only supported with outboundType %s or %s", OutboundTypeManagedNATGateway, OutboundTypeUserAssignedNATGateway)
+	}
+	if k.OutboundType != "" && k.OutboundType != OutboundTypeLoadBalancer && !strings.EqualFold(k.LoadBalancerSku, StandardLoadBalancerSku) {
+		return errors.Errorf("outboundType %s requires loadBalancerSku %s", k.OutboundType, StandardLoadBalancerSku)
+	}
+	return nil
+}
+
+func (k *KubernetesConfig) validateNATGatewayProfile(isCustomVNET bool) error {
+	n := k.NATGatewayProfile
+	if n == nil {
+		n = &NATGatewayProfile{}
+	}
+	if k.OutboundType == OutboundTypeUserAssignedNATGateway {
+		if n.ID == "" {
+			return errors.Errorf("outboundType %s requires a natGatewayProfile.id", OutboundTypeUserAssignedNATGateway)
+		}
+		if !natGatewayIDRegex.MatchString(n.ID) {
+			return errors.Errorf("natGatewayProfile.id '%s' is not a valid NAT gateway resource ID", n.ID)
+		}
+		if n.IdleTimeoutInMinutes != 0 || n.PublicIPPrefixCount != 0 {
+			return errors.Errorf("natGatewayProfile.idleTimeoutInMinutes and natGatewayProfile.publicIPPrefixCount are only supported with outboundType %s", OutboundTypeManagedNATGateway)
+		}
+		return nil
+	}
+	if n.ID != "" {
+		return errors.Errorf("natGatewayProfile.id is only supported with outboundType %s", OutboundTypeUserAssignedNATGateway)
+	}
+	if isCustomVNET {
+		return errors.Errorf("outboundType %s can only associate the NAT gateway with subnets created by aks-engine, associate a NAT gateway with your subnets and use outboundType %s instead", OutboundTypeManagedNATGateway, OutboundTypeUserAssignedNATGateway)
+	}
+	if n.IdleTimeoutInMinutes != 0 && (n.IdleTimeoutInMinutes < common.MinNATGatewayIdleTimeoutInMinutes || n.IdleTimeoutInMinutes > common.MaxNATGatewayIdleTimeoutInMinutes) {
+		return errors.Errorf("natGatewayProfile.idleTimeoutInMinutes was set to %d, it must be between %d and %d", n.IdleTimeoutInMinutes, common.MinNATGatewayIdleTimeoutInMinutes, common.MaxNATGatewayIdleTimeoutInMinutes)
+	}
+	if n.PublicIPPrefixCount < 0 || n.PublicIPPrefixCount > common.MaxNATGatewayPublicIPPrefixCount {
+		return errors.Errorf("natGatewayProfile.publicIPPrefixCount was set to %d, it must be between 1 and %d", n.PublicIPPrefixCount, common.MaxNATGatewayPublicIPPrefixCount)
+	}
+	return nil
+}
+
+func (a *Properties) validateNetworkSecurityRules() error {
+	errs := common.ValidationErrors{}
+	if _, err := validateNetworkSecurityRuleList(a.NetworkSecurityRules, a.defaultNetworkSecurityRules()); err != nil {
+		errs.Add("properties.networkSecurityRules", err)
+		return errs.ErrorOrNil()
+	}
+	for i, pool := range a.AgentPoolProfiles {
+		if len(pool.NetworkSecurityRules) == 0 {
+			continue
+		}
+		// the network security group of a pool replaces the cluster one on its nodes, it holds the default
+		// rules that apply to agents and the cluster rules, followed by the pool rules
+		inherited, err := validateNetworkSecurityRuleList(a.NetworkSecurityRules, a.defaultAgentPoolNetworkSecurityRules(pool))
+		if err == nil {
+			_, err = validateNetworkSecurityRuleList(pool.NetworkSecurityRules, inherited)
+		}
+		if err != nil {
+			errs.Add(fmt.Sprintf("properties.agentPoolProfiles[%d].networkSecurityRules", i), errors.Wrapf(err, "agent pool '%s'", pool.Name))
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// validateNetworkSecurityRuleList validates rules and checks that they fit along the inherited rules,
+// it returns the resulting set of rules, in which a rule replaces the inherited rule with the same name
+func validateNetworkSecurityRuleList(rules []NetworkSecurityRule, inherited []NetworkSecurityRule) ([]NetworkSecurityRule, error) {
+	names := map[string]bool{}
+	for _, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return nil, err
+		}
+		if names[rule.Name] {
+			return nil, errors.Errorf("networkSecurityRule name '%s' is used more than once", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+	var merged []NetworkSecurityRule
+	for _, rule := range inherited {
+		if !names[rule.Name] {
+			merged = append(merged, rule)
+		}
+	}
+	for _, rule := range rules {
+		for _, other := range merged {
+			if other.Direction == rule.Direction && other.Priority == rule.Priority {
+				return nil, errors.Errorf("networkSecurityRule '%s' has the same priority %d and direction %s as rule '%s'", rule.Name, rule.Priority, rule.Direction, other.Name)
+			}
+		}
+		merged = append(merged, rule)
+	}
+	// a rule that only matches traffic a rule with a lower priority already matches is never applied,
+	// whereas an inherited rule may be overridden that way
+	for _, rule := range rules {
+		for _, other := range merged {
+			if other.Direction == rule.Direction && other.Priority < rule.Priority && other.matchesAllTrafficOf(&rule) {
+				return nil, errors.Errorf("networkSecurityRule '%s' is never applied, rule '%s' with priority %d matches all of its traffic first", rule.Name, other.Name, other.Priority)
+			}
+		}
+	}
+	return merged, nil
+}
+
+// matchesAllTrafficOf returns true if every packet matched by other is also matched by r
+func (r *NetworkSecurityRule) matchesAllTrafficOf(other *NetworkSecurityRule) bool {
+	if r.Protocol != "*" && r.Protocol != other.Protocol {
+		return false
+	}
+	return addressPrefixesContain(r.SourceAddressPrefixes, other.SourceAddressPrefixes) &&
+		addressPrefixesContain(r.DestinationAddressPrefixes, other.DestinationAddressPrefixes) &&
+		portRangesContain([]string{r.SourcePortRange}, []string{other.SourcePortRange}) &&
+		portRangesContain(r.DestinationPortRanges, other.DestinationPortRanges)
+}
+
+// addressPrefixesContain returns true if every address of prefixes is in one of the outer prefixes,
+// no prefixes means any address. Service tags only contain themselves.
+func addressPrefixesContain(outer, prefixes []string) bool {
+	if len(outer) == 0 || outer[0] == "*" {
+		return true
+	}
+	if len(prefixes) == 0 || prefixes[0] == "*" {
+		return false
+	}
+	for _, prefix := range prefixes {
+		contained := false
+		for _, o := range outer {
+			if strings.EqualFold(o, prefix) || cidrContains(o, prefix) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			return false
+		}
+	}
+	return true
+}
+
+// cidrContains returns true if the IP address or CIDR inner is within the IP address or CIDR outer
+func cidrContains(outer, inner string) bool {
+	outerNet, ok := parseIPNet(outer)
+	if !ok {
+		return false
+	}
+	innerNet, ok := parseIPNet(inner)
+	if !ok {
+		return false
+	}
+	outerOnes, outerBits := outerNet.Mask.Size()
+	innerOnes, innerBits := innerNet.Mask.Size()
+	return outerBits == innerBits && outerOnes <= innerOnes && outerNet.Contains(innerNet.IP)
+}
+
+func parseIPNet(prefix string) (*net.IPNet, bool) {
+	if ip := net.ParseIP(prefix); ip != nil {
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 8 * net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, true
+	}
+	_, ipNet, err := net.ParseCIDR(prefix)
+	return ipNet, err == nil
+}
+
+// portRangesContain returns true if every port of portRanges is in one of the outer port ranges,
+// no port range means any port
+func portRangesContain(outer, portRanges []string) bool {
+	if len(outer) == 0 {
+		outer = []string{"*"}
+	}
+	if len(portRanges) == 0 {
+		portRanges = []string{"*"}
+	}
+	for _, portRange := range portRanges {
+		first, last := parsePortRange(portRange)
+		contained := false
+		for _, o := range outer {
+			oFirst, oLast := parsePortRange(o)
+			if oFirst <= first && last <= oLast {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePortRange returns the bounds of a port range validated by validateSecurityRulePortRange
+func parsePortRange(portRange string) (int, int) {
+	if portRange == "" || portRange == "*" {
+		return 0, 65535
+	}
+	bounds := strings.Split(portRange, "-")
+	first, _ := strconv.Atoi(bounds[0])
+	last := first
+	if len(bounds) == 2 {
+		last, _ = strconv.Atoi(bounds[1])
+	}
+	return first, last
+}
+
+func (r *NetworkSecurityRule) validate() error {
+	if !securityRuleNameRegex.MatchString(r.Name) {
+		return errors.Errorf("networkSecurityRule name '%s' is invalid, it must have at most 80 characters, begin with a letter or number, end with a letter, number or underscore, and only contain letters, numbers, underscores, periods, or hyphens", r.Name)
+	}
+	if r.Priority < common.MinNetworkSecurityRulePriority || r.Priority > common.MaxNetworkSecurityRulePriority {
+		return errors.Errorf("networkSecurityRule '%s' has priority %d, the priority must be between %d and %d", r.Name, r.Priority, common.MinNetworkSecurityRulePriority, common.MaxNetworkSecurityRulePriority)
+	}
+	if r.Direction != "Inbound" && r.Direction != "Outbound" {
+		return errors.Errorf("networkSecurityRule '%s' has direction '%s', the direction must be either Inbound or Outbound", r.Name, r.Direction)
+	}
+	if r.Access != "Allow" && r.Access != "Deny" {
+		return errors.Errorf("networkSecurityRule '%s' has access '%s', the access must be either Allow or Deny", r.Name, r.Access)
+	}
+	if r.Protocol != "Tcp" && r.Protocol != "Udp" && r.Protocol != "*" {
+		return errors.Errorf("networkSecurityRule '%s' has protocol '%s', the protocol must be one of Tcp, Udp or *", r.Name, r.Protocol)
+	}
+	if err := validateSecurityRuleAddressPrefixes(r.SourceAddressPrefixes); err != nil {
+		return errors.Wrapf(err, "networkSecurityRule '%s' sourceAddressPrefixes", r.Name)
+	}
+	if err := validateSecurityRuleAddressPrefixes(r.DestinationAddressPrefixes); err != nil {
+		return errors.Wrapf(err, "networkSecurityRule '%s' destinationAddressPrefixes", r.Name)
+	}
+	if r.SourcePortRange != "" {
+		if err := validateSecurityRulePortRange(r.SourcePortRange); err != nil {
+			return errors.Wrapf(err, "networkSecurityRule '%s' sourcePortRange", r.Name)
+		}
+	}
+	for _, portRange := range r.DestinationPortRanges {
+		if portRange == "*" && len(r.DestinationPortRanges) > 1 {
+			return errors.Errorf("networkSecurityRule '%s' destinationPortRanges: '*' cannot be combined with other port ranges", r.Name)
+		}
+		if err := validateSecurityRulePortRange(portRange); err != nil {
+			return errors.Wrapf(err, "networkSecurityRule '%s' destinationPortRanges", r.Name)
+		}
+	}
+	return nil
+}
+
+// validateSecurityRuleAddressPrefixes accepts IP addresses and CIDRs, or a single '*' or service tag
+func validateSecurityRuleAddressPrefixes(prefixes []string) error {
+	for _, prefix := range prefixes {
+		if net.ParseIP(prefix) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(prefix); err == nil {
+			continue
+		}
+		if prefix != "*" && !isServiceTag(prefix) {
+			return errors.Errorf("'%s' is neither an IP address, a CIDR, '*' nor a service tag", prefix)
+		}
+		if len(prefixes) > 1 {
+			return errors.Errorf("'%s' cannot be combined with other address prefixes", prefix)
+		}
+	}
+	return nil
+}
+
+// networkSecurityServiceTags are the service tags accepted by Azure in the address prefixes of a security rule,
+// the tags marked as regional may also be scoped to a location, such as Storage.WestUS
+var networkSecurityServiceTags = map[string]bool{
+	"ApiManagement": true, "AppService": true, "AppServiceManagement": false, "AzureActiveDirectory": false,
+	"AzureActiveDirectoryDomainServices": false, "AzureBackup": false, "AzureCloud": true, "AzureConnectors": true,
+	"AzureContainerRegistry": true, "AzureCosmosDB": true, "AzureDatabricks": false, "AzureDataLake": false,
+	"AzureEventGrid": false, "AzureFrontDoor.Backend": false, "AzureFrontDoor.FirstParty": false,
+	"AzureFrontDoor.Frontend": false, "AzureKeyVault": true, "AzureLoadBalancer": false, "AzureMachineLearning": false,
+	"AzureMonitor": true, "AzurePlatformDNS": false, "AzurePlatformIMDS": false, "AzurePlatformLKM": false,
+	"AzureResourceManager": false, "AzureSiteRecovery": false, "AzureTrafficManager": false,
+	"BatchNodeManagement": true, "CognitiveServicesManagement": false, "DataFactory": true, "EventHub": true,
+	"GatewayManager": false, "HDInsight": true, "Internet": false, "MicrosoftContainerRegistry": true,
+	"ServiceBus": true, "ServiceFabric": true, "Sql": true, "SqlManagement": false, "Storage": true,
+	"VirtualNetwork": false,
+}
+
+// isServiceTag returns true if prefix is one of networkSecurityServiceTags, or a regional one scoped to an Azure location
+func isServiceTag(prefix string) bool {
+	for tag, regional := range networkSecurityServiceTags {
+		if strings.EqualFold(prefix, tag) {
+			return true
+		}
+		if regional && len(prefix) > len(tag)+1 && strings.EqualFold(prefix[:len(tag)+1], tag+".") {
+			location := strings.ToLower(prefix[len(tag)+1:])
+			for _, l := range helpers.GetAzureLocations() {
+				if l == location {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// validateSecurityRulePortRange accepts '*', a single port or a range of ports such as 30000-32767
+func validateSecurityRulePortRange(portRange string) error {
+	if portRange == "*" {
+		return nil
+	}
+	bounds := strings.Split(portRange, "-")
+	if len(bounds) > 2 {
+		return errors.Errorf("'%s' is not a valid port range", portRange)
+	}
+	var ports []int
+	for _, b := range bounds {
+		port, err := strconv.Atoi(b)
+		if err != nil || port < common.MinPort || port > common.MaxPort {
+			return errors.Errorf("'%s' is not a valid port range, ports must be between %d and %d", portRange, common.MinPort, common.MaxPort)
+		}
+		ports = append(ports, port)
+	}
+	if len(ports) == 2 && ports[0] > ports[1] {
+		return errors.Errorf("'%s' is not a valid port range, the first port must not be greater than the last one", portRange)
+	}
+	return nil
+}
+
 func validateKeyVaultSecrets(secrets []KeyVaultSecrets, requireCertificateStore bool) error {
 	for _, s := range secrets {
 		if len(s.VaultCertificates) == 0 {
@@ -1871,21 +2500,20 @@ func (cs *ContainerService) validateCustomCloudProfile() error {
 	return nil
 }
 
-// Validate implements validation for ContainerService
+// Validate implements validation for ContainerService. Problems found in the properties
+// are returned together as common.ValidationErrors.
 func (cs *ContainerService) Validate(isUpdate bool) error {
 	if e := cs.validateProperties(); e != nil {
 		return e
 	}
+	// the remaining checks rely on a valid location and custom cloud profile
 	if e := cs.validateLocation(); e != nil {
 		return e
 	}
 	if e := cs.validateCustomCloudProfile(); e != nil {
 		return e
 	}
-	if e := cs.Properties.validate(isUpdate); e != nil {
-		return e
-	}
-	return nil
+	return cs.Properties.validate(isUpdate)
 }
 
 func (cs *ContainerService) validateLocation() error {
@@ -1918,14 +2546,17 @@ func validateDependenciesLocation(dependenciesLocation DependenciesLocation, dep
 // validateAzureStackSupport logs a warning if apimodel contains preview features and returns an error if a property is not supported on Azure Stack clouds
 func (a *Properties) validateAzureStackSupport() error {
 	if a.IsAzureStackCloud() {
-		networkPlugin := a.OrchestratorProfile.KubernetesConfig.NetworkPlugin
+		var networkPlugin string
+		if a.OrchestratorProfile.KubernetesConfig != nil {
+			networkPlugin = a.OrchestratorProfile.KubernetesConfig.NetworkPlugin
+		}
 		if networkPlugin == "azure" || networkPlugin == "" {
 			log.Warnf("NetworkPlugin 'azure' is a private preview feature on Azure Stack clouds")
 		}
 		if networkPlugin != "azure" && networkPlugin != "kubenet" && networkPlugin != "" {
 			return errors.Errorf("kubernetesConfig.networkPlugin '%s' is not supported on Azure Stack clouds", networkPlugin)
 		}
-		if a.MasterProfile.AvailabilityProfile == VirtualMachineScaleSets {
+		if a.MasterProfile != nil && a.MasterProfile.AvailabilityProfile == VirtualMachineScaleSets {
 			return errors.Errorf("masterProfile.availabilityProfile should be set to '%s' on Azure Stack clouds", AvailabilitySet)
 		}
 		for _, agentPool := range a.AgentPoolProfiles {
@@ -4,6 +4,7 @@
 package vlabs
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
 	"strings"
@@ -775,6 +776,8 @@ func Test_Properties_ValidateDistro(t *testing.T) {
 	p := &Properties{}
 	p.OrchestratorProfile = &OrchestratorProfile{}
 	p.OrchestratorProfile.OrchestratorType = Kubernetes
+	p.OrchestratorProfile.OrchestratorVersion = common.RationalizeReleaseAndVersion(Kubernetes, "", "", false, false, false)
+	p.OrchestratorProfile.KubernetesConfig = &KubernetesConfig{ContainerRuntime: Containerd}
 	p.MasterProfile = &MasterProfile{
 		DNSPrefix: "foo",
 	}
@@ -788,7 +791,11 @@ func Test_Properties_ValidateDistro(t *testing.T) {
 				Distro: distro,
 			},
 		}
-		if err := p.validateMasterProfile(false); err != nil {
+		if err := p.validateMasterProfile(false); distro == Flatcar || distro == Ubuntu2204 || distro == Ubuntu2204Gen2 {
+			if err == nil || err.Error() != fmt.Sprintf("The %s distro is only supported for agent pools", distro) {
+				t.Errorf("should error on masterProfile distro=\"%s\"", distro)
+			}
+		} else if err != nil {
 			t.Errorf(
 				"should not error on distro=\"%s\"",
 				distro,
@@ -811,7 +818,11 @@ func Test_Properties_ValidateDistro(t *testing.T) {
 				Distro: distro,
 			},
 		}
-		if err := p.validateMasterProfile(true); err != nil {
+		if err := p.validateMasterProfile(true); distro == Flatcar || distro == Ubuntu2204 || distro == Ubuntu2204Gen2 {
+			if err == nil || err.Error() != fmt.Sprintf("The %s distro is only supported for agent pools", distro) {
+				t.Errorf("should error on masterProfile distro=\"%s\"", distro)
+			}
+		} else if err != nil {
 			t.Errorf(
 				"should not error on distro=\"%s\"",
 				distro,
@@ -1250,11 +1261,13 @@ func TestProperties_ValidateLinuxProfile(t *testing.T) {
 func TestProperties_ValidateWindowsProfile(t *testing.T) {
 	var trueVar = true
 	tests := []struct {
-		name          string
-		k8sVersion    string
-		wp            *WindowsProfile
-		isUpdate      bool
-		expectedError error
+		name             string
+		k8sVersion       string
+		wp               *WindowsProfile
+		containerRuntime string
+		vmSize           string
+		isUpdate         bool
+		expectedError    error
 	}{
 		{
 			name:       "Valid WindowsProfile",
@@ -1375,7 +1388,67 @@ func TestProperties_ValidateWindowsProfile(t *testing.T) {
 					},
 				},
 			},
-			expectedError: nil,
+			containerRuntime: Containerd,
+			vmSize:           "Standard_D4s_v3",
+			expectedError:    nil,
+		},
+		{
+			name: "hyperv default runtime",
+			wp: &WindowsProfile{
+				AdminUsername: "azure",
+				AdminPassword: "replacePassword1234$",
+				WindowsRuntimes: &WindowsRuntimes{
+					Default: "hyperv",
+				},
+			},
+			containerRuntime: Containerd,
+			vmSize:           "Standard_D4s_v3",
+			expectedError:    nil,
+		},
+		{
+			name: "hyperv handlers with docker",
+			wp: &WindowsProfile{
+				AdminUsername: "azure",
+				AdminPassword: "replacePassword1234$",
+				WindowsRuntimes: &WindowsRuntimes{
+					Default: "process",
+					HypervRuntimes: []RuntimeHandlers{
+						{BuildNumber: "17763"},
+					},
+				},
+			},
+			containerRuntime: Docker,
+			vmSize:           "Standard_D4s_v3",
+			expectedError:    errors.New("Hyper-V isolated Windows containers are only supported with the containerd container runtime"),
+		},
+		{
+			name: "hyperv handlers without nested virtualization",
+			wp: &WindowsProfile{
+				AdminUsername: "azure",
+				AdminPassword: "replacePassword1234$",
+				WindowsRuntimes: &WindowsRuntimes{
+					Default: "process",
+					HypervRuntimes: []RuntimeHandlers{
+						{BuildNumber: "17763"},
+					},
+				},
+			},
+			containerRuntime: Containerd,
+			vmSize:           "Standard_D2_v2",
+			expectedError:    errors.New("Hyper-V isolated Windows containers require nested virtualization, which VM size Standard_D2_v2 of Windows agent pool agentpool does not support"),
+		},
+		{
+			name: "hyperv default runtime without nested virtualization",
+			wp: &WindowsProfile{
+				AdminUsername: "azure",
+				AdminPassword: "replacePassword1234$",
+				WindowsRuntimes: &WindowsRuntimes{
+					Default: "hyperv",
+				},
+			},
+			containerRuntime: Containerd,
+			vmSize:           "Standard_B2ms",
+			expectedError:    errors.New("Hyper-V isolated Windows containers require nested virtualization, which VM size Standard_B2ms of Windows agent pool agentpool does not support"),
 		},
 		{
 			name: "some valid handlers some not",
@@ -1420,6 +1493,12 @@ func TestProperties_ValidateWindowsProfile(t *testing.T) {
 			cs := getK8sDefaultContainerService(true)
 			cs.Properties.OrchestratorProfile.OrchestratorVersion = test.k8sVersion
 			cs.Properties.WindowsProfile = test.wp
+			if test.containerRuntime != "" {
+				cs.Properties.OrchestratorProfile.KubernetesConfig = &KubernetesConfig{ContainerRuntime: test.containerRuntime}
+			}
+			if test.vmSize != "" {
+				cs.Properties.AgentPoolProfiles[0].VMSize = test.vmSize
+			}
 			err := cs.Properties.validateWindowsProfile(test.isUpdate)
 			if !helpers.EqualError(err, test.expectedError) {
 				t.Errorf("expected error : '%v', but got '%v'", test.expectedError, err)
@@ -1428,6 +1507,168 @@ func TestProperties_ValidateWindowsProfile(t *testing.T) {
 	}
 }
 
+func TestValidateWindowsGMSAProfile(t *testing.T) {
+	k8sVersion := common.RationalizeReleaseAndVersion(common.Kubernetes, common.KubernetesDefaultRelease, "", false, false, false)
+	tests := []struct {
+		name          string
+		k8sVersion    string
+		gmsa          *WindowsGMSAProfile
+		expectedError error
+	}{
+		{
+			name:          "gMSA not configured",
+			k8sVersion:    k8sVersion,
+			gmsa:          nil,
+			expectedError: nil,
+		},
+		{
+			name:       "Valid domain join with password",
+			k8sVersion: k8sVersion,
+			gmsa: &WindowsGMSAProfile{
+				DomainName:         "contoso.com",
+				DomainJoinUser:     "contoso\\joiner",
+				DomainJoinPassword: "replacePassword1234$",
+			},
+			expectedError: nil,
+		},
+		{
+			name:       "Valid domain join with keyvault secret",
+			k8sVersion: k8sVersion,
+			gmsa: &WindowsGMSAProfile{
+				Mode:           WindowsGMSAModeDomainJoin,
+				DomainName:     "contoso.com",
+				DomainJoinUser: "contoso\\joiner",
+				DomainJoinPasswordKeyvaultSecretRef: &KeyvaultSecretRef{
+					VaultID:    "/subscriptions/SUB-ID/resourceGroups/RG-NAME/providers/Microsoft.KeyVault/vaults/KV-NAME",
+					SecretName: "domain-join",
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name:       "Valid CCG plugin",
+			k8sVersion: k8sVersion,
+			gmsa: &WindowsGMSAProfile{
+				Mode:           WindowsGMSAModeCCGPlugin,
+				DomainName:     "contoso.com",
+				CCGPluginURL:   "https://some/plugin.zip",
+				CCGPluginCLSID: "{CCC2A336-D7F3-4818-A213-272B7924213E}",
+			},
+			expectedError: nil,
+		},
+		{
+			name:       "CCG plugin without URL",
+			k8sVersion: k8sVersion,
+			gmsa: &WindowsGMSAProfile{
+				Mode:           WindowsGMSAModeCCGPlugin,
+				DomainName:     "contoso.com",
+				CCGPluginCLSID: "{CCC2A336-D7F3-4818-A213-272B7924213E}",
+			},
+			expectedError: errors.New("WindowsProfile.GMSA.CCGPluginURL must be the URL of the CCG plugin package with mode CCGPlugin"),
+		},
+		{
+			name:       "CCG plugin with invalid CLSID",
+			k8sVersion: k8sVersion,
+			gmsa: &WindowsGMSAProfile{
+				Mode:           WindowsGMSAModeCCGPlugin,
+				DomainName:     "contoso.com",
+				CCGPluginURL:   "https://some/plugin.zip",
+				CCGPluginCLSID: "CCC2A336-D7F3-4818-A213",
+			},
+			expectedError: errors.New("WindowsProfile.GMSA.CCGPluginCLSID must be the CLSID of the CCG plugin COM class, e.g. {00000000-0000-0000-0000-000000000000}, with mode CCGPlugin"),
+		},
+		{
+			name:       "Unsupported Kubernetes version",
+			k8sVersion: "1.17.9",
+			gmsa: &WindowsGMSAProfile{
+				Mode:       WindowsGMSAModeCCGPlugin,
+				DomainName: "contoso.com",
+			},
+			expectedError: errors.New("gMSA for Windows is only available in Kubernetes versions 1.18.0 or greater"),
+		},
+		{
+			name:       "No domain name",
+			k8sVersion: k8sVersion,
+			gmsa: &WindowsGMSAProfile{
+				Mode: WindowsGMSAModeCCGPlugin,
+			},
+			expectedError: errors.New("WindowsProfile.GMSA.DomainName is required"),
+		},
+		{
+			name:       "Domain join without user",
+			k8sVersion: k8sVersion,
+			gmsa: &WindowsGMSAProfile{
+				DomainName:         "contoso.com",
+				DomainJoinPassword: "replacePassword1234$",
+			},
+			expectedError: errors.New("WindowsProfile.GMSA.DomainJoinUser is required with mode DomainJoin"),
+		},
+		{
+			name:       "Domain join without password",
+			k8sVersion: k8sVersion,
+			gmsa: &WindowsGMSAProfile{
+				DomainName:     "contoso.com",
+				DomainJoinUser: "contoso\\joiner",
+			},
+			expectedError: errors.New("either WindowsProfile.GMSA.DomainJoinPassword or WindowsProfile.GMSA.DomainJoinPasswordKeyvaultSecretRef must be specified with mode DomainJoin"),
+		},
+		{
+			name:       "Domain join with invalid keyvault ID",
+			k8sVersion: k8sVersion,
+			gmsa: &WindowsGMSAProfile{
+				DomainName:     "contoso.com",
+				DomainJoinUser: "contoso\\joiner",
+				DomainJoinPasswordKeyvaultSecretRef: &KeyvaultSecretRef{
+					VaultID:    "not-a-vault",
+					SecretName: "domain-join",
+				},
+			},
+			expectedError: errors.New("gMSA domain join password keyvault secret reference is of incorrect format"),
+		},
+		{
+			name:       "Domain join with CCG plugin URL",
+			k8sVersion: k8sVersion,
+			gmsa: &WindowsGMSAProfile{
+				DomainName:         "contoso.com",
+				DomainJoinUser:     "contoso\\joiner",
+				DomainJoinPassword: "replacePassword1234$",
+				CCGPluginURL:       "https://some/plugin.zip",
+			},
+			expectedError: errors.New("WindowsProfile.GMSA.CCGPluginURL and WindowsProfile.GMSA.CCGPluginCLSID can only be specified with mode CCGPlugin"),
+		},
+		{
+			name:       "CCG plugin with domain join properties",
+			k8sVersion: k8sVersion,
+			gmsa: &WindowsGMSAProfile{
+				Mode:           WindowsGMSAModeCCGPlugin,
+				DomainName:     "contoso.com",
+				DomainJoinUser: "contoso\\joiner",
+			},
+			expectedError: errors.New("the domain join properties of WindowsProfile.GMSA can only be specified with mode DomainJoin"),
+		},
+		{
+			name:       "Unsupported mode",
+			k8sVersion: k8sVersion,
+			gmsa: &WindowsGMSAProfile{
+				Mode:       "Kerberos",
+				DomainName: "contoso.com",
+			},
+			expectedError: errors.New("WindowsProfile.GMSA.Mode \"Kerberos\" is not supported, supported modes are DomainJoin and CCGPlugin"),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateWindowsGMSAProfile(test.gmsa, test.k8sVersion)
+			if !helpers.EqualError(err, test.expectedError) {
+				t.Errorf("expected error : '%v', but got '%v'", test.expectedError, err)
+			}
+		})
+	}
+}
+
 func TestProperties_ValidateInvalidExtensions(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -2374,6 +2615,162 @@ func TestValidateAddons(t *testing.T) {
 	}
 }
 
+func TestValidateCustomAddons(t *testing.T) {
+	checksum := "7d865e959b2466918c9863afca942d0fb89d7c9ac0c99bafc3749504ded97730"
+	tests := []struct {
+		name        string
+		addon       KubernetesAddon
+		expectedErr error
+	}{
+		{
+			name: "custom addon from a path",
+			addon: KubernetesAddon{
+				Name:       "ingress-nginx",
+				Enabled:    to.BoolPtr(true),
+				Containers: []KubernetesContainerSpec{{Name: "controller", Image: "example.com/ingress-nginx/controller:v1.1.0"}},
+				Config:     map[string]string{"replicas": "2"},
+				Pools:      []AddonNodePoolsConfig{{Name: "pool1", Config: map[string]string{"replicas": "1"}}},
+				Source:     &AddonSource{Path: "addons/ingress-nginx", Template: true},
+			},
+		},
+		{
+			name: "custom addon from a url",
+			addon: KubernetesAddon{
+				Name:    "agent",
+				Enabled: to.BoolPtr(true),
+				Source:  &AddonSource{URL: "https://example.com/agent.yaml", SHA256: checksum},
+			},
+		},
+		{
+			name: "custom addon from a helm chart",
+			addon: KubernetesAddon{
+				Name:    "ingress-nginx",
+				Enabled: to.BoolPtr(true),
+				Config:  map[string]string{"controller.replicaCount": "2"},
+				Source:  &AddonSource{HelmChart: &AddonHelmChart{Chart: "ingress-nginx", Repository: "https://kubernetes.github.io/ingress-nginx", Version: "4.0.1"}},
+			},
+		},
+		{
+			name: "custom addon with resolved data",
+			addon: KubernetesAddon{
+				Name:    "agent",
+				Enabled: to.BoolPtr(true),
+				Config:  map[string]string{"foo": "bar"},
+				Data:    base64.StdEncoding.EncodeToString([]byte("kind: Namespace")),
+				Source:  &AddonSource{Path: "agent.yaml"},
+			},
+		},
+		{
+			name: "custom addon with invalid name",
+			addon: KubernetesAddon{
+				Name:   "Ingress_Nginx",
+				Source: &AddonSource{Path: "addons/ingress-nginx"},
+			},
+			expectedErr: errors.New("custom addon name 'Ingress_Nginx' is invalid, it must consist of lower case alphanumeric characters or '-', start and end with an alphanumeric character, and be at most 63 characters"),
+		},
+		{
+			name: "custom addon with the name of a built-in addon",
+			addon: KubernetesAddon{
+				Name:   "metrics-server",
+				Source: &AddonSource{Path: "metrics-server.yaml"},
+			},
+			expectedErr: errors.New("custom addon metrics-server has the name of a built-in addon"),
+		},
+		{
+			name: "templated custom addon from a helm chart",
+			addon: KubernetesAddon{
+				Name:   "agent",
+				Source: &AddonSource{HelmChart: &AddonHelmChart{Chart: "./charts/agent"}, Template: true},
+			},
+			expectedErr: errors.New("custom addon agent source.template is not supported with source.helmChart, Helm renders the chart templates"),
+		},
+		{
+			name: "custom addon without source",
+			addon: KubernetesAddon{
+				Name:   "agent",
+				Source: &AddonSource{},
+			},
+			expectedErr: errors.New("custom addon agent must specify exactly one of source.path, source.url or source.helmChart"),
+		},
+		{
+			name: "custom addon with several sources",
+			addon: KubernetesAddon{
+				Name:   "agent",
+				Source: &AddonSource{Path: "agent.yaml", URL: "https://example.com/agent.yaml", SHA256: checksum},
+			},
+			expectedErr: errors.New("custom addon agent must specify exactly one of source.path, source.url or source.helmChart"),
+		},
+		{
+			name: "custom addon from an http url",
+			addon: KubernetesAddon{
+				Name:   "agent",
+				Source: &AddonSource{URL: "http://example.com/agent.yaml", SHA256: checksum},
+			},
+			expectedErr: errors.New("custom addon agent source.url 'http://example.com/agent.yaml' must be a valid https URL"),
+		},
+		{
+			name: "custom addon from a url without checksum",
+			addon: KubernetesAddon{
+				Name:   "agent",
+				Source: &AddonSource{URL: "https://example.com/agent.yaml"},
+			},
+			expectedErr: errors.New("custom addon agent source.url requires source.sha256 to be the hex-encoded SHA-256 checksum of the manifest"),
+		},
+		{
+			name: "custom addon from a path with checksum",
+			addon: KubernetesAddon{
+				Name:   "agent",
+				Source: &AddonSource{Path: "agent.yaml", SHA256: checksum},
+			},
+			expectedErr: errors.New("custom addon agent source.sha256 may only be used with source.url"),
+		},
+		{
+			name: "custom addon from a helm chart without chart",
+			addon: KubernetesAddon{
+				Name:   "agent",
+				Source: &AddonSource{HelmChart: &AddonHelmChart{Repository: "https://example.com/charts"}},
+			},
+			expectedErr: errors.New("custom addon agent source.helmChart.chart must be specified"),
+		},
+		{
+			name: "custom addon from a helm chart with containers",
+			addon: KubernetesAddon{
+				Name:       "agent",
+				Containers: []KubernetesContainerSpec{{Name: "agent", Image: "example.com/agent:v1"}},
+				Source:     &AddonSource{HelmChart: &AddonHelmChart{Chart: "./charts/agent"}},
+			},
+			expectedErr: errors.New("custom addon agent containers are not supported with source.helmChart, use config to set chart values instead"),
+		},
+		{
+			name: "custom addon with data not base64 encoded",
+			addon: KubernetesAddon{
+				Name:   "agent",
+				Data:   "kind: Namespace",
+				Source: &AddonSource{Path: "agent.yaml"},
+			},
+			expectedErr: errors.New("Addon agent's data should be base64 encoded"),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			p := &Properties{
+				OrchestratorProfile: &OrchestratorProfile{
+					KubernetesConfig: &KubernetesConfig{
+						Addons: []KubernetesAddon{test.addon},
+					},
+				},
+			}
+			gotErr := p.validateAddons(false)
+			if !helpers.EqualError(gotErr, test.expectedErr) {
+				t.Errorf("expected error: %v, got: %v", test.expectedErr, gotErr)
+			}
+		})
+	}
+}
+
 // TODO move these to TestValidateAddons above
 func Test_Properties_ValidateAddons(t *testing.T) {
 	p := &Properties{}
@@ -3352,7 +3749,7 @@ func TestProperties_ValidateZones(t *testing.T) {
 				},
 			},
 			expectedErr:    true,
-			expectedErrStr: "VirtualMachineScaleSets for master profile must be used together with virtualMachineScaleSets for agent profiles. Set \"availabilityProfile\" to \"VirtualMachineScaleSets\" for agent profiles",
+			expectedErrStr: "2 validation errors: properties.masterProfile: VirtualMachineScaleSets for master profile must be used together with virtualMachineScaleSets for agent profiles. Set \"availabilityProfile\" to \"VirtualMachineScaleSets\" for agent profiles; properties.agentPoolProfiles.availabilityZones: Availability Zones are not supported with an AvailabilitySet. Please either remove availabilityProfile or set availabilityProfile to VirtualMachineScaleSets",
 		},
 		{
 			name:                "Master profile with zones and Agent profile without zones",
@@ -3789,7 +4186,7 @@ func TestProperties_ValidateSinglePlacementGroup(t *testing.T) {
 					SinglePlacementGroup: to.BoolPtr(false),
 				},
 			},
-			expectedMsg: `VirtualMachineScaleSets for master profile must be used together with virtualMachineScaleSets for agent profiles. Set "availabilityProfile" to "VirtualMachineScaleSets" for agent profiles`,
+			expectedMsg: `2 validation errors: properties.masterProfile: VirtualMachineScaleSets for master profile must be used together with virtualMachineScaleSets for agent profiles. Set "availabilityProfile" to "VirtualMachineScaleSets" for agent profiles; properties.agentPoolProfiles[0]: singlePlacementGroup is only supported with VirtualMachineScaleSets`,
 		},
 		{
 			name: "VMSS with SinglePlacementGroup false and StorageAccount storage",
@@ -4077,7 +4474,7 @@ func TestProperties_ValidateVNET(t *testing.T) {
 					VnetSubnetID:        validVNetSubnetID,
 				},
 			},
-			expectedMsg: "when masterProfile's availabilityProfile is VirtualMachineScaleSets and a vnetSubnetID is specified, the firstConsecutiveStaticIP should be empty and will be determined by an offset from the first IP in the vnetCidr",
+			expectedMsg: "2 validation errors: properties.masterProfile: when masterProfile's availabilityProfile is VirtualMachineScaleSets and a vnetSubnetID is specified, the firstConsecutiveStaticIP should be empty and will be determined by an offset from the first IP in the vnetCidr; properties.masterProfile.vnetSubnetID: when master profile is using VirtualMachineScaleSets and is custom vnet, set \"vnetsubnetid\" and \"agentVnetSubnetID\" for master profile",
 		},
 		{
 			name: "Invalid vnetcidr",
@@ -4297,6 +4694,35 @@ func TestValidateProperties_CustomNodeLabels(t *testing.T) {
 	})
 }
 
+func TestValidateAgentPoolProfiles_ReportsEveryPool(t *testing.T) {
+	t.Parallel()
+	cs := getK8sDefaultContainerService(false)
+	pool1, pool2 := *cs.Properties.AgentPoolProfiles[0], *cs.Properties.AgentPoolProfiles[0]
+	cs.Properties.AgentPoolProfiles = []*AgentPoolProfile{&pool1, &pool2}
+	cs.Properties.AgentPoolProfiles[0].Name = "Pool1"
+	cs.Properties.AgentPoolProfiles[1].Name = "pool2"
+	cs.Properties.AgentPoolProfiles[1].CustomNodeLabels = map[string]string{"fookey": "b$$a$$r"}
+	expectedMsg := "2 validation errors: properties.agentPoolProfiles[0]: pool name 'Pool1' is invalid. A pool name must start with a lowercase letter, have max length of 12, and only have characters a-z0-9; " +
+		"properties.agentPoolProfiles[1]: Label value 'b$$a$$r' is invalid. Valid label values must be 63 characters or less and must be empty or begin and end with an alphanumeric character ([a-z0-9A-Z]) with dashes (-), underscores (_), dots (.), and alphanumerics between"
+	if err := cs.Properties.validateAgentPoolProfiles(true); err == nil || err.Error() != expectedMsg {
+		t.Errorf("expected error with message : %s, but got %v", expectedMsg, err)
+	}
+
+	cs.Properties.AgentPoolProfiles[0].Name = "pool2"
+	cs.Properties.AgentPoolProfiles[1].CustomNodeLabels = nil
+	err := cs.Properties.validateAgentPoolProfiles(true)
+	verrs, ok := err.(common.ValidationErrors)
+	if !ok || len(verrs) != 1 {
+		t.Fatalf("expected a single validation error, got %v", err)
+	}
+	if verrs[0].Field != "properties.agentPoolProfiles[1].name" || verrs[0].Severity != common.ValidationSeverityError {
+		t.Errorf("unexpected field %s or severity %s", verrs[0].Field, verrs[0].Severity)
+	}
+	if verrs[0].MessageKey != "profile name '%s' already exists, profile names must be unique across pools" || len(verrs[0].Args) != 1 {
+		t.Errorf("expected the message key of the duplicate pool name, got %q with %v", verrs[0].MessageKey, verrs[0].Args)
+	}
+}
+
 func TestAgentPoolProfile_ValidateAvailabilityProfile(t *testing.T) {
 	t.Run("Should fail for invalid availability profile", func(t *testing.T) {
 		t.Parallel()
@@ -4344,6 +4770,283 @@ func TestAgentPoolProfile_ValidateAvailabilityProfile(t *testing.T) {
 	})
 }
 
+func TestAgentPoolProfile_ValidateWindowsImage(t *testing.T) {
+	cases := []struct {
+		name        string
+		pool        AgentPoolProfile
+		expectedErr string
+	}{
+		{
+			name: "Windows pool overriding the image",
+			pool: AgentPoolProfile{Name: "win2022", OSType: Windows, WindowsSku: "2022-datacenter-core-smalldisk", WindowsImageVersion: "latest", WindowsDockerVersion: "20.10.9"},
+		},
+		{
+			name: "Windows pool with an image reference",
+			pool: AgentPoolProfile{Name: "canary", OSType: Windows, ImageRef: &ImageReference{Name: "image", ResourceGroup: "rg"}},
+		},
+		{
+			name:        "Windows pool with an image reference and a marketplace image",
+			pool:        AgentPoolProfile{Name: "canary", OSType: Windows, WindowsSku: "2022-datacenter-core-smalldisk", ImageRef: &ImageReference{Name: "image", ResourceGroup: "rg"}},
+			expectedErr: "Windows agent pool canary can specify either imageReference or windowsPublisher, windowsOffer, windowsSku and windowsImageVersion, but not both",
+		},
+		{
+			name:        "Linux pool with a Windows image",
+			pool:        AgentPoolProfile{Name: "linux", OSType: Linux, WindowsSku: "2022-datacenter-core-smalldisk"},
+			expectedErr: "agent pool linux specifies Windows image properties, which are only supported with osType Windows",
+		},
+		{
+			name:        "Linux pool with a Windows docker version",
+			pool:        AgentPoolProfile{Name: "linux", WindowsDockerVersion: "20.10.9"},
+			expectedErr: "agent pool linux specifies Windows image properties, which are only supported with osType Windows",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			err := c.pool.validateWindowsImage()
+			if c.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, but got %s", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != c.expectedErr {
+				t.Errorf("expected error with message : %s, but got %v", c.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestAgentPoolProfile_ValidateFlatcar(t *testing.T) {
+	cases := []struct {
+		name        string
+		pool        AgentPoolProfile
+		expectedErr string
+	}{
+		{
+			name: "Flatcar pool",
+			pool: AgentPoolProfile{Name: "flatcar", Distro: Flatcar, VMSize: "Standard_D2s_v3"},
+		},
+		{
+			name: "Ubuntu pool with an N-series VM size",
+			pool: AgentPoolProfile{Name: "gpu", Distro: Ubuntu1804, VMSize: "Standard_NC6"},
+		},
+		{
+			name:        "Flatcar pool with an N-series VM size",
+			pool:        AgentPoolProfile{Name: "gpu", Distro: Flatcar, VMSize: "Standard_NC6"},
+			expectedErr: "Flatcar agent pool gpu uses N-series VM size Standard_NC6, the NVIDIA drivers are only supported with Ubuntu-based distros",
+		},
+		{
+			name:        "Flatcar pool with a DC-series VM size",
+			pool:        AgentPoolProfile{Name: "sgx", Distro: Flatcar, VMSize: "Standard_DC2s"},
+			expectedErr: "Flatcar agent pool sgx uses DC-series VM size Standard_DC2s, the SGX drivers are only supported with Ubuntu-based distros",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			err := c.pool.validateFlatcar()
+			if c.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, but got %s", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != c.expectedErr {
+				t.Errorf("expected error with message : %s, but got %v", c.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestAgentPoolProfile_ValidateImageFlavor(t *testing.T) {
+	cases := []struct {
+		name        string
+		pool        AgentPoolProfile
+		expectedErr string
+	}{
+		{
+			name: "default pool",
+			pool: AgentPoolProfile{Name: "pool", VMSize: "Standard_D2s_v3"},
+		},
+		{
+			name: "Gen2 pool",
+			pool: AgentPoolProfile{Name: "pool", Distro: Ubuntu2204, HyperVGeneration: HyperVGenerationV2, VMSize: "Standard_D2s_v3"},
+		},
+		{
+			name: "Gen2 Flatcar pool",
+			pool: AgentPoolProfile{Name: "pool", Distro: Flatcar, HyperVGeneration: HyperVGenerationV2, VMSize: "Standard_D2s_v3"},
+		},
+		{
+			name: "Arm64 pool",
+			pool: AgentPoolProfile{Name: "pool", Distro: Ubuntu2004, Architecture: ArchitectureArm64, VMSize: "Standard_D4ps_v5"},
+		},
+		{
+			name: "Arm64 pool with a VM size unknown to this version",
+			pool: AgentPoolProfile{Name: "pool", Distro: Ubuntu2204, Architecture: ArchitectureArm64, VMSize: "Standard_D4ps_v9"},
+		},
+		{
+			name: "Gen2 pool with a custom image",
+			pool: AgentPoolProfile{Name: "pool", HyperVGeneration: HyperVGenerationV2, VMSize: "Standard_D2s_v3", ImageRef: &ImageReference{Name: "image", ResourceGroup: "rg"}},
+		},
+		{
+			name:        "invalid hyperVGeneration",
+			pool:        AgentPoolProfile{Name: "pool", HyperVGeneration: "V3"},
+			expectedErr: "Invalid hyperVGeneration value \"V3\" for agentPoolProfile \"pool\", please use one of the following values: [V1 V2]",
+		},
+		{
+			name:        "invalid architecture",
+			pool:        AgentPoolProfile{Name: "pool", Architecture: "x86"},
+			expectedErr: "Invalid architecture value \"x86\" for agentPoolProfile \"pool\", please use one of the following values: [amd64 arm64]",
+		},
+		{
+			name:        "Windows pool",
+			pool:        AgentPoolProfile{Name: "pool", OSType: Windows, HyperVGeneration: HyperVGenerationV2},
+			expectedErr: "agent pool pool specifies hyperVGeneration or architecture, which are only supported with osType Linux",
+		},
+		{
+			name:        "Gen2 distro with generation 1",
+			pool:        AgentPoolProfile{Name: "pool", Distro: Ubuntu2004Gen2, HyperVGeneration: HyperVGenerationV1},
+			expectedErr: "agent pool pool uses the Gen2 distro ubuntu-20.04-gen2, which cannot be combined with hyperVGeneration V1",
+		},
+		{
+			name:        "Gen2 VHD distro",
+			pool:        AgentPoolProfile{Name: "pool", Distro: AKSUbuntu1804, HyperVGeneration: HyperVGenerationV2},
+			expectedErr: "agent pool pool uses hyperVGeneration V2, which is not supported with the aks-ubuntu-18.04 distro",
+		},
+		{
+			name:        "Arm64 Ubuntu 18.04 pool",
+			pool:        AgentPoolProfile{Name: "pool", Distro: Ubuntu1804, Architecture: ArchitectureArm64},
+			expectedErr: "agent pool pool uses architecture arm64, which is only supported with the ubuntu-20.04 and ubuntu-22.04 distros",
+		},
+		{
+			name:        "Arm64 generation 1 pool",
+			pool:        AgentPoolProfile{Name: "pool", Distro: Ubuntu2204, Architecture: ArchitectureArm64, HyperVGeneration: HyperVGenerationV1},
+			expectedErr: "agent pool pool uses architecture arm64, which requires hyperVGeneration V2",
+		},
+		{
+			name:        "Gen2 pool with a generation 1 VM size",
+			pool:        AgentPoolProfile{Name: "pool", Distro: Ubuntu2004Gen2, VMSize: "Standard_A2_v2"},
+			expectedErr: "VM size Standard_A2_v2 of agent pool pool does not support hyperVGeneration V2",
+		},
+		{
+			name:        "generation 1 pool with a Gen2 VM size",
+			pool:        AgentPoolProfile{Name: "pool", Distro: Ubuntu2004, VMSize: "Standard_M208ms_v2"},
+			expectedErr: "VM size Standard_M208ms_v2 of agent pool pool does not support hyperVGeneration V1",
+		},
+		{
+			name:        "amd64 pool with an Arm64 VM size",
+			pool:        AgentPoolProfile{Name: "pool", Distro: Ubuntu2004, HyperVGeneration: HyperVGenerationV2, VMSize: "Standard_D4ps_v5"},
+			expectedErr: "VM size Standard_D4ps_v5 of agent pool pool has an Arm64 CPU, which does not match the amd64 architecture of the pool",
+		},
+		{
+			name:        "Arm64 pool with an x64 VM size",
+			pool:        AgentPoolProfile{Name: "pool", Distro: Ubuntu2004, Architecture: ArchitectureArm64, VMSize: "Standard_D4s_v5"},
+			expectedErr: "VM size Standard_D4s_v5 of agent pool pool has an x64 CPU, which does not match the arm64 architecture of the pool",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			err := c.pool.validateImageFlavor()
+			if c.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, but got %s", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != c.expectedErr {
+				t.Errorf("expected error with message : %s, but got %v", c.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestProperties_ValidateUbuntu2204(t *testing.T) {
+	cases := []struct {
+		name             string
+		version          string
+		containerRuntime string
+		vmSize           string
+		expectedErr      string
+	}{
+		{
+			name:             "supported configuration",
+			version:          "1.24.9",
+			containerRuntime: Containerd,
+			vmSize:           "Standard_D2s_v3",
+		},
+		{
+			name:             "Kubernetes version without cgroup v2 support",
+			version:          "1.21.14",
+			containerRuntime: Containerd,
+			vmSize:           "Standard_D2s_v3",
+			expectedErr:      "agent pool pool uses the ubuntu-22.04 distro, which requires Kubernetes 1.22.0 or greater",
+		},
+		{
+			name:             "docker runtime",
+			version:          "1.23.17",
+			containerRuntime: Docker,
+			vmSize:           "Standard_D2s_v3",
+			expectedErr:      "agent pool pool uses the ubuntu-22.04 distro, which is only supported with the containerd container runtime",
+		},
+		{
+			name:             "N-series VM size",
+			version:          "1.24.9",
+			containerRuntime: Containerd,
+			vmSize:           "Standard_NC6s_v3",
+			expectedErr:      "agent pool pool uses the ubuntu-22.04 distro, which does not support the GPU and SGX drivers of VM size Standard_NC6s_v3 yet",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			p := &Properties{
+				OrchestratorProfile: &OrchestratorProfile{
+					OrchestratorVersion: c.version,
+					KubernetesConfig:    &KubernetesConfig{ContainerRuntime: c.containerRuntime},
+				},
+			}
+			err := p.validateUbuntu2204(&AgentPoolProfile{Name: "pool", Distro: Ubuntu2204, VMSize: c.vmSize})
+			if c.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, but got %s", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != c.expectedErr {
+				t.Errorf("expected error with message : %s, but got %v", c.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateLinuxProfile_FlatcarCustomSearchDomain(t *testing.T) {
+	cs := getK8sDefaultContainerService(false)
+	cs.Properties.LinuxProfile.CustomSearchDomain = &CustomSearchDomain{
+		Name:          "example.com",
+		RealmUser:     "user",
+		RealmPassword: "password",
+	}
+	if err := cs.Properties.validateLinuxProfile(); err != nil {
+		t.Errorf("expected no error, but got %s", err)
+	}
+
+	cs.Properties.AgentPoolProfiles[0].Distro = Flatcar
+	expectedMsg := "linuxProfile.customSearchDomain is not supported with Flatcar node pools"
+	if err := cs.Properties.validateLinuxProfile(); err == nil || err.Error() != expectedMsg {
+		t.Errorf("expected error with message : %s, but got %v", expectedMsg, err)
+	}
+}
+
 func TestAgentPoolProfile_ValidateVirtualMachineScaleSet(t *testing.T) {
 	t.Run("Should fail for invalid VMSS + Overprovisioning config", func(t *testing.T) {
 		t.Parallel()
@@ -4857,7 +5560,7 @@ func TestValidateLocation(t *testing.T) {
 					},
 				},
 			},
-			expectedErr: errors.New("AcceleratedNetworkingEnabled or AcceleratedNetworkingEnabledWindows shouldn't be set to true as feature is not yet supported on Azure Stack"),
+			expectedErr: errors.New("2 validation errors: properties.agentPoolProfiles[0]: AcceleratedNetworkingEnabled or AcceleratedNetworkingEnabledWindows shouldn't be set to true as feature is not yet supported on Azure Stack; properties: agentPoolProfiles[testpool].availabilityProfile should be set to 'AvailabilitySet' on Azure Stack clouds"),
 		},
 		{
 			name:          "AzureStack AcceleratedNetworking is true",
@@ -4883,7 +5586,7 @@ func TestValidateLocation(t *testing.T) {
 					},
 				},
 			},
-			expectedErr: errors.New("AcceleratedNetworkingEnabled or AcceleratedNetworkingEnabledWindows shouldn't be set to true as feature is not yet supported on Azure Stack"),
+			expectedErr: errors.New("2 validation errors: properties.agentPoolProfiles[0]: AcceleratedNetworkingEnabled or AcceleratedNetworkingEnabledWindows shouldn't be set to true as feature is not yet supported on Azure Stack; properties: agentPoolProfiles[testpool].availabilityProfile should be set to 'AvailabilitySet' on Azure Stack clouds"),
 		},
 	}
 
@@ -5667,3 +6370,375 @@ func TestValidateConnectedClusterProfile(t *testing.T) {
 		}
 	})
 }
+
+func TestProperties_ValidateNetworkSecurityRules(t *testing.T) {
+	nodePorts := NetworkSecurityRule{
+		Name:                  "allow_nodeports",
+		Priority:              200,
+		Direction:             "Inbound",
+		Access:                "Allow",
+		Protocol:              "Tcp",
+		SourceAddressPrefixes: []string{"10.1.0.0/16", "10.2.0.4"},
+		DestinationPortRanges: []string{"30000-32767"},
+	}
+	withRule := func(f func(r *NetworkSecurityRule)) []NetworkSecurityRule {
+		r := nodePorts
+		f(&r)
+		return []NetworkSecurityRule{r}
+	}
+
+	tests := []struct {
+		name         string
+		clusterRules []NetworkSecurityRule
+		poolRules    []NetworkSecurityRule
+		windows      bool
+		expectedErr  string
+	}{
+		{
+			name: "valid cluster and pool rules",
+			clusterRules: withRule(func(r *NetworkSecurityRule) {
+				r.Name = "deny_smtp"
+				r.Direction = "Outbound"
+				r.Access = "Deny"
+				r.DestinationPortRanges = []string{"25"}
+			}),
+			poolRules: []NetworkSecurityRule{nodePorts},
+		},
+		{
+			name: "custom rule replaces a default rule",
+			clusterRules: withRule(func(r *NetworkSecurityRule) {
+				r.Name = "allow_ssh"
+				r.Priority = 101
+				r.SourceAddressPrefixes = []string{"192.168.0.4/32"}
+			}),
+		},
+		{
+			name: "service tag and wildcard port",
+			clusterRules: withRule(func(r *NetworkSecurityRule) {
+				r.SourceAddressPrefixes = []string{"AzureLoadBalancer"}
+				r.DestinationPortRanges = []string{"*"}
+			}),
+		},
+		{
+			name:         "priority out of range",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.Priority = 99 }),
+			expectedErr:  "networkSecurityRule 'allow_nodeports' has priority 99, the priority must be between 100 and 4096",
+		},
+		{
+			name:         "invalid direction",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.Direction = "In" }),
+			expectedErr:  "networkSecurityRule 'allow_nodeports' has direction 'In', the direction must be either Inbound or Outbound",
+		},
+		{
+			name:         "invalid protocol",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.Protocol = "Icmp" }),
+			expectedErr:  "networkSecurityRule 'allow_nodeports' has protocol 'Icmp', the protocol must be one of Tcp, Udp or *",
+		},
+		{
+			name:         "invalid name",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.Name = "-ssh" }),
+			expectedErr:  "networkSecurityRule name '-ssh' is invalid, it must have at most 80 characters, begin with a letter or number, end with a letter, number or underscore, and only contain letters, numbers, underscores, periods, or hyphens",
+		},
+		{
+			name:         "service tag combined with a cidr",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.SourceAddressPrefixes = []string{"10.1.0.0/16", "Internet"} }),
+			expectedErr:  "networkSecurityRule 'allow_nodeports' sourceAddressPrefixes: 'Internet' cannot be combined with other address prefixes",
+		},
+		{
+			name:         "invalid address prefix",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.DestinationAddressPrefixes = []string{"10.1.0.0/33"} }),
+			expectedErr:  "networkSecurityRule 'allow_nodeports' destinationAddressPrefixes: '10.1.0.0/33' is neither an IP address, a CIDR, '*' nor a service tag",
+		},
+		{
+			name:         "reversed port range",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.DestinationPortRanges = []string{"32767-30000"} }),
+			expectedErr:  "networkSecurityRule 'allow_nodeports' destinationPortRanges: '32767-30000' is not a valid port range, the first port must not be greater than the last one",
+		},
+		{
+			name:         "invalid source port range",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.SourcePortRange = "0-70000" }),
+			expectedErr:  "networkSecurityRule 'allow_nodeports' sourcePortRange: '0-70000' is not a valid port range, ports must be between 1 and 65535",
+		},
+		{
+			name:         "duplicate names",
+			clusterRules: []NetworkSecurityRule{nodePorts, nodePorts},
+			expectedErr:  "networkSecurityRule name 'allow_nodeports' is used more than once",
+		},
+		{
+			name:         "priority taken by a default rule",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.Priority = 101 }),
+			expectedErr:  "networkSecurityRule 'allow_nodeports' has the same priority 101 and direction Inbound as rule 'allow_ssh'",
+		},
+		{
+			name:         "priority taken by the default rdp rule on windows clusters",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.Priority = 102 }),
+			windows:      true,
+			expectedErr:  "networkSecurityRule 'allow_nodeports' has the same priority 102 and direction Inbound as rule 'allow_rdp'",
+		},
+		{
+			name:         "same priority in the other direction",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.Priority = 101; r.Direction = "Outbound" }),
+		},
+		{
+			name:         "pool rule priority taken by a cluster rule",
+			clusterRules: []NetworkSecurityRule{nodePorts},
+			poolRules:    withRule(func(r *NetworkSecurityRule) { r.Name = "allow_http" }),
+			expectedErr:  "agent pool 'pool1': networkSecurityRule 'allow_http' has the same priority 200 and direction Inbound as rule 'allow_nodeports'",
+		},
+		{
+			name:         "pool rule replaces a cluster rule",
+			clusterRules: []NetworkSecurityRule{nodePorts},
+			poolRules:    withRule(func(r *NetworkSecurityRule) { r.Access = "Deny" }),
+		},
+		{
+			name:         "regional service tag",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.SourceAddressPrefixes = []string{"Storage.WestUS"} }),
+		},
+		{
+			name:         "unknown service tag",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.SourceAddressPrefixes = []string{"Intranet"} }),
+			expectedErr:  "networkSecurityRule 'allow_nodeports' sourceAddressPrefixes: 'Intranet' is neither an IP address, a CIDR, '*' nor a service tag",
+		},
+		{
+			name:         "service tag that is not regional",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.SourceAddressPrefixes = []string{"Internet.WestUS"} }),
+			expectedErr:  "networkSecurityRule 'allow_nodeports' sourceAddressPrefixes: 'Internet.WestUS' is neither an IP address, a CIDR, '*' nor a service tag",
+		},
+		{
+			name:         "rule shadowed by a default rule",
+			clusterRules: withRule(func(r *NetworkSecurityRule) { r.DestinationPortRanges = []string{"22"} }),
+			expectedErr:  "networkSecurityRule 'allow_nodeports' is never applied, rule 'allow_ssh' with priority 101 matches all of its traffic first",
+		},
+		{
+			name: "pool rule shadowed by a cluster rule",
+			clusterRules: withRule(func(r *NetworkSecurityRule) {
+				r.Name = "deny_private"
+				r.Priority = 150
+				r.Access = "Deny"
+				r.Protocol = "*"
+				r.SourceAddressPrefixes = []string{"10.0.0.0/8"}
+				r.DestinationPortRanges = nil
+			}),
+			poolRules:   withRule(func(r *NetworkSecurityRule) { r.SourceAddressPrefixes = []string{"10.1.0.0/16"} }),
+			expectedErr: "agent pool 'pool1': networkSecurityRule 'allow_nodeports' is never applied, rule 'deny_private' with priority 150 matches all of its traffic first",
+		},
+		{
+			name: "partially overlapping rules",
+			clusterRules: []NetworkSecurityRule{nodePorts, {
+				Name:                  "deny_nodeports",
+				Priority:              300,
+				Direction:             "Inbound",
+				Access:                "Deny",
+				Protocol:              "*",
+				DestinationPortRanges: []string{"30000-32767"},
+			}},
+		},
+		{
+			name:      "pool rule that a master rule would shadow",
+			poolRules: withRule(func(r *NetworkSecurityRule) { r.DestinationPortRanges = []string{"22"} }),
+		},
+		{
+			name:         "pool rule overrides a cluster rule it shadows",
+			clusterRules: []NetworkSecurityRule{nodePorts},
+			poolRules: withRule(func(r *NetworkSecurityRule) {
+				r.Name = "deny_all"
+				r.Priority = 150
+				r.Access = "Deny"
+				r.Protocol = "*"
+				r.SourceAddressPrefixes = nil
+				r.DestinationPortRanges = nil
+			}),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			p := &Properties{
+				AgentPoolProfiles: []*AgentPoolProfile{
+					{
+						Name:                 "pool1",
+						NetworkSecurityRules: test.poolRules,
+					},
+				},
+				NetworkSecurityRules: test.clusterRules,
+			}
+			if test.windows {
+				p.AgentPoolProfiles[0].OSType = Windows
+			}
+			err := p.validateNetworkSecurityRules()
+			if test.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %s", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("expected error %q, got %v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestProperties_ValidateOutboundType(t *testing.T) {
+	routeTableID := "/subscriptions/SUB_ID/resourceGroups/RG_NAME/providers/Microsoft.Network/routeTables/firewall-rt"
+	natGatewayID := "/subscriptions/SUB_ID/resourceGroups/RG_NAME/providers/Microsoft.Network/natGateways/egress"
+	tests := []struct {
+		name            string
+		outboundType    string
+		routeTableID    string
+		natGateway      *NATGatewayProfile
+		customVNET      bool
+		loadBalancerSku string
+		expectedErr     string
+	}{
+		{
+			name: "default outbound type",
+		},
+		{
+			name:         "loadBalancer outbound type",
+			outboundType: OutboundTypeLoadBalancer,
+		},
+		{
+			name:            "userDefinedRouting outbound type",
+			outboundType:    OutboundTypeUserDefinedRouting,
+			routeTableID:    routeTableID,
+			loadBalancerSku: StandardLoadBalancerSku,
+		},
+		{
+			name:         "invalid outbound type",
+			outboundType: "natGateway",
+			expectedErr:  "Invalid outboundType 'natGateway', only loadBalancer, userDefinedRouting, managedNATGateway and userAssignedNATGateway are supported",
+		},
+		{
+			name:         "route table without userDefinedRouting",
+			routeTableID: routeTableID,
+			expectedErr:  "routeTableID is only supported with outboundType userDefinedRouting",
+		},
+		{
+			name:            "userDefinedRouting without route table",
+			outboundType:    OutboundTypeUserDefinedRouting,
+			loadBalancerSku: StandardLoadBalancerSku,
+			expectedErr:     "outboundType userDefinedRouting requires a routeTableID",
+		},
+		{
+			name:            "userDefinedRouting with an invalid route table",
+			outboundType:    OutboundTypeUserDefinedRouting,
+			routeTableID:    "/subscriptions/SUB_ID/resourceGroups/RG_NAME/providers/Microsoft.Network/virtualNetworks/vnet",
+			loadBalancerSku: StandardLoadBalancerSku,
+			expectedErr:     "routeTableID '/subscriptions/SUB_ID/resourceGroups/RG_NAME/providers/Microsoft.Network/virtualNetworks/vnet' is not a valid route table resource ID",
+		},
+		{
+			name:            "userDefinedRouting with a route table ID with extra segments",
+			outboundType:    OutboundTypeUserDefinedRouting,
+			routeTableID:    "/subscriptions/SUB_ID/resourceGroups/RG_NAME/extra/providers/Microsoft.Network/routeTables/firewall-rt",
+			loadBalancerSku: StandardLoadBalancerSku,
+			expectedErr:     "routeTableID '/subscriptions/SUB_ID/resourceGroups/RG_NAME/extra/providers/Microsoft.Network/routeTables/firewall-rt' is not a valid route table resource ID",
+		},
+		{
+			name:            "userDefinedRouting with a basic load balancer",
+			outboundType:    OutboundTypeUserDefinedRouting,
+			routeTableID:    routeTableID,
+			loadBalancerSku: BasicLoadBalancerSku,
+			expectedErr:     "outboundType userDefinedRouting requires loadBalancerSku Standard",
+		},
+		{
+			name:            "managedNATGateway outbound type",
+			outboundType:    OutboundTypeManagedNATGateway,
+			natGateway:      &NATGatewayProfile{IdleTimeoutInMinutes: 10, PublicIPPrefixCount: 2},
+			loadBalancerSku: StandardLoadBalancerSku,
+		},
+		{
+			name:            "managedNATGateway with a custom VNET",
+			outboundType:    OutboundTypeManagedNATGateway,
+			customVNET:      true,
+			loadBalancerSku: StandardLoadBalancerSku,
+			expectedErr:     "outboundType managedNATGateway can only associate the NAT gateway with subnets created by aks-engine, associate a NAT gateway with your subnets and use outboundType userAssignedNATGateway instead",
+		},
+		{
+			name:            "managedNATGateway idle timeout out of range",
+			outboundType:    OutboundTypeManagedNATGateway,
+			natGateway:      &NATGatewayProfile{IdleTimeoutInMinutes: 121},
+			loadBalancerSku: StandardLoadBalancerSku,
+			expectedErr:     "natGatewayProfile.idleTimeoutInMinutes was set to 121, it must be between 4 and 120",
+		},
+		{
+			name:            "managedNATGateway too many public IP prefixes",
+			outboundType:    OutboundTypeManagedNATGateway,
+			natGateway:      &NATGatewayProfile{PublicIPPrefixCount: 9},
+			loadBalancerSku: StandardLoadBalancerSku,
+			expectedErr:     "natGatewayProfile.publicIPPrefixCount was set to 9, it must be between 1 and 8",
+		},
+		{
+			name:            "managedNATGateway with a NAT gateway ID",
+			outboundType:    OutboundTypeManagedNATGateway,
+			natGateway:      &NATGatewayProfile{ID: natGatewayID},
+			loadBalancerSku: StandardLoadBalancerSku,
+			expectedErr:     "natGatewayProfile.id is only supported with outboundType userAssignedNATGateway",
+		},
+		{
+			name:            "userAssignedNATGateway outbound type",
+			outboundType:    OutboundTypeUserAssignedNATGateway,
+			natGateway:      &NATGatewayProfile{ID: natGatewayID},
+			customVNET:      true,
+			loadBalancerSku: StandardLoadBalancerSku,
+		},
+		{
+			name:            "userAssignedNATGateway without NAT gateway ID",
+			outboundType:    OutboundTypeUserAssignedNATGateway,
+			loadBalancerSku: StandardLoadBalancerSku,
+			expectedErr:     "outboundType userAssignedNATGateway requires a natGatewayProfile.id",
+		},
+		{
+			name:            "userAssignedNATGateway with managed settings",
+			outboundType:    OutboundTypeUserAssignedNATGateway,
+			natGateway:      &NATGatewayProfile{ID: natGatewayID, PublicIPPrefixCount: 2},
+			loadBalancerSku: StandardLoadBalancerSku,
+			expectedErr:     "natGatewayProfile.idleTimeoutInMinutes and natGatewayProfile.publicIPPrefixCount are only supported with outboundType managedNATGateway",
+		},
+		{
+			name:            "userAssignedNATGateway with a basic load balancer",
+			outboundType:    OutboundTypeUserAssignedNATGateway,
+			natGateway:      &NATGatewayProfile{ID: natGatewayID},
+			loadBalancerSku: BasicLoadBalancerSku,
+			expectedErr:     "outboundType userAssignedNATGateway requires loadBalancerSku Standard",
+		},
+		{
+			name:        "natGatewayProfile without a NAT gateway outbound type",
+			natGateway:  &NATGatewayProfile{IdleTimeoutInMinutes: 10},
+			expectedErr: "natGatewayProfile is only supported with outboundType managedNATGateway or userAssignedNATGateway",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			p := &Properties{
+				OrchestratorProfile: &OrchestratorProfile{
+					KubernetesConfig: &KubernetesConfig{
+						OutboundType:      test.outboundType,
+						RouteTableID:      test.routeTableID,
+						NATGatewayProfile: test.natGateway,
+						LoadBalancerSku:   test.loadBalancerSku,
+					},
+				},
+				MasterProfile: &MasterProfile{},
+			}
+			if test.customVNET {
+				p.MasterProfile.VnetSubnetID = "/subscriptions/SUB_ID/resourceGroups/RG_NAME/providers/Microsoft.Network/virtualNetworks/vnet/subnets/subnet"
+			}
+			err := p.validateOutboundType()
+			if test.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %s", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("expected error %q, got %v", test.expectedErr, err)
+			}
+		})
+	}
+}
@@ -63,6 +63,7 @@ type AzureClient struct {
 	resourceSkusClient              compute.ResourceSkusClient
 	storageAccountsClient           storage.AccountsClient
 	interfacesClient                network.InterfacesClient
+	securityGroupsClient            network.SecurityGroupsClient
 	groupsClient                    resources.GroupsClient
 	subscriptionsClient             subscriptions.Client
 	providersClient                 resources.ProvidersClient
@@ -364,6 +365,7 @@ func getClient(env azure.Environment, subscriptionID, tenantID string, armAuthor
 		resourceSkusClient:              compute.NewResourceSkusClientWithBaseURI(env.ResourceManagerEndpoint, subscriptionID),
 		storageAccountsClient:           storage.NewAccountsClientWithBaseURI(env.ResourceManagerEndpoint, subscriptionID),
 		interfacesClient:                network.NewInterfacesClientWithBaseURI(env.ResourceManagerEndpoint, subscriptionID),
+		securityGroupsClient:            network.NewSecurityGroupsClientWithBaseURI(env.ResourceManagerEndpoint, subscriptionID),
 		groupsClient:                    resources.NewGroupsClientWithBaseURI(env.ResourceManagerEndpoint, subscriptionID),
 		subscriptionsClient:             subscriptions.NewClientWithBaseURI(env.ResourceManagerEndpoint),
 		providersClient:                 resources.NewProvidersClientWithBaseURI(env.ResourceManagerEndpoint, subscriptionID),
@@ -387,6 +389,7 @@ func getClient(env azure.Environment, subscriptionID, tenantID string, armAuthor
 	c.disksClient.Authorizer = armAuthorizer
 	c.groupsClient.Authorizer = armAuthorizer
 	c.interfacesClient.Authorizer = armAuthorizer
+	c.securityGroupsClient.Authorizer = armAuthorizer
 	c.msiClient.Authorizer = armAuthorizer
 	c.providersClient.Authorizer = armAuthorizer
 	c.resourcesClient.Authorizer = armAuthorizer
@@ -416,6 +419,7 @@ func getClient(env azure.Environment, subscriptionID, tenantID string, armAuthor
 	c.groupsClient.PollingDuration = DefaultARMOperationTimeout
 	c.subscriptionsClient.PollingDuration = DefaultARMOperationTimeout
 	c.interfacesClient.PollingDuration = DefaultARMOperationTimeout
+	c.securityGroupsClient.PollingDuration = DefaultARMOperationTimeout
 	c.msiClient.PollingDuration = DefaultARMOperationTimeout
 	c.providersClient.PollingDuration = DefaultARMOperationTimeout
 	c.resourcesClient.PollingDuration = DefaultARMOperationTimeout
@@ -506,6 +510,7 @@ func (az *AzureClient) AddAcceptLanguages(languages []string) {
 	az.disksClient.Client.RequestInspector = az.addAcceptLanguages()
 	az.groupsClient.Client.RequestInspector = az.addAcceptLanguages()
 	az.interfacesClient.Client.RequestInspector = az.addAcceptLanguages()
+	az.securityGroupsClient.Client.RequestInspector = az.addAcceptLanguages()
 	az.msiClient.Client.RequestInspector = az.addAcceptLanguages()
 	az.providersClient.Client.RequestInspector = az.addAcceptLanguages()
 	az.resourcesClient.Client.RequestInspector = az.addAcceptLanguages()
@@ -575,6 +580,7 @@ func (az *AzureClient) AddAuxiliaryTokens(tokens []string) {
 	az.disksClient.Client.RequestInspector = requestWithTokens
 	az.groupsClient.Client.RequestInspector = requestWithTokens
 	az.interfacesClient.Client.RequestInspector = requestWithTokens
+	az.securityGroupsClient.Client.RequestInspector = requestWithTokens
 	az.msiClient.Client.RequestInspector = requestWithTokens
 	az.providersClient.Client.RequestInspector = requestWithTokens
 	az.resourcesClient.Client.RequestInspector = requestWithTokens
@@ -59,6 +59,7 @@ type AzureClient struct {
 	resourcesClient                 apimanagement.GroupClient
 	storageAccountsClient           storage.AccountsClient
 	interfacesClient                network.InterfacesClient
+	securityGroupsClient            network.SecurityGroupsClient
 	groupsClient                    resources.GroupsClient
 	subscriptionsClient             subscriptions.Client
 	providersClient                 resources.ProvidersClient
@@ -224,6 +225,7 @@ func getClient(env azure.Environment, subscriptionID, tenantID string, armAuthor
 		resourcesClient:                 apimanagement.NewGroupClientWithBaseURI(env.ResourceManagerEndpoint, subscriptionID),
 		storageAccountsClient:           storage.NewAccountsClientWithBaseURI(env.ResourceManagerEndpoint, subscriptionID),
 		interfacesClient:                network.NewInterfacesClientWithBaseURI(env.ResourceManagerEndpoint, subscriptionID),
+		securityGroupsClient:            network.NewSecurityGroupsClientWithBaseURI(env.ResourceManagerEndpoint, subscriptionID),
 		groupsClient:                    resources.NewGroupsClientWithBaseURI(env.ResourceManagerEndpoint, subscriptionID),
 		subscriptionsClient:             subscriptions.NewClientWithBaseURI(env.ResourceManagerEndpoint),
 		providersClient:                 resources.NewProvidersClientWithBaseURI(env.ResourceManagerEndpoint, subscriptionID),
@@ -247,6 +249,7 @@ func getClient(env azure.Environment, subscriptionID, tenantID string, armAuthor
 	c.resourcesClient.Authorizer = armAuthorizer
 	c.storageAccountsClient.Authorizer = armAuthorizer
 	c.interfacesClient.Authorizer = armAuthorizer
+	c.securityGroupsClient.Authorizer = armAuthorizer
 	c.groupsClient.Authorizer = armAuthorizer
 	c.subscriptionsClient.Authorizer = armAuthorizer
 	c.providersClient.Authorizer = armAuthorizer
@@ -270,6 +273,7 @@ func getClient(env azure.Environment, subscriptionID, tenantID string, armAuthor
 	c.groupsClient.PollingDuration = DefaultARMOperationTimeout
 	c.subscriptionsClient.PollingDuration = DefaultARMOperationTimeout
 	c.interfacesClient.PollingDuration = DefaultARMOperationTimeout
+	c.securityGroupsClient.PollingDuration = DefaultARMOperationTimeout
 	c.providersClient.PollingDuration = DefaultARMOperationTimeout
 	c.resourcesClient.PollingDuration = DefaultARMOperationTimeout
 	c.storageAccountsClient.PollingDuration = DefaultARMOperationTimeout
@@ -374,6 +378,7 @@ func (az *AzureClient) AddAcceptLanguages(languages []string) {
 	az.resourcesClient.Client.RequestInspector = az.addAcceptLanguages()
 	az.storageAccountsClient.Client.RequestInspector = az.addAcceptLanguages()
 	az.interfacesClient.Client.RequestInspector = az.addAcceptLanguages()
+	az.securityGroupsClient.Client.RequestInspector = az.addAcceptLanguages()
 	az.groupsClient.Client.RequestInspector = az.addAcceptLanguages()
 	az.subscriptionsClient.Client.RequestInspector = az.addAcceptLanguages()
 	az.providersClient.Client.RequestInspector = az.addAcceptLanguages()
@@ -438,6 +443,7 @@ func (az *AzureClient) AddAuxiliaryTokens(tokens []string) {
 	az.resourcesClient.Client.RequestInspector = requestWithTokens
 	az.storageAccountsClient.Client.RequestInspector = requestWithTokens
 	az.interfacesClient.Client.RequestInspector = requestWithTokens
+	az.securityGroupsClient.Client.RequestInspector = requestWithTokens
 	az.groupsClient.Client.RequestInspector = requestWithTokens
 	az.subscriptionsClient.Client.RequestInspector = requestWithTokens
 	az.providersClient.Client.RequestInspector = requestWithTokens
@@ -179,6 +179,12 @@ func (az *AzureClient) GetAvailabilitySet(ctx context.Context, resourceGroup, av
 	return azVMAS, nil
 }
 
+// DeleteAvailabilitySet deletes the specified VM availability set.
+func (az *AzureClient) DeleteAvailabilitySet(ctx context.Context, resourceGroup, availabilitySetName string) error {
+	_, err := az.availabilitySetsClient.Delete(ctx, resourceGroup, availabilitySetName)
+	return err
+}
+
 // GetAvailabilitySetFaultDomainCount returns the first existing fault domain count it finds from the IDs provided.
 func (az *AzureClient) GetAvailabilitySetFaultDomainCount(ctx context.Context, resourceGroup string, vmasIDs []string) (int, error) {
 	var count int
@@ -216,3 +222,35 @@ func (az *AzureClient) GetVirtualMachineScaleSetInstancePowerState(ctx context.C
 	// TODO Pass compute.InstanceView once we upgrade azure stack compute's api version
 	return "", errors.Errorf("operation not supported")
 }
+
+// RunVirtualMachineCommand runs a command on the specified virtual machine through the Run Command API
+func (az *AzureClient) RunVirtualMachineCommand(ctx context.Context, resourceGroup, name string, input azcompute.RunCommandInput) (azcompute.RunCommandResult, error) {
+	azResult := azcompute.RunCommandResult{}
+	in := compute.RunCommandInput{}
+	if err := DeepCopy(&in, input); err != nil {
+		return azResult, fmt.Errorf("fail to convert run command input, %v", err)
+	}
+	future, err := az.virtualMachinesClient.RunCommand(ctx, resourceGroup, name, in)
+	if err != nil {
+		return azResult, err
+	}
+
+	if err = future.WaitForCompletionRef(ctx, az.virtualMachinesClient.Client); err != nil {
+		return azResult, err
+	}
+
+	result, err := future.Result(az.virtualMachinesClient)
+	if err != nil {
+		return azResult, err
+	}
+	if err = DeepCopy(&azResult, result); err != nil {
+		return azResult, fmt.Errorf("fail to convert run command result, %v", err)
+	}
+	return azResult, nil
+}
+
+// RunVirtualMachineScaleSetVMCommand runs a command on the specified VMSS VM through the Run Command API
+func (az *AzureClient) RunVirtualMachineScaleSetVMCommand(ctx context.Context, resourceGroup, virtualMachineScaleSet, instanceID string, input azcompute.RunCommandInput) (azcompute.RunCommandResult, error) {
+	// TODO Implement once we upgrade azure stack compute's api version
+	return azcompute.RunCommandResult{}, errors.Errorf("operation not supported")
+}
@@ -7,8 +7,10 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/Azure/aks-engine/pkg/armhelpers"
 	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources"
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -71,3 +73,8 @@ func (az *AzureClient) GetDeployment(ctx context.Context, resourceGroupName, dep
 func (az *AzureClient) CheckDeploymentExistence(ctx context.Context, resourceGroupName string, deploymentName string) (result autorest.Response, err error) {
 	return az.deploymentsClient.CheckExistence(ctx, resourceGroupName, deploymentName)
 }
+
+// WhatIfDeployment is not supported, as Azure Stack Hub does not implement the what-if operation
+func (az *AzureClient) WhatIfDeployment(ctx context.Context, resourceGroupName, deploymentName string, template map[string]interface{}, parameters map[string]interface{}) (armhelpers.WhatIfResult, error) {
+	return armhelpers.WhatIfResult{}, errors.New("the what-if operation is not supported on Azure Stack Hub")
+}
@@ -21,3 +21,18 @@ func (az *AzureClient) DeleteNetworkInterface(ctx context.Context, resourceGroup
 	_, err = future.Result(az.interfacesClient)
 	return err
 }
+
+// DeleteNetworkSecurityGroup deletes the specified network security group.
+func (az *AzureClient) DeleteNetworkSecurityGroup(ctx context.Context, resourceGroup, nsgName string) error {
+	future, err := az.securityGroupsClient.Delete(ctx, resourceGroup, nsgName)
+	if err != nil {
+		return err
+	}
+
+	if err = future.WaitForCompletionRef(ctx, az.securityGroupsClient.Client); err != nil {
+		return err
+	}
+
+	_, err = future.Result(az.securityGroupsClient)
+	return err
+}
@@ -5,7 +5,12 @@ package azurestack
 
 import (
 	"context"
+	"io"
 	"testing"
+	"time"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
 )
 
 const (
@@ -76,3 +81,41 @@ func TestVMImageFetcherInterfaceBadInput(t *testing.T) {
 		t.Fatal("ListVirtualMachineImages did not fail with bad input")
 	}
 }
+
+func TestValidateRequiredImagesThroughWrappedClient(t *testing.T) {
+	mc, err := NewHTTPMockClient()
+	if err != nil {
+		t.Fatalf("failed to create HttpMockClient - %s", err)
+	}
+	mc.Publisher = api.AKSUbuntu2004OSImageConfig.ImagePublisher
+	mc.Offer = api.AKSUbuntu2004OSImageConfig.ImageOffer
+	mc.Sku = api.AKSUbuntu2004OSImageConfig.ImageSku
+	mc.Version = api.AKSUbuntu2004OSImageConfig.ImageVersion
+	mc.RegisterLogin()
+	mc.RegisterVMImageFetcherInterface()
+
+	err = mc.Activate()
+	if err != nil {
+		t.Fatalf("failed to activate HttpMockClient - %s", err)
+	}
+	defer mc.DeactivateAndReset()
+
+	env := mc.GetEnvironment()
+	azureClient, err := NewAzureClientWithClientSecret(env, subscriptionID, "clientID", "secret")
+	if err != nil {
+		t.Fatalf("can not get client %s", err)
+	}
+
+	properties := &api.Properties{
+		MasterProfile: &api.MasterProfile{Distro: api.AKSUbuntu2004},
+	}
+	client := armhelpers.WithDeploymentProgress(azureClient, armhelpers.NewTextDeploymentProgressReporter(io.Discard), time.Second)
+	if err = armhelpers.ValidateRequiredImages(context.Background(), location, properties, client); err != nil {
+		t.Errorf("unexpected error validating the required images through the deployment progress client: %s", err)
+	}
+
+	properties.MasterProfile.Distro = api.AKSUbuntu1804
+	if err = armhelpers.ValidateRequiredImages(context.Background(), location, properties, client); err == nil {
+		t.Error("expected an error validating a missing image through the deployment progress client")
+	}
+}
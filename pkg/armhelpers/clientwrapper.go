@@ -0,0 +1,27 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package armhelpers
+
+// ClientWrapper is an AKSEngineClient that decorates another AKSEngineClient,
+// e.g. to report the progress of its deployments
+type ClientWrapper interface {
+	// Unwrap returns the decorated client
+	Unwrap() AKSEngineClient
+}
+
+// AsVMImageFetcher returns client as a VMImageFetcher, or the first client it wraps that is one.
+// The wrappers only embed the AKSEngineClient interface, so they hide the extensions of the client they wrap
+func AsVMImageFetcher(client AKSEngineClient) (VMImageFetcher, bool) {
+	for client != nil {
+		if fetcher, ok := client.(VMImageFetcher); ok {
+			return fetcher, true
+		}
+		wrapper, ok := client.(ClientWrapper)
+		if !ok {
+			return nil, false
+		}
+		client = wrapper.Unwrap()
+	}
+	return nil, false
+}
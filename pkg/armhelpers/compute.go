@@ -134,6 +134,12 @@ func (az *AzureClient) GetAvailabilitySet(ctx context.Context, resourceGroup, av
 	return az.availabilitySetsClient.Get(ctx, resourceGroup, availabilitySetName)
 }
 
+// DeleteAvailabilitySet deletes the specified VM availability set.
+func (az *AzureClient) DeleteAvailabilitySet(ctx context.Context, resourceGroup, availabilitySetName string) error {
+	_, err := az.availabilitySetsClient.Delete(ctx, resourceGroup, availabilitySetName)
+	return err
+}
+
 // GetAvailabilitySetFaultDomainCount returns the first existing fault domain count it finds from the IDs provided.
 func (az *AzureClient) GetAvailabilitySetFaultDomainCount(ctx context.Context, resourceGroup string, vmasIDs []string) (int, error) {
 	var count int
@@ -179,3 +185,31 @@ func (az *AzureClient) GetVirtualMachineScaleSetInstancePowerState(ctx context.C
 	}
 	return "", nil
 }
+
+// RunVirtualMachineCommand runs a command on the specified virtual machine through the Run Command API
+func (az *AzureClient) RunVirtualMachineCommand(ctx context.Context, resourceGroup, name string, input compute.RunCommandInput) (compute.RunCommandResult, error) {
+	future, err := az.virtualMachinesClient.RunCommand(ctx, resourceGroup, name, input)
+	if err != nil {
+		return compute.RunCommandResult{}, err
+	}
+
+	if err = future.WaitForCompletionRef(ctx, az.virtualMachinesClient.Client); err != nil {
+		return compute.RunCommandResult{}, err
+	}
+
+	return future.Result(az.virtualMachinesClient)
+}
+
+// RunVirtualMachineScaleSetVMCommand runs a command on the specified VMSS VM through the Run Command API
+func (az *AzureClient) RunVirtualMachineScaleSetVMCommand(ctx context.Context, resourceGroup, virtualMachineScaleSet, instanceID string, input compute.RunCommandInput) (compute.RunCommandResult, error) {
+	future, err := az.virtualMachineScaleSetVMsClient.RunCommand(ctx, resourceGroup, virtualMachineScaleSet, instanceID, input)
+	if err != nil {
+		return compute.RunCommandResult{}, err
+	}
+
+	if err = future.WaitForCompletionRef(ctx, az.virtualMachineScaleSetVMsClient.Client); err != nil {
+		return compute.RunCommandResult{}, err
+	}
+
+	return future.Result(az.virtualMachineScaleSetVMsClient)
+}
@@ -5,10 +5,12 @@ package armhelpers
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2017-03-30/compute"
 	azcompute "github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -296,3 +298,50 @@ func TestGetAvailabilitySetFaultDomainCount(t *testing.T) {
 		t.Fatalf("platform fault domain count: expected %d but got %d", expected, count)
 	}
 }
+
+func TestRunCommand(t *testing.T) {
+	mc, err := NewHTTPMockClient()
+	if err != nil {
+		t.Fatalf("failed to create HttpMockClient - %s", err)
+	}
+
+	mc.RegisterLogin()
+	mc.RegisterRunCommand()
+
+	err = mc.Activate()
+	if err != nil {
+		t.Fatalf("failed to activate HttpMockClient - %s", err)
+	}
+	defer mc.DeactivateAndReset()
+
+	env := mc.GetEnvironment()
+	azureClient, err := NewAzureClientWithClientSecret(env, subscriptionID, "clientID", "secret")
+	if err != nil {
+		t.Fatalf("can not get client %s", err)
+	}
+
+	input := azcompute.RunCommandInput{
+		CommandID: to.StringPtr("RunPowerShellScript"),
+		Script:    &[]string{"c:\\k\\debug\\collect-windows-logs.ps1"},
+	}
+	for name, run := range map[string]func() (azcompute.RunCommandResult, error){
+		"RunVirtualMachineCommand": func() (azcompute.RunCommandResult, error) {
+			return azureClient.RunVirtualMachineCommand(context.Background(), resourceGroup, virtualMachineName, input)
+		},
+		"RunVirtualMachineScaleSetVMCommand": func() (azcompute.RunCommandResult, error) {
+			return azureClient.RunVirtualMachineScaleSetVMCommand(context.Background(), resourceGroup, virtualMachineScaleSetName, virtualMachineScaleSetInstanceID, input)
+		},
+	} {
+		result, err := run()
+		if err != nil {
+			t.Fatalf("%s: unexpected error %s", name, err)
+		}
+		if result.Value == nil || len(*result.Value) != 2 {
+			t.Fatalf("%s: expected the stdout and stderr statuses, got %v", name, result.Value)
+		}
+		stdout := (*result.Value)[0]
+		if *stdout.Code != "ComponentStatus/StdOut/succeeded" || !strings.HasPrefix(*stdout.Message, "collected ") {
+			t.Errorf("%s: unexpected stdout status %s %s", name, *stdout.Code, *stdout.Message)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/Azure/aks-engine/pkg/api"
@@ -49,6 +51,114 @@ func (e *DeploymentError) Error() string {
 		e.DeploymentName, e.ResourceGroup, str, e.StatusCode, e.Response, e.ProvisioningState, strings.Join(ops, " | "))
 }
 
+// ExtensionFailure is a VM extension that failed to provision during a deployment
+type ExtensionFailure struct {
+	// VMName is the name of the VM, or of the VMSS, the extension failed on
+	VMName string
+	// ExtensionName is the name of the extension, empty when the extension of a VMSS failed
+	ExtensionName string
+	Message       string
+	// ExitCode is the exit status of the extension command, or -1 if the message does not report one
+	ExitCode int
+	// Windows is true if the failure was reported by the custom script extension of a Windows VM,
+	// whose exit status is not a CSE error code
+	Windows bool
+}
+
+const vmExtensionProvisioningErrorCode = "VMExtensionProvisioningError"
+
+var (
+	extensionExitStatusRegexp        = regexp.MustCompile(`exit status=(\d+)`)
+	windowsExtensionExitStatusRegexp = regexp.MustCompile(`non-zero exit code of: '(-?\d+)'`)
+)
+
+// ExtensionFailures returns the VM extension provisioning failures of the failed deployment operations
+func (e *DeploymentError) ExtensionFailures() []ExtensionFailure {
+	var failures []ExtensionFailure
+	for _, operationsList := range e.OperationsLists {
+		if operationsList.Value == nil {
+			continue
+		}
+		for _, operation := range *operationsList.Value {
+			if operation.Properties == nil || operation.Properties.ProvisioningState == nil || *operation.Properties.ProvisioningState != string(api.Failed) {
+				continue
+			}
+			// The status message is decoded from the ARM response as arbitrary JSON
+			b, err := json.Marshal(operation.Properties.StatusMessage)
+			if err != nil {
+				continue
+			}
+			var status interface{}
+			if err = json.Unmarshal(b, &status); err != nil {
+				continue
+			}
+			var messages []string
+			findExtensionErrorMessages(status, &messages)
+			if len(messages) == 0 {
+				continue
+			}
+			var vmName, extensionName string
+			if target := operation.Properties.TargetResource; target != nil && target.ResourceName != nil {
+				parts := strings.SplitN(*target.ResourceName, "/", 2)
+				vmName = parts[0]
+				if len(parts) == 2 {
+					extensionName = parts[1]
+				}
+			}
+			for _, message := range messages {
+				exitCode, windows := extensionExitCode(message)
+				failures = append(failures, ExtensionFailure{
+					VMName:        vmName,
+					ExtensionName: extensionName,
+					Message:       message,
+					ExitCode:      exitCode,
+					Windows:       windows,
+				})
+			}
+		}
+	}
+	return failures
+}
+
+// findExtensionErrorMessages appends to messages the messages of the VM extension provisioning errors nested in a deployment operation status message
+func findExtensionErrorMessages(status interface{}, messages *[]string) {
+	switch s := status.(type) {
+	case map[string]interface{}:
+		if code, ok := s["code"].(string); ok && code == vmExtensionProvisioningErrorCode {
+			if message, ok := s["message"].(string); ok {
+				*messages = append(*messages, message)
+				return
+			}
+		}
+		for _, v := range s {
+			findExtensionErrorMessages(v, messages)
+		}
+	case []interface{}:
+		for _, v := range s {
+			findExtensionErrorMessages(v, messages)
+		}
+	}
+}
+
+// extensionExitCode returns the exit status reported by the message of the Linux or the Windows custom script extension,
+// and whether the message was reported by the Windows extension
+func extensionExitCode(message string) (int, bool) {
+	match := extensionExitStatusRegexp.FindStringSubmatch(message)
+	windows := false
+	if match == nil {
+		match = windowsExtensionExitStatusRegexp.FindStringSubmatch(message)
+		windows = match != nil
+	}
+	if match == nil {
+		return -1, false
+	}
+	code, err := strconv.Atoi(match[1])
+	if err != nil {
+		return -1, windows
+	}
+	return code, windows
+}
+
 // DeploymentValidationError contains validation error
 type DeploymentValidationError struct {
 	Err error
@@ -63,6 +173,11 @@ func (e *DeploymentValidationError) Error() string {
 func DeployTemplateSync(az AKSEngineClient, logger *logrus.Entry, resourceGroupName, deploymentName string, template map[string]interface{}, parameters map[string]interface{}) error {
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultARMOperationTimeout)
 	defer cancel()
+	return DeployTemplateSyncWithContext(ctx, az, logger, resourceGroupName, deploymentName, template, parameters)
+}
+
+// DeployTemplateSyncWithContext deploys the template within ctx and returns ArmError
+func DeployTemplateSyncWithContext(ctx context.Context, az AKSEngineClient, logger *logrus.Entry, resourceGroupName, deploymentName string, template map[string]interface{}, parameters map[string]interface{}) error {
 	deploymentExtended, err := az.DeployTemplate(ctx, resourceGroupName, deploymentName, template, parameters)
 	if err == nil {
 		return nil
@@ -10,6 +10,7 @@ import (
 	"github.com/onsi/gomega/types"
 
 	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
@@ -150,3 +151,112 @@ func TestDeploymentError_Error(t *testing.T) {
 		t.Errorf("expected error with message %s, but got %s", expected, errString)
 	}
 }
+
+func TestDeploymentError_ExtensionFailures(t *testing.T) {
+	t.Parallel()
+
+	failed := "Failed"
+	succeeded := "Succeeded"
+	vmExtensionStatus := map[string]interface{}{
+		"status": "Failed",
+		"error": map[string]interface{}{
+			"code":    "ResourceDeploymentFailure",
+			"message": "The resource operation completed with terminal provisioning state 'Failed'.",
+			"details": []interface{}{
+				map[string]interface{}{
+					"code":    "VMExtensionProvisioningError",
+					"message": "VM has reported a failure when processing extension 'cse-agent-0'. Error message: \"Enable failed: failed to execute command: command terminated with exit status=52\n[stdout]\n\n[stderr]\n\"",
+				},
+			},
+		},
+	}
+	vmssExtensionStatus := map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    "VMExtensionProvisioningError",
+			"message": "VM has reported a failure when processing extension 'vmssCSE'. Error message: \"Enable failed: failed to execute command: timed out\"",
+		},
+	}
+	windowsExtensionStatus := map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    "VMExtensionProvisioningError",
+			"message": "VM has reported a failure when processing extension 'cse-agent-0'. Error message: \"Command execution finished, but failed because it returned a non-zero exit code of: '1'. The command had an error output of: 'Failed to download https://some/package.zip'\"",
+		},
+	}
+	otherStatus := map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    "Conflict",
+			"message": "Conflict",
+		},
+	}
+	deploymentErr := &DeploymentError{
+		OperationsLists: []resources.DeploymentOperationsListResult{
+			{
+				Value: &[]resources.DeploymentOperation{
+					{
+						Properties: &resources.DeploymentOperationProperties{
+							ProvisioningState: &failed,
+							StatusMessage:     &vmExtensionStatus,
+							TargetResource: &resources.TargetResource{
+								ResourceName: to.StringPtr("k8s-agentpool1-12345678-0/cse-agent-0"),
+							},
+						},
+					},
+					{
+						Properties: &resources.DeploymentOperationProperties{
+							ProvisioningState: &failed,
+							StatusMessage:     otherStatus,
+							TargetResource: &resources.TargetResource{
+								ResourceName: to.StringPtr("k8s-master-12345678-0"),
+							},
+						},
+					},
+					{
+						Properties: &resources.DeploymentOperationProperties{
+							ProvisioningState: &succeeded,
+							StatusMessage:     vmExtensionStatus,
+						},
+					},
+				},
+			},
+			{},
+			{
+				Value: &[]resources.DeploymentOperation{
+					{
+						Properties: &resources.DeploymentOperationProperties{
+							ProvisioningState: &failed,
+							StatusMessage:     vmssExtensionStatus,
+							TargetResource: &resources.TargetResource{
+								ResourceName: to.StringPtr("k8s-agentpool2-12345678-vmss"),
+							},
+						},
+					},
+					{
+						Properties: &resources.DeploymentOperationProperties{
+							ProvisioningState: &failed,
+							StatusMessage:     windowsExtensionStatus,
+							TargetResource: &resources.TargetResource{
+								ResourceName: to.StringPtr("1234k8s000/cse-agent-0"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	g := NewGomegaWithT(t)
+	failures := deploymentErr.ExtensionFailures()
+	g.Expect(failures).To(HaveLen(3))
+	g.Expect(failures[0].VMName).To(Equal("k8s-agentpool1-12345678-0"))
+	g.Expect(failures[0].ExtensionName).To(Equal("cse-agent-0"))
+	g.Expect(failures[0].ExitCode).To(Equal(52))
+	g.Expect(failures[0].Message).To(ContainSubstring("exit status=52"))
+	g.Expect(failures[0].Windows).To(BeFalse())
+	g.Expect(failures[1].VMName).To(Equal("k8s-agentpool2-12345678-vmss"))
+	g.Expect(failures[1].ExtensionName).To(BeEmpty())
+	g.Expect(failures[1].ExitCode).To(Equal(-1))
+	g.Expect(failures[1].Windows).To(BeFalse())
+	g.Expect(failures[2].VMName).To(Equal("1234k8s000"))
+	g.Expect(failures[2].ExitCode).To(Equal(1))
+	g.Expect(failures[2].Windows).To(BeTrue())
+}
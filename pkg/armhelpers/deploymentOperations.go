@@ -5,6 +5,9 @@ package armhelpers
 
 import (
 	"context"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/go-autorest/autorest/to"
 )
 
 // ListDeploymentOperations gets all deployments operations for a deployment.
@@ -12,3 +15,24 @@ func (az *AzureClient) ListDeploymentOperations(ctx context.Context, resourceGro
 	list, err := az.deploymentOperationsClient.List(ctx, resourceGroupName, deploymentName, top)
 	return &list, err
 }
+
+// ListDeployedResources returns the IDs of the target resources of the succeeded operations of a deployment,
+// that is the resources the deployment created or updated
+func ListDeployedResources(ctx context.Context, az AKSEngineClient, resourceGroupName, deploymentName string) ([]string, error) {
+	var resourceIDs []string
+	seen := make(map[string]bool)
+	page, err := az.ListDeploymentOperations(ctx, resourceGroupName, deploymentName, nil)
+	for ; err == nil && page.NotDone(); err = page.Next() {
+		for _, operation := range page.Values() {
+			if operation.Properties == nil || to.String(operation.Properties.ProvisioningState) != string(api.Succeeded) || operation.Properties.TargetResource == nil {
+				continue
+			}
+			id := to.String(operation.Properties.TargetResource.ID)
+			if id != "" && !seen[id] {
+				seen[id] = true
+				resourceIDs = append(resourceIDs, id)
+			}
+		}
+	}
+	return resourceIDs, err
+}
@@ -0,0 +1,63 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package armhelpers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+)
+
+// operationsMockClient lists the given operations for every deployment
+type operationsMockClient struct {
+	*MockAKSEngineClient
+	operations []resources.DeploymentOperation
+}
+
+func (c *operationsMockClient) ListDeploymentOperations(ctx context.Context, resourceGroupName string, deploymentName string, top *int32) (DeploymentOperationsListResultPage, error) {
+	return &MockDeploymentOperationsListResultPage{
+		Fn: func(resources.DeploymentOperationsListResult) (resources.DeploymentOperationsListResult, error) {
+			return resources.DeploymentOperationsListResult{}, nil
+		},
+		Dolr: resources.DeploymentOperationsListResult{Value: &c.operations},
+	}, nil
+}
+
+func TestListDeployedResources(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	operation := func(state, resourceID string) resources.DeploymentOperation {
+		return resources.DeploymentOperation{
+			Properties: &resources.DeploymentOperationProperties{
+				ProvisioningState: to.StringPtr(state),
+				TargetResource:    &resources.TargetResource{ID: to.StringPtr(resourceID)},
+			},
+		}
+	}
+	vmID := "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/k8s-agentpool1-12345678-0"
+	nicID := "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Network/networkInterfaces/k8s-agentpool1-12345678-nic-0"
+	client := &operationsMockClient{
+		MockAKSEngineClient: &MockAKSEngineClient{},
+		operations: []resources.DeploymentOperation{
+			operation("Succeeded", nicID),
+			operation("Succeeded", vmID),
+			operation("Succeeded", vmID),
+			operation("Failed", vmID+"/extensions/cse-agent-0"),
+			{Properties: &resources.DeploymentOperationProperties{ProvisioningState: to.StringPtr("Succeeded")}},
+		},
+	}
+
+	ids, err := ListDeployedResources(context.Background(), client, "rg1", "deployment1")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ids).To(Equal([]string{nicID, vmID}))
+
+	// The operations of the default mock client all failed
+	ids, err = ListDeployedResources(context.Background(), &MockAKSEngineClient{}, "rg1", "deployment1")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ids).To(BeEmpty())
+}
@@ -0,0 +1,244 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package armhelpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+	log "github.com/sirupsen/logrus"
+)
+
+// Types of deployment events
+const (
+	// DeploymentEventStarted is reported when the deployment starts
+	DeploymentEventStarted = "Started"
+	// DeploymentEventResource is reported when the provisioning state of a resource of the deployment changes
+	DeploymentEventResource = "Resource"
+	// DeploymentEventProgress is reported each time the operations of the deployment are polled
+	DeploymentEventProgress = "Progress"
+	// DeploymentEventCompleted is reported when the deployment returns
+	DeploymentEventCompleted = "Completed"
+)
+
+// DefaultDeploymentProgressInterval is the default interval between two polls of the operations of a deployment
+const DefaultDeploymentProgressInterval = 30 * time.Second
+
+// DeploymentEvent is a change in the progress of an ARM deployment
+type DeploymentEvent struct {
+	Type           string    `json:"type"`
+	Time           time.Time `json:"time"`
+	ResourceGroup  string    `json:"resourceGroup"`
+	Deployment     string    `json:"deployment"`
+	ElapsedSeconds int64     `json:"elapsedSeconds"`
+	// ResourceType, ResourceName and StatusMessage are only set on Resource events
+	ResourceType      string `json:"resourceType,omitempty"`
+	ResourceName      string `json:"resourceName,omitempty"`
+	ProvisioningState string `json:"provisioningState,omitempty"`
+	StatusMessage     string `json:"statusMessage,omitempty"`
+	// The counts of resources are only set on Progress events
+	Succeeded int `json:"succeeded,omitempty"`
+	Running   int `json:"running,omitempty"`
+	Failed    int `json:"failed,omitempty"`
+	Total     int `json:"total,omitempty"`
+	// Error is only set on Completed events of failed deployments
+	Error string `json:"error,omitempty"`
+}
+
+// DeploymentProgressReporter reports the events of ARM deployments
+type DeploymentProgressReporter interface {
+	Report(event DeploymentEvent)
+}
+
+// NewTextDeploymentProgressReporter returns a DeploymentProgressReporter that writes human readable lines to w
+func NewTextDeploymentProgressReporter(w io.Writer) DeploymentProgressReporter {
+	return &textDeploymentProgressReporter{w: w}
+}
+
+// NewJSONDeploymentProgressReporter returns a DeploymentProgressReporter that writes one JSON object per event to w
+func NewJSONDeploymentProgressReporter(w io.Writer) DeploymentProgressReporter {
+	return &jsonDeploymentProgressReporter{encoder: json.NewEncoder(w)}
+}
+
+type textDeploymentProgressReporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (r *textDeploymentProgressReporter) Report(event DeploymentEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	elapsed := time.Duration(event.ElapsedSeconds) * time.Second
+	switch event.Type {
+	case DeploymentEventStarted:
+		fmt.Fprintf(r.w, "[%s] Deployment %s started in resource group %s\n", elapsed, event.Deployment, event.ResourceGroup)
+	case DeploymentEventResource:
+		fmt.Fprintf(r.w, "[%s] %s %s: %s\n", elapsed, event.ResourceType, event.ResourceName, event.ProvisioningState)
+		if event.StatusMessage != "" {
+			fmt.Fprintf(r.w, "    %s\n", event.StatusMessage)
+		}
+	case DeploymentEventProgress:
+		fmt.Fprintf(r.w, "[%s] Deployment %s: %d/%d resources succeeded, %d running, %d failed\n", elapsed, event.Deployment, event.Succeeded, event.Total, event.Running, event.Failed)
+	case DeploymentEventCompleted:
+		if event.Error != "" {
+			fmt.Fprintf(r.w, "[%s] Deployment %s failed\n", elapsed, event.Deployment)
+		} else {
+			fmt.Fprintf(r.w, "[%s] Deployment %s succeeded\n", elapsed, event.Deployment)
+		}
+	}
+}
+
+type jsonDeploymentProgressReporter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func (r *jsonDeploymentProgressReporter) Report(event DeploymentEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.encoder.Encode(event)
+}
+
+// deploymentProgressClient is an AKSEngineClient whose template deployments report their progress
+type deploymentProgressClient struct {
+	AKSEngineClient
+	reporter DeploymentProgressReporter
+	interval time.Duration
+}
+
+// WithDeploymentProgress returns an AKSEngineClient that reports the progress of the template deployments of az to reporter,
+// polling the deployment operations every interval while a deployment runs
+func WithDeploymentProgress(az AKSEngineClient, reporter DeploymentProgressReporter, interval time.Duration) AKSEngineClient {
+	return &deploymentProgressClient{
+		AKSEngineClient: az,
+		reporter:        reporter,
+		interval:        interval,
+	}
+}
+
+// Unwrap returns the wrapped client
+func (c *deploymentProgressClient) Unwrap() AKSEngineClient {
+	return c.AKSEngineClient
+}
+
+// DeployTemplate deploys the template like the wrapped client, and reports the progress of the deployment while it runs
+func (c *deploymentProgressClient) DeployTemplate(ctx context.Context, resourceGroupName, deploymentName string, template map[string]interface{}, parameters map[string]interface{}) (resources.DeploymentExtended, error) {
+	p := &deploymentProgress{
+		az:             c.AKSEngineClient,
+		reporter:       c.reporter,
+		resourceGroup:  resourceGroupName,
+		deploymentName: deploymentName,
+		start:          time.Now(),
+		states:         make(map[string]string),
+	}
+	p.report(DeploymentEvent{Type: DeploymentEventStarted})
+
+	pollCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-pollCtx.Done():
+				return
+			case <-ticker.C:
+				p.poll(pollCtx)
+			}
+		}
+	}()
+
+	de, err := c.AKSEngineClient.DeployTemplate(ctx, resourceGroupName, deploymentName, template, parameters)
+	cancel()
+	<-done
+
+	// Poll a last time to report the final state of the resources
+	p.poll(ctx)
+	completed := DeploymentEvent{Type: DeploymentEventCompleted, ProvisioningState: string(api.Succeeded)}
+	if err != nil {
+		completed.ProvisioningState = string(api.Failed)
+		completed.Error = err.Error()
+	}
+	p.report(completed)
+	return de, err
+}
+
+// deploymentProgress tracks the provisioning state of the resources of a deployment
+type deploymentProgress struct {
+	az             AKSEngineClient
+	reporter       DeploymentProgressReporter
+	resourceGroup  string
+	deploymentName string
+	start          time.Time
+	// states are the last reported provisioning states, by operation ID
+	states map[string]string
+}
+
+func (p *deploymentProgress) report(event DeploymentEvent) {
+	event.Time = time.Now()
+	event.ResourceGroup = p.resourceGroup
+	event.Deployment = p.deploymentName
+	event.ElapsedSeconds = int64(event.Time.Sub(p.start) / time.Second)
+	p.reporter.Report(event)
+}
+
+// poll lists the operations of the deployment, reports the resources whose provisioning state changed, then the overall progress
+func (p *deploymentProgress) poll(ctx context.Context) {
+	var operations []resources.DeploymentOperation
+	page, err := p.az.ListDeploymentOperations(ctx, p.resourceGroup, p.deploymentName, nil)
+	for ; err == nil && page.NotDone(); err = page.Next() {
+		operations = append(operations, page.Values()...)
+	}
+	if err != nil {
+		// The operations are not listed until ARM accepts the deployment
+		log.Debugf("unable to list the operations of deployment %s: %v", p.deploymentName, err)
+		return
+	}
+
+	progress := DeploymentEvent{Type: DeploymentEventProgress}
+	for _, operation := range operations {
+		if operation.Properties == nil || operation.Properties.ProvisioningState == nil {
+			continue
+		}
+		state := *operation.Properties.ProvisioningState
+		progress.Total++
+		switch state {
+		case string(api.Succeeded):
+			progress.Succeeded++
+		case string(api.Failed):
+			progress.Failed++
+		default:
+			progress.Running++
+		}
+
+		key := to.String(operation.OperationID)
+		if key == "" {
+			key = to.String(operation.ID)
+		}
+		if p.states[key] == state {
+			continue
+		}
+		p.states[key] = state
+		event := DeploymentEvent{Type: DeploymentEventResource, ProvisioningState: state}
+		if target := operation.Properties.TargetResource; target != nil {
+			event.ResourceType = to.String(target.ResourceType)
+			event.ResourceName = to.String(target.ResourceName)
+		}
+		if state == string(api.Failed) && operation.Properties.StatusMessage != nil {
+			if b, err := json.Marshal(operation.Properties.StatusMessage); err == nil {
+				event.StatusMessage = string(b)
+			}
+		}
+		p.report(event)
+	}
+	p.report(progress)
+}
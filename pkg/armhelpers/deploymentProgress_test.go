@@ -0,0 +1,145 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package armhelpers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+// progressMockClient returns the operations of the next step on each ListDeploymentOperations call,
+// and completes its deployment once every step was listed
+type progressMockClient struct {
+	*MockAKSEngineClient
+	mu        sync.Mutex
+	steps     [][]resources.DeploymentOperation
+	listed    int
+	allListed chan struct{}
+	deployErr error
+}
+
+func (c *progressMockClient) DeployTemplate(ctx context.Context, resourceGroupName, deploymentName string, template map[string]interface{}, parameters map[string]interface{}) (resources.DeploymentExtended, error) {
+	<-c.allListed
+	return resources.DeploymentExtended{}, c.deployErr
+}
+
+func (c *progressMockClient) ListDeploymentOperations(ctx context.Context, resourceGroupName string, deploymentName string, top *int32) (DeploymentOperationsListResultPage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	step := c.steps[len(c.steps)-1]
+	if c.listed < len(c.steps) {
+		step = c.steps[c.listed]
+		c.listed++
+		if c.listed == len(c.steps) {
+			close(c.allListed)
+		}
+	}
+	return &MockDeploymentOperationsListResultPage{
+		Fn: func(resources.DeploymentOperationsListResult) (resources.DeploymentOperationsListResult, error) {
+			return resources.DeploymentOperationsListResult{}, nil
+		},
+		Dolr: resources.DeploymentOperationsListResult{Value: &step},
+	}, nil
+}
+
+func mockDeploymentOperation(operationID, resourceName, state string) resources.DeploymentOperation {
+	operation := resources.DeploymentOperation{
+		OperationID: to.StringPtr(operationID),
+		Properties: &resources.DeploymentOperationProperties{
+			ProvisioningState: to.StringPtr(state),
+			TargetResource: &resources.TargetResource{
+				ResourceType: to.StringPtr("Microsoft.Compute/virtualMachines"),
+				ResourceName: to.StringPtr(resourceName),
+			},
+		},
+	}
+	if state == "Failed" {
+		operation.Properties.StatusMessage = map[string]interface{}{"error": map[string]interface{}{"code": "Conflict"}}
+	}
+	return operation
+}
+
+func newProgressMockClient(deployErr error) *progressMockClient {
+	return &progressMockClient{
+		MockAKSEngineClient: &MockAKSEngineClient{},
+		steps: [][]resources.DeploymentOperation{
+			{
+				mockDeploymentOperation("1", "k8s-master-12345678-0", "Running"),
+				mockDeploymentOperation("2", "k8s-agentpool1-12345678-0", "Running"),
+			},
+			{
+				mockDeploymentOperation("1", "k8s-master-12345678-0", "Succeeded"),
+				mockDeploymentOperation("2", "k8s-agentpool1-12345678-0", "Failed"),
+			},
+		},
+		allListed: make(chan struct{}),
+		deployErr: deployErr,
+	}
+}
+
+func TestDeploymentProgressText(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	var b bytes.Buffer
+	az := WithDeploymentProgress(newProgressMockClient(errors.New("DeployTemplate failed")), NewTextDeploymentProgressReporter(&b), time.Millisecond)
+	_, err := az.DeployTemplate(context.Background(), "rg1", "deployment1", nil, nil)
+	g.Expect(err).To(MatchError("DeployTemplate failed"))
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	g.Expect(lines[0]).To(Equal("[0s] Deployment deployment1 started in resource group rg1"))
+	g.Expect(lines).To(ContainElement("[0s] Microsoft.Compute/virtualMachines k8s-master-12345678-0: Running"))
+	g.Expect(lines).To(ContainElement("[0s] Deployment deployment1: 0/2 resources succeeded, 2 running, 0 failed"))
+	g.Expect(lines).To(ContainElement("[0s] Microsoft.Compute/virtualMachines k8s-master-12345678-0: Succeeded"))
+	g.Expect(lines).To(ContainElement("[0s] Microsoft.Compute/virtualMachines k8s-agentpool1-12345678-0: Failed"))
+	g.Expect(lines).To(ContainElement(`    {"error":{"code":"Conflict"}}`))
+	g.Expect(lines).To(ContainElement("[0s] Deployment deployment1: 1/2 resources succeeded, 0 running, 1 failed"))
+	g.Expect(lines[len(lines)-1]).To(Equal("[0s] Deployment deployment1 failed"))
+	// Each state change is reported once, however many times the operations are polled
+	g.Expect(strings.Count(b.String(), "k8s-master-12345678-0: Succeeded")).To(Equal(1))
+}
+
+func TestDeploymentProgressJSON(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	var b bytes.Buffer
+	az := WithDeploymentProgress(newProgressMockClient(nil), NewJSONDeploymentProgressReporter(&b), time.Millisecond)
+	_, err := az.DeployTemplate(context.Background(), "rg1", "deployment1", nil, nil)
+	g.Expect(err).To(BeNil())
+
+	var events []DeploymentEvent
+	decoder := json.NewDecoder(&b)
+	for decoder.More() {
+		var event DeploymentEvent
+		g.Expect(decoder.Decode(&event)).To(Succeed())
+		g.Expect(event.ResourceGroup).To(Equal("rg1"))
+		g.Expect(event.Deployment).To(Equal("deployment1"))
+		events = append(events, event)
+	}
+	g.Expect(events[0].Type).To(Equal(DeploymentEventStarted))
+	g.Expect(events[len(events)-1].Type).To(Equal(DeploymentEventCompleted))
+	g.Expect(events[len(events)-1].ProvisioningState).To(Equal("Succeeded"))
+	g.Expect(events[len(events)-1].Error).To(BeEmpty())
+
+	var failed []DeploymentEvent
+	for _, event := range events {
+		if event.Type == DeploymentEventResource && event.ProvisioningState == "Failed" {
+			failed = append(failed, event)
+		}
+	}
+	g.Expect(failed).To(HaveLen(1))
+	g.Expect(failed[0].ResourceName).To(Equal("k8s-agentpool1-12345678-0"))
+	g.Expect(failed[0].StatusMessage).To(Equal(`{"error":{"code":"Conflict"}}`))
+}
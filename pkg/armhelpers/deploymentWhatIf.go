@@ -0,0 +1,126 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package armhelpers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/pkg/errors"
+)
+
+// The resources API version of the SDK predates the what-if operation
+const whatIfAPIVersion = "2019-07-01"
+
+// Types of what-if changes
+const (
+	WhatIfChangeCreate   = "Create"
+	WhatIfChangeDelete   = "Delete"
+	WhatIfChangeModify   = "Modify"
+	WhatIfChangeDeploy   = "Deploy"
+	WhatIfChangeNoChange = "NoChange"
+	WhatIfChangeIgnore   = "Ignore"
+)
+
+// WhatIfResult is the result of a what-if operation
+type WhatIfResult struct {
+	Status     string                  `json:"status,omitempty"`
+	Properties *WhatIfResultProperties `json:"properties,omitempty"`
+	Error      *WhatIfError            `json:"error,omitempty"`
+}
+
+// WhatIfResultProperties are the changes a deployment would make
+type WhatIfResultProperties struct {
+	Changes []WhatIfChange `json:"changes,omitempty"`
+}
+
+// WhatIfChange is the change a deployment would make to a resource
+type WhatIfChange struct {
+	ResourceID string                 `json:"resourceId"`
+	ChangeType string                 `json:"changeType"`
+	Before     map[string]interface{} `json:"before,omitempty"`
+	After      map[string]interface{} `json:"after,omitempty"`
+	Delta      []WhatIfPropertyChange `json:"delta,omitempty"`
+}
+
+// WhatIfPropertyChange is the change a deployment would make to a property of a resource
+type WhatIfPropertyChange struct {
+	Path               string                 `json:"path"`
+	PropertyChangeType string                 `json:"propertyChangeType"`
+	Before             interface{}            `json:"before,omitempty"`
+	After              interface{}            `json:"after,omitempty"`
+	Children           []WhatIfPropertyChange `json:"children,omitempty"`
+}
+
+// WhatIfError is the error of a failed what-if operation
+type WhatIfError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Changes returns the changes of the what-if operation
+func (r WhatIfResult) Changes() []WhatIfChange {
+	if r.Properties == nil {
+		return nil
+	}
+	return r.Properties.Changes
+}
+
+// WhatIfDeployment returns the changes that deploying the template would make to the resource group, without deploying it
+func (az *AzureClient) WhatIfDeployment(ctx context.Context, resourceGroupName, deploymentName string, template map[string]interface{}, parameters map[string]interface{}) (result WhatIfResult, err error) {
+	pathParameters := map[string]interface{}{
+		"deploymentName":    autorest.Encode("path", deploymentName),
+		"resourceGroupName": autorest.Encode("path", resourceGroupName),
+		"subscriptionId":    autorest.Encode("path", az.deploymentsClient.SubscriptionID),
+	}
+	queryParameters := map[string]interface{}{
+		"api-version": whatIfAPIVersion,
+	}
+	body := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"template":   template,
+			"parameters": parameters,
+			"mode":       "Incremental",
+		},
+	}
+	req, err := autorest.Prepare((&http.Request{}).WithContext(ctx),
+		autorest.AsContentType("application/json; charset=utf-8"),
+		autorest.AsPost(),
+		autorest.WithBaseURL(az.deploymentsClient.BaseURI),
+		autorest.WithPathParameters("/subscriptions/{subscriptionId}/resourcegroups/{resourceGroupName}/providers/Microsoft.Resources/deployments/{deploymentName}/whatIf", pathParameters),
+		autorest.WithJSON(body),
+		autorest.WithQueryParameters(queryParameters))
+	if err != nil {
+		return result, errors.Wrap(err, "preparing the what-if request")
+	}
+
+	resp, err := az.deploymentsClient.Send(req, azure.DoRetryWithRegistration(az.deploymentsClient.Client))
+	if err != nil {
+		return result, errors.Wrap(err, "sending the what-if request")
+	}
+	future, err := azure.NewFutureFromResponse(resp)
+	if err != nil {
+		return result, errors.Wrap(err, "starting the what-if operation")
+	}
+	if err = future.WaitForCompletionRef(ctx, az.deploymentsClient.Client); err != nil {
+		return result, errors.Wrap(err, "waiting for the what-if operation")
+	}
+	resp, err = future.GetResult(az.deploymentsClient)
+	if err != nil {
+		return result, errors.Wrap(err, "getting the result of the what-if operation")
+	}
+	err = autorest.Respond(resp,
+		azure.WithErrorUnlessStatusCode(http.StatusOK),
+		autorest.ByUnmarshallingJSON(&result),
+		autorest.ByClosing())
+	if err != nil {
+		return result, errors.Wrap(err, "reading the result of the what-if operation")
+	}
+	if result.Error != nil {
+		return result, errors.Errorf("what-if operation failed: %s: %s", result.Error.Code, result.Error.Message)
+	}
+	return result, nil
+}
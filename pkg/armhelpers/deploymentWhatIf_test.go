@@ -0,0 +1,54 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package armhelpers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWhatIfDeployment(t *testing.T) {
+	mc, err := NewHTTPMockClient()
+	if err != nil {
+		t.Fatalf("failed to create HttpMockClient - %s", err)
+	}
+
+	mc.RegisterLogin()
+	mc.RegisterWhatIfDeployment(`{
+		"status": "Succeeded",
+		"properties": {
+			"changes": [
+				{"resourceId": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/k8s-master-12345678-0", "changeType": "Modify", "delta": [{"path": "tags.poolName", "propertyChangeType": "Create", "after": "master"}]},
+				{"resourceId": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/k8s-master-lb", "changeType": "NoChange"}
+			]
+		}
+	}`)
+
+	err = mc.Activate()
+	if err != nil {
+		t.Fatalf("failed to activate HttpMockClient - %s", err)
+	}
+	defer mc.DeactivateAndReset()
+
+	env := mc.GetEnvironment()
+	azureClient, err := NewAzureClientWithClientSecret(env, subscriptionID, "clientID", "secret")
+	if err != nil {
+		t.Fatalf("can not get client %s", err)
+	}
+
+	result, err := azureClient.WhatIfDeployment(context.Background(), resourceGroup, deploymentName, map[string]interface{}{}, map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := result.Changes()
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d", len(changes))
+	}
+	if changes[0].ChangeType != WhatIfChangeModify || len(changes[0].Delta) != 1 || changes[0].Delta[0].Path != "tags.poolName" {
+		t.Errorf("unexpected change %+v", changes[0])
+	}
+	if changes[1].ChangeType != WhatIfChangeNoChange {
+		t.Errorf("unexpected change %+v", changes[1])
+	}
+}
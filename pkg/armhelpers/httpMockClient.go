@@ -43,6 +43,8 @@ const (
 	virutalDiskName                            = "testVirtualdickName"
 	location                                   = "local"
 	operationID                                = "7184adda-13fc-4d49-b941-fbbc3b08ed64"
+	runCommandOperationID                      = "0b2e8b3f-5c4a-4f1e-9d58-2a6c3e1f7b90"
+	virtualMachineScaleSetInstanceID           = "0"
 	publisher                                  = "DefaultPublisher"
 	sku                                        = "DefaultSku"
 	offer                                      = "DefaultOffer"
@@ -65,6 +67,7 @@ const (
 	filePathGetVirtualMachineImage             = "httpMockClientData/getVirtualMachineImage.json"
 	filePathListVirtualMachineImages           = "httpMockClientData/listVirtualMachineImages.json"
 	filePathListResourceSkus                   = "httpMockClientData/listResourceSkus.json"
+	filePathRunCommand                         = "httpMockClientData/runCommand.json"
 )
 
 // HTTPMockClient is an wrapper of httpmock
@@ -96,6 +99,8 @@ type HTTPMockClient struct {
 	VirutalDiskName                            string
 	Location                                   string
 	OperationID                                string
+	RunCommandOperationID                      string
+	VirtualMachineScaleSetInstanceID           string
 	TokenResponse                              string
 	Publisher                                  string
 	Sku                                        string
@@ -118,6 +123,7 @@ type HTTPMockClient struct {
 	ResponseGetVirtualMachineImage             string
 	ResponseListVirtualMachineImages           string
 	ResponseListResourceSkus                   string
+	ResponseRunCommand                         string
 	mux                                        *http.ServeMux
 	server                                     *testserver.TestServer
 }
@@ -168,6 +174,8 @@ func NewHTTPMockClient() (HTTPMockClient, error) {
 		VirutalDiskName:                     virutalDiskName,
 		Location:                            location,
 		OperationID:                         operationID,
+		RunCommandOperationID:               runCommandOperationID,
+		VirtualMachineScaleSetInstanceID:    virtualMachineScaleSetInstanceID,
 		Publisher:                           publisher,
 		Offer:                               offer,
 		Sku:                                 sku,
@@ -253,6 +261,11 @@ func NewHTTPMockClient() (HTTPMockClient, error) {
 		return client, err
 	}
 
+	client.ResponseRunCommand, err = readFromFile(filePathRunCommand)
+	if err != nil {
+		return client, err
+	}
+
 	return client, nil
 }
 
@@ -440,6 +453,42 @@ func (mc HTTPMockClient) RegisterDeleteOperation() {
 	})
 }
 
+// RegisterRunCommand registers mock responses for the runCommand action of the virtual machine and of the VMSS VM,
+// and for checking the status of the run command operation
+func (mc *HTTPMockClient) RegisterRunCommand() {
+	runCommand := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api-version") != mc.ComputeAPIVersion {
+			w.WriteHeader(http.StatusNotFound)
+		} else if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		} else {
+			w.Header().Add("Azure-Asyncoperation", fmt.Sprintf("http://localhost:%d/subscriptions/%s/providers/Microsoft.Compute/locations/%s/operations/%s?api-version=%s", mc.server.Port, mc.SubscriptionID, mc.Location, mc.RunCommandOperationID, mc.ComputeAPIVersion))
+			w.Header().Add("Location", fmt.Sprintf("http://localhost:%d/subscriptions/%s/providers/Microsoft.Compute/locations/%s/operations/%s?monitor=true&api-version=%s", mc.server.Port, mc.SubscriptionID, mc.Location, mc.RunCommandOperationID, mc.ComputeAPIVersion))
+			w.Header().Add("Content-Length", "0")
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}
+	mc.mux.HandleFunc(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachines/%s/runCommand", mc.SubscriptionID, mc.ResourceGroup, mc.VirtualMachineName), runCommand)
+	mc.mux.HandleFunc(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/%s/virtualmachines/%s/runCommand", mc.SubscriptionID, mc.ResourceGroup, mc.VirtualMachineScaleSetName, mc.VirtualMachineScaleSetInstanceID), runCommand)
+
+	pattern := fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Compute/locations/%s/operations/%s", mc.SubscriptionID, mc.Location, mc.RunCommandOperationID)
+	mc.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api-version") != mc.ComputeAPIVersion {
+			w.WriteHeader(http.StatusNotFound)
+		} else if r.URL.Query().Get("monitor") == "true" {
+			_, _ = fmt.Fprint(w, mc.ResponseRunCommand)
+		} else {
+			_, _ = fmt.Fprintf(w, `
+			{
+			  "startTime": "2019-03-30T00:23:10.9206154+00:00",
+			  "endTime": "2019-03-30T00:23:51.8424926+00:00",
+			  "status": "Succeeded",
+			  "name": "%s"
+			}`, mc.RunCommandOperationID)
+		}
+	})
+}
+
 // RegisterDeployTemplate registers the mock response for DeployTemplate
 func (mc *HTTPMockClient) RegisterDeployTemplate() {
 	pattern := fmt.Sprintf("/subscriptions/%s/resourcegroups/%s/providers/Microsoft.Resources/deployments/%s", mc.SubscriptionID, mc.ResourceGroup, mc.DeploymentName)
@@ -461,6 +510,22 @@ func (mc *HTTPMockClient) RegisterDeployTemplate() {
 	})
 }
 
+// RegisterWhatIfDeployment registers the mock responses for WhatIfDeployment, the what-if operation completes on the first poll
+func (mc *HTTPMockClient) RegisterWhatIfDeployment(result string) {
+	pattern := fmt.Sprintf("/subscriptions/%s/resourcegroups/%s/providers/Microsoft.Resources/deployments/%s/whatIf", mc.SubscriptionID, mc.ResourceGroup, mc.DeploymentName)
+	mc.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api-version") != whatIfAPIVersion || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.Header().Add("Location", fmt.Sprintf("http://localhost:%d/subscriptions/%s/resourcegroups/%s/providers/Microsoft.Resources/deployments/%s/whatIfResult?api-version=%s", mc.server.Port, mc.SubscriptionID, mc.ResourceGroup, mc.DeploymentName, whatIfAPIVersion))
+			w.WriteHeader(http.StatusAccepted)
+		}
+	})
+	mc.mux.HandleFunc(pattern+"Result", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, result)
+	})
+}
+
 // RegisterDeployOperationSuccess registers the mock response for a successful deployment
 func (mc HTTPMockClient) RegisterDeployOperationSuccess() {
 	pattern := fmt.Sprintf("/subscriptions/%s/resourcegroups/%s/providers/Microsoft.Resources/deployments/%s/operationStatuses/%s", mc.SubscriptionID, mc.ResourceGroup, mc.DeploymentName, mc.DeploymentStatus)
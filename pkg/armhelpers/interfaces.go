@@ -114,9 +114,15 @@ type AKSEngineClient interface {
 	// DeployTemplate can deploy a template into Azure ARM
 	DeployTemplate(ctx context.Context, resourceGroup, name string, template, parameters map[string]interface{}) (resources.DeploymentExtended, error)
 
+	// WhatIfDeployment returns the changes that deploying a template into Azure ARM would make, without deploying it
+	WhatIfDeployment(ctx context.Context, resourceGroup, name string, template, parameters map[string]interface{}) (WhatIfResult, error)
+
 	// EnsureResourceGroup ensures the specified resource group exists in the specified location
 	EnsureResourceGroup(ctx context.Context, resourceGroup, location string, managedBy *string) (*resources.Group, error)
 
+	// CheckResourceGroupExistence returns the response of the existence check of the resource group, 204 if it exists and 404 if it doesn't
+	CheckResourceGroupExistence(ctx context.Context, resourceGroup string) (autorest.Response, error)
+
 	// ListLocations returns all the Azure locations to which AKS Engine can deploy
 	ListLocations(ctx context.Context) (*[]subscriptions.Location, error)
 
@@ -150,12 +156,18 @@ type AKSEngineClient interface {
 	// DeleteVirtualMachineScaleSetVM deletes a VM in a VMSS
 	DeleteVirtualMachineScaleSetVM(ctx context.Context, resourceGroup, virtualMachineScaleSet, instanceID string) error
 
+	// DeleteVirtualMachineScaleSet deletes a VMSS and its VMs
+	DeleteVirtualMachineScaleSet(ctx context.Context, resourceGroup, vmssName string) error
+
 	// SetVirtualMachineScaleSetCapacity sets the VMSS capacity
 	SetVirtualMachineScaleSetCapacity(ctx context.Context, resourceGroup, virtualMachineScaleSet string, sku compute.Sku, location string) error
 
 	// GetAvailabilitySet retrieves the specified VM availability set.
 	GetAvailabilitySet(ctx context.Context, resourceGroup, availabilitySet string) (compute.AvailabilitySet, error)
 
+	// DeleteAvailabilitySet deletes the specified VM availability set.
+	DeleteAvailabilitySet(ctx context.Context, resourceGroup, availabilitySet string) error
+
 	// GetAvailabilitySetFaultDomainCount returns the first platform fault domain count it finds from the
 	// VM availability set IDs provided.
 	GetAvailabilitySetFaultDomainCount(ctx context.Context, resourceGroup string, vmasIDs []string) (int, error)
@@ -166,6 +178,12 @@ type AKSEngineClient interface {
 	// GetVirtualMachineScaleSetInstancePowerState returns the virtual machine's PowerState status code
 	GetVirtualMachineScaleSetInstancePowerState(ctx context.Context, resourceGroup, name, instanceID string) (string, error)
 
+	// RunVirtualMachineCommand runs a command on the specified virtual machine through the Run Command API
+	RunVirtualMachineCommand(ctx context.Context, resourceGroup, name string, input compute.RunCommandInput) (compute.RunCommandResult, error)
+
+	// RunVirtualMachineScaleSetVMCommand runs a command on the specified VMSS VM through the Run Command API
+	RunVirtualMachineScaleSetVMCommand(ctx context.Context, resourceGroup, virtualMachineScaleSet, instanceID string, input compute.RunCommandInput) (compute.RunCommandResult, error)
+
 	//
 	// STORAGE
 
@@ -179,6 +197,9 @@ type AKSEngineClient interface {
 	// DeleteNetworkInterface deletes the specified network interface.
 	DeleteNetworkInterface(ctx context.Context, resourceGroup, nicName string) error
 
+	// DeleteNetworkSecurityGroup deletes the specified network security group.
+	DeleteNetworkSecurityGroup(ctx context.Context, resourceGroup, nsgName string) error
+
 	//
 	// GRAPH
 
@@ -50,6 +50,8 @@ type MockAKSEngineClient struct {
 	FailDeployTemplateConflict              bool
 	FailDeployTemplateWithProperties        bool
 	FailEnsureResourceGroup                 bool
+	FailCheckResourceGroupExistence         bool
+	ResourceGroupNotFound                   bool
 	FailListVirtualMachines                 bool
 	FailListVirtualMachinesTags             bool
 	FailListVirtualMachineScaleSets         bool
@@ -58,10 +60,13 @@ type MockAKSEngineClient struct {
 	FailRestartVirtualMachine               bool
 	FailDeleteVirtualMachine                bool
 	FailDeleteVirtualMachineScaleSetVM      bool
+	FailDeleteVirtualMachineScaleSet        bool
+	FailDeleteAvailabilitySet               bool
 	FailSetVirtualMachineScaleSetCapacity   bool
 	FailListVirtualMachineScaleSetVMs       bool
 	FailGetStorageClient                    bool
 	FailDeleteNetworkInterface              bool
+	FailDeleteNetworkSecurityGroup          bool
 	FailGetKubernetesClient                 bool
 	FailListProviders                       bool
 	ShouldSupportVMIdentity                 bool
@@ -69,10 +74,14 @@ type MockAKSEngineClient struct {
 	FailEnsureDefaultLogAnalyticsWorkspace  bool
 	FailAddContainerInsightsSolution        bool
 	FailGetLogAnalyticsWorkspaceInfo        bool
+	FailRunCommand                          bool
+	FailWhatIfDeployment                    bool
 	MockKubernetesClient                    *MockKubernetesClient
 	FakeListVirtualMachineScaleSetsResult   func() []compute.VirtualMachineScaleSet
 	FakeListVirtualMachineResult            func() []compute.VirtualMachine
 	FakeListVirtualMachineScaleSetVMsResult func() []compute.VirtualMachineScaleSetVM
+	FakeRunCommandResult                    func(input compute.RunCommandInput) compute.RunCommandResult
+	FakeWhatIfDeploymentResult              func(template map[string]interface{}) WhatIfResult
 }
 
 // MockStorageClient mock implementation of StorageClient
@@ -516,6 +525,17 @@ func (mc *MockAKSEngineClient) AddAcceptLanguages(languages []string) {}
 // AddAuxiliaryTokens mock
 func (mc *MockAKSEngineClient) AddAuxiliaryTokens(tokens []string) {}
 
+// WhatIfDeployment mock
+func (mc *MockAKSEngineClient) WhatIfDeployment(ctx context.Context, resourceGroup, name string, template, parameters map[string]interface{}) (WhatIfResult, error) {
+	if mc.FailWhatIfDeployment {
+		return WhatIfResult{}, errors.New("WhatIfDeployment failed")
+	}
+	if mc.FakeWhatIfDeploymentResult != nil {
+		return mc.FakeWhatIfDeploymentResult(template), nil
+	}
+	return WhatIfResult{Status: "Succeeded"}, nil
+}
+
 // DeployTemplate mock
 func (mc *MockAKSEngineClient) DeployTemplate(ctx context.Context, resourceGroup, name string, template, parameters map[string]interface{}) (de resources.DeploymentExtended, err error) {
 	switch {
@@ -605,6 +625,18 @@ func (mc *MockAKSEngineClient) EnsureResourceGroup(ctx context.Context, resource
 	return nil, nil
 }
 
+// CheckResourceGroupExistence mock
+func (mc *MockAKSEngineClient) CheckResourceGroupExistence(ctx context.Context, resourceGroup string) (autorest.Response, error) {
+	if mc.FailCheckResourceGroupExistence {
+		return autorest.Response{}, errors.New("CheckResourceGroupExistence failed")
+	}
+	if mc.ResourceGroupNotFound {
+		return autorest.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}, nil
+	}
+
+	return autorest.Response{Response: &http.Response{StatusCode: http.StatusNoContent}}, nil
+}
+
 // ListResourceSkus mock
 func (mc *MockAKSEngineClient) ListResourceSkus(ctx context.Context, filter string) (ResourceSkusResultPage, error) {
 	return nil, nil
@@ -817,6 +849,15 @@ func (mc *MockAKSEngineClient) DeleteVirtualMachineScaleSetVM(ctx context.Contex
 	return nil
 }
 
+// DeleteVirtualMachineScaleSet mock
+func (mc *MockAKSEngineClient) DeleteVirtualMachineScaleSet(ctx context.Context, resourceGroup, vmssName string) error {
+	if mc.FailDeleteVirtualMachineScaleSet {
+		return errors.New("DeleteVirtualMachineScaleSet failed")
+	}
+
+	return nil
+}
+
 // SetVirtualMachineScaleSetCapacity mock
 func (mc *MockAKSEngineClient) SetVirtualMachineScaleSetCapacity(ctx context.Context, resourceGroup, virtualMachineScaleSet string, sku compute.Sku, location string) error {
 	if mc.FailSetVirtualMachineScaleSetCapacity {
@@ -856,6 +897,15 @@ func (mc *MockAKSEngineClient) GetAvailabilitySet(ctx context.Context, resourceG
 	return compute.AvailabilitySet{}, nil
 }
 
+// DeleteAvailabilitySet mock
+func (mc *MockAKSEngineClient) DeleteAvailabilitySet(ctx context.Context, resourceGroup, availabilitySetName string) error {
+	if mc.FailDeleteAvailabilitySet {
+		return errors.New("DeleteAvailabilitySet failed")
+	}
+
+	return nil
+}
+
 // GetAvailabilitySetFaultDomainCount mock
 func (mc *MockAKSEngineClient) GetAvailabilitySetFaultDomainCount(ctx context.Context, resourceGroup string, vmasIDs []string) (int, error) {
 	return 3, nil
@@ -879,6 +929,15 @@ func (mc *MockAKSEngineClient) DeleteNetworkInterface(ctx context.Context, resou
 	return nil
 }
 
+// DeleteNetworkSecurityGroup mock
+func (mc *MockAKSEngineClient) DeleteNetworkSecurityGroup(ctx context.Context, resourceGroup, nsgName string) error {
+	if mc.FailDeleteNetworkSecurityGroup {
+		return errors.New("DeleteNetworkSecurityGroup failed")
+	}
+
+	return nil
+}
+
 var validOSDiskResourceName = "https://00k71r4u927seqiagnt0.blob.core.windows.net/osdisk/k8s-agentpool1-12345678-0-osdisk.vhd"
 var validNicResourceName = "/subscriptions/DEC923E3-1EF1-4745-9516-37906D56DEC4/resourceGroups/acsK8sTest/providers/Microsoft.Network/networkInterfaces/k8s-agent-12345678-nic-0"
 
@@ -1090,3 +1149,28 @@ func (mc *MockAKSEngineClient) GetVirtualMachinePowerState(ctx context.Context,
 func (mc *MockAKSEngineClient) GetVirtualMachineScaleSetInstancePowerState(ctx context.Context, resourceGroup, name, instanceID string) (string, error) {
 	return "", nil
 }
+
+// RunVirtualMachineCommand mock
+func (mc *MockAKSEngineClient) RunVirtualMachineCommand(ctx context.Context, resourceGroup, name string, input compute.RunCommandInput) (compute.RunCommandResult, error) {
+	return mc.runCommand(input)
+}
+
+// RunVirtualMachineScaleSetVMCommand mock
+func (mc *MockAKSEngineClient) RunVirtualMachineScaleSetVMCommand(ctx context.Context, resourceGroup, virtualMachineScaleSet, instanceID string, input compute.RunCommandInput) (compute.RunCommandResult, error) {
+	return mc.runCommand(input)
+}
+
+func (mc *MockAKSEngineClient) runCommand(input compute.RunCommandInput) (compute.RunCommandResult, error) {
+	if mc.FailRunCommand {
+		return compute.RunCommandResult{}, errors.New("RunCommand failed")
+	}
+	if mc.FakeRunCommandResult != nil {
+		return mc.FakeRunCommandResult(input), nil
+	}
+	return compute.RunCommandResult{
+		Value: &[]compute.InstanceViewStatus{
+			{Code: to.StringPtr("ComponentStatus/StdOut/succeeded"), Message: to.StringPtr("")},
+			{Code: to.StringPtr("ComponentStatus/StdErr/succeeded"), Message: to.StringPtr("")},
+		},
+	}, nil
+}
@@ -20,24 +20,24 @@ type validationResult struct {
 // ValidateRequiredImages checks that the OS images required by both
 // master and agent pools are available on the target cloud
 func ValidateRequiredImages(ctx context.Context, location string, p *api.Properties, client AKSEngineClient) error {
-	if fetcher, ok := client.(VMImageFetcher); ok {
-		missingImages := make(map[api.Distro]validationResult)
-		for distro, i := range requiredImages(p) {
+	if fetcher, ok := AsVMImageFetcher(client); ok {
+		var missingImages []validationResult
+		for _, i := range requiredImages(p) {
 			log.Debugln(fmt.Sprintf("Validate OS image is available on the target cloud: %s, %s, %s, %s", i.ImagePublisher, i.ImageOffer, i.ImageSku, i.ImageVersion))
 			if i.ImageVersion == "latest" {
 				list, err := fetcher.ListVirtualMachineImages(ctx, location, i.ImagePublisher, i.ImageOffer, i.ImageSku)
 				if err != nil || len(*list.Value) == 0 {
-					missingImages[distro] = validationResult{
+					missingImages = append(missingImages, validationResult{
 						image:     i,
 						errorData: err,
-					}
+					})
 				}
 			} else {
 				if _, err := fetcher.GetVirtualMachineImage(ctx, location, i.ImagePublisher, i.ImageOffer, i.ImageSku, i.ImageVersion); err != nil {
-					missingImages[distro] = validationResult{
+					missingImages = append(missingImages, validationResult{
 						image:     i,
 						errorData: err,
-					}
+					})
 				}
 			}
 		}
@@ -49,20 +49,32 @@ func ValidateRequiredImages(ctx context.Context, location string, p *api.Propert
 	return errors.New("parameter client is not a VMImageFetcher")
 }
 
-func requiredImages(p *api.Properties) map[api.Distro]api.AzureOSImageConfig {
-	images := make(map[api.Distro]api.AzureOSImageConfig)
-	images[p.MasterProfile.Distro] = toImageConfig(p.MasterProfile.Distro)
+// requiredImages returns the distinct OS images of the master and agent pools,
+// Windows agent pools are resolved separately as they can override the image of the WindowsProfile
+func requiredImages(p *api.Properties) []api.AzureOSImageConfig {
+	var images []api.AzureOSImageConfig
+	seen := make(map[api.AzureOSImageConfig]bool)
+	add := func(i api.AzureOSImageConfig) {
+		if !seen[i] {
+			seen[i] = true
+			images = append(images, i)
+		}
+	}
+	add(toImageConfig(p.MasterProfile.Distro))
 	for _, app := range p.AgentPoolProfiles {
 		if app.OSType == api.Windows {
-			images[app.Distro] = toImageConfigWindows(p.WindowsProfile)
+			if app.HasImageRef() {
+				continue
+			}
+			add(toImageConfigWindows(app, p.WindowsProfile))
 		} else {
-			images[app.Distro] = toImageConfig(app.Distro)
+			add(toImageConfigLinux(app))
 		}
 	}
 	return images
 }
 
-func printErrorIfAny(missingImages map[api.Distro]validationResult) error {
+func printErrorIfAny(missingImages []validationResult) error {
 	for _, value := range missingImages {
 		i := value.image
 		log.Errorf("error: %+v", value.errorData)
@@ -86,6 +98,10 @@ func toImageConfig(distro api.Distro) api.AzureOSImageConfig {
 		return api.Ubuntu2004OSImageConfig
 	case api.Ubuntu2004Gen2:
 		return api.Ubuntu2004Gen2OSImageConfig
+	case api.Ubuntu2204:
+		return api.Ubuntu2204OSImageConfig
+	case api.Ubuntu2204Gen2:
+		return api.Ubuntu2204Gen2OSImageConfig
 	case api.Flatcar:
 		return api.FlatcarImageConfig
 	case api.AKSUbuntu1604:
@@ -101,7 +117,18 @@ func toImageConfig(distro api.Distro) api.AzureOSImageConfig {
 	}
 }
 
-func toImageConfigWindows(profile *api.WindowsProfile) api.AzureOSImageConfig {
+// toImageConfigLinux returns the Gen2 or Arm64 flavor of the pool distro image when the pool requires it
+func toImageConfigLinux(pool *api.AgentPoolProfile) api.AzureOSImageConfig {
+	if imageConfig, ok := pool.GetOSImageFlavor(); ok {
+		return imageConfig
+	}
+	return toImageConfig(pool.Distro)
+}
+
+func toImageConfigWindows(pool *api.AgentPoolProfile, profile *api.WindowsProfile) api.AzureOSImageConfig {
+	if pool != nil && pool.HasWindowsImageOverride() {
+		return pool.GetWindowsImageConfig(profile)
+	}
 	if profile != nil {
 		return api.AzureOSImageConfig{
 			ImageOffer:     profile.WindowsOffer,
@@ -120,7 +120,7 @@ func TestToImageConfigWindows(t *testing.T) {
 		WindowsOffer:     api.WindowsServer2019OSImageConfig.ImageOffer,
 		WindowsSku:       api.WindowsServer2019OSImageConfig.ImageSku,
 	}
-	imageConfig := toImageConfigWindows(&windowsProfile)
+	imageConfig := toImageConfigWindows(nil, &windowsProfile)
 
 	if imageConfig.ImageOffer != api.WindowsServer2019OSImageConfig.ImageOffer {
 		t.Fatal("could not fetch windows profile as image config WindowsServer2019OSImageConfig")
@@ -131,10 +131,85 @@ func TestToImageConfigWindows(t *testing.T) {
 		WindowsOffer:     api.AKSWindowsServer2019OSImageConfig.ImageOffer,
 		WindowsSku:       api.AKSWindowsServer2019OSImageConfig.ImageSku,
 	}
-	imageConfig = toImageConfigWindows(&windowsProfile)
+	imageConfig = toImageConfigWindows(nil, &windowsProfile)
 
 	if imageConfig.ImageOffer != api.AKSWindowsServer2019OSImageConfig.ImageOffer {
 		t.Fatal("could not fetch windows profile as image config AKSWindowsServer2019OSImageConfig")
 	}
 
+	pool := api.AgentPoolProfile{
+		Name:                "win2022",
+		OSType:              api.Windows,
+		WindowsSku:          "2022-datacenter-core-smalldisk",
+		WindowsImageVersion: "latest",
+	}
+	imageConfig = toImageConfigWindows(&pool, &windowsProfile)
+
+	expected := api.AzureOSImageConfig{
+		ImagePublisher: api.AKSWindowsServer2019OSImageConfig.ImagePublisher,
+		ImageOffer:     api.AKSWindowsServer2019OSImageConfig.ImageOffer,
+		ImageSku:       "2022-datacenter-core-smalldisk",
+		ImageVersion:   "latest",
+	}
+	if imageConfig != expected {
+		t.Fatalf("expected image config of agent pool %s to be %+v, got %+v", pool.Name, expected, imageConfig)
+	}
+}
+
+func TestRequiredImagesPerWindowsPool(t *testing.T) {
+	p := &api.Properties{
+		MasterProfile: &api.MasterProfile{Distro: api.Ubuntu1804},
+		WindowsProfile: &api.WindowsProfile{
+			WindowsPublisher: api.AKSWindowsServer2019OSImageConfig.ImagePublisher,
+			WindowsOffer:     api.AKSWindowsServer2019OSImageConfig.ImageOffer,
+			WindowsSku:       api.AKSWindowsServer2019OSImageConfig.ImageSku,
+			ImageVersion:     api.AKSWindowsServer2019OSImageConfig.ImageVersion,
+		},
+		AgentPoolProfiles: []*api.AgentPoolProfile{
+			{Name: "linuxpool", Distro: api.Ubuntu1804},
+			{Name: "win2019", OSType: api.Windows},
+			{Name: "win2022", OSType: api.Windows, WindowsSku: "2022-datacenter-core-smalldisk", WindowsImageVersion: "latest"},
+			{Name: "wincustom", OSType: api.Windows, ImageRef: &api.ImageReference{Name: "image", ResourceGroup: "rg"}},
+		},
+	}
+
+	images := requiredImages(p)
+	if len(images) != 3 {
+		t.Fatalf("expected 3 required images, got %d: %+v", len(images), images)
+	}
+	if images[1] != api.AKSWindowsServer2019OSImageConfig {
+		t.Fatalf("expected the image of the WindowsProfile for pool win2019, got %+v", images[1])
+	}
+	if images[2].ImageSku != "2022-datacenter-core-smalldisk" || images[2].ImageVersion != "latest" {
+		t.Fatalf("expected the image of pool win2022, got %+v", images[2])
+	}
+}
+
+func TestRequiredImagesPerLinuxPool(t *testing.T) {
+	p := &api.Properties{
+		MasterProfile: &api.MasterProfile{Distro: api.Ubuntu2004},
+		AgentPoolProfiles: []*api.AgentPoolProfile{
+			{Name: "gen1", Distro: api.Ubuntu2204},
+			{Name: "gen2", Distro: api.Ubuntu2204, HyperVGeneration: api.HyperVGenerationV2},
+			{Name: "arm64", Distro: api.Ubuntu2204, Architecture: api.ArchitectureArm64},
+			{Name: "flatcar", Distro: api.Flatcar, HyperVGeneration: api.HyperVGenerationV2},
+		},
+	}
+
+	images := requiredImages(p)
+	expected := []api.AzureOSImageConfig{
+		api.Ubuntu2004OSImageConfig,
+		api.Ubuntu2204OSImageConfig,
+		api.Ubuntu2204Gen2OSImageConfig,
+		api.Ubuntu2204Arm64OSImageConfig,
+		api.FlatcarGen2ImageConfig,
+	}
+	if len(images) != len(expected) {
+		t.Fatalf("expected %d required images, got %d: %+v", len(expected), len(images), images)
+	}
+	for i := range expected {
+		if images[i] != expected[i] {
+			t.Errorf("expected required image %d to be %+v, got %+v", i, expected[i], images[i])
+		}
+	}
 }
@@ -0,0 +1,125 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cluster
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/engine/transform"
+	"github.com/Azure/aks-engine/pkg/helpers"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+)
+
+// AddPoolRequest is a request to add a node pool to a cluster
+type AddPoolRequest struct {
+	Options
+	// ContainerService is the api model of the cluster, loaded with its defaults.
+	// AddPool replaces its agent pool profiles by the new pool to generate the template of the pool
+	ContainerService *api.ContainerService
+	// ResourceGroup is the resource group of the cluster
+	ResourceGroup string
+	// AgentPool is the profile of the new node pool, AddPool sets the VMSSName of a VMSS pool
+	AgentPool *api.AgentPoolProfile
+}
+
+// AddPoolResponse is the result of adding a node pool
+type AddPoolResponse struct {
+	// DeploymentName is the name of the ARM deployment of the node pool template
+	DeploymentName string
+	// NodesAdded are the names of the VMs deployed for an availability set node pool
+	NodesAdded []string
+}
+
+// AddPool deploys a new node pool in a cluster. The caller adds the pool to the agent pool profiles of its api model
+func AddPool(ctx context.Context, req *AddPoolRequest) (*AddPoolResponse, error) {
+	op, err := newOperation(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	cs, pool := req.ContainerService, req.AgentPool
+	if cs == nil || pool == nil {
+		return nil, errors.New("the api model of the cluster and the profile of the new node pool are required")
+	}
+
+	for _, p := range cs.Properties.AgentPoolProfiles {
+		if strings.EqualFold(p.Name, pool.Name) {
+			return nil, errors.Errorf("node pool %s already exists", p.Name)
+		}
+		if !strings.EqualFold(p.AvailabilityProfile, pool.AvailabilityProfile) {
+			return nil, errors.New("mixed mode availability profiles are not allowed, all node pools should have the same availabilityProfile")
+		}
+	}
+
+	// Assign VMSSName property based on the new pool being added to the end of the existing AgentPoolProfiles array
+	if pool.IsVirtualMachineScaleSets() {
+		numExistingPools := len(cs.Properties.AgentPoolProfiles)
+		// we can reuse the value of numExistingPools due to array index beginning at "0"
+		pool.VMSSName = cs.Properties.GetAgentVMPrefix(pool, numExistingPools)
+		if pool.VMSSName == "" {
+			return nil, errors.Errorf("unable to compute a VMSSName property value from new pool definition")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, armhelpers.DefaultARMOperationTimeout)
+	defer cancel()
+
+	if pool.IsVirtualMachineScaleSets() {
+		for vmssListPage, err := op.client.ListVirtualMachineScaleSets(ctx, req.ResourceGroup); vmssListPage.NotDone(); err = vmssListPage.NextWithContext(ctx) {
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get VMSS list in the resource group")
+			}
+			for _, vmss := range vmssListPage.Values() {
+				if pool.VMSSName == to.String(vmss.Name) {
+					return nil, errors.New("A VMSS node pool with the given name already exists in the cluster")
+				}
+			}
+		}
+	}
+
+	op.warnSubnetNetworkSecurityGroup(cs, pool)
+	cs.Properties.AgentPoolProfiles = []*api.AgentPoolProfile{pool}
+
+	_, err = cs.SetPropertiesDefaults(api.PropertiesDefaultsParams{
+		IsScale:    true,
+		IsUpgrade:  false,
+		PkiKeySize: helpers.DefaultPkiKeySize,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error in SetPropertiesDefaults")
+	}
+	templateJSON, parametersJSON, err := op.generateTemplateJSON(cs)
+	if err != nil {
+		return nil, err
+	}
+
+	transformer := transform.Transformer{Translator: op.translator}
+	if cs.Properties.OrchestratorProfile.KubernetesConfig.LoadBalancerSku == api.StandardLoadBalancerSku {
+		if err = transformer.NormalizeForK8sSLBScalingOrUpgrade(op.logger, templateJSON); err != nil {
+			return nil, errors.Wrap(err, "error transforming the template for scaling with SLB")
+		}
+	}
+	if pool.IsVirtualMachineScaleSets() {
+		if err = transformer.NormalizeForK8sVMASScalingUp(op.logger, templateJSON); err != nil {
+			return nil, errors.Wrap(err, "error transforming the template for scaling template")
+		}
+		addValue(parametersJSON, pool.Name+"Count", 0)
+	} else {
+		if err = transformer.NormalizeForK8sAddVMASPool(op.logger, templateJSON); err != nil {
+			return nil, errors.Wrap(err, "error transforming the template to add a VMAS node pool")
+		}
+	}
+
+	resp := &AddPoolResponse{DeploymentName: newDeploymentName(req.ResourceGroup)}
+	if err = op.deploy(ctx, req.ResourceGroup, resp.DeploymentName, templateJSON, parametersJSON); err != nil || op.whatIf {
+		return resp, err
+	}
+	if pool.IsAvailabilitySets() {
+		resp.NodesAdded = op.deployedVMNames(ctx, req.ResourceGroup, resp.DeploymentName)
+	}
+	return resp, nil
+}
@@ -0,0 +1,87 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+)
+
+func TestAddPool(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	addPool := func(client armhelpers.AKSEngineClient, cs *api.ContainerService, pool *api.AgentPoolProfile) (*AddPoolResponse, error) {
+		return AddPool(context.Background(), &AddPoolRequest{
+			Options:          Options{Client: client},
+			ContainerService: cs,
+			ResourceGroup:    "rg1",
+			AgentPool:        pool,
+		})
+	}
+
+	cs := loadContainerService(t)
+	resp, err := addPool(&armhelpers.MockAKSEngineClient{}, cs, &api.AgentPoolProfile{
+		Name:                "agentpool3",
+		Count:               2,
+		VMSize:              "Standard_D2_v2",
+		AvailabilityProfile: api.AvailabilitySet,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(resp.DeploymentName).To(HavePrefix("rg1-"))
+	g.Expect(cs.Properties.AgentPoolProfiles).To(HaveLen(1))
+	g.Expect(cs.Properties.AgentPoolProfiles[0].Name).To(Equal("agentpool3"))
+
+	cs = loadContainerService(t)
+	cs.Properties.AgentPoolProfiles = nil
+	_, err = addPool(&armhelpers.MockAKSEngineClient{}, cs, &api.AgentPoolProfile{
+		Name:                "agentpool3",
+		Count:               2,
+		VMSize:              "Standard_D2_v2",
+		AvailabilityProfile: api.VirtualMachineScaleSets,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cs.Properties.AgentPoolProfiles).To(HaveLen(1))
+
+	_, err = addPool(&armhelpers.MockAKSEngineClient{}, loadContainerService(t), &api.AgentPoolProfile{
+		Name:                "AgentPool2",
+		AvailabilityProfile: api.AvailabilitySet,
+	})
+	g.Expect(err).To(MatchError("node pool agentpool2 already exists"))
+
+	_, err = addPool(&armhelpers.MockAKSEngineClient{}, loadContainerService(t), &api.AgentPoolProfile{
+		Name:                "agentpool3",
+		AvailabilityProfile: api.VirtualMachineScaleSets,
+	})
+	g.Expect(err).To(MatchError("mixed mode availability profiles are not allowed, all node pools should have the same availabilityProfile"))
+
+	cs = loadContainerService(t)
+	for _, p := range cs.Properties.AgentPoolProfiles {
+		p.AvailabilityProfile = api.VirtualMachineScaleSets
+	}
+	pool := &api.AgentPoolProfile{
+		Name:                "agentpool3",
+		Count:               2,
+		VMSize:              "Standard_D2_v2",
+		AvailabilityProfile: api.VirtualMachineScaleSets,
+	}
+	vmssName := cs.Properties.GetAgentVMPrefix(pool, len(cs.Properties.AgentPoolProfiles))
+	client := &armhelpers.MockAKSEngineClient{
+		FakeListVirtualMachineScaleSetsResult: func() []compute.VirtualMachineScaleSet {
+			return []compute.VirtualMachineScaleSet{{Name: to.StringPtr(vmssName)}}
+		},
+	}
+	_, err = addPool(client, cs, pool)
+	g.Expect(err).To(MatchError("A VMSS node pool with the given name already exists in the cluster"))
+	g.Expect(pool.VMSSName).To(Equal(vmssName))
+
+	_, err = addPool(&armhelpers.MockAKSEngineClient{}, nil, pool)
+	g.Expect(err).To(MatchError("the api model of the cluster and the profile of the new node pool are required"))
+}
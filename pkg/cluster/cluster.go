@@ -0,0 +1,228 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/engine"
+	"github.com/Azure/aks-engine/pkg/engine/transform"
+	"github.com/Azure/aks-engine/pkg/i18n"
+	"github.com/Azure/aks-engine/pkg/kubernetes"
+	"github.com/Azure/aks-engine/pkg/operations"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Options are the dependencies and the settings shared by the requests of the package
+type Options struct {
+	// Client is the client of the Azure APIs, it is required
+	Client armhelpers.AKSEngineClient
+	// KubernetesClient is the client of the apiserver of the cluster, Client creates one from the kubeconfig of the api model if nil
+	KubernetesClient kubernetes.Client
+	// Logger is the standard logger of logrus if nil
+	Logger *log.Entry
+	// Translator translates the messages of the api model errors, they are in English if nil
+	Translator *i18n.Translator
+	// Output is where the reports, like the nodes of a pool and the previews of WhatIf, are printed, stdout if nil
+	Output io.Writer
+	// BuildTag is the version of aks-engine written in the generated templates
+	BuildTag string
+	// WhatIf previews the changes the ARM deployments would make, and the nodes a scale down would remove, instead of making them
+	WhatIf bool
+}
+
+// operation holds the dependencies of an operation, the unset options replaced by their defaults
+type operation struct {
+	client     armhelpers.AKSEngineClient
+	logger     *log.Entry
+	translator *i18n.Translator
+	output     io.Writer
+	buildTag   string
+	whatIf     bool
+}
+
+func newOperation(o Options) (*operation, error) {
+	if o.Client == nil {
+		return nil, errors.New("a client of the Azure APIs is required")
+	}
+	op := &operation{
+		client:     o.Client,
+		logger:     o.Logger,
+		translator: o.Translator,
+		output:     o.Output,
+		buildTag:   o.BuildTag,
+		whatIf:     o.WhatIf,
+	}
+	if o.KubernetesClient != nil {
+		op.client = &kubernetesClientOverride{AKSEngineClient: o.Client, kubernetesClient: o.KubernetesClient}
+	}
+	if op.logger == nil {
+		op.logger = log.NewEntry(log.StandardLogger())
+	}
+	if op.translator == nil {
+		op.translator = &i18n.Translator{}
+	}
+	if op.output == nil {
+		op.output = os.Stdout
+	}
+	return op, nil
+}
+
+// kubernetesClientOverride is an AKSEngineClient that returns the Kubernetes client of the options instead of creating one
+type kubernetesClientOverride struct {
+	armhelpers.AKSEngineClient
+	kubernetesClient kubernetes.Client
+}
+
+// Unwrap returns the client of the options
+func (c *kubernetesClientOverride) Unwrap() armhelpers.AKSEngineClient {
+	return c.AKSEngineClient
+}
+
+// GetKubernetesClient returns the Kubernetes client of the options
+func (c *kubernetesClientOverride) GetKubernetesClient(apiserverURL, kubeConfig string, interval, timeout time.Duration) (kubernetes.Client, error) {
+	return c.kubernetesClient, nil
+}
+
+// warnSubnetNetworkSecurityGroup warns that the subnets of the cluster are left as they are for the network security group of pool.
+// Only deploy and upgrade detach the cluster network security group from the subnets, which holds for the clusters deployed
+// and upgraded before any node pool had networkSecurityRules, and the pool rules can't allow what the cluster rules block.
+func (op *operation) warnSubnetNetworkSecurityGroup(cs *api.ContainerService, pool *api.AgentPoolProfile) {
+	if !pool.HasNetworkSecurityGroup() || cs.Properties.MasterProfile == nil || cs.Properties.MasterProfile.IsCustomVNET() {
+		return
+	}
+	op.logger.Warnf("node pool %s has networkSecurityRules, if the cluster network security group is still attached to the subnets of the cluster "+
+		"its rules also apply to the nodes of the pool: run aks-engine upgrade to attach it to the network interfaces instead", pool.Name)
+}
+
+// generateTemplate generates the ARM template of the api model and its parameters
+func (op *operation) generateTemplate(cs *api.ContainerService) (string, string, error) {
+	templateGenerator, err := engine.InitializeTemplateGenerator(engine.Context{Translator: op.translator})
+	if err != nil {
+		return "", "", errors.Wrap(err, "initializing template generator")
+	}
+	template, parameters, err := templateGenerator.GenerateTemplateV2(cs, engine.DefaultGeneratorCode, op.buildTag)
+	if err != nil {
+		return "", "", errors.Wrap(err, "generating template")
+	}
+	if template, err = transform.PrettyPrintArmTemplate(template); err != nil {
+		return "", "", errors.Wrap(err, "pretty-printing template")
+	}
+	return template, parameters, nil
+}
+
+// generateTemplateJSON generates the ARM template of the api model and its parameters, unmarshaled to be transformed
+func (op *operation) generateTemplateJSON(cs *api.ContainerService) (map[string]interface{}, map[string]interface{}, error) {
+	template, parameters, err := op.generateTemplate(cs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return unmarshalTemplate(template, parameters)
+}
+
+func unmarshalTemplate(template, parameters string) (map[string]interface{}, map[string]interface{}, error) {
+	templateJSON := make(map[string]interface{})
+	parametersJSON := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(template), &templateJSON); err != nil {
+		return nil, nil, errors.Wrap(err, "unmarshaling template")
+	}
+	if err := json.Unmarshal([]byte(parameters), &parametersJSON); err != nil {
+		return nil, nil, errors.Wrap(err, "unmarshaling parameters")
+	}
+	return templateJSON, parametersJSON, nil
+}
+
+// deploy deploys the template, or previews the changes it would make with WhatIf
+func (op *operation) deploy(ctx context.Context, resourceGroup, deploymentName string, template, parameters map[string]interface{}) error {
+	if op.whatIf {
+		return op.previewDeployment(ctx, resourceGroup, deploymentName, template, parameters)
+	}
+	return armhelpers.DeployTemplateSyncWithContext(ctx, op.client, op.logger, resourceGroup, deploymentName, template, parameters)
+}
+
+// previewDeployment runs the what-if operation of the deployment and prints the changes it would make to the resource group.
+// The what-if operation needs an existing resource group, a missing one is reported as the change instead.
+func (op *operation) previewDeployment(ctx context.Context, resourceGroup, deploymentName string, template, parameters map[string]interface{}) error {
+	existence, err := op.client.CheckResourceGroupExistence(ctx, resourceGroup)
+	if err != nil {
+		return errors.Wrapf(err, "checking the existence of resource group %s", resourceGroup)
+	}
+	if existence.Response != nil && existence.StatusCode == http.StatusNotFound {
+		fmt.Fprintf(op.output, "Resource group %s does not exist, it would be created and deployment %s would create all its resources.\n", resourceGroup, deploymentName)
+		return nil
+	}
+	result, err := op.client.WhatIfDeployment(ctx, resourceGroup, deploymentName, template, parameters)
+	if err != nil {
+		return errors.Wrapf(err, "previewing deployment %s", deploymentName)
+	}
+	operations.PrintWhatIfResult(op.output, deploymentName, result)
+	return nil
+}
+
+// validateOSBaseImage checks if the OS image is available on the target cloud (ATM, Azure Stack only)
+func (op *operation) validateOSBaseImage(ctx context.Context, cs *api.ContainerService) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := armhelpers.ValidateRequiredImages(ctx, cs.Location, cs.Properties, op.client); err != nil {
+		return errors.Wrap(err, "OS base image not available in target cloud")
+	}
+	return nil
+}
+
+// newDeploymentName returns a random name for a deployment to the resource group
+func newDeploymentName(resourceGroup string) string {
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return fmt.Sprintf("%s-%d", resourceGroup, random.Int31())
+}
+
+type paramsMap map[string]interface{}
+
+func addValue(m paramsMap, k string, v interface{}) {
+	m[k] = paramsMap{
+		"value": v,
+	}
+}
+
+// vmNames returns the names of the VMs, not the VMSS VMs, of the IDs of deployed resources
+func vmNames(resourceIDs []string) []string {
+	const vmType = "/providers/Microsoft.Compute/virtualMachines/"
+	var names []string
+	for _, id := range resourceIDs {
+		if i := strings.Index(id, vmType); i >= 0 && !strings.Contains(id[i+len(vmType):], "/") {
+			names = append(names, id[i+len(vmType):])
+		}
+	}
+	return names
+}
+
+// deployedVMNames returns the names of the VMs a deployment created or updated
+func (op *operation) deployedVMNames(ctx context.Context, resourceGroup, deploymentName string) []string {
+	resourceIDs, err := armhelpers.ListDeployedResources(ctx, op.client, resourceGroup, deploymentName)
+	if err != nil {
+		op.logger.Warnf("unable to list the resources of deployment %s: %v", deploymentName, err)
+	}
+	return vmNames(resourceIDs)
+}
+
+// LogRequiredEgressFQDNs reminds the user of the destinations their firewall must allow
+// when cluster egress goes through a user-provided route table
+func LogRequiredEgressFQDNs(logger *log.Entry, cs *api.ContainerService) {
+	if !cs.Properties.IsUserDefinedRouting() {
+		return
+	}
+	logger.Warnf("outboundType is %s, the firewall behind route table %s must allow egress to: %s",
+		api.OutboundTypeUserDefinedRouting, cs.Properties.OrchestratorProfile.KubernetesConfig.RouteTableID,
+		strings.Join(cs.GetRequiredEgressFQDNs(), ", "))
+}
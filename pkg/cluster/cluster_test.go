@@ -0,0 +1,167 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/i18n"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+// loadContainerService loads the api model of a cluster of two availability set node pools in westus
+func loadContainerService(t *testing.T) *api.ContainerService {
+	t.Helper()
+	apiloader := &api.Apiloader{Translator: &i18n.Translator{}}
+	cs, _, err := apiloader.LoadContainerServiceFromFile("../engine/testdata/simple/kubernetes.json", true, false, nil)
+	if err != nil {
+		t.Fatalf("unexpected error loading the api model: %s", err)
+	}
+	cs.Location = "westus"
+	return cs
+}
+
+func TestNewOperation(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	_, err := newOperation(Options{})
+	g.Expect(err).To(MatchError("a client of the Azure APIs is required"))
+
+	client := &armhelpers.MockAKSEngineClient{}
+	op, err := newOperation(Options{Client: client})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(op.client).To(BeIdenticalTo(client))
+	g.Expect(op.logger).NotTo(BeNil())
+	g.Expect(op.translator).NotTo(BeNil())
+	g.Expect(op.output).NotTo(BeNil())
+
+	kubeClient := &armhelpers.MockKubernetesClient{}
+	op, err = newOperation(Options{Client: client, KubernetesClient: kubeClient})
+	g.Expect(err).NotTo(HaveOccurred())
+	k, err := op.client.GetKubernetesClient("https://apiserver", "kubeconfig", 0, 0)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(k).To(BeIdenticalTo(kubeClient))
+
+	// The other calls go to the client of the options
+	client.FailGetKubernetesClient = true
+	_, err = client.GetKubernetesClient("https://apiserver", "kubeconfig", 0, 0)
+	g.Expect(err).To(HaveOccurred())
+	_, err = op.client.GetKubernetesClient("https://apiserver", "kubeconfig", 0, 0)
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+// vmImageFetcherClient is a mock client of a cloud, like Azure Stack Hub, whose VM images are validated before they are used
+type vmImageFetcherClient struct {
+	*armhelpers.MockAKSEngineClient
+}
+
+func (c *vmImageFetcherClient) ListVirtualMachineImages(ctx context.Context, location, publisherName, offer, skus string) (compute.ListVirtualMachineImageResource, error) {
+	return compute.ListVirtualMachineImageResource{Value: &[]compute.VirtualMachineImageResource{{Name: to.StringPtr("1.0.0")}}}, nil
+}
+
+func (c *vmImageFetcherClient) GetVirtualMachineImage(ctx context.Context, location, publisherName, offer, skus, version string) (compute.VirtualMachineImage, error) {
+	return compute.VirtualMachineImage{}, nil
+}
+
+func TestValidateOSBaseImage(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	cs := loadContainerService(t)
+	op, err := newOperation(Options{
+		Client:           &vmImageFetcherClient{MockAKSEngineClient: &armhelpers.MockAKSEngineClient{}},
+		KubernetesClient: &armhelpers.MockKubernetesClient{},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(op.validateOSBaseImage(context.Background(), cs)).To(Succeed())
+
+	op, err = newOperation(Options{
+		Client:           &armhelpers.MockAKSEngineClient{},
+		KubernetesClient: &armhelpers.MockKubernetesClient{},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(op.validateOSBaseImage(context.Background(), cs)).NotTo(Succeed())
+}
+
+func TestPreviewDeployment(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	template := map[string]interface{}{"resources": []interface{}{}}
+	var previewed map[string]interface{}
+	client := &armhelpers.MockAKSEngineClient{
+		FailDeployTemplate: true,
+		FakeWhatIfDeploymentResult: func(template map[string]interface{}) armhelpers.WhatIfResult {
+			previewed = template
+			return armhelpers.WhatIfResult{Status: "Succeeded"}
+		},
+	}
+	var out bytes.Buffer
+	op, err := newOperation(Options{Client: client, Output: &out, WhatIf: true})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(op.deploy(context.Background(), "rg1", "deployment1", template, nil)).To(Succeed())
+	g.Expect(previewed).To(Equal(template))
+	g.Expect(out.String()).To(HavePrefix("Deployment deployment1 would make these resource changes"))
+
+	client.FailWhatIfDeployment = true
+	err = op.deploy(context.Background(), "rg1", "deployment1", template, nil)
+	g.Expect(err).To(MatchError("previewing deployment deployment1: WhatIfDeployment failed"))
+
+	out.Reset()
+	client.ResourceGroupNotFound = true
+	g.Expect(op.deploy(context.Background(), "rg1", "deployment1", template, nil)).To(Succeed())
+	g.Expect(out.String()).To(Equal("Resource group rg1 does not exist, it would be created and deployment deployment1 would create all its resources.\n"))
+
+	client.FailCheckResourceGroupExistence = true
+	err = op.deploy(context.Background(), "rg1", "deployment1", template, nil)
+	g.Expect(err).To(MatchError("checking the existence of resource group rg1: CheckResourceGroupExistence failed"))
+}
+
+func TestVMNames(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	g.Expect(vmNames([]string{
+		"/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Network/networkInterfaces/k8s-agentpool-12345678-nic-2",
+		"/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/k8s-agentpool-12345678-2",
+		"/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/k8s-agentpool-12345678-2/extensions/cse-agent-2",
+		"/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachineScaleSets/k8s-agentpool-12345678-vmss/virtualMachines/3",
+	})).To(Equal([]string{"k8s-agentpool-12345678-2"}))
+	g.Expect(vmNames(nil)).To(BeEmpty())
+}
+
+func TestWarnSubnetNetworkSecurityGroup(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	logger, hook := logtest.NewNullLogger()
+	op, err := newOperation(Options{Client: &armhelpers.MockAKSEngineClient{}, Logger: log.NewEntry(logger)})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cs := loadContainerService(t)
+	pool := cs.Properties.AgentPoolProfiles[0]
+	op.warnSubnetNetworkSecurityGroup(cs, pool)
+	g.Expect(hook.Entries).To(BeEmpty())
+
+	pool.NetworkSecurityRules = []api.NetworkSecurityRule{{Name: "allow_nodeports"}}
+	op.warnSubnetNetworkSecurityGroup(cs, pool)
+	g.Expect(hook.Entries).To(HaveLen(1))
+	g.Expect(hook.LastEntry().Level).To(Equal(log.WarnLevel))
+	g.Expect(hook.LastEntry().Message).To(HavePrefix("node pool agentpool1 has networkSecurityRules"))
+
+	// custom VNETs have the cluster network security group on the network interfaces already
+	cs.Properties.MasterProfile.VnetSubnetID = "/subscriptions/SUB_ID/resourceGroups/RG_NAME/providers/Microsoft.Network/virtualNetworks/VNET_NAME/subnets/SUBNET_NAME"
+	hook.Reset()
+	op.warnSubnetNetworkSecurityGroup(cs, pool)
+	g.Expect(hook.Entries).To(BeEmpty())
+}
@@ -0,0 +1,100 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/engine"
+	"github.com/Azure/aks-engine/pkg/engine/transform"
+	"github.com/Azure/aks-engine/pkg/helpers"
+	"github.com/pkg/errors"
+)
+
+// DeployRequest is a request to deploy a new cluster
+type DeployRequest struct {
+	Options
+	// ContainerService is the validated api model of the cluster, Deploy sets its defaults and generates its certificates
+	ContainerService *api.ContainerService
+	// APIVersion is the version of the api model written to OutputDirectory
+	APIVersion string
+	// ResourceGroup is the resource group to deploy to, it must exist
+	ResourceGroup string
+	// OutputDirectory is where the template, its parameters, the api model and the certificates are written.
+	// Nothing is written if it is empty, or with WhatIf
+	OutputDirectory string
+	// ParametersOnly only writes the parameters of the template to OutputDirectory
+	ParametersOnly bool
+}
+
+// DeployResponse is the result of a deployment
+type DeployResponse struct {
+	// DeploymentName is the name of the ARM deployment of the cluster template
+	DeploymentName string
+}
+
+// Deploy generates the ARM template of a cluster, writes its artifacts and deploys it
+func Deploy(ctx context.Context, req *DeployRequest) (*DeployResponse, error) {
+	op, err := newOperation(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	cs := req.ContainerService
+	if cs == nil {
+		return nil, errors.New("the api model of the cluster is required")
+	}
+
+	certsGenerated, err := cs.SetPropertiesDefaults(api.PropertiesDefaultsParams{
+		IsScale:    false,
+		IsUpgrade:  false,
+		PkiKeySize: helpers.DefaultPkiKeySize,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "in SetPropertiesDefaults")
+	}
+	LogRequiredEgressFQDNs(op.logger, cs)
+
+	if cs.Properties.IsAzureStackCloud() {
+		if err = op.validateOSBaseImage(ctx, cs); err != nil {
+			return nil, errors.Wrap(err, "validating OS base images")
+		}
+	}
+
+	if err = engine.ResolveCustomAddons(cs); err != nil {
+		return nil, errors.Wrap(err, "resolving custom addons")
+	}
+
+	template, parameters, err := op.generateTemplate(cs)
+	if err != nil {
+		return nil, err
+	}
+	var parametersFile string
+	if parametersFile, err = transform.BuildAzureParametersFile(parameters); err != nil {
+		return nil, errors.Wrap(err, "pretty-printing template parameters")
+	}
+
+	if op.whatIf {
+		if req.OutputDirectory != "" {
+			op.logger.Infoln("--what-if is set, the artifacts are not written to the output directory")
+		}
+	} else if req.OutputDirectory != "" {
+		writer := &engine.ArtifactWriter{Translator: op.translator}
+		if err = writer.WriteTLSArtifacts(cs, req.APIVersion, template, parametersFile, req.OutputDirectory, certsGenerated, req.ParametersOnly); err != nil {
+			return nil, errors.Wrap(err, "writing artifacts")
+		}
+	}
+
+	templateJSON, parametersJSON, err := unmarshalTemplate(template, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, armhelpers.DefaultARMOperationTimeout)
+	defer cancel()
+
+	resp := &DeployResponse{DeploymentName: newDeploymentName(req.ResourceGroup)}
+	return resp, op.deploy(ctx, req.ResourceGroup, resp.DeploymentName, templateJSON, parametersJSON)
+}
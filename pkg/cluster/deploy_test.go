@@ -0,0 +1,73 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	. "github.com/onsi/gomega"
+)
+
+func TestDeploy(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	outdir, err := ioutil.TempDir("", "cluster-deploy")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(outdir)
+
+	resp, err := Deploy(context.Background(), &DeployRequest{
+		Options:          Options{Client: &armhelpers.MockAKSEngineClient{}},
+		ContainerService: loadContainerService(t),
+		APIVersion:       "vlabs",
+		ResourceGroup:    "rg1",
+		OutputDirectory:  outdir,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(resp.DeploymentName).To(HavePrefix("rg1-"))
+	g.Expect(filepath.Join(outdir, "azuredeploy.json")).To(BeAnExistingFile())
+	g.Expect(filepath.Join(outdir, "apimodel.json")).To(BeAnExistingFile())
+
+	_, err = Deploy(context.Background(), &DeployRequest{
+		Options:          Options{Client: &armhelpers.MockAKSEngineClient{FailDeployTemplate: true}},
+		ContainerService: loadContainerService(t),
+		ResourceGroup:    "rg1",
+	})
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = Deploy(context.Background(), &DeployRequest{Options: Options{Client: &armhelpers.MockAKSEngineClient{}}})
+	g.Expect(err).To(MatchError("the api model of the cluster is required"))
+}
+
+func TestDeployWhatIf(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	outdir, err := ioutil.TempDir("", "cluster-deploy-what-if")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(outdir)
+
+	var out bytes.Buffer
+	resp, err := Deploy(context.Background(), &DeployRequest{
+		Options: Options{
+			Client: &armhelpers.MockAKSEngineClient{FailDeployTemplate: true},
+			Output: &out,
+			WhatIf: true,
+		},
+		ContainerService: loadContainerService(t),
+		ResourceGroup:    "rg1",
+		OutputDirectory:  outdir,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(out.String()).To(HavePrefix("Deployment " + resp.DeploymentName + " would make these resource changes"))
+	files, err := ioutil.ReadDir(outdir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(BeEmpty())
+}
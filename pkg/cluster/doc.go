@@ -0,0 +1,6 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+// Package cluster runs the operations of the deploy, scale, addpool, removepool and upgrade commands of aks-engine,
+// so that they can be embedded in Go programs.
+package cluster
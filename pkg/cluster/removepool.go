@@ -0,0 +1,269 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/api/common"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/operations"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+)
+
+// RemovePoolRequest is a request to remove a node pool from a cluster
+type RemovePoolRequest struct {
+	Options
+	// ContainerService is the api model of the cluster, loaded with its defaults
+	ContainerService *api.ContainerService
+	// SubscriptionID is the subscription of the cluster, the role assignments of the VMs are deleted in its scope
+	SubscriptionID string
+	// ResourceGroup is the resource group of the cluster
+	ResourceGroup string
+	// AgentPoolName is the name of the node pool to remove
+	AgentPoolName string
+	// APIServerURL is the URL of the apiserver, it is required to cordon and drain the nodes of the pool
+	APIServerURL string
+	// Force removes the last node pool of the cluster, or a node pool cluster-autoscaler scales
+	Force bool
+}
+
+// RemovePoolResponse is the result of removing a node pool
+type RemovePoolResponse struct {
+	// AgentPoolIndex is the index of the removed pool in the agent pool profiles of the api model
+	AgentPoolIndex int
+	// NodesRemoved are the names of the nodes whose VMs were deleted
+	NodesRemoved []string
+	// ClusterAutoscalerMode is the mode of the cluster-autoscaler addon if it scaled the removed pool, empty otherwise.
+	// The addon on the cluster keeps scaling the pool until it is applied again without it
+	ClusterAutoscalerMode string
+}
+
+// poolRemover removes the node pool the scaler loaded
+type poolRemover struct {
+	*scaler
+	force    bool
+	response RemovePoolResponse
+}
+
+// RemovePool cordons and drains the nodes of a node pool, then deletes its VMSS, or its VMs, their NICs and OS disks, and its availability set,
+// and the network security group of a pool with networkSecurityRules.
+// The caller removes the pool from its api model with RemoveAgentPoolProfile.
+// Canceling ctx interrupts the removal before it drains the nodes or deletes the resources of the pool, with an *operations.InterruptedError;
+// the calls to Azure are made with a context detached from ctx and bounded by their own timeout
+func RemovePool(ctx context.Context, req *RemovePoolRequest) (*RemovePoolResponse, error) {
+	op, err := newOperation(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	if req.ContainerService == nil {
+		return nil, errors.New("the api model of the cluster is required")
+	}
+	if req.AgentPoolName == "" {
+		return nil, errors.New("the name of the node pool to remove is required")
+	}
+	r := &poolRemover{
+		scaler: &scaler{
+			operation:        op,
+			containerService: req.ContainerService,
+			subscriptionID:   req.SubscriptionID,
+			resourceGroup:    req.ResourceGroup,
+			agentPoolName:    req.AgentPoolName,
+			apiserverURL:     req.APIServerURL,
+		},
+		force: req.Force,
+	}
+	if err = r.load(); err != nil {
+		return nil, err
+	}
+	r.response.AgentPoolIndex = r.agentPoolIndex
+	if err = r.validate(); err != nil {
+		return nil, err
+	}
+	err = r.run(ctx)
+	return &r.response, err
+}
+
+// validate refuses to remove a pool the cluster still depends on
+func (r *poolRemover) validate() error {
+	properties := r.containerService.Properties
+	if len(properties.AgentPoolProfiles) == 1 && !r.force {
+		return errors.Errorf("node pool %s is the last node pool of the cluster, use force to remove it", r.agentPoolName)
+	}
+	if kc := properties.OrchestratorProfile.KubernetesConfig; kc != nil && kc.IsClusterAutoscalerEnabled() {
+		addon := kc.GetAddonByName(common.ClusterAutoscalerAddonName)
+		for _, pool := range addon.Pools {
+			if !strings.EqualFold(pool.Name, r.agentPoolName) {
+				continue
+			}
+			if !r.force {
+				return errors.Errorf("cluster-autoscaler scales node pool %s, remove it from the pools of the addon or use force to remove it", r.agentPoolName)
+			}
+			r.response.ClusterAutoscalerMode = addon.Mode
+			if r.response.ClusterAutoscalerMode == "" {
+				r.response.ClusterAutoscalerMode = api.AddonModeEnsureExists
+			}
+		}
+	}
+	// The names of the VMs of Windows availability set pools derive from the index of their pool
+	for _, pool := range properties.AgentPoolProfiles[r.agentPoolIndex+1:] {
+		if pool.IsWindows() && pool.IsAvailabilitySets() {
+			return errors.Errorf("node pool %s cannot be removed, the names of the VMs of Windows node pool %s that follows it depend on its index", r.agentPoolName, pool.Name)
+		}
+	}
+	if r.apiserverURL == "" {
+		return ErrAPIServerURLRequired
+	}
+	return nil
+}
+
+func (r *poolRemover) run(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(operations.DetachInterrupt(ctx), armhelpers.DefaultARMOperationTimeout)
+	defer cancel()
+
+	var nodes []string
+	vmssFound := false
+	if r.agentPool.IsVirtualMachineScaleSets() {
+		var err error
+		if vmssFound, err = r.findVMSS(ctx); err != nil {
+			return err
+		}
+		if vmssFound {
+			vmssVMs, err := operations.GetScaleSetVMs(ctx, r.client, r.resourceGroup, r.agentPool.VMSSName)
+			if err != nil {
+				return err
+			}
+			for _, vm := range vmssVMs {
+				nodes = append(nodes, vm.Name)
+			}
+		} else {
+			r.logger.Infof("VMSS %s of node pool %s was not found in resource group %s, it was already deleted", r.agentPool.VMSSName, r.agentPoolName, r.resourceGroup)
+		}
+	} else {
+		for vmsListPage, err := r.client.ListVirtualMachines(ctx, r.resourceGroup); vmsListPage.NotDone(); err = vmsListPage.Next() {
+			if err != nil {
+				return errors.Wrap(err, "failed to get VMs in the resource group")
+			}
+			for _, vm := range vmsListPage.Values() {
+				if vm.Name != nil && r.vmInVMASAgentPool(*vm.Name, vm.Tags) {
+					nodes = append(nodes, *vm.Name)
+				}
+			}
+		}
+	}
+
+	if r.whatIf {
+		fmt.Fprintf(r.output, "Removing node pool %s would cordon, drain and delete %d node(s):\n", r.agentPoolName, len(nodes))
+		for _, node := range nodes {
+			fmt.Fprintf(r.output, "  - %s\n", node)
+		}
+		return nil
+	}
+
+	if len(nodes) > 0 {
+		if err := operations.CheckInterrupted(ctx, fmt.Sprintf("draining the nodes of node pool %s", r.agentPoolName)); err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			r.logger.Infof("Node %s will be cordoned and drained\n", node)
+		}
+		if err := r.drainNodes(nodes); err != nil {
+			return errors.Wrap(err, "Got error while draining the nodes to be deleted")
+		}
+	}
+
+	if err := operations.CheckInterrupted(ctx, fmt.Sprintf("deleting the resources of node pool %s", r.agentPoolName)); err != nil {
+		return err
+	}
+	if r.agentPool.IsVirtualMachineScaleSets() {
+		if vmssFound {
+			r.logger.Infof("VMSS %s will be deleted\n", r.agentPool.VMSSName)
+			if err := r.client.DeleteVirtualMachineScaleSet(ctx, r.resourceGroup, r.agentPool.VMSSName); err != nil {
+				return errors.Wrapf(err, "failed to delete VMSS %s", r.agentPool.VMSSName)
+			}
+			r.response.NodesRemoved = nodes
+		}
+		return r.deleteNetworkSecurityGroup(ctx)
+	}
+
+	for _, node := range nodes {
+		r.logger.Infof("Node %s's VM will be deleted\n", node)
+	}
+	if errList := operations.ScaleDownVMs(r.client, r.logger, r.subscriptionID, r.resourceGroup, nodes...); errList != nil {
+		return scaleDownError(errList)
+	}
+	r.response.NodesRemoved = nodes
+	availabilitySet := fmt.Sprintf("%s-availabilitySet-%s", r.agentPool.Name, r.nameSuffix)
+	r.logger.Infof("Availability set %s will be deleted\n", availabilitySet)
+	if err := r.client.DeleteAvailabilitySet(ctx, r.resourceGroup, availabilitySet); err != nil {
+		return errors.Wrapf(err, "failed to delete availability set %s", availabilitySet)
+	}
+	return r.deleteNetworkSecurityGroup(ctx)
+}
+
+// deleteNetworkSecurityGroup deletes the network security group of a pool with networkSecurityRules once its network interfaces are deleted,
+// a pool added later with the same name gets a new one. Deleting a network security group that was already deleted succeeds
+func (r *poolRemover) deleteNetworkSecurityGroup(ctx context.Context) error {
+	if !r.agentPool.HasNetworkSecurityGroup() {
+		return nil
+	}
+	nsg := r.containerService.Properties.GetAgentPoolNSGName(r.agentPool)
+	r.logger.Infof("Network security group %s will be deleted\n", nsg)
+	if err := r.client.DeleteNetworkSecurityGroup(ctx, r.resourceGroup, nsg); err != nil {
+		return errors.Wrapf(err, "failed to delete network security group %s", nsg)
+	}
+	return nil
+}
+
+// findVMSS returns whether the VMSS of the pool exists, it does not once a previous removal deleted it
+func (r *poolRemover) findVMSS(ctx context.Context) (bool, error) {
+	for vmssListPage, err := r.client.ListVirtualMachineScaleSets(ctx, r.resourceGroup); vmssListPage.NotDone(); err = vmssListPage.NextWithContext(ctx) {
+		if err != nil {
+			return false, errors.Wrap(err, "failed to get VMSS list in the resource group")
+		}
+		for _, vmss := range vmssListPage.Values() {
+			if to.String(vmss.Name) == r.agentPool.VMSSName {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// RemoveAgentPoolProfile removes a node pool from the agent pool profiles of an api model, and from the pools cluster-autoscaler scales.
+// It returns false if the api model has no such pool
+func RemoveAgentPoolProfile(properties *api.Properties, name string) bool {
+	index := -1
+	for i, pool := range properties.AgentPoolProfiles {
+		if strings.EqualFold(pool.Name, name) {
+			index = i
+		}
+	}
+	if index == -1 {
+		return false
+	}
+	properties.AgentPoolProfiles = append(properties.AgentPoolProfiles[:index], properties.AgentPoolProfiles[index+1:]...)
+
+	if properties.OrchestratorProfile == nil || properties.OrchestratorProfile.KubernetesConfig == nil {
+		return true
+	}
+	addons := properties.OrchestratorProfile.KubernetesConfig.Addons
+	for i := range addons {
+		if addons[i].Name != common.ClusterAutoscalerAddonName {
+			continue
+		}
+		pools := addons[i].Pools[:0]
+		for _, pool := range addons[i].Pools {
+			if !strings.EqualFold(pool.Name, name) {
+				pools = append(pools, pool)
+			}
+		}
+		addons[i].Pools = pools
+	}
+	return true
+}
@@ -0,0 +1,225 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"sort"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/api/common"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestRemovePool(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	newClient := func(cs *api.ContainerService) *armhelpers.MockAKSEngineClient {
+		clusterID := cs.Properties.GetClusterID()
+		client := &armhelpers.MockAKSEngineClient{
+			MockKubernetesClient: &armhelpers.MockKubernetesClient{},
+			FakeListVirtualMachineResult: func() []compute.VirtualMachine {
+				var vms []compute.VirtualMachine
+				for _, name := range []string{"k8s-agentpool1-" + clusterID + "-0", "k8s-agentpool1-" + clusterID + "-1", "k8s-agentpool2-" + clusterID + "-0"} {
+					vms = append(vms, compute.VirtualMachine{
+						Name: to.StringPtr(name),
+						Tags: map[string]*string{"poolName": to.StringPtr(name[4:14]), "resourceNameSuffix": to.StringPtr(clusterID)},
+					})
+				}
+				return vms
+			},
+		}
+		client.MockKubernetesClient.GetNodeFunc = func(name string) (*v1.Node, error) {
+			node := &v1.Node{}
+			node.Name = name
+			return node, nil
+		}
+		return client
+	}
+	removePool := func(ctx context.Context, client armhelpers.AKSEngineClient, cs *api.ContainerService, poolName string, force bool) (*RemovePoolResponse, error) {
+		return RemovePool(ctx, &RemovePoolRequest{
+			Options:          Options{Client: client, Output: ioutil.Discard},
+			ContainerService: cs,
+			ResourceGroup:    "rg1",
+			AgentPoolName:    poolName,
+			APIServerURL:     "https://apiserver",
+			Force:            force,
+		})
+	}
+
+	cs := loadContainerService(t)
+	clusterID := cs.Properties.GetClusterID()
+	resp, err := removePool(context.Background(), newClient(cs), cs, "agentpool1", false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(resp.AgentPoolIndex).To(Equal(0))
+	sort.Strings(resp.NodesRemoved)
+	g.Expect(resp.NodesRemoved).To(Equal([]string{"k8s-agentpool1-" + clusterID + "-0", "k8s-agentpool1-" + clusterID + "-1"}))
+
+	cs = loadContainerService(t)
+	client := newClient(cs)
+	client.FailDeleteAvailabilitySet = true
+	_, err = removePool(context.Background(), client, cs, "agentpool2", false)
+	g.Expect(err).To(MatchError("failed to delete availability set agentpool2-availabilitySet-" + clusterID + ": DeleteAvailabilitySet failed"))
+
+	cs = loadContainerService(t)
+	cs.Properties.AgentPoolProfiles[1].NetworkSecurityRules = []api.NetworkSecurityRule{{Name: "allow_nodeports"}}
+	client = newClient(cs)
+	client.FailDeleteNetworkSecurityGroup = true
+	_, err = removePool(context.Background(), client, cs, "agentpool1", false)
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = removePool(context.Background(), client, cs, "agentpool2", false)
+	g.Expect(err).To(MatchError("failed to delete network security group k8s-agentpool2-" + clusterID + "-nsg: DeleteNetworkSecurityGroup failed"))
+
+	cs = loadContainerService(t)
+	_, err = removePool(context.Background(), newClient(cs), cs, "agentpool3", false)
+	g.Expect(err).To(MatchError("node pool agentpool3 was not found in the deployed api model"))
+
+	_, err = RemovePool(context.Background(), &RemovePoolRequest{
+		Options:          Options{Client: newClient(cs)},
+		ContainerService: cs,
+		AgentPoolName:    "agentpool1",
+	})
+	g.Expect(errors.Is(err, ErrAPIServerURLRequired)).To(BeTrue())
+
+	cs.Properties.AgentPoolProfiles = cs.Properties.AgentPoolProfiles[1:]
+	_, err = removePool(context.Background(), newClient(cs), cs, "agentpool2", false)
+	g.Expect(err).To(MatchError("node pool agentpool2 is the last node pool of the cluster, use force to remove it"))
+	_, err = removePool(context.Background(), newClient(cs), cs, "agentpool2", true)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cs = loadContainerService(t)
+	cs.Properties.OrchestratorProfile.KubernetesConfig.Addons = []api.KubernetesAddon{
+		{
+			Name:    common.ClusterAutoscalerAddonName,
+			Enabled: to.BoolPtr(true),
+			Pools:   []api.AddonNodePoolsConfig{{Name: "agentpool2"}},
+		},
+	}
+	_, err = removePool(context.Background(), newClient(cs), cs, "agentpool2", false)
+	g.Expect(err).To(MatchError("cluster-autoscaler scales node pool agentpool2, remove it from the pools of the addon or use force to remove it"))
+	resp, err = removePool(context.Background(), newClient(cs), cs, "agentpool1", false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(resp.ClusterAutoscalerMode).To(BeEmpty())
+	resp, err = removePool(context.Background(), newClient(cs), cs, "agentpool2", true)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(resp.ClusterAutoscalerMode).To(Equal(api.AddonModeEnsureExists))
+
+	cs = loadContainerService(t)
+	cs.Properties.AgentPoolProfiles[1].OSType = api.Windows
+	_, err = removePool(context.Background(), newClient(cs), cs, "agentpool1", true)
+	g.Expect(err).To(MatchError("node pool agentpool1 cannot be removed, the names of the VMs of Windows node pool agentpool2 that follows it depend on its index"))
+
+	cs = loadContainerService(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	resp, err = removePool(ctx, newClient(cs), cs, "agentpool1", false)
+	g.Expect(err).To(MatchError("interrupted before draining the nodes of node pool agentpool1"))
+	g.Expect(resp.NodesRemoved).To(BeEmpty())
+}
+
+func TestRemovePoolVMSS(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	cs := loadContainerService(t)
+	for _, p := range cs.Properties.AgentPoolProfiles {
+		p.AvailabilityProfile = api.VirtualMachineScaleSets
+		p.VMSSName = "k8s-" + p.Name + "-" + cs.Properties.GetClusterID() + "-vmss"
+	}
+	client := &armhelpers.MockAKSEngineClient{
+		MockKubernetesClient: &armhelpers.MockKubernetesClient{},
+		FakeListVirtualMachineScaleSetsResult: func() []compute.VirtualMachineScaleSet {
+			return []compute.VirtualMachineScaleSet{{Name: to.StringPtr(cs.Properties.AgentPoolProfiles[1].VMSSName)}}
+		},
+		FakeListVirtualMachineScaleSetVMsResult: func() []compute.VirtualMachineScaleSetVM {
+			return []compute.VirtualMachineScaleSetVM{{
+				InstanceID: to.StringPtr("0"),
+				VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
+					OsProfile: &compute.OSProfile{ComputerName: to.StringPtr("k8s-agentpool2-12345678-vmss000000")},
+				},
+			}}
+		},
+	}
+	client.MockKubernetesClient.GetNodeFunc = func(name string) (*v1.Node, error) {
+		node := &v1.Node{}
+		node.Name = name
+		return node, nil
+	}
+
+	var out bytes.Buffer
+	request := &RemovePoolRequest{
+		Options:          Options{Client: client, Output: &out, WhatIf: true},
+		ContainerService: cs,
+		ResourceGroup:    "rg1",
+		AgentPoolName:    "agentpool2",
+		APIServerURL:     "https://apiserver",
+	}
+	resp, err := RemovePool(context.Background(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(resp.NodesRemoved).To(BeEmpty())
+	g.Expect(out.String()).To(Equal("Removing node pool agentpool2 would cordon, drain and delete 1 node(s):\n  - k8s-agentpool2-12345678-vmss000000\n"))
+
+	request.WhatIf = false
+	resp, err = RemovePool(context.Background(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(resp.AgentPoolIndex).To(Equal(1))
+	g.Expect(resp.NodesRemoved).To(Equal([]string{"k8s-agentpool2-12345678-vmss000000"}))
+
+	client.FailDeleteVirtualMachineScaleSet = true
+	_, err = RemovePool(context.Background(), request)
+	g.Expect(err).To(MatchError("failed to delete VMSS " + cs.Properties.AgentPoolProfiles[1].VMSSName + ": DeleteVirtualMachineScaleSet failed"))
+
+	// A removal that deleted the VMSS before it failed can be run again
+	client.FakeListVirtualMachineScaleSetsResult = func() []compute.VirtualMachineScaleSet {
+		return []compute.VirtualMachineScaleSet{}
+	}
+	resp, err = RemovePool(context.Background(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(resp.NodesRemoved).To(BeEmpty())
+
+	// It deletes the network security group of the pool once the VMSS is gone
+	client.FailDeleteNetworkSecurityGroup = true
+	_, err = RemovePool(context.Background(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	cs.Properties.AgentPoolProfiles[1].NetworkSecurityRules = []api.NetworkSecurityRule{{Name: "allow_nodeports"}}
+	_, err = RemovePool(context.Background(), request)
+	g.Expect(err).To(MatchError("failed to delete network security group k8s-agentpool2-" + cs.Properties.GetClusterID() + "-nsg: DeleteNetworkSecurityGroup failed"))
+}
+
+func TestRemoveAgentPoolProfile(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	properties := &api.Properties{
+		OrchestratorProfile: &api.OrchestratorProfile{
+			KubernetesConfig: &api.KubernetesConfig{
+				Addons: []api.KubernetesAddon{
+					{Name: common.CoreDNSAddonName},
+					{
+						Name:  common.ClusterAutoscalerAddonName,
+						Pools: []api.AddonNodePoolsConfig{{Name: "pool1"}, {Name: "pool2"}},
+					},
+				},
+			},
+		},
+		AgentPoolProfiles: []*api.AgentPoolProfile{{Name: "pool1"}, {Name: "pool2"}, {Name: "pool3"}},
+	}
+	g.Expect(RemoveAgentPoolProfile(properties, "Pool2")).To(BeTrue())
+	g.Expect(properties.AgentPoolProfiles).To(Equal([]*api.AgentPoolProfile{{Name: "pool1"}, {Name: "pool3"}}))
+	g.Expect(properties.OrchestratorProfile.KubernetesConfig.Addons[1].Pools).To(Equal([]api.AddonNodePoolsConfig{{Name: "pool1"}}))
+
+	g.Expect(RemoveAgentPoolProfile(properties, "pool4")).To(BeFalse())
+	g.Expect(properties.AgentPoolProfiles).To(HaveLen(2))
+
+	g.Expect(RemoveAgentPoolProfile(&api.Properties{AgentPoolProfiles: []*api.AgentPoolProfile{{Name: "pool1"}}}, "pool1")).To(BeTrue())
+}
@@ -0,0 +1,641 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cluster
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/armhelpers/utils"
+	"github.com/Azure/aks-engine/pkg/engine"
+	"github.com/Azure/aks-engine/pkg/engine/transform"
+	"github.com/Azure/aks-engine/pkg/helpers"
+	"github.com/Azure/aks-engine/pkg/operations"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+// ErrAPIServerURLRequired is returned by Scale when a node pool is scaled down without the URL of the apiserver,
+// which is required to cordon and drain the nodes to remove
+var ErrAPIServerURLRequired = errors.New("the apiserver URL is required to scale down a node pool")
+
+// ScaleRequest is a request to scale a node pool of a cluster
+type ScaleRequest struct {
+	Options
+	// ContainerService is the api model of the cluster, loaded with its defaults.
+	// Scale replaces its agent pool profiles by the scaled pool to generate the template of the pool
+	ContainerService *api.ContainerService
+	// SubscriptionID is the subscription of the cluster
+	SubscriptionID string
+	// ResourceGroup is the resource group of the cluster
+	ResourceGroup string
+	// AgentPoolName is the name of the node pool to scale, it can be empty when the cluster has a single node pool
+	AgentPoolName string
+	// Count is the desired number of nodes of the pool
+	Count int
+	// APIServerURL is the URL of the apiserver, it is required to scale down. The nodes of the pool are not reported without it
+	APIServerURL string
+	// NodesToRemove are the names of the nodes to remove when scaling down
+	NodesToRemove []string
+	// ScaleDownStrategy selects the nodes to remove when scaling down, it is one of operations.ScaleDownStrategies
+	ScaleDownStrategy string
+	// UpdateVMSSModel deploys the template of a VMSS node pool even when the pool has the desired count, to update its VMSS model
+	UpdateVMSSModel bool
+}
+
+// ScaleResponse is the result of scaling a node pool
+type ScaleResponse struct {
+	// AgentPoolIndex is the index of the scaled pool in the agent pool profiles of the api model
+	AgentPoolIndex int
+	// CurrentCount is the number of VMs of the pool before scaling
+	CurrentCount int
+	// DeploymentName is the name of the ARM deployment of the scale template, empty if the template was not deployed
+	DeploymentName string
+	// NodesAdded are the names of the nodes added to the pool
+	NodesAdded []string
+	// NodesRemoved are the names of the nodes removed from the pool
+	NodesRemoved []string
+	// Nodes are the nodes of the pool after scaling, nil when the apiserver did not list them
+	Nodes []v1.Node
+}
+
+// scaler holds the state of a scale operation
+type scaler struct {
+	*operation
+
+	containerService  *api.ContainerService
+	subscriptionID    string
+	resourceGroup     string
+	agentPoolName     string
+	count             int
+	apiserverURL      string
+	nodesToRemove     []string
+	scaleDownStrategy string
+	updateVMSSModel   bool
+
+	// derived
+	agentPool      *api.AgentPoolProfile
+	agentPoolIndex int
+	nameSuffix     string
+	kubeconfig     string
+	nodes          []v1.Node
+	response       ScaleResponse
+}
+
+// Scale scales a node pool of a cluster to the desired count of nodes.
+// Scaling down cordons and drains the nodes to remove, then deletes their VMs. The caller updates the count of the pool in its api model.
+// The response describes the nodes that were added or removed even if an error occurred after.
+// Canceling ctx interrupts the scale before it drains nodes or deploys the template of the pool, with an *operations.InterruptedError;
+// the steps already started are completed, the calls to Azure are made with a context detached from ctx and bounded by their own timeout
+func Scale(ctx context.Context, req *ScaleRequest) (*ScaleResponse, error) {
+	op, err := newOperation(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	if req.ContainerService == nil {
+		return nil, errors.New("the api model of the cluster is required")
+	}
+	if req.Count < 1 {
+		return nil, errors.New("the desired count of nodes must be at least 1")
+	}
+	s := &scaler{
+		operation:         op,
+		containerService:  req.ContainerService,
+		subscriptionID:    req.SubscriptionID,
+		resourceGroup:     req.ResourceGroup,
+		agentPoolName:     req.AgentPoolName,
+		count:             req.Count,
+		apiserverURL:      req.APIServerURL,
+		nodesToRemove:     req.NodesToRemove,
+		scaleDownStrategy: req.ScaleDownStrategy,
+		updateVMSSModel:   req.UpdateVMSSModel,
+	}
+	if err = s.load(); err != nil {
+		return nil, err
+	}
+	s.warnSubnetNetworkSecurityGroup(s.containerService, s.agentPool)
+	err = s.run(ctx)
+	s.response.AgentPoolIndex = s.agentPoolIndex
+	s.response.Nodes = s.nodes
+	return &s.response, err
+}
+
+func (s *scaler) load() error {
+	profiles := s.containerService.Properties.AgentPoolProfiles
+	if s.agentPoolName == "" {
+		agentPoolCount := len(profiles)
+		if agentPoolCount > 1 {
+			return errors.New("the name of the node pool to scale is required if more than one agent pool is defined in the container service")
+		} else if agentPoolCount == 0 {
+			return errors.New("No node pools found to scale")
+		}
+		s.agentPoolName = profiles[0].Name
+	}
+	s.agentPoolIndex = -1
+	for i, pool := range profiles {
+		if pool.Name == s.agentPoolName {
+			s.agentPool = pool
+			s.agentPoolIndex = i
+		}
+	}
+	if s.agentPoolIndex == -1 {
+		return errors.Errorf("node pool %s was not found in the deployed api model", s.agentPoolName)
+	}
+
+	// Back-compat logic to populate the VMSSName property for clusters built prior to VMSSName being a part of the API model spec
+	if s.agentPool.IsVirtualMachineScaleSets() && s.agentPool.VMSSName == "" {
+		s.agentPool.VMSSName = s.containerService.Properties.GetAgentVMPrefix(s.agentPool, s.agentPoolIndex)
+	}
+
+	//allows to identify VMs in the resource group that belong to this cluster.
+	s.nameSuffix = s.containerService.Properties.GetClusterID()
+	s.logger.Debugf("Cluster ID used in all agent pools: %s", s.nameSuffix)
+
+	var err error
+	s.kubeconfig, err = engine.GenerateKubeConfig(s.containerService.Properties, s.containerService.Location)
+	if err != nil {
+		return errors.New("Unable to derive kubeconfig from api model")
+	}
+	return nil
+}
+
+func (s *scaler) run(ctx context.Context) error {
+	if s.containerService.Properties.IsAzureStackCloud() {
+		if err := s.validateOSBaseImage(ctx, s.containerService); err != nil {
+			return errors.Wrap(err, "validating OS base images")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(operations.DetachInterrupt(ctx), armhelpers.DefaultARMOperationTimeout)
+	defer cancel()
+	orchestratorInfo := s.containerService.Properties.OrchestratorProfile
+	var currentNodeCount, countForTemplate, offsetForTemplate, index, winPoolIndex int
+	winPoolIndex = -1
+	indexes := make([]int, 0)
+	indexToVM := make(map[int]string)
+
+	// Get nodes list from the k8s API before scaling for the desired pool
+	if s.apiserverURL != "" {
+		nodes, err := operations.GetNodes(s.client, s.logger, s.apiserverURL, s.kubeconfig, time.Duration(5)*time.Minute, s.agentPoolName, -1)
+		if err == nil && nodes != nil {
+			s.nodes = nodes
+		}
+	}
+
+	if s.agentPool.IsAvailabilitySets() {
+		for i := 0; i < 10; i++ {
+			for vmsListPage, err := s.client.ListVirtualMachines(ctx, s.resourceGroup); vmsListPage.NotDone(); err = vmsListPage.Next() {
+				if err != nil {
+					return errors.Wrap(err, "failed to get VMs in the resource group")
+				} else if len(vmsListPage.Values()) < 1 {
+					return errors.New("The provided resource group does not contain any VMs")
+				}
+				for _, vm := range vmsListPage.Values() {
+					vmName := *vm.Name
+					if !s.vmInVMASAgentPool(vmName, vm.Tags) {
+						continue
+					}
+
+					if s.agentPool.OSType == api.Windows {
+						_, _, winPoolIndex, index, err = utils.WindowsVMNameParts(vmName)
+					} else {
+						_, _, index, err = utils.K8sLinuxVMNameParts(vmName)
+					}
+					if err != nil {
+						return err
+					}
+
+					indexToVM[index] = vmName
+					indexes = append(indexes, index)
+				}
+			}
+			// If we get zero VMs that match our api model pool name, then
+			// Retry every 30 seconds for up to 5 minutes to accommodate temporary issues connecting to the VM API
+			if len(indexes) > 0 {
+				break
+			}
+			s.logger.Warnf("Found no VMs in resource group %s that match pool name %s\n", s.resourceGroup, s.agentPool.Name)
+			select {
+			case <-operations.Interrupted(ctx):
+				return operations.CheckInterrupted(ctx, fmt.Sprintf("listing the VMs of node pool %s", s.agentPoolName))
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(30 * time.Second):
+			}
+		}
+		sortedIndexes := sort.IntSlice(indexes)
+		sortedIndexes.Sort()
+		indexes = sortedIndexes
+		currentNodeCount = len(indexes)
+		s.response.CurrentCount = currentNodeCount
+
+		if currentNodeCount == s.count {
+			s.printScaleTargetEqualsExisting(currentNodeCount)
+			return nil
+		}
+		if currentNodeCount == 0 {
+			return errors.New("None of the VMs in the provided resource group contain any nodes")
+		}
+
+		// VMAS Scale down Scenario
+		if currentNodeCount > s.count {
+			if s.apiserverURL == "" {
+				return ErrAPIServerURLRequired
+			}
+
+			s.printNodesBeforeScaleDown(currentNodeCount)
+
+			// By default, the VMs with the highest indexes are removed first
+			candidates := make([]string, 0, currentNodeCount)
+			for i := currentNodeCount - 1; i >= 0; i-- {
+				candidates = append(candidates, indexToVM[indexes[i]])
+			}
+			pods, err := s.getPodsForScaleDownStrategy()
+			if err != nil {
+				return err
+			}
+			vmsToDelete, err := operations.SelectNodesToRemove(candidates, currentNodeCount-s.count, s.nodesToRemove, s.scaleDownStrategy, s.nodes, pods)
+			if err != nil {
+				return errors.Wrapf(err, "failed to select the VMs to remove from node pool %s", s.agentPoolName)
+			}
+
+			if s.whatIf {
+				s.printNodesToRemove(vmsToDelete)
+				return nil
+			}
+			if err = operations.CheckInterrupted(ctx, fmt.Sprintf("draining the nodes to remove from node pool %s", s.agentPoolName)); err != nil {
+				return err
+			}
+			for _, node := range vmsToDelete {
+				s.logger.Infof("Node %s will be cordoned and drained\n", node)
+			}
+			err = s.drainNodes(vmsToDelete)
+			if err != nil {
+				return errors.Wrap(err, "Got error while draining the nodes to be deleted")
+			}
+
+			for _, node := range vmsToDelete {
+				s.logger.Infof("Node %s's VM will be deleted\n", node)
+			}
+			errList := operations.ScaleDownVMs(s.client, s.logger, s.subscriptionID, s.resourceGroup, vmsToDelete...)
+			if errList != nil {
+				return scaleDownError(errList)
+			}
+			s.response.NodesRemoved = vmsToDelete
+			if s.nodes != nil {
+				nodes, err := operations.GetNodes(s.client, s.logger, s.apiserverURL, s.kubeconfig, time.Duration(5)*time.Minute, s.agentPoolName, s.count)
+				if err == nil && nodes != nil {
+					s.nodes = nodes
+					s.logger.Infof("Nodes in pool %s after scaling:\n", s.agentPoolName)
+					operations.FprintNodes(s.output, s.nodes)
+				} else {
+					s.logger.Warningf("Unable to get nodes in pool %s after scaling:\n", s.agentPoolName)
+				}
+			}
+			return nil
+		}
+		countForTemplate, offsetForTemplate = getVMASTemplateCountAndOffset(indexes, s.count)
+	} else {
+		for vmssListPage, err := s.client.ListVirtualMachineScaleSets(ctx, s.resourceGroup); vmssListPage.NotDone(); err = vmssListPage.NextWithContext(ctx) {
+			if err != nil {
+				return errors.Wrap(err, "failed to get VMSS list in the resource group")
+			}
+			for _, vmss := range vmssListPage.Values() {
+				vmssName := to.String(vmss.Name)
+				if s.agentPool.VMSSName == vmssName {
+					s.logger.Infof("found VMSS %s in resource group %s that correlates with node pool %s", vmssName, s.resourceGroup, s.agentPoolName)
+				} else {
+					continue
+				}
+
+				if vmss.Sku != nil {
+					currentNodeCount = int(*vmss.Sku.Capacity)
+					s.response.CurrentCount = currentNodeCount
+					if int(*vmss.Sku.Capacity) == s.count && !s.updateVMSSModel {
+						s.printScaleTargetEqualsExisting(currentNodeCount)
+						return nil
+					} else if int(*vmss.Sku.Capacity) > s.count {
+						if s.apiserverURL == "" {
+							return ErrAPIServerURLRequired
+						}
+						if err := s.scaleDownVMSS(ctx, vmssName, int(*vmss.Sku.Capacity)); err != nil {
+							return err
+						}
+					}
+				} else {
+					// Fall back to comparing against the known count value in the api model
+					if s.agentPool.Count == s.count {
+						s.printScaleTargetEqualsExisting(currentNodeCount)
+						return nil
+					}
+				}
+
+				currentNodeCount = int(*vmss.Sku.Capacity)
+				break
+			}
+		}
+		countForTemplate = s.count
+	}
+
+	if len(s.nodesToRemove) > 0 && currentNodeCount < s.count {
+		return errors.New("nodes to remove can only be given to scale down a node pool")
+	}
+
+	s.agentPool.Count = countForTemplate
+	s.containerService.Properties.AgentPoolProfiles = []*api.AgentPoolProfile{s.agentPool}
+
+	_, err := s.containerService.SetPropertiesDefaults(api.PropertiesDefaultsParams{
+		IsScale:    true,
+		IsUpgrade:  false,
+		PkiKeySize: helpers.DefaultPkiKeySize,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error in SetPropertiesDefaults")
+	}
+	templateJSON, parametersJSON, err := s.generateTemplateJSON(s.containerService)
+	if err != nil {
+		return err
+	}
+
+	transformer := transform.Transformer{Translator: s.translator}
+
+	addValue(parametersJSON, s.agentPool.Name+"Count", countForTemplate)
+
+	// The agent pool is set to index 0 for the scale operation, we need to overwrite the template variables that rely on pool index.
+	if winPoolIndex != -1 {
+		templateJSON["variables"].(map[string]interface{})[s.agentPool.Name+"Index"] = winPoolIndex
+		templateJSON["variables"].(map[string]interface{})[s.agentPool.Name+"VMNamePrefix"] = s.containerService.Properties.GetAgentVMPrefix(s.agentPool, winPoolIndex)
+	}
+	if orchestratorInfo.KubernetesConfig.LoadBalancerSku == api.StandardLoadBalancerSku {
+		err = transformer.NormalizeForK8sSLBScalingOrUpgrade(s.logger, templateJSON)
+		if err != nil {
+			return errors.Wrap(err, "error transforming the template for scaling with SLB")
+		}
+	}
+	err = transformer.NormalizeForK8sVMASScalingUp(s.logger, templateJSON)
+	if err != nil {
+		return errors.Wrap(err, "error transforming the template for scaling template")
+	}
+
+	transformer.RemoveImmutableResourceProperties(s.logger, templateJSON)
+
+	if s.agentPool.IsAvailabilitySets() {
+		addValue(parametersJSON, fmt.Sprintf("%sOffset", s.agentPool.Name), offsetForTemplate)
+	}
+
+	if s.nodes != nil {
+		s.logger.Infof("Nodes in pool '%s' before scaling:\n", s.agentPoolName)
+		operations.FprintNodes(s.output, s.nodes)
+	}
+	if err = operations.CheckInterrupted(ctx, fmt.Sprintf("deploying the template of node pool %s", s.agentPoolName)); err != nil {
+		return err
+	}
+	deploymentName := newDeploymentName(s.resourceGroup)
+	if !s.whatIf {
+		s.response.DeploymentName = deploymentName
+	}
+	if err = s.deploy(ctx, s.resourceGroup, deploymentName, templateJSON, parametersJSON); err != nil || s.whatIf {
+		return err
+	}
+	if s.agentPool.IsAvailabilitySets() {
+		// The scale up template only deploys the new VMs of the pool
+		s.response.NodesAdded = s.deployedVMNames(ctx, s.resourceGroup, deploymentName)
+	}
+	if s.nodes != nil {
+		nodes, err := operations.GetNodes(s.client, s.logger, s.apiserverURL, s.kubeconfig, time.Duration(5)*time.Minute, s.agentPoolName, s.count)
+		if err == nil && nodes != nil {
+			if !s.agentPool.IsAvailabilitySets() {
+				s.response.NodesAdded = addedNodes(s.nodes, nodes)
+			}
+			s.nodes = nodes
+			s.logger.Infof("Nodes in pool '%s' after scaling:\n", s.agentPoolName)
+			operations.FprintNodes(s.output, s.nodes)
+		} else {
+			s.logger.Warningf("Unable to get nodes in pool %s after scaling:\n", s.agentPoolName)
+		}
+	}
+	return nil
+}
+
+// getVMASTemplateCountAndOffset returns the count and the offset of the agent pool in the VMAS scale up template, given the sorted indexes of the VMs of the pool.
+// Our templates generate a range of nodes based on a count and offset, it is possible for there to be holes in the indexes after a scale down removed VMs,
+// so the offset follows the highest used index, and the count is larger than the desired count to get enough nodes for the range
+func getVMASTemplateCountAndOffset(indexes []int, desiredCount int) (int, int) {
+	highestUsedIndex := indexes[len(indexes)-1]
+	return desiredCount + highestUsedIndex + 1 - len(indexes), highestUsedIndex + 1
+}
+
+func (s *scaler) vmInVMASAgentPool(vmName string, tags map[string]*string) bool {
+	// Try to locate the VM's agent pool by expected tags.
+	if tags != nil {
+		if poolName, ok := tags["poolName"]; ok {
+			if nameSuffix, ok := tags["resourceNameSuffix"]; ok {
+				// Use strings.Contains for the nameSuffix as the Windows Agent Pools use only
+				// a substring of the first 5 characters of the entire nameSuffix.
+				if strings.EqualFold(*poolName, s.agentPoolName) && strings.Contains(s.nameSuffix, *nameSuffix) {
+					return true
+				}
+			}
+		}
+	}
+
+	// Fall back to checking the VM name to see if it fits the naming pattern
+	if s.agentPool.OSType == api.Windows {
+		return strings.HasPrefix(vmName, s.containerService.Properties.GetClusterID()[:4]) &&
+			vmName[:9] == s.containerService.Properties.GetAgentVMPrefix(s.agentPool, s.agentPoolIndex)[:9]
+	}
+	poolIdentifier, _, _, _ := utils.K8sLinuxVMNameParts(vmName)
+	return strings.Contains(vmName, s.nameSuffix[:5]) && strings.EqualFold(poolIdentifier, s.agentPoolName)
+}
+
+// scaleDownVMSS cordons and drains the nodes of the VMSS VMs to remove, then deletes these VMs.
+// The template deployment that follows reconciles the capacity of the VMSS with the desired count
+func (s *scaler) scaleDownVMSS(ctx context.Context, vmssName string, capacity int) error {
+	vms, err := operations.GetScaleSetVMs(ctx, s.client, s.resourceGroup, vmssName)
+	if err != nil {
+		return err
+	}
+	if len(vms) != capacity {
+		s.logger.Warnf("VMSS %s has a capacity of %d, but has %d VMs\n", vmssName, capacity, len(vms))
+	}
+	if len(vms) <= s.count {
+		return nil
+	}
+
+	s.printNodesBeforeScaleDown(len(vms))
+
+	pods, err := s.getPodsForScaleDownStrategy()
+	if err != nil {
+		return err
+	}
+	toDelete, err := operations.SelectScaleSetVMsToRemove(vms, len(vms)-s.count, s.nodesToRemove, s.scaleDownStrategy, s.nodes, pods)
+	if err != nil {
+		return errors.Wrapf(err, "failed to select the VMs to remove from VMSS %s", vmssName)
+	}
+	vmsToDelete := make([]string, 0, len(toDelete))
+	for _, vm := range toDelete {
+		vmsToDelete = append(vmsToDelete, vm.Name)
+	}
+	if s.whatIf {
+		s.printNodesToRemove(vmsToDelete)
+		return nil
+	}
+	if err = operations.CheckInterrupted(ctx, fmt.Sprintf("draining the nodes to remove from VMSS %s", vmssName)); err != nil {
+		return err
+	}
+	for _, vm := range vmsToDelete {
+		s.logger.Infof("Node %s will be cordoned and drained\n", vm)
+	}
+	if err = s.drainNodes(vmsToDelete); err != nil {
+		return errors.Wrap(err, "Got error while draining the nodes to be deleted")
+	}
+
+	for _, vm := range toDelete {
+		s.logger.Infof("Node %s's VM (instance %s of VMSS %s) will be deleted\n", vm.Name, vm.InstanceID, vmssName)
+	}
+	if errList := operations.ScaleDownScaleSetVMs(s.client, s.logger, s.resourceGroup, vmssName, toDelete...); errList != nil {
+		return scaleDownError(errList)
+	}
+	s.response.NodesRemoved = vmsToDelete
+	return nil
+}
+
+// addedNodes returns the names of the nodes of after that are not in before
+func addedNodes(before, after []v1.Node) []string {
+	existing := make(map[string]bool, len(before))
+	for _, node := range before {
+		existing[node.Name] = true
+	}
+	var added []string
+	for _, node := range after {
+		if !existing[node.Name] {
+			added = append(added, node.Name)
+		}
+	}
+	return added
+}
+
+// printNodesToRemove prints the nodes a scale down would cordon, drain and delete, for WhatIf
+func (s *scaler) printNodesToRemove(nodes []string) {
+	fmt.Fprintf(s.output, "Scaling down node pool %s to %d nodes would cordon, drain and delete %d node(s):\n", s.agentPoolName, s.count, len(nodes))
+	for _, node := range nodes {
+		fmt.Fprintf(s.output, "  - %s\n", node)
+	}
+}
+
+// getPodsForScaleDownStrategy returns the pods of the cluster when the scale down strategy selects the nodes to remove by their pods
+func (s *scaler) getPodsForScaleDownStrategy() ([]v1.Pod, error) {
+	if s.scaleDownStrategy != operations.ScaleDownStrategyLeastPodsFirst {
+		return nil, nil
+	}
+	client, err := s.client.GetKubernetesClient(s.apiserverURL, s.kubeconfig, time.Second, time.Duration(5)*time.Minute)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get a Kubernetes client")
+	}
+	pods, err := client.ListAllPods()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the pods of the cluster")
+	}
+	return pods.Items, nil
+}
+
+// scaleDownError aggregates the errors of the VMs that failed to delete, all items in the list are of type *operations.VMScalingErrorDetails
+func scaleDownError(errList *list.List) error {
+	var err error
+	format := "Node '%s' failed to delete with error: '%s'"
+	for element := errList.Front(); element != nil; element = element.Next() {
+		vmError, ok := element.Value.(*operations.VMScalingErrorDetails)
+		if ok {
+			if err == nil {
+				err = errors.Errorf(format, vmError.Name, vmError.Error.Error())
+			} else {
+				err = errors.Wrapf(err, format, vmError.Name, vmError.Error.Error())
+			}
+		}
+	}
+	return err
+}
+
+func (s *scaler) printNodesBeforeScaleDown(currentNodeCount int) {
+	if s.nodes == nil {
+		return
+	}
+	if len(s.nodes) == 1 {
+		s.logger.Infof("There is %d node in pool %s before scaling down to %d:\n", len(s.nodes), s.agentPoolName, s.count)
+	} else {
+		s.logger.Infof("There are %d nodes in pool %s before scaling down to %d:\n", len(s.nodes), s.agentPoolName, s.count)
+	}
+	operations.FprintNodes(s.output, s.nodes)
+	numNodesFromK8sAPI := len(s.nodes)
+	if currentNodeCount != numNodesFromK8sAPI {
+		s.logger.Warnf("There are %d VMs named \"*%s*\" in the resource group %s, but there are %d nodes named \"*%s*\" in the Kubernetes cluster\n", currentNodeCount, s.agentPoolName, s.resourceGroup, numNodesFromK8sAPI, s.agentPoolName)
+	} else {
+		nodesToDelete := currentNodeCount - s.count
+		if nodesToDelete > 1 {
+			s.logger.Infof("%d nodes will be deleted\n", nodesToDelete)
+		} else {
+			s.logger.Infof("%d node will be deleted\n", nodesToDelete)
+		}
+	}
+}
+
+func (s *scaler) drainNodes(vmsToDelete []string) error {
+	timeout := time.Duration(60) * time.Minute
+	client, err := s.client.GetKubernetesClient(s.apiserverURL, s.kubeconfig, time.Second, timeout)
+	if err != nil {
+		return errors.Wrap(err, "failed to get a Kubernetes client")
+	}
+	numVmsToDrain := len(vmsToDelete)
+	errChan := make(chan *operations.VMScalingErrorDetails, numVmsToDrain)
+	defer close(errChan)
+	for _, vmName := range vmsToDelete {
+		go func(vmName string) {
+			err := operations.SafelyDrainNodeWithClient(client, s.logger, vmName, timeout)
+			if err != nil {
+				s.logger.Errorf("Failed to drain node %s, got error %v", vmName, err)
+				errChan <- &operations.VMScalingErrorDetails{Error: err, Name: vmName}
+				return
+			}
+			errChan <- nil
+		}(vmName)
+	}
+
+	// Every drain is waited for, a drain that fails after the first failure would otherwise send on the closed channel
+	var failedDrain *operations.VMScalingErrorDetails
+	for i := 0; i < numVmsToDrain; i++ {
+		errDetails := <-errChan
+		if errDetails != nil && failedDrain == nil {
+			failedDrain = errDetails
+		}
+	}
+	if failedDrain != nil {
+		return errors.Wrapf(failedDrain.Error, "Node %q failed to drain with error", failedDrain.Name)
+	}
+
+	return nil
+}
+
+func (s *scaler) printScaleTargetEqualsExisting(currentNodeCount int) {
+	var printNodes bool
+	trailingChar := "."
+	if s.nodes != nil {
+		printNodes = true
+		trailingChar = ":"
+	}
+	s.logger.Infof("Node pool %s is already at the desired count %d%s", s.agentPoolName, s.count, trailingChar)
+	if printNodes {
+		operations.FprintNodes(s.output, s.nodes)
+		numNodesFromK8sAPI := len(s.nodes)
+		if currentNodeCount != numNodesFromK8sAPI {
+			s.logger.Warnf("There are %d nodes named \"*%s*\" in the Kubernetes cluster, but there are %d VMs named \"*%s*\" in the resource group %s\n", numNodesFromK8sAPI, s.agentPoolName, currentNodeCount, s.agentPoolName, s.resourceGroup)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cluster
+
+import (
+	"context"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestScale(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	cs := loadContainerService(t)
+	clusterID := cs.Properties.GetClusterID()
+	client := &armhelpers.MockAKSEngineClient{
+		FakeListVirtualMachineResult: func() []compute.VirtualMachine {
+			var vms []compute.VirtualMachine
+			for _, name := range []string{"k8s-agentpool1-" + clusterID + "-0", "k8s-agentpool1-" + clusterID + "-1", "k8s-agentpool2-" + clusterID + "-0"} {
+				vms = append(vms, compute.VirtualMachine{
+					Name: to.StringPtr(name),
+					Tags: map[string]*string{"poolName": to.StringPtr(name[4:14]), "resourceNameSuffix": to.StringPtr(clusterID)},
+				})
+			}
+			return vms
+		},
+	}
+	scale := func(poolName string, count int) (*ScaleResponse, error) {
+		return Scale(context.Background(), &ScaleRequest{
+			Options:          Options{Client: client, Output: ioutil.Discard},
+			ContainerService: cs,
+			ResourceGroup:    "rg1",
+			AgentPoolName:    poolName,
+			Count:            count,
+		})
+	}
+
+	_, err := scale("agentpool1", 0)
+	g.Expect(err).To(MatchError("the desired count of nodes must be at least 1"))
+	_, err = scale("", 3)
+	g.Expect(err).To(MatchError("the name of the node pool to scale is required if more than one agent pool is defined in the container service"))
+	_, err = scale("agentpool3", 3)
+	g.Expect(err).To(MatchError("node pool agentpool3 was not found in the deployed api model"))
+	withoutPools := loadContainerService(t)
+	withoutPools.Properties.AgentPoolProfiles = nil
+	_, err = Scale(context.Background(), &ScaleRequest{
+		Options:          Options{Client: client, Output: ioutil.Discard},
+		ContainerService: withoutPools,
+		ResourceGroup:    "rg1",
+		Count:            3,
+	})
+	g.Expect(err).To(MatchError("No node pools found to scale"))
+
+	resp, err := scale("agentpool2", 1)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(resp.AgentPoolIndex).To(Equal(1))
+	g.Expect(resp.CurrentCount).To(Equal(1))
+	g.Expect(resp.DeploymentName).To(BeEmpty())
+
+	resp, err = scale("agentpool1", 1)
+	g.Expect(errors.Is(err, ErrAPIServerURLRequired)).To(BeTrue())
+	g.Expect(resp.CurrentCount).To(Equal(2))
+	g.Expect(resp.NodesRemoved).To(BeEmpty())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	resp, err = Scale(ctx, &ScaleRequest{
+		Options:          Options{Client: client, Output: ioutil.Discard},
+		ContainerService: cs,
+		ResourceGroup:    "rg1",
+		AgentPoolName:    "agentpool1",
+		Count:            3,
+	})
+	g.Expect(err).To(MatchError("interrupted before deploying the template of node pool agentpool1"))
+	g.Expect(resp.DeploymentName).To(BeEmpty())
+}
+
+func TestGetVMASTemplateCountAndOffset(t *testing.T) {
+	cases := []struct {
+		name           string
+		indexes        []int
+		desiredCount   int
+		expectedCount  int
+		expectedOffset int
+	}{
+		{
+			name:           "NoGaps",
+			indexes:        []int{0, 1, 2},
+			desiredCount:   5,
+			expectedCount:  5,
+			expectedOffset: 3,
+		},
+		{
+			name:           "SingleVM",
+			indexes:        []int{0},
+			desiredCount:   2,
+			expectedCount:  2,
+			expectedOffset: 1,
+		},
+		{
+			name:           "GapsLeftByScaleDown",
+			indexes:        []int{0, 3, 4},
+			desiredCount:   4,
+			expectedCount:  6,
+			expectedOffset: 5,
+		},
+		{
+			name:           "LowestIndexesRemoved",
+			indexes:        []int{2, 3},
+			desiredCount:   3,
+			expectedCount:  5,
+			expectedOffset: 4,
+		},
+	}
+
+	for _, tc := range cases {
+		c := tc
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			count, offset := getVMASTemplateCountAndOffset(c.indexes, c.desiredCount)
+			if count != c.expectedCount || offset != c.expectedOffset {
+				t.Fatalf("expected count %d and offset %d, but instead got count %d and offset %d", c.expectedCount, c.expectedOffset, count, offset)
+			}
+			// The template creates the VMs with indexes offset to count-1
+			if created := count - offset; created != c.desiredCount-len(c.indexes) {
+				t.Fatalf("expected the template to create %d VMs, but it creates %d", c.desiredCount-len(c.indexes), created)
+			}
+		})
+	}
+}
+
+func TestVmInVMASAgentPool(t *testing.T) {
+	tags := map[string]*string{}
+
+	cases := []struct {
+		s        *scaler
+		expected bool
+		name     string
+		vmName   string
+	}{
+		{
+			s: &scaler{
+				nameSuffix:     "39573225",
+				agentPoolIndex: 0,
+				agentPoolName:  "linuxpool",
+				agentPool: &api.AgentPoolProfile{
+					Name:                "linuxpool",
+					OSType:              "Linux",
+					AvailabilityProfile: "AvailabilitySet",
+				},
+				containerService: &api.ContainerService{
+					Properties: &api.Properties{
+						ClusterID: "39573225",
+					},
+				},
+			},
+			expected: false,
+			name:     "linux VM is not in linux pool to scale",
+			vmName:   "k8s-linuxpool2-39573225-0",
+		},
+		{
+			s: &scaler{
+				nameSuffix:     "39573225",
+				agentPoolIndex: 1,
+				agentPoolName:  "linuxpool2",
+				agentPool: &api.AgentPoolProfile{
+					Name:                "linuxpool2",
+					OSType:              "Linux",
+					AvailabilityProfile: "AvailabilitySet",
+				},
+				containerService: &api.ContainerService{
+					Properties: &api.Properties{
+						ClusterID: "39573225",
+					},
+				},
+			},
+			expected: true,
+			name:     "linux VM is in linux pool to scale",
+			vmName:   "k8s-linuxpool2-39573225-1",
+		},
+		{
+			s: &scaler{
+				nameSuffix:     "39573225",
+				agentPoolIndex: 2,
+				agentPoolName:  "windowspool",
+				agentPool: &api.AgentPoolProfile{
+					Name:                "windowspool",
+					OSType:              "Windows",
+					AvailabilityProfile: "AvailabilitySet",
+				},
+				containerService: &api.ContainerService{
+					Properties: &api.Properties{
+						ClusterID: "39573225",
+					},
+				},
+			},
+			expected: false,
+			name:     "windows VM is not in windows pool to scale",
+			vmName:   "3957k8s030",
+		},
+		{
+			s: &scaler{
+				nameSuffix:     "39573225",
+				agentPoolIndex: 3,
+				agentPoolName:  "windowspool2",
+				agentPool: &api.AgentPoolProfile{
+					Name:                "windowspool2",
+					OSType:              "Windows",
+					AvailabilityProfile: "AvailabilitySet",
+				},
+				containerService: &api.ContainerService{
+					Properties: &api.Properties{
+						ClusterID: "39573225",
+					},
+				},
+			},
+			expected: true,
+			name:     "windows VM is in windows pool to scale",
+			vmName:   "3957k8s031",
+		},
+	}
+
+	for _, tc := range cases {
+		c := tc
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			ret := c.s.vmInVMASAgentPool(c.vmName, tags)
+			if ret != c.expected {
+				t.Errorf("expected %t to be %t", ret, c.expected)
+			}
+		})
+	}
+}
+
+func TestScaleDownVMSS(t *testing.T) {
+	scaleSetVM := func(computerName, instanceID string) compute.VirtualMachineScaleSetVM {
+		return compute.VirtualMachineScaleSetVM{
+			InstanceID: to.StringPtr(instanceID),
+			VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
+				OsProfile: &compute.OSProfile{ComputerName: to.StringPtr(computerName)},
+			},
+		}
+	}
+	newScaler := func(client *armhelpers.MockAKSEngineClient, nodesToRemove []string) *scaler {
+		client.FakeListVirtualMachineScaleSetVMsResult = func() []compute.VirtualMachineScaleSetVM {
+			return []compute.VirtualMachineScaleSetVM{
+				scaleSetVM("k8s-agentpool-12345678-vmss000001", "1"),
+				scaleSetVM("k8s-agentpool-12345678-vmss000000", "0"),
+				scaleSetVM("k8s-agentpool-12345678-vmss000002", "2"),
+			}
+		}
+		return &scaler{
+			operation: &operation{
+				client: client,
+				logger: log.NewEntry(log.New()),
+				output: ioutil.Discard,
+			},
+			resourceGroup: "rg",
+			agentPoolName: "agentpool",
+			count:         1,
+			nodesToRemove: nodesToRemove,
+		}
+	}
+
+	var mu sync.Mutex
+	cordoned := []string{}
+	client := &armhelpers.MockAKSEngineClient{MockKubernetesClient: &armhelpers.MockKubernetesClient{}}
+	client.MockKubernetesClient.UpdateNodeFunc = func(node *v1.Node) (*v1.Node, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		cordoned = append(cordoned, node.Name)
+		return node, nil
+	}
+	client.MockKubernetesClient.GetNodeFunc = func(name string) (*v1.Node, error) {
+		node := &v1.Node{}
+		node.Name = name
+		return node, nil
+	}
+	s := newScaler(client, nil)
+	if err := s.scaleDownVMSS(context.Background(), "k8s-agentpool-12345678-vmss", 3); err != nil {
+		t.Fatalf("unexpected error scaling down the VMSS: %s", err)
+	}
+	sort.Strings(cordoned)
+	expected := []string{"k8s-agentpool-12345678-vmss000000", "k8s-agentpool-12345678-vmss000001"}
+	if !reflect.DeepEqual(cordoned, expected) {
+		t.Errorf("expected the oldest nodes %v to be cordoned, got %v", expected, cordoned)
+	}
+	removed := append([]string{}, s.response.NodesRemoved...)
+	sort.Strings(removed)
+	if !reflect.DeepEqual(removed, expected) {
+		t.Errorf("expected the removed nodes %v in the response, got %v", expected, removed)
+	}
+
+	cordoned = []string{}
+	if err := newScaler(client, []string{"k8s-agentpool-12345678-vmss000002", "k8s-agentpool-12345678-vmss000000"}).scaleDownVMSS(context.Background(), "k8s-agentpool-12345678-vmss", 3); err != nil {
+		t.Fatalf("unexpected error scaling down the VMSS: %s", err)
+	}
+	sort.Strings(cordoned)
+	expected = []string{"k8s-agentpool-12345678-vmss000000", "k8s-agentpool-12345678-vmss000002"}
+	if !reflect.DeepEqual(cordoned, expected) {
+		t.Errorf("expected the nodes to remove %v to be cordoned, got %v", expected, cordoned)
+	}
+
+	client.FailGetKubernetesClient = true
+	if err := newScaler(client, nil).scaleDownVMSS(context.Background(), "k8s-agentpool-12345678-vmss", 3); err == nil {
+		t.Errorf("expected an error when the nodes cannot be drained")
+	}
+
+	s = newScaler(client, nil)
+	s.count = 3
+	if err := s.scaleDownVMSS(context.Background(), "k8s-agentpool-12345678-vmss", 4); err != nil {
+		t.Errorf("expected no error when the VMSS has no VM to remove, got %s", err)
+	}
+}
+
+func TestDrainNodes(t *testing.T) {
+	client := &armhelpers.MockAKSEngineClient{MockKubernetesClient: &armhelpers.MockKubernetesClient{}}
+	client.MockKubernetesClient.GetNodeFunc = func(name string) (*v1.Node, error) {
+		// the second drain fails after drainNodes received the first failure
+		if name == "k8s-agentpool-12345678-vmss000001" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return nil, errors.Errorf("GetNode %s failed", name)
+	}
+	s := &scaler{
+		operation: &operation{
+			client: client,
+			logger: log.NewEntry(log.New()),
+			output: ioutil.Discard,
+		},
+	}
+	err := s.drainNodes([]string{"k8s-agentpool-12345678-vmss000000", "k8s-agentpool-12345678-vmss000001"})
+	if err == nil || err.Error() != "Node \"k8s-agentpool-12345678-vmss000000\" failed to drain with error: GetNode k8s-agentpool-12345678-vmss000000 failed" {
+		t.Errorf("expected the first drain failure, got %v", err)
+	}
+	// the second failure would be sent on a closed channel, and panic, if drainNodes returned before receiving it
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestAddedNodes(t *testing.T) {
+	t.Parallel()
+
+	node := func(name string) v1.Node {
+		n := v1.Node{}
+		n.Name = name
+		return n
+	}
+	before := []v1.Node{node("k8s-agentpool-12345678-0"), node("k8s-agentpool-12345678-1")}
+	after := []v1.Node{node("k8s-agentpool-12345678-0"), node("k8s-agentpool-12345678-1"), node("k8s-agentpool-12345678-2")}
+
+	added := addedNodes(before, after)
+	if !reflect.DeepEqual(added, []string{"k8s-agentpool-12345678-2"}) {
+		t.Fatalf("expected node k8s-agentpool-12345678-2 to be added, got %v", added)
+	}
+	if added = addedNodes(after, before); len(added) != 0 {
+		t.Fatalf("expected no node to be added, got %v", added)
+	}
+}
@@ -0,0 +1,366 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cluster
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/api/common"
+	"github.com/Azure/aks-engine/pkg/armhelpers/utils"
+	"github.com/Azure/aks-engine/pkg/engine"
+	"github.com/Azure/aks-engine/pkg/operations/kubernetesupgrade"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+)
+
+// windowsImageSkuReleaseRegex matches the release suffix of the sku of the aks-engine Windows VHDs
+var windowsImageSkuReleaseRegex = regexp.MustCompile(`-[0-9]{4}$`)
+
+// windowsVMSSNameRegex matches the names of the VMSS of the Windows node pools
+var windowsVMSSNameRegex = regexp.MustCompile(`^[0-9]{4}k8s[0]+`)
+
+// UpgradeRequest is a request to upgrade the Kubernetes version of a cluster
+type UpgradeRequest struct {
+	Options
+	// ContainerService is the api model of the cluster, loaded and validated as an update.
+	// Upgrade sets its orchestrator version, and the images and settings the upgraded version requires
+	ContainerService *api.ContainerService
+	// SubscriptionID is the subscription of the cluster
+	SubscriptionID string
+	// ResourceGroup is the resource group of the cluster
+	ResourceGroup string
+	// UpgradeVersion is the Kubernetes version to upgrade to
+	UpgradeVersion string
+	// KubeConfig is the kubeconfig of the cluster, generated from the api model if empty
+	KubeConfig string
+	// Force allows upgrades to the same version and downgrades
+	Force bool
+	// ControlPlaneOnly upgrades the control plane VMs only, not the node pools
+	ControlPlaneOnly bool
+	// UpgradeWindowsVHD upgrades the image of the Windows nodes to the VHD associated with this aks-engine version
+	UpgradeWindowsVHD bool
+	// ResetImagePins resets the addon and component images pinned in the api model to their defaults
+	ResetImagePins bool
+	// StepTimeout is how long to wait for each VM to be upgraded, the default of kubernetesupgrade if nil
+	StepTimeout *time.Duration
+	// CordonDrainTimeout is how long to wait for each node to be cordoned and drained, the default of kubernetesupgrade if nil
+	CordonDrainTimeout *time.Duration
+}
+
+// UpgradeResponse is the result of upgrading a cluster
+type UpgradeResponse struct {
+	// CurrentVersion is the Kubernetes version of the cluster before the upgrade
+	CurrentVersion string
+	// ImageDrift are the images pinned in the api model that differ from the defaults of the upgraded version
+	ImageDrift []api.ImageDrift
+	// NodesUpgraded are the names of the nodes the upgrade recreated, empty with WhatIf
+	NodesUpgraded []string
+}
+
+// Upgrade upgrades the control plane and the node pools of a cluster to a Kubernetes version, one node at a time.
+// The caller saves the api model once the upgrade succeeded.
+// Canceling ctx interrupts the upgrade between two nodes, with an *operations.InterruptedError;
+// the nodes upgraded so far are complete and running the same upgrade again skips them
+func Upgrade(ctx context.Context, req *UpgradeRequest) (*UpgradeResponse, error) {
+	op, err := newOperation(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	cs := req.ContainerService
+	if cs == nil {
+		return nil, errors.New("the api model of the cluster is required")
+	}
+	if err = validateUpgradable(cs); err != nil {
+		return nil, err
+	}
+	if err = validateUpgradeVersion(cs, req.UpgradeVersion, req.Force); err != nil {
+		return nil, err
+	}
+
+	cordonDrainTimeout := req.CordonDrainTimeout
+	// Set 60 minutes cordonDrainTimeout for Azure Stack Cloud to give it enough time to move around resources during Node Drain,
+	// especially disk detach/attach operations. We still honor the user's input.
+	if cordonDrainTimeout == nil && cs.Properties.IsAzureStackCloud() {
+		timeout := time.Duration(60) * time.Minute
+		cordonDrainTimeout = &timeout
+	}
+	if req.UpgradeWindowsVHD {
+		op.upgradeWindowsImages(cs)
+	}
+	op.setUpgradeDefaults(cs, req.UpgradeVersion)
+
+	// The cluster-init component is a cluster create-only feature, temporarily disable if enabled
+	components := cs.Properties.OrchestratorProfile.KubernetesConfig.Components
+	clusterInit := api.GetComponentsIndexByName(components, common.ClusterInitComponentName)
+	disableClusterInit := clusterInit > -1 && components[clusterInit].IsEnabled()
+	if disableClusterInit {
+		components[clusterInit].Enabled = to.BoolPtr(false)
+	}
+
+	resp := &UpgradeResponse{
+		CurrentVersion: cs.Properties.OrchestratorProfile.OrchestratorVersion,
+		ImageDrift:     cs.GetImageDrift(req.UpgradeVersion),
+	}
+	cs.Properties.OrchestratorProfile.OrchestratorVersion = req.UpgradeVersion
+
+	// custom addons are upgraded too, their manifests are read from their sources again
+	if err = engine.RefreshCustomAddons(cs); err != nil {
+		return nil, errors.Wrap(err, "resolving custom addons")
+	}
+
+	op.logImageDrift(resp.ImageDrift, req.UpgradeVersion)
+	if req.ResetImagePins {
+		cs.ResetImageDrift(resp.ImageDrift)
+		op.logger.Infoln("Pinned addon and component images reset to their defaults")
+	} else if len(resp.ImageDrift) > 0 {
+		op.logger.Warnln("Use --reset-image-pins to reset the pinned images to their defaults")
+	}
+
+	if cs.Properties.IsAzureStackCloud() {
+		if err = op.validateOSBaseImage(ctx, cs); err != nil {
+			return nil, errors.Wrap(err, "validating OS base images required by the api model")
+		}
+	}
+
+	kubeConfig := req.KubeConfig
+	if kubeConfig == "" {
+		if kubeConfig, err = engine.GenerateKubeConfig(cs.Properties, cs.Location); err != nil {
+			return nil, errors.Wrap(err, "generating kubeconfig")
+		}
+	}
+
+	//allows to identify VMs in the resource group that belong to this cluster.
+	nameSuffix := cs.Properties.GetClusterID()
+	op.logger.Infof("Upgrading cluster with name suffix: %s", nameSuffix)
+
+	agentPoolsToUpgrade := map[string]bool{kubernetesupgrade.MasterPoolName: true}
+	for _, agentPool := range cs.Properties.AgentPoolProfiles {
+		agentPoolsToUpgrade[agentPool.Name] = true
+	}
+
+	upgradeCluster := kubernetesupgrade.UpgradeCluster{
+		Translator:         op.translator,
+		Logger:             op.logger,
+		Client:             op.client,
+		StepTimeout:        req.StepTimeout,
+		CordonDrainTimeout: cordonDrainTimeout,
+		ClusterTopology: kubernetesupgrade.ClusterTopology{
+			SubscriptionID:      req.SubscriptionID,
+			ResourceGroup:       req.ResourceGroup,
+			DataModel:           cs,
+			NameSuffix:          nameSuffix,
+			AgentPoolsToUpgrade: agentPoolsToUpgrade,
+			IsVMSSToBeUpgraded:  isVMSSNameInAgentPoolsArray,
+		},
+		Force:            req.Force,
+		ControlPlaneOnly: req.ControlPlaneOnly,
+		WhatIf:           op.whatIf,
+		WhatIfOutput:     op.output,
+		CurrentVersion:   resp.CurrentVersion,
+	}
+	if err = upgradeCluster.UpgradeCluster(ctx, op.client, kubeConfig, op.buildTag); err != nil {
+		return resp, err
+	}
+	if op.whatIf {
+		return resp, nil
+	}
+	resp.NodesUpgraded = upgradedNodes(upgradeCluster.ClusterTopology)
+
+	// Restore the original cluster-init component enabled value, if it was disabled during upgrade
+	if disableClusterInit {
+		components[clusterInit].Enabled = to.BoolPtr(true)
+	}
+	return resp, nil
+}
+
+// validateUpgradable ensures there aren't known-breaking api model configurations
+func validateUpgradable(cs *api.ContainerService) error {
+	if cs.Properties.MasterProfile.AvailabilityProfile == api.VirtualMachineScaleSets {
+		return errors.Errorf("clusters with a VMSS control plane are not upgradable using `aks-engine upgrade`")
+	}
+	if cs.Properties.OrchestratorProfile != nil &&
+		cs.Properties.OrchestratorProfile.KubernetesConfig != nil &&
+		to.Bool(cs.Properties.OrchestratorProfile.KubernetesConfig.EnableEncryptionWithExternalKms) &&
+		to.Bool(cs.Properties.OrchestratorProfile.KubernetesConfig.UseManagedIdentity) &&
+		cs.Properties.OrchestratorProfile.KubernetesConfig.UserAssignedID == "" {
+		return errors.Errorf("clusters with enableEncryptionWithExternalKms=true and system-assigned identity are not upgradable using `aks-engine upgrade`")
+	}
+	return nil
+}
+
+// validateUpgradeVersion ensures version is a semver string and, unless forced, an available upgrade of the cluster
+func validateUpgradeVersion(cs *api.ContainerService, version string, force bool) error {
+	if _, err := semver.Make(version); err != nil {
+		return errors.Wrapf(err, "Invalid upgrade version '%s', not a semver string", version)
+	}
+	if force {
+		return nil
+	}
+	// Get available upgrades for container service.
+	orchestratorInfo, err := api.GetOrchestratorVersionProfile(cs.Properties.OrchestratorProfile, cs.Properties.HasWindows(), cs.Properties.IsAzureStackCloud())
+	if err != nil {
+		return errors.Wrap(err, "error getting list of available upgrades")
+	}
+	for _, up := range orchestratorInfo.Upgrades {
+		if up.OrchestratorVersion == version {
+			return nil
+		}
+	}
+	currentVersion := cs.Properties.OrchestratorProfile.OrchestratorVersion
+	return errors.Wrap(
+		errors.Errorf("upgrading from Kubernetes version %s to version %s is not supported. To see a list of available upgrades, use 'aks-engine get-versions --version %s'", currentVersion, version, currentVersion),
+		"Invalid upgrade target version. Consider using --force if you really want to proceed")
+}
+
+// upgradeWindowsImages sets the Windows VHD associated with the aks-engine version as the image of the Windows nodes
+func (op *operation) upgradeWindowsImages(cs *api.ContainerService) {
+	windowsProfile := cs.Properties.WindowsProfile
+	if windowsProfile == nil {
+		return
+	}
+	currentImage := api.AzureOSImageConfig{
+		ImagePublisher: windowsProfile.WindowsPublisher,
+		ImageOffer:     windowsProfile.WindowsOffer,
+		ImageSku:       windowsProfile.WindowsSku,
+	}
+	if imageConfig, ok := getUpgradeWindowsImageConfig(currentImage); ok {
+		windowsProfile.ImageVersion = imageConfig.ImageVersion
+		windowsProfile.WindowsSku = imageConfig.ImageSku
+	}
+	// Windows agent pools overriding the image of the windowsProfile are upgraded separately
+	for _, pool := range cs.Properties.AgentPoolProfiles {
+		if !pool.HasWindowsImageOverride() {
+			continue
+		}
+		currentImage := pool.GetWindowsImageConfig(windowsProfile)
+		if imageConfig, ok := getUpgradeWindowsImageConfig(currentImage); ok {
+			op.logger.Infof("Upgrading the Windows image of agent pool %s to %s version %s", pool.Name, imageConfig.ImageSku, imageConfig.ImageVersion)
+			pool.WindowsImageVersion = imageConfig.ImageVersion
+			pool.WindowsSku = imageConfig.ImageSku
+		} else {
+			op.logger.Infof("Keeping the Windows image of agent pool %s, no Windows VHD of offer %s is associated with this aks-engine version", pool.Name, currentImage.ImageOffer)
+		}
+	}
+}
+
+// setUpgradeDefaults replaces the settings of an Azure Stack cluster that the upgraded version no longer supports
+func (op *operation) setUpgradeDefaults(cs *api.ContainerService, version string) {
+	if !cs.Properties.IsAzureStackCloud() {
+		return
+	}
+	// Update the masterProfile and agentPoolProfiles distro for AzureStackCloud to use aks-ubuntu-20.04 instead of aks-ubuntu-16.04 or aks-ubuntu-18.04
+	if cs.Properties.MasterProfile.Distro == api.AKSUbuntu1604 || cs.Properties.MasterProfile.Distro == api.AKSUbuntu1804 {
+		op.logger.Infof("Distro '%s' is not longer supported on Azure Stack Hub, overwriting master profile distro to '%s'", cs.Properties.MasterProfile.Distro, api.AKSUbuntu2004)
+		cs.Properties.MasterProfile.Distro = api.AKSUbuntu2004
+	}
+	for _, app := range cs.Properties.AgentPoolProfiles {
+		if app.Distro == api.AKSUbuntu1604 || app.Distro == api.AKSUbuntu1804 {
+			op.logger.Infof("Distro '%s' is not longer supported on Azure Stack Hub, overwriting agent pool profile %s distro to '%s'", app.Distro, app.Name, api.AKSUbuntu2004)
+			app.Distro = api.AKSUbuntu2004
+		}
+	}
+
+	kubernetesConfig := cs.Properties.OrchestratorProfile.KubernetesConfig
+	// Enforce UseCloudControllerManager for Kubernetes 1.21+ on Azure Stack cloud
+	if common.IsKubernetesVersionGe(version, "1.21.0") {
+		op.logger.Infoln("The in-tree cloud provider is not longer supported on Azure Stack Hub for v1.21+ clusters, overwriting UseCloudControllerManager to 'true'")
+		kubernetesConfig.UseCloudControllerManager = to.BoolPtr(true)
+	}
+	// Only containerd runtime is allowed for Kubernetes 1.24+ on Azure Stack cloud
+	if strings.EqualFold(kubernetesConfig.ContainerRuntime, "docker") && common.IsKubernetesVersionGe(version, "1.24.0") {
+		op.logger.Infoln("The docker runtime is no longer supported for v1.24+ clusters, overwriting ContainerRuntime to 'containerd'")
+		kubernetesConfig.ContainerRuntime = "containerd"
+	}
+}
+
+// logImageDrift logs the pinned images of drift and what upgrade does with them
+func (op *operation) logImageDrift(drift []api.ImageDrift, version string) {
+	for _, d := range drift {
+		if d.Kept {
+			op.logger.Warnf("The %s image of %s %s is pinned to %s by a custom image property of kubernetesConfig: the upgrade deploys %s, later operations deploy the pinned image again",
+				d.Container, d.Kind, d.Name, d.Image, d.Default)
+		} else {
+			op.logger.Warnf("The %s image of %s %s is pinned to %s: the upgrade replaces it with %s", d.Container, d.Kind, d.Name, d.Image, d.Default)
+		}
+		if d.Incompatible != "" {
+			op.logger.Warnf("The pinned %s image of %s %s is not compatible with Kubernetes %s: %s", d.Container, d.Kind, d.Name, version, d.Incompatible)
+		}
+	}
+}
+
+// isVMSSNameInAgentPoolsArray is a helper func to filter out any VMSS in the cluster resource group
+// that are not participating in the aks-engine-created Kubernetes cluster
+func isVMSSNameInAgentPoolsArray(vmss string, cs *api.ContainerService) bool {
+	for _, pool := range cs.Properties.AgentPoolProfiles {
+		if pool.AvailabilityProfile == api.VirtualMachineScaleSets {
+			if pool.OSType == api.Windows {
+				if windowsVMSSNameRegex.FindString(vmss) != "" {
+					return true
+				}
+			} else {
+				if poolName, _, _ := utils.VmssNameParts(vmss); poolName == pool.Name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// getUpgradeWindowsImageConfig returns the Windows VHD associated with this aks-engine version
+// for the publisher, offer and sku family of the current image. Images of another sku family,
+// e.g. Windows Server 2022 or a Datacenter image with the desktop experience, are kept.
+func getUpgradeWindowsImageConfig(current api.AzureOSImageConfig) (api.AzureOSImageConfig, bool) {
+	for _, imageConfig := range []api.AzureOSImageConfig{
+		api.AKSWindowsServer2019ContainerDOSImageConfig,
+		api.AKSWindowsServer2019OSImageConfig,
+		api.WindowsServer2019OSImageConfig,
+	} {
+		if current.ImagePublisher == imageConfig.ImagePublisher && current.ImageOffer == imageConfig.ImageOffer &&
+			getWindowsImageSkuFamily(current.ImageSku) == getWindowsImageSkuFamily(imageConfig.ImageSku) {
+			return imageConfig, true
+		}
+	}
+	return api.AzureOSImageConfig{}, false
+}
+
+// getWindowsImageSkuFamily returns the sku of a Windows image without the release suffix
+// of the aks-engine VHDs, e.g. 2019-datacenter-core-ctrd for 2019-datacenter-core-ctrd-2104
+func getWindowsImageSkuFamily(sku string) string {
+	return strings.ToLower(windowsImageSkuReleaseRegex.ReplaceAllString(sku, ""))
+}
+
+// upgradedNodes returns the names of the nodes of the cluster topology, which a successful upgrade upgraded
+func upgradedNodes(topology kubernetesupgrade.ClusterTopology) []string {
+	var nodes []string
+	if topology.MasterVMs != nil {
+		for _, vm := range *topology.MasterVMs {
+			nodes = append(nodes, to.String(vm.Name))
+		}
+	}
+	pools := make([]string, 0, len(topology.AgentPools))
+	for pool := range topology.AgentPools {
+		pools = append(pools, pool)
+	}
+	sort.Strings(pools)
+	for _, pool := range pools {
+		if vms := topology.AgentPools[pool].AgentVMs; vms != nil {
+			for _, vm := range *vms {
+				nodes = append(nodes, to.String(vm.Name))
+			}
+		}
+	}
+	for _, scaleSet := range topology.AgentPoolScaleSetsToUpgrade {
+		for _, vm := range scaleSet.VMsToUpgrade {
+			nodes = append(nodes, vm.Name)
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,439 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/api/common"
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/operations/kubernetesupgrade"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestUpgrade(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	cs := loadContainerService(t)
+	currentVersion := cs.Properties.OrchestratorProfile.OrchestratorVersion
+	var output bytes.Buffer
+	client := &armhelpers.MockAKSEngineClient{}
+	resp, err := Upgrade(context.Background(), &UpgradeRequest{
+		Options: Options{
+			Client: client,
+			Logger: log.NewEntry(log.New()),
+			Output: &output,
+			WhatIf: true,
+		},
+		ContainerService: cs,
+		SubscriptionID:   "00000000-0000-0000-0000-000000000000",
+		ResourceGroup:    "rg",
+		UpgradeVersion:   currentVersion,
+		Force:            true,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(resp.CurrentVersion).To(Equal(currentVersion))
+	g.Expect(resp.NodesUpgraded).To(BeEmpty())
+	g.Expect(cs.Properties.OrchestratorProfile.OrchestratorVersion).To(Equal(currentVersion))
+	g.Expect(output.String()).NotTo(BeEmpty())
+
+	_, err = Upgrade(context.Background(), &UpgradeRequest{Options: Options{Client: client}})
+	g.Expect(err).To(MatchError("the api model of the cluster is required"))
+
+	_, err = Upgrade(context.Background(), &UpgradeRequest{
+		Options:          Options{Client: client},
+		ContainerService: loadContainerService(t),
+		UpgradeVersion:   "1.10.13",
+	})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("Invalid upgrade target version"))
+}
+
+func TestValidateUpgradable(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	cs := api.CreateMockContainerService("testcluster", "", 3, 2, false)
+	g.Expect(validateUpgradable(cs)).To(Succeed())
+
+	cs.Properties.MasterProfile.AvailabilityProfile = api.VirtualMachineScaleSets
+	g.Expect(validateUpgradable(cs)).To(MatchError("clusters with a VMSS control plane are not upgradable using `aks-engine upgrade`"))
+
+	cs = api.CreateMockContainerService("testcluster", "", 3, 2, false)
+	cs.Properties.OrchestratorProfile.KubernetesConfig.EnableEncryptionWithExternalKms = to.BoolPtr(true)
+	cs.Properties.OrchestratorProfile.KubernetesConfig.UseManagedIdentity = to.BoolPtr(true)
+	g.Expect(validateUpgradable(cs)).To(MatchError("clusters with enableEncryptionWithExternalKms=true and system-assigned identity are not upgradable using `aks-engine upgrade`"))
+
+	cs.Properties.OrchestratorProfile.KubernetesConfig.UserAssignedID = "identity"
+	g.Expect(validateUpgradable(cs)).To(Succeed())
+}
+
+func TestValidateUpgradeVersion(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	// the oldest supported version has upgrades to newer supported versions
+	versions := common.GetAllSupportedKubernetesVersions(false, false, false)
+	fromVersion := versions[0]
+	cs := api.CreateMockContainerService("testcluster", fromVersion, 3, 2, false)
+	orchestratorInfo, err := api.GetOrchestratorVersionProfile(cs.Properties.OrchestratorProfile, false, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(orchestratorInfo.Upgrades).NotTo(BeEmpty())
+	toVersion := orchestratorInfo.Upgrades[0].OrchestratorVersion
+
+	g.Expect(validateUpgradeVersion(cs, toVersion, false)).To(Succeed())
+
+	err = validateUpgradeVersion(cs, fromVersion, false)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("upgrading from Kubernetes version %s to version %s is not supported", fromVersion, fromVersion))
+	g.Expect(err.Error()).To(ContainSubstring("Consider using --force"))
+
+	err = validateUpgradeVersion(cs, "1.10.13", false)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("upgrading from Kubernetes version %s to version 1.10.13 is not supported", fromVersion))
+
+	// force allows upgrades to the same version and downgrades
+	g.Expect(validateUpgradeVersion(cs, fromVersion, true)).To(Succeed())
+	g.Expect(validateUpgradeVersion(cs, "1.10.13", true)).To(Succeed())
+
+	err = validateUpgradeVersion(cs, "1.10", true)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("Invalid upgrade version '1.10', not a semver string"))
+}
+
+func TestIsVMSSNameInAgentPoolsArray(t *testing.T) {
+	cases := []struct {
+		vmssName string
+		cs       *api.ContainerService
+		expected bool
+		name     string
+	}{
+		{
+			vmssName: "k8s-agentpool1-41325566-vmss",
+			cs: &api.ContainerService{
+				Properties: &api.Properties{
+					OrchestratorProfile: &api.OrchestratorProfile{
+						OrchestratorType:    api.Kubernetes,
+						OrchestratorVersion: "1.15.4",
+						KubernetesConfig: &api.KubernetesConfig{
+							ContainerRuntime: api.Docker,
+						},
+					},
+					AgentPoolProfiles: []*api.AgentPoolProfile{
+						{
+							Name:                "agentpool1",
+							Count:               1,
+							AvailabilityProfile: api.VirtualMachineScaleSets,
+						},
+					},
+				},
+			},
+			expected: true,
+			name:     "vmss is in the api model spec",
+		},
+		{
+			vmssName: "my-vmss",
+			cs: &api.ContainerService{
+				Properties: &api.Properties{
+					OrchestratorProfile: &api.OrchestratorProfile{
+						OrchestratorType:    api.Kubernetes,
+						OrchestratorVersion: "1.15.4",
+						KubernetesConfig: &api.KubernetesConfig{
+							ContainerRuntime: api.Docker,
+						},
+					},
+					AgentPoolProfiles: []*api.AgentPoolProfile{
+						{
+							Name:                "agentpool1",
+							Count:               1,
+							AvailabilityProfile: api.VirtualMachineScaleSets,
+						},
+					},
+				},
+			},
+			expected: false,
+			name:     "vmss unrecognized",
+		},
+		{
+			vmssName: "k8s-frontendpool-41325566-vmss",
+			cs: &api.ContainerService{
+				Properties: &api.Properties{
+					OrchestratorProfile: &api.OrchestratorProfile{
+						OrchestratorType:    api.Kubernetes,
+						OrchestratorVersion: "1.15.4",
+						KubernetesConfig: &api.KubernetesConfig{
+							ContainerRuntime: api.Docker,
+						},
+					},
+					AgentPoolProfiles: []*api.AgentPoolProfile{
+						{
+							Name:                "frontendpool",
+							Count:               30,
+							AvailabilityProfile: api.VirtualMachineScaleSets,
+						},
+						{
+							Name:                "backendpool",
+							Count:               7,
+							AvailabilityProfile: api.VirtualMachineScaleSets,
+						},
+						{
+							Name:                "canary",
+							Count:               5,
+							AvailabilityProfile: api.VirtualMachineScaleSets,
+						},
+					},
+				},
+			},
+			expected: true,
+			name:     "multiple pools, frontendpool vmss is in spec",
+		},
+		{
+			vmssName: "k8s-backendpool-41325566-vmss",
+			cs: &api.ContainerService{
+				Properties: &api.Properties{
+					OrchestratorProfile: &api.OrchestratorProfile{
+						OrchestratorType:    api.Kubernetes,
+						OrchestratorVersion: "1.15.4",
+						KubernetesConfig: &api.KubernetesConfig{
+							ContainerRuntime: api.Docker,
+						},
+					},
+					AgentPoolProfiles: []*api.AgentPoolProfile{
+						{
+							Name:                "frontendpool",
+							Count:               30,
+							AvailabilityProfile: api.VirtualMachineScaleSets,
+						},
+						{
+							Name:                "backendpool",
+							Count:               7,
+							AvailabilityProfile: api.VirtualMachineScaleSets,
+						},
+						{
+							Name:                "canary",
+							Count:               5,
+							AvailabilityProfile: api.VirtualMachineScaleSets,
+						},
+					},
+				},
+			},
+			expected: true,
+			name:     "multiple pools, backendpool vmss is in spec",
+		},
+		{
+			vmssName: "k8s-canary-41325566-vmss",
+			cs: &api.ContainerService{
+				Properties: &api.Properties{
+					OrchestratorProfile: &api.OrchestratorProfile{
+						OrchestratorType:    api.Kubernetes,
+						OrchestratorVersion: "1.15.4",
+						KubernetesConfig: &api.KubernetesConfig{
+							ContainerRuntime: api.Docker,
+						},
+					},
+					AgentPoolProfiles: []*api.AgentPoolProfile{
+						{
+							Name:                "frontendpool",
+							Count:               30,
+							AvailabilityProfile: api.VirtualMachineScaleSets,
+						},
+						{
+							Name:                "backendpool",
+							Count:               7,
+							AvailabilityProfile: api.VirtualMachineScaleSets,
+						},
+						{
+							Name:                "canary",
+							Count:               5,
+							AvailabilityProfile: api.VirtualMachineScaleSets,
+						},
+					},
+				},
+			},
+			expected: true,
+			name:     "multiple pools, canary vmss is in spec",
+		},
+		{
+			vmssName: "k8s-canary-41325566-vmss",
+			cs: &api.ContainerService{
+				Properties: &api.Properties{
+					OrchestratorProfile: &api.OrchestratorProfile{
+						OrchestratorType:    api.Kubernetes,
+						OrchestratorVersion: "1.15.4",
+						KubernetesConfig: &api.KubernetesConfig{
+							ContainerRuntime: api.Docker,
+						},
+					},
+					AgentPoolProfiles: []*api.AgentPoolProfile{},
+				},
+			},
+			expected: false,
+			name:     "no pools",
+		},
+		{
+			vmssName: "k8s-canary-41325566-vmss",
+			cs: &api.ContainerService{
+				Properties: &api.Properties{
+					OrchestratorProfile: &api.OrchestratorProfile{
+						OrchestratorType:    api.Kubernetes,
+						OrchestratorVersion: "1.15.4",
+						KubernetesConfig: &api.KubernetesConfig{
+							ContainerRuntime: api.Docker,
+						},
+					},
+					AgentPoolProfiles: []*api.AgentPoolProfile{
+						{
+							Name:                "canary",
+							Count:               1,
+							AvailabilityProfile: api.AvailabilitySet,
+						},
+					},
+				},
+			},
+			expected: false,
+			name:     "availability set",
+		},
+	}
+
+	for _, tc := range cases {
+		c := tc
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			ret := isVMSSNameInAgentPoolsArray(c.vmssName, c.cs)
+			if ret != c.expected {
+				t.Errorf("expected %t to be %t", ret, c.expected)
+			}
+		})
+	}
+}
+
+func TestGetUpgradeWindowsImageConfig(t *testing.T) {
+	cases := []struct {
+		name     string
+		current  api.AzureOSImageConfig
+		expected api.AzureOSImageConfig
+		ok       bool
+	}{
+		{
+			name: "aks-engine containerd VHD",
+			current: api.AzureOSImageConfig{
+				ImagePublisher: api.AKSWindowsServer2019ContainerDOSImageConfig.ImagePublisher,
+				ImageOffer:     api.AKSWindowsServer2019ContainerDOSImageConfig.ImageOffer,
+				ImageSku:       "2019-datacenter-core-ctrd-2104",
+			},
+			expected: api.AKSWindowsServer2019ContainerDOSImageConfig,
+			ok:       true,
+		},
+		{
+			name: "aks-engine docker VHD",
+			current: api.AzureOSImageConfig{
+				ImagePublisher: api.AKSWindowsServer2019OSImageConfig.ImagePublisher,
+				ImageOffer:     api.AKSWindowsServer2019OSImageConfig.ImageOffer,
+				ImageSku:       "2019-datacenter-core-smalldisk-2104",
+			},
+			expected: api.AKSWindowsServer2019OSImageConfig,
+			ok:       true,
+		},
+		{
+			name: "Windows Server 2019 marketplace image",
+			current: api.AzureOSImageConfig{
+				ImagePublisher: api.WindowsServer2019OSImageConfig.ImagePublisher,
+				ImageOffer:     api.WindowsServer2019OSImageConfig.ImageOffer,
+				ImageSku:       "2019-Datacenter-Core-with-Containers-smalldisk",
+			},
+			expected: api.WindowsServer2019OSImageConfig,
+			ok:       true,
+		},
+		{
+			name: "Windows Server 2022 marketplace image",
+			current: api.AzureOSImageConfig{
+				ImagePublisher: api.WindowsServer2019OSImageConfig.ImagePublisher,
+				ImageOffer:     api.WindowsServer2019OSImageConfig.ImageOffer,
+				ImageSku:       "2022-datacenter-core-smalldisk",
+			},
+			ok: false,
+		},
+		{
+			name: "Windows Server 2019 marketplace image with the desktop experience",
+			current: api.AzureOSImageConfig{
+				ImagePublisher: api.WindowsServer2019OSImageConfig.ImagePublisher,
+				ImageOffer:     api.WindowsServer2019OSImageConfig.ImageOffer,
+				ImageSku:       "2019-Datacenter",
+			},
+			ok: false,
+		},
+		{
+			name: "aks-engine VHD of another sku family",
+			current: api.AzureOSImageConfig{
+				ImagePublisher: api.AKSWindowsServer2019OSImageConfig.ImagePublisher,
+				ImageOffer:     api.AKSWindowsServer2019OSImageConfig.ImageOffer,
+				ImageSku:       "2022-datacenter-core-smalldisk-2204",
+			},
+			ok: false,
+		},
+		{
+			name: "other marketplace image",
+			current: api.AzureOSImageConfig{
+				ImagePublisher: "contoso",
+				ImageOffer:     "windows",
+				ImageSku:       "2022",
+			},
+			ok: false,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewGomegaWithT(t)
+			imageConfig, ok := getUpgradeWindowsImageConfig(c.current)
+			g.Expect(ok).To(Equal(c.ok))
+			g.Expect(imageConfig).To(Equal(c.expected))
+		})
+	}
+}
+
+func TestUpgradedNodes(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	vms := func(names ...string) *[]compute.VirtualMachine {
+		var vms []compute.VirtualMachine
+		for _, name := range names {
+			vms = append(vms, compute.VirtualMachine{Name: to.StringPtr(name)})
+		}
+		return &vms
+	}
+	topology := kubernetesupgrade.ClusterTopology{
+		MasterVMs: vms("k8s-master-12345678-0"),
+		AgentPools: map[string]*kubernetesupgrade.AgentPoolTopology{
+			"pool2": {AgentVMs: vms("k8s-pool2-12345678-0")},
+			"pool1": {AgentVMs: vms("k8s-pool1-12345678-0", "k8s-pool1-12345678-1")},
+			"pool3": {},
+		},
+		AgentPoolScaleSetsToUpgrade: []kubernetesupgrade.AgentPoolScaleSet{
+			{
+				Name:         "k8s-pool4-12345678-vmss",
+				VMsToUpgrade: []kubernetesupgrade.AgentPoolScaleSetVM{{Name: "k8s-pool4-12345678-vmss000000", InstanceID: "0"}},
+			},
+		},
+	}
+
+	g.Expect(upgradedNodes(topology)).To(Equal([]string{
+		"k8s-master-12345678-0",
+		"k8s-pool1-12345678-0",
+		"k8s-pool1-12345678-1",
+		"k8s-pool2-12345678-0",
+		"k8s-pool4-12345678-vmss000000",
+	}))
+	g.Expect(upgradedNodes(kubernetesupgrade.ClusterTopology{})).To(BeEmpty())
+}
@@ -80,12 +80,16 @@ func GenerateARMResources(cs *api.ContainerService) []interface{} {
 	for _, profile := range profiles {
 
 		if profile.IsWindows() {
-			if cs.Properties.WindowsProfile.HasCustomImage() {
+			if cs.Properties.WindowsProfile.HasCustomImage() && !profile.HasImageRef() && !profile.HasWindowsImageOverride() {
 				// Create Image resource from VHD if requestesd
 				armResources = append(armResources, createWindowsImage(profile))
 			}
 		}
 
+		if profile.HasNetworkSecurityGroup() {
+			armResources = append(armResources, createAgentPoolNetworkSecurityGroup(cs, profile))
+		}
+
 		if profile.IsVirtualMachineScaleSets() {
 			if useManagedIdentity && !userAssignedIDEnabled {
 				armResources = append(armResources, createAgentVMSSSysRoleAssignment(profile))
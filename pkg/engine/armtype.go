@@ -148,6 +148,70 @@ type LoadBalancerARM struct {
 	network.LoadBalancer
 }
 
+// PublicIPPrefixARM embeds the ARMResource type in network.PublicIPPrefix.
+type PublicIPPrefixARM struct {
+	ARMResource
+	network.PublicIPPrefix
+}
+
+// NATGatewayARM embeds the ARMResource type in NATGateway.
+type NATGatewayARM struct {
+	ARMResource
+	NATGateway
+}
+
+// NATGateway describes a Microsoft.Network/natGateways resource, which the vendored network API version predates.
+type NATGateway struct {
+	Location                    *string        `json:"location,omitempty"`
+	Name                        *string        `json:"name,omitempty"`
+	Type                        *string        `json:"type,omitempty"`
+	Sku                         *NATGatewaySku `json:"sku,omitempty"`
+	*NATGatewayPropertiesFormat `json:"properties,omitempty"`
+}
+
+// NATGatewaySku is the SKU of a NAT gateway.
+type NATGatewaySku struct {
+	Name string `json:"name,omitempty"`
+}
+
+// NATGatewayPropertiesFormat holds the properties of a NAT gateway.
+type NATGatewayPropertiesFormat struct {
+	IdleTimeoutInMinutes *int32                 `json:"idleTimeoutInMinutes,omitempty"`
+	PublicIPPrefixes     *[]network.SubResource `json:"publicIpPrefixes,omitempty"`
+}
+
+// NATGatewayVirtualNetworkARM is a VirtualNetworkARM whose subnets are associated with a NAT gateway.
+type NATGatewayVirtualNetworkARM struct {
+	VirtualNetworkARM
+	NATGatewayID string `json:"-"`
+}
+
+// MarshalJSON is the custom marshaler for a NATGatewayVirtualNetworkARM, it adds the natGateway
+// property that the subnets of the vendored network API version lack.
+func (vnet NATGatewayVirtualNetworkARM) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(vnet.VirtualNetworkARM)
+	if err != nil {
+		return nil, err
+	}
+	var resource map[string]interface{}
+	if err = json.Unmarshal(b, &resource); err != nil {
+		return nil, err
+	}
+	properties, _ := resource["properties"].(map[string]interface{})
+	subnets, _ := properties["subnets"].([]interface{})
+	for _, s := range subnets {
+		subnet, _ := s.(map[string]interface{})
+		// application gateway subnets do not support NAT gateways
+		if subnet["name"] == "[variables('appGwSubnetName')]" {
+			continue
+		}
+		if subnetProperties, ok := subnet["properties"].(map[string]interface{}); ok {
+			subnetProperties["natGateway"] = map[string]string{"id": vnet.NATGatewayID}
+		}
+	}
+	return json.Marshal(resource)
+}
+
 // ApplicationGatewayARM embeds the ARMResource type in network.ApplicationGateway.
 type ApplicationGatewayARM struct {
 	ARMResource
@@ -340,6 +340,22 @@ func getK8sMasterVars(cs *api.ContainerService) (map[string]interface{}, error)
 		masterVars["virtualNetworkResourceGroupName"] = "''"
 	}
 	masterVars["routeTableName"] = "[concat(variables('masterVMNamePrefix'),'routetable')]"
+	masterVars["routeTableResourceGroup"] = ""
+	if cs.Properties.IsUserDefinedRouting() {
+		masterVars["routeTableName"] = cs.Properties.GetRouteTableName()
+		masterVars["routeTableID"] = kubernetesConfig.RouteTableID
+		masterVars["routeTableResourceGroup"] = cs.Properties.GetRouteTableResourceGroupName()
+	}
+	if cs.Properties.IsNATGateway() {
+		masterVars["apiVersionNATGateway"] = api.APIVersionNATGateway
+		if cs.Properties.IsManagedNATGateway() {
+			masterVars["natGatewayName"] = "[concat(parameters('orchestratorName'), '-natgw-', parameters('nameSuffix'))]"
+			masterVars["natGatewayPublicIPPrefixName"] = "[concat(parameters('orchestratorName'), '-natgw-prefix-', parameters('nameSuffix'))]"
+			masterVars["natGatewayID"] = "[resourceId('Microsoft.Network/natGateways', variables('natGatewayName'))]"
+		} else {
+			masterVars["natGatewayID"] = kubernetesConfig.NATGatewayProfile.ID
+		}
+	}
 	if masterProfile.IsStorageAccount() {
 		masterVars["masterStorageAccountName"] = "[concat(variables('storageAccountBaseName'), 'mstr0')]"
 	}
@@ -600,6 +616,11 @@ func getK8sAgentVars(cs *api.ContainerService, profile *api.AgentPoolProfile) ma
 		agentVars[agentSubnetName] = "[variables('subnetName')]"
 	}
 
+	if profile.HasNetworkSecurityGroup() {
+		agentVars[fmt.Sprintf("%sNetworkSecurityGroupName", agentName)] = fmt.Sprintf("[concat('k8s-%s-', parameters('nameSuffix'), '-nsg')]", agentName)
+		agentVars[fmt.Sprintf("%sNetworkSecurityGroupID", agentName)] = fmt.Sprintf("[resourceId('Microsoft.Network/networkSecurityGroups', variables('%sNetworkSecurityGroupName'))]", agentName)
+	}
+
 	agentVars[agentSubnetResourceGroup] = fmt.Sprintf("[split(variables('%sVnetSubnetID'), '/')[4]]", agentName)
 	agentVars[agentVnet] = fmt.Sprintf("[split(variables('%sVnetSubnetID'), '/')[8]]", agentName)
 
@@ -640,6 +661,7 @@ func getWindowsProfileVars(wp *api.WindowsProfile) map[string]interface{} {
 	windowsPauseImageURL := ""
 	alwaysPullWindowsPauseImage := false
 	windowsSecureTLSEnabled := false
+	var gmsa api.WindowsGMSAProfile
 
 	if wp != nil {
 		enableCSIProxy = wp.IsCSIProxyEnabled()
@@ -648,6 +670,9 @@ func getWindowsProfileVars(wp *api.WindowsProfile) map[string]interface{} {
 		windowsPauseImageURL = wp.WindowsPauseImageURL
 		alwaysPullWindowsPauseImage = (wp.AlwaysPullWindowsPauseImage != nil && *wp.AlwaysPullWindowsPauseImage)
 		windowsSecureTLSEnabled = (wp.WindowsSecureTLSEnabled != nil && *wp.WindowsSecureTLSEnabled)
+		if wp.IsGMSAEnabled() {
+			gmsa = *wp.GMSA
+		}
 	}
 	vars := map[string]interface{}{
 		"windowsEnableCSIProxy":                enableCSIProxy,
@@ -656,6 +681,12 @@ func getWindowsProfileVars(wp *api.WindowsProfile) map[string]interface{} {
 		"windowsPauseImageURL":                 windowsPauseImageURL,
 		"alwaysPullWindowsPauseImage":          strconv.FormatBool(alwaysPullWindowsPauseImage),
 		"windowsSecureTLSEnabled":              strconv.FormatBool(windowsSecureTLSEnabled),
+		"windowsGmsaMode":                      gmsa.Mode,
+		"windowsGmsaDomainName":                gmsa.DomainName,
+		"windowsGmsaDomainJoinUser":            gmsa.DomainJoinUser,
+		"windowsGmsaOrganizationalUnit":        gmsa.OrganizationalUnit,
+		"windowsGmsaCCGPluginURL":              gmsa.CCGPluginURL,
+		"windowsGmsaCCGPluginCLSID":            gmsa.CCGPluginCLSID,
 	}
 	return vars
 }
@@ -6,6 +6,7 @@ package engine
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/Azure/go-autorest/autorest/azure"
@@ -168,6 +169,7 @@ func TestK8sVars(t *testing.T) {
 		"resourceGroup":                             "[resourceGroup().name]",
 		"routeTableID":                              "[resourceId('Microsoft.Network/routeTables', variables('routeTableName'))]",
 		"routeTableName":                            "[concat(variables('masterVMNamePrefix'),'routetable')]",
+		"routeTableResourceGroup":                   "",
 		"scope":                                     "[resourceGroup().id]",
 		"servicePrincipalClientId":                  "msi",
 		"servicePrincipalClientSecret":              "msi",
@@ -200,6 +202,12 @@ func TestK8sVars(t *testing.T) {
 		"windowsPauseImageURL":                      "",
 		"alwaysPullWindowsPauseImage":               "false",
 		"windowsSecureTLSEnabled":                   "false",
+		"windowsGmsaMode":                           "",
+		"windowsGmsaDomainName":                     "",
+		"windowsGmsaDomainJoinUser":                 "",
+		"windowsGmsaOrganizationalUnit":             "",
+		"windowsGmsaCCGPluginURL":                   "",
+		"windowsGmsaCCGPluginCLSID":                 "",
 	}
 
 	diff := cmp.Diff(varMap, expectedMap)
@@ -630,6 +638,7 @@ func TestK8sVars(t *testing.T) {
 		"resourceGroup":                             "[resourceGroup().name]",
 		"routeTableID":                              "[resourceId('Microsoft.Network/routeTables', variables('routeTableName'))]",
 		"routeTableName":                            "[concat(variables('masterVMNamePrefix'),'routetable')]",
+		"routeTableResourceGroup":                   "",
 		"scope":                                     "[resourceGroup().id]",
 		"servicePrincipalClientId":                  "[parameters('servicePrincipalClientId')]",
 		"servicePrincipalClientSecret":              "[parameters('servicePrincipalClientSecret')]",
@@ -660,6 +669,12 @@ func TestK8sVars(t *testing.T) {
 		"windowsPauseImageURL":                      "",
 		"alwaysPullWindowsPauseImage":               "false",
 		"windowsSecureTLSEnabled":                   "false",
+		"windowsGmsaMode":                           "",
+		"windowsGmsaDomainName":                     "",
+		"windowsGmsaDomainJoinUser":                 "",
+		"windowsGmsaOrganizationalUnit":             "",
+		"windowsGmsaCCGPluginURL":                   "",
+		"windowsGmsaCCGPluginCLSID":                 "",
 	}
 
 	diff = cmp.Diff(varMap, expectedMap)
@@ -901,6 +916,7 @@ func TestK8sVarsMastersOnly(t *testing.T) {
 		"resourceGroup":                             "[resourceGroup().name]",
 		"routeTableID":                              "[resourceId('Microsoft.Network/routeTables', variables('routeTableName'))]",
 		"routeTableName":                            "[concat(variables('masterVMNamePrefix'),'routetable')]",
+		"routeTableResourceGroup":                   "",
 		"scope":                                     "[resourceGroup().id]",
 		"servicePrincipalClientId":                  "msi",
 		"servicePrincipalClientSecret":              "msi",
@@ -931,6 +947,12 @@ func TestK8sVarsMastersOnly(t *testing.T) {
 		"windowsPauseImageURL":                      "",
 		"alwaysPullWindowsPauseImage":               "false",
 		"windowsSecureTLSEnabled":                   "false",
+		"windowsGmsaMode":                           "",
+		"windowsGmsaDomainName":                     "",
+		"windowsGmsaDomainJoinUser":                 "",
+		"windowsGmsaOrganizationalUnit":             "",
+		"windowsGmsaCCGPluginURL":                   "",
+		"windowsGmsaCCGPluginCLSID":                 "",
 	}
 	diff := cmp.Diff(varMap, expectedMap)
 
@@ -988,6 +1010,12 @@ func TestK8sVarsWindowsProfile(t *testing.T) {
 				"windowsPauseImageURL":                 "",
 				"alwaysPullWindowsPauseImage":          "false",
 				"windowsSecureTLSEnabled":              "false",
+				"windowsGmsaMode":                      "",
+				"windowsGmsaDomainName":                "",
+				"windowsGmsaDomainJoinUser":            "",
+				"windowsGmsaOrganizationalUnit":        "",
+				"windowsGmsaCCGPluginURL":              "",
+				"windowsGmsaCCGPluginCLSID":            "",
 			},
 		},
 		{
@@ -1000,6 +1028,12 @@ func TestK8sVarsWindowsProfile(t *testing.T) {
 				"windowsPauseImageURL":                 "",
 				"alwaysPullWindowsPauseImage":          "false",
 				"windowsSecureTLSEnabled":              "false",
+				"windowsGmsaMode":                      "",
+				"windowsGmsaDomainName":                "",
+				"windowsGmsaDomainJoinUser":            "",
+				"windowsGmsaOrganizationalUnit":        "",
+				"windowsGmsaCCGPluginURL":              "",
+				"windowsGmsaCCGPluginCLSID":            "",
 			},
 		},
 		{
@@ -1019,6 +1053,63 @@ func TestK8sVarsWindowsProfile(t *testing.T) {
 				"windowsPauseImageURL":                 "mcr.contoso.com/core/pause:",
 				"alwaysPullWindowsPauseImage":          "true",
 				"windowsSecureTLSEnabled":              "true",
+				"windowsGmsaMode":                      "",
+				"windowsGmsaDomainName":                "",
+				"windowsGmsaDomainJoinUser":            "",
+				"windowsGmsaOrganizationalUnit":        "",
+				"windowsGmsaCCGPluginURL":              "",
+				"windowsGmsaCCGPluginCLSID":            "",
+			},
+		},
+		{
+			name: "gMSA domain join",
+			wp: &api.WindowsProfile{
+				GMSA: &api.WindowsGMSAProfile{
+					Mode:               api.WindowsGMSAModeDomainJoin,
+					DomainName:         "contoso.com",
+					DomainJoinUser:     "contoso\\joiner",
+					DomainJoinPassword: "secret",
+					OrganizationalUnit: "OU=k8s,DC=contoso,DC=com",
+				},
+			},
+			expectedVars: map[string]interface{}{
+				"windowsEnableCSIProxy":                false,
+				"windowsCSIProxyURL":                   "",
+				"windowsProvisioningScriptsPackageURL": "",
+				"windowsPauseImageURL":                 "",
+				"alwaysPullWindowsPauseImage":          "false",
+				"windowsSecureTLSEnabled":              "false",
+				"windowsGmsaMode":                      "DomainJoin",
+				"windowsGmsaDomainName":                "contoso.com",
+				"windowsGmsaDomainJoinUser":            "contoso\\joiner",
+				"windowsGmsaOrganizationalUnit":        "OU=k8s,DC=contoso,DC=com",
+				"windowsGmsaCCGPluginURL":              "",
+				"windowsGmsaCCGPluginCLSID":            "",
+			},
+		},
+		{
+			name: "gMSA CCG plugin",
+			wp: &api.WindowsProfile{
+				GMSA: &api.WindowsGMSAProfile{
+					Mode:           api.WindowsGMSAModeCCGPlugin,
+					DomainName:     "contoso.com",
+					CCGPluginURL:   "https://some/plugin.zip",
+					CCGPluginCLSID: "{CCC2A336-D7F3-4818-A213-272B7924213E}",
+				},
+			},
+			expectedVars: map[string]interface{}{
+				"windowsEnableCSIProxy":                false,
+				"windowsCSIProxyURL":                   "",
+				"windowsProvisioningScriptsPackageURL": "",
+				"windowsPauseImageURL":                 "",
+				"alwaysPullWindowsPauseImage":          "false",
+				"windowsSecureTLSEnabled":              "false",
+				"windowsGmsaMode":                      "CCGPlugin",
+				"windowsGmsaDomainName":                "contoso.com",
+				"windowsGmsaDomainJoinUser":            "",
+				"windowsGmsaOrganizationalUnit":        "",
+				"windowsGmsaCCGPluginURL":              "https://some/plugin.zip",
+				"windowsGmsaCCGPluginCLSID":            "{CCC2A336-D7F3-4818-A213-272B7924213E}",
 			},
 		},
 	}
@@ -1037,3 +1128,138 @@ func TestK8sVarsWindowsProfile(t *testing.T) {
 		})
 	}
 }
+
+func TestK8sVarsUserDefinedRouting(t *testing.T) {
+	routeTableID := "/subscriptions/SUB_ID/resourceGroups/NETWORK_RG/providers/Microsoft.Network/routeTables/firewall-rt"
+	cs := &api.ContainerService{
+		Properties: &api.Properties{
+			ServicePrincipalProfile: &api.ServicePrincipalProfile{
+				ClientID: "barClientID",
+				Secret:   "bazSecret",
+			},
+			MasterProfile: &api.MasterProfile{
+				Count:     1,
+				DNSPrefix: "blueorange",
+				VMSize:    "Standard_D2_v2",
+			},
+			OrchestratorProfile: &api.OrchestratorProfile{
+				OrchestratorType: api.Kubernetes,
+				KubernetesConfig: &api.KubernetesConfig{
+					LoadBalancerSku:             api.StandardLoadBalancerSku,
+					ExcludeMasterFromStandardLB: to.BoolPtr(true),
+					OutboundType:                api.OutboundTypeUserDefinedRouting,
+					RouteTableID:                routeTableID,
+				},
+			},
+			LinuxProfile: &api.LinuxProfile{},
+		},
+	}
+
+	_, err := cs.SetPropertiesDefaults(api.PropertiesDefaultsParams{
+		IsScale:    false,
+		IsUpgrade:  false,
+		PkiKeySize: helpers.DefaultPkiKeySize,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	varMap, err := GetKubernetesVariables(cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if varMap["routeTableID"] != routeTableID {
+		t.Errorf("expected routeTableID %s, got %v", routeTableID, varMap["routeTableID"])
+	}
+	if varMap["routeTableName"] != "firewall-rt" {
+		t.Errorf("expected routeTableName firewall-rt, got %v", varMap["routeTableName"])
+	}
+	if varMap["routeTableResourceGroup"] != "NETWORK_RG" {
+		t.Errorf("expected routeTableResourceGroup NETWORK_RG, got %v", varMap["routeTableResourceGroup"])
+	}
+	if !strings.Contains(varMap["provisionScriptParametersCommon"].(string), "ROUTE_TABLE_RESOURCE_GROUP=NETWORK_RG ") {
+		t.Errorf("expected the provision script parameters to carry the route table resource group, got %s", varMap["provisionScriptParametersCommon"])
+	}
+}
+
+func TestK8sVarsNATGateway(t *testing.T) {
+	natGatewayID := "/subscriptions/SUB_ID/resourceGroups/NETWORK_RG/providers/Microsoft.Network/natGateways/egress-natgw"
+	cases := []struct {
+		name                 string
+		outboundType         string
+		natGatewayProfile    *api.NATGatewayProfile
+		expectedNATGatewayID string
+		expectManagedNames   bool
+	}{
+		{
+			name:                 "managed NAT gateway",
+			outboundType:         api.OutboundTypeManagedNATGateway,
+			expectedNATGatewayID: "[resourceId('Microsoft.Network/natGateways', variables('natGatewayName'))]",
+			expectManagedNames:   true,
+		},
+		{
+			name:         "user-assigned NAT gateway",
+			outboundType: api.OutboundTypeUserAssignedNATGateway,
+			natGatewayProfile: &api.NATGatewayProfile{
+				ID: natGatewayID,
+			},
+			expectedNATGatewayID: natGatewayID,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			cs := &api.ContainerService{
+				Properties: &api.Properties{
+					ServicePrincipalProfile: &api.ServicePrincipalProfile{
+						ClientID: "barClientID",
+						Secret:   "bazSecret",
+					},
+					MasterProfile: &api.MasterProfile{
+						Count:     1,
+						DNSPrefix: "blueorange",
+						VMSize:    "Standard_D2_v2",
+					},
+					OrchestratorProfile: &api.OrchestratorProfile{
+						OrchestratorType: api.Kubernetes,
+						KubernetesConfig: &api.KubernetesConfig{
+							LoadBalancerSku:             api.StandardLoadBalancerSku,
+							ExcludeMasterFromStandardLB: to.BoolPtr(true),
+							OutboundType:                c.outboundType,
+							NATGatewayProfile:           c.natGatewayProfile,
+						},
+					},
+					LinuxProfile: &api.LinuxProfile{},
+				},
+			}
+
+			_, err := cs.SetPropertiesDefaults(api.PropertiesDefaultsParams{
+				IsScale:    false,
+				IsUpgrade:  false,
+				PkiKeySize: helpers.DefaultPkiKeySize,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			varMap, err := GetKubernetesVariables(cs)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if varMap["apiVersionNATGateway"] != "2019-11-01" {
+				t.Errorf("expected apiVersionNATGateway 2019-11-01, got %v", varMap["apiVersionNATGateway"])
+			}
+			if varMap["natGatewayID"] != c.expectedNATGatewayID {
+				t.Errorf("expected natGatewayID %s, got %v", c.expectedNATGatewayID, varMap["natGatewayID"])
+			}
+			_, hasName := varMap["natGatewayName"]
+			_, hasPrefixName := varMap["natGatewayPublicIPPrefixName"]
+			if hasName != c.expectManagedNames || hasPrefixName != c.expectManagedNames {
+				t.Errorf("expected the NAT gateway resource names to be set only for a managed NAT gateway, got natGatewayName %v and natGatewayPublicIPPrefixName %v", varMap["natGatewayName"], varMap["natGatewayPublicIPPrefixName"])
+			}
+		})
+	}
+}
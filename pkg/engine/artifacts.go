@@ -74,7 +74,7 @@ func kubernetesAddonSettingsInit(p *api.Properties) map[string]kubernetesCompone
 	o := p.OrchestratorProfile
 	k := o.KubernetesConfig
 	// TODO validate that each of these addons are actually wired in to the conveniences in getAddonFuncMap
-	return map[string]kubernetesComponentFileSpec{
+	settings := map[string]kubernetesComponentFileSpec{
 		common.MetricsServerAddonName: {
 			sourceFile:      metricsServerAddonSourceFilename,
 			base64Data:      k.GetAddonScript(common.MetricsServerAddonName),
@@ -230,7 +230,27 @@ func kubernetesAddonSettingsInit(p *api.Properties) map[string]kubernetesCompone
 			base64Data:      k.GetAddonScript(common.AzureArcOnboardingAddonName),
 			destinationFile: connectedClusterAddonDestinationFilename,
 		},
+		common.GMSAWebhookAddonName: {
+			sourceFile:      gmsaWebhookAddonSourceFilename,
+			base64Data:      k.GetAddonScript(common.GMSAWebhookAddonName),
+			destinationFile: gmsaWebhookAddonDestinationFilename,
+		},
+		common.HypervRuntimeClassAddonName: {
+			sourceFile:      hypervRuntimeClassAddonSourceFilename,
+			base64Data:      k.GetAddonScript(common.HypervRuntimeClassAddonName),
+			destinationFile: hypervRuntimeClassAddonDestinationFilename,
+		},
+	}
+	// custom addons are delivered once their source has been resolved into their data, see ResolveCustomAddons
+	for _, addon := range k.Addons {
+		if _, isBuiltin := settings[addon.Name]; !isBuiltin && addon.IsCustom() && addon.Data != "" {
+			settings[addon.Name] = kubernetesComponentFileSpec{
+				base64Data:      addon.Data,
+				destinationFile: customAddonDestinationFilenamePrefix + addon.Name + ".yaml",
+			}
+		}
 	}
+	return settings
 }
 
 func getComponentString(input, destinationPath, destinationFile string) string {
@@ -74,6 +74,7 @@ const (
 	kubernetesWindowsAzureCniFunctionsPS1         = "k8s/windowsazurecnifunc.ps1"
 	kubernetesWindowsHostsConfigAgentFunctionsPS1 = "k8s/windowshostsconfigagentfunc.ps1"
 	kubernetesWindowsOpenSSHFunctionPS1           = "k8s/windowsinstallopensshfunc.ps1"
+	kubernetesWindowsGMSAFunctionsPS1             = "k8s/windowsgmsafunc.ps1"
 	kubernetesWindowsHypervtemplatetoml           = "k8s/containerdtemplate.toml"
 )
 
@@ -140,6 +141,8 @@ const (
 
 // addons source and destination file references
 const (
+	addonsSourcePath                              string = "k8s/addons"
+	addonsDestinationPath                         string = "/etc/kubernetes/addons"
 	metricsServerAddonSourceFilename              string = "metrics-server.yaml"
 	metricsServerAddonDestinationFilename         string = "metrics-server.yaml"
 	tillerAddonSourceFilename                     string = "tiller.yaml"
@@ -202,6 +205,11 @@ const (
 	secretsStoreCSIDriverAddonDestinationFileName string = "secrets-store-csi-driver.yaml"
 	connectedClusterAddonSourceFilename           string = "arc-onboarding.yaml"
 	connectedClusterAddonDestinationFilename      string = "arc-onboarding.yaml"
+	gmsaWebhookAddonSourceFilename                string = "gmsa-webhook.yaml"
+	gmsaWebhookAddonDestinationFilename           string = "gmsa-webhook.yaml"
+	hypervRuntimeClassAddonSourceFilename         string = "hyperv-runtimeclass.yaml"
+	hypervRuntimeClassAddonDestinationFilename    string = "hyperv-runtimeclass.yaml"
+	customAddonDestinationFilenamePrefix          string = "custom-"
 )
 
 // components source and destination file references
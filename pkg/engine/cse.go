@@ -3,6 +3,8 @@
 
 package engine
 
+import "github.com/Azure/aks-engine/pkg/i18n"
+
 var cseErrorCodes = map[string]int{
 	"ERR_SYSTEMCTL_STOP_FAIL":                    3,
 	"ERR_SYSTEMCTL_START_FAIL":                   4,
@@ -88,3 +90,114 @@ func GetCSEErrorCode(errorType string) int {
 	}
 	return -1
 }
+
+// GetCSEErrorName returns the name of the CSE error that exits with code, or an empty string if no CSE error exits with code
+func GetCSEErrorName(code int) string {
+	for name, c := range cseErrorCodes {
+		if c == code {
+			return name
+		}
+	}
+	return ""
+}
+
+// GetWindowsCSEErrorDiagnostic returns the translated probable cause and remediation of a failure of the provisioning script
+// of a Windows VM, which exits with the same status whatever the error
+func GetWindowsCSEErrorDiagnostic(translator *i18n.Translator) CSEErrorDiagnostic {
+	if translator == nil {
+		translator = &i18n.Translator{}
+	}
+	return CSEErrorDiagnostic{
+		Cause:       translator.T("The provisioning script of the Windows VM failed."),
+		Remediation: translator.T("Collect the logs of the VM with aks-engine get-logs and check C:\\AzureData\\CustomDataSetupScript.log for the error that stopped the provisioning script."),
+	}
+}
+
+// CSEErrorDiagnostic describes the probable cause of a CSE error and how to remediate it
+type CSEErrorDiagnostic struct {
+	Cause       string
+	Remediation string
+}
+
+// GetCSEErrorDiagnostic returns the translated probable cause and remediation of the CSE error named errorName.
+// Errors without a specific diagnostic get a generic remediation that points to the provisioning logs of the VM
+func GetCSEErrorDiagnostic(translator *i18n.Translator, errorName string) CSEErrorDiagnostic {
+	if translator == nil {
+		translator = &i18n.Translator{}
+	}
+	switch errorName {
+	case "ERR_OUTBOUND_CONN_FAIL":
+		return CSEErrorDiagnostic{
+			Cause:       translator.T("The VM has no outbound connectivity to the container registry."),
+			Remediation: translator.T("Check that the network security groups, route tables, firewalls and proxies of the subnet allow outbound traffic to the endpoints AKS Engine requires."),
+		}
+	case "ERR_K8S_API_SERVER_CONN_FAIL":
+		return CSEErrorDiagnostic{
+			Cause:       translator.T("The VM cannot connect to the Kubernetes API server."),
+			Remediation: translator.T("Check that the control plane VMs are running and that the network security groups and route tables allow traffic from the nodes to the API server on port 443."),
+		}
+	case "ERR_K8S_API_SERVER_DNS_LOOKUP_FAIL":
+		return CSEErrorDiagnostic{
+			Cause:       translator.T("The VM cannot resolve the FQDN of the Kubernetes API server."),
+			Remediation: translator.T("Check that the DNS servers of the virtual network resolve the FQDN of the API server, including the private DNS records of private clusters."),
+		}
+	case "ERR_K8S_API_SERVER_AZURE_DNS_LOOKUP_FAIL":
+		return CSEErrorDiagnostic{
+			Cause:       translator.T("The VM cannot resolve the FQDN of the Kubernetes API server with Azure DNS."),
+			Remediation: translator.T("Check that the VM can reach Azure DNS at 168.63.129.16, or that the custom DNS servers of the virtual network forward to it."),
+		}
+	case "ERR_APT_INSTALL_TIMEOUT", "ERR_APT_UPDATE_TIMEOUT", "ERR_APT_DIST_UPGRADE_TIMEOUT", "ERR_MOBY_APT_LIST_TIMEOUT", "ERR_MOBY_INSTALL_TIMEOUT":
+		return CSEErrorDiagnostic{
+			Cause:       translator.T("The VM could not install packages from the apt repositories in time."),
+			Remediation: translator.T("Check that the VM has outbound connectivity to the apt repositories, or use a VHD image that already contains the packages."),
+		}
+	case "ERR_K8S_DOWNLOAD_TIMEOUT", "ERR_CNI_DOWNLOAD_TIMEOUT", "ERR_IMG_DOWNLOAD_TIMEOUT", "ERR_CONTAINER_IMG_PULL_TIMEOUT", "ERR_DEB_DOWNLOAD_TIMEOUT":
+		return CSEErrorDiagnostic{
+			Cause:       translator.T("The VM could not download Kubernetes components in time."),
+			Remediation: translator.T("Check that the VM has outbound connectivity to the download URLs and image repositories of the api model, or use a VHD image that already contains the components."),
+		}
+	case "ERR_KUBELET_START_FAIL":
+		return CSEErrorDiagnostic{
+			Cause:       translator.T("The kubelet service failed to start."),
+			Remediation: translator.T("Collect the logs of the VM with aks-engine get-logs and check the kubelet journal for configuration errors, e.g. in kubeletConfig."),
+		}
+	case "ERR_K8S_RUNNING_TIMEOUT":
+		return CSEErrorDiagnostic{
+			Cause:       translator.T("The Kubernetes API server did not become available on the control plane VM in time."),
+			Remediation: translator.T("Collect the logs of the VM with aks-engine get-logs and check the kube-apiserver container logs and the etcd service."),
+		}
+	case "ERR_ETCD_RUNNING_TIMEOUT", "ERR_ETCD_START_TIMEOUT", "ERR_ETCD_CONFIG_FAIL", "ERR_ETCD_VOL_MOUNT_FAIL":
+		return CSEErrorDiagnostic{
+			Cause:       translator.T("etcd failed to start or join the cluster on the control plane VM."),
+			Remediation: translator.T("Check that the control plane VMs can reach each other on ports 2379 and 2380, and that the etcd data disk is attached."),
+		}
+	case "ERR_FILE_WATCH_TIMEOUT", "ERR_CSE_PROVISION_SCRIPT_NOT_READY_TIMEOUT":
+		return CSEErrorDiagnostic{
+			Cause:       translator.T("cloud-init did not write the provisioning files of the VM in time."),
+			Remediation: translator.T("Collect the logs of the VM with aks-engine get-logs and check /var/log/cloud-init.log for errors in the custom data."),
+		}
+	case "ERR_VHD_FILE_NOT_FOUND":
+		return CSEErrorDiagnostic{
+			Cause:       translator.T("The VHD image of the VM does not contain the files this version of AKS Engine expects."),
+			Remediation: translator.T("Use the VHD image of the AKS Engine release that deploys the cluster, or remove the custom image reference from the api model."),
+		}
+	case "ERR_GPU_DRIVERS_START_FAIL", "ERR_GPU_DRIVERS_INSTALL_TIMEOUT", "ERR_GPU_DRIVERS_CONFIG":
+		return CSEErrorDiagnostic{
+			Cause:       translator.T("The NVIDIA GPU drivers failed to install or load."),
+			Remediation: translator.T("Check that the VM size is a supported NVIDIA N-series size, and collect the logs of the VM with aks-engine get-logs to check the driver installation."),
+		}
+	case "ERR_CUSTOM_SEARCH_DOMAINS_FAIL":
+		return CSEErrorDiagnostic{
+			Cause:       translator.T("The VM failed to join the custom search domain."),
+			Remediation: translator.T("Check the customSearchDomain realm user and password of the api model, and that the VM can reach the domain controllers."),
+		}
+	case "ERR_AZURE_STACK_GET_ARM_TOKEN", "ERR_AZURE_STACK_GET_NETWORK_CONFIGURATION", "ERR_AZURE_STACK_GET_SUBNET_PREFIX", "ERR_AZURE_STACK_GET_SDN_INTERFACES":
+		return CSEErrorDiagnostic{
+			Cause:       translator.T("The VM failed to read its network configuration from the Azure Stack Hub Resource Manager."),
+			Remediation: translator.T("Check the service principal credentials of the api model and that the VM can reach the Azure Stack Hub Resource Manager endpoint."),
+		}
+	}
+	return CSEErrorDiagnostic{
+		Remediation: translator.T("Collect the logs of the VM with aks-engine get-logs and check /var/log/azure/cluster-provision.log."),
+	}
+}
@@ -4,7 +4,10 @@
 package engine
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/Azure/aks-engine/pkg/i18n"
 )
 
 func TestGetCSEErrorCode(t *testing.T) {
@@ -114,3 +117,43 @@ func TestGetCSEErrorCode(t *testing.T) {
 		})
 	}
 }
+
+func TestGetCSEErrorName(t *testing.T) {
+	for name, code := range cseErrorCodes {
+		if actual := GetCSEErrorName(code); actual != name {
+			t.Errorf("expected CSE error name %s for code %d, got: %s", name, code, actual)
+		}
+	}
+	for _, code := range []int{-1, 0, 1, 255} {
+		if actual := GetCSEErrorName(code); actual != "" {
+			t.Errorf("expected no CSE error name for code %d, got: %s", code, actual)
+		}
+	}
+}
+
+func TestGetCSEErrorDiagnostic(t *testing.T) {
+	translator := &i18n.Translator{}
+	for name := range cseErrorCodes {
+		diagnostic := GetCSEErrorDiagnostic(translator, name)
+		if diagnostic.Remediation == "" {
+			t.Errorf("expected a remediation for %s", name)
+		}
+	}
+
+	diagnostic := GetCSEErrorDiagnostic(nil, "ERR_K8S_API_SERVER_DNS_LOOKUP_FAIL")
+	if diagnostic.Cause != "The VM cannot resolve the FQDN of the Kubernetes API server." {
+		t.Errorf("unexpected cause for ERR_K8S_API_SERVER_DNS_LOOKUP_FAIL, got: %s", diagnostic.Cause)
+	}
+
+	diagnostic = GetCSEErrorDiagnostic(translator, "")
+	if diagnostic.Cause != "" || !strings.Contains(diagnostic.Remediation, "aks-engine get-logs") {
+		t.Errorf("unexpected generic diagnostic, got: %+v", diagnostic)
+	}
+}
+
+func TestGetWindowsCSEErrorDiagnostic(t *testing.T) {
+	diagnostic := GetWindowsCSEErrorDiagnostic(nil)
+	if diagnostic.Cause == "" || !strings.Contains(diagnostic.Remediation, `C:\AzureData\CustomDataSetupScript.log`) {
+		t.Errorf("unexpected Windows diagnostic, got: %+v", diagnostic)
+	}
+}
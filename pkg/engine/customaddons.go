@@ -0,0 +1,300 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	addonManagerModeLabel           = "addonmanager.kubernetes.io/mode"
+	customAddonHelmNamespaceDefault = "kube-system"
+	customAddonHelmMinVersion       = "3.0.0"
+)
+
+// helmVersionRegex matches the version printed by helm version --short, e.g. v3.7.1+g1d11fcb
+var helmVersionRegex = regexp.MustCompile(`v(\d+\.\d+\.\d+)`)
+
+// customAddonHTTPClient downloads the manifests of custom addons sourced from a URL
+var customAddonHTTPClient = &http.Client{Timeout: 60 * time.Second}
+
+// ResolveCustomAddons reads the manifest of each enabled custom addon from its source and stores it,
+// base64-encoded, as the addon data. Addons that already have data are left alone, so that scale
+// operations keep delivering the manifest the cluster was created with.
+// Relative paths are resolved against the current working directory.
+func ResolveCustomAddons(cs *api.ContainerService) error {
+	if cs.Properties == nil || cs.Properties.OrchestratorProfile == nil || cs.Properties.OrchestratorProfile.KubernetesConfig == nil {
+		return nil
+	}
+	addons := cs.Properties.OrchestratorProfile.KubernetesConfig.Addons
+	for i := range addons {
+		addon := &addons[i]
+		if !addon.IsCustom() || !addon.IsEnabled() {
+			continue
+		}
+		if addon.Data == "" {
+			manifest, err := readCustomAddonSource(addon)
+			if err != nil {
+				return errors.Wrapf(err, "resolving the source of custom addon %s", addon.Name)
+			}
+			addon.Data = base64.StdEncoding.EncodeToString(manifest)
+		}
+		// template errors are otherwise swallowed while generating the ARM template
+		if _, err := getCustomAddonManifest(cs, *addon); err != nil {
+			return errors.Wrapf(err, "rendering the manifest of custom addon %s", addon.Name)
+		}
+	}
+	return nil
+}
+
+// RefreshCustomAddons reads the manifest of each enabled custom addon from its source again,
+// replacing the data stored when the cluster was created, see ResolveCustomAddons.
+func RefreshCustomAddons(cs *api.ContainerService) error {
+	if cs.Properties == nil || cs.Properties.OrchestratorProfile == nil || cs.Properties.OrchestratorProfile.KubernetesConfig == nil {
+		return nil
+	}
+	addons := cs.Properties.OrchestratorProfile.KubernetesConfig.Addons
+	for i := range addons {
+		if addons[i].IsCustom() && addons[i].IsEnabled() {
+			addons[i].Data = ""
+		}
+	}
+	return ResolveCustomAddons(cs)
+}
+
+func readCustomAddonSource(addon *api.KubernetesAddon) ([]byte, error) {
+	switch {
+	case addon.Source.Path != "":
+		return readCustomAddonPath(addon.Source.Path)
+	case addon.Source.URL != "":
+		return downloadCustomAddon(addon.Source.URL, addon.Source.SHA256)
+	case addon.Source.HelmChart != nil:
+		return renderCustomAddonHelmChart(addon)
+	}
+	return nil, errors.New("one of source.path, source.url or source.helmChart must be specified")
+}
+
+// readCustomAddonPath reads a manifest file, or the YAML files of a manifest directory in lexical order
+func readCustomAddonPath(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return os.ReadFile(path)
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var documents [][]byte
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, bytes.TrimSpace(b))
+	}
+	if len(documents) == 0 {
+		return nil, errors.Errorf("no .yaml or .yml files found in directory %s", path)
+	}
+	return append(bytes.Join(documents, []byte("\n---\n")), '\n'), nil
+}
+
+// downloadCustomAddon downloads a manifest and verifies it against its expected SHA-256 checksum
+func downloadCustomAddon(url, checksum string) ([]byte, error) {
+	resp, err := customAddonHTTPClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("downloading %s: unexpected status %s", url, resp.Status)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "downloading %s", url)
+	}
+	sum := sha256.Sum256(b)
+	if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, checksum) {
+		return nil, errors.Errorf("checksum mismatch for %s: expected %s, got %s", url, checksum, actual)
+	}
+	return b, nil
+}
+
+// renderCustomAddonHelmChart renders a Helm chart offline with "helm template",
+// passing the addon config as chart values
+func renderCustomAddonHelmChart(addon *api.KubernetesAddon) ([]byte, error) {
+	helm, err := exec.LookPath("helm")
+	if err != nil {
+		return nil, errors.New("the helm CLI must be installed to render a custom addon from a Helm chart")
+	}
+	if err = checkHelmVersion(helm); err != nil {
+		return nil, err
+	}
+	chart := addon.Source.HelmChart
+	namespace := chart.Namespace
+	if namespace == "" {
+		namespace = customAddonHelmNamespaceDefault
+	}
+	args := []string{"template", addon.Name, chart.Chart, "--namespace", namespace}
+	if chart.Repository != "" {
+		args = append(args, "--repo", chart.Repository)
+	}
+	if chart.Version != "" {
+		args = append(args, "--version", chart.Version)
+	}
+	if chart.ValuesFile != "" {
+		args = append(args, "--values", chart.ValuesFile)
+	}
+	var keys []string
+	for key := range addon.Config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--set", fmt.Sprintf("%s=%s", key, addon.Config[key]))
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(helm, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "running helm template: %s", strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// checkHelmVersion returns an error if the helm CLI is older than customAddonHelmMinVersion,
+// Helm 2 cannot render a chart from a repository without a Tiller server
+func checkHelmVersion(helm string) error {
+	out, err := exec.Command(helm, "version", "--short").Output()
+	if err != nil {
+		return errors.Wrapf(err, "running helm version, helm %s or later is required", customAddonHelmMinVersion)
+	}
+	match := helmVersionRegex.FindStringSubmatch(string(out))
+	if match == nil {
+		return errors.Errorf("unexpected helm version output %q, helm %s or later is required", strings.TrimSpace(string(out)), customAddonHelmMinVersion)
+	}
+	version, err := semver.Make(match[1])
+	if err != nil {
+		return errors.Wrapf(err, "parsing helm version %s", match[1])
+	}
+	if version.LT(semver.MustParse(customAddonHelmMinVersion)) {
+		return errors.Errorf("helm %s is not supported, helm %s or later is required", match[1], customAddonHelmMinVersion)
+	}
+	return nil
+}
+
+// getCustomAddonManifest renders the resolved manifest of a custom addon and labels its objects
+// so that they are managed by the addon manager. Manifests whose source sets template are templates
+// that can use the same functions as the built-in addons, other manifests are delivered as they are.
+func getCustomAddonManifest(cs *api.ContainerService, addon api.KubernetesAddon) (string, error) {
+	manifest, err := getStringFromBase64(addon.Data)
+	if err != nil {
+		return "", err
+	}
+	if addon.Source.Template {
+		templ, err := template.New("custom addon resolver template").Funcs(getCustomAddonFuncMap(addon, cs)).Parse(manifest)
+		if err != nil {
+			return "", err
+		}
+		var buffer bytes.Buffer
+		if err = templ.Execute(&buffer, addon); err != nil {
+			return "", err
+		}
+		manifest = buffer.String()
+	}
+	mode := addon.Mode
+	if mode == "" {
+		mode = api.AddonModeReconcile
+	}
+	return setAddonManagerMode(manifest, mode)
+}
+
+func getCustomAddonFuncMap(addon api.KubernetesAddon, cs *api.ContainerService) template.FuncMap {
+	funcMap := getAddonFuncMap(addon, cs)
+	funcMap["PoolNames"] = func() []string {
+		var names []string
+		for _, pool := range addon.Pools {
+			names = append(names, pool.Name)
+		}
+		return names
+	}
+	funcMap["PoolConfig"] = func(pool, name string) string {
+		for _, p := range addon.Pools {
+			if p.Name == pool {
+				return p.Config[name]
+			}
+		}
+		return ""
+	}
+	return funcMap
+}
+
+// setAddonManagerMode sets the addon manager mode label on each object of manifest that does not have one
+func setAddonManagerMode(manifest, mode string) (string, error) {
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(strings.NewReader(manifest)))
+	var documents []string
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		var object map[string]interface{}
+		if err = yaml.Unmarshal(document, &object); err != nil {
+			return "", err
+		}
+		if len(object) == 0 {
+			continue
+		}
+		metadata, ok := object["metadata"].(map[string]interface{})
+		if !ok {
+			metadata = map[string]interface{}{}
+			object["metadata"] = metadata
+		}
+		labels, ok := metadata["labels"].(map[string]interface{})
+		if !ok {
+			labels = map[string]interface{}{}
+			metadata["labels"] = labels
+		}
+		if _, ok = labels[addonManagerModeLabel]; !ok {
+			labels[addonManagerModeLabel] = mode
+		}
+		b, err := yaml.Marshal(object)
+		if err != nil {
+			return "", err
+		}
+		documents = append(documents, string(b))
+	}
+	return strings.Join(documents, "---\n"), nil
+}
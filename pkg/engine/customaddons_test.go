@@ -0,0 +1,346 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/api/common"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+const customAddonTestManifest = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: agent
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: agent
+  namespace: agent
+  labels:
+    addonmanager.kubernetes.io/mode: {{GetMode}}
+spec:
+  replicas: {{PoolConfig "pool1" "replicas"}}
+  template:
+    spec:
+      containers:
+      - name: agent
+        image: {{ContainerImage "agent"}}
+`
+
+func getCustomAddonContainerService(addons ...api.KubernetesAddon) *api.ContainerService {
+	cs := api.CreateMockContainerService("testcluster", common.RationalizeReleaseAndVersion(common.Kubernetes, common.KubernetesDefaultRelease, "", false, false, false), 3, 2, false)
+	cs.Properties.OrchestratorProfile.KubernetesConfig.Addons = addons
+	return cs
+}
+
+func getCustomAddonData(t *testing.T, cs *api.ContainerService, name string) string {
+	addon := cs.Properties.OrchestratorProfile.KubernetesConfig.GetAddonByName(name)
+	b, err := base64.StdEncoding.DecodeString(addon.Data)
+	if err != nil {
+		t.Fatalf("expected the data of custom addon %s to be base64 encoded: %s", name, err)
+	}
+	return string(b)
+}
+
+func TestResolveCustomAddonsFromPath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "agent.yaml"), []byte(customAddonTestManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifests := filepath.Join(dir, "manifests")
+	if err := os.Mkdir(manifests, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"01-namespace.yaml": "kind: Namespace\nmetadata:\n  annotations:\n    note: '{{ not a template }}'\n",
+		"02-service.yml":    "kind: Service\n",
+		"README.md":         "# not a manifest\n",
+	} {
+		if err := os.WriteFile(filepath.Join(manifests, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cs := getCustomAddonContainerService(
+		api.KubernetesAddon{
+			Name:       "agent",
+			Enabled:    to.BoolPtr(true),
+			Containers: []api.KubernetesContainerSpec{{Name: "agent", Image: "example.com/agent:v1"}},
+			Pools:      []api.AddonNodePoolsConfig{{Name: "pool1", Config: map[string]string{"replicas": "3"}}},
+			Source:     &api.AddonSource{Path: filepath.Join(dir, "agent.yaml"), Template: true},
+		},
+		api.KubernetesAddon{
+			Name:    "manifests",
+			Enabled: to.BoolPtr(true),
+			Mode:    api.AddonModeEnsureExists,
+			Source:  &api.AddonSource{Path: manifests},
+		},
+		api.KubernetesAddon{
+			Name:    "disabled",
+			Enabled: to.BoolPtr(false),
+			Source:  &api.AddonSource{Path: filepath.Join(dir, "missing.yaml")},
+		},
+	)
+	if err := ResolveCustomAddons(cs); err != nil {
+		t.Fatalf("unexpected error resolving custom addons: %s", err)
+	}
+	if data := getCustomAddonData(t, cs, "agent"); data != customAddonTestManifest {
+		t.Errorf("expected the data of the agent addon to be the manifest file, got %s", data)
+	}
+	if data := getCustomAddonData(t, cs, "manifests"); data != "kind: Namespace\nmetadata:\n  annotations:\n    note: '{{ not a template }}'\n---\nkind: Service\n" {
+		t.Errorf("expected the data of the manifests addon to be the YAML files of the directory, got %s", data)
+	}
+	if data := getCustomAddonData(t, cs, "disabled"); data != "" {
+		t.Errorf("expected a disabled custom addon not to be resolved, got %s", data)
+	}
+
+	manifestPath, manifest, err := GetKubernetesAddonManifest(cs, "agent")
+	if err != nil {
+		t.Fatalf("unexpected error rendering the agent manifest: %s", err)
+	}
+	if manifestPath != "/etc/kubernetes/addons/custom-agent.yaml" {
+		t.Errorf("expected manifest path /etc/kubernetes/addons/custom-agent.yaml, got %s", manifestPath)
+	}
+	for _, expected := range []string{
+		"image: example.com/agent:v1",
+		"replicas: 3",
+		"addonmanager.kubernetes.io/mode: Reconcile",
+	} {
+		if !strings.Contains(manifest, expected) {
+			t.Errorf("expected the agent manifest to contain %q, got %s", expected, manifest)
+		}
+	}
+	if strings.Count(manifest, "addonmanager.kubernetes.io/mode") != 2 {
+		t.Errorf("expected each object of the agent manifest to be labeled once, got %s", manifest)
+	}
+	if !strings.Contains(getAddonsString(cs, addonsSourcePath), getBase64EncodedGzippedCustomScriptFromStr(manifest)) {
+		t.Errorf("expected the custom addon manifest to be delivered at provisioning time")
+	}
+
+	_, manifest, err = GetKubernetesAddonManifest(cs, "manifests")
+	if err != nil {
+		t.Fatalf("unexpected error rendering the manifests manifest: %s", err)
+	}
+	if strings.Count(manifest, "addonmanager.kubernetes.io/mode: EnsureExists") != 2 {
+		t.Errorf("expected each object of the manifests addon to be labeled with its mode, got %s", manifest)
+	}
+	if !strings.Contains(manifest, "{{ not a template }}") {
+		t.Errorf("expected a manifest whose source does not set template to be delivered as it is, got %s", manifest)
+	}
+
+	// resolved addons are not read again
+	if err = os.Remove(filepath.Join(dir, "agent.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if err = ResolveCustomAddons(cs); err != nil {
+		t.Errorf("unexpected error resolving custom addons that have data: %s", err)
+	}
+
+	// refreshed addons are read again
+	if err = RefreshCustomAddons(cs); err == nil || !strings.Contains(err.Error(), "resolving the source of custom addon agent") {
+		t.Errorf("expected refreshing a custom addon whose source was removed to fail, got %v", err)
+	}
+	updated := strings.Replace(customAddonTestManifest, "name: agent\n---", "name: agent-v2\n---", 1)
+	if err = os.WriteFile(filepath.Join(dir, "agent.yaml"), []byte(updated), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = RefreshCustomAddons(cs); err != nil {
+		t.Fatalf("unexpected error refreshing custom addons: %s", err)
+	}
+	if data := getCustomAddonData(t, cs, "agent"); data != updated {
+		t.Errorf("expected the data of the agent addon to be the updated manifest file, got %s", data)
+	}
+	if data := getCustomAddonData(t, cs, "disabled"); data != "" {
+		t.Errorf("expected a disabled custom addon not to be refreshed, got %s", data)
+	}
+}
+
+func TestResolveCustomAddonsFromURL(t *testing.T) {
+	manifest := []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: agent\n")
+	sum := sha256.Sum256(manifest)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/agent.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(manifest)
+	}))
+	defer server.Close()
+	client := customAddonHTTPClient
+	customAddonHTTPClient = server.Client()
+	defer func() { customAddonHTTPClient = client }()
+
+	cases := []struct {
+		name        string
+		url         string
+		checksum    string
+		expectedErr string
+	}{
+		{
+			name:     "valid checksum",
+			url:      server.URL + "/agent.yaml",
+			checksum: strings.ToUpper(hex.EncodeToString(sum[:])),
+		},
+		{
+			name:        "checksum mismatch",
+			url:         server.URL + "/agent.yaml",
+			checksum:    strings.Repeat("0", 64),
+			expectedErr: "checksum mismatch",
+		},
+		{
+			name:        "not found",
+			url:         server.URL + "/missing.yaml",
+			checksum:    hex.EncodeToString(sum[:]),
+			expectedErr: "unexpected status 404 Not Found",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cs := getCustomAddonContainerService(api.KubernetesAddon{
+				Name:    "agent",
+				Enabled: to.BoolPtr(true),
+				Source:  &api.AddonSource{URL: c.url, SHA256: c.checksum},
+			})
+			err := ResolveCustomAddons(cs)
+			if c.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+					t.Errorf("expected an error containing %q, got %v", c.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error resolving the custom addon: %s", err)
+			}
+			if data := getCustomAddonData(t, cs, "agent"); data != string(manifest) {
+				t.Errorf("expected the data of the agent addon to be the downloaded manifest, got %s", data)
+			}
+		})
+	}
+}
+
+func TestResolveCustomAddonsFromHelmChart(t *testing.T) {
+	dir := t.TempDir()
+	// the fake helm CLI prints its arguments and a template expression that must not be rendered
+	script := "#!/bin/sh\nif [ \"$1\" = version ]; then echo \"${HELM_TEST_VERSION:-v3.7.1+g1d11fcb}\"; exit 0; fi\necho \"kind: ConfigMap\"\necho \"metadata:\"\necho \"  name: args\"\necho \"data:\"\necho \"  args: $*\"\necho \"  template: '{{ .Values.foo }}'\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "helm"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	cs := getCustomAddonContainerService(api.KubernetesAddon{
+		Name:    "ingress-nginx",
+		Enabled: to.BoolPtr(true),
+		Config:  map[string]string{"controller.replicaCount": "2", "controller.kind": "DaemonSet"},
+		Source: &api.AddonSource{
+			HelmChart: &api.AddonHelmChart{
+				Chart:      "ingress-nginx",
+				Repository: "https://kubernetes.github.io/ingress-nginx",
+				Version:    "4.0.1",
+				ValuesFile: "values.yaml",
+			},
+		},
+	})
+	if err := ResolveCustomAddons(cs); err != nil {
+		t.Fatalf("unexpected error resolving the custom addon: %s", err)
+	}
+	expected := "args: template ingress-nginx ingress-nginx --namespace kube-system --repo https://kubernetes.github.io/ingress-nginx --version 4.0.1 --values values.yaml --set controller.kind=DaemonSet --set controller.replicaCount=2"
+	if data := getCustomAddonData(t, cs, "ingress-nginx"); !strings.Contains(data, expected) {
+		t.Errorf("expected helm template to be run with %q, got %s", expected, data)
+	}
+
+	_, manifest, err := GetKubernetesAddonManifest(cs, "ingress-nginx")
+	if err != nil {
+		t.Fatalf("unexpected error rendering the ingress-nginx manifest: %s", err)
+	}
+	if !strings.Contains(manifest, "{{ .Values.foo }}") {
+		t.Errorf("expected the output of helm template not to be rendered again, got %s", manifest)
+	}
+	if !strings.Contains(manifest, "addonmanager.kubernetes.io/mode: Reconcile") {
+		t.Errorf("expected the output of helm template to be labeled for the addon manager, got %s", manifest)
+	}
+
+	cs.Properties.OrchestratorProfile.KubernetesConfig.Addons[0].Data = ""
+	t.Setenv("HELM_TEST_VERSION", "Client: v2.16.1+gbbdfe5e")
+	if err = ResolveCustomAddons(cs); err == nil || !strings.Contains(err.Error(), "helm 2.16.1 is not supported, helm 3.0.0 or later is required") {
+		t.Errorf("expected an error when the helm CLI is older than version 3, got %v", err)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if err = ResolveCustomAddons(cs); err == nil || !strings.Contains(err.Error(), "the helm CLI must be installed") {
+		t.Errorf("expected an error when the helm CLI is not installed, got %v", err)
+	}
+}
+
+func TestResolveCustomAddonsErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "agent.yaml"), []byte("image: {{ContainerImage \"agent\"}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name        string
+		addon       api.KubernetesAddon
+		expectedErr string
+	}{
+		{
+			name: "missing path",
+			addon: api.KubernetesAddon{
+				Name:    "agent",
+				Enabled: to.BoolPtr(true),
+				Source:  &api.AddonSource{Path: filepath.Join(dir, "missing.yaml")},
+			},
+			expectedErr: "resolving the source of custom addon agent",
+		},
+		{
+			name: "empty directory",
+			addon: api.KubernetesAddon{
+				Name:    "agent",
+				Enabled: to.BoolPtr(true),
+				Source:  &api.AddonSource{Path: t.TempDir()},
+			},
+			expectedErr: "no .yaml or .yml files found in directory",
+		},
+		{
+			name: "template error",
+			addon: api.KubernetesAddon{
+				Name:    "agent",
+				Enabled: to.BoolPtr(true),
+				Source:  &api.AddonSource{Path: filepath.Join(dir, "agent.yaml"), Template: true},
+			},
+			expectedErr: "rendering the manifest of custom addon agent",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			cs := getCustomAddonContainerService(c.addon)
+			err := ResolveCustomAddons(cs)
+			if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+				t.Errorf("expected an error containing %q, got %v", c.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestBuiltinAddonNames(t *testing.T) {
+	for name := range kubernetesAddonSettingsInit(getCustomAddonContainerService().Properties) {
+		if !common.IsBuiltinAddonName(name) {
+			t.Errorf("expected addon %s to be a built-in addon, custom addons could take its name", name)
+		}
+	}
+}
@@ -679,7 +679,43 @@ func getAddonFuncMap(addon api.KubernetesAddon, cs *api.ContainerService) templa
 			}
 			return "admissionregistration.k8s.io/v1beta1"
 		},
+		"GetRuntimeClassAPIVersion": func() string {
+			if common.IsKubernetesVersionGe(cs.Properties.OrchestratorProfile.OrchestratorVersion, "1.20.0") {
+				return "node.k8s.io/v1"
+			}
+			return "node.k8s.io/v1beta1"
+		},
+		"GetWindowsHypervRuntimeHandlerNames": func() []string {
+			return cs.Properties.WindowsProfile.GetWindowsHypervRuntimeHandlerNames()
+		},
+		"GetWindowsRuntimeHandlerLabelPrefix": func() string {
+			return api.KubernetesWindowsRuntimeHandlerLabelPrefix
+		},
+	}
+}
+
+// getGMSAWebhookAddonFuncMap returns the serving certificate of the gmsa-webhook addon, signed by the cluster CA
+// that the webhook configurations trust. The pair is generated once with the other certificates of the api model,
+// so that rendering the manifest again yields the same secret.
+func getGMSAWebhookAddonFuncMap(addon api.KubernetesAddon, cs *api.ContainerService) (template.FuncMap, error) {
+	certificateProfile := cs.Properties.CertificateProfile
+	if certificateProfile == nil || certificateProfile.CaCertificate == "" {
+		return nil, errors.New("the cluster CA is required to render the gmsa-webhook manifest")
 	}
+	if certificateProfile.GMSAWebhookCertificate == "" || certificateProfile.GMSAWebhookPrivateKey == "" {
+		return nil, errors.New("the gmsa-webhook serving certificate is required to render the gmsa-webhook manifest")
+	}
+	ret := getAddonFuncMap(addon, cs)
+	ret["GetCACertificateBase64"] = func() string {
+		return base64.StdEncoding.EncodeToString([]byte(certificateProfile.CaCertificate))
+	}
+	ret["GetServingCertificateBase64"] = func() string {
+		return base64.StdEncoding.EncodeToString([]byte(certificateProfile.GMSAWebhookCertificate))
+	}
+	ret["GetServingPrivateKeyBase64"] = func() string {
+		return base64.StdEncoding.EncodeToString([]byte(certificateProfile.GMSAWebhookPrivateKey))
+	}
+	return ret, nil
 }
 
 func getClusterAutoscalerAddonFuncMap(addon api.KubernetesAddon, cs *api.ContainerService) template.FuncMap {
@@ -820,43 +856,82 @@ func getAddonsString(cs *api.ContainerService, sourcePath string) string {
 	for _, addonName := range addonNames {
 		setting := settingsMap[addonName]
 		if cs.Properties.OrchestratorProfile.KubernetesConfig.IsAddonEnabled(addonName) {
-			var input string
-			if setting.base64Data != "" {
-				var err error
-				input, err = getStringFromBase64(setting.base64Data)
-				if err != nil {
-					return ""
-				}
-			} else {
-				orchProfile := properties.OrchestratorProfile
-				versions := strings.Split(orchProfile.OrchestratorVersion, ".")
-				addon := orchProfile.KubernetesConfig.GetAddonByName(addonName)
-				var templ *template.Template
-				switch addonName {
-				case "cluster-autoscaler":
-					templ = template.New("addon resolver template").Funcs(getClusterAutoscalerAddonFuncMap(addon, cs))
-				default:
-					templ = template.New("addon resolver template").Funcs(getAddonFuncMap(addon, cs))
-				}
-				addonFile := getCustomDataFilePath(setting.sourceFile, sourcePath, versions[0]+"."+versions[1])
-				addonFileBytes, err := Asset(addonFile)
-				if err != nil {
-					return ""
-				}
-				_, err = templ.Parse(string(addonFileBytes))
-				if err != nil {
-					return ""
-				}
-				var buffer bytes.Buffer
-				_ = templ.Execute(&buffer, addon)
-				input = buffer.String()
+			input, err := getAddonManifest(cs, sourcePath, addonName, setting)
+			if err != nil {
+				return ""
 			}
-			result += getComponentString(input, "/etc/kubernetes/addons", setting.destinationFile)
+			result += getComponentString(input, addonsDestinationPath, setting.destinationFile)
 		}
 	}
 	return result
 }
 
+// getAddonManifest renders the manifest of an addon from its user-provided data or from its template
+func getAddonManifest(cs *api.ContainerService, sourcePath, addonName string, setting kubernetesComponentFileSpec) (string, error) {
+	orchProfile := cs.Properties.OrchestratorProfile
+	addon := orchProfile.KubernetesConfig.GetAddonByName(addonName)
+	if setting.sourceFile == "" && addon.IsCustom() {
+		return getCustomAddonManifest(cs, addon)
+	}
+	if setting.base64Data != "" {
+		return getStringFromBase64(setting.base64Data)
+	}
+	versions := strings.Split(orchProfile.OrchestratorVersion, ".")
+	var templ *template.Template
+	switch addonName {
+	case "cluster-autoscaler":
+		templ = template.New("addon resolver template").Funcs(getClusterAutoscalerAddonFuncMap(addon, cs))
+	case common.GMSAWebhookAddonName:
+		funcMap, err := getGMSAWebhookAddonFuncMap(addon, cs)
+		if err != nil {
+			return "", err
+		}
+		templ = template.New("addon resolver template").Funcs(funcMap)
+	default:
+		templ = template.New("addon resolver template").Funcs(getAddonFuncMap(addon, cs))
+	}
+	addonFile := getCustomDataFilePath(setting.sourceFile, sourcePath, versions[0]+"."+versions[1])
+	addonFileBytes, err := Asset(addonFile)
+	if err != nil {
+		return "", err
+	}
+	_, err = templ.Parse(string(addonFileBytes))
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	_ = templ.Execute(&buffer, addon)
+	return buffer.String(), nil
+}
+
+// GetKubernetesAddonManifestPath returns the path of the manifest of addon addonName
+// in the addon manager directory of the control plane VMs
+func GetKubernetesAddonManifestPath(cs *api.ContainerService, addonName string) (string, error) {
+	setting, ok := kubernetesAddonSettingsInit(cs.Properties)[addonName]
+	if !ok {
+		return "", errors.Errorf("addon %s is not deployed by the addon manager", addonName)
+	}
+	return addonsDestinationPath + "/" + setting.destinationFile, nil
+}
+
+// GetKubernetesAddonManifest returns the path and the content of the manifest of the enabled addon addonName,
+// rendered as it is when the control plane VMs are provisioned
+func GetKubernetesAddonManifest(cs *api.ContainerService, addonName string) (string, string, error) {
+	manifestPath, err := GetKubernetesAddonManifestPath(cs, addonName)
+	if err != nil {
+		return "", "", err
+	}
+	if !cs.Properties.OrchestratorProfile.KubernetesConfig.IsAddonEnabled(addonName) {
+		return "", "", errors.Errorf("addon %s is not enabled", addonName)
+	}
+	setting := kubernetesAddonSettingsInit(cs.Properties)[addonName]
+	manifest, err := getAddonManifest(cs, addonsSourcePath, addonName, setting)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "rendering the manifest of addon %s", addonName)
+	}
+	return manifestPath, manifest, nil
+}
+
 func getKubernetesSubnets(properties *api.Properties) string {
 	subnetString := `{
             "name": "podCIDR%d",
@@ -5,7 +5,10 @@ package engine
 
 import (
 	"bytes"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"os"
 	"path"
@@ -3492,3 +3495,163 @@ func TestGetComponentFuncMap(t *testing.T) {
 		})
 	}
 }
+
+func TestGetKubernetesAddonManifest(t *testing.T) {
+	cs := api.CreateMockContainerService("testcluster", common.RationalizeReleaseAndVersion(common.Kubernetes, common.KubernetesDefaultRelease, "", false, false, false), 3, 2, false)
+	cs.Properties.OrchestratorProfile.KubernetesConfig.Addons = []api.KubernetesAddon{
+		{
+			Name:    common.MetricsServerAddonName,
+			Enabled: to.BoolPtr(true),
+		},
+		{
+			Name:    common.DashboardAddonName,
+			Enabled: to.BoolPtr(false),
+		},
+	}
+	if _, err := cs.SetPropertiesDefaults(api.PropertiesDefaultsParams{
+		IsScale:    false,
+		IsUpgrade:  false,
+		PkiKeySize: helpers.DefaultPkiKeySize,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	manifestPath, manifest, err := GetKubernetesAddonManifest(cs, common.MetricsServerAddonName)
+	if err != nil {
+		t.Fatalf("unexpected error rendering the metrics-server manifest: %s", err)
+	}
+	if manifestPath != "/etc/kubernetes/addons/metrics-server.yaml" {
+		t.Errorf("expected manifest path /etc/kubernetes/addons/metrics-server.yaml, got %s", manifestPath)
+	}
+	image := cs.Properties.OrchestratorProfile.KubernetesConfig.GetAddonByName(common.MetricsServerAddonName).Containers[0].Image
+	if !strings.Contains(manifest, image) {
+		t.Errorf("expected the metrics-server manifest to reference image %s", image)
+	}
+	if !strings.Contains(getAddonsString(cs, addonsSourcePath), getBase64EncodedGzippedCustomScriptFromStr(manifest)) {
+		t.Errorf("expected the rendered manifest to match the one delivered at provisioning time")
+	}
+
+	if _, _, err = GetKubernetesAddonManifest(cs, common.DashboardAddonName); err == nil {
+		t.Errorf("expected an error rendering the manifest of a disabled addon")
+	}
+	if manifestPath, err = GetKubernetesAddonManifestPath(cs, common.DashboardAddonName); err != nil || manifestPath != "/etc/kubernetes/addons/kubernetes-dashboard.yaml" {
+		t.Errorf("expected the manifest path of a disabled addon, got %s, %v", manifestPath, err)
+	}
+	if _, err = GetKubernetesAddonManifestPath(cs, "no-such-addon"); err == nil {
+		t.Errorf("expected an error for an addon not deployed by the addon manager")
+	}
+}
+
+func TestGetGMSAWebhookAddonManifest(t *testing.T) {
+	cs := api.CreateMockContainerService("testcluster", "1.21.2", 3, 2, false)
+	cs.Properties.OrchestratorProfile.KubernetesConfig.Addons = []api.KubernetesAddon{
+		{
+			Name:    common.GMSAWebhookAddonName,
+			Enabled: to.BoolPtr(true),
+		},
+	}
+	if _, err := cs.SetPropertiesDefaults(api.PropertiesDefaultsParams{
+		IsScale:    false,
+		IsUpgrade:  false,
+		PkiKeySize: helpers.DefaultPkiKeySize,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, manifest, err := GetKubernetesAddonManifest(cs, common.GMSAWebhookAddonName)
+	if err != nil {
+		t.Fatalf("unexpected error rendering the gmsa-webhook manifest: %s", err)
+	}
+	if _, rendered, _ := GetKubernetesAddonManifest(cs, common.GMSAWebhookAddonName); rendered != manifest {
+		t.Errorf("expected the gmsa-webhook manifest to be the same when rendered again")
+	}
+	caBundle := base64.StdEncoding.EncodeToString([]byte(cs.Properties.CertificateProfile.CaCertificate))
+	if strings.Count(manifest, "caBundle: "+caBundle) != 2 {
+		t.Errorf("expected both gmsa-webhook configurations to trust the cluster CA")
+	}
+
+	var servingCertificate string
+	for _, line := range strings.Split(manifest, "\n") {
+		if strings.HasPrefix(line, "  tls.crt: ") {
+			servingCertificate = strings.TrimPrefix(line, "  tls.crt: ")
+		}
+	}
+	certificatePem, err := base64.StdEncoding.DecodeString(servingCertificate)
+	if err != nil {
+		t.Fatalf("unexpected error decoding the gmsa-webhook serving certificate: %s", err)
+	}
+	block, _ := pem.Decode(certificatePem)
+	if block == nil {
+		t.Fatalf("expected a PEM encoded gmsa-webhook serving certificate, got %q", certificatePem)
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("unexpected error parsing the gmsa-webhook serving certificate: %s", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM([]byte(cs.Properties.CertificateProfile.CaCertificate))
+	if _, err = certificate.Verify(x509.VerifyOptions{
+		DNSName:   "gmsa-webhook.kube-system.svc",
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}); err != nil {
+		t.Errorf("expected the gmsa-webhook serving certificate to be signed by the cluster CA: %s", err)
+	}
+
+	if servingCertificate != base64.StdEncoding.EncodeToString([]byte(cs.Properties.CertificateProfile.GMSAWebhookCertificate)) {
+		t.Errorf("expected the gmsa-webhook serving certificate of the api model")
+	}
+
+	cs.Properties.CertificateProfile.GMSAWebhookPrivateKey = ""
+	if _, _, err = GetKubernetesAddonManifest(cs, common.GMSAWebhookAddonName); err == nil {
+		t.Errorf("expected an error rendering the gmsa-webhook manifest without its serving certificate")
+	}
+	cs.Properties.CertificateProfile = nil
+	if _, _, err = GetKubernetesAddonManifest(cs, common.GMSAWebhookAddonName); err == nil {
+		t.Errorf("expected an error rendering the gmsa-webhook manifest without the cluster CA")
+	}
+}
+
+func TestGetHypervRuntimeClassAddonManifest(t *testing.T) {
+	cs := api.CreateMockContainerService("testcluster", "1.21.2", 3, 2, false)
+	cs.Properties.OrchestratorProfile.KubernetesConfig.ContainerRuntime = api.Containerd
+	cs.Properties.WindowsProfile = &api.WindowsProfile{
+		AdminUsername: "azureuser",
+		AdminPassword: "replacepassword1234$",
+		WindowsRuntimes: &api.WindowsRuntimes{
+			Default: "process",
+			HypervRuntimes: []api.RuntimeHandlers{
+				{BuildNumber: "17763"},
+				{BuildNumber: "19041"},
+			},
+		},
+	}
+	if _, err := cs.SetPropertiesDefaults(api.PropertiesDefaultsParams{
+		IsScale:    false,
+		IsUpgrade:  false,
+		PkiKeySize: helpers.DefaultPkiKeySize,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, manifest, err := GetKubernetesAddonManifest(cs, common.HypervRuntimeClassAddonName)
+	if err != nil {
+		t.Fatalf("unexpected error rendering the %s manifest: %s", common.HypervRuntimeClassAddonName, err)
+	}
+	for _, expected := range []string{
+		"apiVersion: node.k8s.io/v1\nkind: RuntimeClass",
+		"handler: runhcs-wcow-hypervisor-17763",
+		"runtimehandler.kubernetes.azure.com/runhcs-wcow-hypervisor-17763: \"true\"",
+		"---\napiVersion: node.k8s.io/v1",
+		"handler: runhcs-wcow-hypervisor-19041",
+	} {
+		if !strings.Contains(manifest, expected) {
+			t.Errorf("expected the %s manifest to contain %q, got:\n%s", common.HypervRuntimeClassAddonName, expected, manifest)
+		}
+	}
+
+	expectedLabels := ",runtimehandler.kubernetes.azure.com/runhcs-wcow-hypervisor-17763=true,runtimehandler.kubernetes.azure.com/runhcs-wcow-hypervisor-19041=true"
+	if labels := cs.Properties.GetWindowsRuntimeHandlerLabels(); labels != expectedLabels {
+		t.Errorf("expected Windows runtime handler labels %s, got %s", expectedLabels, labels)
+	}
+}
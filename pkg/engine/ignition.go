@@ -0,0 +1,338 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// ignitionVersion is the Ignition config spec version emitted for Flatcar nodes
+const ignitionVersion = "3.3.0"
+
+// armExpressionPlaceholderFormat stands in for an ARM template expression while the cloud-init
+// document is parsed, it must be a valid start of a plain YAML scalar
+const armExpressionPlaceholderFormat = "__ARMEXPR_%d__"
+
+var armExpressionPlaceholderRegexp = regexp.MustCompile(`__ARMEXPR_[0-9]+__`)
+
+// cloudConfig is the subset of the cloud-init schema used by the node custom data
+type cloudConfig struct {
+	WriteFiles []cloudConfigFile     `json:"write_files,omitempty"`
+	Groups     []map[string][]string `json:"groups,omitempty"`
+	CoreOS     *cloudConfigCoreOS    `json:"coreos,omitempty"`
+}
+
+type cloudConfigFile struct {
+	Path        string `json:"path"`
+	Permissions string `json:"permissions,omitempty"`
+	Encoding    string `json:"encoding,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	Content     string `json:"content,omitempty"`
+}
+
+type cloudConfigCoreOS struct {
+	Units []cloudConfigUnit `json:"units,omitempty"`
+}
+
+type cloudConfigUnit struct {
+	Name    string                  `json:"name"`
+	Enable  bool                    `json:"enable,omitempty"`
+	DropIns []cloudConfigUnitDropIn `json:"drop-ins,omitempty"`
+}
+
+type cloudConfigUnitDropIn struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// ignitionConfig is the subset of the Ignition v3 config spec needed to provision a node
+type ignitionConfig struct {
+	Ignition ignitionMetadata `json:"ignition"`
+	Passwd   *ignitionPasswd  `json:"passwd,omitempty"`
+	Storage  *ignitionStorage `json:"storage,omitempty"`
+	Systemd  *ignitionSystemd `json:"systemd,omitempty"`
+}
+
+type ignitionMetadata struct {
+	Version string `json:"version"`
+}
+
+type ignitionPasswd struct {
+	Users []ignitionUser `json:"users,omitempty"`
+}
+
+type ignitionUser struct {
+	Name   string   `json:"name"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+type ignitionStorage struct {
+	Files []ignitionFile `json:"files,omitempty"`
+}
+
+type ignitionFile struct {
+	Path      string           `json:"path"`
+	Overwrite bool             `json:"overwrite"`
+	Mode      int              `json:"mode"`
+	User      *ignitionNodeRef `json:"user,omitempty"`
+	Group     *ignitionNodeRef `json:"group,omitempty"`
+	Contents  ignitionResource `json:"contents"`
+}
+
+type ignitionNodeRef struct {
+	Name string `json:"name"`
+}
+
+type ignitionResource struct {
+	Source      string `json:"source"`
+	Compression string `json:"compression,omitempty"`
+}
+
+type ignitionSystemd struct {
+	Units []ignitionUnit `json:"units,omitempty"`
+}
+
+type ignitionUnit struct {
+	Name    string           `json:"name"`
+	Enabled *bool            `json:"enabled,omitempty"`
+	DropIns []ignitionDropIn `json:"dropins,omitempty"`
+}
+
+type ignitionDropIn struct {
+	Name     string `json:"name"`
+	Contents string `json:"contents"`
+}
+
+// cloudInitToIgnition converts an expanded cloud-init template, which is the body of an ARM
+// concat() expression, into an Ignition config that is the body of an equivalent ARM concat() expression.
+// ARM expressions embedded in the cloud-init document are carried over into the Ignition config
+// so that both provisioning paths are generated from the same config data.
+func cloudInitToIgnition(concatBody string) (string, error) {
+	doc, exprs, err := extractARMExpressions(concatBody)
+	if err != nil {
+		return "", err
+	}
+	// cloud-init's !!binary tag only marks base64 content, the encoding field carries the same information
+	doc = strings.Replace(doc, "!!binary ", "", -1)
+
+	var cc cloudConfig
+	if err = yaml.Unmarshal([]byte(doc), &cc); err != nil {
+		return "", errors.Wrap(err, "parsing cloud-init document")
+	}
+
+	ic, err := cc.toIgnition(exprs)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err = enc.Encode(ic); err != nil {
+		return "", errors.Wrap(err, "marshaling Ignition config")
+	}
+	return injectARMExpressions(strings.TrimSpace(buf.String()), exprs), nil
+}
+
+// extractARMExpressions splits the body of an ARM concat() expression into its literal text,
+// in which every expression argument has been replaced with a placeholder, and the list of expressions
+func extractARMExpressions(concatBody string) (string, []string, error) {
+	var doc strings.Builder
+	var exprs []string
+	i := 0
+	for i < len(concatBody) {
+		c := concatBody[i]
+		if c != '\'' {
+			doc.WriteByte(c)
+			i++
+			continue
+		}
+		// a doubled single quote is an escaped quote within the literal
+		if i+1 < len(concatBody) && concatBody[i+1] == '\'' {
+			doc.WriteByte('\'')
+			i += 2
+			continue
+		}
+		// otherwise the literal ends and an expression argument follows, up to the next top level ,'
+		if i+1 >= len(concatBody) || concatBody[i+1] != ',' {
+			return "", nil, errors.Errorf("unexpected single quote at offset %d", i)
+		}
+		start := i + 2
+		end, err := findARMExpressionEnd(concatBody, start)
+		if err != nil {
+			return "", nil, err
+		}
+		fmt.Fprintf(&doc, armExpressionPlaceholderFormat, len(exprs))
+		exprs = append(exprs, concatBody[start:end])
+		// skip the ,' that reopens the literal
+		i = end + 2
+	}
+	return doc.String(), exprs, nil
+}
+
+// findARMExpressionEnd returns the offset of the , that terminates the expression argument starting at start
+func findARMExpressionEnd(s string, start int) (int, error) {
+	depth := 0
+	inString := false
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			if c == '\'' {
+				if i+1 < len(s) && s[i+1] == '\'' {
+					i++
+					continue
+				}
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '\'':
+			inString = true
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 && i+1 < len(s) && s[i+1] == '\'' {
+				return i, nil
+			}
+		}
+	}
+	return 0, errors.Errorf("unterminated ARM expression at offset %d", start)
+}
+
+// injectARMExpressions turns text with expression placeholders back into the body of an ARM concat() expression
+func injectARMExpressions(s string, exprs []string) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range armExpressionPlaceholderRegexp.FindAllStringIndex(s, -1) {
+		out.WriteString(strings.Replace(s[last:loc[0]], "'", "''", -1))
+		fmt.Fprintf(&out, "',%s,'", exprs[placeholderIndex(s[loc[0]:loc[1]])])
+		last = loc[1]
+	}
+	out.WriteString(strings.Replace(s[last:], "'", "''", -1))
+	return escapeSingleLine(out.String())
+}
+
+// placeholderIndex returns the index of the expression a placeholder stands in for
+func placeholderIndex(placeholder string) int {
+	index, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(placeholder, "__ARMEXPR_"), "__"))
+	return index
+}
+
+func (cc *cloudConfig) toIgnition(exprs []string) (*ignitionConfig, error) {
+	ic := &ignitionConfig{
+		Ignition: ignitionMetadata{Version: ignitionVersion},
+	}
+	for _, g := range cc.Groups {
+		for group, users := range g {
+			for _, user := range users {
+				if ic.Passwd == nil {
+					ic.Passwd = &ignitionPasswd{}
+				}
+				ic.Passwd.Users = append(ic.Passwd.Users, ignitionUser{Name: user, Groups: []string{group}})
+			}
+		}
+	}
+	for _, f := range cc.WriteFiles {
+		file, err := f.toIgnition(exprs)
+		if err != nil {
+			return nil, err
+		}
+		if ic.Storage == nil {
+			ic.Storage = &ignitionStorage{}
+		}
+		ic.Storage.Files = append(ic.Storage.Files, file)
+	}
+	if cc.CoreOS != nil {
+		for _, u := range cc.CoreOS.Units {
+			unit := ignitionUnit{Name: u.Name}
+			if u.Enable {
+				enabled := true
+				unit.Enabled = &enabled
+			}
+			for _, d := range u.DropIns {
+				unit.DropIns = append(unit.DropIns, ignitionDropIn{Name: d.Name, Contents: d.Content})
+			}
+			if ic.Systemd == nil {
+				ic.Systemd = &ignitionSystemd{}
+			}
+			ic.Systemd.Units = append(ic.Systemd.Units, unit)
+		}
+	}
+	return ic, nil
+}
+
+func (f *cloudConfigFile) toIgnition(exprs []string) (ignitionFile, error) {
+	file := ignitionFile{
+		Path:      f.Path,
+		Overwrite: true,
+		Mode:      0644,
+	}
+	if f.Permissions != "" {
+		mode, err := strconv.ParseInt(f.Permissions, 8, 32)
+		if err != nil {
+			return file, errors.Wrapf(err, "parsing permissions of %s", f.Path)
+		}
+		file.Mode = int(mode)
+	}
+	if f.Owner != "" {
+		owner := strings.SplitN(f.Owner, ":", 2)
+		file.User = &ignitionNodeRef{Name: owner[0]}
+		file.Group = &ignitionNodeRef{Name: owner[0]}
+		if len(owner) == 2 {
+			file.Group.Name = owner[1]
+		}
+	}
+	switch f.Encoding {
+	case "":
+		file.Contents.Source = "data:," + dataURLEscape(f.Content, exprs)
+	case "b64", "base64":
+		file.Contents.Source = "data:;base64," + strings.Join(strings.Fields(f.Content), "")
+	case "gz+b64", "gzip+base64", "gz", "gzip":
+		file.Contents.Source = "data:;base64," + strings.Join(strings.Fields(f.Content), "")
+		file.Contents.Compression = "gzip"
+	default:
+		return file, errors.Errorf("unsupported encoding %q for %s", f.Encoding, f.Path)
+	}
+	return file, nil
+}
+
+// dataURLEscape percent-encodes s for use in a data URL. The expressions referenced from s are
+// wrapped in uriComponent() in place, so that their values are encoded by ARM at deployment time
+func dataURLEscape(s string, exprs []string) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range armExpressionPlaceholderRegexp.FindAllStringIndex(s, -1) {
+		out.WriteString(percentEncode(s[last:loc[0]]))
+		index := placeholderIndex(s[loc[0]:loc[1]])
+		exprs[index] = fmt.Sprintf("uriComponent(%s)", exprs[index])
+		out.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	out.WriteString(percentEncode(s[last:]))
+	return out.String()
+}
+
+func percentEncode(s string) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			out.WriteByte(c)
+		} else {
+			fmt.Fprintf(&out, "%%%02X", c)
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,239 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExtractARMExpressions(t *testing.T) {
+	cases := []struct {
+		name          string
+		concatBody    string
+		expectedDoc   string
+		expectedExprs []string
+		expectedErr   bool
+	}{
+		{
+			name:          "literal only",
+			concatBody:    "echo ''hello''",
+			expectedDoc:   "echo 'hello'",
+			expectedExprs: nil,
+		},
+		{
+			name:          "variables and parameters",
+			concatBody:    "server: https://',variables('kubernetesAPIServerIP'),':443 ',parameters('vnetCidr'),'",
+			expectedDoc:   "server: https://__ARMEXPR_0__:443 __ARMEXPR_1__",
+			expectedExprs: []string{"variables('kubernetesAPIServerIP')", "parameters('vnetCidr')"},
+		},
+		{
+			name:          "nested expression with string arguments",
+			concatBody:    "a',concat(variables('x'), 'b,'),'c",
+			expectedDoc:   "a__ARMEXPR_0__c",
+			expectedExprs: []string{"concat(variables('x'), 'b,')"},
+		},
+		{
+			name:        "unterminated expression",
+			concatBody:  "a',variables('x')",
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			doc, exprs, err := extractARMExpressions(c.concatBody)
+			if c.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if doc != c.expectedDoc {
+				t.Errorf("expected doc %q, got %q", c.expectedDoc, doc)
+			}
+			if diff := cmp.Diff(c.expectedExprs, exprs); diff != "" {
+				t.Errorf("unexpected expressions (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCloudInitToIgnition(t *testing.T) {
+	cloudInit := `#cloud-config
+
+write_files:
+- path: /opt/azure/containers/provision.sh
+  permissions: "0744"
+  encoding: gzip
+  owner: root
+  content: !!binary |
+    ',variables('cloudInitFiles').provisionScript,'
+
+- path: /etc/kubernetes/certs/ca.crt
+  permissions: "0644"
+  encoding: base64
+  owner: root:docker
+  content: |
+    ',parameters('caCertificate'),'
+
+- path: /var/lib/kubelet/kubeconfig
+  content: |
+    server: https://',variables('kubernetesAPIServerIP'),':443
+    echo ''quoted''
+
+groups:
+  - docker: [',parameters('linuxAdminUsername'),']
+
+coreos:
+  units:
+    - name: kubelet.service
+      enable: true
+      drop-ins:
+        - name: "10-flatcar.conf"
+          content: |
+            [Service]
+            ExecStart=/opt/bin/kubelet
+    - name: rpcbind.service
+`
+	str, err := cloudInitToIgnition(cloudInit)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, expected := range []string{
+		`data:;base64,',variables('cloudInitFiles').provisionScript,'\",\"compression\":\"gzip\"`,
+		`data:;base64,',parameters('caCertificate'),'\"`,
+		`\"group\":{\"name\":\"docker\"}`,
+		`data:,server%3A%20https%3A%2F%2F',uriComponent(variables('kubernetesAPIServerIP')),'%3A443%0Aecho%20%27quoted%27%0A`,
+		`\"users\":[{\"name\":\"',parameters('linuxAdminUsername'),'\",\"groups\":[\"docker\"]}]`,
+		`\"mode\":484`,
+		`\"mode\":420`,
+	} {
+		if !strings.Contains(str, expected) {
+			t.Errorf("expected Ignition config to contain %s, got %s", expected, str)
+		}
+	}
+
+	// undo escapeSingleLine, as parsing the ARM template JSON would
+	var unescaped string
+	if err = json.Unmarshal([]byte(`"`+str+`"`), &unescaped); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ic := evaluateIgnitionConcatBody(t, unescaped)
+	if ic.Ignition.Version != ignitionVersion {
+		t.Errorf("expected Ignition version %s, got %s", ignitionVersion, ic.Ignition.Version)
+	}
+	if len(ic.Storage.Files) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(ic.Storage.Files))
+	}
+	if len(ic.Systemd.Units) != 2 {
+		t.Fatalf("expected 2 units, got %d", len(ic.Systemd.Units))
+	}
+	kubelet := ic.Systemd.Units[0]
+	if kubelet.Enabled == nil || !*kubelet.Enabled || len(kubelet.DropIns) != 1 || kubelet.DropIns[0].Contents != "[Service]\nExecStart=/opt/bin/kubelet\n" {
+		t.Errorf("unexpected kubelet unit: %+v", kubelet)
+	}
+	if ic.Systemd.Units[1].Enabled != nil {
+		t.Errorf("expected rpcbind unit to not be enabled explicitly")
+	}
+}
+
+func TestCloudInitToIgnitionErrors(t *testing.T) {
+	cases := []struct {
+		name      string
+		cloudInit string
+	}{
+		{
+			name:      "invalid permissions",
+			cloudInit: "write_files:\n- path: /a\n  permissions: \"rw\"\n",
+		},
+		{
+			name:      "unsupported encoding",
+			cloudInit: "write_files:\n- path: /a\n  encoding: bz2\n",
+		},
+		{
+			name:      "invalid yaml",
+			cloudInit: "write_files: [",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := cloudInitToIgnition(c.cloudInit); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestGetKubernetesFlatcarNodeCustomData(t *testing.T) {
+	cs := api.CreateMockContainerService("testcluster", "1.18.0", 1, 2, false)
+	cs.Properties.OrchestratorProfile.KubernetesConfig.ContainerRuntime = api.Docker
+	cs.Properties.AgentPoolProfiles[0].Distro = api.Flatcar
+	cs.Properties.AgentPoolProfiles[0].KubernetesConfig = &api.KubernetesConfig{ContainerRuntime: api.Docker}
+	cs.Properties.AgentPoolProfiles = append(cs.Properties.AgentPoolProfiles, &api.AgentPoolProfile{Name: "ubuntu", Distro: api.Ubuntu1804, Count: 1})
+	tg, _ := InitializeTemplateGenerator(Context{})
+
+	customData := tg.GetKubernetesLinuxNodeCustomDataJSONObject(cs, cs.Properties.AgentPoolProfiles[0])
+	var obj map[string]string
+	if err := json.Unmarshal([]byte(customData), &obj); err != nil {
+		t.Fatalf("expected customData to be a JSON object: %s", err)
+	}
+	str := strings.TrimSuffix(strings.TrimPrefix(obj["customData"], "[base64(concat('"), "'))]")
+	ic := evaluateIgnitionConcatBody(t, str)
+
+	paths := map[string]bool{}
+	for _, f := range ic.Storage.Files {
+		paths[f.Path] = true
+	}
+	for _, p := range []string{"/opt/azure/containers/provision.sh", "/var/lib/kubelet/kubeconfig", "/etc/default/kubelet"} {
+		if !paths[p] {
+			t.Errorf("expected Ignition config to write %s", p)
+		}
+	}
+	units := map[string]bool{}
+	for _, u := range ic.Systemd.Units {
+		units[u.Name] = true
+	}
+	for _, u := range []string{"kubelet.service", "kubelet-monitor.service", "docker-monitor.service", "rpcbind.service"} {
+		if !units[u] {
+			t.Errorf("expected Ignition config to configure unit %s", u)
+		}
+	}
+	if ic.Passwd == nil || len(ic.Passwd.Users) != 1 || ic.Passwd.Users[0].Groups[0] != "docker" {
+		t.Errorf("expected the admin user to be added to the docker group")
+	}
+
+	customData = tg.GetKubernetesLinuxNodeCustomDataJSONObject(cs, cs.Properties.AgentPoolProfiles[1])
+	if !strings.Contains(customData, "#cloud-config") {
+		t.Errorf("expected non-Flatcar pools to use cloud-init")
+	}
+}
+
+// evaluateIgnitionConcatBody substitutes every expression of an ARM concat() body and parses the resulting Ignition config
+func evaluateIgnitionConcatBody(t *testing.T, concatBody string) ignitionConfig {
+	t.Helper()
+	doc, _, err := extractARMExpressions(concatBody)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	doc = armExpressionPlaceholderRegexp.ReplaceAllString(doc, "value")
+	var ic ignitionConfig
+	if err := json.Unmarshal([]byte(doc), &ic); err != nil {
+		t.Fatalf("expected a valid Ignition config: %s\n%s", err, doc)
+	}
+	return ic
+}
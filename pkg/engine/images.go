@@ -11,10 +11,29 @@ import (
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func createWindowsImageReference(agentPoolProfileName string, windowsProfile *api.WindowsProfile) *compute.ImageReference {
+func createWindowsImageReference(profile *api.AgentPoolProfile, windowsProfile *api.WindowsProfile) *compute.ImageReference {
 	var computeImageRef compute.ImageReference
+	agentPoolProfileName := profile.Name
 
-	if windowsProfile.HasCustomImage() {
+	if profile.HasImageRef() {
+		imageRef := profile.ImageRef
+		if profile.HasImageGallery() {
+			computeImageRef = compute.ImageReference{
+				ID: to.StringPtr(fmt.Sprintf("[concat('/subscriptions/', '%s', '/resourceGroups/', variables('%sosImageResourceGroup'), '/providers/Microsoft.Compute/galleries/', '%s', '/images/', variables('%sosImageName'), '/versions/', '%s')]", imageRef.SubscriptionID, agentPoolProfileName, imageRef.Gallery, agentPoolProfileName, imageRef.Version)),
+			}
+		} else {
+			computeImageRef = compute.ImageReference{
+				ID: to.StringPtr(fmt.Sprintf("[resourceId(variables('%[1]sosImageResourceGroup'), 'Microsoft.Compute/images', variables('%[1]sosImageName'))]", agentPoolProfileName)),
+			}
+		}
+	} else if profile.HasWindowsImageOverride() {
+		computeImageRef = compute.ImageReference{
+			Offer:     to.StringPtr(fmt.Sprintf("[variables('%sosImageOffer')]", agentPoolProfileName)),
+			Publisher: to.StringPtr(fmt.Sprintf("[variables('%sosImagePublisher')]", agentPoolProfileName)),
+			Sku:       to.StringPtr(fmt.Sprintf("[variables('%sosImageSKU')]", agentPoolProfileName)),
+			Version:   to.StringPtr(fmt.Sprintf("[variables('%sosImageVersion')]", agentPoolProfileName)),
+		}
+	} else if windowsProfile.HasCustomImage() {
 		computeImageRef = compute.ImageReference{
 			ID: to.StringPtr(fmt.Sprintf("[resourceId('Microsoft.Compute/images', '%sCustomWindowsImage')]", agentPoolProfileName)),
 		}
@@ -14,14 +14,14 @@ import (
 
 func TestCreateWindowsImageReference(t *testing.T) {
 	cases := []struct {
-		name        string
-		profileName string
-		w           api.WindowsProfile
-		expected    compute.ImageReference
+		name     string
+		profile  api.AgentPoolProfile
+		w        api.WindowsProfile
+		expected compute.ImageReference
 	}{
 		{
-			name:        "CustomImageUrl",
-			profileName: "foobar",
+			name:    "CustomImageUrl",
+			profile: api.AgentPoolProfile{Name: "foobar", OSType: api.Windows},
 			w: api.WindowsProfile{
 				WindowsImageSourceURL: "https://some/image.vhd",
 			},
@@ -30,8 +30,8 @@ func TestCreateWindowsImageReference(t *testing.T) {
 			},
 		},
 		{
-			name:        "Image gallery reference",
-			profileName: "foo",
+			name:    "Image gallery reference",
+			profile: api.AgentPoolProfile{Name: "foo", OSType: api.Windows},
 			w: api.WindowsProfile{
 				ImageRef: &api.ImageReference{
 					Gallery:        "gallery",
@@ -46,8 +46,8 @@ func TestCreateWindowsImageReference(t *testing.T) {
 			},
 		},
 		{
-			name:        "Image reference",
-			profileName: "bar",
+			name:    "Image reference",
+			profile: api.AgentPoolProfile{Name: "bar", OSType: api.Windows},
 			w: api.WindowsProfile{
 				ImageRef: &api.ImageReference{
 					Name:          "tead",
@@ -59,8 +59,8 @@ func TestCreateWindowsImageReference(t *testing.T) {
 			},
 		},
 		{
-			name:        "Marketplace image",
-			profileName: "baz",
+			name:    "Marketplace image",
+			profile: api.AgentPoolProfile{Name: "baz", OSType: api.Windows},
 			w: api.WindowsProfile{
 				WindowsOffer:     "offer",
 				WindowsPublisher: "pub",
@@ -75,9 +75,50 @@ func TestCreateWindowsImageReference(t *testing.T) {
 			},
 		},
 		{
-			name:        "Default",
-			profileName: "qux",
-			w:           api.WindowsProfile{},
+			name: "Agent pool marketplace image",
+			profile: api.AgentPoolProfile{
+				Name:                "win2022",
+				OSType:              api.Windows,
+				WindowsSku:          "2022-datacenter-core-smalldisk",
+				WindowsImageVersion: "latest",
+			},
+			w: api.WindowsProfile{
+				WindowsImageSourceURL: "https://some/image.vhd",
+			},
+			expected: compute.ImageReference{
+				Offer:     to.StringPtr("[variables('win2022osImageOffer')]"),
+				Publisher: to.StringPtr("[variables('win2022osImagePublisher')]"),
+				Sku:       to.StringPtr("[variables('win2022osImageSKU')]"),
+				Version:   to.StringPtr("[variables('win2022osImageVersion')]"),
+			},
+		},
+		{
+			name: "Agent pool image gallery reference",
+			profile: api.AgentPoolProfile{
+				Name:   "canary",
+				OSType: api.Windows,
+				ImageRef: &api.ImageReference{
+					Gallery:        "gallery",
+					Name:           "test",
+					ResourceGroup:  "testRg",
+					SubscriptionID: "00000000-0000-0000-0000-000000000000",
+					Version:        "0.2.0",
+				},
+			},
+			w: api.WindowsProfile{
+				WindowsOffer:     "offer",
+				WindowsPublisher: "pub",
+				WindowsSku:       "sku",
+				ImageVersion:     "ver",
+			},
+			expected: compute.ImageReference{
+				ID: to.StringPtr("[concat('/subscriptions/', '00000000-0000-0000-0000-000000000000', '/resourceGroups/', variables('canaryosImageResourceGroup'), '/providers/Microsoft.Compute/galleries/', 'gallery', '/images/', variables('canaryosImageName'), '/versions/', '0.2.0')]"),
+			},
+		},
+		{
+			name:    "Default",
+			profile: api.AgentPoolProfile{Name: "qux", OSType: api.Windows},
+			w:       api.WindowsProfile{},
 			expected: compute.ImageReference{
 				Offer:     to.StringPtr("[parameters('agentWindowsOffer')]"),
 				Publisher: to.StringPtr("[parameters('agentWindowsPublisher')]"),
@@ -92,7 +133,7 @@ func TestCreateWindowsImageReference(t *testing.T) {
 		t.Run(c.name, func(t *testing.T) {
 			t.Parallel()
 
-			actual := createWindowsImageReference(c.profileName, &c.w)
+			actual := createWindowsImageReference(&c.profile, &c.w)
 			expected := &c.expected
 
 			diff := cmp.Diff(actual, expected)
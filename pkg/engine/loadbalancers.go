@@ -212,7 +212,7 @@ func CreateMasterLoadBalancer(prop *api.Properties, isVMSS bool) LoadBalancerARM
 			}
 			loadBalancer.InboundNatRules = &inboundNATRules
 		}
-	} else {
+	} else if prop.HasLoadBalancerOutboundRules() {
 		outboundRules := createOutboundRules(prop)
 		outboundRule := (*outboundRules)[0]
 		outboundRule.OutboundRulePropertiesFormat.BackendAddressPool.ID = to.StringPtr("[concat(variables('masterLbID'), '/backendAddressPools/', variables('masterLbBackendPoolName'))]")
@@ -300,7 +300,6 @@ func CreateStandardLoadBalancerForNodePools(prop *api.Properties, isVMSS bool) L
 						Name: to.StringPtr("[variables('agentLbBackendPoolName')]"),
 					},
 				},
-				OutboundRules: createOutboundRules(prop),
 			},
 			Sku: &network.LoadBalancerSku{
 				Name: "[variables('loadBalancerSku')]",
@@ -309,6 +308,11 @@ func CreateStandardLoadBalancerForNodePools(prop *api.Properties, isVMSS bool) L
 		},
 	}
 
+	// with userDefinedRouting or a NAT gateway, egress does not go through the load balancer
+	if prop.HasLoadBalancerOutboundRules() {
+		loadBalancer.LoadBalancer.LoadBalancerPropertiesFormat.OutboundRules = createOutboundRules(prop)
+	}
+
 	numIps := 1
 	if prop.OrchestratorProfile.KubernetesConfig.LoadBalancerOutboundIPs != nil {
 		numIps = *prop.OrchestratorProfile.KubernetesConfig.LoadBalancerOutboundIPs
@@ -887,3 +887,37 @@ func TestCreateAgentLoadBalancer(t *testing.T) {
 		t.Errorf("unexpected error while comparing load balancers: %s", diff)
 	}
 }
+
+func TestCreateLoadBalancersUserDefinedRouting(t *testing.T) {
+	cs := &api.ContainerService{
+		Properties: &api.Properties{
+			MasterProfile: &api.MasterProfile{
+				Count: 1,
+			},
+			OrchestratorProfile: &api.OrchestratorProfile{
+				OrchestratorVersion: "1.18.2",
+				KubernetesConfig: &api.KubernetesConfig{
+					LoadBalancerSku: StandardLoadBalancerSku,
+					OutboundType:    api.OutboundTypeUserDefinedRouting,
+					RouteTableID:    "/subscriptions/SUB_ID/resourceGroups/RG_NAME/providers/Microsoft.Network/routeTables/firewall-rt",
+					PrivateCluster: &api.PrivateCluster{
+						Enabled: to.BoolPtr(true),
+					},
+				},
+			},
+		},
+	}
+
+	agentLB := CreateStandardLoadBalancerForNodePools(cs.Properties, false)
+	if agentLB.LoadBalancerPropertiesFormat.OutboundRules != nil {
+		t.Errorf("expected no outbound rules on the node pools load balancer, got %v", *agentLB.LoadBalancerPropertiesFormat.OutboundRules)
+	}
+	if len(*agentLB.LoadBalancerPropertiesFormat.FrontendIPConfigurations) != 1 {
+		t.Errorf("expected the node pools load balancer to keep its frontend IP configuration")
+	}
+
+	masterLB := CreateMasterLoadBalancer(cs.Properties, false)
+	if masterLB.LoadBalancerPropertiesFormat.OutboundRules != nil {
+		t.Errorf("expected no outbound rules on the private cluster master load balancer, got %v", *masterLB.LoadBalancerPropertiesFormat.OutboundRules)
+	}
+}
@@ -29,15 +29,23 @@ func createKubernetesMasterResourcesVMAS(cs *api.ContainerService) []interface{}
 		masterResources = append(masterResources, availabilitySet, storageAccount)
 	}
 
+	if p.IsManagedNATGateway() {
+		masterResources = append(masterResources, createNATGatewayResources(cs)...)
+	}
+
 	if !p.MasterProfile.IsCustomVNET() {
 		virtualNetwork := CreateVirtualNetwork(cs)
-		masterResources = append(masterResources, virtualNetwork)
+		if p.IsNATGateway() {
+			masterResources = append(masterResources, associateNATGateway(cs, virtualNetwork))
+		} else {
+			masterResources = append(masterResources, virtualNetwork)
+		}
 	}
 
 	masterNsg := CreateNetworkSecurityGroup(cs)
 	masterResources = append(masterResources, masterNsg)
 
-	if cs.Properties.RequireRouteTable() {
+	if cs.Properties.RequireRouteTable() && !cs.Properties.IsUserDefinedRouting() {
 		masterResources = append(masterResources, createRouteTable())
 	}
 
@@ -167,12 +175,19 @@ func createKubernetesMasterResourcesVMSS(cs *api.ContainerService) []interface{}
 	masterNSG := CreateNetworkSecurityGroup(cs)
 	masterResources = append(masterResources, masterNSG)
 
-	if cs.Properties.RequireRouteTable() {
+	if cs.Properties.RequireRouteTable() && !cs.Properties.IsUserDefinedRouting() {
 		masterResources = append(masterResources, createRouteTable())
 	}
+	if cs.Properties.IsManagedNATGateway() {
+		masterResources = append(masterResources, createNATGatewayResources(cs)...)
+	}
 	if !cs.Properties.MasterProfile.IsCustomVNET() {
 		masterVNET := createVirtualNetworkVMSS(cs)
-		masterResources = append(masterResources, masterVNET)
+		if cs.Properties.IsNATGateway() {
+			masterResources = append(masterResources, associateNATGateway(cs, masterVNET))
+		} else {
+			masterResources = append(masterResources, masterVNET)
+		}
 	}
 
 	if cs.Properties.MasterProfile.HasMultipleNodes() {
@@ -0,0 +1,98 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package engine
+
+import (
+	"fmt"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/api/common"
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-08-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+// getNATGatewayPublicIPPrefixName returns the ARM expression, without brackets, of the name
+// of the i-th (1-based) public IP prefix of the managed NAT gateway
+func getNATGatewayPublicIPPrefixName(i int) string {
+	return fmt.Sprintf("concat(variables('natGatewayPublicIPPrefixName'), '-%d')", i)
+}
+
+func createNATGatewayPublicIPPrefixes(cs *api.ContainerService) []PublicIPPrefixARM {
+	count := cs.Properties.OrchestratorProfile.KubernetesConfig.NATGatewayProfile.PublicIPPrefixCount
+	prefixes := make([]PublicIPPrefixARM, 0, count)
+	for i := 1; i <= count; i++ {
+		prefixes = append(prefixes, PublicIPPrefixARM{
+			ARMResource: ARMResource{
+				APIVersion: "[variables('apiVersionNetwork')]",
+			},
+			PublicIPPrefix: network.PublicIPPrefix{
+				Location: to.StringPtr("[variables('location')]"),
+				Name:     to.StringPtr("[" + getNATGatewayPublicIPPrefixName(i) + "]"),
+				PublicIPPrefixPropertiesFormat: &network.PublicIPPrefixPropertiesFormat{
+					PublicIPAddressVersion: network.IPv4,
+					PrefixLength:           to.Int32Ptr(common.NATGatewayPublicIPPrefixLength),
+				},
+				Sku: &network.PublicIPPrefixSku{
+					Name: network.Standard,
+				},
+				Type: to.StringPtr("Microsoft.Network/publicIPPrefixes"),
+			},
+		})
+	}
+	return prefixes
+}
+
+func createNATGateway(cs *api.ContainerService) NATGatewayARM {
+	natGatewayProfile := cs.Properties.OrchestratorProfile.KubernetesConfig.NATGatewayProfile
+	dependencies := []string{}
+	publicIPPrefixes := []network.SubResource{}
+	for i := 1; i <= natGatewayProfile.PublicIPPrefixCount; i++ {
+		name := getNATGatewayPublicIPPrefixName(i)
+		dependencies = append(dependencies, fmt.Sprintf("[concat('Microsoft.Network/publicIPPrefixes/', %s)]", name))
+		publicIPPrefixes = append(publicIPPrefixes, network.SubResource{
+			ID: to.StringPtr(fmt.Sprintf("[resourceId('Microsoft.Network/publicIPPrefixes', %s)]", name)),
+		})
+	}
+
+	return NATGatewayARM{
+		ARMResource: ARMResource{
+			APIVersion: "[variables('apiVersionNATGateway')]",
+			DependsOn:  dependencies,
+		},
+		NATGateway: NATGateway{
+			Location: to.StringPtr("[variables('location')]"),
+			Name:     to.StringPtr("[variables('natGatewayName')]"),
+			Type:     to.StringPtr("Microsoft.Network/natGateways"),
+			Sku: &NATGatewaySku{
+				Name: "Standard",
+			},
+			NATGatewayPropertiesFormat: &NATGatewayPropertiesFormat{
+				IdleTimeoutInMinutes: to.Int32Ptr(natGatewayProfile.IdleTimeoutInMinutes),
+				PublicIPPrefixes:     &publicIPPrefixes,
+			},
+		},
+	}
+}
+
+// createNATGatewayResources returns the managed NAT gateway and its public IP prefixes
+func createNATGatewayResources(cs *api.ContainerService) []interface{} {
+	var resources []interface{}
+	for _, prefix := range createNATGatewayPublicIPPrefixes(cs) {
+		resources = append(resources, prefix)
+	}
+	return append(resources, createNATGateway(cs))
+}
+
+// associateNATGateway returns vnet with its subnets associated with the cluster NAT gateway
+func associateNATGateway(cs *api.ContainerService, vnet VirtualNetworkARM) NATGatewayVirtualNetworkARM {
+	// natGateway is not a subnet property in the default network API version
+	vnet.APIVersion = "[variables('apiVersionNATGateway')]"
+	if cs.Properties.IsManagedNATGateway() {
+		vnet.DependsOn = append(vnet.DependsOn, "[concat('Microsoft.Network/natGateways/', variables('natGatewayName'))]")
+	}
+	return NATGatewayVirtualNetworkARM{
+		VirtualNetworkARM: vnet,
+		NATGatewayID:      "[variables('natGatewayID')]",
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/api/common"
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-08-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+)
+
+func getNATGatewayContainerService(outboundType string) *api.ContainerService {
+	cs := &api.ContainerService{
+		Properties: &api.Properties{
+			OrchestratorProfile: &api.OrchestratorProfile{
+				OrchestratorType: api.Kubernetes,
+				KubernetesConfig: &api.KubernetesConfig{
+					OutboundType:    outboundType,
+					LoadBalancerSku: api.StandardLoadBalancerSku,
+				},
+			},
+		},
+	}
+	if outboundType == api.OutboundTypeManagedNATGateway {
+		cs.Properties.OrchestratorProfile.KubernetesConfig.NATGatewayProfile = &api.NATGatewayProfile{
+			IdleTimeoutInMinutes: 10,
+			PublicIPPrefixCount:  2,
+		}
+	}
+	return cs
+}
+
+func TestCreateNATGatewayResources(t *testing.T) {
+	cs := getNATGatewayContainerService(api.OutboundTypeManagedNATGateway)
+
+	resources := createNATGatewayResources(cs)
+	if len(resources) != 3 {
+		t.Fatalf("expected 2 public IP prefixes and a NAT gateway, got %d resources", len(resources))
+	}
+
+	expectedPrefix := PublicIPPrefixARM{
+		ARMResource: ARMResource{
+			APIVersion: "[variables('apiVersionNetwork')]",
+		},
+		PublicIPPrefix: network.PublicIPPrefix{
+			Location: to.StringPtr("[variables('location')]"),
+			Name:     to.StringPtr("[concat(variables('natGatewayPublicIPPrefixName'), '-2')]"),
+			PublicIPPrefixPropertiesFormat: &network.PublicIPPrefixPropertiesFormat{
+				PublicIPAddressVersion: network.IPv4,
+				PrefixLength:           to.Int32Ptr(common.NATGatewayPublicIPPrefixLength),
+			},
+			Sku: &network.PublicIPPrefixSku{
+				Name: network.Standard,
+			},
+			Type: to.StringPtr("Microsoft.Network/publicIPPrefixes"),
+		},
+	}
+
+	diff := cmp.Diff(resources[1], expectedPrefix)
+	if diff != "" {
+		t.Errorf("unexpected diff while comparing public IP prefixes: %s", diff)
+	}
+
+	expectedNATGateway := NATGatewayARM{
+		ARMResource: ARMResource{
+			APIVersion: "[variables('apiVersionNATGateway')]",
+			DependsOn: []string{
+				"[concat('Microsoft.Network/publicIPPrefixes/', concat(variables('natGatewayPublicIPPrefixName'), '-1'))]",
+				"[concat('Microsoft.Network/publicIPPrefixes/', concat(variables('natGatewayPublicIPPrefixName'), '-2'))]",
+			},
+		},
+		NATGateway: NATGateway{
+			Location: to.StringPtr("[variables('location')]"),
+			Name:     to.StringPtr("[variables('natGatewayName')]"),
+			Type:     to.StringPtr("Microsoft.Network/natGateways"),
+			Sku: &NATGatewaySku{
+				Name: "Standard",
+			},
+			NATGatewayPropertiesFormat: &NATGatewayPropertiesFormat{
+				IdleTimeoutInMinutes: to.Int32Ptr(10),
+				PublicIPPrefixes: &[]network.SubResource{
+					{ID: to.StringPtr("[resourceId('Microsoft.Network/publicIPPrefixes', concat(variables('natGatewayPublicIPPrefixName'), '-1'))]")},
+					{ID: to.StringPtr("[resourceId('Microsoft.Network/publicIPPrefixes', concat(variables('natGatewayPublicIPPrefixName'), '-2'))]")},
+				},
+			},
+		},
+	}
+
+	diff = cmp.Diff(resources[2], expectedNATGateway)
+	if diff != "" {
+		t.Errorf("unexpected diff while comparing NAT gateways: %s", diff)
+	}
+}
+
+func TestAssociateNATGateway(t *testing.T) {
+	cases := []struct {
+		name                 string
+		outboundType         string
+		expectedDependencies []string
+	}{
+		{
+			name:         "managed NAT gateway",
+			outboundType: api.OutboundTypeManagedNATGateway,
+			expectedDependencies: []string{
+				"[concat('Microsoft.Network/networkSecurityGroups/', variables('nsgName'))]",
+				"[concat('Microsoft.Network/routeTables/', variables('routeTableName'))]",
+				"[concat('Microsoft.Network/natGateways/', variables('natGatewayName'))]",
+			},
+		},
+		{
+			name:         "user-assigned NAT gateway",
+			outboundType: api.OutboundTypeUserAssignedNATGateway,
+			expectedDependencies: []string{
+				"[concat('Microsoft.Network/networkSecurityGroups/', variables('nsgName'))]",
+				"[concat('Microsoft.Network/routeTables/', variables('routeTableName'))]",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			cs := getNATGatewayContainerService(c.outboundType)
+			cs.Properties.OrchestratorProfile.KubernetesConfig.Addons = []api.KubernetesAddon{
+				{
+					Name:    common.AppGwIngressAddonName,
+					Enabled: to.BoolPtr(true),
+				},
+			}
+
+			vnet := associateNATGateway(cs, createVirtualNetworkVMSS(cs))
+			if vnet.APIVersion != "[variables('apiVersionNATGateway')]" {
+				t.Errorf("expected the NAT gateway API version, got %s", vnet.APIVersion)
+			}
+			diff := cmp.Diff(vnet.DependsOn, c.expectedDependencies)
+			if diff != "" {
+				t.Errorf("unexpected diff while comparing dependencies: %s", diff)
+			}
+
+			b, err := json.Marshal(vnet)
+			if err != nil {
+				t.Fatalf("unexpected error marshaling the virtual network: %s", err)
+			}
+			var resource struct {
+				Name       string `json:"name"`
+				Properties struct {
+					Subnets []struct {
+						Name       string `json:"name"`
+						Properties struct {
+							AddressPrefix string `json:"addressPrefix"`
+							NATGateway    *struct {
+								ID string `json:"id"`
+							} `json:"natGateway"`
+						} `json:"properties"`
+					} `json:"subnets"`
+				} `json:"properties"`
+			}
+			if err = json.Unmarshal(b, &resource); err != nil {
+				t.Fatalf("unexpected error unmarshaling the virtual network: %s", err)
+			}
+			if resource.Name != "[variables('virtualNetworkName')]" {
+				t.Errorf("expected the virtual network name to be kept, got %s", resource.Name)
+			}
+			if len(resource.Properties.Subnets) != 3 {
+				t.Fatalf("expected 3 subnets, got %d", len(resource.Properties.Subnets))
+			}
+			for _, subnet := range resource.Properties.Subnets {
+				if subnet.Properties.AddressPrefix == "" {
+					t.Errorf("expected subnet %s to keep its address prefix", subnet.Name)
+				}
+				if subnet.Name == "[variables('appGwSubnetName')]" {
+					if subnet.Properties.NATGateway != nil {
+						t.Errorf("expected the application gateway subnet not to be associated with the NAT gateway")
+					}
+					continue
+				}
+				if subnet.Properties.NATGateway == nil || subnet.Properties.NATGateway.ID != "[variables('natGatewayID')]" {
+					t.Errorf("expected subnet %s to be associated with the NAT gateway", subnet.Name)
+				}
+			}
+		})
+	}
+}
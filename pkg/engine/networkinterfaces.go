@@ -115,7 +115,7 @@ func CreateMasterVMNetworkInterfaces(cs *api.ContainerService) NetworkInterfaceA
 		}
 	}
 
-	if cs.Properties.MasterProfile != nil && cs.Properties.MasterProfile.IsCustomVNET() {
+	if cs.Properties.MasterProfile != nil && (cs.Properties.MasterProfile.IsCustomVNET() || cs.Properties.HasAgentPoolNetworkSecurityGroups()) {
 		nicProperties.NetworkSecurityGroup = &network.SecurityGroup{
 			ID: to.StringPtr("[variables('nsgID')]"),
 		}
@@ -216,7 +216,7 @@ func createPrivateClusterMasterVMNetworkInterface(cs *api.ContainerService) Netw
 		}
 	}
 
-	if cs.Properties.MasterProfile.IsCustomVNET() {
+	if cs.Properties.MasterProfile.IsCustomVNET() || cs.Properties.HasAgentPoolNetworkSecurityGroups() {
 		nicProperties.NetworkSecurityGroup = &network.SecurityGroup{
 			ID: to.StringPtr("[variables('nsgID')]"),
 		}
@@ -313,6 +313,9 @@ func createAgentVMASNetworkInterface(cs *api.ContainerService, profile *api.Agen
 	} else {
 		dependencies = append(dependencies, "[variables('vnetID')]")
 	}
+	if profile.HasNetworkSecurityGroup() {
+		dependencies = append(dependencies, fmt.Sprintf("[variables('%sNetworkSecurityGroupID')]", profile.Name))
+	}
 	if profile.LoadBalancerBackendAddressPoolIDs == nil &&
 		cs.Properties.OrchestratorProfile.KubernetesConfig.LoadBalancerSku == api.StandardLoadBalancerSku {
 		dependencies = append(dependencies, "[variables('agentLbID')]")
@@ -328,7 +331,13 @@ func createAgentVMASNetworkInterface(cs *api.ContainerService, profile *api.Agen
 
 	networkInterface.InterfacePropertiesFormat = &network.InterfacePropertiesFormat{}
 
-	if isCustomVNet {
+	if profile.HasNetworkSecurityGroup() {
+		networkInterface.NetworkSecurityGroup = &network.SecurityGroup{
+			ID: to.StringPtr(fmt.Sprintf("[variables('%sNetworkSecurityGroupID')]", profile.Name)),
+		}
+	} else if isCustomVNet || cs.Properties.HasAgentPoolNetworkSecurityGroups() {
+		// the cluster network security group is attached to the network interfaces of a custom VNET,
+		// and to those of every pool once one pool has its own, since it is then detached from the subnets
 		networkInterface.NetworkSecurityGroup = &network.SecurityGroup{
 			ID: to.StringPtr("[variables('nsgID')]"),
 		}
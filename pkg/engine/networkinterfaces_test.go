@@ -1066,3 +1066,46 @@ func TestCreateAgentVMASNICWithIPv6DualStackFeature(t *testing.T) {
 		t.Errorf("unexpected diff while comparing: %s", diff)
 	}
 }
+
+func TestCreateAgentVMASNICNetworkSecurityGroup(t *testing.T) {
+	newContainerService := func() *api.ContainerService {
+		return &api.ContainerService{
+			Properties: &api.Properties{
+				MasterProfile: &api.MasterProfile{
+					Count:     1,
+					DNSPrefix: "myprefix1",
+					VMSize:    "Standard_DS2_v2",
+				},
+				OrchestratorProfile: &api.OrchestratorProfile{
+					OrchestratorType: api.Kubernetes,
+					KubernetesConfig: &api.KubernetesConfig{},
+				},
+				AgentPoolProfiles: []*api.AgentPoolProfile{
+					{Name: "pool1", Count: 1, AvailabilityProfile: api.AvailabilitySet},
+					{Name: "pool2", Count: 1, AvailabilityProfile: api.AvailabilitySet},
+				},
+			},
+		}
+	}
+	nsgID := func(nic NetworkInterfaceARM) string {
+		if nic.NetworkSecurityGroup == nil {
+			return ""
+		}
+		return to.String(nic.NetworkSecurityGroup.ID)
+	}
+
+	// the cluster network security group is only on the subnet
+	cs := newContainerService()
+	if actual := nsgID(createAgentVMASNetworkInterface(cs, cs.Properties.AgentPoolProfiles[0])); actual != "" {
+		t.Errorf("expected no network security group on the network interfaces, got %s", actual)
+	}
+
+	cs = newContainerService()
+	cs.Properties.AgentPoolProfiles[1].NetworkSecurityRules = []api.NetworkSecurityRule{{Name: "allow_nodeports"}}
+	if actual := nsgID(createAgentVMASNetworkInterface(cs, cs.Properties.AgentPoolProfiles[1])); actual != "[variables('pool2NetworkSecurityGroupID')]" {
+		t.Errorf("expected the pool network security group on the network interfaces of the pool, got %s", actual)
+	}
+	if actual := nsgID(createAgentVMASNetworkInterface(cs, cs.Properties.AgentPoolProfiles[0])); actual != "[variables('nsgID')]" {
+		t.Errorf("expected the cluster network security group on the network interfaces of the other pools, got %s", actual)
+	}
+}
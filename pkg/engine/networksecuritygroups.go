@@ -4,7 +4,10 @@
 package engine
 
 import (
+	"fmt"
+
 	"github.com/Azure/aks-engine/pkg/api"
+	"github.com/Azure/aks-engine/pkg/api/common"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-08-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 )
@@ -14,8 +17,51 @@ func CreateNetworkSecurityGroup(cs *api.ContainerService) NetworkSecurityGroupAR
 		APIVersion: "[variables('apiVersionNetwork')]",
 	}
 
+	securityRules := getClusterSecurityRules(cs)
+
+	nsg := network.SecurityGroup{
+		Location: to.StringPtr("[variables('location')]"),
+		Name:     to.StringPtr("[variables('nsgName')]"),
+		Type:     to.StringPtr("Microsoft.Network/networkSecurityGroups"),
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &securityRules,
+		},
+	}
+
+	return NetworkSecurityGroupARM{
+		ARMResource:   armResource,
+		SecurityGroup: nsg,
+	}
+}
+
+// createAgentPoolNetworkSecurityGroup returns the network security group dedicated to an agent pool,
+// which replaces the cluster network security group on the nodes of the pool
+func createAgentPoolNetworkSecurityGroup(cs *api.ContainerService, profile *api.AgentPoolProfile) NetworkSecurityGroupARM {
+	armResource := ARMResource{
+		APIVersion: "[variables('apiVersionNetwork')]",
+	}
+
+	securityRules := getAgentPoolSecurityRules(cs, profile)
+
+	nsg := network.SecurityGroup{
+		Location: to.StringPtr("[variables('location')]"),
+		Name:     to.StringPtr(fmt.Sprintf("[variables('%sNetworkSecurityGroupName')]", profile.Name)),
+		Type:     to.StringPtr("Microsoft.Network/networkSecurityGroups"),
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &securityRules,
+		},
+	}
+
+	return NetworkSecurityGroupARM{
+		ARMResource:   armResource,
+		SecurityGroup: nsg,
+	}
+}
+
+// getClusterSecurityRules returns the default security rules merged with the custom cluster rules
+func getClusterSecurityRules(cs *api.ContainerService) []network.SecurityRule {
 	sshRule := network.SecurityRule{
-		Name: to.StringPtr("allow_ssh"),
+		Name: to.StringPtr(common.NetworkSecurityRuleAllowSSH),
 		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
 			Access:                   network.SecurityRuleAccessAllow,
 			Description:              to.StringPtr("Allow SSH traffic to master"),
@@ -30,7 +76,7 @@ func CreateNetworkSecurityGroup(cs *api.ContainerService) NetworkSecurityGroupAR
 	}
 
 	kubeTLSRule := network.SecurityRule{
-		Name: to.StringPtr("allow_kube_tls"),
+		Name: to.StringPtr(common.NetworkSecurityRuleAllowKubeTLS),
 		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
 			Access:                   network.SecurityRuleAccessAllow,
 			Description:              to.StringPtr("Allow kube-apiserver (tls) traffic to master"),
@@ -55,71 +101,149 @@ func CreateNetworkSecurityGroup(cs *api.ContainerService) NetworkSecurityGroupAR
 	}
 
 	if cs.Properties.HasWindows() {
-		rdpRule := network.SecurityRule{
-			Name: to.StringPtr("allow_rdp"),
-			SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
-				Access:                   network.SecurityRuleAccessAllow,
-				Description:              to.StringPtr("Allow RDP traffic to master"),
-				DestinationAddressPrefix: to.StringPtr("*"),
-				DestinationPortRange:     to.StringPtr("3389-3389"),
-				Direction:                network.SecurityRuleDirectionInbound,
-				Priority:                 to.Int32Ptr(102),
-				Protocol:                 network.SecurityRuleProtocolTCP,
-				SourceAddressPrefix:      to.StringPtr("*"),
-				SourcePortRange:          to.StringPtr("*"),
-			},
-		}
+		securityRules = append(securityRules, getRDPSecurityRule())
+	}
+
+	securityRules = append(securityRules, getBlockOutboundSecurityRules(cs)...)
+
+	return mergeSecurityRules(securityRules, cs.Properties.NetworkSecurityRules)
+}
 
-		securityRules = append(securityRules, rdpRule)
+// getAgentPoolSecurityRules returns the default security rules that apply to the nodes of an agent pool,
+// merged with the custom cluster rules and then with the custom rules of the pool
+func getAgentPoolSecurityRules(cs *api.ContainerService, profile *api.AgentPoolProfile) []network.SecurityRule {
+	var securityRules []network.SecurityRule
+
+	if profile.IsWindows() {
+		securityRules = append(securityRules, getRDPSecurityRule())
 	}
 
-	if cs.Properties.FeatureFlags.IsFeatureEnabled("BlockOutboundInternet") {
-		vnetRule := network.SecurityRule{
-			Name: to.StringPtr("allow_vnet"),
-			SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
-				Access:                   network.SecurityRuleAccessAllow,
-				Description:              to.StringPtr("Allow outbound internet to vnet"),
-				DestinationAddressPrefix: to.StringPtr("[parameters('masterSubnet')]"),
-				DestinationPortRange:     to.StringPtr("*"),
-				Direction:                network.SecurityRuleDirectionOutbound,
-				Priority:                 to.Int32Ptr(110),
-				Protocol:                 network.SecurityRuleProtocolAsterisk,
-				SourceAddressPrefix:      to.StringPtr("VirtualNetwork"),
-				SourcePortRange:          to.StringPtr("*"),
-			},
-		}
+	securityRules = append(securityRules, getBlockOutboundSecurityRules(cs)...)
 
-		blockOutBoundRule := network.SecurityRule{
-			Name: to.StringPtr("block_outbound"),
-			SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
-				Access:                   network.SecurityRuleAccessDeny,
-				Description:              to.StringPtr("Block outbound internet from master"),
-				DestinationAddressPrefix: to.StringPtr("*"),
-				DestinationPortRange:     to.StringPtr("*"),
-				Direction:                network.SecurityRuleDirectionOutbound,
-				Priority:                 to.Int32Ptr(120),
-				Protocol:                 network.SecurityRuleProtocolAsterisk,
-				SourceAddressPrefix:      to.StringPtr("*"),
-				SourcePortRange:          to.StringPtr("*"),
-			},
-		}
+	return mergeSecurityRules(mergeSecurityRules(securityRules, cs.Properties.NetworkSecurityRules), profile.NetworkSecurityRules)
+}
 
-		securityRules = append(securityRules, vnetRule)
-		securityRules = append(securityRules, blockOutBoundRule)
+func getRDPSecurityRule() network.SecurityRule {
+	return network.SecurityRule{
+		Name: to.StringPtr(common.NetworkSecurityRuleAllowRDP),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Access:                   network.SecurityRuleAccessAllow,
+			Description:              to.StringPtr("Allow RDP traffic to master"),
+			DestinationAddressPrefix: to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr("3389-3389"),
+			Direction:                network.SecurityRuleDirectionInbound,
+			Priority:                 to.Int32Ptr(102),
+			Protocol:                 network.SecurityRuleProtocolTCP,
+			SourceAddressPrefix:      to.StringPtr("*"),
+			SourcePortRange:          to.StringPtr("*"),
+		},
 	}
+}
 
-	nsg := network.SecurityGroup{
-		Location: to.StringPtr("[variables('location')]"),
-		Name:     to.StringPtr("[variables('nsgName')]"),
-		Type:     to.StringPtr("Microsoft.Network/networkSecurityGroups"),
-		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
-			SecurityRules: &securityRules,
+func getBlockOutboundSecurityRules(cs *api.ContainerService) []network.SecurityRule {
+	if !cs.Properties.FeatureFlags.IsFeatureEnabled("BlockOutboundInternet") {
+		return nil
+	}
+
+	vnetRule := network.SecurityRule{
+		Name: to.StringPtr(common.NetworkSecurityRuleAllowVNET),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Access:                   network.SecurityRuleAccessAllow,
+			Description:              to.StringPtr("Allow outbound internet to vnet"),
+			DestinationAddressPrefix: to.StringPtr("[parameters('masterSubnet')]"),
+			DestinationPortRange:     to.StringPtr("*"),
+			Direction:                network.SecurityRuleDirectionOutbound,
+			Priority:                 to.Int32Ptr(110),
+			Protocol:                 network.SecurityRuleProtocolAsterisk,
+			SourceAddressPrefix:      to.StringPtr("VirtualNetwork"),
+			SourcePortRange:          to.StringPtr("*"),
 		},
 	}
 
-	return NetworkSecurityGroupARM{
-		ARMResource:   armResource,
-		SecurityGroup: nsg,
+	blockOutBoundRule := network.SecurityRule{
+		Name: to.StringPtr(common.NetworkSecurityRuleBlockOutbound),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Access:                   network.SecurityRuleAccessDeny,
+			Description:              to.StringPtr("Block outbound internet from master"),
+			DestinationAddressPrefix: to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr("*"),
+			Direction:                network.SecurityRuleDirectionOutbound,
+			Priority:                 to.Int32Ptr(120),
+			Protocol:                 network.SecurityRuleProtocolAsterisk,
+			SourceAddressPrefix:      to.StringPtr("*"),
+			SourcePortRange:          to.StringPtr("*"),
+		},
+	}
+
+	return []network.SecurityRule{vnetRule, blockOutBoundRule}
+}
+
+// mergeSecurityRules appends the custom rules to rules, a custom rule replaces the rule with the same name
+func mergeSecurityRules(rules []network.SecurityRule, customRules []api.NetworkSecurityRule) []network.SecurityRule {
+	merged := append([]network.SecurityRule{}, rules...)
+	for _, customRule := range customRules {
+		securityRule := convertToSecurityRule(customRule)
+		replaced := false
+		for i := range merged {
+			if to.String(merged[i].Name) == customRule.Name {
+				merged[i] = securityRule
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, securityRule)
+		}
+	}
+	return merged
+}
+
+// convertToSecurityRule converts an api model rule, missing prefixes and port ranges mean any
+func convertToSecurityRule(r api.NetworkSecurityRule) network.SecurityRule {
+	props := &network.SecurityRulePropertiesFormat{
+		Access:          network.SecurityRuleAccess(r.Access),
+		Direction:       network.SecurityRuleDirection(r.Direction),
+		Priority:        to.Int32Ptr(r.Priority),
+		Protocol:        network.SecurityRuleProtocol(r.Protocol),
+		SourcePortRange: to.StringPtr("*"),
+	}
+	if r.Description != "" {
+		props.Description = to.StringPtr(r.Description)
+	}
+	if r.SourcePortRange != "" {
+		props.SourcePortRange = to.StringPtr(r.SourcePortRange)
+	}
+
+	switch len(r.SourceAddressPrefixes) {
+	case 0:
+		props.SourceAddressPrefix = to.StringPtr("*")
+	case 1:
+		props.SourceAddressPrefix = to.StringPtr(r.SourceAddressPrefixes[0])
+	default:
+		props.SourceAddressPrefixes = to.StringSlicePtr(r.SourceAddressPrefixes)
+	}
+
+	switch len(r.DestinationAddressPrefixes) {
+	case 0:
+		props.DestinationAddressPrefix = to.StringPtr("*")
+	case 1:
+		props.DestinationAddressPrefix = to.StringPtr(r.DestinationAddressPrefixes[0])
+	default:
+		props.DestinationAddressPrefixes = to.StringSlicePtr(r.DestinationAddressPrefixes)
+	}
+
+	switch len(r.DestinationPortRanges) {
+	case 0:
+		props.DestinationPortRange = to.StringPtr("*")
+	case 1:
+		props.DestinationPortRange = to.StringPtr(r.DestinationPortRanges[0])
+	default:
+		props.DestinationPortRanges = to.StringSlicePtr(r.DestinationPortRanges)
+	}
+
+	return network.SecurityRule{
+		Name:                         to.StringPtr(r.Name),
+		SecurityRulePropertiesFormat: props,
 	}
 }
 
@@ -213,3 +213,129 @@ func TestCreateJumpboxNSG(t *testing.T) {
 		t.Errorf("unexpected diff while comparing nsgs : %s", diff)
 	}
 }
+
+func TestCreateNetworkSecurityGroupCustomRules(t *testing.T) {
+	cs := &api.ContainerService{
+		Properties: &api.Properties{
+			OrchestratorProfile: &api.OrchestratorProfile{
+				KubernetesConfig: &api.KubernetesConfig{},
+			},
+			AgentPoolProfiles: []*api.AgentPoolProfile{
+				{
+					Name:   "pool1",
+					OSType: "Linux",
+					NetworkSecurityRules: []api.NetworkSecurityRule{
+						{
+							Name:                  "allow_nodeports",
+							Priority:              200,
+							Direction:             "Inbound",
+							Access:                "Allow",
+							Protocol:              "Tcp",
+							SourceAddressPrefixes: []string{"10.1.0.0/16", "10.2.0.0/16"},
+							DestinationPortRanges: []string{"30000-32767"},
+						},
+					},
+				},
+			},
+			FeatureFlags: &api.FeatureFlags{},
+			NetworkSecurityRules: []api.NetworkSecurityRule{
+				{
+					Name:                  "allow_ssh",
+					Description:           "Allow SSH from the bastion",
+					Priority:              101,
+					Direction:             "Inbound",
+					Access:                "Allow",
+					Protocol:              "Tcp",
+					SourceAddressPrefixes: []string{"192.168.0.4/32"},
+					DestinationPortRanges: []string{"22"},
+				},
+				{
+					Name:                  "deny_smtp",
+					Priority:              300,
+					Direction:             "Outbound",
+					Access:                "Deny",
+					Protocol:              "Tcp",
+					DestinationPortRanges: []string{"25"},
+				},
+			},
+		},
+	}
+
+	sshRule := network.SecurityRule{
+		Name: to.StringPtr("allow_ssh"),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Access:                   network.SecurityRuleAccessAllow,
+			Description:              to.StringPtr("Allow SSH from the bastion"),
+			DestinationAddressPrefix: to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr("22"),
+			Direction:                network.SecurityRuleDirectionInbound,
+			Priority:                 to.Int32Ptr(101),
+			Protocol:                 network.SecurityRuleProtocolTCP,
+			SourceAddressPrefix:      to.StringPtr("192.168.0.4/32"),
+			SourcePortRange:          to.StringPtr("*"),
+		},
+	}
+	smtpRule := network.SecurityRule{
+		Name: to.StringPtr("deny_smtp"),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Access:                   network.SecurityRuleAccessDeny,
+			DestinationAddressPrefix: to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr("25"),
+			Direction:                network.SecurityRuleDirectionOutbound,
+			Priority:                 to.Int32Ptr(300),
+			Protocol:                 network.SecurityRuleProtocolTCP,
+			SourceAddressPrefix:      to.StringPtr("*"),
+			SourcePortRange:          to.StringPtr("*"),
+		},
+	}
+	nodePortRule := network.SecurityRule{
+		Name: to.StringPtr("allow_nodeports"),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Access:                   network.SecurityRuleAccessAllow,
+			DestinationAddressPrefix: to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr("30000-32767"),
+			Direction:                network.SecurityRuleDirectionInbound,
+			Priority:                 to.Int32Ptr(200),
+			Protocol:                 network.SecurityRuleProtocolTCP,
+			SourceAddressPrefixes:    &[]string{"10.1.0.0/16", "10.2.0.0/16"},
+			SourcePortRange:          to.StringPtr("*"),
+		},
+	}
+
+	actual := CreateNetworkSecurityGroup(cs)
+	rules := *actual.SecurityRules
+	if len(rules) != 3 {
+		t.Fatalf("expected 3 security rules in the cluster nsg, got %d", len(rules))
+	}
+	// the custom allow_ssh rule replaces the default one in place
+	if diff := cmp.Diff(rules[0], sshRule); diff != "" {
+		t.Errorf("unexpected diff while comparing allow_ssh rules: %s", diff)
+	}
+	if to.String(rules[1].Name) != "allow_kube_tls" {
+		t.Errorf("expected the default allow_kube_tls rule to be kept, got %s", to.String(rules[1].Name))
+	}
+	if diff := cmp.Diff(rules[2], smtpRule); diff != "" {
+		t.Errorf("unexpected diff while comparing deny_smtp rules: %s", diff)
+	}
+
+	poolNSG := createAgentPoolNetworkSecurityGroup(cs, cs.Properties.AgentPoolProfiles[0])
+	if to.String(poolNSG.Name) != "[variables('pool1NetworkSecurityGroupName')]" {
+		t.Errorf("unexpected pool nsg name %s", to.String(poolNSG.Name))
+	}
+	// the default master rules are left out of the pool nsg, the custom cluster rules are kept
+	poolRules := *poolNSG.SecurityRules
+	if diff := cmp.Diff(poolRules, []network.SecurityRule{sshRule, smtpRule, nodePortRule}); diff != "" {
+		t.Errorf("unexpected diff while comparing the pool nsg rules: %s", diff)
+	}
+
+	cs.Properties.AgentPoolProfiles[0].OSType = api.Windows
+	cs.Properties.FeatureFlags.BlockOutboundInternet = true
+	poolRules = *createAgentPoolNetworkSecurityGroup(cs, cs.Properties.AgentPoolProfiles[0]).SecurityRules
+	var names []string
+	for _, r := range poolRules {
+		names = append(names, to.String(r.Name))
+	}
+	if diff := cmp.Diff(names, []string{"allow_rdp", "allow_vnet", "block_outbound", "allow_ssh", "deny_smtp", "allow_nodeports"}); diff != "" {
+		t.Errorf("unexpected diff while comparing the windows pool nsg rules: %s", diff)
+	}
+}
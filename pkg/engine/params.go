@@ -103,16 +103,26 @@ func getParameters(cs *api.ContainerService, generatorCode string, aksEngineVers
 		}
 
 		// Unless distro is defined, default distro is configured by defaults#setAgentProfileDefaults
-		//   Ignores Windows OS
+		//   Windows agent pools only get OS image values when they override the image of the windowsProfile
 		if !(agentProfile.OSType == api.Windows) {
 			if agentProfile.ImageRef != nil {
 				addValue(parametersMap, fmt.Sprintf("%sosImageName", agentProfile.Name), agentProfile.ImageRef.Name)
 				addValue(parametersMap, fmt.Sprintf("%sosImageResourceGroup", agentProfile.Name), agentProfile.ImageRef.ResourceGroup)
 			}
-			addValue(parametersMap, fmt.Sprintf("%sosImageOffer", agentProfile.Name), cloudSpecConfig.OSImageConfig[agentProfile.Distro].ImageOffer)
-			addValue(parametersMap, fmt.Sprintf("%sosImageSKU", agentProfile.Name), cloudSpecConfig.OSImageConfig[agentProfile.Distro].ImageSku)
-			addValue(parametersMap, fmt.Sprintf("%sosImagePublisher", agentProfile.Name), cloudSpecConfig.OSImageConfig[agentProfile.Distro].ImagePublisher)
-			addValue(parametersMap, fmt.Sprintf("%sosImageVersion", agentProfile.Name), cloudSpecConfig.OSImageConfig[agentProfile.Distro].ImageVersion)
+			imageConfig := agentProfile.GetOSImageConfig(cloudSpecConfig)
+			addValue(parametersMap, fmt.Sprintf("%sosImageOffer", agentProfile.Name), imageConfig.ImageOffer)
+			addValue(parametersMap, fmt.Sprintf("%sosImageSKU", agentProfile.Name), imageConfig.ImageSku)
+			addValue(parametersMap, fmt.Sprintf("%sosImagePublisher", agentProfile.Name), imageConfig.ImagePublisher)
+			addValue(parametersMap, fmt.Sprintf("%sosImageVersion", agentProfile.Name), imageConfig.ImageVersion)
+		} else if agentProfile.HasImageRef() {
+			addValue(parametersMap, fmt.Sprintf("%sosImageName", agentProfile.Name), agentProfile.ImageRef.Name)
+			addValue(parametersMap, fmt.Sprintf("%sosImageResourceGroup", agentProfile.Name), agentProfile.ImageRef.ResourceGroup)
+		} else if agentProfile.HasWindowsImageOverride() {
+			imageConfig := agentProfile.GetWindowsImageConfig(properties.WindowsProfile)
+			addValue(parametersMap, fmt.Sprintf("%sosImageOffer", agentProfile.Name), imageConfig.ImageOffer)
+			addValue(parametersMap, fmt.Sprintf("%sosImageSKU", agentProfile.Name), imageConfig.ImageSku)
+			addValue(parametersMap, fmt.Sprintf("%sosImagePublisher", agentProfile.Name), imageConfig.ImagePublisher)
+			addValue(parametersMap, fmt.Sprintf("%sosImageVersion", agentProfile.Name), imageConfig.ImageVersion)
 		}
 	}
 
@@ -121,6 +131,18 @@ func getParameters(cs *api.ContainerService, generatorCode string, aksEngineVers
 		addValue(parametersMap, "windowsAdminUsername", properties.WindowsProfile.AdminUsername)
 		addSecret(parametersMap, "windowsAdminPassword", properties.WindowsProfile.AdminPassword, false)
 
+		if properties.WindowsProfile.IsGMSADomainJoinEnabled() {
+			gmsa := properties.WindowsProfile.GMSA
+			if gmsa.DomainJoinPasswordKeyvaultSecretRef != nil {
+				addKeyvaultReference(parametersMap, "windowsGmsaDomainJoinPassword",
+					gmsa.DomainJoinPasswordKeyvaultSecretRef.VaultID,
+					gmsa.DomainJoinPasswordKeyvaultSecretRef.SecretName,
+					gmsa.DomainJoinPasswordKeyvaultSecretRef.SecretVersion)
+			} else {
+				addSecret(parametersMap, "windowsGmsaDomainJoinPassword", gmsa.DomainJoinPassword, false)
+			}
+		}
+
 		if properties.WindowsProfile.HasCustomImage() {
 			addValue(parametersMap, "agentWindowsSourceUrl", properties.WindowsProfile.WindowsImageSourceURL)
 		} else if properties.WindowsProfile.HasImageRef() {
@@ -55,12 +55,12 @@ func assignKubernetesParameters(properties *api.Properties, parametersMap params
 		addValue(parametersMap, "networkMode", kubernetesConfig.NetworkMode)
 		addValue(parametersMap, "containerRuntime", kubernetesConfig.ContainerRuntime)
 		addValue(parametersMap, "containerdDownloadURLBase", cloudSpecConfig.KubernetesSpecConfig.ContainerdDownloadURLBase)
-		addValue(parametersMap, "cniPluginsURL", cloudSpecConfig.KubernetesSpecConfig.CNIPluginsDownloadURL)
+		addValue(parametersMap, "cniPluginsURL", kubernetesConfig.GetCNIPluginsURL(cloudSpecConfig))
 		addValue(parametersMap, "vnetCniLinuxPluginsURL", kubernetesConfig.GetAzureCNIURLLinux(cloudSpecConfig))
 		addValue(parametersMap, "vnetCniWindowsPluginsURL", kubernetesConfig.GetAzureCNIURLWindows(cloudSpecConfig))
 		addValue(parametersMap, "gchighthreshold", kubernetesConfig.GCHighThreshold)
 		addValue(parametersMap, "gclowthreshold", kubernetesConfig.GCLowThreshold)
-		addValue(parametersMap, "etcdDownloadURLBase", cloudSpecConfig.KubernetesSpecConfig.EtcdDownloadURLBase)
+		addValue(parametersMap, "etcdDownloadURLBase", kubernetesConfig.GetEtcdDownloadURLBase(cloudSpecConfig))
 		addValue(parametersMap, "etcdVersion", kubernetesConfig.EtcdVersion)
 		addValue(parametersMap, "etcdDiskSizeGB", kubernetesConfig.EtcdDiskSizeGB)
 		addValue(parametersMap, "etcdEncryptionKey", kubernetesConfig.EtcdEncryptionKey)
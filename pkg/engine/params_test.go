@@ -56,3 +56,72 @@ func TestAssignParameters(t *testing.T) {
 		}
 	}
 }
+
+func TestGetParametersWindowsAgentPoolImage(t *testing.T) {
+	cs := api.CreateMockContainerService("testcluster", "1.18.0", 1, 2, false)
+	cs.Properties.WindowsProfile = &api.WindowsProfile{
+		AdminUsername:    "azureuser",
+		AdminPassword:    "password",
+		WindowsPublisher: api.AKSWindowsServer2019OSImageConfig.ImagePublisher,
+		WindowsOffer:     api.AKSWindowsServer2019OSImageConfig.ImageOffer,
+		WindowsSku:       api.AKSWindowsServer2019OSImageConfig.ImageSku,
+		ImageVersion:     api.AKSWindowsServer2019OSImageConfig.ImageVersion,
+	}
+	cs.Properties.AgentPoolProfiles = []*api.AgentPoolProfile{
+		{Name: "win2019", OSType: api.Windows, Count: 1},
+		{Name: "win2022", OSType: api.Windows, Count: 1, WindowsSku: "2022-datacenter-core-smalldisk", WindowsImageVersion: "latest"},
+	}
+
+	parametersMap := getParameters(cs, DefaultGeneratorCode, "testversion")
+
+	if _, ok := parametersMap["win2019osImageSKU"]; ok {
+		t.Errorf("expected no OS image parameters for pool win2019, which uses the image of the windowsProfile")
+	}
+	expected := map[string]string{
+		"win2022osImagePublisher": api.AKSWindowsServer2019OSImageConfig.ImagePublisher,
+		"win2022osImageOffer":     api.AKSWindowsServer2019OSImageConfig.ImageOffer,
+		"win2022osImageSKU":       "2022-datacenter-core-smalldisk",
+		"win2022osImageVersion":   "latest",
+		"agentWindowsSku":         api.AKSWindowsServer2019OSImageConfig.ImageSku,
+	}
+	for k, v := range expected {
+		p, ok := parametersMap[k]
+		if !ok {
+			t.Errorf("expected parameter %s", k)
+			continue
+		}
+		if actual := p.(paramsMap)["value"]; actual != v {
+			t.Errorf("expected parameter %s to be %s, got %v", k, v, actual)
+		}
+	}
+}
+
+func TestGetParametersLinuxAgentPoolImageFlavor(t *testing.T) {
+	cs := api.CreateMockContainerService("testcluster", "1.24.0", 1, 1, false)
+	cs.Properties.AgentPoolProfiles = []*api.AgentPoolProfile{
+		{Name: "gen1", Distro: api.Ubuntu2204, Count: 1},
+		{Name: "gen2", Distro: api.Ubuntu2004, HyperVGeneration: api.HyperVGenerationV2, Count: 1},
+		{Name: "arm", Distro: api.Ubuntu2204, Architecture: api.ArchitectureArm64, Count: 1},
+	}
+
+	parametersMap := getParameters(cs, DefaultGeneratorCode, "testversion")
+
+	expected := map[string]string{
+		"gen1osImageOffer": api.Ubuntu2204OSImageConfig.ImageOffer,
+		"gen1osImageSKU":   api.Ubuntu2204OSImageConfig.ImageSku,
+		"gen2osImageOffer": api.Ubuntu2004Gen2OSImageConfig.ImageOffer,
+		"gen2osImageSKU":   api.Ubuntu2004Gen2OSImageConfig.ImageSku,
+		"armosImageOffer":  api.Ubuntu2204Arm64OSImageConfig.ImageOffer,
+		"armosImageSKU":    api.Ubuntu2204Arm64OSImageConfig.ImageSku,
+	}
+	for k, v := range expected {
+		p, ok := parametersMap[k]
+		if !ok {
+			t.Errorf("expected parameter %s", k)
+			continue
+		}
+		if actual := p.(paramsMap)["value"]; actual != v {
+			t.Errorf("expected parameter %s to be %s, got %v", k, v, actual)
+		}
+	}
+}
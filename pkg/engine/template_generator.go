@@ -167,7 +167,7 @@ func (t *TemplateGenerator) GetMasterCustomDataJSONObject(cs *api.ContainerServi
 		customFilesReader,
 		"MASTER_CUSTOM_FILES_PLACEHOLDER")
 
-	addonStr := getAddonsString(cs, "k8s/addons")
+	addonStr := getAddonsString(cs, addonsSourcePath)
 
 	str = strings.Replace(str, "MASTER_CONTAINER_ADDONS_PLACEHOLDER", addonStr, -1)
 
@@ -177,7 +177,12 @@ func (t *TemplateGenerator) GetMasterCustomDataJSONObject(cs *api.ContainerServi
 
 // GetKubernetesLinuxNodeCustomDataJSONObject returns Linux customData JSON object in the form
 // { "customData": "[base64(concat(<customData string>))]" }
+// Flatcar nodes are provisioned with an Ignition config converted from the same cloud-init document.
 func (t *TemplateGenerator) GetKubernetesLinuxNodeCustomDataJSONObject(cs *api.ContainerService, profile *api.AgentPoolProfile) string {
+	if profile.IsFlatcar() {
+		return t.getKubernetesFlatcarNodeCustomDataJSONObject(cs, profile)
+	}
+
 	str, e := t.getSingleLineForTemplate(kubernetesNodeCustomDataYaml, cs, profile)
 
 	if e != nil {
@@ -187,6 +192,23 @@ func (t *TemplateGenerator) GetKubernetesLinuxNodeCustomDataJSONObject(cs *api.C
 	return fmt.Sprintf("{\"customData\": \"[base64(concat('%s'))]\"}", str)
 }
 
+// getKubernetesFlatcarNodeCustomDataJSONObject returns the Flatcar node Ignition config as a customData JSON object
+func (t *TemplateGenerator) getKubernetesFlatcarNodeCustomDataJSONObject(cs *api.ContainerService, profile *api.AgentPoolProfile) string {
+	str, e := t.getSingleLine(kubernetesNodeCustomDataYaml, cs, profile)
+
+	if e != nil {
+		panic(e)
+	}
+
+	str, e = cloudInitToIgnition(str)
+
+	if e != nil {
+		panic(e)
+	}
+
+	return fmt.Sprintf("{\"customData\": \"[base64(concat('%s'))]\"}", str)
+}
+
 // GetKubernetesWindowsNodeCustomDataJSONObject returns Windows customData JSON object in the form
 // { "customData": "[base64(concat(<customData string>))]" }
 func (t *TemplateGenerator) GetKubernetesWindowsNodeCustomDataJSONObject(cs *api.ContainerService, profile *api.AgentPoolProfile) string {
@@ -251,6 +273,9 @@ func getContainerServiceFuncMap(cs *api.ContainerService) template.FuncMap {
 			return common.GetMasterKubernetesLabels(rg, false)
 		},
 		"GetAgentKubernetesLabels": func(profile *api.AgentPoolProfile, rg string) string {
+			if profile.IsWindows() {
+				return profile.GetKubernetesLabels(rg, false) + cs.Properties.GetWindowsRuntimeHandlerLabels()
+			}
 			return profile.GetKubernetesLabels(rg, false)
 		},
 		"GetKubeletConfigKeyVals": func(kc *api.KubernetesConfig) string {
@@ -405,6 +430,7 @@ func getContainerServiceFuncMap(cs *api.ContainerService) template.FuncMap {
 				kubernetesWindowsAzureCniFunctionsPS1,
 				kubernetesWindowsHostsConfigAgentFunctionsPS1,
 				kubernetesWindowsOpenSSHFunctionPS1,
+				kubernetesWindowsGMSAFunctionsPS1,
 				kubernetesWindowsHypervtemplatetoml,
 			}
 
@@ -504,6 +530,9 @@ func getContainerServiceFuncMap(cs *api.ContainerService) template.FuncMap {
 		"WindowsSSHEnabled": func() bool {
 			return cs.Properties.WindowsProfile.GetSSHEnabled()
 		},
+		"HasWindowsGMSADomainJoin": func() bool {
+			return cs.Properties.WindowsProfile.IsGMSADomainJoinEnabled()
+		},
 		"GetMasterOSImageOffer": func() string {
 			cloudSpecConfig := cs.GetCloudSpecConfig()
 			return fmt.Sprintf("\"%s\"", cloudSpecConfig.OSImageConfig[cs.Properties.MasterProfile.Distro].ImageOffer)
@@ -521,20 +550,16 @@ func getContainerServiceFuncMap(cs *api.ContainerService) template.FuncMap {
 			return fmt.Sprintf("\"%s\"", cloudSpecConfig.OSImageConfig[cs.Properties.MasterProfile.Distro].ImageVersion)
 		},
 		"GetAgentOSImageOffer": func(profile *api.AgentPoolProfile) string {
-			cloudSpecConfig := cs.GetCloudSpecConfig()
-			return fmt.Sprintf("\"%s\"", cloudSpecConfig.OSImageConfig[profile.Distro].ImageOffer)
+			return fmt.Sprintf("\"%s\"", profile.GetOSImageConfig(cs.GetCloudSpecConfig()).ImageOffer)
 		},
 		"GetAgentOSImagePublisher": func(profile *api.AgentPoolProfile) string {
-			cloudSpecConfig := cs.GetCloudSpecConfig()
-			return fmt.Sprintf("\"%s\"", cloudSpecConfig.OSImageConfig[profile.Distro].ImagePublisher)
+			return fmt.Sprintf("\"%s\"", profile.GetOSImageConfig(cs.GetCloudSpecConfig()).ImagePublisher)
 		},
 		"GetAgentOSImageSKU": func(profile *api.AgentPoolProfile) string {
-			cloudSpecConfig := cs.GetCloudSpecConfig()
-			return fmt.Sprintf("\"%s\"", cloudSpecConfig.OSImageConfig[profile.Distro].ImageSku)
+			return fmt.Sprintf("\"%s\"", profile.GetOSImageConfig(cs.GetCloudSpecConfig()).ImageSku)
 		},
 		"GetAgentOSImageVersion": func(profile *api.AgentPoolProfile) string {
-			cloudSpecConfig := cs.GetCloudSpecConfig()
-			return fmt.Sprintf("\"%s\"", cloudSpecConfig.OSImageConfig[profile.Distro].ImageVersion)
+			return fmt.Sprintf("\"%s\"", profile.GetOSImageConfig(cs.GetCloudSpecConfig()).ImageVersion)
 		},
 		"HasVHDDistroNodes": func() bool {
 			return cs.Properties.HasVHDDistroNodes()
@@ -629,6 +654,13 @@ func getContainerServiceFuncMap(cs *api.ContainerService) template.FuncMap {
 			}
 			return val
 		},
+		"GetAgentContainerdConfig": func(profile *api.AgentPoolProfile) string {
+			val, err := getAgentContainerdConfig(cs, profile)
+			if err != nil {
+				return ""
+			}
+			return val
+		},
 		"GetNvidiaContainerdConfig": func() string {
 			return `oom_score = 0
 version = 2
@@ -922,6 +954,13 @@ func generateUserAssignedIdentityClientIDParameterForWindows(isUserAssignedIdent
 	return ""
 }
 
+func generateGMSADomainJoinPasswordParameterForWindows(isGMSADomainJoin bool) string {
+	if isGMSADomainJoin {
+		return "' -GmsaDomainJoinPassword ',variables('singleQuote'),variables('singleQuote'),base64(parameters('windowsGmsaDomainJoinPassword')),variables('singleQuote'),variables('singleQuote'),"
+	}
+	return ""
+}
+
 func getDockerConfig(cs *api.ContainerService, hasGPU bool) (string, error) {
 	var overrides []func(*common.DockerConfig) error
 
@@ -938,6 +977,11 @@ func getDockerConfig(cs *api.ContainerService, hasGPU bool) (string, error) {
 }
 
 func getContainerdConfig(cs *api.ContainerService) (string, error) {
+	return getAgentContainerdConfig(cs, nil)
+}
+
+// getAgentContainerdConfig returns the containerd config of the nodes of an agent pool, profile is nil for the masters
+func getAgentContainerdConfig(cs *api.ContainerService, profile *api.AgentPoolProfile) (string, error) {
 	var overrides = []func(*common.ContainerdConfig) error{
 		common.ContainerdSandboxImageOverrider(cs.Properties.OrchestratorProfile.GetPodInfraContainerSpec()),
 	}
@@ -946,6 +990,10 @@ func getContainerdConfig(cs *api.ContainerService) (string, error) {
 		overrides = append(overrides, common.ContainerdKubenetOverride)
 	}
 
+	if profile != nil && profile.IsUbuntu2204() {
+		overrides = append(overrides, common.ContainerdSystemdCgroupOverride)
+	}
+
 	val, err := common.GetContainerdConfig(cs.Properties.OrchestratorProfile.KubernetesConfig.ContainerRuntimeConfig, overrides)
 	if err != nil {
 		return "", err
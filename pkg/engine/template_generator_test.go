@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"text/template"
 
@@ -1825,6 +1826,46 @@ func TestGetContainerdConfig(t *testing.T) {
 	}
 }
 
+func TestGetAgentContainerdConfig(t *testing.T) {
+	cs := api.CreateMockContainerService("testcluster", "1.24.0", 1, 1, false)
+	cs.Properties.OrchestratorProfile.KubernetesConfig.ContainerRuntime = api.Containerd
+	cs.Properties.OrchestratorProfile.KubernetesConfig.NetworkPlugin = NetworkPluginAzure
+
+	for _, c := range []struct {
+		distro        api.Distro
+		systemdCgroup bool
+	}{
+		{distro: api.AKSUbuntu1804},
+		{distro: api.Ubuntu2004},
+		{distro: api.Ubuntu2204, systemdCgroup: true},
+		{distro: api.Ubuntu2204Gen2, systemdCgroup: true},
+	} {
+		profile := &api.AgentPoolProfile{Name: "agentpool", Distro: c.distro}
+		got, err := getAgentContainerdConfig(cs, profile)
+		if err != nil {
+			t.Fatalf("unexpected error getting the containerd config of a %s pool: %s", c.distro, err)
+		}
+		if strings.Contains(got, "SystemdCgroup = true") != c.systemdCgroup {
+			t.Errorf("expected the containerd config of a %s pool to set SystemdCgroup to %t, got:\n%s", c.distro, c.systemdCgroup, got)
+		}
+	}
+
+	got, err := getContainerdConfig(cs)
+	if err != nil {
+		t.Fatalf("unexpected error getting the containerd config of the masters: %s", err)
+	}
+	if strings.Contains(got, "SystemdCgroup") {
+		t.Errorf("expected the containerd config of the masters not to set SystemdCgroup, got:\n%s", got)
+	}
+
+	cs.Properties.AgentPoolProfiles[0].Distro = api.Ubuntu2204
+	tg, _ := InitializeTemplateGenerator(Context{})
+	customData := tg.GetKubernetesLinuxNodeCustomDataJSONObject(cs, cs.Properties.AgentPoolProfiles[0])
+	if !strings.Contains(customData, "SystemdCgroup = true") {
+		t.Errorf("expected the custom data of an Ubuntu 22.04 pool to configure containerd with SystemdCgroup")
+	}
+}
+
 func TestGetBase64EncodedEnvironmentJSON(t *testing.T) {
 	apiModelString := `{"properties":{"customCloudProfile":{"environment":{"name":"AzureStackCloud","managementPortalURL":"https://portal.local.azurestack.external/","publishSettingsURL":"'single quotes'","serviceManagementEndpoint":"https://management.azurestack.onmicrosoft.com/00000000-0000-0000-0000-0000000000","resourceManagerEndpoint":"https://management.local.azurestack.external/","activeDirectoryEndpoint":"https://login.microsoftonline.com/","galleryEndpoint":"https://galleryartifacts.hosting.local.azurestack.external/galleryartifacts/","keyVaultEndpoint":"https://vault.azurestack.onmicrosoft.com/00000000-0000-0000-0000-0000000000","graphEndpoint":"https://graph.windows.net/","serviceBusEndpoint":"","batchManagementEndpoint":"","storageEndpointSuffix":"local.azurestack.external","sqlDatabaseDNSSuffix":"","trafficManagerDNSSuffix":"","keyVaultDNSSuffix":"vault.local.azurestack.external","serviceBusEndpointSuffix":"","serviceManagementVMDNSSuffix":"","resourceManagerVMDNSSuffix":"cloudapp.azurestack.external","containerRegistryDNSSuffix":"","cosmosDBDNSSuffix":"","tokenAudience":"","resourceIdentifiers":{"graph":"","keyVault":"","datalake":"","batch":"","operationalInsights":"","storage":""}}}}}`
 	funcmap, err := getFuncMap(apiModelString)
@@ -1915,3 +1956,55 @@ func TestGenerateUserAssignedIdentityClientIDParameterForWindows(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateGMSADomainJoinPasswordParameterForWindows(t *testing.T) {
+	testCases := []struct {
+		name             string
+		isGMSADomainJoin bool
+		expected         string
+	}{
+		{
+			name:             "enabled",
+			isGMSADomainJoin: true,
+			expected:         "' -GmsaDomainJoinPassword ',variables('singleQuote'),variables('singleQuote'),base64(parameters('windowsGmsaDomainJoinPassword')),variables('singleQuote'),variables('singleQuote'),",
+		},
+		{
+			name:             "disabled",
+			isGMSADomainJoin: false,
+			expected:         "",
+		},
+	}
+
+	for _, c := range testCases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			if ret := generateGMSADomainJoinPasswordParameterForWindows(c.isGMSADomainJoin); ret != c.expected {
+				t.Fatalf("generateGMSADomainJoinPasswordParameterForWindows(%t) returned %s, expected %s", c.isGMSADomainJoin, ret, c.expected)
+			}
+		})
+	}
+}
+
+func TestGetKubernetesWindowsNodeCustomDataDockerVersion(t *testing.T) {
+	cs := api.CreateMockContainerService("testcluster", "1.18.0", 1, 2, false)
+	cs.Properties.WindowsProfile = &api.WindowsProfile{
+		AdminUsername: "azureuser",
+		AdminPassword: "password",
+	}
+	cs.Properties.AgentPoolProfiles = []*api.AgentPoolProfile{
+		{Name: "win2019", OSType: api.Windows, Count: 1},
+		{Name: "win2022", OSType: api.Windows, Count: 1, WindowsDockerVersion: "20.10.9"},
+	}
+	tg, _ := InitializeTemplateGenerator(Context{})
+
+	customData := tg.GetKubernetesWindowsNodeCustomDataJSONObject(cs, cs.Properties.AgentPoolProfiles[0])
+	if !strings.Contains(customData, "parameters('windowsDockerVersion')") {
+		t.Errorf("expected the custom data of pool win2019 to use the docker version of the windowsProfile")
+	}
+
+	customData = tg.GetKubernetesWindowsNodeCustomDataJSONObject(cs, cs.Properties.AgentPoolProfiles[1])
+	if strings.Contains(customData, "parameters('windowsDockerVersion')") || !strings.Contains(customData, `DockerVersion = \"20.10.9\"`) {
+		t.Errorf("expected the custom data of pool win2022 to use the docker version of the pool")
+	}
+}
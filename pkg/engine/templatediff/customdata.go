@@ -0,0 +1,164 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package templatediff
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// CloudConfigFile is the name of the embedded file holding the cloud-init document of custom data,
+// without the content of its write_files entries
+const CloudConfigFile = "(cloud-config)"
+
+// CustomDataFile is the name of the embedded file holding custom data that is not a cloud-init document
+const CustomDataFile = "(custom data)"
+
+// EmbeddedFile is a file embedded in custom data
+type EmbeddedFile struct {
+	Path    string
+	Content string
+}
+
+var writeFilesPathRegexp = regexp.MustCompile(`^- path: "?([^"]+)"?\s*$`)
+
+// DecodeCustomData decodes the customData expression generated for the VMs of a cluster,
+// "[base64(concat('<literal>', <expression>, ...))]", into the files written by cloud-init.
+// The expressions the document concatenates, e.g. "variables('cloudInitFiles').provisionScript", are kept as is,
+// and the gzip and base64 contents of the files are decoded. It returns false if s is not a customData expression.
+func DecodeCustomData(s string) ([]EmbeddedFile, bool) {
+	const prefix, suffix = "[base64(concat(", "))]"
+	if !strings.HasPrefix(s, prefix) || !strings.HasSuffix(s, suffix) {
+		return nil, false
+	}
+	args, ok := splitConcatArguments(strings.TrimSuffix(strings.TrimPrefix(s, prefix), suffix))
+	if !ok {
+		return nil, false
+	}
+	return splitCloudConfig(strings.Join(args, "")), true
+}
+
+// splitConcatArguments returns the string literals of the arguments of an ARM concat() call unquoted,
+// and its other arguments as "[<expression>]"
+func splitConcatArguments(s string) ([]string, bool) {
+	var raw []string
+	inLiteral, depth, start := false, 0, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\'':
+			// '' escapes a quote in a literal, and is read as the end and the start of a literal
+			inLiteral = !inLiteral
+		case inLiteral:
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			raw = append(raw, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if inLiteral || depth != 0 {
+		return nil, false
+	}
+	raw = append(raw, strings.TrimSpace(s[start:]))
+
+	args := make([]string, 0, len(raw))
+	for _, arg := range raw {
+		if len(arg) >= 2 && arg[0] == '\'' && arg[len(arg)-1] == '\'' && !strings.Contains(strings.ReplaceAll(arg[1:len(arg)-1], "''", ""), "'") {
+			args = append(args, strings.ReplaceAll(arg[1:len(arg)-1], "''", "'"))
+		} else {
+			args = append(args, "["+arg+"]")
+		}
+	}
+	return args, true
+}
+
+// splitCloudConfig splits a cloud-init document into the files of its write_files entries,
+// and the rest of the document
+func splitCloudConfig(doc string) []EmbeddedFile {
+	if !strings.HasPrefix(doc, "#cloud-config") {
+		return []EmbeddedFile{{Path: CustomDataFile, Content: doc}}
+	}
+	var files []EmbeddedFile
+	var rest []string
+	lines := strings.Split(doc, "\n")
+	for i := 0; i < len(lines); i++ {
+		m := writeFilesPathRegexp.FindStringSubmatch(lines[i])
+		if m == nil {
+			rest = append(rest, lines[i])
+			continue
+		}
+		file := EmbeddedFile{Path: m[1]}
+		var encoding string
+		var content []string
+		rest = append(rest, lines[i])
+		// The attributes of the entry are indented by 2 spaces, its content by 4
+		for i+1 < len(lines) && (strings.HasPrefix(lines[i+1], "  ") || continuesContent(lines[i+1:])) {
+			i++
+			line := lines[i]
+			if strings.HasPrefix(line, "    ") || line == "" {
+				content = append(content, strings.TrimPrefix(line, "    "))
+				continue
+			}
+			if strings.HasPrefix(line, "  encoding: ") {
+				encoding = strings.TrimSpace(strings.TrimPrefix(line, "  encoding: "))
+			}
+			rest = append(rest, line)
+		}
+		file.Content = decodeFileContent(strings.Join(content, "\n"), encoding)
+		files = append(files, file)
+	}
+	return append(files, EmbeddedFile{Path: CloudConfigFile, Content: strings.Join(rest, "\n")})
+}
+
+// continuesContent returns true if lines start with empty lines followed by a line of file content
+func continuesContent(lines []string) bool {
+	for _, line := range lines {
+		if line != "" {
+			return strings.HasPrefix(line, "    ")
+		}
+	}
+	return false
+}
+
+func decodeFileContent(content, encoding string) string {
+	switch encoding {
+	case "gzip", "gz", "gz+b64", "gzip+base64":
+		if text, ok := decodeText(strings.Join(strings.Fields(content), "")); ok {
+			return text
+		}
+	case "b64", "base64":
+		if b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(content), "")); err == nil {
+			return string(b)
+		}
+	}
+	return content
+}
+
+// decodeText decodes s if it is a base64 encoded gzip payload, or returns s as is
+func decodeText(s string) (string, bool) {
+	// "H4sI" is the base64 encoding of the gzip magic number and deflate method
+	if !strings.HasPrefix(s, "H4sI") {
+		return s, false
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return s, false
+	}
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return s, false
+	}
+	defer r.Close()
+	var text bytes.Buffer
+	if _, err := io.Copy(&text, r); err != nil {
+		return s, false
+	}
+	return text.String(), true
+}
@@ -0,0 +1,49 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package templatediff
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDecodeCustomData(t *testing.T) {
+	RegisterTestingT(t)
+	s := "[base64(concat('#cloud-config\n\nwrite_files:\n- path: /opt/azure/containers/provision.sh\n  permissions: \"0744\"\n  content: |\n    #!/bin/bash\n\n    echo ''provisioning''\n    ',variables('provisionScript'),'\n\n- path: /etc/kubernetes/azure.json\n  encoding: b64\n  content: |\n    e30=\n\nruncmd:\n- set -x\n'))]"
+
+	files, ok := DecodeCustomData(s)
+	Expect(ok).To(BeTrue())
+	Expect(files).To(Equal([]EmbeddedFile{
+		{Path: "/opt/azure/containers/provision.sh", Content: "#!/bin/bash\n\necho 'provisioning'\n[variables('provisionScript')]"},
+		{Path: "/etc/kubernetes/azure.json", Content: "{}"},
+		{Path: CloudConfigFile, Content: "#cloud-config\n\nwrite_files:\n- path: /opt/azure/containers/provision.sh\n  permissions: \"0744\"\n  content: |\n\n- path: /etc/kubernetes/azure.json\n  encoding: b64\n  content: |\n\nruncmd:\n- set -x\n"},
+	}))
+}
+
+func TestDecodeCustomDataNotCloudConfig(t *testing.T) {
+	RegisterTestingT(t)
+	files, ok := DecodeCustomData("[base64(concat('#!/bin/bash\necho ', parameters('name')))]")
+	Expect(ok).To(BeTrue())
+	Expect(files).To(Equal([]EmbeddedFile{{Path: CustomDataFile, Content: "#!/bin/bash\necho [parameters('name')]"}}))
+
+	for _, s := range []string{
+		"[variables('customData')]",
+		"[base64(concat('unterminated))]",
+		"[base64(concat(variables('a'), ')'))]x",
+	} {
+		_, ok := DecodeCustomData(s)
+		Expect(ok).To(BeFalse(), s)
+	}
+}
+
+func TestSplitConcatArguments(t *testing.T) {
+	RegisterTestingT(t)
+	args, ok := splitConcatArguments("'a, b', variables('c'), 'it''s', concat('d', 'e'), '[f]'")
+	Expect(ok).To(BeTrue())
+	Expect(args).To(Equal([]string{"a, b", "[variables('c')]", "it's", "[concat('d', 'e')]", "[f]"}))
+
+	_, ok = splitConcatArguments("variables('c'")
+	Expect(ok).To(BeFalse())
+}
@@ -0,0 +1,6 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+// Package templatediff compares two generated ARM templates, decoding the gzip and base64 payloads and the
+// cloud-init custom data they embed so that the changes are reported per resource and per embedded file.
+package templatediff
@@ -0,0 +1,176 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package templatediff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines around the changes of a hunk
+const diffContextLines = 3
+
+// maxDiffCells bounds the size of the table used to find the longest common subsequence of two texts
+const maxDiffCells = 16 * 1024 * 1024
+
+// DiffLines returns the unified diff of two texts, without file headers
+func DiffLines(before, after string) []string {
+	if before == after {
+		return nil
+	}
+	a := splitLines(before)
+	b := splitLines(after)
+
+	// Only the lines between the common prefix and suffix are compared
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, edit{' ', a[i]})
+	}
+	edits = append(edits, diffEdits(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for i := len(a) - suffix; i < len(a); i++ {
+		edits = append(edits, edit{' ', a[i]})
+	}
+	return hunks(edits)
+}
+
+type edit struct {
+	op   byte
+	line string
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffEdits returns the edits from a to b along their longest common subsequence,
+// or removes all lines of a and adds all lines of b if they are too large to compare
+func diffEdits(a, b []string) []edit {
+	var edits []edit
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			edits = append(edits, edit{'-', line})
+		}
+		for _, line := range b {
+			edits = append(edits, edit{'+', line})
+		}
+		return edits
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, edit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		edits = append(edits, edit{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		edits = append(edits, edit{'+', b[j]})
+	}
+	return edits
+}
+
+// hunks groups the changed edits with their context lines into unified diff hunks
+func hunks(edits []edit) []string {
+	var lines []string
+	for start := 0; start < len(edits); {
+		// Find the next change, and the end of the hunk it starts
+		first := start
+		for first < len(edits) && edits[first].op == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		last := first
+		for next := first; next < len(edits); next++ {
+			if edits[next].op != ' ' {
+				if next-last > 2*diffContextLines {
+					break
+				}
+				last = next
+			}
+		}
+		from := first - diffContextLines
+		if from < start {
+			from = start
+		}
+		to := last + diffContextLines + 1
+		if to > len(edits) {
+			to = len(edits)
+		}
+
+		beforeLine, afterLine := 1, 1
+		for _, e := range edits[:from] {
+			if e.op != '+' {
+				beforeLine++
+			}
+			if e.op != '-' {
+				afterLine++
+			}
+		}
+		var beforeCount, afterCount int
+		var hunk []string
+		for _, e := range edits[from:to] {
+			if e.op != '+' {
+				beforeCount++
+			}
+			if e.op != '-' {
+				afterCount++
+			}
+			hunk = append(hunk, string(e.op)+e.line)
+		}
+		// As in unified diffs, an empty range starts at the line before it
+		if beforeCount == 0 {
+			beforeLine--
+		}
+		if afterCount == 0 {
+			afterLine--
+		}
+		lines = append(lines, fmt.Sprintf("@@ -%d,%d +%d,%d @@", beforeLine, beforeCount, afterLine, afterCount))
+		lines = append(lines, hunk...)
+		start = to
+	}
+	return lines
+}
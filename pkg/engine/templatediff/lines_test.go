@@ -0,0 +1,35 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package templatediff
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDiffLines(t *testing.T) {
+	RegisterTestingT(t)
+	Expect(DiffLines("a\nb\n", "a\nb\n")).To(BeNil())
+
+	Expect(DiffLines("", "a\nb\n")).To(Equal([]string{"@@ -0,0 +1,2 @@", "+a", "+b"}))
+
+	before := strings.Join([]string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12"}, "\n")
+	after := strings.Join([]string{"1", "two", "3", "4", "5", "6", "7", "8", "9", "10", "11"}, "\n")
+	Expect(DiffLines(before, after)).To(Equal([]string{
+		"@@ -1,5 +1,5 @@",
+		" 1",
+		"-2",
+		"+two",
+		" 3",
+		" 4",
+		" 5",
+		"@@ -9,4 +9,3 @@",
+		" 9",
+		" 10",
+		" 11",
+		"-12",
+	}))
+}
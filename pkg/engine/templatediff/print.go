@@ -0,0 +1,81 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package templatediff
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/Azure/aks-engine/pkg/helpers"
+)
+
+var changeSymbols = map[string]string{
+	Added:    "+",
+	Removed:  "-",
+	Modified: "~",
+}
+
+// Print writes the changes of d per section, resource and embedded file, then a summary
+func (d *Diff) Print(w io.Writer) {
+	if d.Empty() {
+		fmt.Fprintln(w, "No changes.")
+		return
+	}
+	if len(d.Parameters) > 0 {
+		fmt.Fprintln(w, "Parameters:")
+		printPropertyChanges(w, "  ", d.Parameters)
+	}
+	if len(d.Variables) > 0 {
+		fmt.Fprintln(w, "Variables:")
+		printPropertyChanges(w, "  ", d.Variables)
+	}
+	if len(d.Resources) > 0 {
+		fmt.Fprintln(w, "Resources:")
+		for _, r := range d.Resources {
+			fmt.Fprintf(w, "  %s %s %s\n", changeSymbols[r.ChangeType], r.Type, r.Name)
+			printPropertyChanges(w, "      ", r.Changes)
+		}
+	}
+	fmt.Fprintf(w, "%d parameter(s), %d variable(s) and %d resource(s) changed.\n", len(d.Parameters), len(d.Variables), len(d.Resources))
+}
+
+func printPropertyChanges(w io.Writer, indent string, changes []PropertyChange) {
+	for _, c := range changes {
+		symbol := changeSymbols[c.ChangeType]
+		switch {
+		case c.Secure:
+			fmt.Fprintf(w, "%s%s %s: (secure value)\n", indent, symbol, c.Path)
+		case c.Files != nil:
+			fmt.Fprintf(w, "%s%s %s:\n", indent, symbol, c.Path)
+			for _, f := range c.Files {
+				fmt.Fprintf(w, "%s    %s %s\n", indent, changeSymbols[f.ChangeType], f.Path)
+				printLines(w, indent+"        ", f.Lines)
+			}
+		case c.Lines != nil:
+			fmt.Fprintf(w, "%s%s %s:\n", indent, symbol, c.Path)
+			printLines(w, indent+"    ", c.Lines)
+		case c.ChangeType == Added:
+			fmt.Fprintf(w, "%s%s %s: %s\n", indent, symbol, c.Path, jsonValue(c.After))
+		case c.ChangeType == Removed:
+			fmt.Fprintf(w, "%s%s %s: %s\n", indent, symbol, c.Path, jsonValue(c.Before))
+		default:
+			fmt.Fprintf(w, "%s%s %s: %s => %s\n", indent, symbol, c.Path, jsonValue(c.Before), jsonValue(c.After))
+		}
+	}
+}
+
+func printLines(w io.Writer, indent string, lines []string) {
+	for _, line := range lines {
+		fmt.Fprintf(w, "%s%s\n", indent, line)
+	}
+}
+
+func jsonValue(v interface{}) string {
+	b, err := helpers.JSONMarshal(v, false)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(bytes.TrimSpace(b))
+}
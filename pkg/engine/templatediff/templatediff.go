@@ -0,0 +1,332 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package templatediff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Types of changes
+const (
+	Added    = "Added"
+	Removed  = "Removed"
+	Modified = "Modified"
+)
+
+// maxInlineValueLength is the length above which the changes of a single line value are reported word by word
+const maxInlineValueLength = 200
+
+// Template is a generated ARM template and its parameters
+type Template struct {
+	Template   map[string]interface{}
+	Parameters map[string]interface{}
+}
+
+// Diff are the changes between two templates
+type Diff struct {
+	Parameters []PropertyChange
+	Variables  []PropertyChange
+	Resources  []ResourceChange
+}
+
+// ResourceChange is an added, removed or modified resource of a template
+type ResourceChange struct {
+	Type       string
+	Name       string
+	ChangeType string
+	// Changes are the changed properties of a modified resource
+	Changes []PropertyChange
+}
+
+// PropertyChange is an added, removed or modified property of a parameter, variable or resource
+type PropertyChange struct {
+	Path       string
+	ChangeType string
+	Before     interface{}
+	After      interface{}
+	// Secure is set for the values of secure parameters, which are not reported
+	Secure bool
+	// Lines is the line diff of a modified text or gzip payload
+	Lines []string
+	// Files are the changes of the files embedded in modified custom data
+	Files []FileChange
+}
+
+// FileChange is an added, removed or modified file embedded in custom data
+type FileChange struct {
+	Path       string
+	ChangeType string
+	// Lines is the line diff of the content of the file
+	Lines []string
+}
+
+// LoadTemplate parses a template and its parameters, which may be wrapped in a deployment parameters file
+func LoadTemplate(templateJSON, parametersJSON []byte) (Template, error) {
+	var t Template
+	if err := json.Unmarshal(templateJSON, &t.Template); err != nil {
+		return t, errors.Wrap(err, "parsing template")
+	}
+	if len(parametersJSON) > 0 {
+		if err := json.Unmarshal(parametersJSON, &t.Parameters); err != nil {
+			return t, errors.Wrap(err, "parsing template parameters")
+		}
+		if parameters, ok := t.Parameters["parameters"].(map[string]interface{}); ok {
+			t.Parameters = parameters
+		}
+	}
+	return t, nil
+}
+
+// Empty returns true if the templates are the same
+func (d *Diff) Empty() bool {
+	return len(d.Parameters) == 0 && len(d.Variables) == 0 && len(d.Resources) == 0
+}
+
+// Compare returns the changes from the before template to the after template
+func Compare(before, after Template) *Diff {
+	return &Diff{
+		Parameters: compareParameters(before, after),
+		Variables:  compareProperties("", mapValue(before.Template, "variables"), mapValue(after.Template, "variables")),
+		Resources:  compareResources(before.Template["resources"], after.Template["resources"]),
+	}
+}
+
+// compareParameters compares the values of the parameters, keeping the values of secure parameters out of the diff
+func compareParameters(before, after Template) []PropertyChange {
+	beforeValues := parameterValues(before)
+	afterValues := parameterValues(after)
+	changes := compareProperties("", beforeValues, afterValues)
+	for i := range changes {
+		name := strings.SplitN(strings.SplitN(changes[i].Path, ".", 2)[0], "[", 2)[0]
+		if isSecureParameter(before, name) || isSecureParameter(after, name) {
+			changes[i] = PropertyChange{Path: name, ChangeType: changes[i].ChangeType, Secure: true}
+		}
+	}
+	return dedupeSecureChanges(changes)
+}
+
+// parameterValues returns the value of each parameter, or its default value when the parameters file does not set it
+func parameterValues(t Template) map[string]interface{} {
+	values := make(map[string]interface{})
+	for name, definition := range mapValue(t.Template, "parameters") {
+		if defaultValue, ok := definition.(map[string]interface{})["defaultValue"]; ok {
+			values[name] = defaultValue
+		}
+	}
+	for name, parameter := range t.Parameters {
+		if p, ok := parameter.(map[string]interface{}); ok {
+			if value, ok := p["value"]; ok {
+				values[name] = value
+				continue
+			}
+		}
+		values[name] = parameter
+	}
+	return values
+}
+
+func isSecureParameter(t Template, name string) bool {
+	definition, _ := mapValue(t.Template, "parameters")[name].(map[string]interface{})
+	parameterType, _ := definition["type"].(string)
+	return strings.HasPrefix(strings.ToLower(parameterType), "secure")
+}
+
+func dedupeSecureChanges(changes []PropertyChange) []PropertyChange {
+	var deduped []PropertyChange
+	seen := make(map[string]bool)
+	for _, change := range changes {
+		if change.Secure {
+			if seen[change.Path] {
+				continue
+			}
+			seen[change.Path] = true
+		}
+		deduped = append(deduped, change)
+	}
+	return deduped
+}
+
+// compareResources matches the resources of both templates by type and name, then compares their properties
+func compareResources(before, after interface{}) []ResourceChange {
+	beforeResources, beforeKeys := indexResources(before)
+	afterResources, afterKeys := indexResources(after)
+
+	var changes []ResourceChange
+	for _, key := range beforeKeys {
+		if _, ok := afterResources[key]; !ok {
+			r := beforeResources[key]
+			changes = append(changes, ResourceChange{Type: resourceField(r, "type"), Name: resourceField(r, "name"), ChangeType: Removed})
+		}
+	}
+	for _, key := range afterKeys {
+		r := afterResources[key]
+		beforeResource, ok := beforeResources[key]
+		if !ok {
+			changes = append(changes, ResourceChange{Type: resourceField(r, "type"), Name: resourceField(r, "name"), ChangeType: Added})
+			continue
+		}
+		if propertyChanges := compareProperties("", beforeResource, r); len(propertyChanges) > 0 {
+			changes = append(changes, ResourceChange{Type: resourceField(r, "type"), Name: resourceField(r, "name"), ChangeType: Modified, Changes: propertyChanges})
+		}
+	}
+	return changes
+}
+
+// indexResources returns the resources by type and name, and their keys in template order
+func indexResources(resources interface{}) (map[string]map[string]interface{}, []string) {
+	index := make(map[string]map[string]interface{})
+	var keys []string
+	list, _ := resources.([]interface{})
+	for _, resource := range list {
+		r, ok := resource.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key := resourceField(r, "type") + " " + resourceField(r, "name")
+		// Resources with the same type and name, e.g. with different conditions, are matched in order
+		for n := 2; index[key] != nil; n++ {
+			key = fmt.Sprintf("%s %s #%d", resourceField(r, "type"), resourceField(r, "name"), n)
+		}
+		index[key] = r
+		keys = append(keys, key)
+	}
+	return index, keys
+}
+
+func resourceField(r map[string]interface{}, field string) string {
+	s, _ := r[field].(string)
+	return s
+}
+
+// compareProperties compares two JSON objects recursively, returning the changes of the leaf values
+func compareProperties(path string, before, after map[string]interface{}) []PropertyChange {
+	var changes []PropertyChange
+	for _, key := range sortedKeys(before, after) {
+		beforeValue, inBefore := before[key]
+		afterValue, inAfter := after[key]
+		changes = append(changes, compareValues(joinPath(path, key), beforeValue, inBefore, afterValue, inAfter)...)
+	}
+	return changes
+}
+
+func compareValues(path string, before interface{}, inBefore bool, after interface{}, inAfter bool) []PropertyChange {
+	switch {
+	case !inBefore:
+		return []PropertyChange{{Path: path, ChangeType: Added, After: after}}
+	case !inAfter:
+		return []PropertyChange{{Path: path, ChangeType: Removed, Before: before}}
+	case reflect.DeepEqual(before, after):
+		return nil
+	}
+
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		return compareProperties(path, beforeMap, afterMap)
+	}
+
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList {
+		var changes []PropertyChange
+		for i := 0; i < len(beforeList) || i < len(afterList); i++ {
+			var b, a interface{}
+			if i < len(beforeList) {
+				b = beforeList[i]
+			}
+			if i < len(afterList) {
+				a = afterList[i]
+			}
+			changes = append(changes, compareValues(fmt.Sprintf("%s[%d]", path, i), b, i < len(beforeList), a, i < len(afterList))...)
+		}
+		return changes
+	}
+
+	change := PropertyChange{Path: path, ChangeType: Modified, Before: before, After: after}
+	beforeString, beforeIsString := before.(string)
+	afterString, afterIsString := after.(string)
+	if beforeIsString && afterIsString {
+		if beforeFiles, ok := DecodeCustomData(beforeString); ok {
+			if afterFiles, ok := DecodeCustomData(afterString); ok {
+				change.Before, change.After = nil, nil
+				change.Files = compareFiles(beforeFiles, afterFiles)
+				return []PropertyChange{change}
+			}
+		}
+		beforeText, beforeIsText := decodeText(beforeString)
+		afterText, afterIsText := decodeText(afterString)
+		switch {
+		case beforeIsText || afterIsText || strings.Contains(beforeString, "\n") || strings.Contains(afterString, "\n"):
+			change.Before, change.After = nil, nil
+			change.Lines = DiffLines(beforeText, afterText)
+		case len(beforeString) > maxInlineValueLength || len(afterString) > maxInlineValueLength:
+			// Long single line values, e.g. the parameters of the provision script, are compared word by word
+			change.Before, change.After = nil, nil
+			change.Lines = DiffLines(strings.Join(strings.Fields(beforeString), "\n"), strings.Join(strings.Fields(afterString), "\n"))
+		}
+	}
+	return []PropertyChange{change}
+}
+
+// compareFiles compares the files embedded in two custom data payloads
+func compareFiles(before, after []EmbeddedFile) []FileChange {
+	beforeFiles := make(map[string]string)
+	for _, f := range before {
+		beforeFiles[f.Path] = f.Content
+	}
+	afterFiles := make(map[string]string)
+	for _, f := range after {
+		afterFiles[f.Path] = f.Content
+	}
+
+	var changes []FileChange
+	for _, f := range before {
+		if _, ok := afterFiles[f.Path]; !ok {
+			changes = append(changes, FileChange{Path: f.Path, ChangeType: Removed})
+		}
+	}
+	for _, f := range after {
+		beforeContent, ok := beforeFiles[f.Path]
+		switch {
+		case !ok:
+			changes = append(changes, FileChange{Path: f.Path, ChangeType: Added, Lines: DiffLines("", f.Content)})
+		case beforeContent != f.Content:
+			changes = append(changes, FileChange{Path: f.Path, ChangeType: Modified, Lines: DiffLines(beforeContent, f.Content)})
+		}
+	}
+	return changes
+}
+
+func mapValue(m map[string]interface{}, key string) map[string]interface{} {
+	v, _ := m[key].(map[string]interface{})
+	return v
+}
+
+func sortedKeys(maps ...map[string]interface{}) []string {
+	set := make(map[string]bool)
+	for _, m := range maps {
+		for key := range m {
+			set[key] = true
+		}
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
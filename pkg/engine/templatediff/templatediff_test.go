@@ -0,0 +1,205 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package templatediff
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func gzipBase64(t *testing.T, s string) string {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(b.Bytes())
+}
+
+func customData(t *testing.T, kubeletFlags string) string {
+	doc := strings.Join([]string{
+		"#cloud-config",
+		"",
+		"write_files:",
+		"- path: /etc/default/kubelet",
+		"  permissions: \"0644\"",
+		"  encoding: gzip",
+		"  owner: root",
+		"  content: !!binary |",
+		"    " + gzipBase64(t, "KUBELET_CONFIG="+kubeletFlags+"\nKUBELET_NODE_LABELS=\n"),
+		"",
+		"- path: /opt/azure/containers/provision.sh",
+		"  permissions: \"0744\"",
+		"  content: |",
+		"    #!/bin/bash",
+		"",
+		"    #!/bin/bash",
+		"",
+		"runcmd:",
+		"- set -x",
+	}, "\n")
+	return "[base64(concat('" + strings.ReplaceAll(doc, "'", "''") + "'))]"
+}
+
+func loadTestTemplate(t *testing.T, version, secret, kubeletFlags string) Template {
+	template := map[string]interface{}{
+		"parameters": map[string]interface{}{
+			"fqdnEndpointSuffix":           map[string]interface{}{"type": "string", "defaultValue": "cloudapp.azure.com"},
+			"kubernetesVersion":            map[string]interface{}{"type": "string"},
+			"servicePrincipalClientSecret": map[string]interface{}{"type": "securestring"},
+		},
+		"variables": map[string]interface{}{
+			"orchestratorNameVersionTag": "Kubernetes:" + version,
+			"maxVMsPerPool":              100,
+		},
+		"resources": []interface{}{
+			map[string]interface{}{
+				"type":       "Microsoft.Network/virtualNetworks",
+				"name":       "[variables('virtualNetworkName')]",
+				"properties": map[string]interface{}{"addressSpace": map[string]interface{}{"addressPrefixes": []string{"10.0.0.0/8"}}},
+			},
+			map[string]interface{}{
+				"type":       "Microsoft.Compute/virtualMachines",
+				"name":       "[concat(variables('masterVMNamePrefix'), copyIndex(variables('masterOffset')))]",
+				"properties": map[string]interface{}{"osProfile": map[string]interface{}{"customData": customData(t, kubeletFlags)}},
+			},
+		},
+	}
+	parameters := map[string]interface{}{
+		"$schema":        "https://schema.management.azure.com/schemas/2015-01-01/deploymentParameters.json#",
+		"contentVersion": "1.0.0.0",
+		"parameters": map[string]interface{}{
+			"kubernetesVersion":            map[string]interface{}{"value": version},
+			"servicePrincipalClientSecret": map[string]interface{}{"value": secret},
+		},
+	}
+	templateJSON, err := json.Marshal(template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parametersJSON, err := json.Marshal(parameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := LoadTemplate(templateJSON, parametersJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmpl
+}
+
+func TestCompareSameTemplate(t *testing.T) {
+	RegisterTestingT(t)
+	before := loadTestTemplate(t, "1.23.17", "secret", "--v=2")
+	after := loadTestTemplate(t, "1.23.17", "secret", "--v=2")
+
+	d := Compare(before, after)
+	Expect(d.Empty()).To(BeTrue())
+
+	var out bytes.Buffer
+	d.Print(&out)
+	Expect(out.String()).To(Equal("No changes.\n"))
+}
+
+func TestCompare(t *testing.T) {
+	RegisterTestingT(t)
+	before := loadTestTemplate(t, "1.23.17", "secret", "--v=2")
+	after := loadTestTemplate(t, "1.24.17", "rotated", "--v=4")
+
+	d := Compare(before, after)
+	Expect(d.Empty()).To(BeFalse())
+	Expect(d.Parameters).To(Equal([]PropertyChange{
+		{Path: "kubernetesVersion", ChangeType: Modified, Before: "1.23.17", After: "1.24.17"},
+		{Path: "servicePrincipalClientSecret", ChangeType: Modified, Secure: true},
+	}))
+	Expect(d.Variables).To(Equal([]PropertyChange{
+		{Path: "orchestratorNameVersionTag", ChangeType: Modified, Before: "Kubernetes:1.23.17", After: "Kubernetes:1.24.17"},
+	}))
+	Expect(d.Resources).To(HaveLen(1))
+	r := d.Resources[0]
+	Expect(r.Type).To(Equal("Microsoft.Compute/virtualMachines"))
+	Expect(r.ChangeType).To(Equal(Modified))
+	Expect(r.Changes).To(HaveLen(1))
+	Expect(r.Changes[0].Path).To(Equal("properties.osProfile.customData"))
+	Expect(r.Changes[0].Files).To(Equal([]FileChange{
+		{
+			Path:       "/etc/default/kubelet",
+			ChangeType: Modified,
+			Lines: []string{
+				"@@ -1,2 +1,2 @@",
+				"-KUBELET_CONFIG=--v=2",
+				"+KUBELET_CONFIG=--v=4",
+				" KUBELET_NODE_LABELS=",
+			},
+		},
+	}))
+
+	var out bytes.Buffer
+	d.Print(&out)
+	Expect(out.String()).To(ContainSubstring("  ~ kubernetesVersion: \"1.23.17\" => \"1.24.17\"\n"))
+	Expect(out.String()).To(ContainSubstring("  ~ servicePrincipalClientSecret: (secure value)\n"))
+	Expect(out.String()).NotTo(ContainSubstring("rotated"))
+	Expect(out.String()).To(ContainSubstring("      ~ properties.osProfile.customData:\n          ~ /etc/default/kubelet\n"))
+	Expect(out.String()).To(HaveSuffix("2 parameter(s), 1 variable(s) and 1 resource(s) changed.\n"))
+}
+
+func TestCompareResources(t *testing.T) {
+	RegisterTestingT(t)
+	before := Template{Template: map[string]interface{}{
+		"resources": []interface{}{
+			map[string]interface{}{"type": "Microsoft.Network/networkSecurityGroups", "name": "nsg"},
+			map[string]interface{}{"type": "Microsoft.Network/routeTables", "name": "rt"},
+		},
+	}}
+	after := Template{Template: map[string]interface{}{
+		"resources": []interface{}{
+			map[string]interface{}{"type": "Microsoft.Network/networkSecurityGroups", "name": "nsg"},
+			map[string]interface{}{"type": "Microsoft.Network/publicIPAddresses", "name": "ip"},
+		},
+	}}
+
+	d := Compare(before, after)
+	Expect(d.Resources).To(Equal([]ResourceChange{
+		{Type: "Microsoft.Network/routeTables", Name: "rt", ChangeType: Removed},
+		{Type: "Microsoft.Network/publicIPAddresses", Name: "ip", ChangeType: Added},
+	}))
+}
+
+func TestCompareLongValue(t *testing.T) {
+	RegisterTestingT(t)
+	flags := strings.Repeat("--flag=value ", 20)
+	before := Template{Template: map[string]interface{}{"variables": map[string]interface{}{"flags": flags + "--v=2"}}}
+	after := Template{Template: map[string]interface{}{"variables": map[string]interface{}{"flags": flags + "--v=4"}}}
+
+	d := Compare(before, after)
+	Expect(d.Variables).To(HaveLen(1))
+	Expect(d.Variables[0].Before).To(BeNil())
+	Expect(d.Variables[0].Lines).To(Equal([]string{
+		"@@ -18,4 +18,4 @@",
+		" --flag=value",
+		" --flag=value",
+		" --flag=value",
+		"---v=2",
+		"+--v=4",
+	}))
+}
+
+func TestLoadTemplate(t *testing.T) {
+	RegisterTestingT(t)
+	_, err := LoadTemplate([]byte("{"), nil)
+	Expect(err).To(HaveOccurred())
+
+	tmpl, err := LoadTemplate([]byte(`{"parameters": {}}`), []byte(`{"kubernetesVersion": {"value": "1.24.17"}}`))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(tmpl.Parameters).To(HaveKey("kubernetesVersion"))
+}
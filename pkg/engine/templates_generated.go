@@ -21,6 +21,8 @@
 // ../../parts/k8s/addons/container-monitoring.yaml
 // ../../parts/k8s/addons/coredns.yaml
 // ../../parts/k8s/addons/flannel.yaml
+// ../../parts/k8s/addons/gmsa-webhook.yaml
+// ../../parts/k8s/addons/hyperv-runtimeclass.yaml
 // ../../parts/k8s/addons/ip-masq-agent.yaml
 // ../../parts/k8s/addons/keyvault-flexvolume.yaml
 // ../../parts/k8s/addons/kube-dns.yaml
@@ -79,6 +81,7 @@
 // ../../parts/k8s/cloud-init/jumpboxcustomdata.yml
 // ../../parts/k8s/cloud-init/masternodecustomdata.yml
 // ../../parts/k8s/cloud-init/nodecustomdata.yml
+// ../../parts/k8s/collect-logs-flatcar.sh
 // ../../parts/k8s/containerdtemplate.toml
 // ../../parts/k8s/kubeconfig.json
 // ../../parts/k8s/kubernetesparams.t
@@ -98,6 +101,7 @@
 // ../../parts/k8s/windowsconfigfunc.ps1
 // ../../parts/k8s/windowscontainerdfunc.ps1
 // ../../parts/k8s/windowscsiproxyfunc.ps1
+// ../../parts/k8s/windowsgmsafunc.ps1
 // ../../parts/k8s/windowshostsconfigagentfunc.ps1
 // ../../parts/k8s/windowsinstallopensshfunc.ps1
 // ../../parts/k8s/windowskubeletfunc.ps1
@@ -13433,6 +13437,267 @@ func k8sAddonsFlannelYaml() (*asset, error) {
 	return a, nil
 }
 
+var _k8sAddonsGmsaWebhookYaml = []byte(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gmsacredentialspecs.windows.k8s.io
+  labels:
+    addonmanager.kubernetes.io/mode: Reconcile
+spec:
+  group: windows.k8s.io
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          credspec:
+            description: GMSA Credential Spec
+            type: object
+            x-kubernetes-preserve-unknown-fields: true
+  conversion:
+    strategy: None
+  names:
+    kind: GMSACredentialSpec
+    plural: gmsacredentialspecs
+  scope: Cluster
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: gmsa-webhook
+  namespace: kube-system
+  labels:
+    addonmanager.kubernetes.io/mode: Reconcile
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: gmsa-webhook
+  labels:
+    addonmanager.kubernetes.io/mode: Reconcile
+rules:
+- apiGroups: ["authorization.k8s.io"]
+  resources: ["localsubjectaccessreviews"]
+  verbs: ["create"]
+- apiGroups: ["windows.k8s.io"]
+  resources: ["gmsacredentialspecs"]
+  verbs: ["get", "list", "watch"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: gmsa-webhook
+  labels:
+    addonmanager.kubernetes.io/mode: Reconcile
+subjects:
+- kind: ServiceAccount
+  name: gmsa-webhook
+  namespace: kube-system
+roleRef:
+  kind: ClusterRole
+  name: gmsa-webhook
+  apiGroup: rbac.authorization.k8s.io
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: gmsa-webhook
+  namespace: kube-system
+  labels:
+    addonmanager.kubernetes.io/mode: Reconcile
+type: kubernetes.io/tls
+data:
+  tls.crt: {{GetServingCertificateBase64}}
+  tls.key: {{GetServingPrivateKeyBase64}}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: gmsa-webhook
+  namespace: kube-system
+  labels:
+    app: gmsa-webhook
+    addonmanager.kubernetes.io/mode: Reconcile
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: gmsa-webhook
+  template:
+    metadata:
+      labels:
+        app: gmsa-webhook
+    spec:
+      serviceAccountName: gmsa-webhook
+      priorityClassName: system-cluster-critical
+      nodeSelector:
+        kubernetes.io/os: linux
+      tolerations:
+      - key: CriticalAddonsOnly
+        operator: Exists
+      - key: node-role.kubernetes.io/master
+        operator: Equal
+        value: "true"
+        effect: NoSchedule
+      containers:
+      - name: gmsa-webhook
+        image: {{ContainerImage "gmsa-webhook"}}
+        imagePullPolicy: IfNotPresent
+        env:
+        - name: TLS_KEY
+          value: /tls/key
+        - name: TLS_CRT
+          value: /tls/crt
+        ports:
+        - containerPort: 443
+        readinessProbe:
+          httpGet:
+            scheme: HTTPS
+            path: /health
+            port: 443
+        resources:
+          requests:
+            cpu: {{ContainerCPUReqs "gmsa-webhook"}}
+            memory: {{ContainerMemReqs "gmsa-webhook"}}
+          limits:
+            cpu: {{ContainerCPULimits "gmsa-webhook"}}
+            memory: {{ContainerMemLimits "gmsa-webhook"}}
+        volumeMounts:
+        - name: tls
+          mountPath: /tls
+          readOnly: true
+      volumes:
+      - name: tls
+        secret:
+          secretName: gmsa-webhook
+          items:
+          - key: tls.key
+            path: key
+          - key: tls.crt
+            path: crt
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: gmsa-webhook
+  namespace: kube-system
+  labels:
+    addonmanager.kubernetes.io/mode: Reconcile
+spec:
+  ports:
+  - port: 443
+    targetPort: 443
+  selector:
+    app: gmsa-webhook
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: gmsa-webhook
+  labels:
+    addonmanager.kubernetes.io/mode: Reconcile
+webhooks:
+- name: admission-webhook.windows-gmsa.sigs.k8s.io
+  clientConfig:
+    service:
+      name: gmsa-webhook
+      namespace: kube-system
+      path: /validate
+    caBundle: {{GetCACertificateBase64}}
+  rules:
+  - operations: ["CREATE", "UPDATE"]
+    apiGroups: [""]
+    apiVersions: ["*"]
+    resources: ["pods"]
+  failurePolicy: Fail
+  sideEffects: None
+  admissionReviewVersions: ["v1", "v1beta1"]
+  namespaceSelector:
+    matchExpressions:
+    - key: gmsa-webhook
+      operator: NotIn
+      values: [disabled]
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: gmsa-webhook
+  labels:
+    addonmanager.kubernetes.io/mode: Reconcile
+webhooks:
+- name: admission-webhook.windows-gmsa.sigs.k8s.io
+  clientConfig:
+    service:
+      name: gmsa-webhook
+      namespace: kube-system
+      path: /mutate
+    caBundle: {{GetCACertificateBase64}}
+  rules:
+  - operations: ["CREATE"]
+    apiGroups: [""]
+    apiVersions: ["*"]
+    resources: ["pods"]
+  failurePolicy: Fail
+  sideEffects: None
+  admissionReviewVersions: ["v1", "v1beta1"]
+  namespaceSelector:
+    matchExpressions:
+    - key: gmsa-webhook
+      operator: NotIn
+      values: [disabled]
+`)
+
+func k8sAddonsGmsaWebhookYamlBytes() ([]byte, error) {
+	return _k8sAddonsGmsaWebhookYaml, nil
+}
+
+func k8sAddonsGmsaWebhookYaml() (*asset, error) {
+	bytes, err := k8sAddonsGmsaWebhookYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "k8s/addons/gmsa-webhook.yaml", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _k8sAddonsHypervRuntimeclassYaml = []byte(`{{- range $i, $handler := GetWindowsHypervRuntimeHandlerNames}}
+{{- if $i}}
+---
+{{- end}}
+apiVersion: {{GetRuntimeClassAPIVersion}}
+kind: RuntimeClass
+metadata:
+  name: {{$handler}}
+  labels:
+    addonmanager.kubernetes.io/mode: Reconcile
+handler: {{$handler}}
+scheduling:
+  nodeSelector:
+    kubernetes.io/os: windows
+    {{GetWindowsRuntimeHandlerLabelPrefix}}{{$handler}}: "true"
+{{- end}}
+`)
+
+func k8sAddonsHypervRuntimeclassYamlBytes() ([]byte, error) {
+	return _k8sAddonsHypervRuntimeclassYaml, nil
+}
+
+func k8sAddonsHypervRuntimeclassYaml() (*asset, error) {
+	bytes, err := k8sAddonsHypervRuntimeclassYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "k8s/addons/hyperv-runtimeclass.yaml", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 var _k8sAddonsIpMasqAgentYaml = []byte(`apiVersion: apps/v1
 kind: DaemonSet
 metadata:
@@ -16457,6 +16722,7 @@ configureK8s() {
     "vnetName": "${VIRTUAL_NETWORK}",
     "vnetResourceGroup": "${VIRTUAL_NETWORK_RESOURCE_GROUP}",
     "routeTableName": "${ROUTE_TABLE}",
+    "routeTableResourceGroup": "${ROUTE_TABLE_RESOURCE_GROUP:-}",
     "primaryAvailabilitySetName": "${PRIMARY_AVAILABILITY_SET}",
     "primaryScaleSetName": "${PRIMARY_SCALE_SET}",
     "cloudProviderBackoffMode": "${CLOUDPROVIDER_BACKOFF_MODE}",
@@ -16578,7 +16844,7 @@ installContainerd() {
     os_lower=$(echo ${OS} | tr '[:upper:]' '[:lower:]')
     if [[ ${OS} == "${UBUNTU_OS_NAME}" ]]; then
       url_path="${os_lower}/${UBUNTU_RELEASE}"
-      [[ ${UBUNTU_RELEASE} == "20.04" ]] || url_path+="/multiarch"
+      [[ ${UBUNTU_RELEASE} == "22.04" || ${UBUNTU_RELEASE} == "20.04" ]] || url_path+="/multiarch"
       url_path+="/prod"
     elif [[ ${OS} == "${DEBIAN_OS_NAME}" ]]; then
       url_path="${os_lower}/${UBUNTU_RELEASE}/prod"
@@ -17241,8 +17507,9 @@ fi
 if [[ ${OS} == "${UBUNTU_OS_NAME}" ]]; then
   UBUNTU_RELEASE=$(lsb_release -r -s)
 fi
+CPU_ARCH=$(uname -m | sed -e 's/x86_64/amd64/' -e 's/aarch64/arm64/')
 DOCKER=/usr/bin/docker
-if [[ $UBUNTU_RELEASE == "20.04" || $UBUNTU_RELEASE == "18.04" ]]; then
+if [[ $UBUNTU_RELEASE == "22.04" || $UBUNTU_RELEASE == "20.04" || $UBUNTU_RELEASE == "18.04" ]]; then
   export GPU_DV=515.65.01
 else
   export GPU_DV=418.40.04
@@ -17571,8 +17838,10 @@ installDeps() {
     retrycmd_no_stats 120 5 25 curl ${MS_APT_REPO}/keys/microsoft.asc | gpg --dearmor >/tmp/microsoft.gpg || exit 26
     retrycmd 10 5 10 cp /tmp/microsoft.gpg /etc/apt/trusted.gpg.d/ || exit 26
     aptmarkWALinuxAgent hold
-    packages+=" cgroup-lite ceph-common glusterfs-client"
-    if [[ $UBUNTU_RELEASE == "20.04" || $UBUNTU_RELEASE == "18.04" ]]; then
+    packages+=" ceph-common glusterfs-client"
+    {{/* cgroup-lite mounts the cgroup v1 hierarchies, 22.04 boots with the unified cgroup v2 hierarchy */}}
+    [[ $UBUNTU_RELEASE == "22.04" ]] || packages+=" cgroup-lite"
+    if [[ $UBUNTU_RELEASE == "22.04" || $UBUNTU_RELEASE == "20.04" || $UBUNTU_RELEASE == "18.04" ]]; then
       disableTimeSyncd
       packages+=" ntp ntpstat chrony net-tools"
     fi
@@ -17636,8 +17905,8 @@ installRunc() {
   v=$(runc --version | head -n 1 | cut -d" " -f3)
   if [[ $v != "1.1.2" ]]; then
     url=${MS_APT_REPO}/ubuntu/${UBUNTU_RELEASE}
-    [[ ${UBUNTU_RELEASE} == "20.04" ]] || url=${url}/multiarch
-    url=${url}/prod/pool/main/m/moby-runc/moby-runc_1.1.2%2Bazure-ubuntu${UBUNTU_RELEASE}u1_amd64.deb
+    [[ ${UBUNTU_RELEASE} == "22.04" || ${UBUNTU_RELEASE} == "20.04" ]] || url=${url}/multiarch
+    url=${url}/prod/pool/main/m/moby-runc/moby-runc_1.1.2%2Bazure-ubuntu${UBUNTU_RELEASE}u1_${CPU_ARCH}.deb
     if [[ -n "${url:-}" ]]; then
       DEB="${url##*/}"
       retrycmd_no_stats 120 5 25 curl -fsSL ${url} >/tmp/${DEB} || exit 184
@@ -17727,6 +17996,8 @@ installBpftrace() {
   chmod -R +x "$tools_fp/tools"
 }
 installImg() {
+  {{/* img is only published for amd64 */}}
+  [[ ${CPU_ARCH} == "amd64" ]] || return 0
   img_filepath=/usr/local/bin/img
   retrycmd_get_executable 120 5 $img_filepath "https://upstreamartifacts.azureedge.net/img/img-linux-amd64-v0.5.6" ls || exit 33
 }
@@ -17756,7 +18027,7 @@ extractHyperkube() {
   fi
 }
 extractKubeBinaries() {
-  KUBE_BINARY_URL=${KUBE_BINARY_URL:-"https://kubernetesartifacts.azureedge.net/kubernetes/v${KUBERNETES_VERSION}/binaries/kubernetes-node-linux-amd64.tar.gz"}
+  KUBE_BINARY_URL=${KUBE_BINARY_URL:-"https://kubernetesartifacts.azureedge.net/kubernetes/v${KUBERNETES_VERSION}/binaries/kubernetes-node-linux-${CPU_ARCH}.tar.gz"}
   local dest="/opt/kubernetes/downloads" tmpDir=${KUBE_BINARY_URL##*/}
   mkdir -p "${dest}"
   retrycmd_get_tarball 120 5 "$dest/${tmpDir}" ${KUBE_BINARY_URL} || exit 31
@@ -17849,7 +18120,7 @@ wait_for_file 3600 1 {{GetCustomCloudConfigCSEScriptFilepath}} || exit {{GetCSEE
 source {{GetCustomCloudConfigCSEScriptFilepath }}
 {{end}}
 
-if [[ ${UBUNTU_RELEASE} == "20.04" || ${UBUNTU_RELEASE} == "18.04" ]]; then
+if [[ ${UBUNTU_RELEASE} == "22.04" || ${UBUNTU_RELEASE} == "20.04" || ${UBUNTU_RELEASE} == "18.04" ]]; then
   disable1804SystemdResolved
 fi
 
@@ -17887,7 +18158,7 @@ fi
 {{- if not IsVHDDistroForAllNodes}}
 if [[ $OS == $UBUNTU_OS_NAME || $OS == $DEBIAN_OS_NAME ]] && [ "$FULL_INSTALL_REQUIRED" = "true" ]; then
   time_metric "InstallDeps" installDeps
-  if [[ ${UBUNTU_RELEASE} == "20.04" || ${UBUNTU_RELEASE} == "18.04" ]]; then
+  if [[ ${UBUNTU_RELEASE} == "22.04" || ${UBUNTU_RELEASE} == "20.04" || ${UBUNTU_RELEASE} == "18.04" ]]; then
     overrideNetworkConfig
   fi
   {{- if not IsDockerContainerRuntime}}
@@ -17896,7 +18167,7 @@ if [[ $OS == $UBUNTU_OS_NAME || $OS == $DEBIAN_OS_NAME ]] && [ "$FULL_INSTALL_RE
 fi
 {{end}}
 
-if [[ ${UBUNTU_RELEASE} == "20.04" || ${UBUNTU_RELEASE} == "18.04" ]]; then
+if [[ ${UBUNTU_RELEASE} == "22.04" || ${UBUNTU_RELEASE} == "20.04" || ${UBUNTU_RELEASE} == "18.04" ]]; then
   if apt list --installed | grep 'chrony'; then
     time_metric "ConfigureChrony" configureChrony
     time_metric "EnsureChrony" ensureChrony
@@ -18100,7 +18371,9 @@ fi
 
 {{- if not HasBlockOutboundInternet}}
     {{- if RunUnattendedUpgradesOnBootstrap}}
-apt_get_update && unattended_upgrade
+if [[ $OS == $UBUNTU_OS_NAME ]]; then
+  apt_get_update && unattended_upgrade
+fi
     {{- end}}
 {{- end}}
 
@@ -20348,7 +20621,7 @@ write_files:
   {{end}}
 {{end}}
 
-{{- if .IsUbuntu2004}}
+{{- if or .IsUbuntu2004 .IsUbuntu2204}}
   {{- if not .IsVHDDistro}}
 - path: /var/run/reboot-required
   permissions: "0644"
@@ -20533,7 +20806,7 @@ write_files:
   {{- if IsNSeriesSKU .VMSize}}
 {{IndentString GetNvidiaContainerdConfig 4}}
   {{else}}
-{{IndentString GetContainerdConfig 4}}
+{{IndentString (GetAgentContainerdConfig .) 4}}
   {{- end}}
     #EOF
 
@@ -20749,6 +21022,101 @@ func k8sCloudInitNodecustomdataYml() (*asset, error) {
 	return a, nil
 }
 
+var _k8sCollectLogsFlatcarSh = []byte(`#!/bin/bash
+
+# Log collection script for Flatcar nodes, which do not ship the aks-engine VHD script nor zip
+
+set -o pipefail
+
+collectCloudProviderJson() {
+    local DIR=${OUTDIR}/etc/kubernetes
+    mkdir -p ${DIR}
+    if [ -f /etc/kubernetes/azure.json ]; then
+        grep -v aadClient /etc/kubernetes/azure.json > ${DIR}/azure.json
+    fi
+    if [ -f /etc/kubernetes/network_interfaces.json ]; then
+        cp /etc/kubernetes/network_interfaces.json ${DIR}
+    fi
+    if [ -f /etc/kubernetes/interfaces.json ]; then
+        cp /etc/kubernetes/interfaces.json ${DIR}
+    fi
+}
+
+collectDirLogs() {
+    local DIR=${OUTDIR}${1}
+    if [ -d ${1} ]; then
+        mkdir -p ${DIR}
+        cp ${1}/*.log ${DIR}
+    fi
+}
+
+collectDir() {
+    local DIR=${OUTDIR}${1}
+    if [ -d ${1} ]; then
+        mkdir -p ${DIR}
+        cp ${1}/* ${DIR}
+    fi
+}
+
+collectDaemonLogs() {
+    local DIR=${OUTDIR}/daemons
+    mkdir -p ${DIR}
+    if systemctl list-units --no-pager | grep -q ${1}; then
+        timeout 15 systemctl status ${1} &> ${DIR}/${1}.status
+        timeout 15 journalctl --utc -o short-iso --no-pager -r -u ${1} &> /tmp/${1}.log
+        tac /tmp/${1}.log > ${DIR}/${1}.log
+    fi
+}
+
+collectIgnitionLogs() {
+    local DIR=${OUTDIR}/ignition
+    mkdir -p ${DIR}
+    timeout 15 journalctl --utc -o short-iso --no-pager -t ignition &> ${DIR}/ignition.log
+}
+
+collectContainerLogs() {
+    local DIR=${OUTDIR}/containers
+    mkdir -p ${DIR}
+    find /var/log/containers -name "${1}*" -exec cp {} ${DIR} \;
+}
+
+compressLogsDirectory() {
+    sync
+    TGZ="/tmp/logs.tar.gz"
+    rm -f ${TGZ}
+    tar -C ${OUTDIR}/.. -czf ${TGZ} ${HOSTNAME}
+}
+
+OUTDIR="$(mktemp -d)/${HOSTNAME}"
+
+collectCloudProviderJson
+collectDirLogs /var/log
+collectDirLogs /var/log/azure
+collectDir /etc/kubernetes/manifests
+collectContainerLogs kube-proxy
+collectDaemonLogs kubelet.service
+collectDaemonLogs docker.service
+collectDaemonLogs containerd.service
+collectIgnitionLogs
+
+compressLogsDirectory
+`)
+
+func k8sCollectLogsFlatcarShBytes() ([]byte, error) {
+	return _k8sCollectLogsFlatcarSh, nil
+}
+
+func k8sCollectLogsFlatcarSh() (*asset, error) {
+	bytes, err := k8sCollectLogsFlatcarShBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "k8s/collect-logs-flatcar.sh", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 var _k8sContainerdtemplateToml = []byte(`root = "C:\\ProgramData\\containerd\\root"
 state = "C:\\ProgramData\\containerd\\state"
 
@@ -21778,7 +22146,10 @@ param(
     $TargetEnvironment,
 
     [string]
-    $UserAssignedClientID
+    $UserAssignedClientID,
+
+    [string]
+    $GmsaDomainJoinPassword # base64
 )
 
 # These globals will not change between nodes in the same cluster, so they are not
@@ -21799,7 +22170,7 @@ $global:ContainerdUrl = "{{WrapAsParameter "windowsContainerdURL"}}"
 $global:ContainerdSdnPluginUrl = "{{WrapAsParameter "windowsSdnPluginURL"}}"
 
 ## Docker Version
-$global:DockerVersion = "{{WrapAsParameter "windowsDockerVersion"}}"
+$global:DockerVersion = "{{if .WindowsDockerVersion}}{{.WindowsDockerVersion}}{{else}}{{WrapAsParameter "windowsDockerVersion"}}{{end}}"
 
 ## ContainerD Usage
 $global:ContainerRuntime = "{{WrapAsParameter "containerRuntime"}}"
@@ -21821,6 +22192,7 @@ $global:MasterSubnet = "{{GetWindowsMasterSubnetARMParam}}"
 $global:SecurityGroupName = "{{WrapAsVariable "nsgName"}}"
 $global:VNetName = "{{WrapAsVariable "virtualNetworkName"}}"
 $global:RouteTableName = "{{WrapAsVariable "routeTableName"}}"
+$global:RouteTableResourceGroup = "{{WrapAsVariable "routeTableResourceGroup"}}"
 $global:PrimaryAvailabilitySetName = "{{WrapAsVariable "primaryAvailabilitySetName"}}"
 $global:PrimaryScaleSetName = "{{WrapAsVariable "primaryScaleSetName"}}"
 
@@ -21882,6 +22254,14 @@ $global:AlwaysPullWindowsPauseImage = [System.Convert]::ToBoolean("{{WrapAsVaria
 # Secure Windows TLS protocols
 $global:WindowsSecureTLSEnabled = [System.Convert]::ToBoolean("{{WrapAsVariable "windowsSecureTLSEnabled" }}");
 
+# gMSA settings
+$global:GmsaMode = "{{WrapAsVariable "windowsGmsaMode" }}";
+$global:GmsaDomainName = "{{WrapAsVariable "windowsGmsaDomainName" }}";
+$global:GmsaDomainJoinUser = "{{WrapAsVariable "windowsGmsaDomainJoinUser" }}";
+$global:GmsaOrganizationalUnit = "{{WrapAsVariable "windowsGmsaOrganizationalUnit" }}";
+$global:GmsaCCGPluginURL = "{{WrapAsVariable "windowsGmsaCCGPluginURL" }}";
+$global:GmsaCCGPluginCLSID = "{{WrapAsVariable "windowsGmsaCCGPluginCLSID" }}";
+
 # Base64 representation of ZIP archive
 $zippedFiles = "{{ GetKubernetesWindowsAgentFunctions }}"
 
@@ -21899,6 +22279,7 @@ Expand-Archive scripts.zip -DestinationPath "C:\\AzureData\\"
 . c:\AzureData\k8s\windowsinstallopensshfunc.ps1
 . c:\AzureData\k8s\windowscontainerdfunc.ps1
 . c:\AzureData\k8s\windowshostsconfigagentfunc.ps1
+. c:\AzureData\k8s\windowsgmsafunc.ps1
 
 $useContainerD = ($global:ContainerRuntime -eq "containerd")
 $global:KubeClusterConfigPath = "c:\k\kubeclusterconfig.json"
@@ -22040,6 +22421,7 @@ try
             -SecurityGroupName $global:SecurityGroupName ` + "`" + `
             -VNetName $global:VNetName ` + "`" + `
             -RouteTableName $global:RouteTableName ` + "`" + `
+            -RouteTableResourceGroup $global:RouteTableResourceGroup ` + "`" + `
             -PrimaryAvailabilitySetName $global:PrimaryAvailabilitySetName ` + "`" + `
             -PrimaryScaleSetName $global:PrimaryScaleSetName ` + "`" + `
             -UseManagedIdentityExtension $global:UseManagedIdentityExtension ` + "`" + `
@@ -22161,6 +22543,18 @@ try
             Enable-SecureTls
         }
 
+        if ($global:GmsaMode -eq "DomainJoin") {
+            Write-Log "Join node to domain $global:GmsaDomainName for gMSA"
+            Join-GmsaDomain -DomainName $global:GmsaDomainName ` + "`" + `
+                -DomainJoinUser $global:GmsaDomainJoinUser ` + "`" + `
+                -DomainJoinPassword $GmsaDomainJoinPassword ` + "`" + `
+                -OrganizationalUnit $global:GmsaOrganizationalUnit
+        } elseif ($global:GmsaMode -eq "CCGPlugin") {
+            Write-Log "Install gMSA CCG plugin"
+            Install-GmsaCCGPlugin -CCGPluginURL $global:GmsaCCGPluginURL ` + "`" + `
+                -CCGPluginCLSID $global:GmsaCCGPluginCLSID
+        }
+
         Write-Log "Adjust pagefile size"
         Adjust-PageFileSize
 
@@ -23840,6 +24234,140 @@ func k8sWindowscsiproxyfuncPs1() (*asset, error) {
 	return a, nil
 }
 
+var _k8sWindowsgmsafuncPs1 = []byte(`function Join-GmsaDomain {
+    Param(
+        [Parameter(Mandatory = $true)][string]
+        $DomainName,
+        [Parameter(Mandatory = $true)][string]
+        $DomainJoinUser,
+        [Parameter(Mandatory = $true)][string]
+        $DomainJoinPassword, # base64
+        [Parameter(Mandatory = $false)][string]
+        $OrganizationalUnit
+    )
+
+    if ((Get-WmiObject -Class Win32_ComputerSystem).Domain -eq $DomainName) {
+        Write-Log "Node is already joined to domain $DomainName"
+        return
+    }
+
+    $password = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String($DomainJoinPassword))
+    $securePassword = ConvertTo-SecureString -String $password -AsPlainText -Force
+    $credential = New-Object System.Management.Automation.PSCredential($DomainJoinUser, $securePassword)
+
+    $joinParams = @{
+        DomainName = $DomainName
+        Credential = $credential
+        Force = $true
+        ErrorAction = "Stop"
+    }
+    if (-not [string]::IsNullOrEmpty($OrganizationalUnit)) {
+        $joinParams["OUPath"] = $OrganizationalUnit
+    }
+
+    # The node is restarted at the end of the provisioning, which completes the domain join
+    Write-Log "Joining node to domain $DomainName"
+    Retry-Command -Command "Add-Computer" -Args $joinParams -Retries 5 -RetryDelaySeconds 10
+}
+
+function Install-GmsaCCGPlugin {
+    Param(
+        [Parameter(Mandatory = $true)][string]
+        $CCGPluginURL,
+        [Parameter(Mandatory = $true)][string]
+        $CCGPluginCLSID
+    )
+
+    $tempdir = New-TemporaryDirectory
+    $pluginPackage = "$tempdir\ccgakvplugin.zip"
+
+    DownloadFileOverHttp -Url $CCGPluginURL -DestinationPath $pluginPackage
+    Expand-Archive -Path $pluginPackage -DestinationPath $tempdir -Force
+
+    $pluginDll = Get-ChildItem -Path $tempdir -Filter "CCGAKVPlugin.dll" -Recurse | Select-Object -First 1
+    if ($null -eq $pluginDll) {
+        throw "CCGAKVPlugin.dll was not found in $CCGPluginURL"
+    }
+    Copy-Item -Path $pluginDll.FullName -Destination "$env:SystemRoot\System32\CCGAKVPlugin.dll" -Force
+
+    del $tempdir -Recurse
+
+    # The plugin is a COM server that Container Credential Guard (CCG) activates
+    # to retrieve the gMSA credentials from Azure Key Vault
+    Write-Log "Registering the gMSA CCG plugin"
+    & "$env:SystemRoot\System32\regsvr32.exe" /s "$env:SystemRoot\System32\CCGAKVPlugin.dll"
+    if ($LASTEXITCODE -ne 0) {
+        throw "Failed to register CCGAKVPlugin.dll, exit code $LASTEXITCODE"
+    }
+
+    $ccgKey = "HKLM:\SYSTEM\CurrentControlSet\Control\CCG\COMClasses\$CCGPluginCLSID"
+    if (-not (Test-Path $ccgKey)) {
+        Grant-CCGRegistryKeyOwnership
+        New-Item -Path $ccgKey -Force | Out-Null
+    }
+}
+
+function Grant-CCGRegistryKeyOwnership {
+    # The CCG COMClasses key is owned by TrustedInstaller, take ownership and grant
+    # Administrators full control so that the plugin can be registered
+    $keyPath = "SYSTEM\CurrentControlSet\Control\CCG\COMClasses"
+    $definition = @"
+using System;
+using System.Runtime.InteropServices;
+public class CCGTokenPrivilege {
+    [DllImport("advapi32.dll", ExactSpelling = true, SetLastError = true)]
+    internal static extern bool AdjustTokenPrivileges(IntPtr htok, bool disall, ref TokPriv1Luid newst, int len, IntPtr prev, IntPtr relen);
+    [DllImport("advapi32.dll", ExactSpelling = true, SetLastError = true)]
+    internal static extern bool OpenProcessToken(IntPtr h, int acc, ref IntPtr phtok);
+    [DllImport("advapi32.dll", SetLastError = true)]
+    internal static extern bool LookupPrivilegeValue(string host, string name, ref long pluid);
+    [StructLayout(LayoutKind.Sequential, Pack = 1)]
+    internal struct TokPriv1Luid { public int Count; public long Luid; public int Attr; }
+    public static bool Enable(long processHandle, string privilege) {
+        TokPriv1Luid tp;
+        IntPtr htok = IntPtr.Zero;
+        OpenProcessToken(new IntPtr(processHandle), 0x28, ref htok);
+        tp.Count = 1;
+        tp.Luid = 0;
+        tp.Attr = 2;
+        LookupPrivilegeValue(null, privilege, ref tp.Luid);
+        return AdjustTokenPrivileges(htok, false, ref tp, 0, IntPtr.Zero, IntPtr.Zero);
+    }
+}
+"@
+    Add-Type -TypeDefinition $definition
+    $processHandle = (Get-Process -Id $pid).Handle
+    [CCGTokenPrivilege]::Enable($processHandle, "SeTakeOwnershipPrivilege") | Out-Null
+
+    $administrators = New-Object System.Security.Principal.SecurityIdentifier("S-1-5-32-544")
+    $key = [Microsoft.Win32.Registry]::LocalMachine.OpenSubKey($keyPath, [Microsoft.Win32.RegistryKeyPermissionCheck]::ReadWriteSubTree, [System.Security.AccessControl.RegistryRights]::TakeOwnership)
+    $acl = $key.GetAccessControl([System.Security.AccessControl.AccessControlSections]::None)
+    $acl.SetOwner($administrators)
+    $key.SetAccessControl($acl)
+
+    $key = [Microsoft.Win32.Registry]::LocalMachine.OpenSubKey($keyPath, [Microsoft.Win32.RegistryKeyPermissionCheck]::ReadWriteSubTree, [System.Security.AccessControl.RegistryRights]::ChangePermissions)
+    $acl = $key.GetAccessControl()
+    $rule = New-Object System.Security.AccessControl.RegistryAccessRule($administrators, "FullControl", "ContainerInherit", "None", "Allow")
+    $acl.SetAccessRule($rule)
+    $key.SetAccessControl($acl)
+}
+`)
+
+func k8sWindowsgmsafuncPs1Bytes() ([]byte, error) {
+	return _k8sWindowsgmsafuncPs1, nil
+}
+
+func k8sWindowsgmsafuncPs1() (*asset, error) {
+	bytes, err := k8sWindowsgmsafuncPs1Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "k8s/windowsgmsafunc.ps1", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 var _k8sWindowshostsconfigagentfuncPs1 = []byte(`function New-HostsConfigService {
     $HostsConfigParameters = [io.path]::Combine($KubeDir, "hostsconfigagent.ps1")
 
@@ -23979,6 +24507,8 @@ Write-AzureConfig {
         $VNetName,
         [Parameter(Mandatory = $true)][string]
         $RouteTableName,
+        [Parameter(Mandatory = $false)][string]
+        $RouteTableResourceGroup,
         [Parameter(Mandatory = $false)][string] # Need one of these configured
         $PrimaryAvailabilitySetName,
         [Parameter(Mandatory = $false)][string] # Need one of these configured
@@ -24021,6 +24551,7 @@ Write-AzureConfig {
     "securityGroupName": "$SecurityGroupName",
     "vnetName": "$VNetName",
     "routeTableName": "$RouteTableName",
+    "routeTableResourceGroup": "$RouteTableResourceGroup",
     "primaryAvailabilitySetName": "$PrimaryAvailabilitySetName",
     "primaryScaleSetName": "$PrimaryScaleSetName",
     "useManagedIdentityExtension": $UseManagedIdentityExtension,
@@ -24683,6 +25214,14 @@ var _windowsparamsT = []byte(`    "kubeBinariesSASURL": {
         "description": "Password for the Windows Swarm Agent Virtual Machines."
       }
     },
+{{if HasWindowsGMSADomainJoin}}
+    "windowsGmsaDomainJoinPassword": {
+      "type": "securestring",
+      "metadata": {
+        "description": "Password of the user that joins the Windows agent virtual machines to the Active Directory domain."
+      }
+    },
+{{end}}
     "agentWindowsImageName": {
       "defaultValue": "",
       "type": "string",
@@ -24843,6 +25382,8 @@ var _bindata = map[string]func() (*asset, error){
 	"k8s/addons/container-monitoring.yaml":                               k8sAddonsContainerMonitoringYaml,
 	"k8s/addons/coredns.yaml":                                            k8sAddonsCorednsYaml,
 	"k8s/addons/flannel.yaml":                                            k8sAddonsFlannelYaml,
+	"k8s/addons/gmsa-webhook.yaml":                                       k8sAddonsGmsaWebhookYaml,
+	"k8s/addons/hyperv-runtimeclass.yaml":                                k8sAddonsHypervRuntimeclassYaml,
 	"k8s/addons/ip-masq-agent.yaml":                                      k8sAddonsIpMasqAgentYaml,
 	"k8s/addons/keyvault-flexvolume.yaml":                                k8sAddonsKeyvaultFlexvolumeYaml,
 	"k8s/addons/kube-dns.yaml":                                           k8sAddonsKubeDnsYaml,
@@ -24901,6 +25442,7 @@ var _bindata = map[string]func() (*asset, error){
 	"k8s/cloud-init/jumpboxcustomdata.yml":                               k8sCloudInitJumpboxcustomdataYml,
 	"k8s/cloud-init/masternodecustomdata.yml":                            k8sCloudInitMasternodecustomdataYml,
 	"k8s/cloud-init/nodecustomdata.yml":                                  k8sCloudInitNodecustomdataYml,
+	"k8s/collect-logs-flatcar.sh":                                        k8sCollectLogsFlatcarSh,
 	"k8s/containerdtemplate.toml":                                        k8sContainerdtemplateToml,
 	"k8s/kubeconfig.json":                                                k8sKubeconfigJson,
 	"k8s/kubernetesparams.t":                                             k8sKubernetesparamsT,
@@ -24920,6 +25462,7 @@ var _bindata = map[string]func() (*asset, error){
 	"k8s/windowsconfigfunc.ps1":                                          k8sWindowsconfigfuncPs1,
 	"k8s/windowscontainerdfunc.ps1":                                      k8sWindowscontainerdfuncPs1,
 	"k8s/windowscsiproxyfunc.ps1":                                        k8sWindowscsiproxyfuncPs1,
+	"k8s/windowsgmsafunc.ps1":                                            k8sWindowsgmsafuncPs1,
 	"k8s/windowshostsconfigagentfunc.ps1":                                k8sWindowshostsconfigagentfuncPs1,
 	"k8s/windowsinstallopensshfunc.ps1":                                  k8sWindowsinstallopensshfuncPs1,
 	"k8s/windowskubeletfunc.ps1":                                         k8sWindowskubeletfuncPs1,
@@ -24994,6 +25537,8 @@ var _bintree = &bintree{nil, map[string]*bintree{
 			"container-monitoring.yaml":             {k8sAddonsContainerMonitoringYaml, map[string]*bintree{}},
 			"coredns.yaml":                          {k8sAddonsCorednsYaml, map[string]*bintree{}},
 			"flannel.yaml":                          {k8sAddonsFlannelYaml, map[string]*bintree{}},
+			"gmsa-webhook.yaml":                     {k8sAddonsGmsaWebhookYaml, map[string]*bintree{}},
+			"hyperv-runtimeclass.yaml":              {k8sAddonsHypervRuntimeclassYaml, map[string]*bintree{}},
 			"ip-masq-agent.yaml":                    {k8sAddonsIpMasqAgentYaml, map[string]*bintree{}},
 			"keyvault-flexvolume.yaml":              {k8sAddonsKeyvaultFlexvolumeYaml, map[string]*bintree{}},
 			"kube-dns.yaml":                         {k8sAddonsKubeDnsYaml, map[string]*bintree{}},
@@ -25057,6 +25602,7 @@ var _bintree = &bintree{nil, map[string]*bintree{
 			"masternodecustomdata.yml": {k8sCloudInitMasternodecustomdataYml, map[string]*bintree{}},
 			"nodecustomdata.yml":       {k8sCloudInitNodecustomdataYml, map[string]*bintree{}},
 		}},
+		"collect-logs-flatcar.sh":        {k8sCollectLogsFlatcarSh, map[string]*bintree{}},
 		"containerdtemplate.toml":        {k8sContainerdtemplateToml, map[string]*bintree{}},
 		"kubeconfig.json":                {k8sKubeconfigJson, map[string]*bintree{}},
 		"kubernetesparams.t":             {k8sKubernetesparamsT, map[string]*bintree{}},
@@ -25078,6 +25624,7 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"windowsconfigfunc.ps1":           {k8sWindowsconfigfuncPs1, map[string]*bintree{}},
 		"windowscontainerdfunc.ps1":       {k8sWindowscontainerdfuncPs1, map[string]*bintree{}},
 		"windowscsiproxyfunc.ps1":         {k8sWindowscsiproxyfuncPs1, map[string]*bintree{}},
+		"windowsgmsafunc.ps1":             {k8sWindowsgmsafuncPs1, map[string]*bintree{}},
 		"windowshostsconfigagentfunc.ps1": {k8sWindowshostsconfigagentfuncPs1, map[string]*bintree{}},
 		"windowsinstallopensshfunc.ps1":   {k8sWindowsinstallopensshfuncPs1, map[string]*bintree{}},
 		"windowskubeletfunc.ps1":          {k8sWindowskubeletfuncPs1, map[string]*bintree{}},
@@ -52,12 +52,17 @@ const (
 	publicIPAddressResourceType      = "Microsoft.Network/publicIPAddresses"
 	storageAccountsResourceType      = "Microsoft.Storage/storageAccounts"
 	userAssignedIdentityResourceType = "Microsoft.ManagedIdentity/userAssignedIdentities"
+	natGatewayResourceType           = "Microsoft.Network/natGateways"
+	publicIPPrefixResourceType       = "Microsoft.Network/publicIPPrefixes"
 
 	// resource ids
 	nsgID     = "nsgID"
 	rtID      = "routeTableID"
 	vnetID    = "vnetID"
 	agentLbID = "agentLbID"
+
+	// agent pool network security groups are named after the pool, e.g. [variables('agentpool1NetworkSecurityGroupName')]
+	agentPoolNSGNameSuffix = "NetworkSecurityGroupName')"
 )
 
 // Translator defines all required interfaces for i18n.Translator.
@@ -259,6 +264,7 @@ func (t *Transformer) NormalizeForK8sVMASScalingUp(logger *logrus.Entry, templat
 	nsgIndex := -1
 	vnetIndex := -1
 	vmasIndexes := make([]int, 0)
+	natGatewayIndexes := make([]int, 0)
 
 	resources := templateMap[resourcesFieldName].([]interface{})
 	for index, resource := range resources {
@@ -271,7 +277,7 @@ func (t *Transformer) NormalizeForK8sVMASScalingUp(logger *logrus.Entry, templat
 		resourceType, ok := resourceMap[typeFieldName].(string)
 		resourceName := resourceMap[nameFieldName].(string)
 
-		if ok && resourceType == nsgResourceType && !strings.Contains(resourceName, "variables('jumpboxNetworkSecurityGroupName')") {
+		if ok && resourceType == nsgResourceType && !strings.Contains(resourceName, "variables('jumpboxNetworkSecurityGroupName')") && !isAgentPoolNSG(resourceName) {
 
 			if nsgIndex != -1 {
 				err := t.Translator.Errorf("Found 2 resources with type %s in the template. There should only be 1", nsgResourceType)
@@ -300,6 +306,10 @@ func (t *Transformer) NormalizeForK8sVMASScalingUp(logger *logrus.Entry, templat
 			// All availability sets can be removed
 			vmasIndexes = append(vmasIndexes, index)
 		}
+		if ok && (resourceType == natGatewayResourceType || resourceType == publicIPPrefixResourceType) {
+			// the NAT gateway and its public IP prefixes are left as they are, like the virtual network
+			natGatewayIndexes = append(natGatewayIndexes, index)
+		}
 
 		dependencies, ok := resourceMap[dependsOnFieldName].([]interface{})
 		if !ok {
@@ -311,7 +321,8 @@ func (t *Transformer) NormalizeForK8sVMASScalingUp(logger *logrus.Entry, templat
 			if strings.Contains(dependency, nsgResourceType) || strings.Contains(dependency, nsgID) ||
 				strings.Contains(dependency, rtResourceType) || strings.Contains(dependency, rtID) ||
 				strings.Contains(dependency, vnetResourceType) || strings.Contains(dependency, vnetID) ||
-				strings.Contains(dependency, vmasResourceType) {
+				strings.Contains(dependency, vmasResourceType) ||
+				strings.Contains(dependency, natGatewayResourceType) || strings.Contains(dependency, publicIPPrefixResourceType) {
 				dependencies = append(dependencies[:dIndex], dependencies[dIndex+1:]...)
 			}
 		}
@@ -338,6 +349,9 @@ func (t *Transformer) NormalizeForK8sVMASScalingUp(logger *logrus.Entry, templat
 	if len(vmasIndexes) != 0 {
 		indexesToRemove = append(indexesToRemove, vmasIndexes...)
 	}
+	if len(natGatewayIndexes) != 0 {
+		indexesToRemove = append(indexesToRemove, natGatewayIndexes...)
+	}
 	if nsgIndex > 0 {
 		indexesToRemove = append(indexesToRemove, nsgIndex)
 	}
@@ -439,7 +453,8 @@ func (t *Transformer) NormalizeResourcesForK8sMasterUpgrade(logger *logrus.Entry
 		vmssResourceType: true, vmasResourceType: true, roleResourceType: true,
 		publicIPAddressResourceType: true, storageAccountsResourceType: true,
 		keyVaultResourceType: true, rtResourceType: true,
-		userAssignedIdentityResourceType: true}
+		userAssignedIdentityResourceType: true, natGatewayResourceType: true,
+		publicIPPrefixResourceType: true}
 	logger.Infoln(fmt.Sprintf("Resource count before running NormalizeResourcesForK8sMasterUpgrade: %d", len(resources)))
 
 	filteredResources := resources[:0]
@@ -485,7 +500,8 @@ func (t *Transformer) NormalizeResourcesForK8sMasterUpgrade(logger *logrus.Entry
 				filteredResources = filteredResources[:len(filteredResources)-1]
 				continue
 			}
-		case publicIPAddressResourceType, rtResourceType, userAssignedIdentityResourceType:
+		case publicIPAddressResourceType, rtResourceType, userAssignedIdentityResourceType,
+			natGatewayResourceType, publicIPPrefixResourceType:
 			filteredResources = filteredResources[:len(filteredResources)-1]
 			continue
 		case lbResourceType:
@@ -760,6 +776,32 @@ func (t *Transformer) NormalizeResourcesForK8sAgentUpgrade(logger *logrus.Entry,
 	return nil
 }
 
+// NormalizeForK8sSubnetUpdate takes a template and keeps only the virtual network aks-engine creates for the cluster,
+// deploying it updates the subnets of the cluster without changing its other resources
+func (t *Transformer) NormalizeForK8sSubnetUpdate(logger *logrus.Entry, templateMap map[string]interface{}) error {
+	resources := templateMap[resourcesFieldName].([]interface{})
+	var vnets []interface{}
+	for _, resource := range resources {
+		resourceMap, ok := resource.(map[string]interface{})
+		if !ok {
+			logger.Warnf("Template improperly formatted for resource")
+			continue
+		}
+		resourceType, _ := resourceMap[typeFieldName].(string)
+		resourceName, _ := resourceMap[nameFieldName].(string)
+		if resourceType == vnetResourceType && strings.Contains(resourceName, "variables('virtualNetworkName')") {
+			delete(resourceMap, dependsOnFieldName)
+			vnets = append(vnets, resource)
+		}
+	}
+	if len(vnets) != 1 {
+		return t.Translator.Errorf("Found %d resources with type %s in the template. There should only be 1", len(vnets), vnetResourceType)
+	}
+	templateMap[resourcesFieldName] = vnets
+	delete(templateMap, outputsFieldName)
+	return nil
+}
+
 // NormalizeForK8sAddVMASPool takes a template and removes elements that are unwanted in a K8s VMAS add pool case
 func (t *Transformer) NormalizeForK8sAddVMASPool(l *logrus.Entry, templateMap map[string]interface{}) error {
 	t.RemoveImmutableResourceProperties(l, templateMap)
@@ -795,7 +837,9 @@ func removeSingleOfType(logger *logrus.Entry, templateMap map[string]interface{}
 		}
 
 		resourceType, found := resource[typeFieldName].(string)
-		if found && resourceType == typeToRemove {
+		resourceName, _ := resource[nameFieldName].(string)
+		// agent pool network security groups hold pool specific rules and are kept
+		if found && resourceType == typeToRemove && !(resourceType == nsgResourceType && isAgentPoolNSG(resourceName)) {
 			if indexToRemove != -1 {
 				err := errors.Errorf("Found at least 2 resources of type %s in the template but only 1 is expected", vnetResourceType)
 				logger.Warnf(err.Error())
@@ -830,6 +874,11 @@ func removeSingleOfType(logger *logrus.Entry, templateMap map[string]interface{}
 	return nil
 }
 
+// isAgentPoolNSG returns true if resourceName is the name of a network security group dedicated to an agent pool
+func isAgentPoolNSG(resourceName string) bool {
+	return strings.Contains(resourceName, agentPoolNSGNameSuffix) && !strings.Contains(resourceName, "variables('jumpbox")
+}
+
 func containsResourceID(dep, typeToRemove string) bool {
 	switch typeToRemove {
 	case nsgResourceType:
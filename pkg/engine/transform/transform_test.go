@@ -388,3 +388,127 @@ func TestRemoveSingleOfType(t *testing.T) {
 		})
 	}
 }
+
+func TestAgentPoolNSGIsKeptForScaling(t *testing.T) {
+	g := NewGomegaWithT(t)
+	l := logrus.New().WithField("testName", "TestAgentPoolNSGIsKeptForScaling")
+
+	newTemplate := func() map[string]interface{} {
+		return map[string]interface{}{
+			resourcesFieldName: []interface{}{
+				map[string]interface{}{
+					nameFieldName: "[variables('pool1NetworkSecurityGroupName')]",
+					typeFieldName: nsgResourceType,
+				},
+				map[string]interface{}{
+					nameFieldName: "[variables('nsgName')]",
+					typeFieldName: nsgResourceType,
+				},
+				map[string]interface{}{
+					nameFieldName:      "[variables('pool1VMNamePrefix')]",
+					typeFieldName:      vmssResourceType,
+					dependsOnFieldName: []interface{}{"[variables('nsgID')]", "[variables('pool1NetworkSecurityGroupID')]"},
+				},
+			},
+		}
+	}
+
+	assertPoolNSGKept := func(template map[string]interface{}) {
+		resources := template[resourcesFieldName].([]interface{})
+		g.Expect(resources).To(HaveLen(2))
+		nsg := resources[0].(map[string]interface{})
+		g.Expect(nsg[nameFieldName]).To(Equal("[variables('pool1NetworkSecurityGroupName')]"))
+		vmss := resources[1].(map[string]interface{})
+		g.Expect(vmss[dependsOnFieldName]).To(Equal([]interface{}{"[variables('pool1NetworkSecurityGroupID')]"}))
+	}
+
+	template := newTemplate()
+	g.Expect(removeSingleOfType(l, template, nsgResourceType)).To(Succeed())
+	assertPoolNSGKept(template)
+
+	template = newTemplate()
+	transformer := Transformer{Translator: &i18n.Translator{}}
+	g.Expect(transformer.NormalizeForK8sVMASScalingUp(l, template)).To(Succeed())
+	assertPoolNSGKept(template)
+}
+
+func TestNATGatewayIsRemovedForScalingAndUpgrade(t *testing.T) {
+	g := NewGomegaWithT(t)
+	l := logrus.New().WithField("testName", "TestNATGatewayIsRemovedForScalingAndUpgrade")
+
+	newTemplate := func() map[string]interface{} {
+		return map[string]interface{}{
+			resourcesFieldName: []interface{}{
+				map[string]interface{}{
+					nameFieldName: "[concat(variables('natGatewayPublicIPPrefixName'), '-0')]",
+					typeFieldName: publicIPPrefixResourceType,
+				},
+				map[string]interface{}{
+					nameFieldName:      "[variables('natGatewayName')]",
+					typeFieldName:      natGatewayResourceType,
+					dependsOnFieldName: []interface{}{"[resourceId('Microsoft.Network/publicIPPrefixes', concat(variables('natGatewayPublicIPPrefixName'), '-0'))]"},
+				},
+				map[string]interface{}{
+					nameFieldName:      "[variables('agentpool1VMNamePrefix')]",
+					typeFieldName:      vmssResourceType,
+					dependsOnFieldName: []interface{}{"[concat('Microsoft.Network/natGateways/', variables('natGatewayName'))]"},
+				},
+				map[string]interface{}{
+					nameFieldName: "[concat(variables('masterVMNamePrefix'), 0)]",
+					typeFieldName: vmResourceType,
+				},
+			},
+		}
+	}
+
+	template := newTemplate()
+	transformer := Transformer{Translator: &i18n.Translator{}}
+	g.Expect(transformer.NormalizeForK8sVMASScalingUp(l, template)).To(Succeed())
+	resources := template[resourcesFieldName].([]interface{})
+	g.Expect(resources).To(HaveLen(1))
+	vmss := resources[0].(map[string]interface{})
+	g.Expect(vmss[typeFieldName]).To(Equal(vmssResourceType))
+	g.Expect(vmss).NotTo(HaveKey(dependsOnFieldName))
+
+	template = newTemplate()
+	g.Expect(transformer.NormalizeResourcesForK8sMasterUpgrade(l, template, true, nil)).To(Succeed())
+	resources = template[resourcesFieldName].([]interface{})
+	g.Expect(resources).To(HaveLen(1))
+	g.Expect(resources[0].(map[string]interface{})[typeFieldName]).To(Equal(vmResourceType))
+}
+
+func TestNormalizeForK8sSubnetUpdate(t *testing.T) {
+	g := NewGomegaWithT(t)
+	l := logrus.New().WithField("testName", "TestNormalizeForK8sSubnetUpdate")
+	transformer := Transformer{Translator: &i18n.Translator{}}
+
+	template := map[string]interface{}{
+		resourcesFieldName: []interface{}{
+			map[string]interface{}{
+				nameFieldName: "[variables('nsgName')]",
+				typeFieldName: nsgResourceType,
+			},
+			map[string]interface{}{
+				nameFieldName:      "[variables('virtualNetworkName')]",
+				typeFieldName:      vnetResourceType,
+				dependsOnFieldName: []interface{}{"[concat('Microsoft.Network/networkSecurityGroups/', variables('nsgName'))]"},
+			},
+			map[string]interface{}{
+				nameFieldName: "[variables('pool1VMNamePrefix')]",
+				typeFieldName: vmssResourceType,
+			},
+		},
+		outputsFieldName: map[string]interface{}{},
+	}
+	g.Expect(transformer.NormalizeForK8sSubnetUpdate(l, template)).To(Succeed())
+	g.Expect(template[resourcesFieldName]).To(Equal([]interface{}{
+		map[string]interface{}{
+			nameFieldName: "[variables('virtualNetworkName')]",
+			typeFieldName: vnetResourceType,
+		},
+	}))
+	g.Expect(template).NotTo(HaveKey(outputsFieldName))
+
+	template = map[string]interface{}{resourcesFieldName: []interface{}{}}
+	g.Expect(transformer.NormalizeForK8sSubnetUpdate(l, template)).To(MatchError("Found 0 resources with type Microsoft.Network/virtualNetworks in the template. There should only be 1"))
+}
@@ -1052,3 +1052,36 @@ func TestCreateCustomOSVMSS(t *testing.T) {
 		t.Errorf("unexpected diff while expecting equal agent VMSS structs: %s", diff)
 	}
 }
+
+func TestCreateAgentVMSSNetworkSecurityGroup(t *testing.T) {
+	newContainerService := func() *api.ContainerService {
+		cs := api.CreateMockContainerService("testcluster", "", 1, 1, false)
+		cs.Properties.AgentPoolProfiles = []*api.AgentPoolProfile{
+			{Name: "pool1", Count: 1, VMSize: "Standard_D2_v2", AvailabilityProfile: api.VirtualMachineScaleSets, OSType: api.Linux},
+			{Name: "pool2", Count: 1, VMSize: "Standard_D2_v2", AvailabilityProfile: api.VirtualMachineScaleSets, OSType: api.Linux},
+		}
+		return cs
+	}
+	nsgID := func(vmss VirtualMachineScaleSetARM) string {
+		nic := (*vmss.VirtualMachineProfile.NetworkProfile.NetworkInterfaceConfigurations)[0]
+		if nic.NetworkSecurityGroup == nil {
+			return ""
+		}
+		return to.String(nic.NetworkSecurityGroup.ID)
+	}
+
+	// the cluster network security group is only on the subnet
+	cs := newContainerService()
+	if actual := nsgID(CreateAgentVMSS(cs, cs.Properties.AgentPoolProfiles[0])); actual != "" {
+		t.Errorf("expected no network security group on the network interfaces, got %s", actual)
+	}
+
+	cs = newContainerService()
+	cs.Properties.AgentPoolProfiles[1].NetworkSecurityRules = []api.NetworkSecurityRule{{Name: "allow_nodeports"}}
+	if actual := nsgID(CreateAgentVMSS(cs, cs.Properties.AgentPoolProfiles[1])); actual != "[variables('pool2NetworkSecurityGroupID')]" {
+		t.Errorf("expected the pool network security group on the network interfaces of the pool, got %s", actual)
+	}
+	if actual := nsgID(CreateAgentVMSS(cs, cs.Properties.AgentPoolProfiles[0])); actual != "[variables('nsgID')]" {
+		t.Errorf("expected the cluster network security group on the network interfaces of the other pools, got %s", actual)
+	}
+}
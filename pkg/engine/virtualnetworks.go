@@ -16,8 +16,10 @@ func CreateVirtualNetwork(cs *api.ContainerService) VirtualNetworkARM {
 		"[concat('Microsoft.Network/networkSecurityGroups/', variables('nsgName'))]",
 	}
 
-	requireRouteTable := cs.Properties.RequireRouteTable()
-	if requireRouteTable {
+	// with userDefinedRouting the route table is provided by the user and only attached to the subnets
+	userDefinedRouting := cs.Properties.IsUserDefinedRouting()
+	requireRouteTable := cs.Properties.RequireRouteTable() || userDefinedRouting
+	if requireRouteTable && !userDefinedRouting {
 		dependencies = append(dependencies, "[concat('Microsoft.Network/routeTables/', variables('routeTableName'))]")
 	}
 
@@ -30,12 +32,17 @@ func CreateVirtualNetwork(cs *api.ContainerService) VirtualNetworkARM {
 		Name: to.StringPtr("[variables('subnetName')]"),
 		SubnetPropertiesFormat: &network.SubnetPropertiesFormat{
 			AddressPrefix: to.StringPtr("[parameters('masterSubnet')]"),
-			NetworkSecurityGroup: &network.SecurityGroup{
-				ID: to.StringPtr("[variables('nsgID')]"),
-			},
 		},
 	}
 
+	// the pool rules could not allow traffic that the cluster rules block on a shared subnet,
+	// so with agent pool network security groups the cluster one is attached to network interfaces instead
+	if !cs.Properties.HasAgentPoolNetworkSecurityGroups() {
+		subnet.NetworkSecurityGroup = &network.SecurityGroup{
+			ID: to.StringPtr("[variables('nsgID')]"),
+		}
+	}
+
 	masterAddressPrefixes := []string{"[parameters('masterSubnet')]"}
 	// add ipv6 vnet cidr if dual stack enabled
 	if cs.Properties.FeatureFlags.IsFeatureEnabled("EnableIPv6DualStack") ||
@@ -96,8 +103,10 @@ func createVirtualNetworkVMSS(cs *api.ContainerService) VirtualNetworkARM {
 		"[concat('Microsoft.Network/networkSecurityGroups/', variables('nsgName'))]",
 	}
 
-	requireRouteTable := cs.Properties.RequireRouteTable()
-	if requireRouteTable {
+	// with userDefinedRouting the route table is provided by the user and only attached to the subnets
+	userDefinedRouting := cs.Properties.IsUserDefinedRouting()
+	requireRouteTable := cs.Properties.RequireRouteTable() || userDefinedRouting
+	if requireRouteTable && !userDefinedRouting {
 		dependencies = append(dependencies, "[concat('Microsoft.Network/routeTables/', variables('routeTableName'))]")
 	}
 
@@ -110,9 +119,6 @@ func createVirtualNetworkVMSS(cs *api.ContainerService) VirtualNetworkARM {
 		Name: to.StringPtr("subnetmaster"),
 		SubnetPropertiesFormat: &network.SubnetPropertiesFormat{
 			AddressPrefix: to.StringPtr("[parameters('masterSubnet')]"),
-			NetworkSecurityGroup: &network.SecurityGroup{
-				ID: to.StringPtr("[variables('nsgID')]"),
-			},
 		},
 	}
 	masterAddressPrefixes := []string{"[parameters('masterSubnet')]"}
@@ -134,9 +140,6 @@ func createVirtualNetworkVMSS(cs *api.ContainerService) VirtualNetworkARM {
 		Name: to.StringPtr("subnetagent"),
 		SubnetPropertiesFormat: &network.SubnetPropertiesFormat{
 			AddressPrefix: to.StringPtr("[parameters('agentSubnet')]"),
-			NetworkSecurityGroup: &network.SecurityGroup{
-				ID: to.StringPtr("[variables('nsgID')]"),
-			},
 		},
 	}
 
@@ -146,6 +149,14 @@ func createVirtualNetworkVMSS(cs *api.ContainerService) VirtualNetworkARM {
 		}
 	}
 
+	if !cs.Properties.HasAgentPoolNetworkSecurityGroups() {
+		nsg := &network.SecurityGroup{
+			ID: to.StringPtr("[variables('nsgID')]"),
+		}
+		subnetMaster.NetworkSecurityGroup = nsg
+		subnetAgent.NetworkSecurityGroup = nsg
+	}
+
 	addressPrefixes := []string{"[parameters('vnetCidr')]"}
 	// add ipv6 vnet cidr if dual stack enabled
 	if cs.Properties.FeatureFlags.IsFeatureEnabled("EnableIPv6DualStack") ||
@@ -552,3 +552,80 @@ func TestCreateVirtualNetworkVMSS(t *testing.T) {
 		t.Errorf("Unexpected diff while comparing vnets: %s", diff)
 	}
 }
+
+func TestCreateVirtualNetworkUserDefinedRouting(t *testing.T) {
+	cs := &api.ContainerService{
+		Properties: &api.Properties{
+			OrchestratorProfile: &api.OrchestratorProfile{
+				OrchestratorType: "Kubernetes",
+				KubernetesConfig: &api.KubernetesConfig{
+					NetworkPlugin: "azure",
+					OutboundType:  api.OutboundTypeUserDefinedRouting,
+					RouteTableID:  "/subscriptions/SUB_ID/resourceGroups/RG_NAME/providers/Microsoft.Network/routeTables/firewall-rt",
+				},
+			},
+		},
+	}
+	expectedDependencies := []string{
+		"[concat('Microsoft.Network/networkSecurityGroups/', variables('nsgName'))]",
+	}
+
+	for _, vnet := range []VirtualNetworkARM{CreateVirtualNetwork(cs), createVirtualNetworkVMSS(cs)} {
+		if diff := cmp.Diff(vnet.DependsOn, expectedDependencies); diff != "" {
+			t.Errorf("unexpected vnet dependencies: %s", diff)
+		}
+		for _, subnet := range *vnet.Subnets {
+			if subnet.RouteTable == nil || to.String(subnet.RouteTable.ID) != "[variables('routeTableID')]" {
+				t.Errorf("expected subnet %s to use the user-provided route table", to.String(subnet.Name))
+			}
+		}
+	}
+}
+
+func TestCreateVirtualNetworkWithAgentPoolNetworkSecurityGroups(t *testing.T) {
+	cs := &api.ContainerService{
+		Properties: &api.Properties{
+			OrchestratorProfile: &api.OrchestratorProfile{
+				OrchestratorType: "Kubernetes",
+				KubernetesConfig: &api.KubernetesConfig{},
+			},
+			MasterProfile: &api.MasterProfile{},
+			AgentPoolProfiles: []*api.AgentPoolProfile{
+				{Name: "pool1"},
+				{
+					Name: "pool2",
+					NetworkSecurityRules: []api.NetworkSecurityRule{
+						{Name: "allow_nodeports", Priority: 200, Direction: "Inbound", Access: "Allow", Protocol: "Tcp"},
+					},
+				},
+			},
+		},
+	}
+
+	// the cluster network security group moves from the subnets to the network interfaces
+	for _, vnet := range []VirtualNetworkARM{CreateVirtualNetwork(cs), createVirtualNetworkVMSS(cs)} {
+		for _, subnet := range *vnet.Subnets {
+			if subnet.NetworkSecurityGroup != nil {
+				t.Errorf("expected subnet %s to have no network security group, got %s", to.String(subnet.Name), to.String(subnet.NetworkSecurityGroup.ID))
+			}
+		}
+	}
+	nsgID := to.StringPtr("[variables('nsgID')]")
+	if diff := cmp.Diff(CreateMasterVMNetworkInterfaces(cs).NetworkSecurityGroup, &network.SecurityGroup{ID: nsgID}); diff != "" {
+		t.Errorf("unexpected diff while comparing the master nic network security group: %s", diff)
+	}
+	if diff := cmp.Diff(createAgentVMASNetworkInterface(cs, cs.Properties.AgentPoolProfiles[0]).NetworkSecurityGroup, &network.SecurityGroup{ID: nsgID}); diff != "" {
+		t.Errorf("unexpected diff while comparing the pool1 nic network security group: %s", diff)
+	}
+	if diff := cmp.Diff(createAgentVMASNetworkInterface(cs, cs.Properties.AgentPoolProfiles[1]).NetworkSecurityGroup, &network.SecurityGroup{ID: to.StringPtr("[variables('pool2NetworkSecurityGroupID')]")}); diff != "" {
+		t.Errorf("unexpected diff while comparing the pool2 nic network security group: %s", diff)
+	}
+
+	cs.Properties.AgentPoolProfiles[1].NetworkSecurityRules = nil
+	if diff := cmp.Diff((*CreateVirtualNetwork(cs).Subnets)[0].NetworkSecurityGroup, &network.SecurityGroup{ID: nsgID}); diff != "" {
+		t.Errorf("unexpected diff while comparing the subnet network security group: %s", diff)
+	}
+	if CreateMasterVMNetworkInterfaces(cs).NetworkSecurityGroup != nil {
+		t.Errorf("expected the master nic to have no network security group")
+	}
+}
@@ -4,6 +4,7 @@
 package engine
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/Azure/aks-engine/pkg/api"
@@ -366,6 +367,29 @@ func TestCreateAgentVMASCustomScriptExtension(t *testing.T) {
 	}
 }
 
+func TestCreateAgentVMASCustomScriptExtensionArm64(t *testing.T) {
+	cs := api.CreateMockContainerService("testcluster", "1.24.0", 1, 1, false)
+	cs.Properties.FeatureFlags = &api.FeatureFlags{}
+
+	profile := &api.AgentPoolProfile{Name: "amd", OSType: api.Linux, Distro: api.Ubuntu2204}
+	cse := createAgentVMASCustomScriptExtension(cs, profile)
+	commandExec := (*cse.VirtualMachineExtension.ProtectedSettings.(*map[string]interface{}))["commandToExecute"].(string)
+	if strings.Contains(commandExec, "arm64") {
+		t.Errorf("expected the commandToExecute of an amd64 pool not to reference arm64 binaries, got %s", commandExec)
+	}
+
+	profile = &api.AgentPoolProfile{Name: "arm", OSType: api.Linux, Distro: api.Ubuntu2204, Architecture: api.ArchitectureArm64}
+	cse = createAgentVMASCustomScriptExtension(cs, profile)
+	commandExec = (*cse.VirtualMachineExtension.ProtectedSettings.(*map[string]interface{}))["commandToExecute"].(string)
+	expected := "USER_ASSIGNED_IDENTITY_ID=',' ',' CNI_PLUGINS_URL="
+	if !strings.Contains(commandExec, expected) {
+		t.Errorf("expected the commandToExecute of an arm64 pool to contain %s, got %s", expected, commandExec)
+	}
+	if !strings.Contains(commandExec, "KUBE_BINARY_URL=https://kubernetesartifacts.azureedge.net/kubernetes/v1.24.0/binaries/kubernetes-node-linux-arm64.tar.gz") {
+		t.Errorf("expected the commandToExecute of an arm64 pool to override KUBE_BINARY_URL, got %s", commandExec)
+	}
+}
+
 func TestCreateCustomExtensions(t *testing.T) {
 	properties := &api.Properties{
 		OrchestratorProfile: &api.OrchestratorProfile{
@@ -10,6 +10,17 @@ import (
 	"github.com/Azure/aks-engine/pkg/api/common"
 )
 
+const (
+	// HyperVGenerationV1 is the Hyper-V generation of the VMs booting with BIOS
+	HyperVGenerationV1 = "V1"
+	// HyperVGenerationV2 is the Hyper-V generation of the VMs booting with UEFI
+	HyperVGenerationV2 = "V2"
+	// CPUArchitectureTypeX64 is the CPU architecture of the Intel and AMD VM sizes
+	CPUArchitectureTypeX64 = "x64"
+	// CPUArchitectureTypeArm64 is the CPU architecture of the Ampere Altra VM sizes
+	CPUArchitectureTypeArm64 = "Arm64"
+)
+
 // GetKubernetesAllowedVMSKUs returns the allowed sizes for Kubernetes agent
 func GetKubernetesAllowedVMSKUs() string {
 	var b strings.Builder
@@ -55,3 +66,40 @@ func AcceleratedNetworkingSupported(sku string) bool {
 	}
 	return false
 }
+
+// NestedVirtualizationSupported checks if the VM SKU supports nested virtualization, which Hyper-V isolated containers require.
+func NestedVirtualizationSupported(sku string) bool {
+	name := strings.TrimSuffix(sku, "_Promo")
+	for _, sku := range VMSkus {
+		if name == sku.Name {
+			return sku.NestedVirtualization
+		}
+	}
+	return false
+}
+
+// GetVMSku returns the capabilities of a VM SKU, and false if the SKU is unknown.
+func GetVMSku(sku string) (VMSku, bool) {
+	name := strings.TrimSuffix(sku, "_Promo")
+	for _, s := range VMSkus {
+		if name == s.Name {
+			return s, true
+		}
+	}
+	return VMSku{}, false
+}
+
+// SupportsHyperVGeneration checks if the VM SKU can boot images of the given Hyper-V generation.
+func (s VMSku) SupportsHyperVGeneration(generation string) bool {
+	for _, g := range strings.Split(s.HyperVGenerations, ",") {
+		if strings.EqualFold(strings.TrimSpace(g), generation) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsArm64 checks if the VM SKU has an Arm64 CPU.
+func (s VMSku) IsArm64() bool {
+	return strings.EqualFold(s.CPUArchitectureType, CPUArchitectureTypeArm64)
+}
@@ -932,3 +932,36 @@ func TestGetSizeMap(t *testing.T) {
 	}
 
 }
+
+func TestGetVMSku(t *testing.T) {
+	cases := []struct {
+		sku           string
+		expectedFound bool
+		expectedGen1  bool
+		expectedGen2  bool
+		expectedArm64 bool
+	}{
+		{sku: "Standard_D2s_v3", expectedFound: true, expectedGen1: true, expectedGen2: true},
+		{sku: "Standard_D2s_v3_Promo", expectedFound: true, expectedGen1: true, expectedGen2: true},
+		{sku: "Standard_A2", expectedFound: true, expectedGen1: true},
+		{sku: "Standard_M208ms_v2", expectedFound: true, expectedGen2: true},
+		{sku: "Standard_D4ps_v5", expectedFound: true, expectedGen2: true, expectedArm64: true},
+		{sku: "Standard_Unknown"},
+	}
+
+	for _, c := range cases {
+		sku, found := GetVMSku(c.sku)
+		if found != c.expectedFound {
+			t.Errorf("expected GetVMSku(%s) to return found %t, got %t", c.sku, c.expectedFound, found)
+		}
+		if sku.SupportsHyperVGeneration(HyperVGenerationV1) != c.expectedGen1 {
+			t.Errorf("expected %s generation 1 support to be %t", c.sku, c.expectedGen1)
+		}
+		if sku.SupportsHyperVGeneration(HyperVGenerationV2) != c.expectedGen2 {
+			t.Errorf("expected %s generation 2 support to be %t", c.sku, c.expectedGen2)
+		}
+		if sku.IsArm64() != c.expectedArm64 {
+			t.Errorf("expected %s Arm64 to be %t", c.sku, c.expectedArm64)
+		}
+	}
+}
@@ -285,6 +285,49 @@ func TestAcceleratedNetworkingSupported(t *testing.T) {
 	}
 }
 
+func TestNestedVirtualizationSupported(t *testing.T) {
+	cases := []struct {
+		input          string
+		expectedResult bool
+	}{
+		{
+			input:          "Standard_D2_v2",
+			expectedResult: false,
+		},
+		{
+			input:          "Standard_B2s",
+			expectedResult: false,
+		},
+		{
+			input:          "Standard_D4s_v3",
+			expectedResult: true,
+		},
+		{
+			input:          "Standard_E8ds_v4",
+			expectedResult: true,
+		},
+		{
+			input:          "Standard_F8s_v2",
+			expectedResult: true,
+		},
+		{
+			input:          "Standard_D4s_v3_Promo",
+			expectedResult: true,
+		},
+		{
+			input:          "",
+			expectedResult: false,
+		},
+	}
+
+	for _, c := range cases {
+		result := NestedVirtualizationSupported(c.input)
+		if c.expectedResult != result {
+			t.Fatalf("NestedVirtualizationSupported returned unexpected result for %s: expected %t but got %t", c.input, c.expectedResult, result)
+		}
+	}
+}
+
 func TestEqualError(t *testing.T) {
 	testcases := []struct {
 		errA     error
@@ -69,6 +69,34 @@ func CreatePkiKeyCertPair(params PkiKeyCertPairParams) (*PkiKeyCertPair, error)
 	return caPair, nil
 }
 
+// CreateServerPkiKeyCertPair generates a pair of server certificate for dnsNames, signed by caPair, and private key
+func CreateServerPkiKeyCertPair(caPair *PkiKeyCertPair, commonName string, dnsNames []string, pkiKeySize int) (*PkiKeyCertPair, error) {
+	caCertificate, err := pemToCertificate(caPair.CertificatePem)
+	if err != nil {
+		return nil, err
+	}
+	caPrivateKey, err := pemToKey(caPair.PrivateKeyPem)
+	if err != nil {
+		return nil, err
+	}
+	certPram := certParams{
+		commonName:    commonName,
+		caCertificate: caCertificate,
+		caPrivateKey:  caPrivateKey,
+		isEtcd:        false,
+		isServer:      true,
+		extraFQDNs:    dnsNames,
+		extraIPs:      nil,
+		organization:  nil,
+		keySize:       pkiKeySize,
+	}
+	certificate, privateKey, err := createCertificate(certPram)
+	if err != nil {
+		return nil, err
+	}
+	return &PkiKeyCertPair{CertificatePem: string(certificateToPem(certificate.Raw)), PrivateKeyPem: string(privateKeyToPem(privateKey))}, nil
+}
+
 // CreatePki creates PKI certificates
 func CreatePki(pkiParams PkiParams) (*PkiKeyCertPair, *PkiKeyCertPair, *PkiKeyCertPair, *PkiKeyCertPair, *PkiKeyCertPair, []*PkiKeyCertPair, error) {
 	start := time.Now()
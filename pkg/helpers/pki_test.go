@@ -309,3 +309,38 @@ func TestCreatePkiKeyCertPair(t *testing.T) {
 		t.Errorf("unexpected error thrown while executing CreatePkiKeyCertPair : %s", err.Error())
 	}
 }
+
+func TestCreateServerPkiKeyCertPair(t *testing.T) {
+	caPair, err := CreatePkiKeyCertPair(PkiKeyCertPairParams{CommonName: "ca", PkiKeySize: DefaultPkiKeySize})
+	if err != nil {
+		t.Fatalf("unexpected error thrown while executing CreatePkiKeyCertPair : %s", err.Error())
+	}
+	dnsNames := []string{"webhook", "webhook.kube-system.svc"}
+	pair, err := CreateServerPkiKeyCertPair(caPair, "webhook", dnsNames, DefaultPkiKeySize)
+	if err != nil {
+		t.Fatalf("unexpected error thrown while executing CreateServerPkiKeyCertPair : %s", err.Error())
+	}
+	certificate, err := pemToCertificate(pair.CertificatePem)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %s", err)
+	}
+	if _, err := pemToKey(pair.PrivateKeyPem); err != nil {
+		t.Fatalf("failed to parse private key: %s", err)
+	}
+	caCertificate, _ := pemToCertificate(caPair.CertificatePem)
+	if err := certificate.CheckSignatureFrom(caCertificate); err != nil {
+		t.Errorf("expected the certificate to be signed by the CA: %s", err)
+	}
+	for _, name := range dnsNames {
+		if err := certificate.VerifyHostname(name); err != nil {
+			t.Errorf("expected the certificate to be valid for %s: %s", name, err)
+		}
+	}
+	if len(certificate.ExtKeyUsage) != 1 || certificate.ExtKeyUsage[0] != x509.ExtKeyUsageServerAuth {
+		t.Errorf("expected a server certificate, got extended key usages %v", certificate.ExtKeyUsage)
+	}
+
+	if _, err := CreateServerPkiKeyCertPair(&PkiKeyCertPair{CertificatePem: "invalid"}, "webhook", dnsNames, DefaultPkiKeySize); err == nil {
+		t.Errorf("expected an error for an invalid CA")
+	}
+}
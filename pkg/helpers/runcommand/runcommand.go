@@ -0,0 +1,169 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+// Package runcommand runs PowerShell scripts on the cluster Windows nodes through the Azure Run Command API,
+// an alternative to SSH for nodes that do not accept SSH connections.
+package runcommand
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/helpers/ssh"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+)
+
+const (
+	// RunPowerShellScriptCommandID is the ID of the built-in command that runs a PowerShell script on a Windows VM
+	RunPowerShellScriptCommandID = "RunPowerShellScript"
+
+	// CompletedMarker is the last line of the standard output of a script that completes. The Run Command API does not
+	// return the exit status of the script, and PowerShell also writes warnings and progress to the standard error.
+	CompletedMarker = "aks-engine-run-command-completed"
+
+	stdOutStatusCode = "ComponentStatus/StdOut/succeeded"
+	stdErrStatusCode = "ComponentStatus/StdErr/succeeded"
+)
+
+// Client runs PowerShell scripts on the VMs of a resource group, identified by their computer name,
+// which is the name of the Kubernetes node
+type Client struct {
+	client        armhelpers.AKSEngineClient
+	resourceGroup string
+	vms           map[string]vm
+}
+
+type vm struct {
+	name       string
+	vmssName   string
+	instanceID string
+}
+
+// NewClient returns a Client that runs scripts on the VMs of resourceGroup
+func NewClient(client armhelpers.AKSEngineClient, resourceGroup string) *Client {
+	return &Client{client: client, resourceGroup: resourceGroup}
+}
+
+// ExecuteRemote runs script on node and returns its standard output.
+// The script stops on the first error, an error is returned if it does not complete, i.e. if its exit status
+// is not zero. Its standard error is returned in the error, it does not fail the script by itself.
+func (c *Client) ExecuteRemote(ctx context.Context, node string, script ...string) (stdout string, err error) {
+	target, err := c.getVM(ctx, node)
+	if err != nil {
+		return "", err
+	}
+	input := compute.RunCommandInput{
+		CommandID: to.StringPtr(RunPowerShellScriptCommandID),
+		Script:    &[]string{"$ErrorActionPreference = 'Stop'"},
+	}
+	*input.Script = append(*input.Script, script...)
+	*input.Script = append(*input.Script, fmt.Sprintf("Write-Output %s", CompletedMarker))
+	var result compute.RunCommandResult
+	if target.vmssName != "" {
+		result, err = c.client.RunVirtualMachineScaleSetVMCommand(ctx, c.resourceGroup, target.vmssName, target.instanceID, input)
+	} else {
+		result, err = c.client.RunVirtualMachineCommand(ctx, c.resourceGroup, target.name, input)
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "running command on node %s", node)
+	}
+	stdout, stderr := getOutput(result)
+	if !strings.HasSuffix(stdout, CompletedMarker) {
+		return stdout, errors.Errorf("running command on node %s: the script did not complete: %s", node, stderr)
+	}
+	return strings.TrimSpace(strings.TrimSuffix(stdout, CompletedMarker)), nil
+}
+
+// CopyToRemote writes files on node, the paths of the files can reference PowerShell variables such as $env:temp
+func (c *Client) CopyToRemote(ctx context.Context, node string, files ...*ssh.RemoteFile) (stdout string, err error) {
+	return c.ExecuteRemote(ctx, node, WriteFileScript(files...)...)
+}
+
+// WriteFileScript returns the PowerShell statements that write files
+func WriteFileScript(files ...*ssh.RemoteFile) []string {
+	script := make([]string, 0, len(files))
+	for _, f := range files {
+		script = append(script, fmt.Sprintf("[IO.File]::WriteAllBytes(\"%s\", [Convert]::FromBase64String('%s'))",
+			f.Path, base64.StdEncoding.EncodeToString(f.Content)))
+	}
+	return script
+}
+
+// UploadFileScript returns the PowerShell statement that uploads the file at path to the blob sasURL,
+// a SAS URL of an Azure Storage blob
+func UploadFileScript(path string, sasURL *url.URL) string {
+	return fmt.Sprintf("Invoke-WebRequest -UseBasicParsing -Method Put -InFile \"%s\" -Headers @{'x-ms-blob-type'='BlockBlob'; 'x-ms-version'='2019-12-12'} -Uri '%s' | Out-Null",
+		path, strings.ReplaceAll(sasURL.String(), "'", "''"))
+}
+
+// getVM returns the VM, or the VMSS VM, whose computer name is node
+func (c *Client) getVM(ctx context.Context, node string) (vm, error) {
+	if c.vms == nil {
+		if err := c.listVMs(ctx); err != nil {
+			return vm{}, errors.Wrapf(err, "listing the virtual machines of resource group %s", c.resourceGroup)
+		}
+	}
+	target, ok := c.vms[strings.ToLower(node)]
+	if !ok {
+		return vm{}, errors.Errorf("virtual machine of node %s not found in resource group %s", node, c.resourceGroup)
+	}
+	return target, nil
+}
+
+func (c *Client) listVMs(ctx context.Context) error {
+	vms := make(map[string]vm)
+	for page, err := c.client.ListVirtualMachines(ctx, c.resourceGroup); page.NotDone(); err = page.Next() {
+		if err != nil {
+			return err
+		}
+		for _, v := range page.Values() {
+			if v.Name != nil && v.VirtualMachineProperties != nil && v.OsProfile != nil && v.OsProfile.ComputerName != nil {
+				vms[strings.ToLower(*v.OsProfile.ComputerName)] = vm{name: *v.Name}
+			}
+		}
+	}
+	for vmssPage, err := c.client.ListVirtualMachineScaleSets(ctx, c.resourceGroup); vmssPage.NotDone(); err = vmssPage.NextWithContext(ctx) {
+		if err != nil {
+			return err
+		}
+		for _, vmss := range vmssPage.Values() {
+			for page, err := c.client.ListVirtualMachineScaleSetVMs(ctx, c.resourceGroup, *vmss.Name); page.NotDone(); err = page.NextWithContext(ctx) {
+				if err != nil {
+					return err
+				}
+				for _, v := range page.Values() {
+					if v.InstanceID != nil && v.VirtualMachineScaleSetVMProperties != nil && v.OsProfile != nil && v.OsProfile.ComputerName != nil {
+						vms[strings.ToLower(*v.OsProfile.ComputerName)] = vm{vmssName: *vmss.Name, instanceID: *v.InstanceID}
+					}
+				}
+			}
+		}
+	}
+	c.vms = vms
+	return nil
+}
+
+// getOutput returns the standard output and the standard error of a run command
+func getOutput(result compute.RunCommandResult) (stdout, stderr string) {
+	if result.Value == nil {
+		return "", ""
+	}
+	for _, status := range *result.Value {
+		if status.Code == nil || status.Message == nil {
+			continue
+		}
+		switch *status.Code {
+		case stdOutStatusCode:
+			stdout = strings.TrimSpace(*status.Message)
+		case stdErrStatusCode:
+			stderr = strings.TrimSpace(*status.Message)
+		}
+	}
+	return stdout, stderr
+}
@@ -0,0 +1,116 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package runcommand
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/helpers/ssh"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func getMockClient(stdout, stderr string, scripts *[][]string) *armhelpers.MockAKSEngineClient {
+	return &armhelpers.MockAKSEngineClient{
+		FakeListVirtualMachineResult: func() []compute.VirtualMachine {
+			return []compute.VirtualMachine{{
+				Name: to.StringPtr("2753k8s010"),
+				VirtualMachineProperties: &compute.VirtualMachineProperties{
+					OsProfile: &compute.OSProfile{ComputerName: to.StringPtr("2753k8s010")},
+				},
+			}}
+		},
+		FakeListVirtualMachineScaleSetsResult: func() []compute.VirtualMachineScaleSet {
+			return []compute.VirtualMachineScaleSet{{Name: to.StringPtr("akswin")}}
+		},
+		FakeListVirtualMachineScaleSetVMsResult: func() []compute.VirtualMachineScaleSetVM {
+			return []compute.VirtualMachineScaleSetVM{{
+				InstanceID: to.StringPtr("2"),
+				VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
+					OsProfile: &compute.OSProfile{ComputerName: to.StringPtr("akswin000002")},
+				},
+			}}
+		},
+		FakeRunCommandResult: func(input compute.RunCommandInput) compute.RunCommandResult {
+			*scripts = append(*scripts, *input.Script)
+			return compute.RunCommandResult{
+				Value: &[]compute.InstanceViewStatus{
+					{Code: to.StringPtr(stdOutStatusCode), Message: to.StringPtr(stdout)},
+					{Code: to.StringPtr(stdErrStatusCode), Message: to.StringPtr(stderr)},
+				},
+			}
+		},
+	}
+}
+
+func TestExecuteRemote(t *testing.T) {
+	var scripts [][]string
+	c := NewClient(getMockClient("done\n"+CompletedMarker+"\n", "", &scripts), "rg")
+
+	for _, node := range []string{"2753k8s010", "AKSWIN000002"} {
+		stdout, err := c.ExecuteRemote(context.Background(), node, "Write-Output done")
+		if err != nil {
+			t.Fatalf("unexpected error running a command on %s: %s", node, err)
+		}
+		if stdout != "done" {
+			t.Errorf("expected the standard output of the command, got %q", stdout)
+		}
+	}
+	if len(scripts) != 2 || scripts[0][0] != "$ErrorActionPreference = 'Stop'" || scripts[0][1] != "Write-Output done" ||
+		scripts[0][2] != "Write-Output aks-engine-run-command-completed" {
+		t.Errorf("unexpected scripts %v", scripts)
+	}
+
+	if _, err := c.ExecuteRemote(context.Background(), "k8s-agentpool1-12345678-0", "Write-Output done"); err == nil || !strings.Contains(err.Error(), "not found in resource group rg") {
+		t.Errorf("expected an error for a node without VM, got %v", err)
+	}
+
+	c = NewClient(getMockClient("", "Assert-FileExists : c:\\k\\ca.crt not found", &scripts), "rg")
+	if _, err := c.ExecuteRemote(context.Background(), "2753k8s010", "Start-CertRotation"); err == nil || !strings.Contains(err.Error(), "did not complete: Assert-FileExists : c:\\k\\ca.crt not found") {
+		t.Errorf("expected the standard error in the error of a script that does not complete, got %v", err)
+	}
+
+	c = NewClient(getMockClient("copied\n"+CompletedMarker, "WARNING: the certificate expires soon", &scripts), "rg")
+	if stdout, err := c.ExecuteRemote(context.Background(), "2753k8s010", "Start-CertRotation"); err != nil || stdout != "copied" {
+		t.Errorf("expected a script that completes and writes to its standard error to succeed, got %q %v", stdout, err)
+	}
+
+	mock := getMockClient("", "", &scripts)
+	mock.FailRunCommand = true
+	if _, err := NewClient(mock, "rg").ExecuteRemote(context.Background(), "2753k8s010", "Write-Output done"); err == nil {
+		t.Errorf("expected an error when the run command fails")
+	}
+}
+
+func TestCopyToRemote(t *testing.T) {
+	var scripts [][]string
+	c := NewClient(getMockClient(CompletedMarker, "", &scripts), "rg")
+	_, err := c.CopyToRemote(context.Background(), "2753k8s010",
+		ssh.NewRemoteFile("$env:temp\\ca.crt", "", "", []byte("ca")),
+		ssh.NewRemoteFile("$env:temp\\client.crt", "", "", []byte("client")))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{
+		"$ErrorActionPreference = 'Stop'",
+		"[IO.File]::WriteAllBytes(\"$env:temp\\ca.crt\", [Convert]::FromBase64String('Y2E='))",
+		"[IO.File]::WriteAllBytes(\"$env:temp\\client.crt\", [Convert]::FromBase64String('Y2xpZW50'))",
+		"Write-Output aks-engine-run-command-completed",
+	}
+	if len(scripts) != 1 || strings.Join(scripts[0], "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected script %v, got %v", expected, scripts)
+	}
+}
+
+func TestUploadFileScript(t *testing.T) {
+	sasURL, _ := url.Parse("https://account.blob.core.windows.net/logs/node.zip?sv=2019-12-12&sig=it's")
+	expected := "Invoke-WebRequest -UseBasicParsing -Method Put -InFile \"$env:temp\\node.zip\" -Headers @{'x-ms-blob-type'='BlockBlob'; 'x-ms-version'='2019-12-12'} -Uri 'https://account.blob.core.windows.net/logs/node.zip?sv=2019-12-12&sig=it''s' | Out-Null"
+	if actual := UploadFileScript("$env:temp\\node.zip", sasURL); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
@@ -454,6 +454,132 @@ msgstr ""
 "Plural-Forms: nplurals=2; plural=(n != 1);\n"
 "X-Generator: Poedit 2.0.3\n"
 
+#: pkg/engine/cse.go:142
+msgid ""
+"Check that the DNS servers of the virtual network resolve the FQDN of the "
+"API server, including the private DNS records of private clusters."
+msgstr ""
+"Check that the DNS servers of the virtual network resolve the FQDN of the "
+"API server, including the private DNS records of private clusters."
+
+#: pkg/engine/cse.go:147
+msgid ""
+"Check that the VM can reach Azure DNS at 168.63.129.16, or that the custom "
+"DNS servers of the virtual network forward to it."
+msgstr ""
+"Check that the VM can reach Azure DNS at 168.63.129.16, or that the custom "
+"DNS servers of the virtual network forward to it."
+
+#: pkg/engine/cse.go:152
+msgid ""
+"Check that the VM has outbound connectivity to the apt repositories, or use "
+"a VHD image that already contains the packages."
+msgstr ""
+"Check that the VM has outbound connectivity to the apt repositories, or use "
+"a VHD image that already contains the packages."
+
+#: pkg/engine/cse.go:157
+msgid ""
+"Check that the VM has outbound connectivity to the download URLs and image "
+"repositories of the api model, or use a VHD image that already contains the "
+"components."
+msgstr ""
+"Check that the VM has outbound connectivity to the download URLs and image "
+"repositories of the api model, or use a VHD image that already contains the "
+"components."
+
+#: pkg/engine/cse.go:187
+msgid ""
+"Check that the VM size is a supported NVIDIA N-series size, and collect the "
+"logs of the VM with aks-engine get-logs to check the driver installation."
+msgstr ""
+"Check that the VM size is a supported NVIDIA N-series size, and collect the "
+"logs of the VM with aks-engine get-logs to check the driver installation."
+
+#: pkg/engine/cse.go:137
+msgid ""
+"Check that the control plane VMs are running and that the network security "
+"groups and route tables allow traffic from the nodes to the API server on "
+"port 443."
+msgstr ""
+"Check that the control plane VMs are running and that the network security "
+"groups and route tables allow traffic from the nodes to the API server on "
+"port 443."
+
+#: pkg/engine/cse.go:172
+msgid ""
+"Check that the control plane VMs can reach each other on ports 2379 and "
+"2380, and that the etcd data disk is attached."
+msgstr ""
+"Check that the control plane VMs can reach each other on ports 2379 and "
+"2380, and that the etcd data disk is attached."
+
+#: pkg/engine/cse.go:132
+msgid ""
+"Check that the network security groups, route tables, firewalls and proxies "
+"of the subnet allow outbound traffic to the endpoints AKS Engine requires."
+msgstr ""
+"Check that the network security groups, route tables, firewalls and proxies "
+"of the subnet allow outbound traffic to the endpoints AKS Engine requires."
+
+#: pkg/engine/cse.go:192
+msgid ""
+"Check the customSearchDomain realm user and password of the api model, and "
+"that the VM can reach the domain controllers."
+msgstr ""
+"Check the customSearchDomain realm user and password of the api model, and "
+"that the VM can reach the domain controllers."
+
+#: pkg/engine/cse.go:197
+msgid ""
+"Check the service principal credentials of the api model and that the VM "
+"can reach the Azure Stack Hub Resource Manager endpoint."
+msgstr ""
+"Check the service principal credentials of the api model and that the VM "
+"can reach the Azure Stack Hub Resource Manager endpoint."
+
+#: pkg/engine/cse.go:201
+msgid ""
+"Collect the logs of the VM with aks-engine get-logs and check "
+"/var/log/azure/cluster-provision.log."
+msgstr ""
+"Collect the logs of the VM with aks-engine get-logs and check "
+"/var/log/azure/cluster-provision.log."
+
+#: pkg/engine/cse.go:177
+msgid ""
+"Collect the logs of the VM with aks-engine get-logs and check "
+"/var/log/cloud-init.log for errors in the custom data."
+msgstr ""
+"Collect the logs of the VM with aks-engine get-logs and check "
+"/var/log/cloud-init.log for errors in the custom data."
+
+#: pkg/engine/cse.go:112
+msgid ""
+"Collect the logs of the VM with aks-engine get-logs and check "
+"C:\\AzureData\\CustomDataSetupScript.log for the error that stopped the "
+"provisioning script."
+msgstr ""
+"Collect the logs of the VM with aks-engine get-logs and check "
+"C:\\AzureData\\CustomDataSetupScript.log for the error that stopped the "
+"provisioning script."
+
+#: pkg/engine/cse.go:167
+msgid ""
+"Collect the logs of the VM with aks-engine get-logs and check the "
+"kube-apiserver container logs and the etcd service."
+msgstr ""
+"Collect the logs of the VM with aks-engine get-logs and check the "
+"kube-apiserver container logs and the etcd service."
+
+#: pkg/engine/cse.go:162
+msgid ""
+"Collect the logs of the VM with aks-engine get-logs and check the kubelet "
+"journal for configuration errors, e.g. in kubeletConfig."
+msgstr ""
+"Collect the logs of the VM with aks-engine get-logs and check the kubelet "
+"journal for configuration errors, e.g. in kubeletConfig."
+
 #: pkg/operations/kubernetesupgrade/upgrader.go:202
 #: pkg/operations/kubernetesupgrade/upgrader.go:217
 #, c-format
@@ -488,6 +614,72 @@ msgstr ""
 msgid "Node was not ready within %v"
 msgstr "Node was not ready within %v"
 
+#: pkg/engine/cse.go:166
+msgid ""
+"The Kubernetes API server did not become available on the control plane VM "
+"in time."
+msgstr ""
+"The Kubernetes API server did not become available on the control plane VM "
+"in time."
+
+#: pkg/engine/cse.go:186
+msgid "The NVIDIA GPU drivers failed to install or load."
+msgstr "The NVIDIA GPU drivers failed to install or load."
+
+#: pkg/engine/cse.go:181
+msgid ""
+"The VHD image of the VM does not contain the files this version of AKS "
+"Engine expects."
+msgstr ""
+"The VHD image of the VM does not contain the files this version of AKS "
+"Engine expects."
+
+#: pkg/engine/cse.go:136
+msgid "The VM cannot connect to the Kubernetes API server."
+msgstr "The VM cannot connect to the Kubernetes API server."
+
+#: pkg/engine/cse.go:146
+msgid ""
+"The VM cannot resolve the FQDN of the Kubernetes API server with Azure DNS."
+msgstr ""
+"The VM cannot resolve the FQDN of the Kubernetes API server with Azure DNS."
+
+#: pkg/engine/cse.go:141
+msgid "The VM cannot resolve the FQDN of the Kubernetes API server."
+msgstr "The VM cannot resolve the FQDN of the Kubernetes API server."
+
+#: pkg/engine/cse.go:156
+msgid "The VM could not download Kubernetes components in time."
+msgstr "The VM could not download Kubernetes components in time."
+
+#: pkg/engine/cse.go:151
+msgid "The VM could not install packages from the apt repositories in time."
+msgstr "The VM could not install packages from the apt repositories in time."
+
+#: pkg/engine/cse.go:191
+msgid "The VM failed to join the custom search domain."
+msgstr "The VM failed to join the custom search domain."
+
+#: pkg/engine/cse.go:196
+msgid ""
+"The VM failed to read its network configuration from the Azure Stack Hub "
+"Resource Manager."
+msgstr ""
+"The VM failed to read its network configuration from the Azure Stack Hub "
+"Resource Manager."
+
+#: pkg/engine/cse.go:131
+msgid "The VM has no outbound connectivity to the container registry."
+msgstr "The VM has no outbound connectivity to the container registry."
+
+#: pkg/engine/cse.go:161
+msgid "The kubelet service failed to start."
+msgstr "The kubelet service failed to start."
+
+#: pkg/engine/cse.go:111
+msgid "The provisioning script of the Windows VM failed."
+msgstr "The provisioning script of the Windows VM failed."
+
 #: pkg/api/apiloader.go:205 pkg/api/apiloader.go:210 pkg/api/apiloader.go:225
 #: pkg/api/apiloader.go:230
 #, c-format
@@ -504,6 +696,18 @@ msgstr "Total count of master VMs: %d exceeded expected count: %d"
 msgid "Upgrade to Kubernetes version %s is not supported"
 msgstr "Upgrade to Kubernetes version %s is not supported"
 
+#: pkg/engine/cse.go:182
+msgid ""
+"Use the VHD image of the AKS Engine release that deploys the cluster, or "
+"remove the custom image reference from the api model."
+msgstr ""
+"Use the VHD image of the AKS Engine release that deploys the cluster, or "
+"remove the custom image reference from the api model."
+
+#: pkg/engine/cse.go:176
+msgid "cloud-init did not write the provisioning files of the VM in time."
+msgstr "cloud-init did not write the provisioning files of the VM in time."
+
 #: pkg/acsengine/filesaver.go:26
 #, c-format
 msgid "error creating directory '%s': %s"
@@ -530,6 +734,10 @@ msgstr "error parsing file %s: %v"
 msgid "error reading file %s: %s"
 msgstr "error reading file %s: %s"
 
+#: pkg/engine/cse.go:171
+msgid "etcd failed to start or join the cluster on the control plane VM."
+msgstr "etcd failed to start or join the cluster on the control plane VM."
+
 #: pkg/operations/kubernetesupgrade/upgrader.go:385
 #, c-format
 msgid "failed to initialize template generator: %s"
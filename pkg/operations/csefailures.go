@@ -0,0 +1,79 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package operations
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/engine"
+	"github.com/Azure/aks-engine/pkg/i18n"
+	"github.com/pkg/errors"
+)
+
+// CSEFailure is a VM extension failure of a deployment, decoded into the CSE error its exit code stands for
+type CSEFailure struct {
+	armhelpers.ExtensionFailure
+	engine.CSEErrorDiagnostic
+	// ErrorName is the name of the CSE error, empty if the exit code is not a CSE error code
+	ErrorName string
+}
+
+// GetCSEFailures decodes the VM extension failures of err, or returns nil if err does not wrap an *armhelpers.DeploymentError
+func GetCSEFailures(err error, translator *i18n.Translator) []CSEFailure {
+	var deploymentErr *armhelpers.DeploymentError
+	if !errors.As(err, &deploymentErr) {
+		return nil
+	}
+	var failures []CSEFailure
+	for _, failure := range deploymentErr.ExtensionFailures() {
+		if failure.Windows {
+			failures = append(failures, CSEFailure{
+				ExtensionFailure:   failure,
+				CSEErrorDiagnostic: engine.GetWindowsCSEErrorDiagnostic(translator),
+			})
+			continue
+		}
+		var name string
+		if failure.ExitCode > 0 {
+			name = engine.GetCSEErrorName(failure.ExitCode)
+		}
+		failures = append(failures, CSEFailure{
+			ExtensionFailure:   failure,
+			CSEErrorDiagnostic: engine.GetCSEErrorDiagnostic(translator, name),
+			ErrorName:          name,
+		})
+	}
+	return failures
+}
+
+// PrintCSEFailures prints the probable cause and remediation of each VM extension failure
+func PrintCSEFailures(w io.Writer, failures []CSEFailure) {
+	if len(failures) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%d VM extension(s) failed to provision:\n", len(failures))
+	for _, failure := range failures {
+		vm := failure.VMName
+		if failure.ExtensionName != "" {
+			vm = fmt.Sprintf("%s (%s)", failure.VMName, failure.ExtensionName)
+		}
+		switch {
+		case failure.Windows:
+			// The error output of the Windows provisioning script is more telling than its exit status
+			fmt.Fprintf(w, "%s: %s\n", vm, failure.Message)
+		case failure.ErrorName != "":
+			fmt.Fprintf(w, "%s: exit code %d, %s\n", vm, failure.ExitCode, failure.ErrorName)
+		case failure.ExitCode >= 0:
+			fmt.Fprintf(w, "%s: exit code %d\n", vm, failure.ExitCode)
+		default:
+			fmt.Fprintf(w, "%s: %s\n", vm, failure.Message)
+		}
+		if failure.Cause != "" {
+			fmt.Fprintf(w, "    Probable cause: %s\n", failure.Cause)
+		}
+		fmt.Fprintf(w, "    Remediation: %s\n", failure.Remediation)
+	}
+}
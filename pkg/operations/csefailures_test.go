@@ -0,0 +1,83 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package operations
+
+import (
+	"bytes"
+
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/aks-engine/pkg/i18n"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+func mockExtensionFailureOperation(resourceName, message string) resources.DeploymentOperation {
+	return resources.DeploymentOperation{
+		Properties: &resources.DeploymentOperationProperties{
+			ProvisioningState: to.StringPtr("Failed"),
+			StatusMessage: map[string]interface{}{
+				"error": map[string]interface{}{
+					"code":    "VMExtensionProvisioningError",
+					"message": message,
+				},
+			},
+			TargetResource: &resources.TargetResource{ResourceName: to.StringPtr(resourceName)},
+		},
+	}
+}
+
+var _ = Describe("CSE failures tests", func() {
+	deploymentErr := &armhelpers.DeploymentError{
+		TopError: errors.New("At least one resource deployment operation failed"),
+		OperationsLists: []resources.DeploymentOperationsListResult{
+			{
+				Value: &[]resources.DeploymentOperation{
+					mockExtensionFailureOperation("k8s-master-12345678-0/cse-master-0", "Enable failed: command terminated with exit status=52"),
+					mockExtensionFailureOperation("k8s-agentpool1-12345678-0/cse-agent-0", "Enable failed: command terminated with exit status=1"),
+					mockExtensionFailureOperation("k8s-agentpool2-12345678-vmss", "Enable failed: timed out"),
+					mockExtensionFailureOperation("1234k8s000/cse-agent-0", "Command execution finished, but failed because it returned a non-zero exit code of: '1'. The command had an error output of: 'Failed to download'"),
+				},
+			},
+		},
+	}
+
+	It("Should decode the exit codes of the extension failures", func() {
+		failures := GetCSEFailures(errors.Wrap(deploymentErr, "upgrading cluster"), &i18n.Translator{})
+		Expect(failures).To(HaveLen(4))
+		Expect(failures[0].VMName).To(Equal("k8s-master-12345678-0"))
+		Expect(failures[0].ErrorName).To(Equal("ERR_K8S_API_SERVER_DNS_LOOKUP_FAIL"))
+		Expect(failures[0].Cause).NotTo(BeEmpty())
+		Expect(failures[1].ErrorName).To(BeEmpty())
+		Expect(failures[1].Cause).To(BeEmpty())
+		Expect(failures[1].Remediation).To(ContainSubstring("aks-engine get-logs"))
+		Expect(failures[2].ExitCode).To(Equal(-1))
+		Expect(failures[2].ErrorName).To(BeEmpty())
+		Expect(failures[3].ExitCode).To(Equal(1))
+		Expect(failures[3].ErrorName).To(BeEmpty())
+		Expect(failures[3].Remediation).To(ContainSubstring(`C:\AzureData\CustomDataSetupScript.log`))
+		Expect(failures[3].Remediation).NotTo(ContainSubstring("/var/log"))
+	})
+
+	It("Should not decode other errors", func() {
+		Expect(GetCSEFailures(errors.New("DeployTemplate failed"), &i18n.Translator{})).To(BeNil())
+		Expect(GetCSEFailures(nil, &i18n.Translator{})).To(BeNil())
+	})
+
+	It("Should print a summary per VM", func() {
+		var b bytes.Buffer
+		PrintCSEFailures(&b, GetCSEFailures(deploymentErr, &i18n.Translator{}))
+		Expect(b.String()).To(HavePrefix("4 VM extension(s) failed to provision:\n"))
+		Expect(b.String()).To(ContainSubstring("k8s-master-12345678-0 (cse-master-0): exit code 52, ERR_K8S_API_SERVER_DNS_LOOKUP_FAIL\n    Probable cause: The VM cannot resolve the FQDN of the Kubernetes API server.\n    Remediation: "))
+		Expect(b.String()).To(ContainSubstring("k8s-agentpool1-12345678-0 (cse-agent-0): exit code 1\n    Remediation: "))
+		Expect(b.String()).To(ContainSubstring("k8s-agentpool2-12345678-vmss: Enable failed: timed out\n"))
+		Expect(b.String()).To(ContainSubstring("1234k8s000 (cse-agent-0): Command execution finished, but failed because it returned a non-zero exit code of: '1'. The command had an error output of: 'Failed to download'\n    Probable cause: The provisioning script of the Windows VM failed.\n"))
+
+		b.Reset()
+		PrintCSEFailures(&b, nil)
+		Expect(b.String()).To(BeEmpty())
+	})
+})
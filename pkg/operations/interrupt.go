@@ -0,0 +1,75 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package operations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// InterruptedError is returned by a long-running operation that stopped at a safe point, between two nodes,
+// because its context was canceled. The steps already done are complete, running the operation again resumes it
+type InterruptedError struct {
+	// Step is the step the operation stopped before
+	Step string
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("interrupted before %s", e.Step)
+}
+
+type interruptKey struct{}
+
+// detachedContext carries the values of the context of the operation, but neither its deadline nor its cancellation,
+// so that a step is completed once started. Its children still report the cancellation through CheckInterrupted
+type detachedContext struct {
+	interrupt context.Context
+}
+
+func (c detachedContext) Deadline() (deadline time.Time, ok bool) { return }
+func (c detachedContext) Done() <-chan struct{}                   { return nil }
+func (c detachedContext) Err() error                              { return nil }
+func (c detachedContext) Value(key interface{}) interface{} {
+	if key == (interruptKey{}) {
+		return c.interrupt
+	}
+	return c.interrupt.Value(key)
+}
+
+// DetachInterrupt returns a context for the steps of the operation running with ctx that must not be left half done.
+// Canceling ctx doesn't cancel it or its children, the calls to Azure made with them are bounded by their own timeout,
+// but CheckInterrupted and Interrupted on them report the cancellation of ctx
+func DetachInterrupt(ctx context.Context) context.Context {
+	return detachedContext{interrupt: interruptContext(ctx)}
+}
+
+// interruptContext returns the context whose cancellation interrupts the operation running with ctx
+func interruptContext(ctx context.Context) context.Context {
+	if interrupt, ok := ctx.Value(interruptKey{}).(context.Context); ok {
+		return interrupt
+	}
+	return ctx
+}
+
+// Interrupted returns a channel that is closed once the operation running with ctx is interrupted
+func Interrupted(ctx context.Context) <-chan struct{} {
+	return interruptContext(ctx).Done()
+}
+
+// CheckInterrupted returns an *InterruptedError if the operation running with ctx is interrupted, so that it stops before starting step
+func CheckInterrupted(ctx context.Context, step string) error {
+	if interruptContext(ctx).Err() != nil {
+		return &InterruptedError{Step: step}
+	}
+	return nil
+}
+
+// IsInterrupted returns true if err wraps an *InterruptedError
+func IsInterrupted(err error) bool {
+	var interruptedErr *InterruptedError
+	return errors.As(err, &interruptedErr)
+}
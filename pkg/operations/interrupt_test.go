@@ -0,0 +1,40 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package operations
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("Interruption tests", func() {
+	It("Should only return an error once the context is canceled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		Expect(CheckInterrupted(ctx, "upgrading node k8s-agentpool1-12345678-1")).To(Succeed())
+
+		cancel()
+		err := CheckInterrupted(ctx, "upgrading node k8s-agentpool1-12345678-1")
+		Expect(err).To(MatchError("interrupted before upgrading node k8s-agentpool1-12345678-1"))
+		Expect(IsInterrupted(err)).To(BeTrue())
+		Expect(IsInterrupted(errors.Wrap(err, "upgrading agent pools"))).To(BeTrue())
+		Expect(IsInterrupted(context.Canceled)).To(BeFalse())
+	})
+
+	It("Should not cancel a detached context but report the interruption", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		detached, cancelStep := context.WithTimeout(DetachInterrupt(ctx), time.Minute)
+		defer cancelStep()
+		Expect(CheckInterrupted(detached, "upgrading node k8s-agentpool1-12345678-1")).To(Succeed())
+
+		cancel()
+		Expect(detached.Err()).NotTo(HaveOccurred())
+		Expect(Interrupted(detached)).To(BeClosed())
+		Expect(CheckInterrupted(detached, "upgrading node k8s-agentpool1-12345678-1")).To(MatchError("interrupted before upgrading node k8s-agentpool1-12345678-1"))
+		Expect(CheckInterrupted(DetachInterrupt(detached), "upgrading node k8s-agentpool1-12345678-1")).To(HaveOccurred())
+	})
+})
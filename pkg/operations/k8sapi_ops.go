@@ -6,6 +6,7 @@ package operations
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"text/tabwriter"
@@ -89,7 +90,12 @@ func listNodes(az armhelpers.AKSEngineClient, logger *log.Entry, apiserverURL, k
 
 // PrintNodes outputs nodes to stdout
 func PrintNodes(nodes []v1.Node) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 8, 4, ' ', tabwriter.FilterHTML)
+	FprintNodes(os.Stdout, nodes)
+}
+
+// FprintNodes outputs nodes to out
+func FprintNodes(out io.Writer, nodes []v1.Node) {
+	w := tabwriter.NewWriter(out, 0, 8, 4, ' ', tabwriter.FilterHTML)
 	fmt.Fprintln(w, "NODE\tSTATUS\tVERSION\tOS\tKERNEL")
 	for _, node := range nodes {
 		nodeStatus := "NotReady"
@@ -102,7 +102,7 @@ func (kan *UpgradeAgentNode) CreateNode(ctx context.Context, poolName string, ag
 	deploymentSuffix := random.Int31()
 	deploymentName := fmt.Sprintf("k8s-upgrade-%s-%d-%s-%d", poolName, agentNo, time.Now().Format("06-01-02T15.04.05"), deploymentSuffix)
 
-	return armhelpers.DeployTemplateSync(kan.Client, kan.logger, kan.ResourceGroup, deploymentName, kan.TemplateMap, kan.ParametersMap)
+	return armhelpers.DeployTemplateSyncWithContext(ctx, kan.Client, kan.logger, kan.ResourceGroup, deploymentName, kan.TemplateMap, kan.ParametersMap)
 }
 
 // Validate will verify that agent node has been upgraded as expected.
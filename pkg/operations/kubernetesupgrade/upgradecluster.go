@@ -6,6 +6,7 @@ package kubernetesupgrade
 import (
 	"context"
 	"fmt"
+	"io"
 	"math/rand"
 	"strings"
 	"time"
@@ -83,13 +84,18 @@ type UpgradeCluster struct {
 	Force              bool
 	ControlPlaneOnly   bool
 	CurrentVersion     string
+	// WhatIf previews the deployments of the upgrade templates, without upgrading the cluster
+	WhatIf bool
+	// WhatIfOutput is where the previews of WhatIf are printed, stdout if nil
+	WhatIfOutput io.Writer
 }
 
 // MasterPoolName pool name
 const MasterPoolName = "master"
 
 // UpgradeCluster runs the workflow to upgrade a Kubernetes cluster.
-func (uc *UpgradeCluster) UpgradeCluster(az armhelpers.AKSEngineClient, kubeConfig string, aksEngineVersion string) error {
+// Canceling ctx interrupts the upgrade once the node being upgraded is done, see UpgradeWorkFlow.RunUpgrade.
+func (uc *UpgradeCluster) UpgradeCluster(ctx context.Context, az armhelpers.AKSEngineClient, kubeConfig string, aksEngineVersion string) error {
 	uc.MasterVMs = &[]compute.VirtualMachine{}
 	uc.UpgradedMasterVMs = &[]compute.VirtualMachine{}
 	uc.AgentPools = make(map[string]*AgentPoolTopology)
@@ -119,7 +125,7 @@ func (uc *UpgradeCluster) UpgradeCluster(az armhelpers.AKSEngineClient, kubeConf
 	}
 
 	kc := uc.DataModel.Properties.OrchestratorProfile.KubernetesConfig
-	if kc != nil && kc.IsClusterAutoscalerEnabled() && !uc.ControlPlaneOnly {
+	if kc != nil && kc.IsClusterAutoscalerEnabled() && !uc.ControlPlaneOnly && !uc.WhatIf {
 		// pause the cluster-autoscaler before running upgrade and resume it afterward
 		uc.Logger.Info("Pausing cluster autoscaler, replica count: 0")
 		count, err := uc.SetClusterAutoscalerReplicaCount(kubeClient, 0)
@@ -145,11 +151,18 @@ func (uc *UpgradeCluster) UpgradeCluster(az armhelpers.AKSEngineClient, kubeConf
 	if uc.ControlPlaneOnly {
 		what = "control plane nodes"
 	}
-	uc.Logger.Infof("Upgrading %s to Kubernetes version %s", what, upgradeVersion)
+	if uc.WhatIf {
+		uc.Logger.Infof("Previewing the upgrade of %s to Kubernetes version %s", what, upgradeVersion)
+	} else {
+		uc.Logger.Infof("Upgrading %s to Kubernetes version %s", what, upgradeVersion)
+	}
 
-	if err := uc.getUpgradeWorkflow(kubeConfig, aksEngineVersion).RunUpgrade(); err != nil {
+	if err := uc.getUpgradeWorkflow(kubeConfig, aksEngineVersion).RunUpgrade(ctx); err != nil {
 		return err
 	}
+	if uc.WhatIf {
+		return nil
+	}
 
 	what = "Cluster"
 	if uc.ControlPlaneOnly {
@@ -195,6 +208,8 @@ func (uc *UpgradeCluster) getUpgradeWorkflow(kubeConfig string, aksEngineVersion
 	u := &Upgrader{}
 	u.Init(uc.Translator, uc.Logger, uc.ClusterTopology, uc.Client, kubeConfig, uc.StepTimeout, uc.CordonDrainTimeout, aksEngineVersion, uc.ControlPlaneOnly)
 	u.CurrentVersion = uc.CurrentVersion
+	u.WhatIf = uc.WhatIf
+	u.WhatIfOutput = uc.WhatIfOutput
 	return u
 }
 
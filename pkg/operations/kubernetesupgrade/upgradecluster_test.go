@@ -17,6 +17,7 @@ import (
 	"github.com/Azure/aks-engine/pkg/armhelpers"
 	"github.com/Azure/aks-engine/pkg/i18n"
 	mock "github.com/Azure/aks-engine/pkg/kubernetes/mock_kubernetes"
+	"github.com/Azure/aks-engine/pkg/operations"
 	. "github.com/Azure/aks-engine/pkg/test"
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
@@ -37,7 +38,7 @@ type fakeUpgradeWorkflow struct {
 	ValidateError   error
 }
 
-func (workflow fakeUpgradeWorkflow) RunUpgrade() error {
+func (workflow fakeUpgradeWorkflow) RunUpgrade(ctx context.Context) error {
 	if workflow.RunUpgradeError != nil {
 		return workflow.RunUpgradeError
 	}
@@ -85,7 +86,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		uc.NameSuffix = "12345678"
 		uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
 
-		err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(uc.ClusterTopology.AgentPools).NotTo(BeEmpty())
 
@@ -93,6 +94,28 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		os.RemoveAll("./translations")
 	})
 
+	It("Should upgrade the control plane of a cluster without node pools", func() {
+		cs := api.CreateMockContainerService("testcluster", upgradeVersion, 1, 1, false)
+		cs.Properties.AgentPoolProfiles = nil
+		uc := UpgradeCluster{
+			Translator: &i18n.Translator{},
+			Logger:     log.NewEntry(log.New()),
+		}
+
+		mockClient := armhelpers.MockAKSEngineClient{}
+		uc.Client = &mockClient
+
+		uc.ClusterTopology = ClusterTopology{}
+		uc.SubscriptionID = "DEC923E3-1EF1-4745-9516-37906D56DEC4"
+		uc.ResourceGroup = "TestRg"
+		uc.DataModel = cs
+		uc.NameSuffix = "12345678"
+
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(uc.ClusterTopology.AgentPools).To(BeEmpty())
+	})
+
 	It("Should return error message when failing to list VMs during upgrade operation", func() {
 		cs := api.CreateMockContainerService("testcluster", upgradeVersion, 1, 1, false)
 		uc := UpgradeCluster{
@@ -111,7 +134,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		uc.NameSuffix = "12345678"
 		uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
 
-		err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).To(HaveOccurred())
 		Expect(err).To(MatchError("Error while querying ARM for resources: ListVirtualMachines failed"))
 
@@ -137,11 +160,35 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		uc.NameSuffix = "12345678"
 		uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
 
-		err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(Equal("DeleteVirtualMachine failed"))
 	})
 
+	It("Should stop between two nodes once the upgrade is interrupted", func() {
+		cs := api.CreateMockContainerService("testcluster", upgradeVersion, 1, 1, false)
+		uc := UpgradeCluster{
+			Translator: &i18n.Translator{},
+			Logger:     log.NewEntry(log.New()),
+		}
+
+		mockClient := armhelpers.MockAKSEngineClient{}
+		uc.Client = &mockClient
+
+		uc.ClusterTopology = ClusterTopology{}
+		uc.SubscriptionID = "DEC923E3-1EF1-4745-9516-37906D56DEC4"
+		uc.ResourceGroup = "TestRg"
+		uc.DataModel = cs
+		uc.NameSuffix = "12345678"
+		uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := uc.UpgradeCluster(ctx, &mockClient, "kubeConfig", TestAKSEngineVersion)
+		Expect(operations.IsInterrupted(err)).To(BeTrue())
+		Expect(err).To(MatchError("interrupted before upgrading the agent nodes"))
+	})
+
 	It("Should return error message when failing to deploy template during upgrade operation", func() {
 		cs := api.CreateMockContainerService("testcluster", upgradeVersion, 1, 1, false)
 		uc := UpgradeCluster{
@@ -160,9 +207,145 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		uc.NameSuffix = "12345678"
 		uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
 
-		err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("TopError[DeployTemplate failed]"))
+	})
+
+	It("Should preview the upgrade templates without deleting VMs or deploying templates with what-if", func() {
+		cs := api.CreateMockContainerService("testcluster", upgradeVersion, 3, 2, false)
+		uc := UpgradeCluster{
+			Translator: &i18n.Translator{},
+			Logger:     log.NewEntry(log.New()),
+			WhatIf:     true,
+		}
+
+		mockClient := armhelpers.MockAKSEngineClient{}
+		mockClient.FailDeleteVirtualMachine = true
+		mockClient.FailDeployTemplate = true
+		var previewed []map[string]interface{}
+		mockClient.FakeWhatIfDeploymentResult = func(template map[string]interface{}) armhelpers.WhatIfResult {
+			previewed = append(previewed, template)
+			return armhelpers.WhatIfResult{Status: "Succeeded"}
+		}
+		uc.Client = &mockClient
+
+		uc.ClusterTopology = ClusterTopology{}
+		uc.SubscriptionID = "DEC923E3-1EF1-4745-9516-37906D56DEC4"
+		uc.ResourceGroup = "TestRg"
+		uc.DataModel = cs
+		uc.NameSuffix = "12345678"
+		uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
+
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
+		Expect(err).NotTo(HaveOccurred())
+		// The master pool template, then the agentpool1 template
+		Expect(previewed).To(HaveLen(2))
+		Expect(previewed[0]["variables"].(map[string]interface{})["masterCount"]).To(Equal(3))
+		Expect(previewed[1]["variables"].(map[string]interface{})["agentpool1Offset"]).To(Equal(0))
+	})
+
+	It("Should regenerate the Ignition custom data of the nodes of a Flatcar pool", func() {
+		cs := api.CreateMockContainerService("testcluster", upgradeVersion, 1, 1, false)
+		cs.Properties.AgentPoolProfiles[0].Distro = api.Flatcar
+		uc := UpgradeCluster{
+			Translator: &i18n.Translator{},
+			Logger:     log.NewEntry(log.New()),
+			WhatIf:     true,
+		}
+
+		mockClient := armhelpers.MockAKSEngineClient{}
+		var previewed []map[string]interface{}
+		mockClient.FakeWhatIfDeploymentResult = func(template map[string]interface{}) armhelpers.WhatIfResult {
+			previewed = append(previewed, template)
+			return armhelpers.WhatIfResult{Status: "Succeeded"}
+		}
+		uc.Client = &mockClient
+
+		uc.ClusterTopology = ClusterTopology{}
+		uc.SubscriptionID = "DEC923E3-1EF1-4745-9516-37906D56DEC4"
+		uc.ResourceGroup = "TestRg"
+		uc.DataModel = cs
+		uc.NameSuffix = "12345678"
+		uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
+
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
+		Expect(err).NotTo(HaveOccurred())
+		// The master pool template, then the agentpool1 template
+		Expect(previewed).To(HaveLen(2))
+		var customData []string
+		for _, r := range previewed[1]["resources"].([]interface{}) {
+			resource := r.(map[string]interface{})
+			if resource["type"] == "Microsoft.Compute/virtualMachines" && strings.Contains(resource["name"].(string), "agentpool1") {
+				osProfile := resource["properties"].(map[string]interface{})["osProfile"].(map[string]interface{})
+				customData = append(customData, osProfile["customData"].(string))
+			}
+		}
+		Expect(customData).To(HaveLen(1))
+		Expect(customData[0]).To(ContainSubstring(`"ignition":{"version":"3.3.0"}`))
+		Expect(customData[0]).NotTo(ContainSubstring("#cloud-config"))
+	})
+
+	It("Should update the subnets once the nodes are upgraded when an agent pool has a network security group", func() {
+		cs := api.CreateMockContainerService("testcluster", upgradeVersion, 1, 1, false)
+		cs.Properties.AgentPoolProfiles[0].NetworkSecurityRules = []api.NetworkSecurityRule{
+			{Name: "allow_nodeports", Priority: 200, Direction: "Inbound", Access: "Allow", Protocol: "Tcp"},
+		}
+		uc := UpgradeCluster{
+			Translator: &i18n.Translator{},
+			Logger:     log.NewEntry(log.New()),
+			WhatIf:     true,
+		}
+
+		mockClient := armhelpers.MockAKSEngineClient{}
+		var previewed []map[string]interface{}
+		mockClient.FakeWhatIfDeploymentResult = func(template map[string]interface{}) armhelpers.WhatIfResult {
+			previewed = append(previewed, template)
+			return armhelpers.WhatIfResult{Status: "Succeeded"}
+		}
+		uc.Client = &mockClient
+
+		uc.ClusterTopology = ClusterTopology{}
+		uc.SubscriptionID = "DEC923E3-1EF1-4745-9516-37906D56DEC4"
+		uc.ResourceGroup = "TestRg"
+		uc.DataModel = cs
+		uc.NameSuffix = "12345678"
+		uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
+
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
+		Expect(err).NotTo(HaveOccurred())
+		// The master pool template, the agentpool1 template, then the virtual network without the cluster nsg on its subnet
+		Expect(previewed).To(HaveLen(3))
+		resources := previewed[2]["resources"].([]interface{})
+		Expect(resources).To(HaveLen(1))
+		vnet := resources[0].(map[string]interface{})
+		Expect(vnet["type"]).To(Equal("Microsoft.Network/virtualNetworks"))
+		subnets := vnet["properties"].(map[string]interface{})["subnets"].([]interface{})
+		Expect(subnets[0].(map[string]interface{})["properties"]).NotTo(HaveKey("networkSecurityGroup"))
+	})
+
+	It("Should return error message when failing to preview the upgrade templates with what-if", func() {
+		cs := api.CreateMockContainerService("testcluster", upgradeVersion, 1, 1, false)
+		uc := UpgradeCluster{
+			Translator: &i18n.Translator{},
+			Logger:     log.NewEntry(log.New()),
+			WhatIf:     true,
+		}
+
+		mockClient := armhelpers.MockAKSEngineClient{}
+		mockClient.FailWhatIfDeployment = true
+		uc.Client = &mockClient
+
+		uc.ClusterTopology = ClusterTopology{}
+		uc.SubscriptionID = "DEC923E3-1EF1-4745-9516-37906D56DEC4"
+		uc.ResourceGroup = "TestRg"
+		uc.DataModel = cs
+		uc.NameSuffix = "12345678"
+		uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
+
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).To(HaveOccurred())
-		Expect(err.Error()).To(Equal("DeployTemplate failed"))
+		Expect(err.Error()).To(ContainSubstring("WhatIfDeployment failed"))
 	})
 
 	It("Should return error message when failing to get a virtual machine during upgrade operation", func() {
@@ -183,7 +366,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		uc.NameSuffix = "12345678"
 		uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
 
-		err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(Equal("GetVirtualMachine failed"))
 	})
@@ -206,7 +389,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		uc.NameSuffix = "12345678"
 		uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
 
-		err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(Equal("GetStorageClient failed"))
 	})
@@ -229,7 +412,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		uc.NameSuffix = "12345678"
 		uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
 
-		err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(Equal("DeleteNetworkInterface failed"))
 	})
@@ -255,7 +438,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		uc.NameSuffix = "12345678"
 		uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
 
-		err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(Equal("DeleteRoleAssignmentByID failed"))
 	})
@@ -306,7 +489,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			}
 			uc.Force = false
 
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(uc.AgentPoolScaleSetsToUpgrade[0].VMsToUpgrade).To(HaveLen(2))
 		})
@@ -319,7 +502,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			}
 			uc.Force = false
 
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(uc.AgentPoolScaleSetsToUpgrade[0].VMsToUpgrade).To(HaveLen(1))
 		})
@@ -332,7 +515,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			}
 			uc.Force = true
 
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(uc.AgentPoolScaleSetsToUpgrade[0].VMsToUpgrade).To(HaveLen(2))
 		})
@@ -347,7 +530,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			}
 			uc.Force = true
 
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(uc.AgentPoolScaleSetsToUpgrade[0].VMsToUpgrade).To(HaveLen(4))
 		})
@@ -362,7 +545,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			}
 			uc.Force = false
 
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(uc.AgentPoolScaleSetsToUpgrade[0].VMsToUpgrade).To(HaveLen(4))
 		})
@@ -393,7 +576,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			}
 			uc.Force = false
 
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(uc.AgentPoolScaleSetsToUpgrade[0].VMsToUpgrade).To(HaveLen(1))
 			Expect(uc.AgentPoolScaleSetsToUpgrade[0].VMsToUpgrade[0].Name).To(Equal("vmWithoutLatestModelApplied!"))
@@ -434,7 +617,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			}
 
 			Expect(uc.DataModel.Properties.AgentPoolProfiles[0].Count).To(Equal(3))
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(uc.DataModel.Properties.AgentPoolProfiles[0].Count).To(Equal(int(capacity)))
 			Expect(uc.AgentPoolScaleSetsToUpgrade[0].VMsToUpgrade).To(HaveLen(int(capacity)))
@@ -500,7 +683,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			}
 			uc.Force = false
 
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(uc.AgentPoolScaleSetsToUpgrade[0].IsWindows).To(BeTrue())
 			Expect(uc.AgentPoolScaleSetsToUpgrade[0].VMsToUpgrade).To(HaveLen(2))
@@ -547,7 +730,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
 			uc.Force = false
 
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(*uc.AgentPools["agentpool1"].AgentVMs).To(HaveLen(0))
 		})
@@ -565,7 +748,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
 			uc.Force = false
 			uc.DataModel.Properties.OrchestratorProfile.OrchestratorVersion = desiredVersion
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("1.9.7 cannot be upgraded to 1.9.10"))
 		})
@@ -583,7 +766,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
 			uc.Force = true
 			uc.DataModel.Properties.OrchestratorProfile.OrchestratorVersion = desiredVersion
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(*uc.AgentPools["agentpool1"].AgentVMs).To(HaveLen(1))
 
@@ -597,7 +780,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
 			uc.Force = true
 
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(*uc.AgentPools["agentpool1"].AgentVMs).To(HaveLen(1))
 		})
@@ -609,7 +792,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			}
 			uc.Force = false
 
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(*uc.MasterVMs).To(HaveLen(0))
 			Expect(*uc.UpgradedMasterVMs).To(HaveLen(1))
@@ -623,7 +806,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			}
 			uc.Force = true
 
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(*uc.MasterVMs).To(HaveLen(1))
 			Expect(*uc.UpgradedMasterVMs).To(HaveLen(0))
@@ -648,7 +831,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			uc.NameSuffix = "12345678"
 			uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
 
-			err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+			err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 			Expect(err).To(BeNil())
 			Expect(cs.Properties.MasterProfile.PlatformFaultDomainCount).To(BeNil())
 			for _, pool := range cs.Properties.AgentPoolProfiles {
@@ -712,7 +895,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 			{Name: "agentpool1"},
 		}
 
-		err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).To(BeNil())
 		Expect(cs.Properties.MasterProfile.PlatformFaultDomainCount).To(BeNil())
 		for _, pool := range cs.Properties.AgentPoolProfiles {
@@ -740,7 +923,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		uc.NameSuffix = "12345678"
 		uc.AgentPoolsToUpgrade = map[string]bool{"agentpool1": true}
 
-		err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -760,7 +943,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		logger, hook := logtest.NewNullLogger()
 		uc.Logger.Logger = logger
 		defer hook.Reset()
-		err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).NotTo(HaveOccurred())
 		// check log messages to see that we logged the failure
 		messages := []string{
@@ -804,7 +987,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		uc.Client = &mockClient
 		uc.DataModel = cs
 
-		err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).To(MatchError("GetDeployment failed"))
 
 		logger, hook := logtest.NewNullLogger()
@@ -812,7 +995,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		defer hook.Reset()
 		uc.Force = true
 		mockK8sClient.FailUpdateDeploymentCount = 10
-		err = uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err = uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).NotTo(HaveOccurred())
 		// check log messages to see that we logged the failure
 		messages := []string{
@@ -856,7 +1039,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		logger, hook := logtest.NewNullLogger()
 		uc.Logger.Logger = logger
 		defer hook.Reset()
-		err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).NotTo(HaveOccurred())
 		// check log messages to see that we paused the cluster-autoscaler
 		messages := []string{
@@ -901,7 +1084,7 @@ var _ = Describe("Upgrade Kubernetes cluster tests", func() {
 		logger, hook := logtest.NewNullLogger()
 		uc.Logger.Logger = logger
 		defer hook.Reset()
-		err := uc.UpgradeCluster(&mockClient, "kubeConfig", TestAKSEngineVersion)
+		err := uc.UpgradeCluster(context.Background(), &mockClient, "kubeConfig", TestAKSEngineVersion)
 		Expect(err).NotTo(HaveOccurred())
 		// messages we do not expect to see
 		messages := []string{
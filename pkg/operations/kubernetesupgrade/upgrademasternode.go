@@ -60,13 +60,7 @@ func (kmn *UpgradeMasterNode) CreateNode(ctx context.Context, poolName string, m
 	deploymentSuffix := random.Int31()
 	deploymentName := fmt.Sprintf("k8s-upgrade-master-%d-%s-%d", masterNo, time.Now().Format("06-01-02T15.04.05"), deploymentSuffix)
 
-	_, err := kmn.Client.DeployTemplate(
-		ctx,
-		kmn.ResourceGroup,
-		deploymentName,
-		kmn.TemplateMap,
-		kmn.ParametersMap)
-	return err
+	return armhelpers.DeployTemplateSyncWithContext(ctx, kmn.Client, kmn.logger, kmn.ResourceGroup, deploymentName, kmn.TemplateMap, kmn.ParametersMap)
 }
 
 // Validate will verify the that master node has been upgraded as expected.
@@ -7,7 +7,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/rand"
+	"os"
 	"strings"
 	"time"
 
@@ -42,6 +44,10 @@ type Upgrader struct {
 	AKSEngineVersion   string
 	CurrentVersion     string
 	ControlPlaneOnly   bool
+	// WhatIf previews the deployments of the upgrade templates instead of upgrading the nodes
+	WhatIf bool
+	// WhatIfOutput is where the previews of WhatIf are printed, stdout if nil
+	WhatIfOutput io.Writer
 }
 
 type vmStatus int
@@ -75,24 +81,33 @@ func (ku *Upgrader) Init(translator *i18n.Translator, logger *logrus.Entry, clus
 	ku.ControlPlaneOnly = controlPlaneOnly
 }
 
-// RunUpgrade runs the upgrade pipeline
-func (ku *Upgrader) RunUpgrade() error {
+// RunUpgrade runs the upgrade pipeline.
+// Canceling ctx interrupts the upgrade between two nodes: the node being upgraded is completed,
+// then RunUpgrade returns an *operations.InterruptedError and running the upgrade again resumes it.
+// The nodes are upgraded with a context detached from ctx and bounded by the upgrade timeouts, so that none is left deleted
+func (ku *Upgrader) RunUpgrade(ctx context.Context) error {
 	controlPlaneUpgradeTimeout := perNodeUpgradeTimeout
 	if ku.ClusterTopology.DataModel.Properties.MasterProfile.Count > 0 {
 		controlPlaneUpgradeTimeout = perNodeUpgradeTimeout * time.Duration(ku.ClusterTopology.DataModel.Properties.MasterProfile.Count)
 	}
-	ctxControlPlane, cancelControlPlane := context.WithTimeout(context.Background(), controlPlaneUpgradeTimeout)
+	ctxControlPlane, cancelControlPlane := context.WithTimeout(operations.DetachInterrupt(ctx), controlPlaneUpgradeTimeout)
 	defer cancelControlPlane()
 	if err := ku.upgradeMasterNodes(ctxControlPlane); err != nil {
 		return err
 	}
 
-	ku.handleUnreconcilableAddons()
+	if !ku.WhatIf {
+		ku.handleUnreconcilableAddons()
+	}
 
 	if ku.ControlPlaneOnly {
 		return nil
 	}
 
+	if err := operations.CheckInterrupted(ctx, "upgrading the agent nodes"); err != nil {
+		return err
+	}
+
 	var numNodesToUpgrade int
 	for _, pool := range ku.ClusterTopology.AgentPoolScaleSetsToUpgrade {
 		numNodesToUpgrade += len(pool.VMsToUpgrade)
@@ -101,14 +116,58 @@ func (ku *Upgrader) RunUpgrade() error {
 	if numNodesToUpgrade > 0 {
 		nodesUpgradeTimeout = perNodeUpgradeTimeout * time.Duration(numNodesToUpgrade)
 	}
-	ctxNodes, cancelNodes := context.WithTimeout(context.Background(), nodesUpgradeTimeout)
+	ctxNodes, cancelNodes := context.WithTimeout(operations.DetachInterrupt(ctx), nodesUpgradeTimeout)
 	defer cancelNodes()
 	if err := ku.upgradeAgentScaleSets(ctxNodes); err != nil {
 		return err
 	}
 
 	//This is handling VMAS VMs only, not VMSS
-	return ku.upgradeAgentPools(ctxNodes)
+	if err := ku.upgradeAgentPools(ctxNodes); err != nil {
+		return err
+	}
+
+	return ku.updateSubnets(ctxNodes)
+}
+
+// updateSubnets deploys the virtual network of the cluster once all of its nodes are upgraded, when an agent pool has a
+// network security group of its own. The cluster network security group is then attached to the network interfaces,
+// and is detached from the subnets of a cluster deployed before any agent pool had network security rules.
+func (ku *Upgrader) updateSubnets(ctx context.Context) error {
+	properties := ku.ClusterTopology.DataModel.Properties
+	if properties.MasterProfile == nil || properties.MasterProfile.IsCustomVNET() || !properties.HasAgentPoolNetworkSecurityGroups() {
+		return nil
+	}
+	if err := operations.CheckInterrupted(ctx, "updating the subnets of the cluster"); err != nil {
+		return err
+	}
+
+	templateMap, parametersMap, err := ku.generateUpgradeTemplate(ku.ClusterTopology.DataModel, ku.AKSEngineVersion)
+	if err != nil {
+		return ku.Translator.Errorf("error generating upgrade template: %s", err.Error())
+	}
+	transformer := &transform.Transformer{
+		Translator: ku.Translator,
+	}
+	if err = transformer.NormalizeForK8sSubnetUpdate(ku.logger, templateMap); err != nil {
+		return ku.Translator.Errorf("error normalizing upgrade template for the subnets: %s", err.Error())
+	}
+
+	if ku.WhatIf {
+		return ku.previewDeployment(ctx, "k8s-upgrade-subnets", templateMap, parametersMap)
+	}
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	deploymentName := fmt.Sprintf("k8s-upgrade-subnets-%s-%d", time.Now().Format("06-01-02T15.04.05"), random.Int31())
+	ku.logger.Infof("Deploying ARM template to update the subnets of the cluster...")
+	return armhelpers.DeployTemplateSyncWithContext(
+		ctx,
+		ku.Client,
+		ku.logger,
+		ku.ClusterTopology.ResourceGroup,
+		deploymentName,
+		templateMap,
+		parametersMap)
 }
 
 // handleUnreconcilableAddons ensures addon upgrades that addon-manager cannot handle by itself.
@@ -214,6 +273,14 @@ func (ku *Upgrader) upgradeMasterNodes(ctx context.Context) error {
 
 	transformer.RemoveImmutableResourceProperties(ku.logger, templateMap)
 
+	if ku.WhatIf {
+		// Preview the template the master nodes converge to once they are all recreated
+		templateVariables := templateMap["variables"].(map[string]interface{})
+		templateVariables["masterOffset"] = 0
+		templateVariables["masterCount"] = ku.ClusterTopology.DataModel.Properties.MasterProfile.Count
+		return ku.previewDeployment(ctx, "k8s-upgrade-master", templateMap, parametersMap)
+	}
+
 	upgradeMasterNode := UpgradeMasterNode{
 		Translator: ku.Translator,
 		logger:     ku.logger,
@@ -299,6 +366,9 @@ func (ku *Upgrader) upgradeMasterNodes(ctx context.Context) error {
 	}
 
 	for _, vm := range *ku.ClusterTopology.MasterVMs {
+		if err = operations.CheckInterrupted(ctx, fmt.Sprintf("upgrading master VM %s", *vm.Name)); err != nil {
+			return err
+		}
 		ku.logger.Infof("Upgrading Master VM: %s", *vm.Name)
 
 		masterIndex, _ := utils.GetVMNameIndex(vm.StorageProfile.OsDisk.OsType, *vm.Name)
@@ -383,6 +453,16 @@ func (ku *Upgrader) upgradeAgentPools(ctx context.Context) error {
 			return nil
 		}
 
+		if ku.WhatIf {
+			// Preview the template the agent nodes of the pool converge to once they are all recreated
+			parametersMap[*agentPool.Name+"Count"].(map[string]interface{})["value"] = agentCount
+			templateMap["variables"].(map[string]interface{})[*agentPool.Name+"Offset"] = 0
+			if err = ku.previewDeployment(ctx, fmt.Sprintf("k8s-upgrade-%s", *agentPool.Name), templateMap, parametersMap); err != nil {
+				return err
+			}
+			continue
+		}
+
 		upgradeAgentNode := UpgradeAgentNode{
 			Translator: ku.Translator,
 			logger:     ku.logger,
@@ -475,6 +555,9 @@ func (ku *Upgrader) upgradeAgentPools(ctx context.Context) error {
 				ku.logger.Errorf("Error reconstructing agent VM name with index %d: %v", agentIndex, err)
 				return err
 			}
+			if err = operations.CheckInterrupted(ctx, fmt.Sprintf("creating agent node %s", vmName)); err != nil {
+				return err
+			}
 			ku.logger.Infof("Creating new agent node %s (index %d)", vmName, agentIndex)
 
 			err = upgradeAgentNode.CreateNode(ctx, *agentPool.Name, agentIndex)
@@ -505,6 +588,9 @@ func (ku *Upgrader) upgradeAgentPools(ctx context.Context) error {
 			if vm.status != vmStatusNotUpgraded {
 				continue
 			}
+			if err = operations.CheckInterrupted(ctx, fmt.Sprintf("upgrading agent VM %s", vm.name)); err != nil {
+				return err
+			}
 			ku.logger.Infof("Upgrading Agent VM: %s, pool name: %s", vm.name, *agentPool.Name)
 
 			// copy custom properties from old node to new node if the PreserveNodesProperties in AgentPoolProfile is not set to false explicitly.
@@ -608,9 +694,15 @@ func (ku *Upgrader) upgradeAgentScaleSets(ctx context.Context) error {
 		deploymentSuffix := random.Int31()
 		deploymentName := fmt.Sprintf("k8s-upgrade-update-vmss-pools-%s-%d", time.Now().Format("06-01-02T15.04.05"), deploymentSuffix)
 
+		if ku.WhatIf {
+			return ku.previewDeployment(ctx, deploymentName, templateMap, parametersMap)
+		}
+
 		ku.logger.Infof("Deploying ARM template to update all VMSS node pools...")
-		_, err = ku.Client.DeployTemplate(
+		err = armhelpers.DeployTemplateSyncWithContext(
 			ctx,
+			ku.Client,
+			ku.logger,
 			ku.ClusterTopology.ResourceGroup,
 			deploymentName,
 			templateMap,
@@ -643,6 +735,9 @@ func (ku *Upgrader) upgradeAgentScaleSets(ctx context.Context) error {
 		*vmssToUpgrade.Sku.Capacity = newCapacity
 
 		for _, vmToUpgrade := range vmssToUpgrade.VMsToUpgrade {
+			if err := operations.CheckInterrupted(ctx, fmt.Sprintf("upgrading VMSS instance %s", vmToUpgrade.Name)); err != nil {
+				return err
+			}
 			if err := ku.Client.SetVirtualMachineScaleSetCapacity(
 				ctx,
 				ku.ClusterTopology.ResourceGroup,
@@ -742,6 +837,20 @@ func (ku *Upgrader) upgradeAgentScaleSets(ctx context.Context) error {
 	return nil
 }
 
+// previewDeployment prints the changes that deploying the upgrade template would make to the resource group
+func (ku *Upgrader) previewDeployment(ctx context.Context, deploymentName string, templateMap, parametersMap map[string]interface{}) error {
+	result, err := ku.Client.WhatIfDeployment(ctx, ku.ClusterTopology.ResourceGroup, deploymentName, templateMap, parametersMap)
+	if err != nil {
+		return errors.Wrapf(err, "previewing deployment %s", deploymentName)
+	}
+	w := ku.WhatIfOutput
+	if w == nil {
+		w = os.Stdout
+	}
+	operations.PrintWhatIfResult(w, deploymentName, result)
+	return nil
+}
+
 func (ku *Upgrader) generateUpgradeTemplate(upgradeContainerService *api.ContainerService, aksEngineVersion string) (map[string]interface{}, map[string]interface{}, error) {
 	var err error
 	ctx := engine.Context{
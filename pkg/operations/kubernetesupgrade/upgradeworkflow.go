@@ -10,7 +10,8 @@ import "context"
 type UpgradeWorkFlow interface {
 	// upgrade masters
 	// upgrade agent nodes
-	RunUpgrade() error
+	// stops between two nodes with an *operations.InterruptedError once ctx is canceled
+	RunUpgrade(ctx context.Context) error
 
 	Validate() error
 }
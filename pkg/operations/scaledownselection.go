@@ -0,0 +1,120 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package operations
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-engine/pkg/kubernetes"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Scale down strategies select the nodes to remove when scaling down an agent pool.
+// VMs that have no node registered in the cluster are always removed first
+const (
+	// ScaleDownStrategyNotReadyFirst removes the nodes that are not Ready first
+	ScaleDownStrategyNotReadyFirst = "NotReadyFirst"
+	// ScaleDownStrategyOldestFirst removes the nodes that were registered first
+	ScaleDownStrategyOldestFirst = "OldestFirst"
+	// ScaleDownStrategyLeastPodsFirst removes the nodes that run the fewest pods to evict first
+	ScaleDownStrategyLeastPodsFirst = "LeastPodsFirst"
+)
+
+// ScaleDownStrategies are the supported scale down strategies
+var ScaleDownStrategies = []string{ScaleDownStrategyNotReadyFirst, ScaleDownStrategyOldestFirst, ScaleDownStrategyLeastPodsFirst}
+
+// SelectNodesToRemove returns the names of the count nodes to remove from an agent pool.
+// candidates are the names of the VMs of the pool, in the order the pool removes them by default.
+// The nodes are the ones named by nodesToRemove if any, or the first candidates once sorted by the strategy otherwise.
+// nodes and pods are only used by the strategies, and may be nil if strategy is empty
+func SelectNodesToRemove(candidates []string, count int, nodesToRemove []string, strategy string, nodes []v1.Node, pods []v1.Pod) ([]string, error) {
+	if count > len(candidates) {
+		return nil, errors.Errorf("cannot remove %d nodes from a pool of %d VMs", count, len(candidates))
+	}
+	if len(nodesToRemove) > 0 {
+		return selectNamedNodes(candidates, count, nodesToRemove)
+	}
+
+	sorted := make([]string, len(candidates))
+	copy(sorted, candidates)
+	if strategy == "" {
+		return sorted[:count], nil
+	}
+
+	nodesByName := make(map[string]v1.Node, len(nodes))
+	for _, node := range nodes {
+		nodesByName[strings.ToLower(node.Name)] = node
+	}
+	var less func(a, b v1.Node) bool
+	switch strategy {
+	case ScaleDownStrategyNotReadyFirst:
+		less = func(a, b v1.Node) bool {
+			return !kubernetes.IsNodeReady(&a) && kubernetes.IsNodeReady(&b)
+		}
+	case ScaleDownStrategyOldestFirst:
+		less = func(a, b v1.Node) bool {
+			return a.CreationTimestamp.Before(&b.CreationTimestamp)
+		}
+	case ScaleDownStrategyLeastPodsFirst:
+		podCounts := countPodsToEvict(pods)
+		less = func(a, b v1.Node) bool {
+			return podCounts[strings.ToLower(a.Name)] < podCounts[strings.ToLower(b.Name)]
+		}
+	default:
+		return nil, errors.Errorf("unsupported scale down strategy %s", strategy)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, aFound := nodesByName[strings.ToLower(sorted[i])]
+		b, bFound := nodesByName[strings.ToLower(sorted[j])]
+		if !aFound || !bFound {
+			return !aFound && bFound
+		}
+		return less(a, b)
+	})
+	return sorted[:count], nil
+}
+
+func selectNamedNodes(candidates []string, count int, nodesToRemove []string) ([]string, error) {
+	if len(nodesToRemove) != count {
+		return nil, errors.Errorf("%d nodes to remove were given, but scaling down requires removing %d nodes", len(nodesToRemove), count)
+	}
+	remaining := make(map[string]string, len(candidates))
+	for _, candidate := range candidates {
+		remaining[strings.ToLower(candidate)] = candidate
+	}
+	selected := make([]string, 0, count)
+	for _, name := range nodesToRemove {
+		candidate, ok := remaining[strings.ToLower(name)]
+		if !ok {
+			return nil, errors.Errorf("node %s is not a VM of the pool", name)
+		}
+		selected = append(selected, candidate)
+		delete(remaining, strings.ToLower(name))
+	}
+	return selected, nil
+}
+
+// countPodsToEvict returns the number of pods per node that draining the node evicts,
+// which excludes the terminated pods and the pods of DaemonSets
+func countPodsToEvict(pods []v1.Pod) map[string]int {
+	counts := make(map[string]int)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		daemonSetPod := false
+		for _, ref := range pod.OwnerReferences {
+			if ref.Kind == "DaemonSet" {
+				daemonSetPod = true
+				break
+			}
+		}
+		if !daemonSetPod {
+			counts[strings.ToLower(pod.Spec.NodeName)]++
+		}
+	}
+	return counts
+}
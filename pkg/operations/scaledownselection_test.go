@@ -0,0 +1,109 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package operations
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func mockNode(name string, ready bool, created time.Time) v1.Node {
+	status := v1.ConditionTrue
+	if !ready {
+		status = v1.ConditionFalse
+	}
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func mockPod(nodeName string, phase v1.PodPhase, ownerKind string) v1.Pod {
+	pod := v1.Pod{
+		Spec:   v1.PodSpec{NodeName: nodeName},
+		Status: v1.PodStatus{Phase: phase},
+	}
+	if ownerKind != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind}}
+	}
+	return pod
+}
+
+var _ = Describe("Scale down node selection tests", func() {
+	now := time.Now()
+	// The candidates are in the default order of the pool, highest index first
+	candidates := []string{"k8s-agentpool-12345678-3", "k8s-agentpool-12345678-2", "k8s-agentpool-12345678-1", "k8s-agentpool-12345678-0"}
+	nodes := []v1.Node{
+		mockNode("k8s-agentpool-12345678-3", true, now),
+		mockNode("k8s-agentpool-12345678-2", true, now.Add(-time.Hour)),
+		mockNode("k8s-agentpool-12345678-1", false, now.Add(-2*time.Hour)),
+		mockNode("k8s-agentpool-12345678-0", true, now.Add(-3*time.Hour)),
+	}
+
+	It("Should select the first candidates without a strategy", func() {
+		selected, err := SelectNodesToRemove(candidates, 2, nil, "", nodes, nil)
+		Expect(err).To(BeNil())
+		Expect(selected).To(Equal(candidates[:2]))
+	})
+
+	It("Should select the nodes that are not Ready first", func() {
+		selected, err := SelectNodesToRemove(candidates, 2, nil, ScaleDownStrategyNotReadyFirst, nodes, nil)
+		Expect(err).To(BeNil())
+		Expect(selected).To(Equal([]string{"k8s-agentpool-12345678-1", "k8s-agentpool-12345678-3"}))
+	})
+
+	It("Should select the oldest nodes first", func() {
+		selected, err := SelectNodesToRemove(candidates, 2, nil, ScaleDownStrategyOldestFirst, nodes, nil)
+		Expect(err).To(BeNil())
+		Expect(selected).To(Equal([]string{"k8s-agentpool-12345678-0", "k8s-agentpool-12345678-1"}))
+	})
+
+	It("Should select the nodes with the fewest pods to evict first", func() {
+		pods := []v1.Pod{
+			mockPod("k8s-agentpool-12345678-3", v1.PodRunning, "ReplicaSet"),
+			mockPod("k8s-agentpool-12345678-3", v1.PodRunning, ""),
+			mockPod("k8s-agentpool-12345678-2", v1.PodRunning, "ReplicaSet"),
+			mockPod("k8s-agentpool-12345678-1", v1.PodRunning, "StatefulSet"),
+			mockPod("k8s-agentpool-12345678-1", v1.PodRunning, "ReplicaSet"),
+			mockPod("k8s-agentpool-12345678-1", v1.PodRunning, "ReplicaSet"),
+			mockPod("k8s-agentpool-12345678-0", v1.PodRunning, "DaemonSet"),
+			mockPod("k8s-agentpool-12345678-0", v1.PodSucceeded, "Job"),
+			mockPod("k8s-agentpool-12345678-0", v1.PodFailed, "Job"),
+		}
+		selected, err := SelectNodesToRemove(candidates, 3, nil, ScaleDownStrategyLeastPodsFirst, nodes, pods)
+		Expect(err).To(BeNil())
+		Expect(selected).To(Equal([]string{"k8s-agentpool-12345678-0", "k8s-agentpool-12345678-2", "k8s-agentpool-12345678-3"}))
+	})
+
+	It("Should select the VMs without a node first", func() {
+		selected, err := SelectNodesToRemove(candidates, 2, nil, ScaleDownStrategyOldestFirst, nodes[:2], nil)
+		Expect(err).To(BeNil())
+		Expect(selected).To(Equal([]string{"k8s-agentpool-12345678-1", "k8s-agentpool-12345678-0"}))
+	})
+
+	It("Should select the named nodes", func() {
+		selected, err := SelectNodesToRemove(candidates, 2, []string{"K8S-AGENTPOOL-12345678-0", "k8s-agentpool-12345678-2"}, ScaleDownStrategyOldestFirst, nodes, nil)
+		Expect(err).To(BeNil())
+		Expect(selected).To(Equal([]string{"k8s-agentpool-12345678-0", "k8s-agentpool-12345678-2"}))
+	})
+
+	It("Should not select nodes that cannot be removed", func() {
+		_, err := SelectNodesToRemove(candidates, 5, nil, "", nodes, nil)
+		Expect(err).To(HaveOccurred())
+		_, err = SelectNodesToRemove(candidates, 2, []string{"k8s-agentpool-12345678-0"}, "", nodes, nil)
+		Expect(err).To(HaveOccurred())
+		_, err = SelectNodesToRemove(candidates, 2, []string{"k8s-agentpool-12345678-0", "k8s-agentpool-12345678-0"}, "", nodes, nil)
+		Expect(err).To(HaveOccurred())
+		_, err = SelectNodesToRemove(candidates, 1, []string{"k8s-otherpool-12345678-0"}, "", nodes, nil)
+		Expect(err).To(HaveOccurred())
+		_, err = SelectNodesToRemove(candidates, 1, nil, "NewestFirst", nodes, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,105 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package operations
+
+import (
+	"container/list"
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+// ScaleSetVM is a VM of a VMSS agent pool, Name is the computer name of the VM, which is also the name of its node
+type ScaleSetVM struct {
+	Name       string
+	InstanceID string
+}
+
+// GetScaleSetVMs returns the VMs of a VMSS, oldest first.
+// VMSS instance IDs are allocated incrementally, so the VMs are sorted by instance ID
+func GetScaleSetVMs(ctx context.Context, az armhelpers.AKSEngineClient, resourceGroup, vmssName string) ([]ScaleSetVM, error) {
+	vms := make([]ScaleSetVM, 0)
+	for page, err := az.ListVirtualMachineScaleSetVMs(ctx, resourceGroup, vmssName); page.NotDone(); err = page.NextWithContext(ctx) {
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list the VMs of VMSS %s", vmssName)
+		}
+		for _, vm := range page.Values() {
+			if vm.VirtualMachineScaleSetVMProperties == nil || vm.OsProfile == nil {
+				continue
+			}
+			vms = append(vms, ScaleSetVM{
+				Name:       strings.ToLower(to.String(vm.OsProfile.ComputerName)),
+				InstanceID: to.String(vm.InstanceID),
+			})
+		}
+	}
+	sort.SliceStable(vms, func(i, j int) bool {
+		a, errA := strconv.Atoi(vms[i].InstanceID)
+		b, errB := strconv.Atoi(vms[j].InstanceID)
+		if errA != nil || errB != nil {
+			return vms[i].InstanceID < vms[j].InstanceID
+		}
+		return a < b
+	})
+	return vms, nil
+}
+
+// SelectScaleSetVMsToRemove returns the count VMs to remove from a VMSS, which are the VMs named by nodesToRemove
+// if any, or the oldest VMs once sorted by the strategy otherwise, see SelectNodesToRemove
+func SelectScaleSetVMsToRemove(vms []ScaleSetVM, count int, nodesToRemove []string, strategy string, nodes []v1.Node, pods []v1.Pod) ([]ScaleSetVM, error) {
+	candidates := make([]string, 0, len(vms))
+	byName := make(map[string]ScaleSetVM, len(vms))
+	for _, vm := range vms {
+		candidates = append(candidates, vm.Name)
+		byName[vm.Name] = vm
+	}
+	names, err := SelectNodesToRemove(candidates, count, nodesToRemove, strategy, nodes, pods)
+	if err != nil {
+		return nil, err
+	}
+	selected := make([]ScaleSetVM, 0, len(names))
+	for _, name := range names {
+		selected = append(selected, byName[name])
+	}
+	return selected, nil
+}
+
+// ScaleDownScaleSetVMs deletes the provided VMs of a VMSS, which reduces the capacity of the VMSS accordingly.
+// Returns a list with details on each failure, all items in the list will always be of type *VMScalingErrorDetails
+func ScaleDownScaleSetVMs(az armhelpers.AKSEngineClient, logger *log.Entry, resourceGroup, vmssName string, vms ...ScaleSetVM) *list.List {
+	numVmsToDelete := len(vms)
+	errChan := make(chan *VMScalingErrorDetails, numVmsToDelete)
+	defer close(errChan)
+	for _, vm := range vms {
+		go func(vm ScaleSetVM) {
+			ctx, cancel := context.WithTimeout(context.Background(), armhelpers.DefaultARMOperationTimeout)
+			defer cancel()
+			logger.Debugf("deleting VM %s of VMSS %s in resource group %s", vm.Name, vmssName, resourceGroup)
+			if err := az.DeleteVirtualMachineScaleSetVM(ctx, resourceGroup, vmssName, vm.InstanceID); err != nil {
+				errChan <- &VMScalingErrorDetails{Name: vm.Name, Error: err}
+				return
+			}
+			errChan <- nil
+		}(vm)
+	}
+	failedVMDeletions := &list.List{}
+	for i := 0; i < numVmsToDelete; i++ {
+		errDetails := <-errChan
+		if errDetails != nil {
+			failedVMDeletions.PushBack(errDetails)
+			logger.Errorf("VMSS VM '%s' failed to delete with error: '%s'", errDetails.Name, errDetails.Error.Error())
+		}
+	}
+	if failedVMDeletions.Len() > 0 {
+		return failedVMDeletions
+	}
+	return nil
+}
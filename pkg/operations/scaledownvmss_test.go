@@ -0,0 +1,83 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package operations
+
+import (
+	"context"
+
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+)
+
+func mockScaleSetVM(computerName, instanceID string) compute.VirtualMachineScaleSetVM {
+	return compute.VirtualMachineScaleSetVM{
+		InstanceID: to.StringPtr(instanceID),
+		VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
+			OsProfile: &compute.OSProfile{ComputerName: to.StringPtr(computerName)},
+		},
+	}
+}
+
+var _ = Describe("Scale down VMSS operation tests", func() {
+	vms := []ScaleSetVM{
+		{Name: "k8s-agentpool-12345678-vmss000002", InstanceID: "2"},
+		{Name: "k8s-agentpool-12345678-vmss00000a", InstanceID: "10"},
+		{Name: "k8s-agentpool-12345678-vmss00000b", InstanceID: "11"},
+	}
+
+	It("Should list the VMs of a VMSS oldest first", func() {
+		mockClient := armhelpers.MockAKSEngineClient{}
+		mockClient.FakeListVirtualMachineScaleSetVMsResult = func() []compute.VirtualMachineScaleSetVM {
+			return []compute.VirtualMachineScaleSetVM{
+				mockScaleSetVM("k8s-agentpool-12345678-vmss00000A", "10"),
+				mockScaleSetVM("k8s-agentpool-12345678-vmss00000B", "11"),
+				mockScaleSetVM("k8s-agentpool-12345678-vmss000002", "2"),
+			}
+		}
+		actual, err := GetScaleSetVMs(context.Background(), &mockClient, "rg", "k8s-agentpool-12345678-vmss")
+		Expect(err).To(BeNil())
+		Expect(actual).To(Equal(vms))
+	})
+
+	It("Should select the oldest VMs unless nodes to remove are given", func() {
+		selected, err := SelectScaleSetVMsToRemove(vms, 2, nil, "", nil, nil)
+		Expect(err).To(BeNil())
+		Expect(selected).To(Equal(vms[:2]))
+
+		selected, err = SelectScaleSetVMsToRemove(vms, 1, []string{"K8S-AGENTPOOL-12345678-VMSS00000B"}, "", nil, nil)
+		Expect(err).To(BeNil())
+		Expect(selected).To(Equal([]ScaleSetVM{vms[2]}))
+	})
+
+	It("Should not select nodes that cannot be removed", func() {
+		_, err := SelectScaleSetVMsToRemove(vms, 4, nil, "", nil, nil)
+		Expect(err).To(HaveOccurred())
+		_, err = SelectScaleSetVMsToRemove(vms, 2, []string{"k8s-agentpool-12345678-vmss000002"}, "", nil, nil)
+		Expect(err).To(HaveOccurred())
+		_, err = SelectScaleSetVMsToRemove(vms, 1, []string{"k8s-otherpool-12345678-vmss000002"}, "", nil, nil)
+		Expect(err).To(HaveOccurred())
+		_, err = SelectScaleSetVMsToRemove(vms, 2, []string{"k8s-agentpool-12345678-vmss000002", "k8s-agentpool-12345678-vmss000002"}, "", nil, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should return error messages for failing VMSS VMs", func() {
+		mockClient := armhelpers.MockAKSEngineClient{FailDeleteVirtualMachineScaleSetVM: true}
+		errs := ScaleDownScaleSetVMs(&mockClient, log.NewEntry(log.New()), "rg", "k8s-agentpool-12345678-vmss", vms...)
+		Expect(errs.Len()).To(Equal(3))
+		for e := errs.Front(); e != nil; e = e.Next() {
+			output := e.Value.(*VMScalingErrorDetails)
+			Expect(output.Name).To(ContainSubstring("vmss"))
+			Expect(output.Error).To(HaveOccurred())
+		}
+	})
+
+	It("Should return nil for errors if all VMSS VM deletes are successful", func() {
+		mockClient := armhelpers.MockAKSEngineClient{}
+		Expect(ScaleDownScaleSetVMs(&mockClient, log.NewEntry(log.New()), "rg", "k8s-agentpool-12345678-vmss", vms...)).To(BeNil())
+	})
+})
@@ -0,0 +1,99 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package operations
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+)
+
+// The symbols of the what-if changes, as displayed by the Azure CLI
+var whatIfChangeSymbols = map[string]string{
+	armhelpers.WhatIfChangeCreate:   "+",
+	armhelpers.WhatIfChangeDelete:   "-",
+	armhelpers.WhatIfChangeModify:   "~",
+	armhelpers.WhatIfChangeDeploy:   "!",
+	armhelpers.WhatIfChangeNoChange: "=",
+	armhelpers.WhatIfChangeIgnore:   "*",
+	"Array":                         "~",
+	"NoEffect":                      "x",
+}
+
+// PrintWhatIfResult prints a summary of the resource changes of a what-if operation,
+// then each resource the deployment would create, modify, delete or deploy
+func PrintWhatIfResult(w io.Writer, deploymentName string, result armhelpers.WhatIfResult) {
+	counts := make(map[string]int)
+	for _, change := range result.Changes() {
+		counts[change.ChangeType]++
+	}
+	fmt.Fprintf(w, "Deployment %s would make these resource changes: %d to create, %d to modify, %d to delete, %d to deploy, %d no change, %d ignored.\n",
+		deploymentName,
+		counts[armhelpers.WhatIfChangeCreate],
+		counts[armhelpers.WhatIfChangeModify],
+		counts[armhelpers.WhatIfChangeDelete],
+		counts[armhelpers.WhatIfChangeDeploy],
+		counts[armhelpers.WhatIfChangeNoChange],
+		counts[armhelpers.WhatIfChangeIgnore])
+	for _, change := range result.Changes() {
+		if change.ChangeType == armhelpers.WhatIfChangeNoChange || change.ChangeType == armhelpers.WhatIfChangeIgnore {
+			continue
+		}
+		fmt.Fprintf(w, "  %s %s\n", whatIfChangeSymbol(change.ChangeType), shortResourceID(change.ResourceID))
+		printWhatIfPropertyChanges(w, "      ", "", change.Delta)
+	}
+}
+
+func printWhatIfPropertyChanges(w io.Writer, indent, parentPath string, changes []armhelpers.WhatIfPropertyChange) {
+	for _, change := range changes {
+		path := change.Path
+		if parentPath != "" {
+			if strings.HasPrefix(path, "[") {
+				path = parentPath + path
+			} else {
+				path = parentPath + "." + path
+			}
+		}
+		if len(change.Children) > 0 {
+			printWhatIfPropertyChanges(w, indent, path, change.Children)
+			continue
+		}
+		symbol := whatIfChangeSymbol(change.PropertyChangeType)
+		switch change.PropertyChangeType {
+		case armhelpers.WhatIfChangeCreate:
+			fmt.Fprintf(w, "%s%s %s: %s\n", indent, symbol, path, whatIfValue(change.After))
+		case armhelpers.WhatIfChangeDelete:
+			fmt.Fprintf(w, "%s%s %s: %s\n", indent, symbol, path, whatIfValue(change.Before))
+		default:
+			fmt.Fprintf(w, "%s%s %s: %s => %s\n", indent, symbol, path, whatIfValue(change.Before), whatIfValue(change.After))
+		}
+	}
+}
+
+func whatIfChangeSymbol(changeType string) string {
+	if symbol, ok := whatIfChangeSymbols[changeType]; ok {
+		return symbol
+	}
+	return "?"
+}
+
+// shortResourceID trims the subscription and resource group of a resource ID
+func shortResourceID(resourceID string) string {
+	const providers = "/providers/"
+	if i := strings.LastIndex(strings.ToLower(resourceID), providers); i >= 0 {
+		return resourceID[i+len(providers):]
+	}
+	return resourceID
+}
+
+func whatIfValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+
+package operations
+
+import (
+	"bytes"
+
+	"github.com/Azure/aks-engine/pkg/armhelpers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("What-if tests", func() {
+	const resourceGroupID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg1/providers/"
+
+	It("Should print the changes of each resource", func() {
+		result := armhelpers.WhatIfResult{
+			Status: "Succeeded",
+			Properties: &armhelpers.WhatIfResultProperties{
+				Changes: []armhelpers.WhatIfChange{
+					{
+						ResourceID: resourceGroupID + "Microsoft.Compute/virtualMachines/k8s-agentpool1-12345678-2",
+						ChangeType: armhelpers.WhatIfChangeCreate,
+					},
+					{
+						ResourceID: resourceGroupID + "Microsoft.Compute/virtualMachines/k8s-agentpool1-12345678-0",
+						ChangeType: armhelpers.WhatIfChangeModify,
+						Delta: []armhelpers.WhatIfPropertyChange{
+							{
+								Path:               "properties.storageProfile",
+								PropertyChangeType: armhelpers.WhatIfChangeModify,
+								Children: []armhelpers.WhatIfPropertyChange{
+									{Path: "imageReference.version", PropertyChangeType: armhelpers.WhatIfChangeModify, Before: "2021.01.01", After: "2021.02.01"},
+								},
+							},
+							{Path: "tags.poolName", PropertyChangeType: armhelpers.WhatIfChangeCreate, After: "agentpool1"},
+							{Path: "tags.orchestrator", PropertyChangeType: armhelpers.WhatIfChangeDelete, Before: "Kubernetes:1.18.8"},
+						},
+					},
+					{
+						ResourceID: resourceGroupID + "Microsoft.Network/loadBalancers/k8s-master-lb",
+						ChangeType: armhelpers.WhatIfChangeNoChange,
+					},
+					{
+						ResourceID: resourceGroupID + "Microsoft.Compute/availabilitySets/agentpool1-availabilitySet-12345678",
+						ChangeType: armhelpers.WhatIfChangeDelete,
+					},
+				},
+			},
+		}
+
+		var b bytes.Buffer
+		PrintWhatIfResult(&b, "deployment1", result)
+		Expect(b.String()).To(Equal(`Deployment deployment1 would make these resource changes: 1 to create, 1 to modify, 1 to delete, 0 to deploy, 1 no change, 0 ignored.
+  + Microsoft.Compute/virtualMachines/k8s-agentpool1-12345678-2
+  ~ Microsoft.Compute/virtualMachines/k8s-agentpool1-12345678-0
+      ~ properties.storageProfile.imageReference.version: "2021.01.01" => "2021.02.01"
+      + tags.poolName: "agentpool1"
+      - tags.orchestrator: "Kubernetes:1.18.8"
+  - Microsoft.Compute/availabilitySets/agentpool1-availabilitySet-12345678
+`))
+	})
+
+	It("Should print a summary without changes", func() {
+		var b bytes.Buffer
+		PrintWhatIfResult(&b, "deployment1", armhelpers.WhatIfResult{Status: "Succeeded"})
+		Expect(b.String()).To(Equal("Deployment deployment1 would make these resource changes: 0 to create, 0 to modify, 0 to delete, 0 to deploy, 0 no change, 0 ignored.\n"))
+	})
+})